@@ -6,6 +6,30 @@
     "upto-v3.1.0-add_writer_column.sql": importstr "rid/upto-v3.1.0-add_writer_column.sql",
     "upto-v3.1.1-add_index_by_time_subscriptions.sql": importstr "rid/upto-v3.1.1-add_index_by_time_subscriptions.sql",
     "upto-v4.0.0-rename_defaultdb_to_rid.sql": importstr "rid/upto-v4.0.0-rename_defaultdb_to_rid.sql",
+    "upto-v4.1.0-add_labels_columns.sql": importstr "rid/upto-v4.1.0-add_labels_columns.sql",
+    "upto-v4.2.0-add_updated_at_defaults.sql": importstr "rid/upto-v4.2.0-add_updated_at_defaults.sql",
+    "upto-v4.3.0-add_subscription_notification_counters.sql": importstr "rid/upto-v4.3.0-add_subscription_notification_counters.sql",
+    "upto-v4.4.0-add_isa_url_index.sql": importstr "rid/upto-v4.4.0-add_isa_url_index.sql",
+    "upto-v4.5.0-add_activity_events.sql": importstr "rid/upto-v4.5.0-add_activity_events.sql",
+    "downfrom-v4.5.0-remove_activity_events.sql": importstr "rid/downfrom-v4.5.0-remove_activity_events.sql",
+    "upto-v4.6.0-require_subscription_end.sql": importstr "rid/upto-v4.6.0-require_subscription_end.sql",
+    "downfrom-v4.6.0-allow_open_ended_subscriptions.sql": importstr "rid/downfrom-v4.6.0-allow_open_ended_subscriptions.sql",
+    "upto-v4.7.0-add_isa_extents.sql": importstr "rid/upto-v4.7.0-add_isa_extents.sql",
+    "downfrom-v4.7.0-remove_isa_extents.sql": importstr "rid/downfrom-v4.7.0-remove_isa_extents.sql",
+    "upto-v4.8.0-add_owner_aliases.sql": importstr "rid/upto-v4.8.0-add_owner_aliases.sql",
+    "downfrom-v4.8.0-remove_owner_aliases.sql": importstr "rid/downfrom-v4.8.0-remove_owner_aliases.sql",
+    "upto-v4.9.0-add_subscription_callback_urls.sql": importstr "rid/upto-v4.9.0-add_subscription_callback_urls.sql",
+    "downfrom-v4.9.0-remove_subscription_callback_urls.sql": importstr "rid/downfrom-v4.9.0-remove_subscription_callback_urls.sql",
+    "upto-v4.10.0-add_subscription_notification_intervals.sql": importstr "rid/upto-v4.10.0-add_subscription_notification_intervals.sql",
+    "downfrom-v4.10.0-remove_subscription_notification_intervals.sql": importstr "rid/downfrom-v4.10.0-remove_subscription_notification_intervals.sql",
+    "upto-v4.11.0-add_idempotent_responses.sql": importstr "rid/upto-v4.11.0-add_idempotent_responses.sql",
+    "downfrom-v4.11.0-remove_idempotent_responses.sql": importstr "rid/downfrom-v4.11.0-remove_idempotent_responses.sql",
+    "upto-v4.12.0-add_subscription_self_notifications.sql": importstr "rid/upto-v4.12.0-add_subscription_self_notifications.sql",
+    "downfrom-v4.12.0-remove_subscription_self_notifications.sql": importstr "rid/downfrom-v4.12.0-remove_subscription_self_notifications.sql",
+    "downfrom-v4.4.0-remove_isa_url_index.sql": importstr "rid/downfrom-v4.4.0-remove_isa_url_index.sql",
+    "downfrom-v4.3.0-remove_subscription_notification_counters.sql": importstr "rid/downfrom-v4.3.0-remove_subscription_notification_counters.sql",
+    "downfrom-v4.2.0-remove_updated_at_defaults.sql": importstr "rid/downfrom-v4.2.0-remove_updated_at_defaults.sql",
+    "downfrom-v4.1.0-remove_labels_columns.sql": importstr "rid/downfrom-v4.1.0-remove_labels_columns.sql",
     "downfrom-v4.0.0-move_rid_to_defaultdb.sql": importstr "rid/downfrom-v4.0.0-move_rid_to_defaultdb.sql",
     "downfrom-v3.1.1-remove_index_by_time_subscriptions.sql": importstr "rid/downfrom-v3.1.1-remove_index_by_time_subscriptions.sql",
     "downfrom-v3.1.0-remove_writer_column.sql": importstr "rid/downfrom-v3.1.0-remove_writer_column.sql",
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS identification_service_areas@isa_labels_idx;
+DROP INDEX IF EXISTS subscriptions@s_labels_idx;
+ALTER TABLE identification_service_areas DROP IF EXISTS labels;
+ALTER TABLE subscriptions DROP IF EXISTS labels;
+UPDATE schema_versions set schema_version = 'v4.0.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS idempotent_responses;
+UPDATE schema_versions set schema_version = 'v4.10.0' WHERE onerow_enforcer = TRUE;
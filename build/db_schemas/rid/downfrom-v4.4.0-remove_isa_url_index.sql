@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS identification_service_areas@isa_url_idx;
+UPDATE schema_versions set schema_version = 'v4.3.0' WHERE onerow_enforcer = TRUE;
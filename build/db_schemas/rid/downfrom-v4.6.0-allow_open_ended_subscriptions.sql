@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions ALTER COLUMN ends_at DROP NOT NULL;
+UPDATE schema_versions set schema_version = 'v4.5.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,4 @@
+ALTER TABLE identification_service_areas DROP IF EXISTS footprint;
+ALTER TABLE identification_service_areas DROP IF EXISTS altitude_upper;
+ALTER TABLE identification_service_areas DROP IF EXISTS altitude_lower;
+UPDATE schema_versions set schema_version = 'v4.6.0' WHERE onerow_enforcer = TRUE;
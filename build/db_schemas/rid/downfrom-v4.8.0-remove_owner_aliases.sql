@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS owner_aliases;
+UPDATE schema_versions set schema_version = 'v4.7.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions DROP IF EXISTS callback_urls;
+UPDATE schema_versions set schema_version = 'v4.8.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,5 @@
+ALTER TABLE identification_service_areas ADD COLUMN IF NOT EXISTS labels JSONB;
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS labels JSONB;
+CREATE INVERTED INDEX IF NOT EXISTS isa_labels_idx ON identification_service_areas (labels);
+CREATE INVERTED INDEX IF NOT EXISTS s_labels_idx ON subscriptions (labels);
+UPDATE schema_versions set schema_version = 'v4.1.0' WHERE onerow_enforcer = TRUE;
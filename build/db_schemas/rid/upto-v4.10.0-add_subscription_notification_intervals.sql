@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS min_notification_interval_seconds INT8;
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS last_notified_at TIMESTAMPTZ;
+UPDATE schema_versions set schema_version = 'v4.10.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS idempotent_responses (
+    owner STRING NOT NULL,
+    idempotency_key STRING NOT NULL,
+    request_hash BYTES NOT NULL,
+    status INT8 NOT NULL,
+    body BYTES NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (owner, idempotency_key),
+    INDEX idempotent_responses_expires_at_idx (expires_at)
+);
+UPDATE schema_versions set schema_version = 'v4.11.0' WHERE onerow_enforcer = TRUE;
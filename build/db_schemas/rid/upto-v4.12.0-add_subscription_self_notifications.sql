@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS notify_own_isas BOOL NOT NULL DEFAULT false;
+UPDATE schema_versions set schema_version = 'v4.12.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,6 @@
+-- updated_at doubles as the entity version, so it is only given a DEFAULT and
+-- not an ON UPDATE expression: notification index and label updates must not
+-- change the version of an entity.
+ALTER TABLE identification_service_areas ALTER COLUMN updated_at SET DEFAULT transaction_timestamp();
+ALTER TABLE subscriptions ALTER COLUMN updated_at SET DEFAULT transaction_timestamp();
+UPDATE schema_versions set schema_version = 'v4.2.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS isa_url_idx ON identification_service_areas (url);
+UPDATE schema_versions set schema_version = 'v4.4.0' WHERE onerow_enforcer = TRUE;
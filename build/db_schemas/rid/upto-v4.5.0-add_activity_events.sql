@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS activity_events (
+    entity_type STRING NOT NULL,
+    entity_id UUID NOT NULL,
+    event STRING NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    PRIMARY KEY (entity_id, occurred_at, event),
+    INDEX activity_events_occurred_at_idx (occurred_at),
+    INDEX activity_events_ends_at_idx (entity_type, ends_at)
+);
+UPDATE schema_versions set schema_version = 'v4.5.0' WHERE onerow_enforcer = TRUE;
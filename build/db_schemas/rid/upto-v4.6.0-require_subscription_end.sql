@@ -0,0 +1,5 @@
+-- Open-ended subscriptions, which predate the default end time set by the
+-- DSS, end the maximum subscription duration after their start.
+UPDATE subscriptions SET ends_at = COALESCE(starts_at, updated_at) + INTERVAL '24 hours' WHERE ends_at IS NULL;
+ALTER TABLE subscriptions ALTER COLUMN ends_at SET NOT NULL;
+UPDATE schema_versions set schema_version = 'v4.6.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,4 @@
+ALTER TABLE identification_service_areas ADD COLUMN IF NOT EXISTS altitude_lower REAL;
+ALTER TABLE identification_service_areas ADD COLUMN IF NOT EXISTS altitude_upper REAL;
+ALTER TABLE identification_service_areas ADD COLUMN IF NOT EXISTS footprint JSONB;
+UPDATE schema_versions set schema_version = 'v4.7.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS owner_aliases (
+    subject STRING PRIMARY KEY,
+    owner STRING NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    INDEX owner_aliases_owner_idx (owner)
+);
+UPDATE schema_versions set schema_version = 'v4.8.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS callback_urls STRING[];
+UPDATE schema_versions set schema_version = 'v4.9.0' WHERE onerow_enforcer = TRUE;
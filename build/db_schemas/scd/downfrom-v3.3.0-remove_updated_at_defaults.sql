@@ -0,0 +1,5 @@
+ALTER TABLE scd_subscriptions ALTER COLUMN updated_at DROP DEFAULT;
+ALTER TABLE scd_operations ALTER COLUMN updated_at DROP DEFAULT;
+ALTER TABLE scd_constraints ALTER COLUMN updated_at DROP DEFAULT;
+ALTER TABLE scd_uss_availability ALTER COLUMN updated_at DROP DEFAULT;
+UPDATE schema_versions set schema_version = 'v3.2.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,8 @@
+-- updated_at doubles as the entity version, so it is only given a DEFAULT and
+-- not an ON UPDATE expression: notification index updates must not change the
+-- version of an entity.
+ALTER TABLE scd_subscriptions ALTER COLUMN updated_at SET DEFAULT transaction_timestamp();
+ALTER TABLE scd_operations ALTER COLUMN updated_at SET DEFAULT transaction_timestamp();
+ALTER TABLE scd_constraints ALTER COLUMN updated_at SET DEFAULT transaction_timestamp();
+ALTER TABLE scd_uss_availability ALTER COLUMN updated_at SET DEFAULT transaction_timestamp();
+UPDATE schema_versions set schema_version = 'v3.3.0' WHERE onerow_enforcer = TRUE;
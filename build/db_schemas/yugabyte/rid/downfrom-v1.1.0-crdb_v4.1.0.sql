@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS isa_labels_idx;
+DROP INDEX IF EXISTS s_labels_idx;
+ALTER TABLE identification_service_areas DROP COLUMN IF EXISTS labels;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS labels;
+UPDATE schema_versions set schema_version = 'v1.0.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS last_notified_at;
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS min_notification_interval_seconds;
+UPDATE schema_versions set schema_version = 'v1.9.0' WHERE onerow_enforcer = TRUE;
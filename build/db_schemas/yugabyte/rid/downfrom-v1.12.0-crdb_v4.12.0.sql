@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS notify_own_isas;
+UPDATE schema_versions set schema_version = 'v1.11.0' WHERE onerow_enforcer = TRUE;
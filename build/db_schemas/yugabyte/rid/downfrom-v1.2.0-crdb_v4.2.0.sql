@@ -0,0 +1,3 @@
+ALTER TABLE identification_service_areas ALTER COLUMN updated_at DROP DEFAULT;
+ALTER TABLE subscriptions ALTER COLUMN updated_at DROP DEFAULT;
+UPDATE schema_versions set schema_version = 'v1.1.0' WHERE onerow_enforcer = TRUE;
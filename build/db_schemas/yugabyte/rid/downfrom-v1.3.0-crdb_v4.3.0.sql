@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS subscription_notification_counters;
+UPDATE schema_versions set schema_version = 'v1.2.0' WHERE onerow_enforcer = TRUE;
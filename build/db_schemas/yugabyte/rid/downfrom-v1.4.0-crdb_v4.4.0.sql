@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS isa_url_idx;
+UPDATE schema_versions set schema_version = 'v1.3.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS activity_events;
+UPDATE schema_versions set schema_version = 'v1.4.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,4 @@
+ALTER TABLE identification_service_areas DROP COLUMN IF EXISTS footprint;
+ALTER TABLE identification_service_areas DROP COLUMN IF EXISTS altitude_upper;
+ALTER TABLE identification_service_areas DROP COLUMN IF EXISTS altitude_lower;
+UPDATE schema_versions set schema_version = 'v1.6.0' WHERE onerow_enforcer = TRUE;
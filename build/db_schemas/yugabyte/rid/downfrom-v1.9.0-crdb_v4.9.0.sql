@@ -0,0 +1,2 @@
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS callback_urls;
+UPDATE schema_versions set schema_version = 'v1.8.0' WHERE onerow_enforcer = TRUE;
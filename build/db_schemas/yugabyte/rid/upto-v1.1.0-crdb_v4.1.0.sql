@@ -0,0 +1,7 @@
+-- This migration is equivalent to rid v4.1.0 schema for CockroachDB.
+
+ALTER TABLE identification_service_areas ADD COLUMN IF NOT EXISTS labels JSONB;
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS labels JSONB;
+CREATE INDEX IF NOT EXISTS isa_labels_idx ON identification_service_areas USING ybgin (labels);
+CREATE INDEX IF NOT EXISTS s_labels_idx ON subscriptions USING ybgin (labels);
+UPDATE schema_versions set schema_version = 'v1.1.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,5 @@
+-- This migration is equivalent to rid v4.10.0 schema for CockroachDB.
+
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS min_notification_interval_seconds BIGINT;
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS last_notified_at TIMESTAMPTZ;
+UPDATE schema_versions set schema_version = 'v1.10.0' WHERE onerow_enforcer = TRUE;
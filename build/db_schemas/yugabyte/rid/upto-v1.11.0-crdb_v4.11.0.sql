@@ -0,0 +1,13 @@
+-- This migration is equivalent to rid v4.11.0 schema for CockroachDB.
+
+CREATE TABLE IF NOT EXISTS idempotent_responses (
+    owner TEXT NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    request_hash BYTEA NOT NULL,
+    status BIGINT NOT NULL,
+    body BYTEA NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (owner, idempotency_key)
+);
+CREATE INDEX IF NOT EXISTS idempotent_responses_expires_at_idx ON idempotent_responses (expires_at);
+UPDATE schema_versions set schema_version = 'v1.11.0' WHERE onerow_enforcer = TRUE;
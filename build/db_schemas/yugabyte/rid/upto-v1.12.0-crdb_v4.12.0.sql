@@ -0,0 +1,4 @@
+-- This migration is equivalent to rid v4.12.0 schema for CockroachDB.
+
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS notify_own_isas BOOLEAN NOT NULL DEFAULT false;
+UPDATE schema_versions set schema_version = 'v1.12.0' WHERE onerow_enforcer = TRUE;
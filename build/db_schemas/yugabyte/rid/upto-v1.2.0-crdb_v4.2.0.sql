@@ -0,0 +1,5 @@
+-- This migration is equivalent to rid v4.2.0 schema for CockroachDB.
+
+ALTER TABLE identification_service_areas ALTER COLUMN updated_at SET DEFAULT transaction_timestamp();
+ALTER TABLE subscriptions ALTER COLUMN updated_at SET DEFAULT transaction_timestamp();
+UPDATE schema_versions set schema_version = 'v1.2.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,9 @@
+-- This migration is equivalent to rid v4.3.0 schema for CockroachDB.
+
+CREATE TABLE IF NOT EXISTS subscription_notification_counters (
+    subscription_id UUID NOT NULL,
+    shard INT4 NOT NULL,
+    increments INT8 NOT NULL DEFAULT 0,
+    PRIMARY KEY (subscription_id, shard)
+);
+UPDATE schema_versions set schema_version = 'v1.3.0' WHERE onerow_enforcer = TRUE;
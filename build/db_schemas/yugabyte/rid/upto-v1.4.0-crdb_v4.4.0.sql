@@ -0,0 +1,5 @@
+-- This migration is equivalent to rid v4.4.0 schema for CockroachDB.
+
+-- The index is ordered so that it serves searches by URL prefix.
+CREATE INDEX IF NOT EXISTS isa_url_idx ON identification_service_areas (url ASC);
+UPDATE schema_versions set schema_version = 'v1.4.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,14 @@
+-- This migration is equivalent to rid v4.5.0 schema for CockroachDB.
+
+CREATE TABLE IF NOT EXISTS activity_events (
+    entity_type TEXT NOT NULL,
+    entity_id UUID NOT NULL,
+    event TEXT NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    PRIMARY KEY (entity_id, occurred_at, event)
+);
+CREATE INDEX IF NOT EXISTS activity_events_occurred_at_idx ON activity_events (occurred_at);
+CREATE INDEX IF NOT EXISTS activity_events_ends_at_idx ON activity_events (entity_type, ends_at);
+UPDATE schema_versions set schema_version = 'v1.5.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,5 @@
+-- This migration is equivalent to rid v4.6.0 schema for CockroachDB.
+
+UPDATE subscriptions SET ends_at = COALESCE(starts_at, updated_at) + INTERVAL '24 hours' WHERE ends_at IS NULL;
+ALTER TABLE subscriptions ALTER COLUMN ends_at SET NOT NULL;
+UPDATE schema_versions set schema_version = 'v1.6.0' WHERE onerow_enforcer = TRUE;
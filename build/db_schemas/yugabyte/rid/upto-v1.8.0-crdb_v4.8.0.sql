@@ -0,0 +1,9 @@
+-- This migration is equivalent to rid v4.8.0 schema for CockroachDB.
+
+CREATE TABLE IF NOT EXISTS owner_aliases (
+    subject TEXT PRIMARY KEY,
+    owner TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS owner_aliases_owner_idx ON owner_aliases (owner);
+UPDATE schema_versions set schema_version = 'v1.8.0' WHERE onerow_enforcer = TRUE;
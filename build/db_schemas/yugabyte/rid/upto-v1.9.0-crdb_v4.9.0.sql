@@ -0,0 +1,4 @@
+-- This migration is equivalent to rid v4.9.0 schema for CockroachDB.
+
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS callback_urls TEXT[];
+UPDATE schema_versions set schema_version = 'v1.9.0' WHERE onerow_enforcer = TRUE;
@@ -0,0 +1,7 @@
+-- This migration is equivalent to scd v3.3.0 schema for CockroachDB.
+
+ALTER TABLE scd_subscriptions ALTER COLUMN updated_at SET DEFAULT transaction_timestamp();
+ALTER TABLE scd_operations ALTER COLUMN updated_at SET DEFAULT transaction_timestamp();
+ALTER TABLE scd_constraints ALTER COLUMN updated_at SET DEFAULT transaction_timestamp();
+ALTER TABLE scd_uss_availability ALTER COLUMN updated_at SET DEFAULT transaction_timestamp();
+UPDATE schema_versions set schema_version = 'v1.1.0' WHERE onerow_enforcer = TRUE;
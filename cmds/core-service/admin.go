@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	apiauxv1 "github.com/interuss/dss/pkg/api/auxv1"
+	"github.com/interuss/dss/pkg/auth"
+	"github.com/interuss/stacktrace"
+)
+
+// The ways --admin_auth authorizes the clients of --admin_addr.
+const (
+	adminAuthScopes = "scopes"
+	adminAuthMTLS   = "mtls"
+)
+
+// adminScopes are granted to the clients of --admin_addr authenticated by
+// their certificate.
+var adminScopes = []string{string(apiauxv1.DssAdminScope), string(apiauxv1.DssTransferOwnershipScope)}
+
+// createAdminTLSConfig returns the TLS configuration of --admin_addr, nil if
+// it serves plain HTTP or is not set.
+func createAdminTLSConfig() (*tls.Config, error) {
+	if *adminAddr == "" {
+		return nil, nil
+	}
+	switch *adminAuth {
+	case adminAuthScopes, adminAuthMTLS:
+	default:
+		return nil, stacktrace.NewError("--admin_auth must be %s or %s, not %s", adminAuthScopes, adminAuthMTLS, *adminAuth)
+	}
+	if (*adminTLSCertFile == "") != (*adminTLSKeyFile == "") {
+		return nil, stacktrace.NewError("--admin_tls_cert_file and --admin_tls_key_file must be set together")
+	}
+	if *adminClientCAFile != "" && *adminTLSCertFile == "" {
+		return nil, stacktrace.NewError("--admin_client_ca_file requires --admin_tls_cert_file and --admin_tls_key_file")
+	}
+	if *adminAuth == adminAuthMTLS && *adminClientCAFile == "" {
+		return nil, stacktrace.NewError("--admin_auth=%s requires --admin_client_ca_file", adminAuthMTLS)
+	}
+	if *adminTLSCertFile == "" {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(*adminTLSCertFile, *adminTLSKeyFile)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error loading --admin_tls_cert_file and --admin_tls_key_file")
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if *adminClientCAFile != "" {
+		pem, err := os.ReadFile(*adminClientCAFile)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Error reading --admin_client_ca_file")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, stacktrace.NewError("No PEM-encoded certificate in --admin_client_ca_file %s", *adminClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// createAdminAuthorizer returns the authorizer of --admin_addr, verifying
+// client certificates or access tokens as configured by --admin_auth.
+func createAdminAuthorizer(ctx context.Context, configuration auth.Configuration) (api.Authorizer, error) {
+	if *adminAuth == adminAuthMTLS {
+		return &auth.ClientCertificateAuthorizer{Scopes: adminScopes, OwnerAliases: configuration.OwnerAliases}, nil
+	}
+	if *adminJWTAudiences != "" {
+		configuration.AcceptedAudiences = strings.Split(*adminJWTAudiences, ",")
+	}
+	// Administrators act as themselves, and every administrative operation
+	// requires scopes.
+	configuration.AllowImpersonation = false
+	configuration.PublicPaths = nil
+	return auth.NewRSAAuthorizer(ctx, configuration)
+}
+
+// listenAdmin returns a listener on --admin_addr, accepting TLS connections
+// if tlsConfig is set.
+func listenAdmin(tlsConfig *tls.Config) (net.Listener, error) {
+	l, err := listenUnlimited(*adminAddr)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if tlsConfig != nil {
+		l = tls.NewListener(l, tlsConfig)
+	}
+	return l, nil
+}
+
+// newAdminServer returns the server of --admin_addr, serving handler.
+func newAdminServer(handler http.Handler, tlsConfig *tls.Config) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 15 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       *httpIdleTimeout,
+	}
+}
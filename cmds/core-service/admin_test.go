@@ -0,0 +1,147 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	"github.com/interuss/dss/pkg/auth"
+	"github.com/stretchr/testify/require"
+)
+
+// setAdminFlags sets the flags of the admin listener until the end of t.
+func setAdminFlags(t *testing.T, addr, authorization, certFile, keyFile, clientCAFile string) {
+	previous := []string{*adminAddr, *adminAuth, *adminTLSCertFile, *adminTLSKeyFile, *adminClientCAFile}
+	t.Cleanup(func() {
+		*adminAddr, *adminAuth, *adminTLSCertFile, *adminTLSKeyFile, *adminClientCAFile =
+			previous[0], previous[1], previous[2], previous[3], previous[4]
+	})
+	*adminAddr, *adminAuth, *adminTLSCertFile, *adminTLSKeyFile, *adminClientCAFile =
+		addr, authorization, certFile, keyFile, clientCAFile
+}
+
+// issueCertificate returns a certificate for commonName signed by parent, or
+// self-signed if parent is nil, and its key.
+func issueCertificate(t *testing.T, commonName string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+func writePEM(t *testing.T, name string, blocks ...*pem.Block) string {
+	file := filepath.Join(t.TempDir(), name)
+	var content []byte
+	for _, block := range blocks {
+		content = append(content, pem.EncodeToMemory(block)...)
+	}
+	require.NoError(t, os.WriteFile(file, content, 0600))
+	return file
+}
+
+func TestCreateAdminTLSConfig(t *testing.T) {
+	setAdminFlags(t, "", "bogus", "", "", "")
+	config, err := createAdminTLSConfig()
+	require.NoError(t, err)
+	require.Nil(t, config)
+
+	setAdminFlags(t, ":8081", "bogus", "", "", "")
+	_, err = createAdminTLSConfig()
+	require.Error(t, err)
+
+	setAdminFlags(t, ":8081", adminAuthScopes, "", "", "")
+	config, err = createAdminTLSConfig()
+	require.NoError(t, err)
+	require.Nil(t, config)
+
+	setAdminFlags(t, ":8081", adminAuthScopes, "server.pem", "", "")
+	_, err = createAdminTLSConfig()
+	require.Error(t, err)
+
+	setAdminFlags(t, ":8081", adminAuthScopes, "", "", "ca.pem")
+	_, err = createAdminTLSConfig()
+	require.Error(t, err)
+
+	setAdminFlags(t, ":8081", adminAuthMTLS, "server.pem", "server.key", "")
+	_, err = createAdminTLSConfig()
+	require.Error(t, err)
+}
+
+func TestAdminListenerRequiresClientCertificates(t *testing.T) {
+	ca, caKey := issueCertificate(t, "admin CA", nil, nil)
+	server, serverKey := issueCertificate(t, "dss", ca, caKey)
+	client, clientKey := issueCertificate(t, "ops-console", ca, caKey)
+	serverKeyDER, err := x509.MarshalECPrivateKey(serverKey)
+	require.NoError(t, err)
+	setAdminFlags(t, "127.0.0.1:0", adminAuthMTLS,
+		writePEM(t, "server.pem", &pem.Block{Type: "CERTIFICATE", Bytes: server.Raw}),
+		writePEM(t, "server.key", &pem.Block{Type: "EC PRIVATE KEY", Bytes: serverKeyDER}),
+		writePEM(t, "ca.pem", &pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}))
+
+	tlsConfig, err := createAdminTLSConfig()
+	require.NoError(t, err)
+	authorizer := &auth.ClientCertificateAuthorizer{Scopes: adminScopes}
+	adminServer := newAdminServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res := authorizer.Authorize(w, r, []api.AuthorizationOption{{"Auth": {"dss.admin"}}})
+		if res.Error != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(*res.ClientID))
+	}), tlsConfig)
+	l, err := listenAdmin(tlsConfig)
+	require.NoError(t, err)
+	go func() { _ = adminServer.Serve(l) }()
+	defer adminServer.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	url := "https://" + l.Addr().String() + "/aux/v1/owner_aliases"
+
+	anonymous := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	_, err = anonymous.Get(url)
+	require.Error(t, err)
+
+	authenticated := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{{Certificate: [][]byte{client.Raw}, PrivateKey: clientKey}},
+	}}}
+	resp, err := authenticated.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "ops-console", string(body))
+}
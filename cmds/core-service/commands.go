@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/interuss/dss/cmds/db-manager/migration"
+	"github.com/interuss/dss/pkg/geo"
+	"github.com/interuss/dss/pkg/logging"
+	"github.com/interuss/dss/pkg/version"
+	"github.com/interuss/stacktrace"
+	"github.com/spf13/cobra"
+)
+
+// The commands of core-service other than migrate parse their flags with the
+// flag package rather than cobra, so that the single-dash flags of existing
+// deployments, e.g. -addr, keep working. Running core-service without a
+// command serves requests.
+var (
+	rootCmd = &cobra.Command{
+		Use:                "core-service",
+		Short:              "DSS core service",
+		Long:               "DSS core service, serving requests when run without a command; run `core-service serve -help` for the flags.",
+		Args:               cobra.ArbitraryArgs,
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		RunE:               runServe,
+		CompletionOptions:  cobra.CompletionOptions{DisableDefaultCmd: true},
+	}
+	serveCmd = &cobra.Command{
+		Use:                "serve",
+		Short:              "Serve requests",
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		RunE:               runServe,
+	}
+	checkCmd = &cobra.Command{
+		Use:                "check",
+		Short:              "Validate the runtime environment with the flags used to serve requests",
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		RunE:               runCheck,
+	}
+	versionCmd = &cobra.Command{
+		Use:   "version",
+		Short: "Print the version of core-service",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			fmt.Fprintln(cmd.OutOrStdout(), version.Current().String())
+		},
+	}
+)
+
+func init() {
+	// migrate parses the database flags of core-service with cobra, like
+	// db-manager does.
+	migration.MigrationCmd.Flags().AddGoFlagSet(flag.CommandLine)
+	rootCmd.AddCommand(serveCmd, checkCmd, migration.MigrationCmd, versionCmd)
+}
+
+// configure parses args into the flags of core-service, applies
+// --config_file and configures logging, as shared by serve and check.
+func configure(args []string) error {
+	if err := flag.CommandLine.Parse(args); err != nil {
+		return err
+	}
+	if flag.NArg() > 0 {
+		return stacktrace.NewError("Unexpected arguments %v", flag.Args())
+	}
+	snapshotStartupFlags()
+	if *configFile != "" {
+		values, err := readConfigFile(*configFile)
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to read configuration file")
+		}
+		if _, err := applyConfig(values); err != nil {
+			return stacktrace.Propagate(err, "Failed to apply configuration file")
+		}
+	}
+	if err := logging.Configure(*logLevel, *logFormat); err != nil {
+		return stacktrace.Propagate(err, "Failed to configure logging")
+	}
+	// Every log line identifies the instance writing it.
+	logging.Logger = logging.Logger.With(createInstanceIdentity().Fields()...)
+
+	SetDeprecatingHttpFlag(logging.Logger, &allowHTTPBaseUrls, &enableHTTP)
+
+	geo.SetCoveringCacheSize(*coveringCacheSize)
+	return nil
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if err := configure(args); err != nil {
+		return err
+	}
+	// -check is the flag predating the check command.
+	if *checkOnly {
+		return runChecks()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	serve(ctx, cancel)
+	return nil
+}
+
+func runCheck(_ *cobra.Command, args []string) error {
+	if err := configure(args); err != nil {
+		return err
+	}
+	return runChecks()
+}
+
+// runChecks runs the self-checks applicable to the configuration and exits
+// with a non-zero status if any fails.
+func runChecks() error {
+	if !runSelfChecks(context.Background(), os.Stdout, doctorChecks()) {
+		os.Exit(1)
+	}
+	return nil
+}
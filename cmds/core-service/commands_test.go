@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommands(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		use  string
+	}{
+		{nil, "core-service"},
+		{[]string{"-addr", ":8082", "-enable_scd"}, "core-service"},
+		{[]string{"serve", "-addr", ":8082"}, "serve"},
+		{[]string{"check", "-cockroach_host", "localhost"}, "check"},
+		{[]string{"migrate", "--schemas_dir", "db-schemas/rid"}, "migrate"},
+		{[]string{"version"}, "version"},
+	} {
+		cmd, _, err := rootCmd.Find(tc.args)
+		require.NoError(t, err, tc.args)
+		require.Equal(t, tc.use, cmd.Name(), tc.args)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/interuss/stacktrace"
+)
+
+var (
+	tcpKeepAlive     = flag.Duration("tcp_keepalive_period", 0, "Period of the TCP keep-alive probes of client connections, detecting peers gone without closing their connection; 15s if 0, disabled if negative")
+	httpIdleTimeout  = flag.Duration("http_idle_timeout", 30*time.Second, "Time after which idle keep-alive client connections are closed")
+	maxConnectionAge = flag.Duration("http_max_connection_age", 0, "Age, within +/-10%, after which client connections are closed once their current request is served, so that clients reconnecting through an L4 load balancer spread again across instances; unlimited if 0")
+	maxConnections   = flag.Int("http_max_connections", 0, "Maximum number of concurrent client connections on each listen address, further connections waiting to be accepted; unlimited if 0")
+)
+
+// maxConnectionAgeJitter is the fraction by which the maximum age of each
+// connection is randomly shortened or lengthened, so that connections opened
+// together, e.g. after a restart, are not all closed together.
+const maxConnectionAgeJitter = 0.1
+
+// connectionPolicy manages the lifetime of client connections.
+type connectionPolicy struct {
+	// MaxAge is the age after which connections are closed once their
+	// current request is served; unlimited if 0.
+	MaxAge time.Duration
+
+	jitter func() float64
+}
+
+// createConnectionPolicy returns the policy managing client connections.
+func createConnectionPolicy() (connectionPolicy, error) {
+	if *httpIdleTimeout < 0 {
+		return connectionPolicy{}, stacktrace.NewError("--http_idle_timeout must not be negative")
+	}
+	if *maxConnectionAge < 0 {
+		return connectionPolicy{}, stacktrace.NewError("--http_max_connection_age must not be negative")
+	}
+	if *maxConnections < 0 {
+		return connectionPolicy{}, stacktrace.NewError("--http_max_connections must not be negative")
+	}
+	return connectionPolicy{MaxAge: *maxConnectionAge}, nil
+}
+
+type connectionExpiryKey struct{}
+
+// ConnContext is an http.Server ConnContext hook recording in ctx when the
+// connection c expires.
+func (p connectionPolicy) ConnContext(ctx context.Context, c net.Conn) context.Context {
+	if p.MaxAge <= 0 {
+		return ctx
+	}
+	jitter := rand.Float64
+	if p.jitter != nil {
+		jitter = p.jitter
+	}
+	age := time.Duration(float64(p.MaxAge) * (1 + maxConnectionAgeJitter*(2*jitter()-1)))
+	return context.WithValue(ctx, connectionExpiryKey{}, time.Now().Add(age))
+}
+
+// Middleware returns an http.Handler closing the connections of the requests
+// passed to next, once they are served, after the connections expired.
+func (p connectionPolicy) Middleware(next http.Handler) http.Handler {
+	if p.MaxAge <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if expiry, ok := r.Context().Value(connectionExpiryKey{}).(time.Time); ok && time.Now().After(expiry) {
+			w.Header().Set("Connection", "close")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionPolicyClosesExpiredConnections(t *testing.T) {
+	p := connectionPolicy{MaxAge: time.Hour, jitter: func() float64 { return 0 }}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	serve := func(ctx context.Context) http.Header {
+		w := httptest.NewRecorder()
+		p.Middleware(ok).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+		return w.Header()
+	}
+
+	// The connection expires after 54 minutes, the age less 10%.
+	ctx := p.ConnContext(context.Background(), nil)
+	expiry := ctx.Value(connectionExpiryKey{}).(time.Time)
+	require.WithinDuration(t, time.Now().Add(54*time.Minute), expiry, time.Second)
+	require.Empty(t, serve(ctx).Get("Connection"))
+
+	ctx = context.WithValue(ctx, connectionExpiryKey{}, time.Now().Add(-time.Second))
+	require.Equal(t, "close", serve(ctx).Get("Connection"))
+}
+
+func TestConnectionPolicyWithoutMaxAge(t *testing.T) {
+	p := connectionPolicy{}
+	ctx := context.Background()
+	require.Equal(t, ctx, p.ConnContext(ctx, nil))
+}
+
+func TestServerClosesExpiredConnections(t *testing.T) {
+	p := connectionPolicy{MaxAge: time.Nanosecond}
+	server := httptest.NewUnstartedServer(p.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	server.Config.ConnContext = p.ConnContext
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.True(t, resp.Close)
+}
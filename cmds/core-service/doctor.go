@@ -0,0 +1,394 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/interuss/dss/pkg/datastore"
+	"github.com/interuss/dss/pkg/datastore/flags"
+	"github.com/interuss/dss/pkg/geo"
+	ridstore "github.com/interuss/dss/pkg/rid/store"
+	ridc "github.com/interuss/dss/pkg/rid/store/cockroach"
+	scdc "github.com/interuss/dss/pkg/scd/store/cockroach"
+	"github.com/interuss/stacktrace"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	// certificateExpiryWarning is how long before a certificate expires that
+	// it is reported as a warning.
+	certificateExpiryWarning = 30 * 24 * time.Hour
+)
+
+// checkStatus is the outcome of a single self-check.
+type checkStatus string
+
+const (
+	checkOK      checkStatus = "OK"
+	checkWarning checkStatus = "WARN"
+	checkFailed  checkStatus = "FAIL"
+)
+
+// checkResult describes the outcome of a single self-check along with the
+// action an operator should take when it did not succeed.
+type checkResult struct {
+	Status checkStatus
+	Detail string
+	Hint   string
+}
+
+// selfCheck validates one aspect of the runtime environment.
+type selfCheck struct {
+	Name string
+	Run  func(ctx context.Context) checkResult
+}
+
+func ok(format string, a ...interface{}) checkResult {
+	return checkResult{Status: checkOK, Detail: fmt.Sprintf(format, a...)}
+}
+
+func warning(hint, format string, a ...interface{}) checkResult {
+	return checkResult{Status: checkWarning, Detail: fmt.Sprintf(format, a...), Hint: hint}
+}
+
+func failed(err error, hint string) checkResult {
+	return checkResult{Status: checkFailed, Detail: stacktrace.RootCause(err).Error(), Hint: hint}
+}
+
+// runSelfChecks runs checks in order, reporting their outcome to w, and
+// returns whether none of them failed.
+func runSelfChecks(ctx context.Context, w io.Writer, checks []selfCheck) bool {
+	healthy := true
+	for _, c := range checks {
+		r := c.Run(ctx)
+		fmt.Fprintf(w, "[%-4s] %s: %s\n", r.Status, c.Name, r.Detail)
+		if r.Hint != "" {
+			fmt.Fprintf(w, "       -> %s\n", r.Hint)
+		}
+		if r.Status == checkFailed {
+			healthy = false
+		}
+	}
+	return healthy
+}
+
+// doctorChecks returns the self-checks applicable to the configuration
+// provided on the command line.
+func doctorChecks() []selfCheck {
+	checks := []selfCheck{
+		{Name: "remote ID database", Run: checkRIDDatabase},
+	}
+	if *enableSCD {
+		checks = append(checks, selfCheck{Name: "strategic conflict detection database", Run: checkSCDDatabase})
+	}
+	if _, ok := flags.ReadConnectParameters(); ok {
+		checks = append(checks, selfCheck{Name: "read database", Run: checkReadDatabase})
+	}
+	return append(checks,
+		selfCheck{Name: "database TLS certificates", Run: checkDatabaseCertificates},
+		selfCheck{Name: "access token keys", Run: checkAccessTokenKeys},
+		selfCheck{Name: "S2 configuration", Run: checkS2Configuration},
+		selfCheck{Name: "service configuration", Run: checkServiceConfiguration},
+	)
+}
+
+const schemaHint = "verify the database is reachable and migrated as described in https://github.com/interuss/dss/tree/master/build#upgrading-database-schemas"
+
+func checkRIDDatabase(ctx context.Context) checkResult {
+	if *ridStoreURI != "" {
+		return checkRIDStoreURI(ctx)
+	}
+	dbNames := []string{flags.RIDDatabaseName()}
+	if dbNames[0] == "rid" {
+		// Deployments predating schema 4.0.0 keep remote ID data in defaultdb.
+		dbNames = append(dbNames, "defaultdb")
+	}
+	var lastErr error
+	for _, dbName := range dbNames {
+		connectParameters := flags.ConnectParameters()
+		connectParameters.DBName = dbName
+		db, err := datastore.Dial(ctx, connectParameters)
+		if err != nil {
+			return failed(err, schemaHint)
+		}
+		store, err := ridc.NewStore(ctx, db, dbName, zap.NewNop())
+		if err != nil {
+			db.Pool.Close()
+			lastErr = err
+			continue
+		}
+		defer store.Close()
+		vs, err := store.GetVersion(ctx)
+		if err != nil {
+			return failed(err, schemaHint)
+		}
+		if err := store.CheckTargetSchemaVersion(ctx); err != nil {
+			return schemaVersionResult(err)
+		}
+		return ok("connected to %s with schema v%s", dbName, vs)
+	}
+	return failed(lastErr, schemaHint)
+}
+
+// checkRIDStoreURI checks the remote ID store located by --rid_store_uri.
+func checkRIDStoreURI(ctx context.Context) checkResult {
+	const hint = "fix --rid_store_uri, e.g. cockroach://root@crdb:26257/rid?sslmode=disable"
+	store, err := ridstore.Open(ctx, *ridStoreURI, zap.NewNop())
+	if err != nil {
+		return failed(err, hint)
+	}
+	defer store.Close()
+	if names := cockroachRIDFlags(); len(names) > 0 {
+		if _, isCrdb := store.(*ridc.Store); !isCrdb {
+			return failed(stacktrace.NewError("%s require a CockroachDB or Yugabyte remote ID store", strings.Join(names, ", ")), hint)
+		}
+	}
+	vs, err := store.GetVersion(ctx)
+	if err != nil {
+		return failed(err, schemaHint)
+	}
+	if crdbStore, isCrdb := store.(*ridc.Store); isCrdb {
+		if err := crdbStore.CheckTargetSchemaVersion(ctx); err != nil {
+			return schemaVersionResult(err)
+		}
+		return ok("connected to %s with schema v%s", crdbStore.DatabaseName, vs)
+	}
+	return ok("opened %T with schema v%s", store, vs)
+}
+
+func checkSCDDatabase(ctx context.Context) checkResult {
+	connectParameters := flags.ConnectParameters()
+	connectParameters.DBName = flags.SCDDatabaseName()
+	db, err := datastore.Dial(ctx, connectParameters)
+	if err != nil {
+		return failed(err, schemaHint)
+	}
+	store, err := scdc.NewStore(ctx, db)
+	if err != nil {
+		db.Pool.Close()
+		return failed(err, schemaHint)
+	}
+	defer store.Close()
+	vs, err := store.GetVersion(ctx)
+	if err != nil {
+		return failed(err, schemaHint)
+	}
+	if err := store.CheckTargetSchemaVersion(ctx); err != nil {
+		return schemaVersionResult(err)
+	}
+	return ok("connected to %s with schema v%s", connectParameters.DBName, vs)
+}
+
+// schemaVersionResult reports a schema version supported by the store but
+// other than the one it is written for, which only fails the check if
+// --strict_schema_version is set.
+func schemaVersionResult(err error) checkResult {
+	if *strictSchemaVersion {
+		return failed(err, schemaHint)
+	}
+	return warning(schemaHint, "%s", stacktrace.RootCause(err).Error())
+}
+
+func checkReadDatabase(ctx context.Context) checkResult {
+	readParameters, _ := flags.ReadConnectParameters()
+	readParameters.DBName = flags.RIDDatabaseName()
+	db, err := datastore.Dial(ctx, readParameters)
+	if err != nil {
+		return failed(err, "verify --cockroach_read_host and --cockroach_read_port")
+	}
+	defer db.Pool.Close()
+	if err := db.Pool.Ping(ctx); err != nil {
+		return failed(err, "verify --cockroach_read_host and --cockroach_read_port")
+	}
+	return ok("connected to %s:%d", readParameters.Host, readParameters.Port)
+}
+
+func checkDatabaseCertificates(_ context.Context) checkResult {
+	connectParameters := flags.ConnectParameters()
+	if connectParameters.SSL.Mode == "disable" {
+		return warning("set --cockroach_ssl_mode for production deployments", "TLS is disabled")
+	}
+	files := []string{
+		filepath.Join(connectParameters.SSL.Dir, "ca.crt"),
+		filepath.Join(connectParameters.SSL.Dir, fmt.Sprintf("client.%s.crt", connectParameters.Credentials.Username)),
+	}
+	return checkCertificateFiles(time.Now(), files)
+}
+
+// checkCertificateFiles verifies that the certificates in files are valid at
+// now and reports those expiring soon.
+func checkCertificateFiles(now time.Time, files []string) checkResult {
+	var soonest *x509.Certificate
+	for _, f := range files {
+		certs, err := readCertificates(f)
+		if err != nil {
+			return failed(err, "verify --cockroach_ssl_dir contains the CA and client certificates")
+		}
+		for _, cert := range certs {
+			if now.Before(cert.NotBefore) {
+				return failed(stacktrace.NewError("Certificate %s in %s is not valid before %s", cert.Subject, f, cert.NotBefore.Format(time.RFC3339)), "check the system clock or reissue the certificate")
+			}
+			if now.After(cert.NotAfter) {
+				return failed(stacktrace.NewError("Certificate %s in %s expired at %s", cert.Subject, f, cert.NotAfter.Format(time.RFC3339)), "renew the certificate")
+			}
+			if soonest == nil || cert.NotAfter.Before(soonest.NotAfter) {
+				soonest = cert
+			}
+		}
+	}
+	if soonest == nil {
+		return ok("no certificates to check")
+	}
+	if soonest.NotAfter.Sub(now) < certificateExpiryWarning {
+		return warning("renew the certificate", "certificate %s expires at %s", soonest.Subject, soonest.NotAfter.Format(time.RFC3339))
+	}
+	return ok("%d certificate file(s) valid until at least %s", len(files), soonest.NotAfter.Format(time.RFC3339))
+}
+
+func readCertificates(file string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error reading certificate file")
+	}
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Error parsing certificate in %s", file)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, stacktrace.NewError("No certificate found in %s", file)
+	}
+	return certs, nil
+}
+
+func checkAccessTokenKeys(ctx context.Context) checkResult {
+	const hint = "verify --public_key_files, --public_key_source or --jwks_endpoint and --jwks_key_ids"
+	keyResolver, err := createKeyResolver()
+	if err != nil {
+		return failed(err, hint)
+	}
+	if keyResolver == nil {
+		return warning(hint, "no key configured; access tokens will not be validated")
+	}
+	ctx, cancel := context.WithTimeout(ctx, *keyRefreshTimeout)
+	defer cancel()
+	keys, err := keyResolver.ResolveKeys(ctx)
+	if err != nil {
+		return failed(err, hint)
+	}
+	if len(keys) == 0 {
+		return failed(stacktrace.NewError("No key resolved"), hint)
+	}
+	if *jwtAudiences == "" {
+		return warning("set --accepted_jwt_audiences", "%d key(s) resolved but no accepted audience configured", len(keys))
+	}
+	return ok("%d key(s) resolved", len(keys))
+}
+
+func checkS2Configuration(_ context.Context) checkResult {
+	if err := geo.ConfigureCoverings(*minCellLevel, *maxCellLevel, *maxCoveringCells); err != nil {
+		return failed(err, "fix --s2_min_cell_level, --s2_max_cell_level or --s2_max_covering_cells")
+	}
+	if *coveringCacheSize < 0 {
+		return failed(stacktrace.NewError("Covering cache size %d is negative", *coveringCacheSize), "set --covering_cache_size to 0 or more")
+	}
+	if _, err := createLoadAdaptiveCoverings(); err != nil {
+		return failed(err, "fix --s2_load_adaptive_threshold or --s2_load_adaptive_max_levels")
+	}
+	coverer := geo.RegionCoverer
+	if geo.MultiLevelCoverings() {
+		return warning("make sure all the DSS instances sharing the database use the same cell levels",
+			"cell levels [%d, %d] with about %d cells per covering, covering cache size %d", coverer.MinLevel, coverer.MaxLevel, coverer.MaxCells, *coveringCacheSize)
+	}
+	return ok("cell levels [%d, %d], covering cache size %d", coverer.MinLevel, coverer.MaxLevel, *coveringCacheSize)
+}
+
+func checkServiceConfiguration(ctx context.Context) checkResult {
+	if _, err := createURLPolicy(); err != nil {
+		return failed(err, "fix --url_allowed_ports")
+	}
+	if _, err := createResultsPolicy(); err != nil {
+		return failed(err, "fix --max_search_results or --search_results_overflow")
+	}
+	if _, err := createPayloadPolicy(); err != nil {
+		return failed(err, "fix --max_request_body_bytes")
+	}
+	if _, err := createOwnerLabels(); err != nil {
+		return failed(err, "fix --metrics_owner_labels or --metrics_max_owner_labels")
+	}
+	if _, err := createWritePolicy(); err != nil {
+		return failed(err, "fix --rid_max_isa_area_km2, --rid_max_isa_duration, --rid_min_altitude, --rid_max_altitude or --rid_max_cells")
+	}
+	if _, err := createOwnerCodec(); err != nil {
+		return failed(err, "fix --owner_encryption_key_file")
+	}
+	if _, err := createWebhookDispatcher(ctx, zap.NewNop()); err != nil {
+		return failed(err, "fix --webhook_urls, --webhook_secret_file or --webhook_max_attempts")
+	}
+	if _, err := createConnectionPolicy(); err != nil {
+		return failed(err, "fix --http_idle_timeout, --http_max_connection_age or --http_max_connections")
+	}
+	if _, err := createResponseSigner(); err != nil {
+		return failed(err, "fix --response_signing_key_file")
+	}
+	if *readMaxStaleness < 0 {
+		return failed(stacktrace.NewError("Read max staleness %s is negative", *readMaxStaleness), "set --read_max_staleness to 0 or more")
+	}
+	if *slowQueryThreshold < 0 {
+		return failed(stacktrace.NewError("Slow query threshold %s is negative", *slowQueryThreshold), "set --slow_query_threshold to 0 or more")
+	}
+	if _, err := createDBHealth(); err != nil {
+		return failed(err, "fix --db_ping_interval or --db_max_ping_interval")
+	}
+	if _, err := createErrorMessages(); err != nil {
+		return failed(err, "fix --error_messages_file")
+	}
+	if _, err := createPublicPaths(); err != nil {
+		return failed(err, "fix --public_paths")
+	}
+	if _, err := createRetryHints(); err != nil {
+		return failed(err, "fix --retry_after_base, --retry_after_max or --retry_after_window")
+	}
+	if _, err := createAdminTLSConfig(); err != nil {
+		return failed(err, "fix --admin_auth, --admin_tls_cert_file, --admin_tls_key_file or --admin_client_ca_file")
+	}
+	if _, err := createRejectionCounter(); err != nil {
+		return failed(err, "set --usage_rejection_window to 0 or at least 1m")
+	}
+	if _, err := createChurnDetector(zap.NewNop()); err != nil {
+		return failed(err, "fix --isa_churn_max_updates_per_minute, --isa_churn_max_recreations_per_minute or --isa_churn_cooldown")
+	}
+	if _, err := cron.ParseStandard(*garbageCollectorSpec); err != nil {
+		return failed(err, "fix --garbage_collector_spec")
+	}
+	faultPlan, err := createFaultPlan()
+	if err != nil {
+		return failed(err, "fix --dangerously_inject_faults")
+	}
+	if faultPlan != nil {
+		return warning("unset --dangerously_inject_faults outside of failover drills", "injecting faults %s", faultPlan)
+	}
+	if *locality == "" {
+		return warning("set --locality to identify this instance in the pool", "no locality configured")
+	}
+	return ok("locality %s", *locality)
+}
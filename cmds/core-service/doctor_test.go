@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeCertificate(t *testing.T, notBefore, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "cert.crt")
+	require.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	return file
+}
+
+func TestCheckCertificateFiles(t *testing.T) {
+	now := time.Now()
+
+	valid := writeCertificate(t, now.Add(-time.Hour), now.Add(365*24*time.Hour))
+	require.Equal(t, checkOK, checkCertificateFiles(now, []string{valid}).Status)
+
+	expiring := writeCertificate(t, now.Add(-time.Hour), now.Add(24*time.Hour))
+	require.Equal(t, checkWarning, checkCertificateFiles(now, []string{valid, expiring}).Status)
+
+	expired := writeCertificate(t, now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+	require.Equal(t, checkFailed, checkCertificateFiles(now, []string{valid, expired}).Status)
+
+	notYetValid := writeCertificate(t, now.Add(time.Hour), now.Add(48*time.Hour))
+	require.Equal(t, checkFailed, checkCertificateFiles(now, []string{notYetValid}).Status)
+
+	require.Equal(t, checkFailed, checkCertificateFiles(now, []string{filepath.Join(t.TempDir(), "missing.crt")}).Status)
+}
+
+func TestRunSelfChecks(t *testing.T) {
+	var out bytes.Buffer
+	healthy := runSelfChecks(context.Background(), &out, []selfCheck{
+		{Name: "good", Run: func(context.Context) checkResult { return ok("fine") }},
+		{Name: "meh", Run: func(context.Context) checkResult { return warning("do something", "not great") }},
+	})
+	require.True(t, healthy)
+	require.Contains(t, out.String(), "[OK  ] good: fine")
+	require.Contains(t, out.String(), "-> do something")
+
+	healthy = runSelfChecks(context.Background(), &out, []selfCheck{
+		{Name: "bad", Run: func(context.Context) checkResult { return checkResult{Status: checkFailed, Detail: "broken"} }},
+	})
+	require.False(t, healthy)
+}
+
+func TestCheckRIDStoreURI(t *testing.T) {
+	defer func(uri string, retention time.Duration) { *ridStoreURI, *activityRetention = uri, retention }(*ridStoreURI, *activityRetention)
+
+	*ridStoreURI = "memory://"
+	result := checkRIDDatabase(context.Background())
+	require.Equal(t, checkOK, result.Status, result.Detail)
+
+	*activityRetention = time.Hour
+	result = checkRIDDatabase(context.Background())
+	require.Equal(t, checkFailed, result.Status)
+	require.Contains(t, result.Detail, "--rid_activity_retention")
+
+	*ridStoreURI = "spanner://projects/p/instances/i/databases/rid"
+	result = checkRIDDatabase(context.Background())
+	require.Equal(t, checkFailed, result.Status)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// localityHeader names the response header carrying the locality of the
+	// instance serving the request.
+	localityHeader = "DSS-Locality"
+	// instanceHeader names the response header carrying the ID of the
+	// instance serving the request.
+	instanceHeader = "DSS-Instance"
+)
+
+var (
+	instanceID      = flag.String("instance_id", "", "Identifier of this instance among the replicas of its pool, set in logs and, with --identity_headers, in responses; the hostname if empty")
+	identityHeaders = flag.Bool("identity_headers", false, "Sets the DSS-Locality and DSS-Instance headers, carrying --locality and --instance_id, in every response so that clients can tell which instance of a pool served a request")
+)
+
+// instanceIdentity identifies the instance serving requests among the
+// instances of the DSS pools.
+type instanceIdentity struct {
+	// Locality is the --locality of the instance, shared by the instances
+	// writing to the same database.
+	Locality string
+	// Instance identifies the instance among the replicas of its locality.
+	Instance string
+	// Headers is whether the identity is set in responses.
+	Headers bool
+}
+
+// createInstanceIdentity returns the identity of this instance configured by
+// flags.
+func createInstanceIdentity() instanceIdentity {
+	id := instanceIdentity{Locality: *locality, Instance: *instanceID, Headers: *identityHeaders}
+	if id.Instance == "" {
+		// The hostname, e.g. the pod name in Kubernetes, tells replicas apart.
+		id.Instance, _ = os.Hostname()
+	}
+	return id
+}
+
+// Fields returns the log fields identifying the instance, empty ones being
+// omitted.
+func (id instanceIdentity) Fields() []zap.Field {
+	var fields []zap.Field
+	if id.Locality != "" {
+		fields = append(fields, zap.String("locality", id.Locality))
+	}
+	if id.Instance != "" {
+		fields = append(fields, zap.String("instance", id.Instance))
+	}
+	return fields
+}
+
+// Middleware sets the identity of the instance in the responses of next if
+// enabled.
+func (id instanceIdentity) Middleware(next http.Handler) http.Handler {
+	if !id.Headers {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id.Locality != "" {
+			w.Header().Set(localityHeader, id.Locality)
+		}
+		if id.Instance != "" {
+			w.Header().Set(instanceHeader, id.Instance)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
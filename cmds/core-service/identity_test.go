@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestInstanceIdentityMiddleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	serve := func(id instanceIdentity) http.Header {
+		w := httptest.NewRecorder()
+		id.Middleware(ok).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthy", nil))
+		return w.Header()
+	}
+
+	h := serve(instanceIdentity{Locality: "us-east", Instance: "dss-0", Headers: true})
+	require.Equal(t, "us-east", h.Get(localityHeader))
+	require.Equal(t, "dss-0", h.Get(instanceHeader))
+
+	h = serve(instanceIdentity{Instance: "dss-0", Headers: true})
+	require.Empty(t, h.Values(localityHeader))
+	require.Equal(t, "dss-0", h.Get(instanceHeader))
+
+	h = serve(instanceIdentity{Locality: "us-east", Instance: "dss-0"})
+	require.Empty(t, h.Get(localityHeader))
+	require.Empty(t, h.Get(instanceHeader))
+}
+
+func TestInstanceIdentityFields(t *testing.T) {
+	require.Equal(t, []zap.Field{zap.String("locality", "us-east"), zap.String("instance", "dss-0")},
+		instanceIdentity{Locality: "us-east", Instance: "dss-0"}.Fields())
+	require.Empty(t, instanceIdentity{}.Fields())
+}
+
+func TestCreateInstanceIdentityDefaultsToHostname(t *testing.T) {
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	require.Equal(t, hostname, createInstanceIdentity().Instance)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/interuss/stacktrace"
+	"golang.org/x/net/netutil"
+)
+
+// unixSocketPrefix designates listen addresses that are Unix domain socket
+// paths rather than TCP addresses.
+const unixSocketPrefix = "unix:"
+
+// listenAddresses is a repeatable flag collecting the addresses the HTTP
+// server listens on.
+type listenAddresses []string
+
+func (a *listenAddresses) String() string {
+	return strings.Join(*a, ",")
+}
+
+func (a *listenAddresses) Set(value string) error {
+	if value == "" || value == unixSocketPrefix {
+		return stacktrace.NewError("Listen address must not be empty")
+	}
+	*a = append(*a, value)
+	return nil
+}
+
+var listenAddrs listenAddresses
+
+func init() {
+	flag.Var(&listenAddrs, "listen", "Address to listen on for incoming connections: a TCP address (host:port) or unix:<path> for a Unix domain socket; may be repeated to listen on several addresses, overrides --addr")
+}
+
+// serverAddresses returns the addresses the HTTP server listens on.
+func serverAddresses() []string {
+	if len(listenAddrs) > 0 {
+		return listenAddrs
+	}
+	return []string{*address}
+}
+
+// listen returns a listener on address, which is either a TCP address or a
+// Unix domain socket path prefixed with unix:, accepting at most
+// --http_max_connections concurrent connections. A socket left behind by a
+// previous instance at that path is removed first.
+func listen(address string) (net.Listener, error) {
+	l, err := listenUnlimited(address)
+	if err != nil || *maxConnections <= 0 {
+		return l, err
+	}
+	return netutil.LimitListener(l, *maxConnections), nil
+}
+
+func listenUnlimited(address string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(address, unixSocketPrefix)
+	if !ok {
+		lc := net.ListenConfig{KeepAlive: *tcpKeepAlive}
+		l, err := lc.Listen(context.Background(), "tcp", address)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Error listening on %s", address)
+		}
+		return l, nil
+	}
+	if info, err := os.Stat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, stacktrace.NewError("Cannot listen on %s: file exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, stacktrace.Propagate(err, "Error removing stale socket %s", path)
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error listening on %s", address)
+	}
+	return l, nil
+}
+
+// listenAll returns listeners on all addresses, or closes those already
+// opened if one of them fails.
+func listenAll(addresses []string) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(addresses))
+	for _, a := range addresses {
+		l, err := listen(a)
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, err // No need to Propagate this error as this stack layer does not add useful information
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
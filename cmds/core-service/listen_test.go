@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenAddressesFlag(t *testing.T) {
+	var addrs listenAddresses
+	require.NoError(t, addrs.Set(":8080"))
+	require.NoError(t, addrs.Set("unix:/run/dss.sock"))
+	require.Error(t, addrs.Set(""))
+	require.Error(t, addrs.Set("unix:"))
+	require.Equal(t, listenAddresses{":8080", "unix:/run/dss.sock"}, addrs)
+	require.Equal(t, ":8080,unix:/run/dss.sock", addrs.String())
+}
+
+func TestServeOnTCPAndUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "dss.sock")
+	listeners, err := listenAll([]string{"127.0.0.1:0", unixSocketPrefix + socket})
+	require.NoError(t, err)
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})}
+	defer server.Close()
+	for _, l := range listeners {
+		go func(l net.Listener) { _ = server.Serve(l) }(l)
+	}
+
+	clients := []*http.Client{
+		http.DefaultClient,
+		{Transport: &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		}}},
+	}
+	for _, c := range clients {
+		resp, err := c.Get("http://" + listeners[0].Addr().String())
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+		require.Equal(t, "ok", string(body))
+	}
+}
+
+func TestListenReplacesStaleSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "dss.sock")
+	stale, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	l, err := listen(unixSocketPrefix + socket)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+}
+
+func TestListenRefusesToReplaceRegularFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "dss.sock")
+	require.NoError(t, os.WriteFile(file, nil, 0600))
+
+	_, err := listen(unixSocketPrefix + file)
+	require.Error(t, err)
+	_, err = os.Stat(file)
+	require.NoError(t, err)
+}
@@ -1,14 +1,18 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
+	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
@@ -24,41 +28,137 @@ import (
 	"github.com/interuss/dss/pkg/auth"
 	aux "github.com/interuss/dss/pkg/aux_"
 	"github.com/interuss/dss/pkg/build"
+	"github.com/interuss/dss/pkg/consistency"
+	"github.com/interuss/dss/pkg/cost"
 	"github.com/interuss/dss/pkg/datastore"
 	"github.com/interuss/dss/pkg/datastore/flags" // Force command line flag registration
+	"github.com/interuss/dss/pkg/datastore/owners"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/etag"
+	"github.com/interuss/dss/pkg/faults"
+	"github.com/interuss/dss/pkg/geo"
+	"github.com/interuss/dss/pkg/headers"
+	"github.com/interuss/dss/pkg/limits"
 	"github.com/interuss/dss/pkg/logging"
+	"github.com/interuss/dss/pkg/metrics"
+	"github.com/interuss/dss/pkg/payload"
+	"github.com/interuss/dss/pkg/retry"
 	"github.com/interuss/dss/pkg/rid/application"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/dss/pkg/rid/repos"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
 	rid_v1 "github.com/interuss/dss/pkg/rid/server/v1"
 	rid_v2 "github.com/interuss/dss/pkg/rid/server/v2"
+	ridstore "github.com/interuss/dss/pkg/rid/store"
 	ridc "github.com/interuss/dss/pkg/rid/store/cockroach"
+	_ "github.com/interuss/dss/pkg/rid/store/memory" // Registers the memory remote ID store
 	"github.com/interuss/dss/pkg/scd"
 	scdc "github.com/interuss/dss/pkg/scd/store/cockroach"
+	"github.com/interuss/dss/pkg/signing"
 	"github.com/interuss/dss/pkg/version"
 	"github.com/interuss/dss/pkg/versioning"
+	"github.com/interuss/dss/pkg/webhooks"
 	"github.com/interuss/stacktrace"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
 )
 
 var (
-	address           = flag.String("addr", ":8080", "Local address that the service binds to and listens on for incoming connections")
+	address           = flag.String("addr", ":8080", "Local address that the service binds to and listens on for incoming connections, unless --listen is set")
 	enableSCD         = flag.Bool("enable_scd", false, "Enables the Strategic Conflict Detection API")
 	allowHTTPBaseUrls = flag.Bool("allow_http_base_urls", false, "Enables http scheme for Strategic Conflict Detection API")
 	enableHTTP        = flag.Bool("enable_http", false, "DEPRECATED (replaced by allow_http_base_urls): Enables http scheme for Strategic Conflict Detection API")
 	timeout           = flag.Duration("server timeout", 10*time.Second, "Default timeout for server calls")
 	locality          = flag.String("locality", "", "self-identification string used as CRDB table writer column")
 
+	urlRejectIPLiterals = flag.Bool("url_reject_ip_literals", false, "Rejects remote ID flights and callback URLs whose host is an IP address")
+	urlAllowedPorts     = flag.String("url_allowed_ports", "", "Comma-separated ports or port ranges (e.g. 443,8443-8453) allowed in remote ID flights and callback URLs; any port is allowed if empty")
+	urlMaxLength        = flag.Int("url_max_length", 0, "Maximum length of remote ID flights and callback URLs; unlimited if 0")
+
+	ridMaxISAArea     = flag.Float64("rid_max_isa_area_km2", 0, "Maximum area in km² of the cells covering an ISA, larger ISAs being rejected; unlimited if 0")
+	ridMaxISADuration = flag.Duration("rid_max_isa_duration", 0, "Maximum duration of an ISA, longer ISAs being rejected; unlimited if 0")
+	ridMinAltitude    = flag.String("rid_min_altitude", "", "Minimum altitude_lo in meters of ISAs and subscriptions, lower ones being rejected; unlimited if empty")
+	ridMaxAltitude    = flag.String("rid_max_altitude", "", "Maximum altitude_hi in meters of ISAs and subscriptions, higher ones being rejected; unlimited if empty")
+	ridMaxCells       = flag.Int("rid_max_cells", 0, "Maximum number of S2 cells covering the area of an ISA or subscription, ISAs and subscriptions covered by more cells being rejected; unlimited if 0")
+
 	logFormat            = flag.String("log_format", logging.DefaultFormat, "The log format in {json, console}")
 	logLevel             = flag.String("log_level", logging.DefaultLevel.String(), "The log level")
 	dumpRequests         = flag.Bool("dump_requests", false, "Log full HTTP request and response (note: will dump sensitive information to logs; intended only for debugging and/or development)")
 	profServiceName      = flag.String("gcp_prof_service_name", "", "Service name for the Go profiler")
+	metricsAddr          = flag.String("metrics_addr", "", "Local address on which Prometheus metrics are served at /metrics, and liveness, readiness and startup probes at /healthz, /readyz and /startupz; disabled if empty")
+	metricsOwnerLabels   = flag.String("metrics_owner_labels", "", "Comma-separated owners labelling request metrics individually, other owners being counted as 'other'")
+	metricsMaxOwners     = flag.Int("metrics_max_owner_labels", 0, "Number of owners, in order of first request, labelling request metrics individually when --metrics_owner_labels is empty, other owners being counted as 'other'; owners are not labelled if 0")
+	corsAllowedOrigins   = flag.String("cors_allowed_origins", "", "Comma-separated origins allowed to make cross-origin requests from browsers ('*' for any); CORS is disabled if empty")
+	corsAllowedMethods   = flag.String("cors_allowed_methods", strings.Join(headers.DefaultAllowedMethods, ","), "Comma-separated methods allowed in cross-origin requests")
+	corsAllowedHeaders   = flag.String("cors_allowed_headers", strings.Join(headers.DefaultAllowedHeaders, ","), "Comma-separated request headers allowed in cross-origin requests")
+	corsMaxAge           = flag.Duration("cors_max_age", 10*time.Minute, "Duration for which browsers may cache the result of a CORS preflight request")
+	securityHeaders      = flag.Bool("security_headers", true, "Adds headers instructing browsers not to sniff content types, frame responses or send referrers")
+	coveringCacheSize    = flag.Int("covering_cache_size", geo.DefaultCoveringCacheSize, "Number of search area coverings to cache; 0 disables caching")
+	minCellLevel         = flag.Int("s2_min_cell_level", geo.DefaultMinimumCellLevel, "Coarsest S2 cell level of area coverings")
+	maxCellLevel         = flag.Int("s2_max_cell_level", geo.DefaultMaximumCellLevel, "Finest S2 cell level of area coverings; coverings mixing cell levels are searched by cell ID ranges, which the inverted indices of cells do not serve")
+	maxCoveringCells     = flag.Int("s2_max_covering_cells", 0, "Approximate number of cells of area coverings when --s2_max_cell_level exceeds --s2_min_cell_level, large areas being covered with coarser cells; areas are covered at --s2_min_cell_level if 0")
+	loadAdaptiveRequests = flag.Int("s2_load_adaptive_threshold", 0, "Number of requests in flight beyond which the coverings of remote ID searches are coarsened by one cell level, and by one more per further such number of requests, which requires --s2_max_cell_level to exceed --s2_min_cell_level; never coarsened if 0")
+	loadAdaptiveLevels   = flag.Int("s2_load_adaptive_max_levels", 0, "Largest number of cell levels by which the coverings of remote ID searches are coarsened under load; bounded by --s2_min_cell_level alone if 0")
+	strictSchemaVersion  = flag.Bool("strict_schema_version", false, "Refuses to start unless the database schemas are exactly at the versions this build is written for, i.e. those of the latest db-manager migrations, rather than accepting any supported version; the service never changes schemas itself")
+	checkOnly            = flag.Bool("check", false, "Validates the runtime environment (databases, keys, certificates, configuration), reports the outcome and exits with a non-zero status on failure instead of serving requests")
+	ridStoreURI          = flag.String("rid_store_uri", "", "URI of the remote ID store, whose scheme selects the storage backend among those registered: cockroach, postgres or postgresql (CockroachDB or Yugabyte, e.g. cockroach://root@crdb:26257/rid?sslmode=disable) or memory (lost on exit, for development); the database flags locate a CockroachDB or Yugabyte store if empty")
+	notificationCounters = flag.Int("rid_notification_counter_shards", 0, "Number of counters per remote ID subscription recording notification index increments, spreading the contention of popular subscriptions across rows; notification indices are incremented in subscription rows if 0")
+	notificationFoldSpec = flag.String("rid_notification_counter_fold_spec", "@every 1m", "Schedule, in robfig/cron format, at which notification counters are folded into remote ID subscriptions")
+	deleteEndedSubs      = flag.Duration("rid_delete_subscriptions_after", 0, "Time after their end at which remote ID subscriptions of any writer, including instances no longer running, are deleted; only the subscriptions written by this instance are deleted, by the garbage collector, if 0")
+	activityRetention    = flag.Duration("rid_activity_retention", 0, "Duration for which the creations, updates and deletions of remote ID entities are kept to report the activity of the pool through /aux/v1/rid/activity; not recorded if 0")
+	idempotencyRetention = flag.Duration("rid_idempotency_key_retention", 0, "Duration for which the responses to remote ID writes sent with an Idempotency-Key header are kept to answer their retries; Idempotency-Key headers are ignored if 0")
+	enableMapUI          = flag.Bool("enable_map_ui", false, "Serves a map of the remote ID coverage of the pool at /aux/v1/map/, rendering the tiles served to dss.admin access tokens by /aux/v1/rid/tiles/{z}/{x}/{y}")
+	hedgeReadsAfter      = flag.Duration("rid_hedge_reads_after", 0, "Time after which remote ID gets and searches not yet completed are attempted a second time, against the primary database if --cockroach_read_host is set, the first successful attempt being returned; reads are attempted once if 0")
+	maxSearchResults     = flag.Int("max_search_results", 0, "Maximum number of entities returned by a search, which clients may lower with the DSS-Max-Results request header; searches are only bounded by the store limit if 0")
+	searchOverflow       = flag.String("search_results_overflow", string(limits.OverflowTruncate), "How searches finding more than --max_search_results entities are handled: truncate (the response carries the DSS-Results-Truncated header) or reject (413 instructing the client to narrow its search)")
+	maxRequestBytes      = flag.Int64("max_request_body_bytes", 0, "Maximum size in bytes of request bodies, after decompression, larger requests being rejected; unlimited if 0")
+	conditionalRequests  = flag.Bool("conditional_requests", false, "Tags successful GET responses with an ETag header, answering requests with a matching If-None-Match header with 304, and rejects writes whose If-Match header does not match the entity targeted with 412")
+	gzipResponses        = flag.Bool("gzip_responses", false, "Compresses responses with gzip for clients accepting it; gzip-compressed request bodies are accepted regardless")
+	dbUnavailableAfter   = flag.Int("db_unavailable_after_failed_pings", 0, "Number of consecutive failed pings of the database after which requests fail fast with 503 and a Retry-After header until a ping succeeds; disabled if 0")
+	slowQueryThreshold   = flag.Duration("slow_query_threshold", 0, "Duration from which database queries are logged, by name and with their IDs, rows and transaction retries, along with the fields of the request they serve; queries are not logged if 0")
+	dbPingInterval       = flag.Duration("db_ping_interval", time.Second, "Period of database pings monitoring its availability")
+	dbMaxPingInterval    = flag.Duration("db_max_ping_interval", 30*time.Second, "Maximum period of database pings while the database is unavailable, pings backing off exponentially from --db_ping_interval")
+	retryAfterBase       = flag.Duration("retry_after_base", time.Second, "Retry-After hinted by 429 and 503 responses while they are rare, growing with the square root of their number over --retry_after_window and spread at random; responses only carry the Retry-After set by the handler if 0")
+	retryAfterMax        = flag.Duration("retry_after_max", time.Minute, "Maximum Retry-After derived from the rate of 429 and 503 responses, before random spread")
+	retryAfterWindow     = flag.Duration("retry_after_window", time.Minute, "Time constant with which past 429 and 503 responses stop lengthening the Retry-After hints")
+	errorMessagesFile    = flag.String("error_messages_file", "", "Path to a JSON catalog translating the messages of errors returned to clients, by language tag, into the languages accepted by their Accept-Language header; messages are in English if empty")
+	isaChurnMaxUpdates   = flag.Int("isa_churn_max_updates_per_minute", 0, "Number of updates of an ISA per minute through this instance beyond which it churns, which is counted in dss_rid_isa_churn_detections_total and logged; unbounded if 0")
+	isaChurnMaxRecreates = flag.Int("isa_churn_max_recreations_per_minute", 0, "Number of creations per minute through this instance of an ISA deleted less than a minute before beyond which it churns; unbounded if 0")
+	rejectionWindow      = flag.Duration("usage_rejection_window", time.Hour, "Period over which the requests of each owner rejected with a 4xx status are counted by each instance and reported to the owner by /aux/v1/rid/usage; rejections are not counted if 0")
+	isaChurnCooldown     = flag.Duration("isa_churn_cooldown", 0, "Duration during which the writes of a churning ISA are rejected with 429 Too Many Requests; churn is only detected if 0")
+	readMaxStaleness     = flag.Duration("read_max_staleness", 10*time.Second, "Maximum replication lag of --cockroach_read_host, during which the reads of requests passing back the X-DSS-Consistency-Token of a write are served by the primary database")
+	ownerKeyFile         = flag.String("owner_encryption_key_file", "", "Path to a file holding a secret key of at least 32 bytes with which owners are encrypted in the remote ID database so that its dumps do not reveal USS identities; owners are stored in plain text if empty")
+	injectFaults         = flag.String("dangerously_inject_faults", "", "DANGEROUS, for failover drills in staging pools only: comma-separated faults injected into a percentage of requests, as kind=value@percent with kind latency (duration), error (HTTP status) or db_latency (duration added to each database query), e.g. latency=500ms@10,error=503@5; no fault is injected if empty")
+	signingKeyFile       = flag.String("response_signing_key_file", "", "Path to a PEM-encoded ECDSA, RSA or Ed25519 private key with which responses are signed, a detached JWS of their body bound to the request and time being set in the DSS-Signature header, so that clients can prove what the DSS responded; responses are not signed if empty")
+	signingKeyID         = flag.String("response_signing_key_id", "", "Key ID set in the signatures of responses, e.g. to rotate --response_signing_key_file")
+	webhookURLs          = flag.String("webhook_urls", "", "Comma-separated http or https URLs to which every creation, update and deletion of remote ID entities made through this instance is POSTed as a JSON event signed with --webhook_secret_file, e.g. to feed operator dashboards; no event is delivered if empty")
+	webhookSecretFile    = flag.String("webhook_secret_file", "", "Path to a file holding the secret with which webhook deliveries are signed, an HMAC-SHA256 of the DSS-Webhook-Timestamp header, a dot and the body being set in the DSS-Webhook-Signature header; required with --webhook_urls")
+	webhookMaxAttempts   = flag.Int("webhook_max_attempts", 5, "Number of attempts to deliver an event to a webhook, retrying network errors and 408, 429 and 5xx responses with exponential backoff, before giving up")
 	garbageCollectorSpec = flag.String("garbage_collector_spec", "@every 30m", "Garbage collector schedule. The value must follow robfig/cron format. See https://godoc.org/github.com/robfig/cron#hdr-Usage for more detail.")
 
-	pkFile            = flag.String("public_key_files", "", "Path to public Keys to use for JWT decoding, separated by commas.")
-	jwksEndpoint      = flag.String("jwks_endpoint", "", "URL pointing to an endpoint serving JWKS")
-	jwksKeyIDs        = flag.String("jwks_key_ids", "", "IDs of a set of key in a JWKS, separated by commas")
-	keyRefreshTimeout = flag.Duration("key_refresh_timeout", 1*time.Minute, "Timeout for refreshing keys for JWT verification")
-	jwtAudiences      = flag.String("accepted_jwt_audiences", "", "comma-separated acceptable JWT `aud` claims")
+	pkFile             = flag.String("public_key_files", "", "Path to public Keys to use for JWT decoding, separated by commas.")
+	publicKeySource    = flag.String("public_key_source", "", "URI of a secret holding PEM-encoded public keys to use for JWT decoding, refreshed every --key_refresh_timeout: gcpsm://<project>/<secret>[/<version>] for GCP Secret Manager or vault://<host>[:<port>]/<path>#<field> for HashiCorp Vault (authenticated with VAULT_TOKEN)")
+	jwksEndpoint       = flag.String("jwks_endpoint", "", "URL pointing to an endpoint serving JWKS")
+	jwksKeyIDs         = flag.String("jwks_key_ids", "", "IDs of a set of key in a JWKS, separated by commas")
+	keyCacheFile       = flag.String("key_cache_file", "", "Path to a file caching the keys fetched from --jwks_endpoint or --public_key_source, which keep verifying access tokens while they cannot be fetched, including after a restart; keys are not cached if empty")
+	keyMaxStaleness    = flag.Duration("key_cache_max_staleness", 24*time.Hour, "Maximum time since cached keys were last fetched for them to be used; unlimited if 0")
+	keyRefreshTimeout  = flag.Duration("key_refresh_timeout", 1*time.Minute, "Timeout for refreshing keys for JWT verification")
+	jwtAudiences       = flag.String("accepted_jwt_audiences", "", "comma-separated acceptable JWT `aud` claims")
+	allowImpersonation = flag.Bool("allow_owner_impersonation", false, "Lets access tokens with the dss.admin.impersonate_owner scope act on behalf of the owner named in the X-Impersonate-Owner request header; every impersonation is logged")
+	rejectReplays      = flag.Bool("reject_replayed_write_tokens", false, "Requires access tokens of write operations to carry a jti claim and rejects their reuse on this instance until they expire")
+	ownerAliasRefresh  = flag.Duration("owner_alias_refresh_interval", time.Minute, "Period at which the owner aliases, mapping the subjects of access tokens to canonical owners, are reloaded from the remote ID database to pick up changes made through other instances")
+	clockSkewLeeway    = flag.Duration("jwt_clock_skew_leeway", 0, "Tolerance for the clocks of access token issuers when validating the exp, nbf and iat claims, e.g. 30s for issuers whose clocks are not tightly synchronized")
+	tokenCacheSize     = flag.Int("token_cache_size", 0, "Number of verified access tokens whose claims are cached, by hash, until they expire or the verification keys change, so that tokens presented repeatedly are verified once; tokens are verified on every request if 0")
+	publicPaths        = flag.String("public_paths", strings.Join(auth.DefaultPublicPaths, ","), "Comma-separated path patterns, e.g. /aux/v1/*, of the operations requiring no scopes which are served without verifying access tokens, e.g. to health checkers; every operation verifies tokens if empty")
+
+	adminAddr         = flag.String("admin_addr", "", "Local address, a TCP address or unix:<path>, on which the operations administering the pool (reconciliation, expiry and transfer of ISAs, activity and coverage statistics, label searches, owner aliases) and the map UI are served with their own authorization instead of on --addr; they are served along with the other operations if empty")
+	adminAuth         = flag.String("admin_auth", adminAuthScopes, "How clients of --admin_addr are authorized: scopes (access tokens with the dss.admin or dss.transfer_ownership scopes and an audience in --admin_accepted_jwt_audiences, over mutual TLS too if --admin_client_ca_file is set) or mtls (client certificates verified with --admin_client_ca_file, granted both scopes and identified by their common name)")
+	adminJWTAudiences = flag.String("admin_accepted_jwt_audiences", "", "Comma-separated acceptable JWT `aud` claims of the access tokens of --admin_addr; those of --accepted_jwt_audiences if empty")
+	adminTLSCertFile  = flag.String("admin_tls_cert_file", "", "Path to the PEM-encoded certificate with which --admin_addr serves HTTPS; plain HTTP is served if empty")
+	adminTLSKeyFile   = flag.String("admin_tls_key_file", "", "Path to the PEM-encoded private key of --admin_tls_cert_file")
+	adminClientCAFile = flag.String("admin_client_ca_file", "", "Path to the PEM-encoded certificates of the authorities whose client certificates --admin_addr requires, over HTTPS; client certificates are not requested if empty")
 )
 
 const (
@@ -86,11 +186,45 @@ func getDBStats(ctx context.Context, db *datastore.Datastore, databaseName strin
 }
 
 func createKeyResolver() (auth.KeyResolver, error) {
+	resolver, err := createUncachedKeyResolver()
+	// Keys read from local files need no cache.
+	if err != nil || resolver == nil || *keyCacheFile == "" || *pkFile != "" {
+		return resolver, err
+	}
+	if *keyMaxStaleness < 0 {
+		return nil, stacktrace.NewError("--key_cache_max_staleness must not be negative")
+	}
+	return &auth.CachingKeyResolver{
+		Resolver:     resolver,
+		File:         *keyCacheFile,
+		MaxStaleness: *keyMaxStaleness,
+		Logger:       logging.Logger,
+	}, nil
+}
+
+// createPublicPaths returns the path patterns of --public_paths.
+func createPublicPaths() ([]string, error) {
+	paths := headers.SplitList(*publicPaths)
+	for _, pattern := range paths {
+		if _, err := path.Match(pattern, "/"); err != nil {
+			return nil, stacktrace.Propagate(err, "Invalid public path pattern %s", pattern)
+		}
+	}
+	return paths, nil
+}
+
+func createUncachedKeyResolver() (auth.KeyResolver, error) {
 	switch {
 	case *pkFile != "":
 		return &auth.FromFileKeyResolver{
 			KeyFiles: strings.Split(*pkFile, ","),
 		}, nil
+	case *publicKeySource != "":
+		source, err := auth.SecretSourceFromURI(*publicKeySource)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Error parsing --public_key_source")
+		}
+		return &auth.SecretKeyResolver{Source: source}, nil
 	case *jwksEndpoint != "" && *jwksKeyIDs != "":
 		u, err := url.Parse(*jwksEndpoint)
 		if err != nil {
@@ -106,76 +240,572 @@ func createKeyResolver() (auth.KeyResolver, error) {
 	}
 }
 
-func createRIDServers(ctx context.Context, locality string, logger *zap.Logger) (*rid_v1.Server, *rid_v2.Server, error) {
+func createCapabilities() aux.Capabilities {
+	capabilities := aux.Capabilities{SCDEnabled: *enableSCD, MaxResults: *maxSearchResults}
+	for feature, enabled := range map[string]bool{
+		aux.FeatureOwnerImpersonation:         *allowImpersonation,
+		aux.FeatureWriteTokenReplayProtection: *rejectReplays,
+		aux.FeatureNotificationCounters:       *notificationCounters > 0,
+		aux.FeatureActivity:                   *activityRetention > 0,
+		aux.FeatureCORS:                       *corsAllowedOrigins != "",
+		aux.FeatureMapUI:                      *enableMapUI,
+	} {
+		if enabled {
+			capabilities.Features = append(capabilities.Features, feature)
+		}
+	}
+	sort.Strings(capabilities.Features)
+	return capabilities
+}
+
+func createResultsPolicy() (limits.Policy, error) {
+	policy := limits.Policy{
+		MaxResults: *maxSearchResults,
+		Overflow:   limits.Overflow(*searchOverflow),
+	}
+	if err := policy.Validate(); err != nil {
+		return limits.Policy{}, stacktrace.Propagate(err, "Error validating --max_search_results and --search_results_overflow")
+	}
+	return policy, nil
+}
+
+// createPayloadPolicy returns the policy for request and response bodies.
+func createPayloadPolicy() (payload.Policy, error) {
+	policy := payload.Policy{
+		MaxRequestBytes:   *maxRequestBytes,
+		CompressResponses: *gzipResponses,
+	}
+	if err := policy.Validate(); err != nil {
+		return payload.Policy{}, stacktrace.Propagate(err, "Error validating --max_request_body_bytes")
+	}
+	return policy, nil
+}
+
+// createOwnerLabels returns the owner labels of request metrics.
+func createOwnerLabels() (*metrics.OwnerLabels, error) {
+	ownerLabels := &metrics.OwnerLabels{}
+	if err := configureOwnerLabels(ownerLabels); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return ownerLabels, nil
+}
+
+// configureOwnerLabels applies the flags selecting the owners labelled in
+// request metrics to ownerLabels, which keeps the owners it already labels.
+func configureOwnerLabels(ownerLabels *metrics.OwnerLabels) error {
+	if err := ownerLabels.Configure(headers.SplitList(*metricsOwnerLabels), *metricsMaxOwners); err != nil {
+		return stacktrace.Propagate(err, "Error validating --metrics_owner_labels and --metrics_max_owner_labels")
+	}
+	return nil
+}
+
+// createFaultPlan returns the faults injected into requests, or nil if none.
+func createFaultPlan() (*faults.Plan, error) {
+	plan, err := faults.Parse(*injectFaults)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error parsing --dangerously_inject_faults")
+	}
+	return plan, nil
+}
+
+// createResponseSigner returns the signer of responses, or nil if they are not
+// signed.
+func createResponseSigner() (*signing.Signer, error) {
+	if *signingKeyFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(*signingKeyFile)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error reading --response_signing_key_file")
+	}
+	key, err := signing.ParsePrivateKey(data)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error parsing --response_signing_key_file")
+	}
+	signer, err := signing.NewSigner(key, *signingKeyID)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error creating response signer")
+	}
+	return signer, nil
+}
+
+// createDBHealth returns the monitor of the database availability, or nil if
+// disabled.
+func createDBHealth() (*datastore.Health, error) {
+	if *dbUnavailableAfter <= 0 {
+		return nil, nil
+	}
+	if *dbPingInterval <= 0 || *dbMaxPingInterval < *dbPingInterval {
+		return nil, stacktrace.NewError("--db_ping_interval must be positive and not exceed --db_max_ping_interval")
+	}
+	return &datastore.Health{
+		Threshold:   *dbUnavailableAfter,
+		Interval:    *dbPingInterval,
+		MaxInterval: *dbMaxPingInterval,
+	}, nil
+}
+
+func createRetryHints() (*retry.Hints, error) {
+	if *retryAfterBase <= 0 {
+		return nil, nil
+	}
+	if *retryAfterMax < *retryAfterBase || *retryAfterWindow <= 0 {
+		return nil, stacktrace.NewError("--retry_after_max must not be less than --retry_after_base, and --retry_after_window must be positive")
+	}
+	return &retry.Hints{
+		Base:   *retryAfterBase,
+		Max:    *retryAfterMax,
+		Window: *retryAfterWindow,
+	}, nil
+}
+
+// createChurnDetector returns the detector of churning ISAs, nil if no
+// threshold is set.
+func createChurnDetector(logger *zap.Logger) (*ridserver.ChurnDetector, error) {
+	detector := &ridserver.ChurnDetector{
+		MaxUpdates:     *isaChurnMaxUpdates,
+		MaxRecreations: *isaChurnMaxRecreates,
+		Cooldown:       *isaChurnCooldown,
+		Logger:         logger,
+	}
+	if err := detector.Validate(); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if detector.MaxUpdates == 0 && detector.MaxRecreations == 0 {
+		return nil, nil
+	}
+	return detector, nil
+}
+
+// createRejectionCounter returns the counter of the rejected requests of
+// each owner, nil if they are not counted.
+func createRejectionCounter() (*ridserver.RejectionCounter, error) {
+	if *rejectionWindow == 0 {
+		return nil, nil
+	}
+	counter := &ridserver.RejectionCounter{Window: *rejectionWindow}
+	if err := counter.Validate(); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return counter, nil
+}
+
+// createLoadAdaptiveCoverings returns the coarsening of the coverings of
+// remote ID searches under load, nil if they are never coarsened. Coverings
+// must be configured first.
+func createLoadAdaptiveCoverings() (*ridserver.LoadAdaptiveCoverings, error) {
+	coverings := &ridserver.LoadAdaptiveCoverings{
+		Threshold: *loadAdaptiveRequests,
+		MaxLevels: *loadAdaptiveLevels,
+	}
+	if err := coverings.Validate(); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if coverings.Threshold == 0 {
+		return nil, nil
+	}
+	return coverings, nil
+}
+
+func createErrorMessages() (*dsserr.Messages, error) {
+	if *errorMessagesFile == "" {
+		return nil, nil
+	}
+	return dsserr.LoadMessages(*errorMessagesFile)
+}
+
+func createOwnerCodec() (owners.Codec, error) {
+	if *ownerKeyFile == "" {
+		return owners.Plain{}, nil
+	}
+	key, err := os.ReadFile(*ownerKeyFile)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error reading --owner_encryption_key_file")
+	}
+	codec, err := owners.NewSealed(key)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error creating owner codec")
+	}
+	return codec, nil
+}
+
+// createWebhookDispatcher returns the dispatcher delivering the changes of
+// remote ID entities to webhooks until ctx is done, or nil if no webhook is
+// configured.
+func createWebhookDispatcher(ctx context.Context, logger *zap.Logger) (*webhooks.Dispatcher, error) {
+	if *webhookURLs == "" {
+		return nil, nil
+	}
+	if *webhookSecretFile == "" {
+		return nil, stacktrace.NewError("--webhook_secret_file is required with --webhook_urls")
+	}
+	if *webhookMaxAttempts <= 0 {
+		return nil, stacktrace.NewError("--webhook_max_attempts must be positive")
+	}
+	secret, err := os.ReadFile(*webhookSecretFile)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error reading --webhook_secret_file")
+	}
+	dispatcher, err := webhooks.NewDispatcher(ctx, webhooks.Config{
+		URLs:        strings.Split(*webhookURLs, ","),
+		Secret:      bytes.TrimSpace(secret),
+		MaxAttempts: *webhookMaxAttempts,
+	}, logger)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error creating webhook dispatcher")
+	}
+	return dispatcher, nil
+}
+
+// createWritePolicy returns the policy vetting the remote ID entities written,
+// or nil if no limit is configured.
+func createWritePolicy() (application.WritePolicy, error) {
+	if *ridMaxISAArea < 0 || *ridMaxISADuration < 0 || *ridMaxCells < 0 {
+		return nil, stacktrace.NewError("--rid_max_isa_area_km2, --rid_max_isa_duration and --rid_max_cells must not be negative")
+	}
+	limits := application.Limits{
+		MaxISAAreaKm2:  *ridMaxISAArea,
+		MaxISADuration: *ridMaxISADuration,
+		MaxCells:       *ridMaxCells,
+	}
+	for _, a := range []struct {
+		flag  string
+		value string
+		limit **float32
+	}{
+		{"--rid_min_altitude", *ridMinAltitude, &limits.MinAltitude},
+		{"--rid_max_altitude", *ridMaxAltitude, &limits.MaxAltitude},
+	} {
+		if a.value == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(a.value, 32)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Error parsing %s", a.flag)
+		}
+		altitude := float32(f)
+		*a.limit = &altitude
+	}
+	if limits.MinAltitude != nil && limits.MaxAltitude != nil && *limits.MinAltitude > *limits.MaxAltitude {
+		return nil, stacktrace.NewError("--rid_min_altitude must not exceed --rid_max_altitude")
+	}
+	if limits == (application.Limits{}) {
+		return nil, nil
+	}
+	return limits, nil
+}
+
+func createURLPolicy() (ridmodels.URLPolicy, error) {
+	ports, err := ridmodels.PortRangesFromString(*urlAllowedPorts)
+	if err != nil {
+		return ridmodels.URLPolicy{}, stacktrace.Propagate(err, "Error parsing --url_allowed_ports")
+	}
+	return ridmodels.URLPolicy{
+		AllowHTTP:        *allowHTTPBaseUrls,
+		RejectIPLiterals: *urlRejectIPLiterals,
+		AllowedPorts:     ports,
+		MaxLength:        *urlMaxLength,
+	}, nil
+}
+
+// openRIDStore returns the remote ID store located by --rid_store_uri or,
+// if not set, the CockroachDB store of the database flags.
+func openRIDStore(ctx context.Context, logger *zap.Logger) (ridstore.Store, error) {
+	if *ridStoreURI != "" {
+		if _, ok := flags.ReadConnectParameters(); ok {
+			return nil, stacktrace.NewError("--cockroach_read_host is not supported with --rid_store_uri")
+		}
+		ridStore, err := ridstore.Open(ctx, *ridStoreURI, logger)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Failed to open remote ID store of --rid_store_uri")
+		}
+		return ridStore, nil
+	}
+
 	connectParameters := flags.ConnectParameters()
-	connectParameters.DBName = "rid"
+	connectParameters.DBName = flags.RIDDatabaseName()
 	ridCrdb, err := datastore.Dial(ctx, connectParameters)
 	if err != nil {
 		// TODO: More robustly detect failure to create RID server is due to a problem that may be temporary
 		if strings.Contains(err.Error(), "connect: connection refused") {
-			return nil, nil, stacktrace.PropagateWithCode(err, codeRetryable, "Failed to connect to CRDB server for remote ID store")
+			return nil, stacktrace.PropagateWithCode(err, codeRetryable, "Failed to connect to CRDB server for remote ID store")
 		}
-		return nil, nil, stacktrace.Propagate(err, "Failed to connect to remote ID database; verify your database configuration is current with https://github.com/interuss/dss/tree/master/build#upgrading-database-schemas")
+		return nil, stacktrace.Propagate(err, "Failed to connect to remote ID database; verify your database configuration is current with https://github.com/interuss/dss/tree/master/build#upgrading-database-schemas")
 	}
 
 	ridStore, err := ridc.NewStore(ctx, ridCrdb, connectParameters.DBName, logger)
+	if err != nil && connectParameters.DBName != "rid" {
+		ridCrdb.Pool.Close()
+		return nil, stacktrace.Propagate(err, "Failed to create remote ID store in --rid_db_name %s", connectParameters.DBName)
+	}
 	if err != nil {
 		// try DBName of defaultdb for older versions.
 		ridCrdb.Pool.Close()
 		connectParameters.DBName = "defaultdb"
 		ridCrdb, err := datastore.Dial(ctx, connectParameters)
 		if err != nil {
-			return nil, nil, stacktrace.Propagate(err, "Failed to connect to remote ID database for older version <defaultdb>; verify your database configuration is current with https://github.com/interuss/dss/tree/master/build#upgrading-database-schemas")
+			return nil, stacktrace.Propagate(err, "Failed to connect to remote ID database for older version <defaultdb>; verify your database configuration is current with https://github.com/interuss/dss/tree/master/build#upgrading-database-schemas")
 		}
 		ridStore, err = ridc.NewStore(ctx, ridCrdb, connectParameters.DBName, logger)
 		if err != nil {
 			// TODO: More robustly detect failure to create RID server is due to a problem that may be temporary
 			if strings.Contains(err.Error(), "connect: connection refused") || strings.Contains(err.Error(), "database has not been bootstrapped with Schema Manager") {
 				ridCrdb.Pool.Close()
-				return nil, nil, stacktrace.PropagateWithCode(err, codeRetryable, "Failed to connect to CRDB server for remote ID store")
+				return nil, stacktrace.PropagateWithCode(err, codeRetryable, "Failed to connect to CRDB server for remote ID store")
 			}
-			return nil, nil, stacktrace.Propagate(err, "Failed to create remote ID store")
+			return nil, stacktrace.Propagate(err, "Failed to create remote ID store")
+		}
+	}
+
+	if readParameters, ok := flags.ReadConnectParameters(); ok {
+		readParameters.DBName = connectParameters.DBName
+		ridReadCrdb, err := datastore.Dial(ctx, readParameters)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Failed to connect to remote ID read database")
+		}
+		ridStore.SetReadDatastore(ridReadCrdb)
+	}
+	return ridStore, nil
+}
+
+// configureCockroachRIDStore enables the features of the CockroachDB remote
+// ID store configured by flags.
+func configureCockroachRIDStore(ctx context.Context, ridStore *ridc.Store, ownerCodec owners.Codec) error {
+	if *strictSchemaVersion {
+		if err := ridStore.CheckTargetSchemaVersion(ctx); err != nil {
+			return stacktrace.Propagate(err, "Remote ID schema version rejected by --strict_schema_version")
+		}
+	}
+
+	ridStore.UseOwnerCodec(ownerCodec)
+
+	if *notificationCounters > 0 {
+		if err := ridStore.UseNotificationCounters(*notificationCounters); err != nil {
+			return stacktrace.Propagate(err, "Failed to configure notification counters")
+		}
+	}
+
+	if *activityRetention > 0 {
+		if err := ridStore.UseActivityLog(); err != nil {
+			return stacktrace.Propagate(err, "Failed to configure activity log")
+		}
+	}
+
+	if *idempotencyRetention > 0 {
+		if err := ridStore.UseIdempotencyKeys(); err != nil {
+			return stacktrace.Propagate(err, "Failed to configure idempotency keys")
+		}
+	}
+	return nil
+}
+
+// cockroachRIDFlags returns the flags set which only the CockroachDB remote
+// ID store supports.
+func cockroachRIDFlags() []string {
+	var names []string
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"--strict_schema_version", *strictSchemaVersion},
+		{"--owner_encryption_key_file", *ownerKeyFile != ""},
+		{"--rid_notification_counter_shards", *notificationCounters > 0},
+		{"--rid_delete_subscriptions_after", *deleteEndedSubs > 0},
+		{"--rid_activity_retention", *activityRetention > 0},
+		{"--rid_idempotency_key_retention", *idempotencyRetention > 0},
+	} {
+		if f.set {
+			names = append(names, f.name)
+		}
+	}
+	return names
+}
+
+func createRIDServers(ctx context.Context, locality string, dbHealth *datastore.Health, logger *zap.Logger) (*rid_v1.Server, *rid_v2.Server, *ridserver.Idempotency, error) {
+	urlPolicy, err := createURLPolicy()
+	if err != nil {
+		return nil, nil, nil, stacktrace.Propagate(err, "Failed to create URL policy")
+	}
+	ownerCodec, err := createOwnerCodec()
+	if err != nil {
+		return nil, nil, nil, stacktrace.Propagate(err, "Failed to create owner codec")
+	}
+	writePolicy, err := createWritePolicy()
+	if err != nil {
+		return nil, nil, nil, stacktrace.Propagate(err, "Failed to create write policy")
+	}
+	if *hedgeReadsAfter < 0 {
+		return nil, nil, nil, stacktrace.NewError("--rid_hedge_reads_after must not be negative")
+	}
+
+	ridStore, err := openRIDStore(ctx, logger)
+	if err != nil {
+		return nil, nil, nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	crdbStore, isCrdb := ridStore.(*ridc.Store)
+	if isCrdb {
+		if err := configureCockroachRIDStore(ctx, crdbStore, ownerCodec); err != nil {
+			return nil, nil, nil, err // No need to Propagate this error as this stack layer does not add useful information
 		}
+	} else if names := cockroachRIDFlags(); len(names) > 0 {
+		return nil, nil, nil, stacktrace.NewError("%s require a CockroachDB or Yugabyte remote ID store", strings.Join(names, ", "))
 	}
 
-	repo, err := ridStore.Interact(ctx)
+	// The dispatcher delivers changes until ctx is done, so it is only started
+	// once the store it reports on is open.
+	dispatcher, err := createWebhookDispatcher(ctx, logger)
 	if err != nil {
-		return nil, nil, stacktrace.Propagate(err, "Unable to interact with store")
+		return nil, nil, nil, stacktrace.Propagate(err, "Failed to create webhook dispatcher")
+	}
+
+	if pinger, ok := ridStore.(interface{ Ping(context.Context) error }); ok && dbHealth != nil {
+		go dbHealth.Monitor(ctx, pinger.Ping, logger)
+	}
+	if checker, ok := ridStore.(interface{ CheckReady(context.Context) error }); ok {
+		serviceProbes.SetReadinessCheck("rid", checker.CheckReady)
+	}
+
+	var repo repos.Repository
+	if isCrdb {
+		repo, err = crdbStore.InteractPrimary(ctx)
+	} else {
+		repo, err = ridStore.Interact(ctx)
+	}
+	if err != nil {
+		return nil, nil, nil, stacktrace.Propagate(err, "Unable to interact with store")
 	}
 	gc := ridc.NewGarbageCollector(repo, locality)
 
 	// schedule period tasks for RID Server
 	ridCron := cron.New()
-	// schedule printing of DB connection stats every minute for the underlying storage for RID Server
-	if _, err := ridCron.AddFunc("@every 1m", func() { getDBStats(ctx, ridCrdb, connectParameters.DBName) }); err != nil {
-		return nil, nil, stacktrace.Propagate(err, "Failed to schedule periodic db stat check to %s", connectParameters.DBName)
+	if isCrdb {
+		// schedule printing of DB connection stats every minute for the underlying storage for RID Server
+		if _, err := ridCron.AddFunc("@every 1m", func() { getDBStats(ctx, crdbStore.Datastore(), crdbStore.DatabaseName) }); err != nil {
+			return nil, nil, nil, stacktrace.Propagate(err, "Failed to schedule periodic db stat check to %s", crdbStore.DatabaseName)
+		}
 	}
 
 	cronLogger := cron.VerbosePrintfLogger(log.New(os.Stdout, "RIDGarbageCollectorJob: ", log.LstdFlags))
 	if _, err = ridCron.AddJob(*garbageCollectorSpec, cron.NewChain(cron.SkipIfStillRunning(cronLogger)).Then(RIDGarbageCollectorJob{"delete rid expired records", *gc, ctx})); err != nil {
-		return nil, nil, stacktrace.Propagate(err, "Failed to schedule periodic delete rid expired records to %s", connectParameters.DBName)
+		return nil, nil, nil, stacktrace.Propagate(err, "Failed to schedule periodic delete rid expired records")
+	}
+	if *notificationCounters > 0 {
+		if _, err := ridCron.AddFunc(*notificationFoldSpec, func() { foldNotificationCounters(ctx, crdbStore, logger) }); err != nil {
+			return nil, nil, nil, stacktrace.Propagate(err, "Failed to schedule periodic folding of notification counters")
+		}
+	}
+	if *deleteEndedSubs > 0 {
+		if _, err := ridCron.AddFunc("@every 10m", func() { deleteEndedSubscriptions(ctx, crdbStore, *deleteEndedSubs, logger) }); err != nil {
+			return nil, nil, nil, stacktrace.Propagate(err, "Failed to schedule periodic deletion of ended subscriptions")
+		}
+	}
+	if *activityRetention > 0 {
+		if _, err := ridCron.AddFunc("@every 10m", func() { pruneActivity(ctx, crdbStore, *activityRetention, logger) }); err != nil {
+			return nil, nil, nil, stacktrace.Propagate(err, "Failed to schedule periodic pruning of activity events")
+		}
+	}
+	var idempotency *ridserver.Idempotency
+	if *idempotencyRetention > 0 {
+		if _, err := ridCron.AddFunc("@every 10m", func() { pruneIdempotentResponses(ctx, crdbStore, logger) }); err != nil {
+			return nil, nil, nil, stacktrace.Propagate(err, "Failed to schedule periodic pruning of idempotent responses")
+		}
+		idempotency = &ridserver.Idempotency{Store: crdbStore, Retention: *idempotencyRetention, Logger: logger}
 	}
 	ridCron.Start()
 
-	app := application.NewFromTransactor(ridStore, logger)
+	ridWritePolicy.Swap(writePolicy)
+	opts := []application.Option{application.WithWritePolicy(ridWritePolicy), application.WithHedgedReads(*hedgeReadsAfter)}
+	if dispatcher != nil {
+		opts = append(opts, application.WithEventSink(dispatcher))
+	}
+	app := application.NewFromTransactor(ridStore, logger, opts...)
 	return &rid_v1.Server{
-			App:               app,
-			Timeout:           *timeout,
-			Locality:          locality,
-			AllowHTTPBaseUrls: *allowHTTPBaseUrls,
-			Cron:              ridCron,
-		}, &rid_v2.Server{
-			App:               app,
-			Timeout:           *timeout,
-			Locality:          locality,
-			AllowHTTPBaseUrls: *allowHTTPBaseUrls,
-			Cron:              ridCron,
-		}, nil
+		App:       app,
+		Timeout:   *timeout,
+		Locality:  locality,
+		URLPolicy: urlPolicy,
+		Cron:      ridCron,
+	}, &rid_v2.Server{
+		App:       app,
+		Timeout:   *timeout,
+		Locality:  locality,
+		URLPolicy: urlPolicy,
+		Cron:      ridCron,
+	}, idempotency, nil
+}
+
+// foldNotificationCounters folds the notification counters of remote ID
+// subscriptions into their rows, in batches.
+func foldNotificationCounters(ctx context.Context, store *ridc.Store, logger *zap.Logger) {
+	const batchSize = 1000
+	for {
+		n, err := store.FoldNotificationCounters(ctx, batchSize)
+		if err != nil {
+			logger.Warn("Failed to fold notification counters", zap.Error(err))
+			return
+		}
+		if n < batchSize {
+			return
+		}
+	}
+}
+
+// deleteEndedSubscriptions deletes the remote ID subscriptions which ended
+// more than "after" ago, in batches.
+func deleteEndedSubscriptions(ctx context.Context, store *ridc.Store, after time.Duration, logger *zap.Logger) {
+	const batchSize = 100
+	before := time.Now().Add(-after)
+	for {
+		n, err := store.DeleteSubscriptionsEndedBefore(ctx, before, batchSize)
+		if err != nil {
+			logger.Warn("Failed to delete ended subscriptions", zap.Error(err))
+			return
+		}
+		if n > 0 {
+			logger.Info("Deleted ended subscriptions", zap.Int("count", n))
+		}
+		if n < batchSize {
+			return
+		}
+	}
+}
+
+// pruneActivity deletes the remote ID activity events older than retention,
+// in batches.
+func pruneActivity(ctx context.Context, store *ridc.Store, retention time.Duration, logger *zap.Logger) {
+	const batchSize = 1000
+	before := time.Now().Add(-retention)
+	for {
+		n, err := store.PruneActivity(ctx, before, batchSize)
+		if err != nil {
+			logger.Warn("Failed to prune activity events", zap.Error(err))
+			return
+		}
+		if n == 0 {
+			return
+		}
+	}
+}
+
+// pruneIdempotentResponses deletes the expired responses to remote ID writes
+// sent with idempotency keys, in batches.
+func pruneIdempotentResponses(ctx context.Context, store *ridc.Store, logger *zap.Logger) {
+	const batchSize = 1000
+	for {
+		n, err := store.PruneIdempotentResponses(ctx, time.Now(), batchSize)
+		if err != nil {
+			logger.Warn("Failed to prune idempotent responses", zap.Error(err))
+			return
+		}
+		if n < batchSize {
+			return
+		}
+	}
 }
 
 func createSCDServer(ctx context.Context, logger *zap.Logger) (*scd.Server, error) {
 	connectParameters := flags.ConnectParameters()
-	connectParameters.DBName = scdc.DatabaseName
+	connectParameters.DBName = flags.SCDDatabaseName()
 	scdCrdb, err := datastore.Dial(ctx, connectParameters)
 	if err != nil {
 		return nil, stacktrace.Propagate(err, "Failed to connect to strategic conflict detection database; verify your database configuration is current with https://github.com/interuss/dss/tree/master/build#upgrading-database-schemas")
@@ -184,21 +814,28 @@ func createSCDServer(ctx context.Context, logger *zap.Logger) (*scd.Server, erro
 	scdStore, err := scdc.NewStore(ctx, scdCrdb)
 	if err != nil {
 		// TODO: More robustly detect failure to create SCD server is due to a problem that may be temporary
-		if strings.Contains(err.Error(), "connect: connection refused") || strings.Contains(err.Error(), "database \"scd\" does not exist") {
+		if strings.Contains(err.Error(), "connect: connection refused") || strings.Contains(err.Error(), fmt.Sprintf("database \"%s\" does not exist", connectParameters.DBName)) {
 			scdCrdb.Pool.Close()
 			return nil, stacktrace.PropagateWithCode(err, codeRetryable, "Failed to connect to CRDB server for strategic conflict detection store")
 		}
 		return nil, stacktrace.Propagate(err, "Failed to create strategic conflict detection store")
 	}
 
+	if *strictSchemaVersion {
+		if err := scdStore.CheckTargetSchemaVersion(ctx); err != nil {
+			return nil, stacktrace.Propagate(err, "Strategic conflict detection schema version rejected by --strict_schema_version")
+		}
+	}
+
 	// schedule period tasks for SCD Server
 	scdCron := cron.New()
 	// schedule printing of DB connection stats every minute for the underlying storage for RID Server
-	if _, err := scdCron.AddFunc("@every 1m", func() { getDBStats(ctx, scdCrdb, scdc.DatabaseName) }); err != nil {
-		return nil, stacktrace.Propagate(err, "Failed to schedule periodic db stat check to %s", scdc.DatabaseName)
+	if _, err := scdCron.AddFunc("@every 1m", func() { getDBStats(ctx, scdCrdb, connectParameters.DBName) }); err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to schedule periodic db stat check to %s", connectParameters.DBName)
 	}
 
 	scdCron.Start()
+	serviceProbes.SetReadinessCheck("scd", scdStore.CheckReady)
 
 	return &scd.Server{
 		Store:             scdStore,
@@ -209,12 +846,17 @@ func createSCDServer(ctx context.Context, logger *zap.Logger) (*scd.Server, erro
 }
 
 // RunHTTPServer starts the DSS HTTP server.
-func RunHTTPServer(ctx context.Context, ctxCanceler func(), address, locality string) error {
-	logger := logging.WithValuesFromContext(ctx, logging.Logger).With(zap.String("address", address))
+func RunHTTPServer(ctx context.Context, ctxCanceler func(), addresses []string, locality string) error {
+	logger := logging.WithValuesFromContext(ctx, logging.Logger).With(zap.Strings("addresses", addresses))
 	logger.Info("version", zap.Any("version", version.Current()))
 	logger.Info("build", zap.Any("description", build.Describe()))
 	logger.Info("config", zap.Bool("scd", *enableSCD))
 
+	// Background work started for this attempt to serve, such as datastore
+	// health monitoring, ends when it returns so that retries do not leak it.
+	attemptCtx, cancelAttempt := context.WithCancel(ctx)
+	defer cancelAttempt()
+
 	if len(*jwtAudiences) == 0 {
 		// TODO: Make this flag required once all parties can set audiences
 		// correctly.
@@ -225,16 +867,94 @@ func RunHTTPServer(ctx context.Context, ctxCanceler func(), address, locality st
 		err                error
 		ridV1Server        *rid_v1.Server
 		ridV2Server        *rid_v2.Server
+		idempotency        *ridserver.Idempotency
 		scdV1Server        *scd.Server
 		auxV1Server        = &aux.Server{}
 		versioningV1Server = &versioning.Server{}
 	)
 
+	faultPlan, err := createFaultPlan()
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to configure fault injection")
+	}
+	tracers := datastore.QueryTracers{cost.QueryTracer{}}
+	if *slowQueryThreshold < 0 {
+		return stacktrace.NewError("--slow_query_threshold must not be negative")
+	}
+	if *slowQueryThreshold > 0 {
+		tracers = append(tracers, &datastore.SlowQueryTracer{Threshold: *slowQueryThreshold, Logger: logger})
+	}
+	if faultPlan != nil {
+		logger.Warn("INJECTING FAULTS INTO REQUESTS; never run this configuration in production", zap.Stringer("faults", faultPlan))
+		tracers = append(tracers, faults.QueryTracer{})
+	}
+	datastore.QueryTracer = tracers
+
+	signer, err := createResponseSigner()
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to configure response signing")
+	}
+
 	// Initialize remote ID
-	ridV1Server, ridV2Server, err = createRIDServers(ctx, locality, logger)
+	dbHealth, err := createDBHealth()
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to configure database availability monitoring")
+	}
+	retryHints, err := createRetryHints()
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to configure retry hints")
+	}
+	churnDetector, err := createChurnDetector(logger)
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to configure ISA churn detection")
+	}
+	loadAdaptiveCoverings, err := createLoadAdaptiveCoverings()
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to configure load-adaptive coverings")
+	}
+	rejections, err := createRejectionCounter()
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to configure rejected request counting")
+	}
+	auxV1Server.Rejections = rejections
+	if *readMaxStaleness < 0 {
+		return stacktrace.NewError("--read_max_staleness must not be negative")
+	}
+	consistencyPolicy := consistency.Policy{MaxStaleness: *readMaxStaleness}
+	errorMessages, err := createErrorMessages()
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to load --error_messages_file")
+	}
+	ridV1Server, ridV2Server, idempotency, err = createRIDServers(attemptCtx, locality, dbHealth, logger)
 	if err != nil {
 		return stacktrace.Propagate(err, "Failed to create remote ID server")
 	}
+	context.AfterFunc(attemptCtx, func() {
+		ridV1Server.Cron.Stop()
+		ridV2Server.Cron.Stop()
+	})
+	auxV1Server.RIDApp = ridV2Server.App
+	auxV1Server.MapUI = *enableMapUI
+	auxV1Server.URLPolicy = ridV2Server.URLPolicy
+
+	resultsPolicy, err := createResultsPolicy()
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to configure search results limit")
+	}
+	auxCapabilities.Swap(createCapabilities())
+	auxV1Server.Capabilities = auxCapabilities
+
+	// Initialize owner aliases, applied to the subjects of access tokens
+	if *ownerAliasRefresh <= 0 {
+		return stacktrace.NewError("--owner_alias_refresh_interval must be positive")
+	}
+	ownerAliases := &auth.OwnerAliases{}
+	loadOwnerAliases := aux.OwnerAliasLoader(ridV2Server.App)
+	if err := ownerAliases.Load(attemptCtx, loadOwnerAliases); err != nil {
+		return stacktrace.Propagate(err, "Failed to load owner aliases")
+	}
+	go ownerAliases.Refresh(attemptCtx, loadOwnerAliases, *ownerAliasRefresh, logger)
+	auxV1Server.OwnerAliases = ownerAliases
 
 	// Initialize access token validation
 	keyResolver, err := createKeyResolver()
@@ -245,13 +965,26 @@ func RunHTTPServer(ctx context.Context, ctxCanceler func(), address, locality st
 		logger.Warn("operating without authorizing interceptor")
 	}
 
-	authorizer, err := auth.NewRSAAuthorizer(
-		ctx, auth.Configuration{
-			KeyResolver:       keyResolver,
-			KeyRefreshTimeout: *keyRefreshTimeout,
-			AcceptedAudiences: strings.Split(*jwtAudiences, ","),
-		},
-	)
+	paths, err := createPublicPaths()
+	if err != nil {
+		return stacktrace.Propagate(err, "Invalid --public_paths")
+	}
+	var replayGuard auth.ReplayGuard
+	if *rejectReplays {
+		replayGuard = auth.NewMemoryReplayGuard()
+	}
+	authConfiguration := auth.Configuration{
+		KeyResolver:        keyResolver,
+		KeyRefreshTimeout:  *keyRefreshTimeout,
+		AcceptedAudiences:  strings.Split(*jwtAudiences, ","),
+		ReplayGuard:        replayGuard,
+		AllowImpersonation: *allowImpersonation,
+		TokenCacheSize:     *tokenCacheSize,
+		ClockSkewLeeway:    *clockSkewLeeway,
+		OwnerAliases:       ownerAliases,
+		PublicPaths:        paths,
+	}
+	authorizer, err := auth.NewRSAAuthorizer(ctx, authConfiguration)
 	if err != nil {
 		return stacktrace.Propagate(err, "Error creating RSA authorizer")
 	}
@@ -268,12 +1001,39 @@ func RunHTTPServer(ctx context.Context, ctxCanceler func(), address, locality st
 			&ridV2Router,
 		}}
 
+	// Initialize the administrative operations, served on --admin_addr with
+	// their own authorizer if set.
+	adminTLSConfig, err := createAdminTLSConfig()
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to configure the admin listener")
+	}
+	var adminServer *http.Server
+	if *adminAddr != "" {
+		adminAuthorizer, err := createAdminAuthorizer(ctx, authConfiguration)
+		if err != nil {
+			return stacktrace.Propagate(err, "Error creating admin authorizer")
+		}
+		aux.KeepAdminRoutes(&auxV1Router, false)
+		auxAdminRouter := apiauxv1.MakeAPIRouter(auxV1Server, adminAuthorizer)
+		aux.KeepAdminRoutes(&auxAdminRouter, true)
+		adminRouter := api.MultiRouter{Routers: []api.PartialRouter{&auxAdminRouter}}
+		if *enableMapUI {
+			adminRouter.Routers = append(adminRouter.Routers, aux.MapUIRouter{})
+		}
+		adminServer = newAdminServer(
+			logging.HTTPMiddleware(logger.With(zap.String("listener", "admin")), *dumpRequests,
+				createInstanceIdentity().Middleware(
+					healthyEndpointMiddleware(logger,
+						availabilityMiddleware(dbHealth, cost.Middleware(&adminRouter))))),
+			adminTLSConfig)
+	} else if *enableMapUI {
+		multiRouter.Routers = append(multiRouter.Routers, aux.MapUIRouter{})
+	}
+
 	// Initialize strategic conflict detection
 	if *enableSCD {
 		scdV1Server, err = createSCDServer(ctx, logger)
 		if err != nil {
-			ridV1Server.Cron.Stop()
-			ridV2Server.Cron.Stop()
 			return stacktrace.Propagate(err, "Failed to create strategic conflict detection server")
 		}
 
@@ -281,43 +1041,181 @@ func RunHTTPServer(ctx context.Context, ctxCanceler func(), address, locality st
 		multiRouter.Routers = append(multiRouter.Routers, &scdV1Router)
 	}
 
-	handler := logging.HTTPMiddleware(logger, *dumpRequests,
-		healthyEndpointMiddleware(logger,
-			&multiRouter,
-		))
+	// ownerLabels is shared by the handlers created on reload so that the
+	// number of owners labelled in request metrics stays bounded.
+	ownerLabels := &metrics.OwnerLabels{}
+
+	// createHandler wraps the API routers in middlewares configured by flags
+	// which may be reloaded.
+	createHandler := func() (http.Handler, error) {
+		headerPolicy := headers.Policy{
+			AllowedOrigins:  headers.SplitList(*corsAllowedOrigins),
+			AllowedMethods:  headers.SplitList(*corsAllowedMethods),
+			AllowedHeaders:  headers.SplitList(*corsAllowedHeaders),
+			MaxAge:          *corsMaxAge,
+			SecurityHeaders: *securityHeaders,
+		}
+		if err := configureOwnerLabels(ownerLabels); err != nil {
+			return nil, stacktrace.Propagate(err, "Failed to configure request metrics")
+		}
+		payloadPolicy, err := createPayloadPolicy()
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Failed to configure request and response bodies")
+		}
+		// middlewares wrap the API routers in order, the first being the
+		// outermost.
+		middlewares := []func(http.Handler) http.Handler{
+			// Outermost so that every request is logged, including those
+			// answered by the middlewares below, and carries the logging
+			// context in which its owner is recorded.
+			func(next http.Handler) http.Handler {
+				return logging.HTTPMiddleware(logger, *dumpRequests, next)
+			},
+			// Identifies the instance in every response, including those of
+			// /healthy and of the requests failed fast below.
+			createInstanceIdentity().Middleware,
+			// Counts every request in metrics, once logging.HTTPMiddleware
+			// has set up the context recording its owner.
+			ownerLabels.Middleware,
+			// Counts the requests rejected with a 4xx status by any
+			// middleware below as well as by the API.
+			rejections.Middleware,
+			// Sets the CORS and security headers of all the responses below,
+			// and answers CORS preflight requests before anything else.
+			headerPolicy.Middleware,
+			// Outside payloadPolicy so that entity tags are computed from
+			// the responses as encoded for the client.
+			conditionalMiddleware,
+			// Bounds request bodies before they are read, and compresses
+			// the responses once signed.
+			payloadPolicy.Middleware,
+			// Signs the responses as produced below, before compression.
+			signer.Middleware,
+			// Makes the result limit of the request available to the
+			// searches of the API.
+			resultsPolicy.Middleware,
+			// Counts the requests in flight, including those rejected
+			// below, to coarsen the search coverings of the API under load.
+			loadAdaptiveCoverings.Middleware,
+			// Reads the RID header excluding the ISAs of the caller from
+			// searches.
+			ridserver.ExcludeSelfMiddleware,
+			// Reads the RID header filtering searches by exact footprint.
+			ridserver.ExactGeometryMiddleware,
+			// Reports the notification deltas recorded by the API.
+			ridserver.NotificationDeltasMiddleware,
+			// Answers /healthy from this instance, without the database
+			// checks and faults below.
+			func(next http.Handler) http.Handler {
+				return healthyEndpointMiddleware(logger, next)
+			},
+			// Hints when to retry the 429 and 503 responses of the churn
+			// detector, injected faults and the database below.
+			func(next http.Handler) http.Handler {
+				return retryHintsMiddleware(retryHints, next)
+			},
+			// Rejects the writes of churning ISAs during their cooldown,
+			// ahead of the idempotency records below.
+			churnDetector.Middleware,
+			// Replays the recorded responses of retried writes instead of
+			// passing them to the API again.
+			idempotency.Middleware,
+			// Injects faults in the requests which would reach the API.
+			faultPlan.Middleware,
+			// Fails requests fast while the database is unavailable.
+			func(next http.Handler) http.Handler {
+				return availabilityMiddleware(dbHealth, next)
+			},
+			// Translates the error messages of the API.
+			errorMessages.Middleware,
+			// Carries the consistency tokens of the request to the stores of
+			// the API and back.
+			consistencyPolicy.Middleware,
+			// Innermost so that only the requests reaching the API carry a
+			// cost.
+			cost.Middleware,
+		}
+		var h http.Handler = &multiRouter
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			h = middlewares[i](h)
+		}
+		return h, nil
+	}
+	connections, err := createConnectionPolicy()
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to configure client connections")
+	}
+	handler := &reloadableHandler{}
+	h, err := createHandler()
+	if err != nil {
+		return err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	handler.Swap(h)
 
 	httpServer := &http.Server{
-		Addr:              address,
-		Handler:           handler,
+		Handler:           connections.Middleware(handler),
+		ConnContext:       connections.ConnContext,
 		ReadHeaderTimeout: 15 * time.Second,
 		ReadTimeout:       15 * time.Second,
 		WriteTimeout:      10 * time.Second,
-		IdleTimeout:       30 * time.Second,
+		IdleTimeout:       *httpIdleTimeout,
 	}
 
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
 	defer signal.Stop(signals)
 
+	// SIGHUP terminates the process unless a configuration file can be reloaded.
+	reloads := make(chan os.Signal, 1)
+	if *configFile != "" {
+		signal.Notify(reloads, syscall.SIGHUP)
+		defer signal.Stop(reloads)
+	}
+
 	go func() {
 		defer func() {
 			if err := httpServer.Shutdown(context.Background()); err != nil {
 				logger.Warn("failed to shut down http server", zap.Error(err))
 			}
+			if adminServer != nil {
+				if err := adminServer.Shutdown(context.Background()); err != nil {
+					logger.Warn("failed to shut down admin http server", zap.Error(err))
+				}
+			}
 		}()
 
 		for {
 			select {
 			case <-ctx.Done():
 				logger.Info("stopping server due to context having been canceled")
+				serviceProbes.SetStarted(false)
 				return
 			case s := <-signals:
 				logger.Info("received OS signal", zap.Stringer("signal", s))
 				ctxCanceler()
+			case <-reloads:
+				if err := reloadConfig(logger, handler, createHandler); err != nil {
+					logger.Error("Failed to reload configuration; keeping the current one", zap.Error(err))
+				}
 			}
 		}
 	}()
 
+	listeners, err := listenAll(addresses)
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to listen for incoming connections")
+	}
+	var adminListener net.Listener
+	if adminServer != nil {
+		adminListener, err = listenAdmin(adminTLSConfig)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return stacktrace.Propagate(err, "Failed to listen for incoming admin connections")
+		}
+	}
+
 	// Indicate ready for container health checks
 	readyFile, err := os.Create("service.ready")
 	if err != nil {
@@ -330,7 +1228,20 @@ func RunHTTPServer(ctx context.Context, ctxCanceler func(), address, locality st
 	}
 
 	logger.Info("Starting DSS HTTP server")
-	return httpServer.ListenAndServe()
+	serviceProbes.SetStarted(true)
+	serveErrs := make(chan error, len(listeners)+1)
+	for _, l := range listeners {
+		go func(l net.Listener) {
+			serveErrs <- httpServer.Serve(l)
+		}(l)
+	}
+	if adminListener != nil {
+		logger.Info("Starting DSS admin HTTP server", zap.String("admin_address", *adminAddr), zap.String("admin_auth", *adminAuth))
+		go func() {
+			serveErrs <- adminServer.Serve(adminListener)
+		}()
+	}
+	return <-serveErrs
 }
 
 // healthyEndpointMiddleware intercepts a request and responds with an "ok" message at the endpoint "/healthy".
@@ -346,6 +1257,34 @@ func healthyEndpointMiddleware(logger *zap.Logger, next http.Handler) http.Handl
 	})
 }
 
+// conditionalMiddleware supports conditional requests if enabled by
+// --conditional_requests. Entity tags are computed from the responses as
+// encoded for the client, so that they differ between content codings.
+func conditionalMiddleware(next http.Handler) http.Handler {
+	if !*conditionalRequests {
+		return next
+	}
+	return etag.Middleware(next)
+}
+
+// retryHintsMiddleware hints when to retry the requests rejected with 429 or
+// 503 according to retryHints, if set.
+func retryHintsMiddleware(retryHints *retry.Hints, next http.Handler) http.Handler {
+	if retryHints == nil {
+		return next
+	}
+	return retryHints.Middleware(next)
+}
+
+// availabilityMiddleware fails requests fast while the database is
+// unavailable according to dbHealth, if set.
+func availabilityMiddleware(dbHealth *datastore.Health, next http.Handler) http.Handler {
+	if dbHealth == nil {
+		return next
+	}
+	return dbHealth.Middleware(next)
+}
+
 type RIDGarbageCollectorJob struct {
 	name string
 	gc   ridc.GarbageCollector
@@ -372,18 +1311,32 @@ func SetDeprecatingHttpFlag(logger *zap.Logger, newFlag **bool, deprecatedFlag *
 }
 
 func main() {
-	flag.Parse()
-	if err := logging.Configure(*logLevel, *logFormat); err != nil {
-		panic(fmt.Sprintf("Failed to configure logging: %s", err.Error()))
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
 	}
+}
 
-	var (
-		ctx, cancel = context.WithCancel(context.Background())
-		logger      = logging.WithValuesFromContext(ctx, logging.Logger)
-	)
-	defer cancel()
+// serve serves requests until the server shuts down, once configured.
+func serve(ctx context.Context, cancel context.CancelFunc) {
+	logger := logging.WithValuesFromContext(ctx, logging.Logger)
 
-	SetDeprecatingHttpFlag(logger, &allowHTTPBaseUrls, &enableHTTP)
+	if err := geo.ConfigureCoverings(*minCellLevel, *maxCellLevel, *maxCoveringCells); err != nil {
+		logger.Panic("Invalid S2 configuration", zap.Error(err))
+	}
+
+	if *metricsAddr != "" {
+		go func() {
+			mux := http.NewServeMux()
+			// OpenMetrics exposes exemplars, e.g. the hashes of searched areas.
+			mux.Handle("/metrics", promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+				promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})))
+			serviceProbes.Register(mux)
+			logger.Info("Starting metrics server", zap.String("address", *metricsAddr))
+			if err := http.ListenAndServe(*metricsAddr, mux); err != nil {
+				logger.Error("Metrics server stopped", zap.Error(err))
+			}
+		}()
+	}
 
 	if *profServiceName != "" {
 		if err := profiler.Start(profiler.Config{Service: *profServiceName}); err != nil {
@@ -396,7 +1349,7 @@ func main() {
 		1 * time.Minute, 5 * time.Minute}
 	backoff := 0
 	for {
-		if err := RunHTTPServer(ctx, cancel, *address, *locality); err != nil {
+		if err := RunHTTPServer(ctx, cancel, serverAddresses(), *locality); err != nil {
 			if stacktrace.GetCode(err) == codeRetryable {
 				logger.Info(fmt.Sprintf("Prerequisites not yet satisfied; waiting %.fs to retry...", backoffs[backoff].Seconds()), zap.Error(err))
 				time.Sleep(backoffs[backoff])
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// readinessTimeout bounds the readiness checks made to answer a probe.
+const readinessTimeout = 5 * time.Second
+
+// serviceProbes answers the probes of the --metrics_addr listener.
+var serviceProbes = newProbes()
+
+// probes answers the liveness, readiness and startup probes of deployments
+// which check the health of instances over plain HTTP, e.g. load balancers
+// or orchestrators:
+//   - /healthz succeeds as long as the process serves the probes;
+//   - /startupz succeeds once the DSS serves its API;
+//   - /readyz succeeds while the DSS serves its API and all its readiness
+//     checks, e.g. pinging the database and checking its schema version,
+//     succeed.
+type probes struct {
+	started atomic.Bool
+
+	mu     sync.Mutex
+	checks map[string]func(context.Context) error
+}
+
+func newProbes() *probes {
+	return &probes{checks: make(map[string]func(context.Context) error)}
+}
+
+// SetStarted records whether the DSS serves its API.
+func (p *probes) SetStarted(started bool) {
+	p.started.Store(started)
+}
+
+// SetReadinessCheck makes readiness depend on check, replacing the check
+// previously set under name, e.g. when the servers are created again.
+func (p *probes) SetReadinessCheck(name string, check func(context.Context) error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checks[name] = check
+}
+
+// Register registers the handlers of the probes on mux.
+func (p *probes) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeProbe(w, http.StatusOK, "ok")
+	})
+	mux.HandleFunc("/startupz", func(w http.ResponseWriter, r *http.Request) {
+		if !p.started.Load() {
+			writeProbe(w, http.StatusServiceUnavailable, "not started")
+			return
+		}
+		writeProbe(w, http.StatusOK, "ok")
+	})
+	mux.HandleFunc("/readyz", p.serveReadiness)
+}
+
+// serveReadiness responds with the result of each readiness check, and
+// fails unless the DSS serves its API and all checks succeed.
+func (p *probes) serveReadiness(w http.ResponseWriter, r *http.Request) {
+	if !p.started.Load() {
+		writeProbe(w, http.StatusServiceUnavailable, "not started")
+		return
+	}
+
+	p.mu.Lock()
+	names := make([]string, 0, len(p.checks))
+	checks := make(map[string]func(context.Context) error, len(p.checks))
+	for name, check := range p.checks {
+		names = append(names, name)
+		checks[name] = check
+	}
+	p.mu.Unlock()
+	sort.Strings(names)
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+	status := http.StatusOK
+	body := ""
+	for _, name := range names {
+		if err := checks[name](ctx); err != nil {
+			status = http.StatusServiceUnavailable
+			body += fmt.Sprintf("%s: %s\n", name, err)
+		} else {
+			body += fmt.Sprintf("%s: ok\n", name)
+		}
+	}
+	if body == "" {
+		body = "ok"
+	}
+	writeProbe(w, status, body)
+}
+
+func writeProbe(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(body))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func probe(t *testing.T, mux *http.ServeMux, path string) (int, string) {
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Code, w.Body.String()
+}
+
+func TestProbes(t *testing.T) {
+	p := newProbes()
+	mux := http.NewServeMux()
+	p.Register(mux)
+
+	code, _ := probe(t, mux, "/healthz")
+	require.Equal(t, http.StatusOK, code)
+	code, _ = probe(t, mux, "/startupz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	code, _ = probe(t, mux, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+
+	p.SetStarted(true)
+	code, _ = probe(t, mux, "/startupz")
+	require.Equal(t, http.StatusOK, code)
+	code, body := probe(t, mux, "/readyz")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ok", body)
+
+	var dbErr error
+	p.SetReadinessCheck("scd", func(context.Context) error { return nil })
+	p.SetReadinessCheck("rid", func(context.Context) error { return dbErr })
+	code, body = probe(t, mux, "/readyz")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "rid: ok\nscd: ok\n", body)
+
+	dbErr = errors.New("connection refused")
+	code, body = probe(t, mux, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "rid: connection refused\nscd: ok\n", body)
+
+	p.SetStarted(false)
+	code, _ = probe(t, mux, "/startupz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	code, _ = probe(t, mux, "/healthz")
+	require.Equal(t, http.StatusOK, code)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	aux "github.com/interuss/dss/pkg/aux_"
+	"github.com/interuss/dss/pkg/logging"
+	"github.com/interuss/dss/pkg/rid/application"
+	"github.com/interuss/stacktrace"
+	"go.uber.org/zap"
+)
+
+// reloadableFlags are the flags which can be set in --config_file, whose
+// values are applied again without a restart when the process receives
+// SIGHUP.
+var reloadableFlags = map[string]bool{
+	"log_level":                true,
+	"cors_allowed_origins":     true,
+	"max_request_body_bytes":   true,
+	"metrics_owner_labels":     true,
+	"metrics_max_owner_labels": true,
+	"rid_max_isa_area_km2":     true,
+	"rid_max_isa_duration":     true,
+	"rid_min_altitude":         true,
+	"rid_max_altitude":         true,
+	"rid_max_cells":            true,
+}
+
+var (
+	configFile = flag.String("config_file", "", "Path to a file setting reloadable flags, one name=value per line, which is applied on startup over the command line and again on SIGHUP")
+
+	// ridWritePolicy vets the remote ID entities written according to the
+	// current configuration.
+	ridWritePolicy = &application.SwappableWritePolicy{}
+
+	// auxCapabilities are the capabilities reported by the auxiliary API
+	// according to the current configuration.
+	auxCapabilities = &aux.SwappableCapabilities{}
+
+	// startupFlags are the values of the reloadable flags before
+	// --config_file is first applied, to which the flags it no longer sets
+	// are reset.
+	startupFlags map[string]string
+)
+
+// snapshotStartupFlags records the current values of the reloadable flags as
+// startupFlags.
+func snapshotStartupFlags() {
+	startupFlags = map[string]string{}
+	for name := range reloadableFlags {
+		if f := flag.Lookup(name); f != nil {
+			startupFlags[name] = f.Value.String()
+		}
+	}
+}
+
+// readConfigFile returns the flag values set in file, which holds one
+// name=value per line, ignoring blank lines and lines starting with #.
+func readConfigFile(file string) (map[string]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error opening configuration file")
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, stacktrace.NewError("Expected name=value at %s:%d", file, n)
+		}
+		name = strings.TrimLeft(strings.TrimSpace(name), "-")
+		if !reloadableFlags[name] {
+			return nil, stacktrace.NewError("Flag %s set at %s:%d cannot be reloaded", name, file, n)
+		}
+		values[name] = strings.TrimSpace(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, stacktrace.Propagate(err, "Error reading configuration file")
+	}
+	return values, nil
+}
+
+// validateReloadableFlags returns an error if the values of the reloadable
+// flags are not valid.
+func validateReloadableFlags() error {
+	if _, err := zap.ParseAtomicLevel(*logLevel); err != nil {
+		return stacktrace.Propagate(err, "Invalid --log_level")
+	}
+	if _, err := createPayloadPolicy(); err != nil {
+		return err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if _, err := createOwnerLabels(); err != nil {
+		return err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if _, err := createWritePolicy(); err != nil {
+		return err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return nil
+}
+
+// applyConfig sets the flags to values, and the other reloadable flags to
+// their startupFlags, and returns the changes made, as "name: old -> new". The
+// flags are left unchanged if any value is invalid.
+func applyConfig(values map[string]string) ([]string, error) {
+	all := map[string]string{}
+	for name, value := range startupFlags {
+		all[name] = value
+	}
+	for name, value := range values {
+		all[name] = value
+	}
+
+	previous := map[string]string{}
+	restore := func() {
+		for name, value := range previous {
+			_ = flag.Set(name, value)
+		}
+	}
+	for name, value := range all {
+		f := flag.Lookup(name)
+		if f == nil || !reloadableFlags[name] {
+			restore()
+			return nil, stacktrace.NewError("Flag %s cannot be reloaded", name)
+		}
+		previous[name] = f.Value.String()
+		if err := flag.Set(name, value); err != nil {
+			restore()
+			return nil, stacktrace.Propagate(err, "Invalid value for --%s", name)
+		}
+	}
+	if err := validateReloadableFlags(); err != nil {
+		restore()
+		return nil, stacktrace.Propagate(err, "Invalid configuration")
+	}
+
+	var changes []string
+	flag.VisitAll(func(f *flag.Flag) {
+		if old, ok := previous[f.Name]; ok && old != f.Value.String() {
+			changes = append(changes, fmt.Sprintf("%s: %q -> %q", f.Name, old, f.Value.String()))
+		}
+	})
+	return changes, nil
+}
+
+// reloadableHandler serves requests with a handler which can be replaced
+// while in use, without closing connections.
+type reloadableHandler struct {
+	current atomic.Pointer[http.Handler]
+}
+
+func (h *reloadableHandler) Swap(handler http.Handler) {
+	h.current.Store(&handler)
+}
+
+func (h *reloadableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*h.current.Load()).ServeHTTP(w, r)
+}
+
+// reloadConfig applies --config_file again, then updates the log level, the
+// remote ID write policy, the capabilities reported by the auxiliary API and
+// the handler, created by createHandler, of handler.
+func reloadConfig(logger *zap.Logger, handler *reloadableHandler, createHandler func() (http.Handler, error)) error {
+	values, err := readConfigFile(*configFile)
+	if err != nil {
+		return stacktrace.Propagate(err, "Error reading --config_file")
+	}
+	changes, err := applyConfig(values)
+	if err != nil {
+		return stacktrace.Propagate(err, "Error applying --config_file")
+	}
+
+	if err := logging.SetLevel(*logLevel); err != nil {
+		return stacktrace.Propagate(err, "Error setting log level")
+	}
+	writePolicy, err := createWritePolicy()
+	if err != nil {
+		return stacktrace.Propagate(err, "Error creating write policy")
+	}
+	ridWritePolicy.Swap(writePolicy)
+	auxCapabilities.Swap(createCapabilities())
+	h, err := createHandler()
+	if err != nil {
+		return stacktrace.Propagate(err, "Error creating handler")
+	}
+	handler.Swap(h)
+
+	logger.Info("Reloaded configuration", zap.String("file", *configFile), zap.Strings("changes", changes))
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	aux "github.com/interuss/dss/pkg/aux_"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// restoreFlags restores the values of the reloadable flags at the end of t.
+func restoreFlags(t *testing.T) {
+	values := map[string]string{}
+	for name := range reloadableFlags {
+		values[name] = flag.Lookup(name).Value.String()
+	}
+	t.Cleanup(func() {
+		for name, value := range values {
+			require.NoError(t, flag.Set(name, value))
+		}
+	})
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "dss.conf")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0600))
+	return file
+}
+
+func TestReadConfigFile(t *testing.T) {
+	values, err := readConfigFile(writeConfigFile(t, "# Limits\n\nrid_max_isa_duration = 2h\n--log_level=debug\n"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"rid_max_isa_duration": "2h", "log_level": "debug"}, values)
+
+	_, err = readConfigFile(writeConfigFile(t, "log_level\n"))
+	require.Error(t, err)
+	_, err = readConfigFile(writeConfigFile(t, "addr=:9090\n"))
+	require.Error(t, err)
+}
+
+func TestApplyConfig(t *testing.T) {
+	restoreFlags(t)
+	require.NoError(t, flag.Set("rid_max_isa_duration", "1h"))
+	require.NoError(t, flag.Set("log_level", "info"))
+
+	changes, err := applyConfig(map[string]string{"rid_max_isa_duration": "2h", "log_level": "info"})
+	require.NoError(t, err)
+	require.Equal(t, []string{`rid_max_isa_duration: "1h0m0s" -> "2h0m0s"`}, changes)
+
+	// Invalid configurations leave all the flags unchanged.
+	_, err = applyConfig(map[string]string{"rid_max_isa_duration": "3h", "rid_min_altitude": "100", "rid_max_altitude": "50"})
+	require.Error(t, err)
+	_, err = applyConfig(map[string]string{"rid_max_isa_duration": "3h", "log_level": "loud"})
+	require.Error(t, err)
+	_, err = applyConfig(map[string]string{"rid_max_isa_duration": "3h", "addr": ":9090"})
+	require.Error(t, err)
+	require.Equal(t, "2h0m0s", flag.Lookup("rid_max_isa_duration").Value.String())
+	require.Equal(t, "", *ridMinAltitude)
+}
+
+func TestApplyConfigResetsRemovedFlags(t *testing.T) {
+	restoreFlags(t)
+	previousStartupFlags := startupFlags
+	t.Cleanup(func() { startupFlags = previousStartupFlags })
+	require.NoError(t, flag.Set("rid_max_isa_duration", "1h"))
+	require.NoError(t, flag.Set("log_level", "info"))
+	snapshotStartupFlags()
+
+	_, err := applyConfig(map[string]string{"rid_max_isa_duration": "2h", "log_level": "debug"})
+	require.NoError(t, err)
+
+	// Flags no longer set by the configuration return to their startup values.
+	changes, err := applyConfig(map[string]string{"log_level": "debug"})
+	require.NoError(t, err)
+	require.Equal(t, []string{`rid_max_isa_duration: "2h0m0s" -> "1h0m0s"`}, changes)
+	require.Equal(t, "debug", *logLevel)
+}
+
+func TestReloadConfig(t *testing.T) {
+	restoreFlags(t)
+	previousConfigFile := *configFile
+	t.Cleanup(func() { *configFile = previousConfigFile })
+	*configFile = writeConfigFile(t, "max_request_body_bytes=10\ncors_allowed_origins=https://uss.example\n")
+	previousCapabilities := auxCapabilities.Load()
+	t.Cleanup(func() { auxCapabilities.Swap(previousCapabilities) })
+
+	handler := &reloadableHandler{}
+	createHandler := func() (http.Handler, error) {
+		maxBytes := *maxRequestBytes
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if maxBytes > 0 && r.ContentLength > maxBytes {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+			}
+		}), nil
+	}
+	h, err := createHandler()
+	require.NoError(t, err)
+	handler.Swap(h)
+
+	serve := func() int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPut, "/", nil)
+		r.ContentLength = 100
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+	require.Equal(t, http.StatusOK, serve())
+	require.NotContains(t, auxCapabilities.Load().Features, aux.FeatureCORS)
+	require.NoError(t, reloadConfig(zap.NewNop(), handler, createHandler))
+	require.Equal(t, http.StatusRequestEntityTooLarge, serve())
+	require.Contains(t, auxCapabilities.Load().Features, aux.FeatureCORS)
+}
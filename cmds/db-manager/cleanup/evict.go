@@ -101,7 +101,7 @@ func evict(cmd *cobra.Command, _ []string) error {
 func getSCDStore(ctx context.Context) (*scdc.Store, error) {
 	connectParameters := crdbflags.ConnectParameters()
 	connectParameters.ApplicationName = "db-manager"
-	connectParameters.DBName = scdc.DatabaseName
+	connectParameters.DBName = crdbflags.SCDDatabaseName()
 	scdCrdb, err := datastore.Dial(ctx, connectParameters)
 	if err != nil {
 		logParams := connectParameters
@@ -7,6 +7,7 @@ import (
 
 	"github.com/interuss/dss/cmds/db-manager/cleanup"
 	"github.com/interuss/dss/cmds/db-manager/migration"
+	"github.com/interuss/dss/cmds/db-manager/seed"
 	"github.com/spf13/cobra"
 )
 
@@ -21,6 +22,7 @@ func init() {
 	DBManagerCmd.PersistentFlags().AddGoFlagSet(flag.CommandLine) // enable support for flags not yet migrated to using pflag (e.g. crdb flags)
 	DBManagerCmd.AddCommand(migration.MigrationCmd)
 	DBManagerCmd.AddCommand(cleanup.EvictCmd)
+	DBManagerCmd.AddCommand(seed.SeedCmd)
 }
 
 func main() {
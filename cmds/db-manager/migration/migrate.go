@@ -14,6 +14,7 @@ import (
 	crdbflags "github.com/interuss/dss/pkg/datastore/flags"
 
 	"github.com/interuss/stacktrace"
+	"github.com/jackc/pgx/v5"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -27,6 +28,10 @@ type MigrationStep struct {
 var (
 	// Pattern to match files describing migration steps
 	migrationStepRegexp = "(upto|downfrom)-v(\\d+\\.\\d+\\.\\d+)-(.*)\\.sql"
+
+	// ridRenameVersion is the rid schema version whose migration step renames
+	// the defaultdb database to rid, which a database named otherwise skips.
+	ridRenameVersion = semver.New("4.0.0")
 )
 
 var (
@@ -48,7 +53,11 @@ func init() {
 func migrate(cmd *cobra.Command, _ []string) error {
 	var (
 		ctx    = cmd.Context()
-		dbName = filepath.Base(*path)
+		dbName = databaseName(filepath.Base(*path))
+		// renamed is whether the rid database follows the defaultdb to rid
+		// rename of ridRenameVersion rather than being named by --rid_db_name.
+		renamed     = dbName == "rid"
+		skipsRename = filepath.Base(*path) == "rid" && !renamed
 	)
 
 	// Enumerate schema versions
@@ -95,7 +104,7 @@ func migrate(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to check whether database %s exists: %w", dbName, err)
 	}
-	if isCockroach && !exists && dbName == "rid" {
+	if isCockroach && !exists && renamed {
 		// In the special case of rid, the database was previously named defaultdb
 		log.Printf("Database %s does not exist; checking for older \"defaultdb\" database", dbName)
 		dbName = "defaultdb"
@@ -106,8 +115,7 @@ func migrate(cmd *cobra.Command, _ []string) error {
 	}
 	if !exists {
 		log.Printf("Database %s does not exist; creating now", dbName)
-		createDB := fmt.Sprintf("CREATE DATABASE %s", dbName)
-		if _, err := ds.Pool.Exec(ctx, createDB); err != nil {
+		if err := ds.CreateDatabase(ctx, dbName); err != nil {
 			return fmt.Errorf("failed to create new database %s: %v", dbName, err)
 		}
 	} else {
@@ -123,6 +131,13 @@ func migrate(cmd *cobra.Command, _ []string) error {
 		ds2.Pool.Close()
 	}()
 
+	// Make sure the schema of --cockroach_schema exists
+	if schema := crdbflags.ConnectParameters().Schema; schema != "" {
+		if err := ds2.CreateSchema(ctx, schema); err != nil {
+			return fmt.Errorf("failed to create schema %s in database %s: %w", schema, dbName, err)
+		}
+	}
+
 	// Read current schema version of database
 	currentVersion, err := ds2.GetSchemaVersion(ctx, dbName)
 	if err != nil {
@@ -175,7 +190,11 @@ func migrate(cmd *cobra.Command, _ []string) error {
 				sessionConfigurationSQL = "SET enable_implicit_transaction_for_batch_statements = false;\n"
 			}
 
-			migrationSQL = sessionConfigurationSQL + fmt.Sprintf("USE %s;\n", dbName) + string(rawMigrationSQL)
+			if skipsRename && (newVersion.Equal(*ridRenameVersion) || currentVersion.Equal(*ridRenameVersion)) {
+				// A database named by --rid_db_name only records the version of the rename step
+				rawMigrationSQL = []byte(fmt.Sprintf("UPDATE schema_versions SET schema_version = 'v%s' WHERE onerow_enforcer = TRUE;", newVersion))
+			}
+			migrationSQL = sessionConfigurationSQL + fmt.Sprintf("USE %s;\n", pgx.Identifier{dbName}.Sanitize()) + string(rawMigrationSQL)
 		}
 		if isYugabyte {
 			// Migrations do not require database switch in opposite to CRDB.
@@ -190,11 +209,11 @@ func migrate(cmd *cobra.Command, _ []string) error {
 		// Update current state
 		if isCockroach {
 			// Update current state for CRDB
-			if dbName == "defaultdb" && newVersion.String() == "4.0.0" && newCurrentStepIndex > currentStepIndex {
+			if renamed && dbName == "defaultdb" && newVersion.String() == "4.0.0" && newCurrentStepIndex > currentStepIndex {
 				// RID database changes from `defaultdb` to `rid` when moving up to 4.0.0
 				dbName = "rid"
 			}
-			if dbName == "rid" && currentVersion.String() == "4.0.0" && newCurrentStepIndex < currentStepIndex {
+			if renamed && dbName == "rid" && currentVersion.String() == "4.0.0" && newCurrentStepIndex < currentStepIndex {
 				// RID database changes from `rid` to `defaultdb` when moving down from 4.0.0
 				dbName = "defaultdb"
 			}
@@ -214,6 +233,18 @@ func migrate(cmd *cobra.Command, _ []string) error {
 	return nil
 }
 
+// databaseName returns the name of the database to migrate with the schemas
+// of the folder named folder, which --rid_db_name and --scd_db_name override.
+func databaseName(folder string) string {
+	switch folder {
+	case "rid":
+		return crdbflags.RIDDatabaseName()
+	case "scd":
+		return crdbflags.SCDDatabaseName()
+	}
+	return folder
+}
+
 func connectTo(ctx context.Context, dbName string) (*datastore.Datastore, error) {
 	// Connect to database server
 	connectParameters := crdbflags.ConnectParameters()
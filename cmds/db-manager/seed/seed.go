@@ -0,0 +1,279 @@
+package seed
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/interuss/dss/pkg/datastore"
+	crdbflags "github.com/interuss/dss/pkg/datastore/flags"
+	"github.com/interuss/dss/pkg/geo"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/dss/pkg/rid/repos"
+	ridc "github.com/interuss/dss/pkg/rid/store/cockroach"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+)
+
+const (
+	// writer is the writer of the seeded entities.
+	writer = "db-manager"
+
+	// labelKey is the label of the seeded entities, valued with the run that
+	// seeded them, with which they can be found and deleted.
+	labelKey = "seed"
+
+	// maxFlightMinutes is the maximum duration of the seeded ISAs.
+	maxFlightMinutes = 60
+
+	// batchSize is the number of entities inserted per transaction.
+	batchSize = 100
+
+	// earthRadiusKm approximates the radius of the Earth to compute the area
+	// of the bounding box.
+	earthRadiusKm = 6371.0
+)
+
+var (
+	SeedCmd = &cobra.Command{
+		Use:   "seed",
+		Short: "Populate the remote ID database with synthetic ISAs and subscriptions",
+		RunE:  seed,
+	}
+	flags      = pflag.NewFlagSet("seed", pflag.ExitOnError)
+	bbox       = flags.String("bbox", "46.90,7.35,47.00,7.50", "bounding box of the seeded entities: lat_lo,lng_lo,lat_hi,lng_hi")
+	startTime  = flags.String("start_time", "", "start of the time window of the seeded entities in RFC 3339 format, defaults to now")
+	window     = flags.Duration("window", 4*time.Hour, "duration of the time window of the seeded entities")
+	isaDensity = flags.Float64("isas_per_km2", 0.5, "number of ISAs seeded per km² of the bounding box")
+	subDensity = flags.Float64("subscriptions_per_km2", 0.1, "number of subscriptions seeded per km² of the bounding box")
+	ownerCount = flags.Int("owners", 3, "number of synthetic USSs owning the seeded entities")
+	minRadius  = flags.Float64("min_radius_meters", 200, "minimum radius of the circular areas of the seeded entities")
+	maxRadius  = flags.Float64("max_radius_meters", 2000, "maximum radius of the circular areas of the seeded entities")
+	randomSeed = flags.Int64("random_seed", 1, "seed of the pseudo-random generator, so that runs with the same flags seed the same areas and times")
+	dryRun     = flags.Bool("dry_run", false, "set this flag to true to only log the entities that would be seeded")
+)
+
+func init() {
+	SeedCmd.Flags().AddFlagSet(flags)
+}
+
+// params are the parameters of a run of seed.
+type params struct {
+	latLo, lngLo, latHi, lngHi float64
+	start                      time.Time
+	window                     time.Duration
+	isas, subscriptions        int
+	owners                     int
+	minRadius, maxRadius       float64
+	run                        string
+}
+
+func seed(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+
+	p, err := parseParams()
+	if err != nil {
+		return err
+	}
+	isas, subs := generate(p, rand.New(rand.NewSource(*randomSeed)))
+	log.Printf("seeding %d ISAs and %d subscriptions of %d owners in %s from %s for %s, labelled %s=%s",
+		len(isas), len(subs), p.owners, *bbox, p.start.Format(time.RFC3339), p.window, labelKey, p.run)
+	if *dryRun {
+		log.Printf("no entity was seeded, run the command again without the `--dry_run` flag to do so")
+		return nil
+	}
+
+	connectParameters := crdbflags.ConnectParameters()
+	connectParameters.ApplicationName = "db-manager"
+	connectParameters.DBName = crdbflags.RIDDatabaseName()
+	ridCrdb, err := datastore.Dial(ctx, connectParameters)
+	if err != nil {
+		logParams := connectParameters
+		logParams.Credentials.Password = "[REDACTED]"
+		return fmt.Errorf("failed to connect to database with %+v: %w", logParams, err)
+	}
+	ridStore, err := ridc.NewStore(ctx, ridCrdb, connectParameters.DBName, zap.NewNop())
+	if err != nil {
+		ridCrdb.Pool.Close()
+		return fmt.Errorf("failed to create remote ID store: %w", err)
+	}
+	defer ridStore.Close()
+
+	for i := 0; i < len(isas); i += batchSize {
+		batch := isas[i:min(i+batchSize, len(isas))]
+		if err := ridStore.Transact(ctx, func(r repos.Repository) error {
+			for _, isa := range batch {
+				if _, err := r.InsertISA(ctx, isa); err != nil {
+					return fmt.Errorf("inserting ISA %s: %w", isa.ID, err)
+				}
+			}
+			return nil
+		}); err != nil {
+			return fmt.Errorf("failed to seed ISAs: %w", err)
+		}
+	}
+	for i := 0; i < len(subs); i += batchSize {
+		batch := subs[i:min(i+batchSize, len(subs))]
+		if err := ridStore.Transact(ctx, func(r repos.Repository) error {
+			for _, sub := range batch {
+				if _, err := r.InsertSubscription(ctx, sub); err != nil {
+					return fmt.Errorf("inserting subscription %s: %w", sub.ID, err)
+				}
+			}
+			return nil
+		}); err != nil {
+			return fmt.Errorf("failed to seed subscriptions: %w", err)
+		}
+	}
+	log.Printf("seeded %d ISAs and %d subscriptions", len(isas), len(subs))
+	return nil
+}
+
+// parseParams returns the params of the flags.
+func parseParams() (params, error) {
+	p := params{
+		start:     time.Now().UTC(),
+		window:    *window,
+		owners:    *ownerCount,
+		minRadius: *minRadius,
+		maxRadius: *maxRadius,
+		run:       strconv.FormatInt(time.Now().Unix(), 10),
+	}
+
+	coords := strings.Split(*bbox, ",")
+	if len(coords) != 4 {
+		return p, fmt.Errorf("expected 4 comma-separated coordinates in --bbox, got `%s`", *bbox)
+	}
+	values := make([]float64, len(coords))
+	for i, c := range coords {
+		v, err := strconv.ParseFloat(strings.TrimSpace(c), 64)
+		if err != nil {
+			return p, fmt.Errorf("invalid coordinate `%s` in --bbox: %w", c, err)
+		}
+		values[i] = v
+	}
+	p.latLo, p.lngLo, p.latHi, p.lngHi = values[0], values[1], values[2], values[3]
+	if p.latLo < -90 || p.latHi > 90 || p.latLo >= p.latHi || p.lngLo < -180 || p.lngHi > 180 || p.lngLo >= p.lngHi {
+		return p, fmt.Errorf("--bbox `%s` is not a valid lat_lo,lng_lo,lat_hi,lng_hi bounding box", *bbox)
+	}
+
+	if *startTime != "" {
+		start, err := time.Parse(time.RFC3339, *startTime)
+		if err != nil {
+			return p, fmt.Errorf("invalid --start_time: %w", err)
+		}
+		p.start = start
+	}
+	if p.window <= 0 {
+		return p, fmt.Errorf("--window must be positive")
+	}
+	if p.owners < 1 {
+		return p, fmt.Errorf("--owners must be at least 1")
+	}
+	if p.minRadius <= 0 || p.maxRadius < p.minRadius {
+		return p, fmt.Errorf("--min_radius_meters must be positive and not more than --max_radius_meters")
+	}
+	if area := math.Pi * math.Pow(p.maxRadius/1000, 2); area > geo.MaxAllowedAreaKm2 {
+		return p, fmt.Errorf("--max_radius_meters covers %.0f km², more than the maximum area of %.0f km²", area, geo.MaxAllowedAreaKm2)
+	}
+	if *isaDensity < 0 || *subDensity < 0 {
+		return p, fmt.Errorf("--isas_per_km2 and --subscriptions_per_km2 must not be negative")
+	}
+
+	area := bboxAreaKm2(p.latLo, p.lngLo, p.latHi, p.lngHi)
+	p.isas = int(math.Round(*isaDensity * area))
+	p.subscriptions = int(math.Round(*subDensity * area))
+	return p, nil
+}
+
+// bboxAreaKm2 returns the area of the bounding box in km².
+func bboxAreaKm2(latLo, lngLo, latHi, lngHi float64) float64 {
+	toRad := math.Pi / 180
+	return earthRadiusKm * earthRadiusKm * (lngHi - lngLo) * toRad *
+		math.Abs(math.Sin(latHi*toRad)-math.Sin(latLo*toRad))
+}
+
+// generate returns the ISAs and subscriptions of p, drawn from rng: circular
+// areas centered in the bounding box, flights of up to maxFlightMinutes for
+// ISAs and the rest of the window, up to the maximum duration, for
+// subscriptions.
+func generate(p params, rng *rand.Rand) ([]*ridmodels.IdentificationServiceArea, []*ridmodels.Subscription) {
+	var (
+		altitudeLo = float32(0)
+		altitudeHi = float32(120)
+		labels     = ridmodels.Labels{labelKey: p.run}
+	)
+	owner := func() int { return rng.Intn(p.owners) + 1 }
+	footprint := func() *dssmodels.GeoCircle {
+		return &dssmodels.GeoCircle{
+			Center: dssmodels.LatLngPoint{
+				Lat: p.latLo + rng.Float64()*(p.latHi-p.latLo),
+				Lng: p.lngLo + rng.Float64()*(p.lngHi-p.lngLo),
+			},
+			RadiusMeter: float32(p.minRadius + rng.Float64()*(p.maxRadius-p.minRadius)),
+		}
+	}
+	newID := func() dssmodels.ID {
+		id, _ := uuid.NewRandomFromReader(rng)
+		return dssmodels.ID(id.String())
+	}
+
+	isas := make([]*ridmodels.IdentificationServiceArea, 0, p.isas)
+	for len(isas) < p.isas {
+		area := footprint()
+		cells, err := area.CalculateCovering()
+		if err != nil {
+			continue // Too large for the maximum area: draw again.
+		}
+		n := owner()
+		start := p.start.Add(time.Duration(rng.Int63n(int64(p.window))))
+		end := start.Add(time.Duration(1+rng.Intn(maxFlightMinutes)) * time.Minute)
+		isas = append(isas, &ridmodels.IdentificationServiceArea{
+			ID:         newID(),
+			URL:        fmt.Sprintf("https://uss%d.example.com/rid/v2/uss", n),
+			Owner:      dssmodels.Owner(fmt.Sprintf("uss%d", n)),
+			Cells:      cells,
+			StartTime:  &start,
+			EndTime:    &end,
+			AltitudeLo: &altitudeLo,
+			AltitudeHi: &altitudeHi,
+			Footprint:  area,
+			Writer:     writer,
+			Labels:     labels,
+		})
+	}
+
+	subs := make([]*ridmodels.Subscription, 0, p.subscriptions)
+	for len(subs) < p.subscriptions {
+		cells, err := footprint().CalculateCovering()
+		if err != nil {
+			continue // Too large for the maximum area: draw again.
+		}
+		n := owner()
+		start := p.start.Add(time.Duration(rng.Int63n(int64(p.window))))
+		end := p.start.Add(p.window)
+		if maxEnd := start.Add(ridmodels.MaxSubscriptionDuration()); end.After(maxEnd) {
+			end = maxEnd
+		}
+		subs = append(subs, &ridmodels.Subscription{
+			ID:         newID(),
+			URL:        fmt.Sprintf("https://uss%d.example.com/rid/v2/uss", n),
+			Owner:      dssmodels.Owner(fmt.Sprintf("uss%d", n)),
+			Cells:      cells,
+			StartTime:  &start,
+			EndTime:    &end,
+			AltitudeLo: &altitudeLo,
+			AltitudeHi: &altitudeHi,
+			Writer:     writer,
+			Labels:     labels,
+		})
+	}
+	return isas, subs
+}
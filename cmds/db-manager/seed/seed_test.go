@@ -0,0 +1,61 @@
+package seed
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBBoxAreaKm2(t *testing.T) {
+	// One degree of latitude and longitude at the equator is about 111 km.
+	require.InDelta(t, 111.2*111.2, bboxAreaKm2(0, 0, 1, 1), 10)
+	require.InDelta(t, 111.2*111.2/2, bboxAreaKm2(60, 0, 61, 1), 150)
+}
+
+func TestGenerate(t *testing.T) {
+	p := params{
+		latLo: 46.9, lngLo: 7.35, latHi: 47, lngHi: 7.5,
+		start:         time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		window:        48 * time.Hour,
+		isas:          50,
+		subscriptions: 20,
+		owners:        3,
+		minRadius:     200,
+		maxRadius:     2000,
+		run:           "42",
+	}
+	isas, subs := generate(p, rand.New(rand.NewSource(1)))
+	require.Len(t, isas, p.isas)
+	require.Len(t, subs, p.subscriptions)
+
+	end := p.start.Add(p.window)
+	owners := map[dssmodels.Owner]bool{}
+	for _, isa := range isas {
+		owners[isa.Owner] = true
+		require.NotEmpty(t, isa.Cells)
+		require.Equal(t, ridmodels.Labels{labelKey: "42"}, isa.Labels)
+		require.False(t, isa.StartTime.Before(p.start))
+		require.True(t, isa.StartTime.Before(end))
+		require.True(t, isa.EndTime.After(*isa.StartTime))
+		circle := isa.Footprint.(*dssmodels.GeoCircle)
+		require.True(t, circle.Center.Lat >= p.latLo && circle.Center.Lat <= p.latHi)
+		require.True(t, circle.Center.Lng >= p.lngLo && circle.Center.Lng <= p.lngHi)
+		require.True(t, circle.RadiusMeter >= 200 && circle.RadiusMeter <= 2000)
+	}
+	require.Len(t, owners, p.owners)
+	for _, sub := range subs {
+		require.NotEmpty(t, sub.Cells)
+		require.True(t, sub.EndTime.After(*sub.StartTime))
+		require.False(t, sub.EndTime.After(end))
+		require.LessOrEqual(t, sub.EndTime.Sub(*sub.StartTime), ridmodels.MaxSubscriptionDuration())
+	}
+
+	// The same seed generates the same entities.
+	again, _ := generate(p, rand.New(rand.NewSource(1)))
+	require.Equal(t, isas[0].ID, again[0].ID)
+	require.Equal(t, isas[0].Cells, again[0].Cells)
+}
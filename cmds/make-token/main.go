@@ -0,0 +1,109 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/interuss/dss/pkg/signing"
+	"github.com/interuss/stacktrace"
+)
+
+var (
+	keyFile   = flag.String("private_key_file", "build/test-certs/auth2.key", "PEM-encoded RSA or ECDSA P-256 private key signing the token, with RS256 or ES256 respectively")
+	sub       = flag.String("sub", "fake_uss", "Subject of the token, i.e. the USS it identifies")
+	scopes    = flag.String("scopes", "", "Comma- or space-separated scopes granted by the token")
+	audience  = flag.String("audience", "localhost", "Intended audience of the token, which must be accepted by the DSS with --accepted_jwt_audiences")
+	issuer    = flag.String("issuer", "dummyoauth", "Issuer of the token")
+	expiresIn = flag.Duration("expires_in", time.Hour, "Time from now after which the token expires; the DSS rejects tokens expiring more than an hour from now")
+	jti       = flag.String("jti", "", "Unique identifier of the token, which the DSS requires for writes with --reject_replayed_write_tokens; random if empty")
+)
+
+// tokenClaims are the claims of the tokens minted.
+type tokenClaims struct {
+	jwt.StandardClaims
+	Scope string `json:"scope"`
+}
+
+// signingMethod returns the signing method of the tokens signed with key.
+func signingMethod(key crypto.Signer) (jwt.SigningMethod, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, stacktrace.NewError("Unsupported ECDSA curve %s; ES256 requires P-256", k.Curve.Params().Name)
+		}
+		return jwt.SigningMethodES256, nil
+	}
+	return nil, stacktrace.NewError("Unsupported private key type %T; RSA or ECDSA P-256 keys are supported", key)
+}
+
+// makeToken returns a token signed with key granting scopes to subject for
+// audience until expiry.
+func makeToken(key crypto.Signer, subject string, scopes []string, audience, issuer, id string, now, expiry time.Time) (string, error) {
+	method, err := signingMethod(key)
+	if err != nil {
+		return "", err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	token := jwt.NewWithClaims(method, tokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   subject,
+			Audience:  audience,
+			Issuer:    issuer,
+			Id:        id,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiry.Unix(),
+		},
+		Scope: strings.Join(scopes, " "),
+	})
+	signed, err := token.SignedString(key)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "Error signing token")
+	}
+	return signed, nil
+}
+
+// splitScopes returns the scopes listed in s, separated by commas or spaces.
+func splitScopes(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+func main() {
+	flag.Parse()
+
+	if *sub == "" {
+		log.Fatal("--sub must not be empty")
+	}
+	if *expiresIn <= 0 {
+		log.Fatal("--expires_in must be positive")
+	}
+	data, err := os.ReadFile(*keyFile)
+	if err != nil {
+		log.Fatalf("Error reading private key file: %v", err)
+	}
+	key, err := signing.ParsePrivateKey(data)
+	if err != nil {
+		log.Fatalf("Error parsing private key file %s: %v", *keyFile, err)
+	}
+	id := *jti
+	if id == "" {
+		id = uuid.New().String()
+	}
+
+	now := time.Now()
+	token, err := makeToken(key, *sub, splitScopes(*scopes), *audience, *issuer, id, now, now.Add(*expiresIn))
+	if err != nil {
+		log.Fatalf("Error making token: %v", err)
+	}
+	fmt.Println(token)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/interuss/dss/pkg/api"
+	"github.com/interuss/dss/pkg/auth"
+	"github.com/interuss/dss/pkg/signing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakeTokenAcceptedByDevConfiguration(t *testing.T) {
+	data, err := os.ReadFile("../../build/test-certs/auth2.key")
+	require.NoError(t, err)
+	key, err := signing.ParsePrivateKey(data)
+	require.NoError(t, err)
+
+	now := time.Now()
+	token, err := makeToken(key, "uss1", splitScopes("dss.read.identification_service_areas,dss.write.identification_service_areas"), "localhost", "dummyoauth", "jti1", now, now.Add(time.Hour))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, err := auth.NewRSAAuthorizer(ctx, auth.Configuration{
+		KeyResolver:       &auth.FromFileKeyResolver{KeyFiles: []string{"../../build/test-certs/auth2.pem"}},
+		KeyRefreshTimeout: time.Hour,
+		AcceptedAudiences: []string{"localhost"},
+	})
+	require.NoError(t, err)
+	req := &http.Request{Method: http.MethodGet, Header: http.Header{"Authorization": {"Bearer " + token}}}
+	res := a.Authorize(nil, req, []api.AuthorizationOption{{"Authority": {"dss.write.identification_service_areas"}}})
+	require.NoError(t, res.Error)
+	require.Equal(t, "uss1", *res.ClientID)
+}
+
+func TestMakeTokenES256(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	now := time.Now()
+	token, err := makeToken(key, "uss1", []string{"dss.read.identification_service_areas"}, "localhost", "dummyoauth", "jti1", now, now.Add(time.Minute))
+	require.NoError(t, err)
+
+	claims := &tokenClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		require.Equal(t, "ES256", token.Method.Alg())
+		return &key.PublicKey, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "uss1", claims.Subject)
+	require.Equal(t, "localhost", claims.Audience)
+	require.Equal(t, "jti1", claims.Id)
+	require.Equal(t, "dss.read.identification_service_areas", claims.Scope)
+
+	key, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, err = makeToken(key, "uss1", nil, "localhost", "dummyoauth", "jti1", now, now.Add(time.Minute))
+	require.Error(t, err)
+}
+
+func TestSplitScopes(t *testing.T) {
+	require.Equal(t, []string{"a", "b", "c"}, splitScopes("a,b c, "))
+	require.Empty(t, splitScopes(""))
+}
@@ -9,7 +9,10 @@ import (
 var (
 	DssWriteIdentificationServiceAreasScope = api.RequiredScope("dss.write.identification_service_areas")
 	DssReadIdentificationServiceAreasScope  = api.RequiredScope("dss.read.identification_service_areas")
+	DssAdminScope                           = api.RequiredScope("dss.admin")
+	DssTransferOwnershipScope               = api.RequiredScope("dss.transfer_ownership")
 	GetVersionSecurity                      = []api.AuthorizationOption{}
+	GetCapabilitiesSecurity                 = []api.AuthorizationOption{}
 	ValidateOauthSecurity                   = []api.AuthorizationOption{
 		{
 			"Auth": {DssReadIdentificationServiceAreasScope},
@@ -18,6 +21,139 @@ var (
 			"Auth": {DssWriteIdentificationServiceAreasScope},
 		},
 	}
+	ReconcileISAsSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssAdminScope},
+		},
+	}
+	ExpireISAsSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssAdminScope},
+		},
+	}
+	TransferOwnershipSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssTransferOwnershipScope},
+		},
+	}
+	SearchISAsByURLSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssAdminScope},
+		},
+	}
+	CountISAsSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssReadIdentificationServiceAreasScope},
+		},
+		{
+			"Auth": {DssWriteIdentificationServiceAreasScope},
+		},
+	}
+	SearchISAExtentsSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssReadIdentificationServiceAreasScope},
+		},
+		{
+			"Auth": {DssWriteIdentificationServiceAreasScope},
+		},
+	}
+	SearchISAsGeoJSONSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssReadIdentificationServiceAreasScope},
+		},
+		{
+			"Auth": {DssWriteIdentificationServiceAreasScope},
+		},
+	}
+	GetISAExtentsSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssReadIdentificationServiceAreasScope},
+		},
+		{
+			"Auth": {DssWriteIdentificationServiceAreasScope},
+		},
+	}
+	GetCoveringSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssReadIdentificationServiceAreasScope},
+		},
+		{
+			"Auth": {DssWriteIdentificationServiceAreasScope},
+		},
+	}
+	GetRIDActivitySecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssAdminScope},
+		},
+	}
+	GetRIDTileSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssAdminScope},
+		},
+	}
+	SearchISAsByLabelsSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssAdminScope},
+		},
+	}
+	SetISALabelsSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssWriteIdentificationServiceAreasScope},
+		},
+	}
+	SearchSubscriptionsByLabelsSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssAdminScope},
+		},
+	}
+	SearchSubscriptionsByURLSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssReadIdentificationServiceAreasScope},
+		},
+	}
+	SetSubscriptionLabelsSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssReadIdentificationServiceAreasScope},
+		},
+	}
+	SetSubscriptionCallbacksSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssReadIdentificationServiceAreasScope},
+		},
+	}
+	SetSubscriptionNotificationIntervalSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssReadIdentificationServiceAreasScope},
+		},
+	}
+	SetSubscriptionSelfNotificationSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssReadIdentificationServiceAreasScope},
+		},
+	}
+	GetMyUsageSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssReadIdentificationServiceAreasScope},
+		},
+		{
+			"Auth": {DssWriteIdentificationServiceAreasScope},
+		},
+	}
+	ListOwnerAliasesSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssAdminScope},
+		},
+	}
+	SetOwnerAliasSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssAdminScope},
+		},
+	}
+	DeleteOwnerAliasSecurity = []api.AuthorizationOption{
+		{
+			"Auth": {DssAdminScope},
+		},
+	}
 )
 
 type GetVersionRequest struct {
@@ -32,6 +168,18 @@ type GetVersionResponseSet struct {
 	Response500 *api.InternalServerErrorBody
 }
 
+type GetCapabilitiesRequest struct {
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type GetCapabilitiesResponseSet struct {
+	// The capabilities of the DSS are successfully returned.
+	Response200 *CapabilitiesResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
 type ValidateOauthRequest struct {
 	// Validate the owner claim matches the provided owner.
 	Owner *string
@@ -53,10 +201,739 @@ type ValidateOauthResponseSet struct {
 	Response500 *api.InternalServerErrorBody
 }
 
-type Implementation interface {
-	// Queries the version of the DSS.
-	GetVersion(ctx context.Context, req *GetVersionRequest) GetVersionResponseSet
+type ReconcileISAsRequest struct {
+	// The data contained in the body of this request, if it parsed correctly
+	Body *ReconcileISAsParameters
 
-	// Validate Oauth token against the DSS.
-	ValidateOauth(ctx context.Context, req *ValidateOauthRequest) ValidateOauthResponseSet
+	// The error encountered when attempting to parse the body of this request
+	BodyParseError error
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type ReconcileISAsResponseSet struct {
+	// The ISAs of the owner were reconciled.
+	Response200 *ReconcileISAsResponse
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type ExpireISAsRequest struct {
+	// The data contained in the body of this request, if it parsed correctly
+	Body *ExpireISAsParameters
+
+	// The error encountered when attempting to parse the body of this request
+	BodyParseError error
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type ExpireISAsResponseSet struct {
+	// The active ISAs of the owner were expired.
+	Response200 *ExpireISAsResponse
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type TransferOwnershipRequest struct {
+	// The data contained in the body of this request, if it parsed correctly
+	Body *TransferOwnershipParameters
+
+	// The error encountered when attempting to parse the body of this request
+	BodyParseError error
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type TransferOwnershipResponseSet struct {
+	// The entities were transferred.
+	Response200 *TransferOwnershipResponse
+
+	// The request was malformed, or an entity is not owned by from_owner. No entity was transferred.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// An entity was not found. No entity was transferred.
+	Response404 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type SearchISAsByURLRequest struct {
+	// Flights URL of the ISAs.
+	Url *string
+
+	// Whether the flights URL of the ISAs is `url` (exact, the default) or starts with `url` (prefix).
+	Match *string
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type SearchISAsByURLResponseSet struct {
+	// The matching ISAs are returned.
+	Response200 *SearchISAsByURLResponse
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type CountISAsRequest struct {
+	// Polygon in the format of the area parameter of remote ID searches: comma-separated lat,lng pairs of at least 3 vertices.
+	Area *string
+
+	// Only ISAs ending at or after this RFC 3339 time are counted, as for remote ID searches; now if not specified.
+	EarliestTime *string
+
+	// Only ISAs starting at or before this RFC 3339 time are counted, as for remote ID searches.
+	LatestTime *string
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type CountISAsResponseSet struct {
+	// The number of matching ISAs is returned.
+	Response200 *ISACountResponse
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// The area is too large.
+	Response413 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type SearchISAExtentsRequest struct {
+	// Polygon in the format of the area parameter of remote ID searches: comma-separated lat,lng pairs of at least 3 vertices.
+	Area *string
+
+	// Only ISAs ending at or after this RFC 3339 time are returned, as for remote ID searches; now if not specified.
+	EarliestTime *string
+
+	// Only ISAs starting at or before this RFC 3339 time are returned, as for remote ID searches.
+	LatestTime *string
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type SearchISAExtentsResponseSet struct {
+	// The matching ISAs are returned with their extents.
+	Response200 *SearchISAExtentsResponse
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// The area is too large or too many ISAs were found.
+	Response413 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type SearchISAsGeoJSONRequest struct {
+	// Polygon in the format of the area parameter of remote ID searches: comma-separated lat,lng pairs of at least 3 vertices.
+	Area *string
+
+	// Only ISAs ending at or after this RFC 3339 time are returned, as for remote ID searches; now if not specified.
+	EarliestTime *string
+
+	// Only ISAs starting at or before this RFC 3339 time are returned, as for remote ID searches.
+	LatestTime *string
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type SearchISAsGeoJSONResponseSet struct {
+	// The matching ISAs are returned as a GeoJSON FeatureCollection.
+	Response200 *ISAFeatureCollection
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// The area is too large or too many ISAs were found.
+	Response413 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type GetISAExtentsRequest struct {
+	// ID of the ISA.
+	Id string
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type GetISAExtentsResponseSet struct {
+	// The extents of the ISA are returned.
+	Response200 *ISAExtentsResponse
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// The ISA was not found.
+	Response404 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type GetCoveringRequest struct {
+	// Polygon in the format of the area parameter of remote ID searches: comma-separated lat,lng pairs of at least 3 vertices.
+	Area *string
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type GetCoveringResponseSet struct {
+	// The covering of the area is returned.
+	Response200 *CoveringResponse
+
+	// The area is not a valid polygon.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type GetRIDActivityRequest struct {
+	// Number of hours of activity to return.
+	Hours *int32
+
+	// Duration of each bucket of activity, 60 if not specified.
+	BucketMinutes *int32
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type GetRIDActivityResponseSet struct {
+	// The activity of the remote ID pool is returned.
+	Response200 *RIDActivityResponse
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// This DSS instance does not record the activity of the remote ID pool.
+	Response404 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type GetRIDTileRequest struct {
+	// Zoom level of the tile, from 0 to 22.
+	Z string
+
+	// Column of the tile, from 0 (west) to 2^z - 1.
+	X string
+
+	// Row of the tile, from 0 (north) to 2^z - 1.
+	Y string
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type GetRIDTileResponseSet struct {
+	// The remote ID coverage of the tile is returned.
+	Response200 *RIDTileResponse
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// This DSS instance does not serve the map UI.
+	Response404 *ErrorResponse
+
+	// The tile is too large to be searched; a higher zoom level is required.
+	Response413 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type SearchISAsByLabelsRequest struct {
+	// Comma-separated key=value pairs that matching ISAs must all carry.
+	Labels *string
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type SearchISAsByLabelsResponseSet struct {
+	// The matching ISAs are returned.
+	Response200 *SearchLabeledEntitiesResponse
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type SetISALabelsRequest struct {
+	// ID of the ISA.
+	Id string
+
+	// The data contained in the body of this request, if it parsed correctly
+	Body *SetLabelsParameters
+
+	// The error encountered when attempting to parse the body of this request
+	BodyParseError error
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type SetISALabelsResponseSet struct {
+	// The labels of the ISA were replaced.
+	Response200 *LabeledEntity
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// The entity was not found.
+	Response404 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type SearchSubscriptionsByLabelsRequest struct {
+	// Comma-separated key=value pairs that matching subscriptions must all carry.
+	Labels *string
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type SearchSubscriptionsByLabelsResponseSet struct {
+	// The matching subscriptions are returned.
+	Response200 *SearchLabeledEntitiesResponse
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type SearchSubscriptionsByURLRequest struct {
+	// Text the callback URL of the subscriptions contains, e.g. the host of a decommissioned endpoint; all the subscriptions of the client are returned if not specified.
+	UrlContains *string
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type SearchSubscriptionsByURLResponseSet struct {
+	// The matching subscriptions are returned.
+	Response200 *SearchSubscriptionsByURLResponse
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type SetSubscriptionLabelsRequest struct {
+	// ID of the subscription.
+	Id string
+
+	// The data contained in the body of this request, if it parsed correctly
+	Body *SetLabelsParameters
+
+	// The error encountered when attempting to parse the body of this request
+	BodyParseError error
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type SetSubscriptionLabelsResponseSet struct {
+	// The labels of the subscription were replaced.
+	Response200 *LabeledEntity
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// The entity was not found.
+	Response404 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type SetSubscriptionCallbacksRequest struct {
+	// ID of the subscription.
+	Id string
+
+	// The data contained in the body of this request, if it parsed correctly
+	Body *SetSubscriptionCallbacksParameters
+
+	// The error encountered when attempting to parse the body of this request
+	BodyParseError error
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type SetSubscriptionCallbacksResponseSet struct {
+	// The callback URLs of the subscription were replaced.
+	Response200 *SubscriptionReference
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// The entity was not found.
+	Response404 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type SetSubscriptionNotificationIntervalRequest struct {
+	// ID of the subscription.
+	Id string
+
+	// The data contained in the body of this request, if it parsed correctly
+	Body *SetSubscriptionNotificationIntervalParameters
+
+	// The error encountered when attempting to parse the body of this request
+	BodyParseError error
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type SetSubscriptionNotificationIntervalResponseSet struct {
+	// The minimum notification interval of the subscription was replaced.
+	Response200 *SubscriptionReference
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// The entity was not found.
+	Response404 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type SetSubscriptionSelfNotificationRequest struct {
+	// ID of the subscription.
+	Id string
+
+	// The data contained in the body of this request, if it parsed correctly
+	Body *SetSubscriptionSelfNotificationParameters
+
+	// The error encountered when attempting to parse the body of this request
+	BodyParseError error
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type SetSubscriptionSelfNotificationResponseSet struct {
+	// Whether the subscription is notified of the ISAs of its owner was set.
+	Response200 *SubscriptionReference
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// The entity was not found.
+	Response404 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type GetMyUsageRequest struct {
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type GetMyUsageResponseSet struct {
+	// The usage of the client is returned.
+	Response200 *UsageResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type ListOwnerAliasesRequest struct {
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type ListOwnerAliasesResponseSet struct {
+	// The owner aliases are returned.
+	Response200 *OwnerAliasesResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type SetOwnerAliasRequest struct {
+	// The data contained in the body of this request, if it parsed correctly
+	Body *SetOwnerAliasParameters
+
+	// The error encountered when attempting to parse the body of this request
+	BodyParseError error
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type SetOwnerAliasResponseSet struct {
+	// The alias was set.
+	Response200 *OwnerAlias
+
+	// The request was malformed, or the alias would chain with another alias.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// The schema of the remote ID database does not store owner aliases.
+	Response404 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type DeleteOwnerAliasRequest struct {
+	// Subject whose alias to delete.
+	Subject *string
+
+	// The result of attempting to authorize this request
+	Auth api.AuthorizationResult
+}
+type DeleteOwnerAliasResponseSet struct {
+	// The alias was deleted.
+	Response200 *OwnerAlias
+
+	// The request was malformed.
+	Response400 *ErrorResponse
+
+	// Bearer access token was not provided in Authorization header, token could not be decoded, or token was invalid.
+	Response401 *ErrorResponse
+
+	// The access token was decoded successfully but did not include a scope appropriate to this endpoint.
+	Response403 *ErrorResponse
+
+	// The subject has no alias.
+	Response404 *ErrorResponse
+
+	// Auto-generated internal server error response
+	Response500 *api.InternalServerErrorBody
+}
+
+type Implementation interface {
+	// Queries the version of the DSS.
+	GetVersion(ctx context.Context, req *GetVersionRequest) GetVersionResponseSet
+
+	// Queries the APIs, limits and optional features supported by this DSS instance.
+	GetCapabilities(ctx context.Context, req *GetCapabilitiesRequest) GetCapabilitiesResponseSet
+
+	// Validate Oauth token against the DSS.
+	ValidateOauth(ctx context.Context, req *ValidateOauthRequest) ValidateOauthResponseSet
+
+	// Compares the set of active ISAs claimed by a USS against the ISAs indexed by the DSS for that USS and optionally removes the ISAs the USS does not claim.
+	ReconcileISAs(ctx context.Context, req *ReconcileISAsRequest) ReconcileISAsResponseSet
+
+	// Ends all the active ISAs of a USS now, in a single transaction, notifying their subscribers; used to cut off a USS publishing bogus airspace data.
+	ExpireISAs(ctx context.Context, req *ExpireISAsRequest) ExpireISAsResponseSet
+
+	// Transfers remote ID ISAs and subscriptions of a USS to another USS, e.g. when the former shuts down, rather than abandoning them. Every transfer is logged for audit.
+	TransferOwnership(ctx context.Context, req *TransferOwnershipRequest) TransferOwnershipResponseSet
+
+	// Searches the active remote ID ISAs of all owners by flights URL or URL prefix, e.g. to find the ISAs of a misbehaving feed.
+	SearchISAsByURL(ctx context.Context, req *SearchISAsByURLRequest) SearchISAsByURLResponseSet
+
+	// Counts the active remote ID ISAs a search would find without returning them, e.g. for display providers to decide whether to search or to ask the user to zoom in.
+	CountISAs(ctx context.Context, req *CountISAsRequest) CountISAsResponseSet
+
+	// Searches active remote ID ISAs like remote ID searches, returning their outlines and altitudes as written rather than the cell coverings the search matched, e.g. for display providers to discard ISAs not actually intersecting their view.
+	SearchISAExtents(ctx context.Context, req *SearchISAExtentsRequest) SearchISAExtentsResponseSet
+
+	// Searches active remote ID ISAs like remote ID searches, returning them as a GeoJSON FeatureCollection with their outlines, times, altitudes and owners, e.g. for GIS tools and map UIs to render the contents of the DSS.
+	SearchISAsGeoJSON(ctx context.Context, req *SearchISAsGeoJSONRequest) SearchISAsGeoJSONResponseSet
+
+	// Returns the 4D volume of a remote ID ISA as written, including the outline and altitudes which the ISAs of remote ID responses do not carry.
+	GetISAExtents(ctx context.Context, req *GetISAExtentsRequest) GetISAExtentsResponseSet
+
+	// Returns the S2 cells covering an area as computed by this DSS instance, and whether it exceeds the maximum area, e.g. for USS developers to understand unexpected search results.
+	GetCovering(ctx context.Context, req *GetCoveringRequest) GetCoveringResponseSet
+
+	// Returns time-bucketed counts of ISA creations and deletions and of active subscriptions over the last hours, e.g. for operators to plot the activity of the pool.
+	GetRIDActivity(ctx context.Context, req *GetRIDActivityRequest) GetRIDActivityResponseSet
+
+	// Returns the active remote ID ISAs of a Web Mercator map tile, with the numbers of active ISAs and subscriptions in the S2 cells of the tile, for the map UI to visualize the airspace coverage of the pool.
+	GetRIDTile(ctx context.Context, req *GetRIDTileRequest) GetRIDTileResponseSet
+
+	// Searches active remote ID ISAs by labels.
+	SearchISAsByLabels(ctx context.Context, req *SearchISAsByLabelsRequest) SearchISAsByLabelsResponseSet
+
+	// Replaces the labels of a remote ID ISA owned by the client.
+	SetISALabels(ctx context.Context, req *SetISALabelsRequest) SetISALabelsResponseSet
+
+	// Searches active remote ID subscriptions by labels.
+	SearchSubscriptionsByLabels(ctx context.Context, req *SearchSubscriptionsByLabelsRequest) SearchSubscriptionsByLabelsResponseSet
+
+	// Searches the active remote ID subscriptions owned by the client by callback URL, e.g. to find stale subscriptions pointing at decommissioned endpoints.
+	SearchSubscriptionsByURL(ctx context.Context, req *SearchSubscriptionsByURLRequest) SearchSubscriptionsByURLResponseSet
+
+	// Replaces the labels of a remote ID subscription owned by the client.
+	SetSubscriptionLabels(ctx context.Context, req *SetSubscriptionLabelsRequest) SetSubscriptionLabelsResponseSet
+
+	// Replaces the URLs to which a remote ID subscription owned by the client is notified besides its callback URL.
+	SetSubscriptionCallbacks(ctx context.Context, req *SetSubscriptionCallbacksRequest) SetSubscriptionCallbacksResponseSet
+
+	// Replaces the minimum interval between the notifications of a remote ID subscription owned by the client.
+	SetSubscriptionNotificationInterval(ctx context.Context, req *SetSubscriptionNotificationIntervalRequest) SetSubscriptionNotificationIntervalResponseSet
+
+	// Sets whether a remote ID subscription owned by the client is notified of the changes to the ISAs of the client.
+	SetSubscriptionSelfNotification(ctx context.Context, req *SetSubscriptionSelfNotificationRequest) SetSubscriptionSelfNotificationResponseSet
+
+	// Returns the remote ID entities, quotas and recently rejected requests of the client, so that USSs can diagnose quota or limit problems themselves.
+	GetMyUsage(ctx context.Context, req *GetMyUsageRequest) GetMyUsageResponseSet
+
+	// Lists the subjects of access tokens acting as another, canonical owner.
+	ListOwnerAliases(ctx context.Context, req *ListOwnerAliasesRequest) ListOwnerAliasesResponseSet
+
+	// Makes the access tokens of a subject act as a canonical owner, e.g. so that a USS migrating to another authorization server keeps access to its entities. Aliases take effect on all the DSS instances within the owner alias refresh interval, and every change is logged for audit.
+	SetOwnerAlias(ctx context.Context, req *SetOwnerAliasRequest) SetOwnerAliasResponseSet
+
+	// Deletes the alias of a subject, whose access tokens then act as the subject again.
+	DeleteOwnerAlias(ctx context.Context, req *DeleteOwnerAliasRequest) DeleteOwnerAliasResponseSet
 }
@@ -3,9 +3,11 @@ package auxv1
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/interuss/dss/pkg/api"
 	"net/http"
 	"regexp"
+	"strconv"
 )
 
 type APIRouter struct {
@@ -48,6 +50,29 @@ func (s *APIRouter) GetVersion(exp *regexp.Regexp, w http.ResponseWriter, r *htt
 	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
 }
 
+func (s *APIRouter) GetCapabilities(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req GetCapabilitiesRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, GetCapabilitiesSecurity)
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.GetCapabilities(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
 func (s *APIRouter) ValidateOauth(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
 	var req ValidateOauthRequest
 
@@ -87,14 +112,1136 @@ func (s *APIRouter) ValidateOauth(exp *regexp.Regexp, w http.ResponseWriter, r *
 	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
 }
 
-func MakeAPIRouter(impl Implementation, auth api.Authorizer) APIRouter {
-	router := APIRouter{Implementation: impl, Authorizer: auth, Routes: make([]*api.Route, 2)}
+func (s *APIRouter) ReconcileISAs(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req ReconcileISAsRequest
 
-	pattern := regexp.MustCompile("^/aux/v1/version$")
-	router.Routes[0] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.GetVersion}
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, ReconcileISAsSecurity)
 
-	pattern = regexp.MustCompile("^/aux/v1/validate_oauth$")
-	router.Routes[1] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.ValidateOauth}
+	// Parse request body
+	req.Body = new(ReconcileISAsParameters)
+	defer r.Body.Close()
+	req.BodyParseError = json.NewDecoder(r.Body).Decode(req.Body)
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.ReconcileISAs(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) ExpireISAs(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req ExpireISAsRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, ExpireISAsSecurity)
+
+	// Parse request body
+	req.Body = new(ExpireISAsParameters)
+	defer r.Body.Close()
+	req.BodyParseError = json.NewDecoder(r.Body).Decode(req.Body)
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.ExpireISAs(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) TransferOwnership(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req TransferOwnershipRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, TransferOwnershipSecurity)
+
+	// Parse request body
+	req.Body = new(TransferOwnershipParameters)
+	defer r.Body.Close()
+	req.BodyParseError = json.NewDecoder(r.Body).Decode(req.Body)
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.TransferOwnership(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response404 != nil {
+		api.WriteJSON(w, 404, response.Response404)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) SearchISAsByURL(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req SearchISAsByURLRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, SearchISAsByURLSecurity)
+
+	// Copy query parameters
+	query := r.URL.Query()
+	// TODO: Change to query.Has after Go 1.17
+	if query.Get("url") != "" {
+		v := query.Get("url")
+		req.Url = &v
+	}
+	if query.Get("match") != "" {
+		v := query.Get("match")
+		req.Match = &v
+	}
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.SearchISAsByURL(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) CountISAs(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req CountISAsRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, CountISAsSecurity)
+
+	// Copy query parameters
+	query := r.URL.Query()
+	// TODO: Change to query.Has after Go 1.17
+	if query.Get("area") != "" {
+		v := query.Get("area")
+		req.Area = &v
+	}
+	if query.Get("earliest_time") != "" {
+		v := query.Get("earliest_time")
+		req.EarliestTime = &v
+	}
+	if query.Get("latest_time") != "" {
+		v := query.Get("latest_time")
+		req.LatestTime = &v
+	}
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.CountISAs(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response413 != nil {
+		api.WriteJSON(w, 413, response.Response413)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) SearchISAExtents(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req SearchISAExtentsRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, SearchISAExtentsSecurity)
+
+	// Copy query parameters
+	query := r.URL.Query()
+	// TODO: Change to query.Has after Go 1.17
+	if query.Get("area") != "" {
+		v := query.Get("area")
+		req.Area = &v
+	}
+	if query.Get("earliest_time") != "" {
+		v := query.Get("earliest_time")
+		req.EarliestTime = &v
+	}
+	if query.Get("latest_time") != "" {
+		v := query.Get("latest_time")
+		req.LatestTime = &v
+	}
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.SearchISAExtents(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response413 != nil {
+		api.WriteJSON(w, 413, response.Response413)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) SearchISAsGeoJSON(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req SearchISAsGeoJSONRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, SearchISAsGeoJSONSecurity)
+
+	// Copy query parameters
+	query := r.URL.Query()
+	// TODO: Change to query.Has after Go 1.17
+	if query.Get("area") != "" {
+		v := query.Get("area")
+		req.Area = &v
+	}
+	if query.Get("earliest_time") != "" {
+		v := query.Get("earliest_time")
+		req.EarliestTime = &v
+	}
+	if query.Get("latest_time") != "" {
+		v := query.Get("latest_time")
+		req.LatestTime = &v
+	}
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.SearchISAsGeoJSON(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response413 != nil {
+		api.WriteJSON(w, 413, response.Response413)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) GetISAExtents(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req GetISAExtentsRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, GetISAExtentsSecurity)
+
+	// Parse path parameters
+	pathMatch := exp.FindStringSubmatch(r.URL.Path)
+	req.Id = pathMatch[1]
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.GetISAExtents(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response404 != nil {
+		api.WriteJSON(w, 404, response.Response404)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) GetCovering(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req GetCoveringRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, GetCoveringSecurity)
+
+	// Copy query parameters
+	query := r.URL.Query()
+	// TODO: Change to query.Has after Go 1.17
+	if query.Get("area") != "" {
+		v := query.Get("area")
+		req.Area = &v
+	}
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.GetCovering(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) GetRIDActivity(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req GetRIDActivityRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, GetRIDActivitySecurity)
+
+	// Copy query parameters
+	query := r.URL.Query()
+	// TODO: Change to query.Has after Go 1.17
+	if query.Get("hours") != "" {
+		i, err := strconv.ParseInt(query.Get("hours"), 10, 32)
+		if err == nil {
+			v := int32(i)
+			req.Hours = &v
+		}
+	}
+	if query.Get("bucket_minutes") != "" {
+		i, err := strconv.ParseInt(query.Get("bucket_minutes"), 10, 32)
+		if err == nil {
+			v := int32(i)
+			req.BucketMinutes = &v
+		}
+	}
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.GetRIDActivity(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response404 != nil {
+		api.WriteJSON(w, 404, response.Response404)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) GetRIDTile(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req GetRIDTileRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, GetRIDTileSecurity)
+
+	// Parse path parameters
+	pathMatch := exp.FindStringSubmatch(r.URL.Path)
+	req.Z = pathMatch[1]
+	req.X = pathMatch[2]
+	req.Y = pathMatch[3]
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.GetRIDTile(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response404 != nil {
+		api.WriteJSON(w, 404, response.Response404)
+		return
+	}
+	if response.Response413 != nil {
+		api.WriteJSON(w, 413, response.Response413)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) SearchISAsByLabels(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req SearchISAsByLabelsRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, SearchISAsByLabelsSecurity)
+
+	// Copy query parameters
+	query := r.URL.Query()
+	// TODO: Change to query.Has after Go 1.17
+	if query.Get("labels") != "" {
+		v := query.Get("labels")
+		req.Labels = &v
+	}
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.SearchISAsByLabels(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) SetISALabels(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req SetISALabelsRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, SetISALabelsSecurity)
+
+	// Parse path parameters
+	pathMatch := exp.FindStringSubmatch(r.URL.Path)
+	req.Id = pathMatch[1]
+
+	// Parse request body
+	req.Body = new(SetLabelsParameters)
+	defer r.Body.Close()
+	req.BodyParseError = json.NewDecoder(r.Body).Decode(req.Body)
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.SetISALabels(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response404 != nil {
+		api.WriteJSON(w, 404, response.Response404)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) SearchSubscriptionsByLabels(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req SearchSubscriptionsByLabelsRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, SearchSubscriptionsByLabelsSecurity)
+
+	// Copy query parameters
+	query := r.URL.Query()
+	// TODO: Change to query.Has after Go 1.17
+	if query.Get("labels") != "" {
+		v := query.Get("labels")
+		req.Labels = &v
+	}
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.SearchSubscriptionsByLabels(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) SearchSubscriptionsByURL(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req SearchSubscriptionsByURLRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, SearchSubscriptionsByURLSecurity)
+
+	// Copy query parameters
+	query := r.URL.Query()
+	// TODO: Change to query.Has after Go 1.17
+	if query.Get("url_contains") != "" {
+		v := query.Get("url_contains")
+		req.UrlContains = &v
+	}
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.SearchSubscriptionsByURL(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) SetSubscriptionLabels(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req SetSubscriptionLabelsRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, SetSubscriptionLabelsSecurity)
+
+	// Parse path parameters
+	pathMatch := exp.FindStringSubmatch(r.URL.Path)
+	req.Id = pathMatch[1]
+
+	// Parse request body
+	req.Body = new(SetLabelsParameters)
+	defer r.Body.Close()
+	req.BodyParseError = json.NewDecoder(r.Body).Decode(req.Body)
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.SetSubscriptionLabels(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response404 != nil {
+		api.WriteJSON(w, 404, response.Response404)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) SetSubscriptionCallbacks(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req SetSubscriptionCallbacksRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, SetSubscriptionCallbacksSecurity)
+
+	// Parse path parameters
+	pathMatch := exp.FindStringSubmatch(r.URL.Path)
+	req.Id = pathMatch[1]
+
+	// Parse request body
+	req.Body = new(SetSubscriptionCallbacksParameters)
+	defer r.Body.Close()
+	req.BodyParseError = json.NewDecoder(r.Body).Decode(req.Body)
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.SetSubscriptionCallbacks(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response404 != nil {
+		api.WriteJSON(w, 404, response.Response404)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) SetSubscriptionNotificationInterval(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req SetSubscriptionNotificationIntervalRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, SetSubscriptionNotificationIntervalSecurity)
+
+	// Parse path parameters
+	pathMatch := exp.FindStringSubmatch(r.URL.Path)
+	req.Id = pathMatch[1]
+
+	// Parse request body
+	req.Body = new(SetSubscriptionNotificationIntervalParameters)
+	defer r.Body.Close()
+	req.BodyParseError = json.NewDecoder(r.Body).Decode(req.Body)
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.SetSubscriptionNotificationInterval(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response404 != nil {
+		api.WriteJSON(w, 404, response.Response404)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) SetSubscriptionSelfNotification(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req SetSubscriptionSelfNotificationRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, SetSubscriptionSelfNotificationSecurity)
+
+	// Parse path parameters
+	pathMatch := exp.FindStringSubmatch(r.URL.Path)
+	req.Id = pathMatch[1]
+
+	// Parse request body
+	req.Body = new(SetSubscriptionSelfNotificationParameters)
+	defer r.Body.Close()
+	req.BodyParseError = json.NewDecoder(r.Body).Decode(req.Body)
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.SetSubscriptionSelfNotification(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response404 != nil {
+		api.WriteJSON(w, 404, response.Response404)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) GetMyUsage(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req GetMyUsageRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, GetMyUsageSecurity)
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.GetMyUsage(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) ListOwnerAliases(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req ListOwnerAliasesRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, ListOwnerAliasesSecurity)
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.ListOwnerAliases(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) SetOwnerAlias(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req SetOwnerAliasRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, SetOwnerAliasSecurity)
+
+	// Parse request body
+	req.Body = new(SetOwnerAliasParameters)
+	defer r.Body.Close()
+	req.BodyParseError = json.NewDecoder(r.Body).Decode(req.Body)
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.SetOwnerAlias(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response404 != nil {
+		api.WriteJSON(w, 404, response.Response404)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func (s *APIRouter) DeleteOwnerAlias(exp *regexp.Regexp, w http.ResponseWriter, r *http.Request) {
+	var req DeleteOwnerAliasRequest
+
+	// Authorize request
+	req.Auth = s.Authorizer.Authorize(w, r, DeleteOwnerAliasSecurity)
+
+	// Copy query parameters
+	query := r.URL.Query()
+	// TODO: Change to query.Has after Go 1.17
+	if query.Get("subject") != "" {
+		v := query.Get("subject")
+		req.Subject = &v
+	}
+
+	// Call implementation
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	response := s.Implementation.DeleteOwnerAlias(ctx, &req)
+
+	// Write response to client
+	if response.Response200 != nil {
+		api.WriteJSON(w, 200, response.Response200)
+		return
+	}
+	if response.Response400 != nil {
+		api.WriteJSON(w, 400, response.Response400)
+		return
+	}
+	if response.Response401 != nil {
+		api.WriteJSON(w, 401, response.Response401)
+		return
+	}
+	if response.Response403 != nil {
+		api.WriteJSON(w, 403, response.Response403)
+		return
+	}
+	if response.Response404 != nil {
+		api.WriteJSON(w, 404, response.Response404)
+		return
+	}
+	if response.Response500 != nil {
+		api.WriteJSON(w, 500, response.Response500)
+		return
+	}
+	api.WriteJSON(w, 500, api.InternalServerErrorBody{ErrorMessage: "Handler implementation did not set a response"})
+}
+
+func MakeAPIRouter(impl Implementation, auth api.Authorizer) APIRouter {
+	router := APIRouter{Implementation: impl, Authorizer: auth, Routes: make([]*api.Route, 26)}
+
+	pattern := regexp.MustCompile("^/aux/v1/version$")
+	router.Routes[0] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.GetVersion}
+
+	pattern = regexp.MustCompile("^/aux/v1/capabilities$")
+	router.Routes[1] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.GetCapabilities}
+
+	pattern = regexp.MustCompile("^/aux/v1/validate_oauth$")
+	router.Routes[2] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.ValidateOauth}
+
+	pattern = regexp.MustCompile("^/aux/v1/reconciliation/rid/isas$")
+	router.Routes[3] = &api.Route{Method: http.MethodPost, Pattern: pattern, Handler: router.ReconcileISAs}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/identification_service_areas/expire$")
+	router.Routes[4] = &api.Route{Method: http.MethodPost, Pattern: pattern, Handler: router.ExpireISAs}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/transfer_ownership$")
+	router.Routes[5] = &api.Route{Method: http.MethodPost, Pattern: pattern, Handler: router.TransferOwnership}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/identification_service_areas/by_url$")
+	router.Routes[6] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.SearchISAsByURL}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/identification_service_areas/count$")
+	router.Routes[7] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.CountISAs}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/identification_service_areas/extents$")
+	router.Routes[8] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.SearchISAExtents}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/identification_service_areas/geojson$")
+	router.Routes[9] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.SearchISAsGeoJSON}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/identification_service_areas/(?P<id>[^/]*)/extents$")
+	router.Routes[10] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.GetISAExtents}
+
+	pattern = regexp.MustCompile("^/aux/v1/covering$")
+	router.Routes[11] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.GetCovering}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/activity$")
+	router.Routes[12] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.GetRIDActivity}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/tiles/(?P<z>[^/]*)/(?P<x>[^/]*)/(?P<y>[^/]*)$")
+	router.Routes[13] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.GetRIDTile}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/identification_service_areas$")
+	router.Routes[14] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.SearchISAsByLabels}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/identification_service_areas/(?P<id>[^/]*)/labels$")
+	router.Routes[15] = &api.Route{Method: http.MethodPut, Pattern: pattern, Handler: router.SetISALabels}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/subscriptions$")
+	router.Routes[16] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.SearchSubscriptionsByLabels}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/subscriptions/by_url$")
+	router.Routes[17] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.SearchSubscriptionsByURL}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/subscriptions/(?P<id>[^/]*)/labels$")
+	router.Routes[18] = &api.Route{Method: http.MethodPut, Pattern: pattern, Handler: router.SetSubscriptionLabels}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/subscriptions/(?P<id>[^/]*)/callbacks$")
+	router.Routes[19] = &api.Route{Method: http.MethodPut, Pattern: pattern, Handler: router.SetSubscriptionCallbacks}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/subscriptions/(?P<id>[^/]*)/notification_interval$")
+	router.Routes[20] = &api.Route{Method: http.MethodPut, Pattern: pattern, Handler: router.SetSubscriptionNotificationInterval}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/subscriptions/(?P<id>[^/]*)/self_notification$")
+	router.Routes[21] = &api.Route{Method: http.MethodPut, Pattern: pattern, Handler: router.SetSubscriptionSelfNotification}
+
+	pattern = regexp.MustCompile("^/aux/v1/rid/usage$")
+	router.Routes[22] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.GetMyUsage}
+
+	pattern = regexp.MustCompile("^/aux/v1/owner_aliases$")
+	router.Routes[23] = &api.Route{Method: http.MethodGet, Pattern: pattern, Handler: router.ListOwnerAliases}
+
+	pattern = regexp.MustCompile("^/aux/v1/owner_aliases$")
+	router.Routes[24] = &api.Route{Method: http.MethodPut, Pattern: pattern, Handler: router.SetOwnerAlias}
+
+	pattern = regexp.MustCompile("^/aux/v1/owner_aliases$")
+	router.Routes[25] = &api.Route{Method: http.MethodDelete, Pattern: pattern, Handler: router.DeleteOwnerAlias}
 
 	return router
 }
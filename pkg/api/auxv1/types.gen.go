@@ -6,6 +6,489 @@ type VersionResponse struct {
 	Version string `json:"version"`
 }
 
+type ClaimedISA struct {
+	// ID of an ISA the USS believes to be active in the DSS.
+	Id string `json:"id"`
+
+	// Version of the ISA known to the USS, if any.
+	Version *string `json:"version,omitempty"`
+}
+
+type ReconcileISAsParameters struct {
+	// Owner whose ISAs are reconciled.
+	Owner string `json:"owner"`
+
+	// Complete set of active ISAs claimed by the USS.
+	Isas []ClaimedISA `json:"isas"`
+
+	// If true, ISAs indexed by the DSS for the owner but not claimed by the USS are removed, notifying their subscribers.
+	Repair *bool `json:"repair,omitempty"`
+}
+
+type ISAVersionMismatch struct {
+	Id string `json:"id"`
+
+	// Version of the ISA indexed by the DSS.
+	DssVersion string `json:"dss_version"`
+
+	// Version of the ISA claimed by the USS.
+	ClaimedVersion string `json:"claimed_version"`
+}
+
+type ReconcileISAsResponse struct {
+	// IDs of ISAs claimed by the USS but not indexed by the DSS.
+	Missing []string `json:"missing"`
+
+	// IDs of ISAs indexed by the DSS but not claimed by the USS.
+	Unclaimed []string `json:"unclaimed"`
+
+	// ISAs known to both with differing versions.
+	VersionMismatches []ISAVersionMismatch `json:"version_mismatches"`
+
+	// IDs of unclaimed ISAs removed from the DSS when repair was requested.
+	Removed []string `json:"removed"`
+}
+
+type ExpireISAsParameters struct {
+	// Owner whose active ISAs are expired.
+	Owner string `json:"owner"`
+
+	// Why the ISAs of the owner are expired, recorded in the audit log.
+	Reason string `json:"reason"`
+}
+
+type ExpireISAsResponse struct {
+	// IDs of the ISAs that were expired.
+	Expired []string `json:"expired"`
+}
+
+type TransferOwnershipParameters struct {
+	// Current owner of the transferred entities, e.g. a USS winding down.
+	FromOwner string `json:"from_owner"`
+
+	// Owner to which the entities are transferred.
+	ToOwner string `json:"to_owner"`
+
+	// Why the entities are transferred, recorded in the audit log.
+	Reason string `json:"reason"`
+
+	// IDs of the remote ID ISAs of from_owner to transfer.
+	Isas *[]string `json:"isas,omitempty"`
+
+	// IDs of the remote ID subscriptions of from_owner to transfer.
+	Subscriptions *[]string `json:"subscriptions,omitempty"`
+}
+
+type TransferredEntity struct {
+	Id string `json:"id"`
+
+	// Version of the entity after the transfer, with which its new owner may update it.
+	Version string `json:"version"`
+}
+
+type TransferOwnershipResponse struct {
+	// The transferred ISAs.
+	Isas []TransferredEntity `json:"isas"`
+
+	// The transferred subscriptions.
+	Subscriptions []TransferredEntity `json:"subscriptions"`
+
+	// IDs of the subscriptions whose notification index was incremented because of the transferred ISAs.
+	NotifiedSubscriptions []string `json:"notified_subscriptions"`
+}
+
+type ISAReference struct {
+	Id string `json:"id"`
+
+	Owner string `json:"owner"`
+
+	// Flights URL of the ISA.
+	UssBaseUrl string `json:"uss_base_url"`
+
+	Version string `json:"version"`
+
+	// Start time of the ISA, in RFC 3339 format.
+	TimeStart string `json:"time_start"`
+
+	// End time of the ISA, in RFC 3339 format.
+	TimeEnd string `json:"time_end"`
+}
+
+type SearchISAsByURLResponse struct {
+	// Active ISAs of all owners referencing the requested URL.
+	ServiceAreas []ISAReference `json:"service_areas"`
+}
+
+type SubscriptionReference struct {
+	Id string `json:"id"`
+
+	Owner string `json:"owner"`
+
+	// URL to which the subscriber is notified of changes to ISAs.
+	CallbackUrl string `json:"callback_url"`
+
+	// URLs to which the subscriber is notified besides callback_url, e.g. the distinct endpoints of future entity types.
+	CallbackUrls *[]string `json:"callback_urls,omitempty"`
+
+	Version string `json:"version"`
+
+	// Number of notifications of changes to ISAs sent to the subscriber.
+	NotificationIndex int32 `json:"notification_index"`
+
+	// Start time of the subscription, in RFC 3339 format.
+	TimeStart string `json:"time_start"`
+
+	// End time of the subscription, in RFC 3339 format.
+	TimeEnd string `json:"time_end"`
+
+	// Minimum interval between the notifications of the subscriber, within which the changes to ISAs after a notification increment the notification index without being notified.
+	MinNotificationIntervalSeconds *int32 `json:"min_notification_interval_seconds,omitempty"`
+
+	// Whether the subscriber is notified of the changes to the ISAs of its owner too.
+	NotifyOwnIsas *bool `json:"notify_own_isas,omitempty"`
+}
+
+type SearchSubscriptionsByURLResponse struct {
+	// Active subscriptions of the client whose callback URL matches.
+	Subscriptions []SubscriptionReference `json:"subscriptions"`
+}
+
+type SetSubscriptionCallbacksParameters struct {
+	// URLs replacing all the URLs to which the subscriber is notified besides the callback URL of the subscription.
+	CallbackUrls []string `json:"callback_urls"`
+}
+
+type SetSubscriptionNotificationIntervalParameters struct {
+	// Minimum interval between the notifications of the subscriber, up to 3600 seconds, or 0 to notify the subscriber of every change.
+	MinNotificationIntervalSeconds int32 `json:"min_notification_interval_seconds"`
+}
+
+type SetSubscriptionSelfNotificationParameters struct {
+	// Whether the subscriber is notified of the changes to the ISAs of its owner too, e.g. when the systems of the USS publishing ISAs and monitoring them are separate.
+	NotifyOwnIsas bool `json:"notify_own_isas"`
+}
+
+type UsageResponse struct {
+	// Owner as which the client acts.
+	Owner string `json:"owner"`
+
+	// Number of remote ID ISAs of the client which have not ended.
+	ActiveIsas int32 `json:"active_isas"`
+
+	// Number of remote ID subscriptions of the client which have not ended.
+	ActiveSubscriptions int32 `json:"active_subscriptions"`
+
+	Limits UsageLimits `json:"limits"`
+
+	RecentRejections *RecentRejections `json:"recent_rejections,omitempty"`
+}
+
+type UsageLimits struct {
+	// Number of remote ID subscriptions the client may have in any area.
+	MaxSubscriptionsPerArea int32 `json:"max_subscriptions_per_area"`
+
+	// Maximum number of entities returned by a search.
+	MaxResults int32 `json:"max_results"`
+}
+
+type RecentRejections struct {
+	// Period, ending now, over which the rejections of the client are counted.
+	WindowSeconds int32 `json:"window_seconds"`
+
+	// Number of requests of the client rejected with a 4xx status by the DSS instance serving this request, by status, in increasing order of status.
+	Counts []RejectionCount `json:"counts"`
+}
+
+type RejectionCount struct {
+	// HTTP status of the rejections.
+	Status int32 `json:"status"`
+
+	// Number of requests rejected with status.
+	Count int32 `json:"count"`
+}
+
+type OwnerAlias struct {
+	// Subject of the access tokens acting as owner.
+	Subject string `json:"subject"`
+
+	// Canonical owner as which the access tokens of subject act.
+	Owner string `json:"owner"`
+
+	// Time at which the alias was last set, in RFC 3339 format.
+	UpdatedAt *string `json:"updated_at,omitempty"`
+}
+
+type SetOwnerAliasParameters struct {
+	// Subject of the access tokens to map, e.g. the subject of a USS at the authorization server it migrates to.
+	Subject string `json:"subject"`
+
+	// Canonical owner as which the access tokens of subject act, e.g. the former subject of the USS.
+	Owner string `json:"owner"`
+
+	// Why the alias is set, recorded in the audit log.
+	Reason string `json:"reason"`
+}
+
+type OwnerAliasesResponse struct {
+	// All the owner aliases, ordered by subject.
+	Aliases []OwnerAlias `json:"aliases"`
+}
+
+type GeoJSONPolygonCoordinatesItemItem []float64
+
+type GeoJSONPolygonCoordinatesItem []GeoJSONPolygonCoordinatesItemItem
+
+// Polygon geometry of GeoJSON (RFC 7946).
+type GeoJSONPolygon struct {
+	// Always Polygon.
+	Type string `json:"type"`
+
+	// Linear rings of [longitude, latitude] positions, the first one being the outline; the last position of each ring repeats the first one.
+	Coordinates []GeoJSONPolygonCoordinatesItem `json:"coordinates"`
+}
+
+type ISAFeatureProperties struct {
+	// Owner of the ISA.
+	Owner string `json:"owner"`
+
+	// Version of the ISA.
+	Version string `json:"version"`
+
+	// Flights URL of the ISA.
+	FlightsUrl string `json:"flights_url"`
+
+	// Start time of the ISA, in RFC 3339 format.
+	TimeStart *string `json:"time_start,omitempty"`
+
+	// End time of the ISA, in RFC 3339 format.
+	TimeEnd *string `json:"time_end,omitempty"`
+
+	// Lower altitude of the ISA, in meters above the WGS84 ellipsoid.
+	AltitudeLower *float32 `json:"altitude_lower,omitempty"`
+
+	// Upper altitude of the ISA, in meters above the WGS84 ellipsoid.
+	AltitudeUpper *float32 `json:"altitude_upper,omitempty"`
+}
+
+// GeoJSON Feature of an ISA.
+type ISAFeature struct {
+	// Always Feature.
+	Type string `json:"type"`
+
+	// ID of the ISA.
+	Id string `json:"id"`
+
+	Geometry GeoJSONPolygon `json:"geometry"`
+
+	Properties ISAFeatureProperties `json:"properties"`
+}
+
+// GeoJSON FeatureCollection of ISAs.
+type ISAFeatureCollection struct {
+	// Always FeatureCollection.
+	Type string `json:"type"`
+
+	// The ISAs a remote ID search of the area and time range would find, except those last written before the database stored their outline. Circular ISAs are approximated by polygons.
+	Features []ISAFeature `json:"features"`
+}
+
+type CellDensity struct {
+	// Token of the S2 cell.
+	Cell string `json:"cell"`
+
+	// Level of the S2 cell.
+	Level int32 `json:"level"`
+
+	// Number of active ISAs in the cell.
+	IsaCount int32 `json:"isa_count"`
+
+	// Number of active subscriptions in the cell.
+	SubscriptionCount int32 `json:"subscription_count"`
+
+	Outline GeoJSONPolygon `json:"outline"`
+}
+
+type RIDTileResponse struct {
+	Isas ISAFeatureCollection `json:"isas"`
+
+	// The S2 cells of the tile containing active ISAs or subscriptions; each entity is counted once per cell.
+	Cells []CellDensity `json:"cells"`
+}
+
+type RIDActivityBucket struct {
+	// Start of the bucket, in RFC 3339 format.
+	TimeStart string `json:"time_start"`
+
+	// End of the bucket, in RFC 3339 format.
+	TimeEnd string `json:"time_end"`
+
+	// Number of ISAs created in the bucket.
+	IsasCreated int32 `json:"isas_created"`
+
+	// Number of ISAs deleted in the bucket, including expired ISAs removed.
+	IsasDeleted int32 `json:"isas_deleted"`
+
+	// Number of subscriptions active at the end of the bucket.
+	ActiveSubscriptions int32 `json:"active_subscriptions"`
+}
+
+type RIDActivityResponse struct {
+	// Consecutive buckets of activity, the last one ending at the time of the request.
+	Buckets []RIDActivityBucket `json:"buckets"`
+}
+
+type ISACountResponse struct {
+	// Number of active ISAs intersecting the area and time range, counted up to the most entities any search returns.
+	Count int32 `json:"count"`
+
+	// Most ISAs a search with the same headers returns, beyond which it is truncated or rejected; if count exceeds it, clients should narrow the area rather than search.
+	MaxResults int32 `json:"max_results"`
+}
+
+type CoveringCell struct {
+	// Token of the S2 cell.
+	Token string `json:"token"`
+
+	// Level of the S2 cell.
+	Level int32 `json:"level"`
+}
+
+type CoveringResponse struct {
+	// Area of the polygon, in square kilometers.
+	AreaKm2 float64 `json:"area_km2"`
+
+	// Largest area, in square kilometers, that the DSS covers.
+	MaxAreaKm2 float64 `json:"max_area_km2"`
+
+	// Whether the area exceeds max_area_km2, in which case the DSS rejects searches and entities in it as too large and no cell is returned.
+	ExceedsMaxArea bool `json:"exceeds_max_area"`
+
+	// Coarsest level of the cells of the coverings computed by this DSS instance.
+	MinLevel int32 `json:"min_level"`
+
+	// Finest level of the cells of the coverings computed by this DSS instance.
+	MaxLevel int32 `json:"max_level"`
+
+	// S2 cells covering the area, as searched or stored by the DSS.
+	Cells []CoveringCell `json:"cells"`
+
+	// Area of the cells, in square kilometers, which exceeds area_km2 by up to the area of the cells along its boundary.
+	CellsAreaKm2 float64 `json:"cells_area_km2"`
+}
+
+type LatLngPoint struct {
+	// Latitude, in degrees.
+	Lat float64 `json:"lat"`
+
+	// Longitude, in degrees.
+	Lng float64 `json:"lng"`
+}
+
+type Polygon struct {
+	Vertices []LatLngPoint `json:"vertices"`
+}
+
+type Circle struct {
+	Center LatLngPoint `json:"center"`
+
+	// Radius of the circle, in meters.
+	RadiusM float32 `json:"radius_m"`
+}
+
+type Volume3D struct {
+	OutlinePolygon *Polygon `json:"outline_polygon,omitempty"`
+
+	OutlineCircle *Circle `json:"outline_circle,omitempty"`
+
+	// Lower altitude of the volume, in meters above the WGS84 ellipsoid.
+	AltitudeLower *float32 `json:"altitude_lower,omitempty"`
+
+	// Upper altitude of the volume, in meters above the WGS84 ellipsoid.
+	AltitudeUpper *float32 `json:"altitude_upper,omitempty"`
+}
+
+type Volume4D struct {
+	Volume Volume3D `json:"volume"`
+
+	// Start time of the volume, in RFC 3339 format.
+	TimeStart *string `json:"time_start,omitempty"`
+
+	// End time of the volume, in RFC 3339 format.
+	TimeEnd *string `json:"time_end,omitempty"`
+}
+
+type ISAExtentsResponse struct {
+	Id string `json:"id"`
+
+	Version string `json:"version"`
+
+	// Extents of the ISA as last written. The outline and altitudes are missing for ISAs last written before the database stored them.
+	Extents Volume4D `json:"extents"`
+}
+
+type SearchISAExtentsResponse struct {
+	// The ISAs a remote ID search of the area and time range would find, with their extents.
+	ServiceAreas []ISAExtentsResponse `json:"service_areas"`
+}
+
+type Label struct {
+	// Key of the label, unique within an entity.
+	Key string `json:"key"`
+
+	Value string `json:"value"`
+}
+
+type SetLabelsParameters struct {
+	// Labels replacing all the existing labels of the entity.
+	Labels []Label `json:"labels"`
+}
+
+type LabeledEntity struct {
+	Id string `json:"id"`
+
+	Owner string `json:"owner"`
+
+	Version string `json:"version"`
+
+	Labels []Label `json:"labels"`
+}
+
+type SearchLabeledEntitiesResponse struct {
+	// Active entities carrying all the requested labels.
+	Entities []LabeledEntity `json:"entities"`
+}
+
+type APICapability struct {
+	// Name of the API, including the standard it implements if any.
+	Name string `json:"name"`
+
+	// Path prefix of the endpoints of the API.
+	BasePath string `json:"base_path"`
+}
+
+type CapabilitiesResponse struct {
+	// APIs served by this DSS instance.
+	Apis []APICapability `json:"apis"`
+
+	// Types of entities managed by this DSS instance.
+	EntityTypes []string `json:"entity_types"`
+
+	// Largest area, in square kilometers, of a search or of an entity.
+	MaxAreaKm2 float64 `json:"max_area_km2"`
+
+	// Longest duration of a remote ID subscription.
+	MaxRidSubscriptionDurationSeconds float32 `json:"max_rid_subscription_duration_seconds"`
+
+	// Longest duration of a strategic coordination subscription, when strategic coordination is enabled.
+	MaxScdSubscriptionDurationSeconds *float32 `json:"max_scd_subscription_duration_seconds,omitempty"`
+
+	// Largest number of entities returned by a search.
+	MaxResults float32 `json:"max_results"`
+
+	// Optional features enabled on this DSS instance.
+	Features []string `json:"features"`
+}
+
 type ErrorResponse struct {
 	// Human-readable message indicating what error occurred and/or why.
 	Message *string `json:"message,omitempty"`
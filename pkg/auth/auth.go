@@ -10,6 +10,9 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -120,23 +123,49 @@ func (r *JWKSResolver) ResolveKeys(ctx context.Context) ([]interface{}, error) {
 
 // Authorizer authorizes incoming requests.
 type Authorizer struct {
-	logger            *zap.Logger
-	keys              []interface{}
-	keyGuard          sync.RWMutex
-	acceptedAudiences map[string]bool
+	logger             *zap.Logger
+	keys               []interface{}
+	keyGuard           sync.RWMutex
+	acceptedAudiences  map[string]bool
+	replayGuard        ReplayGuard
+	allowImpersonation bool
+	tokens             *tokenCache
+	clockSkewLeeway    time.Duration
+	ownerAliases       *OwnerAliases
+	publicPaths        []string
 }
 
 // Configuration bundles up creation-time parameters for an Authorizer instance.
 type Configuration struct {
-	KeyResolver       KeyResolver   // Used to initialize and periodically refresh keys.
-	KeyRefreshTimeout time.Duration // Keys are refreshed on this cadence.
-	AcceptedAudiences []string      // AcceptedAudiences enforces the aud keyClaim on the jwt. An empty string allows no aud keyClaim.
+	KeyResolver        KeyResolver   // Used to initialize and periodically refresh keys.
+	KeyRefreshTimeout  time.Duration // Keys are refreshed on this cadence.
+	AcceptedAudiences  []string      // AcceptedAudiences enforces the aud keyClaim on the jwt. An empty string allows no aud keyClaim.
+	ReplayGuard        ReplayGuard   // If set, tokens of mutating requests must have a jti keyClaim and may be used only once.
+	AllowImpersonation bool          // If set, tokens with ImpersonateOwnerScope may act on behalf of the owner in the ImpersonateOwnerHeader header.
+	TokenCacheSize     int           // Number of verified tokens whose claims are cached until they expire; tokens are verified on every request if 0.
+	ClockSkewLeeway    time.Duration // Tolerance for the clocks of token issuers when validating the exp, nbf and iat claims.
+	OwnerAliases       *OwnerAliases // If set, tokens act as the canonical owner of their subject.
+	PublicPaths        []string      // path.Match patterns of the paths whose operations requiring no scopes are served without verifying access tokens.
 }
 
+// DefaultPublicPaths are the paths of the operations, requiring no scopes,
+// which are served to clients without access tokens, e.g. health checkers.
+var DefaultPublicPaths = []string{"/aux/v1/version", "/aux/v1/capabilities"}
+
 // NewRSAAuthorizer returns an Authorizer instance using values from configuration.
 func NewRSAAuthorizer(ctx context.Context, configuration Configuration) (*Authorizer, error) {
 	logger := logging.WithValuesFromContext(ctx, logging.Logger)
 
+	if configuration.ClockSkewLeeway < 0 {
+		return nil, stacktrace.NewError("Clock skew leeway must not be negative")
+	}
+
+	for _, pattern := range configuration.PublicPaths {
+		if _, err := path.Match(pattern, "/"); err != nil {
+			return nil, stacktrace.Propagate(err, "Invalid public path pattern %s", pattern)
+		}
+	}
+
 	keys, err := configuration.KeyResolver.ResolveKeys(ctx)
 	if err != nil {
 		return nil, stacktrace.Propagate(err, "Unable to resolve keys")
@@ -148,9 +177,15 @@ func NewRSAAuthorizer(ctx context.Context, configuration Configuration) (*Author
 	}
 
 	authorizer := &Authorizer{
-		acceptedAudiences: auds,
-		logger:            logger,
-		keys:              keys,
+		acceptedAudiences:  auds,
+		logger:             logger,
+		keys:               keys,
+		replayGuard:        configuration.ReplayGuard,
+		allowImpersonation: configuration.AllowImpersonation,
+		tokens:             newTokenCache(configuration.TokenCacheSize),
+		clockSkewLeeway:    configuration.ClockSkewLeeway,
+		ownerAliases:       configuration.OwnerAliases,
+		publicPaths:        configuration.PublicPaths,
 	}
 
 	go func() {
@@ -178,38 +213,48 @@ func NewRSAAuthorizer(ctx context.Context, configuration Configuration) (*Author
 
 func (a *Authorizer) setKeys(keys []interface{}) {
 	a.keyGuard.Lock()
+	changed := !reflect.DeepEqual(a.keys, keys)
 	a.keys = keys
 	a.keyGuard.Unlock()
+	// Tokens verified with keys no longer trusted must be verified again.
+	if changed {
+		a.tokens.Clear()
+	}
 }
 
 // Authorize extracts and verifies bearer tokens from a http.Request.
 func (a *Authorizer) Authorize(_ http.ResponseWriter, r *http.Request, authOptions []api.AuthorizationOption) api.AuthorizationResult {
+	if len(authOptions) == 0 && a.isPublic(r) {
+		return api.AuthorizationResult{}
+	}
 
 	tknStr, ok := getToken(r)
 	if !ok {
 		return api.AuthorizationResult{Error: stacktrace.NewErrorWithCode(dsserr.Unauthenticated, "Missing access token")}
 	}
 
-	a.keyGuard.RLock()
-	keys := a.keys
-	a.keyGuard.RUnlock()
-	validated := false
-	var err error
-	var keyClaims claims
-
-	for _, key := range keys {
-		keyClaims = claims{}
-		key := key
-		_, err = jwt.ParseWithClaims(tknStr, &keyClaims, func(token *jwt.Token) (interface{}, error) {
-			return key, nil
-		})
-		if err == nil {
-			validated = true
-			break
-		}
-	}
+	keyClaims, validated := a.tokens.Get(tknStr)
 	if !validated {
-		return api.AuthorizationResult{Error: stacktrace.PropagateWithCode(err, dsserr.Unauthenticated, "Access token validation failed")}
+		a.keyGuard.RLock()
+		keys := a.keys
+		a.keyGuard.RUnlock()
+		var err error
+
+		for _, key := range keys {
+			keyClaims = claims{leeway: a.clockSkewLeeway}
+			key := key
+			_, err = jwt.ParseWithClaims(tknStr, &keyClaims, func(token *jwt.Token) (interface{}, error) {
+				return key, nil
+			})
+			if err == nil {
+				validated = true
+				break
+			}
+		}
+		if !validated {
+			return api.AuthorizationResult{Error: stacktrace.PropagateWithCode(err, dsserr.Unauthenticated, "Access token validation failed")}
+		}
+		a.tokens.Add(tknStr, keyClaims)
 	}
 
 	if !a.acceptedAudiences[keyClaims.Audience] {
@@ -222,12 +267,48 @@ func (a *Authorizer) Authorize(_ http.ResponseWriter, r *http.Request, authOptio
 			missing, describeAuthorizationExpectations(authOptions), strings.Join(keyClaims.Scopes.ToStringSlice(), ", "))}
 	}
 
+	if a.replayGuard != nil && isMutating(r) {
+		if keyClaims.Id == "" {
+			return api.AuthorizationResult{Error: stacktrace.NewErrorWithCode(dsserr.Unauthenticated, "Access token for a write operation is missing jti claim")}
+		}
+		if err := a.replayGuard.Use(keyClaims.Issuer, keyClaims.Id, time.Unix(keyClaims.ExpiresAt, 0)); err != nil {
+			return api.AuthorizationResult{Error: stacktrace.Propagate(err, "Access token replay rejected")}
+		}
+	}
+
+	clientID := a.ownerAliases.CanonicalOwner(keyClaims.Subject)
+	if clientID != keyClaims.Subject {
+		logging.WithFields(r.Context(), zap.String("subject", keyClaims.Subject))
+	}
+	owner, err := a.impersonatedOwner(r, &keyClaims)
+	if err != nil {
+		return api.AuthorizationResult{Error: err}
+	}
+	if owner != "" {
+		clientID = owner
+	}
+	logging.WithFields(r.Context(), zap.String("owner", clientID))
+
 	return api.AuthorizationResult{
-		ClientID: &keyClaims.Subject,
+		ClientID: &clientID,
 		Scopes:   keyClaims.Scopes.ToStringSlice(),
 	}
 }
 
+// isPublic returns whether the path of r matches one of the public paths of a,
+// whose operations requiring no scopes are served without access tokens.
+func (a *Authorizer) isPublic(r *http.Request) bool {
+	if r.URL == nil {
+		return false
+	}
+	for _, pattern := range a.publicPaths {
+		if matched, _ := path.Match(pattern, r.URL.Path); matched {
+			return true
+		}
+	}
+	return false
+}
+
 func HasScope(scopes []string, requiredScope api.RequiredScope) bool {
 	for _, scope := range scopes {
 		if scope == string(requiredScope) {
@@ -238,6 +319,7 @@ func HasScope(scopes []string, requiredScope api.RequiredScope) bool {
 }
 
 // describeAuthorizationExpectations builds a human-readable string describing the expectations of the authorization options.
+// Schemes are described in the order of their names so that the description of the same options is always the same.
 func describeAuthorizationExpectations(authOptions []api.AuthorizationOption) string {
 	if len(authOptions) == 0 {
 		return "no expectation"
@@ -245,8 +327,15 @@ func describeAuthorizationExpectations(authOptions []api.AuthorizationOption) st
 
 	var expectations []string
 	for _, authOption := range authOptions {
+		schemes := make([]string, 0, len(authOption))
+		for scheme := range authOption {
+			schemes = append(schemes, string(scheme))
+		}
+		sort.Strings(schemes)
+
 		var authOptionExpectations []string
-		for scheme, scopes := range authOption {
+		for _, scheme := range schemes {
+			scopes := authOption[api.SecurityScheme(scheme)]
 
 			scopesStr := make([]string, len(scopes))
 			for scopeIdx, scope := range scopes {
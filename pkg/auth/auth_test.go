@@ -17,6 +17,8 @@ import (
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func rsaTokenReq(key *rsa.PrivateKey, exp, nbf int64) *http.Request {
@@ -192,6 +194,23 @@ func TestMissingScopes(t *testing.T) {
 	}
 }
 
+func TestDescribeAuthorizationExpectations(t *testing.T) {
+	require.Equal(t, "no expectation", describeAuthorizationExpectations(nil))
+
+	authOptions := []api.AuthorizationOption{
+		{"Authority": {"utm.strategic_coordination"}},
+		{"Authority": {"utm.strategic_coordination", "utm.constraint_processing"}},
+		{"Bearer": {"dss.read.identification_service_areas"}, "Authority": {"utm.constraint_management"}},
+	}
+	for i := 0; i < 10; i++ {
+		require.Equal(t,
+			"[Authority: (utm.strategic_coordination)] OR "+
+				"[Authority: (utm.strategic_coordination AND utm.constraint_processing)] OR "+
+				"[Authority: (utm.constraint_management) AND Bearer: (dss.read.identification_service_areas)]",
+			describeAuthorizationExpectations(authOptions))
+	}
+}
+
 func TestClaimsValidation(t *testing.T) {
 	Now = func() time.Time {
 		return time.Unix(42, 0)
@@ -223,6 +242,52 @@ func TestClaimsValidation(t *testing.T) {
 	require.Error(t, claims.Valid())
 }
 
+func TestClaimsClockSkewLeeway(t *testing.T) {
+	Now = func() time.Time {
+		return time.Unix(1000, 0)
+	}
+	jwt.TimeFunc = Now
+	defer func() {
+		jwt.TimeFunc = time.Now
+		Now = time.Now
+	}()
+
+	newClaims := func(leeway time.Duration) *claims {
+		return &claims{
+			StandardClaims: jwt.StandardClaims{Subject: "real_owner", Issuer: "real_issuer", ExpiresAt: 1100},
+			leeway:         leeway,
+		}
+	}
+
+	// Issued or valid a few seconds ahead of the clock of the DSS.
+	c := newClaims(0)
+	c.IssuedAt = 1005
+	require.Error(t, c.Valid())
+	c.leeway = 10 * time.Second
+	require.NoError(t, c.Valid())
+
+	c = newClaims(0)
+	c.NotBefore = 1005
+	require.Error(t, c.Valid())
+	c.leeway = 10 * time.Second
+	require.NoError(t, c.Valid())
+
+	// Expired a few seconds ago.
+	c = newClaims(0)
+	c.ExpiresAt = 995
+	require.Error(t, c.Valid())
+	c.leeway = 10 * time.Second
+	require.NoError(t, c.Valid())
+
+	// Skew beyond the leeway is still rejected.
+	c = newClaims(10 * time.Second)
+	c.IssuedAt = 1020
+	require.Error(t, c.Valid())
+	c = newClaims(10 * time.Second)
+	c.ExpiresAt = 980
+	require.Error(t, c.Valid())
+}
+
 func TestHasScope(t *testing.T) {
 	scopes := []string{
 		string(scdv1.UtmStrategicCoordinationScope),
@@ -233,3 +298,169 @@ func TestHasScope(t *testing.T) {
 	require.True(t, HasScope(scopes, scdv1.UtmConformanceMonitoringSaScope))
 	require.False(t, HasScope(scopes, scdv1.UtmAvailabilityArbitrationScope))
 }
+
+func TestReplayProtection(t *testing.T) {
+	jwt.TimeFunc = func() time.Time {
+		return time.Unix(42, 0)
+	}
+	Now = jwt.TimeFunc
+	defer func() {
+		jwt.TimeFunc = time.Now
+		Now = time.Now
+	}()
+
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+	a, err := NewRSAAuthorizer(context.Background(), Configuration{
+		KeyResolver: &fromMemoryKeyResolver{
+			Keys: []interface{}{&key.PublicKey},
+		},
+		KeyRefreshTimeout: 1 * time.Millisecond,
+		AcceptedAudiences: []string{""},
+		ReplayGuard:       NewMemoryReplayGuard(),
+	})
+	require.NoError(t, err)
+
+	tokenReq := func(method, jti string) *http.Request {
+		claims := jwt.MapClaims{"exp": 100, "sub": "real_owner", "iss": "baz"}
+		if jti != "" {
+			claims["jti"] = jti
+		}
+		tokenString, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+		require.NoError(t, err)
+		req := &http.Request{Method: method, Header: make(http.Header)}
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+		return req
+	}
+
+	// Reads may reuse tokens.
+	require.NoError(t, a.Authorize(nil, tokenReq(http.MethodGet, "read"), nil).Error)
+	require.NoError(t, a.Authorize(nil, tokenReq(http.MethodGet, "read"), nil).Error)
+
+	// Writes may not.
+	require.NoError(t, a.Authorize(nil, tokenReq(http.MethodPut, "write"), nil).Error)
+	res := a.Authorize(nil, tokenReq(http.MethodPut, "write"), nil)
+	require.Equal(t, dsserr.Unauthenticated, stacktrace.GetCode(res.Error))
+
+	// Writes must identify their tokens.
+	res = a.Authorize(nil, tokenReq(http.MethodPost, ""), nil)
+	require.Equal(t, dsserr.Unauthenticated, stacktrace.GetCode(res.Error))
+}
+
+func TestOwnerImpersonation(t *testing.T) {
+	jwt.TimeFunc = func() time.Time {
+		return time.Unix(42, 0)
+	}
+	defer func() {
+		jwt.TimeFunc = time.Now
+	}()
+
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+	newAuthorizer := func(allow bool) (*Authorizer, *observer.ObservedLogs) {
+		a, err := NewRSAAuthorizer(context.Background(), Configuration{
+			KeyResolver: &fromMemoryKeyResolver{
+				Keys: []interface{}{&key.PublicKey},
+			},
+			KeyRefreshTimeout:  1 * time.Millisecond,
+			AcceptedAudiences:  []string{""},
+			AllowImpersonation: allow,
+		})
+		require.NoError(t, err)
+		core, logs := observer.New(zap.InfoLevel)
+		a.logger = zap.New(core)
+		return a, logs
+	}
+	tokenReq := func(scope, owner string) *http.Request {
+		tokenString, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"exp": 100, "sub": "support", "iss": "baz", "scope": scope,
+		}).SignedString(key)
+		require.NoError(t, err)
+		req := &http.Request{Method: http.MethodDelete, Header: make(http.Header)}
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+		if owner != "" {
+			req.Header.Set(ImpersonateOwnerHeader, owner)
+		}
+		return req
+	}
+
+	a, logs := newAuthorizer(true)
+
+	// Requests without the header act as the token subject.
+	res := a.Authorize(nil, tokenReq(ImpersonateOwnerScope, ""), nil)
+	require.NoError(t, res.Error)
+	require.Equal(t, "support", *res.ClientID)
+	require.Zero(t, logs.Len())
+
+	res = a.Authorize(nil, tokenReq(ImpersonateOwnerScope, "uss1"), nil)
+	require.NoError(t, res.Error)
+	require.Equal(t, "uss1", *res.ClientID)
+	require.Equal(t, 1, logs.FilterField(zap.String("actor", "support")).FilterField(zap.String("impersonated_owner", "uss1")).Len())
+
+	// The impersonation scope is required.
+	res = a.Authorize(nil, tokenReq("dss.admin", "uss1"), nil)
+	require.Equal(t, dsserr.PermissionDenied, stacktrace.GetCode(res.Error))
+	require.Equal(t, 1, logs.FilterLevelExact(zap.WarnLevel).Len())
+
+	// Impersonation must be enabled.
+	a, _ = newAuthorizer(false)
+	res = a.Authorize(nil, tokenReq(ImpersonateOwnerScope, "uss1"), nil)
+	require.Equal(t, dsserr.PermissionDenied, stacktrace.GetCode(res.Error))
+}
+
+func TestMemoryReplayGuardForgetsExpiredTokens(t *testing.T) {
+	now := time.Unix(1000, 0)
+	Now = func() time.Time { return now }
+	defer func() { Now = time.Now }()
+
+	g := NewMemoryReplayGuard()
+	require.NoError(t, g.Use("iss", "jti", now.Add(time.Minute)))
+	require.Error(t, g.Use("iss", "jti", now.Add(time.Minute)))
+	require.NoError(t, g.Use("other-iss", "jti", now.Add(time.Minute)))
+
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, g.Use("iss", "jti", now.Add(time.Minute)))
+	require.Len(t, g.used, 1)
+}
+
+func TestPublicPaths(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+	a, err := NewRSAAuthorizer(context.Background(), Configuration{
+		KeyResolver: &fromMemoryKeyResolver{
+			Keys: []interface{}{&key.PublicKey},
+		},
+		KeyRefreshTimeout: 1 * time.Millisecond,
+		AcceptedAudiences: []string{""},
+		PublicPaths:       append([]string{"/aux/v1/debug/*"}, DefaultPublicPaths...),
+	})
+	require.NoError(t, err)
+
+	noTokenReq := func(path string) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, "https://dss.example.com"+path, nil)
+		require.NoError(t, err)
+		return req
+	}
+	scoped := []api.AuthorizationOption{{"Auth": {"dss.read.identification_service_areas"}}}
+
+	// Operations requiring no scopes under public paths skip token verification.
+	for _, p := range []string{"/aux/v1/version", "/aux/v1/capabilities", "/aux/v1/debug/cells"} {
+		res := a.Authorize(nil, noTokenReq(p), nil)
+		require.NoError(t, res.Error, p)
+		require.Nil(t, res.ClientID, p)
+	}
+
+	// Other paths, and operations requiring scopes, still require tokens.
+	res := a.Authorize(nil, noTokenReq("/aux/v1/validate_oauth"), nil)
+	require.Equal(t, dsserr.Unauthenticated, stacktrace.GetCode(res.Error))
+	res = a.Authorize(nil, noTokenReq("/aux/v1/debug/a/b"), nil)
+	require.Equal(t, dsserr.Unauthenticated, stacktrace.GetCode(res.Error))
+	res = a.Authorize(nil, noTokenReq("/aux/v1/capabilities"), scoped)
+	require.Equal(t, dsserr.Unauthenticated, stacktrace.GetCode(res.Error))
+
+	_, err = NewRSAAuthorizer(context.Background(), Configuration{
+		KeyResolver: &fromMemoryKeyResolver{},
+		PublicPaths: []string{"/aux/v1/["},
+	})
+	require.Error(t, err)
+}
@@ -8,16 +8,33 @@ import (
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/interuss/stacktrace"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 var (
 	errMissingOrEmptySubject = errors.New("missing or empty subject")
 	errTokenExpireTooFar     = errors.New("token expiration time is too far in the furture, Max token duration is 1 Hour")
 	errMissingIssuer         = errors.New("missing Issuer URI")
+	errTokenExpired          = errors.New("token is expired")
+	errTokenUsedBeforeIssued = errors.New("token used before issued")
+	errTokenNotValidYet      = errors.New("token is not valid yet")
 	// Now allows test to override with specific time values
 	Now = time.Now
 )
 
+var (
+	tokenClockSkew = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "dss_auth_token_clock_skew_seconds",
+		Help:    "How far in the future the iat or nbf claims of access tokens were, for the tokens issued ahead of the clock of this DSS.",
+		Buckets: []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60, 300},
+	})
+	tokenSkewChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dss_auth_token_skew_checks_total",
+		Help: "Number of access tokens whose exp, nbf or iat claim failed validation without leeway, by claim and result (tolerated within the leeway or rejected).",
+	}, []string{"claim", "result"})
+)
+
 // ScopeSet models a set of scopes.
 type ScopeSet map[string]struct{}
 
@@ -64,6 +81,10 @@ func (s *ScopeSet) ToStringSlice() []string {
 type claims struct {
 	jwt.StandardClaims
 	Scopes ScopeSet `json:"scope"`
+
+	// leeway tolerates the clocks of token issuers being off by up to leeway
+	// when validating the exp, nbf and iat claims.
+	leeway time.Duration
 }
 
 func (c *claims) Valid() error {
@@ -72,9 +93,7 @@ func (c *claims) Valid() error {
 	}
 	now := Now()
 
-	c.VerifyExpiresAt(now.Unix(), true)
-
-	if c.ExpiresAt > now.Add(time.Hour).Unix() {
+	if c.ExpiresAt > now.Add(time.Hour+c.leeway).Unix() {
 		return errTokenExpireTooFar
 	}
 
@@ -82,5 +101,38 @@ func (c *claims) Valid() error {
 		return errMissingIssuer
 	}
 
-	return c.StandardClaims.Valid()
+	return c.validTimes()
+}
+
+// validTimes validates the exp, nbf and iat claims as
+// jwt.StandardClaims.Valid does, tolerating c.leeway.
+func (c *claims) validTimes() error {
+	now := jwt.TimeFunc()
+	if ahead := time.Unix(max(c.IssuedAt, c.NotBefore), 0).Sub(now); ahead > 0 {
+		tokenClockSkew.Observe(ahead.Seconds())
+	}
+
+	leeway := int64(c.leeway / time.Second)
+	checks := []struct {
+		claim   string
+		valid   func(int64, bool) bool
+		shift   int64
+		invalid error
+	}{
+		{"exp", c.VerifyExpiresAt, -leeway, errTokenExpired},
+		{"iat", c.VerifyIssuedAt, leeway, errTokenUsedBeforeIssued},
+		{"nbf", c.VerifyNotBefore, leeway, errTokenNotValidYet},
+	}
+	for _, check := range checks {
+		if check.valid(now.Unix(), false) {
+			continue
+		}
+		if check.valid(now.Unix()+check.shift, false) {
+			tokenSkewChecks.WithLabelValues(check.claim, "tolerated").Inc()
+			continue
+		}
+		tokenSkewChecks.WithLabelValues(check.claim, "rejected").Inc()
+		return check.invalid
+	}
+	return nil
 }
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/interuss/dss/pkg/api"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/logging"
+	"github.com/interuss/stacktrace"
+	"go.uber.org/zap"
+)
+
+// ClientCertificateAuthorizer authorizes the requests made over TLS
+// connections whose client presented a certificate verified by the server,
+// i.e. a listener requiring mutual TLS, without access tokens. Clients are
+// identified by the common name of their certificate and granted Scopes.
+type ClientCertificateAuthorizer struct {
+	// Scopes are granted to every verified client.
+	Scopes []string
+	// OwnerAliases, if set, map the common names to canonical owners.
+	OwnerAliases *OwnerAliases
+}
+
+// Authorize authorizes r by its verified client certificate.
+func (a *ClientCertificateAuthorizer) Authorize(_ http.ResponseWriter, r *http.Request, authOptions []api.AuthorizationOption) api.AuthorizationResult {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return api.AuthorizationResult{Error: stacktrace.NewErrorWithCode(dsserr.Unauthenticated, "Missing verified client certificate")}
+	}
+	subject := r.TLS.VerifiedChains[0][0].Subject.CommonName
+	if subject == "" {
+		return api.AuthorizationResult{Error: stacktrace.NewErrorWithCode(dsserr.Unauthenticated, "Client certificate has no common name")}
+	}
+
+	scopes := ScopeSet{}
+	for _, scope := range a.Scopes {
+		scopes[scope] = struct{}{}
+	}
+	if pass, missing := validateScopes(authOptions, scopes); !pass {
+		return api.AuthorizationResult{Error: stacktrace.NewErrorWithCode(dsserr.PermissionDenied,
+			"Client certificate %s missing scopes (%v) while expecting %v and granted %v",
+			subject, missing, describeAuthorizationExpectations(authOptions), strings.Join(a.Scopes, ", "))}
+	}
+
+	clientID := a.OwnerAliases.CanonicalOwner(subject)
+	logging.WithFields(r.Context(), zap.String("owner", clientID), zap.String("client_certificate", r.TLS.VerifiedChains[0][0].Subject.String()))
+	return api.AuthorizationResult{
+		ClientID: &clientID,
+		Scopes:   a.Scopes,
+	}
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"testing"
+
+	"github.com/interuss/dss/pkg/api"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+func clientCertificateReq(commonName string) *http.Request {
+	req := (&http.Request{Header: make(http.Header)}).WithContext(context.Background())
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{
+		{Subject: pkix.Name{CommonName: commonName}},
+	}}}
+	return req
+}
+
+func TestClientCertificateAuthorizer(t *testing.T) {
+	aliases := &OwnerAliases{}
+	aliases.Set(map[string]string{"ops-console": "uss1"})
+	authorizer := &ClientCertificateAuthorizer{Scopes: []string{"dss.admin"}, OwnerAliases: aliases}
+	adminOptions := []api.AuthorizationOption{{"Auth": {"dss.admin"}}}
+
+	res := authorizer.Authorize(nil, clientCertificateReq("ops-console"), adminOptions)
+	require.NoError(t, res.Error)
+	require.Equal(t, "uss1", *res.ClientID)
+	require.Equal(t, []string{"dss.admin"}, res.Scopes)
+
+	res = authorizer.Authorize(nil, clientCertificateReq("ops-console"), []api.AuthorizationOption{{"Auth": {"dss.transfer_ownership"}}})
+	require.Equal(t, dsserr.PermissionDenied, stacktrace.GetCode(res.Error))
+
+	res = authorizer.Authorize(nil, clientCertificateReq(""), adminOptions)
+	require.Equal(t, dsserr.Unauthenticated, stacktrace.GetCode(res.Error))
+
+	// Requests without a verified certificate, e.g. over plain HTTP, are not
+	// authenticated.
+	res = authorizer.Authorize(nil, &http.Request{Header: make(http.Header)}, adminOptions)
+	require.Equal(t, dsserr.Unauthenticated, stacktrace.GetCode(res.Error))
+	res = authorizer.Authorize(nil, &http.Request{Header: make(http.Header), TLS: &tls.ConnectionState{}}, adminOptions)
+	require.Equal(t, dsserr.Unauthenticated, stacktrace.GetCode(res.Error))
+}
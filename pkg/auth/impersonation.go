@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"net/http"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+	"go.uber.org/zap"
+)
+
+const (
+	// ImpersonateOwnerHeader is the request header designating the owner on
+	// behalf of whom a request is made.
+	ImpersonateOwnerHeader = "X-Impersonate-Owner"
+
+	// ImpersonateOwnerScope is the scope an access token must carry for its
+	// request to act on behalf of another owner.
+	ImpersonateOwnerScope = "dss.admin.impersonate_owner"
+)
+
+// impersonatedOwner returns the owner on behalf of whom r is made, or an empty
+// string if r does not request impersonation. Every impersonation attempt is
+// logged for audit, whether it is permitted or not.
+func (a *Authorizer) impersonatedOwner(r *http.Request, keyClaims *claims) (string, error) {
+	owner := r.Header.Get(ImpersonateOwnerHeader)
+	if owner == "" {
+		return "", nil
+	}
+
+	logger := a.logger.With(
+		zap.String("actor", keyClaims.Subject),
+		zap.String("issuer", keyClaims.Issuer),
+		zap.String("impersonated_owner", owner),
+		zap.String("method", r.Method),
+		zap.String("path", requestPath(r)),
+	)
+
+	var err error
+	if !a.allowImpersonation {
+		err = stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Owner impersonation is not enabled on this DSS instance")
+	} else if _, ok := keyClaims.Scopes[ImpersonateOwnerScope]; !ok {
+		err = stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Access token missing scope %s required to impersonate an owner", ImpersonateOwnerScope)
+	}
+	if err != nil {
+		logger.Warn("audit: owner impersonation denied", zap.Error(err))
+		return "", err
+	}
+
+	logger.Info("audit: owner impersonation")
+	return owner, nil
+}
+
+func requestPath(r *http.Request) string {
+	if r.URL == nil {
+		return ""
+	}
+	return r.URL.Path
+}
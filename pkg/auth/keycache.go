@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/interuss/stacktrace"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var (
+	keyCacheStaleness = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "dss_auth_key_cache_staleness_seconds",
+		Help: "Time since the access token verification keys in use were resolved, 0 while they resolve successfully.",
+	})
+	keyCacheFallbacks = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "dss_auth_key_cache_fallbacks_total",
+		Help: "Number of failed access token verification key resolutions answered with cached keys.",
+	})
+)
+
+// cachedKeys is the content of the file of a CachingKeyResolver.
+type cachedKeys struct {
+	ResolvedAt time.Time          `json:"resolved_at"`
+	Keys       jose.JSONWebKeySet `json:"keys"`
+}
+
+// CachingKeyResolver resolves keys with Resolver and saves them to File so
+// that, when Resolver fails, e.g. while a JWKS endpoint is unreachable, the
+// keys last resolved keep being used, including across restarts, until they
+// were resolved more than MaxStaleness ago.
+type CachingKeyResolver struct {
+	Resolver KeyResolver
+	File     string
+	// MaxStaleness bounds the age of the cached keys used; unbounded if 0.
+	MaxStaleness time.Duration
+	Logger       *zap.Logger
+
+	mu     sync.Mutex
+	cached *cachedKeys
+	now    func() time.Time
+}
+
+// ResolveKeys implements KeyResolver.
+func (r *CachingKeyResolver) ResolveKeys(ctx context.Context) ([]interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if r.now != nil {
+		now = r.now()
+	}
+
+	keys, err := r.Resolver.ResolveKeys(ctx)
+	if err == nil {
+		keyCacheStaleness.Set(0)
+		r.cached = &cachedKeys{ResolvedAt: now}
+		for _, k := range keys {
+			r.cached.Keys.Keys = append(r.cached.Keys.Keys, jose.JSONWebKey{Key: k})
+		}
+		if err := r.save(); err != nil {
+			r.Logger.Warn("Failed to cache access token verification keys", zap.String("file", r.File), zap.Error(err))
+		}
+		return keys, nil
+	}
+
+	if r.cached == nil {
+		cached, loadErr := r.load()
+		if loadErr != nil {
+			return nil, stacktrace.Propagate(err, "Error resolving keys, and no cached keys available: %v", loadErr)
+		}
+		r.cached = cached
+	}
+	staleness := now.Sub(r.cached.ResolvedAt)
+	if r.MaxStaleness > 0 && staleness > r.MaxStaleness {
+		return nil, stacktrace.Propagate(err, "Error resolving keys, and cached keys resolved %s ago exceed the maximum staleness of %s", staleness, r.MaxStaleness)
+	}
+	keyCacheStaleness.Set(staleness.Seconds())
+	keyCacheFallbacks.Inc()
+	r.Logger.Warn("Failed to resolve access token verification keys; using cached keys",
+		zap.Duration("staleness", staleness), zap.Time("resolved_at", r.cached.ResolvedAt), zap.Error(err))
+
+	keys = make([]interface{}, 0, len(r.cached.Keys.Keys))
+	for _, k := range r.cached.Keys.Keys {
+		keys = append(keys, k.Key)
+	}
+	return keys, nil
+}
+
+// save writes the cached keys to File atomically, so that a crash while
+// writing does not lose the keys previously cached.
+func (r *CachingKeyResolver) save() error {
+	content, err := json.Marshal(r.cached)
+	if err != nil {
+		return stacktrace.Propagate(err, "Error encoding keys")
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.File), filepath.Base(r.File)+".*")
+	if err != nil {
+		return stacktrace.Propagate(err, "Error creating temporary key cache file")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return stacktrace.Propagate(err, "Error writing temporary key cache file")
+	}
+	if err := tmp.Close(); err != nil {
+		return stacktrace.Propagate(err, "Error closing temporary key cache file")
+	}
+	if err := os.Rename(tmp.Name(), r.File); err != nil {
+		return stacktrace.Propagate(err, "Error replacing key cache file")
+	}
+	return nil
+}
+
+func (r *CachingKeyResolver) load() (*cachedKeys, error) {
+	content, err := os.ReadFile(r.File)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error reading key cache file")
+	}
+	cached := &cachedKeys{}
+	if err := json.Unmarshal(content, cached); err != nil {
+		return nil, stacktrace.Propagate(err, "Error decoding key cache file %s", r.File)
+	}
+	if len(cached.Keys.Keys) == 0 {
+		return nil, stacktrace.NewError("No key in key cache file %s", r.File)
+	}
+	return cached, nil
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// flakyKeyResolver resolves keys unless err is set.
+type flakyKeyResolver struct {
+	keys []interface{}
+	err  error
+}
+
+func (r *flakyKeyResolver) ResolveKeys(context.Context) ([]interface{}, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.keys, nil
+}
+
+func TestCachingKeyResolver(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		file     = filepath.Join(t.TempDir(), "keys.json")
+		now      = time.Now()
+		clock    = func() time.Time { return now }
+		upstream = &flakyKeyResolver{}
+	)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	upstream.keys = []interface{}{&key.PublicKey}
+
+	newResolver := func() *CachingKeyResolver {
+		return &CachingKeyResolver{Resolver: upstream, File: file, MaxStaleness: time.Hour, Logger: zap.NewNop(), now: clock}
+	}
+
+	// Without cached keys, failures are reported.
+	upstream.err = stacktrace.NewError("JWKS unreachable")
+	_, err = newResolver().ResolveKeys(ctx)
+	require.Error(t, err)
+
+	// Resolved keys are cached.
+	upstream.err = nil
+	r := newResolver()
+	keys, err := r.ResolveKeys(ctx)
+	require.NoError(t, err)
+	require.Equal(t, upstream.keys, keys)
+
+	// Failures are answered with the cached keys until they are too stale,
+	// including by resolvers created after a restart.
+	upstream.err = stacktrace.NewError("JWKS unreachable")
+	now = now.Add(30 * time.Minute)
+	for _, resolver := range []*CachingKeyResolver{r, newResolver()} {
+		keys, err = resolver.ResolveKeys(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		require.True(t, key.PublicKey.Equal(keys[0]))
+	}
+
+	now = now.Add(time.Hour)
+	for _, resolver := range []*CachingKeyResolver{r, newResolver()} {
+		_, err = resolver.ResolveKeys(ctx)
+		require.Error(t, err)
+	}
+
+	// Resolving keys again refreshes the cache.
+	upstream.err = nil
+	_, err = r.ResolveKeys(ctx)
+	require.NoError(t, err)
+	upstream.err = stacktrace.NewError("JWKS unreachable")
+	keys, err = newResolver().ResolveKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/interuss/stacktrace"
+	"go.uber.org/zap"
+)
+
+// OwnerAliases maps the subjects of access tokens to the canonical owners they
+// act as, e.g. so that a USS whose tokens are issued by another authorization
+// server, with another subject, keeps access to its entities. The zero value
+// maps no subject, and a nil *OwnerAliases is valid.
+type OwnerAliases struct {
+	mu     sync.RWMutex
+	owners map[string]string
+}
+
+// Set replaces the aliases with owners, mapping subjects to canonical owners.
+func (a *OwnerAliases) Set(owners map[string]string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.owners = owners
+}
+
+// CanonicalOwner returns the owner subject acts as: its canonical owner if it
+// has an alias, subject itself otherwise.
+func (a *OwnerAliases) CanonicalOwner(subject string) string {
+	if a == nil {
+		return subject
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if owner, ok := a.owners[subject]; ok {
+		return owner
+	}
+	return subject
+}
+
+// Load replaces the aliases with those returned by load.
+func (a *OwnerAliases) Load(ctx context.Context, load func(context.Context) (map[string]string, error)) error {
+	owners, err := load(ctx)
+	if err != nil {
+		return stacktrace.Propagate(err, "Unable to load owner aliases")
+	}
+	a.Set(owners)
+	return nil
+}
+
+// Refresh loads the aliases with load every interval until ctx is done, e.g.
+// to pick up the aliases changed through other instances. Failures are logged
+// and the current aliases kept.
+func (a *OwnerAliases) Refresh(ctx context.Context, load func(context.Context) (map[string]string, error), interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := a.Load(ctx, load); err != nil {
+				logger.Warn("Failed to refresh owner aliases", zap.Error(err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnerAliases(t *testing.T) {
+	var aliases *OwnerAliases
+	require.Equal(t, "subject", aliases.CanonicalOwner("subject"))
+
+	aliases = &OwnerAliases{}
+	require.Equal(t, "subject", aliases.CanonicalOwner("subject"))
+	require.NoError(t, aliases.Load(context.Background(), func(context.Context) (map[string]string, error) {
+		return map[string]string{"subject": "uss1"}, nil
+	}))
+	require.Equal(t, "uss1", aliases.CanonicalOwner("subject"))
+	require.Equal(t, "uss2", aliases.CanonicalOwner("uss2"))
+
+	// Failed loads keep the current aliases.
+	require.Error(t, aliases.Load(context.Background(), func(context.Context) (map[string]string, error) {
+		return nil, errors.New("unavailable")
+	}))
+	require.Equal(t, "uss1", aliases.CanonicalOwner("subject"))
+}
+
+func TestAuthorizerAppliesOwnerAliases(t *testing.T) {
+	jwt.TimeFunc = func() time.Time {
+		return time.Unix(42, 0)
+	}
+	Now = jwt.TimeFunc
+	defer func() {
+		jwt.TimeFunc = time.Now
+		Now = time.Now
+	}()
+
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+	aliases := &OwnerAliases{}
+	aliases.Set(map[string]string{"new-idp-subject": "uss1"})
+	a, err := NewRSAAuthorizer(context.Background(), Configuration{
+		KeyResolver: &fromMemoryKeyResolver{
+			Keys: []interface{}{&key.PublicKey},
+		},
+		KeyRefreshTimeout: 1 * time.Millisecond,
+		AcceptedAudiences: []string{""},
+		OwnerAliases:      aliases,
+	})
+	require.NoError(t, err)
+
+	tokenReq := func(subject string) *http.Request {
+		tokenString, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"exp": 100, "sub": subject, "iss": "baz"}).SignedString(key)
+		require.NoError(t, err)
+		req := &http.Request{Method: http.MethodGet, Header: make(http.Header)}
+		req.Header.Set("Authorization", "Bearer "+tokenString)
+		return req
+	}
+
+	res := a.Authorize(nil, tokenReq("new-idp-subject"), nil)
+	require.NoError(t, res.Error)
+	require.Equal(t, "uss1", *res.ClientID)
+
+	res = a.Authorize(nil, tokenReq("uss2"), nil)
+	require.NoError(t, res.Error)
+	require.Equal(t, "uss2", *res.ClientID)
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+)
+
+// ReplayGuard records the tokens used for mutating requests and rejects their
+// reuse.
+type ReplayGuard interface {
+	// Use records the token identified by "issuer" and "jti" as used until
+	// "expiresAt", returning an error if it was already used.
+	Use(issuer, jti string, expiresAt time.Time) error
+}
+
+// MemoryReplayGuard is a ReplayGuard keeping used tokens in memory until they
+// expire. It only detects tokens replayed against the same DSS instance.
+type MemoryReplayGuard struct {
+	mu   sync.Mutex
+	used map[string]time.Time
+	// nextSweep is when expired tokens are next removed from used.
+	nextSweep time.Time
+}
+
+// sweepInterval is the minimum interval between removals of expired tokens.
+const sweepInterval = time.Minute
+
+// NewMemoryReplayGuard returns an empty MemoryReplayGuard.
+func NewMemoryReplayGuard() *MemoryReplayGuard {
+	return &MemoryReplayGuard{used: make(map[string]time.Time)}
+}
+
+// Use implements ReplayGuard.
+func (g *MemoryReplayGuard) Use(issuer, jti string, expiresAt time.Time) error {
+	now := Now()
+	key := issuer + " " + jti
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if now.After(g.nextSweep) {
+		for k, exp := range g.used {
+			if now.After(exp) {
+				delete(g.used, k)
+			}
+		}
+		g.nextSweep = now.Add(sweepInterval)
+	}
+
+	if exp, ok := g.used[key]; ok && !now.After(exp) {
+		return stacktrace.NewErrorWithCode(dsserr.Unauthenticated, "Access token %s from %s was already used", jti, issuer)
+	}
+	g.used[key] = expiresAt
+	return nil
+}
+
+// isMutating returns true if r may modify the state of the DSS.
+func isMutating(r *http.Request) bool {
+	switch r.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
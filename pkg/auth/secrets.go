@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/interuss/stacktrace"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// GCPSecretManagerScheme is the URI scheme of secrets stored in GCP
+	// Secret Manager: gcpsm://<project>/<secret>[/<version>].
+	GCPSecretManagerScheme = "gcpsm"
+
+	// VaultScheme is the URI scheme of secrets stored in HashiCorp Vault:
+	// vault://<host>[:<port>]/<path>#<field>, authenticated with the token in
+	// the VAULT_TOKEN environment variable.
+	VaultScheme = "vault"
+
+	defaultGCPSecretManagerEndpoint = "https://secretmanager.googleapis.com"
+	gcpCloudPlatformScope           = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// SecretSource fetches the content of a secret.
+type SecretSource interface {
+	FetchSecret(ctx context.Context) ([]byte, error)
+}
+
+// SecretSourceFromURI returns the SecretSource designated by uri.
+func SecretSourceFromURI(uri string) (SecretSource, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error parsing secret URI")
+	}
+	path := strings.Trim(u.Path, "/")
+	switch u.Scheme {
+	case GCPSecretManagerScheme:
+		parts := strings.Split(path, "/")
+		if u.Host == "" || path == "" || len(parts) > 2 {
+			return nil, stacktrace.NewError("Expected %s://<project>/<secret>[/<version>], got `%s`", GCPSecretManagerScheme, uri)
+		}
+		source := &GCPSecretManagerSource{Project: u.Host, Secret: parts[0], Version: "latest"}
+		if len(parts) == 2 {
+			source.Version = parts[1]
+		}
+		return source, nil
+	case VaultScheme:
+		if u.Host == "" || path == "" || u.Fragment == "" {
+			return nil, stacktrace.NewError("Expected %s://<host>[:<port>]/<path>#<field>, got `%s`", VaultScheme, uri)
+		}
+		return &VaultSource{
+			Address: &url.URL{Scheme: "https", Host: u.Host},
+			Path:    path,
+			Field:   u.Fragment,
+			Token:   os.Getenv("VAULT_TOKEN"),
+		}, nil
+	default:
+		return nil, stacktrace.NewError("Unsupported secret URI scheme `%s`; expected %s or %s", u.Scheme, GCPSecretManagerScheme, VaultScheme)
+	}
+}
+
+// GCPSecretManagerSource fetches a secret version from GCP Secret Manager
+// using Application Default Credentials.
+type GCPSecretManagerSource struct {
+	Project string
+	Secret  string
+	Version string
+	// Endpoint overrides the Secret Manager API endpoint if not empty.
+	Endpoint string
+	// Client overrides the authenticated HTTP client if not nil.
+	Client *http.Client
+}
+
+// FetchSecret implements SecretSource.
+func (s *GCPSecretManagerSource) FetchSecret(ctx context.Context) ([]byte, error) {
+	client := s.Client
+	if client == nil {
+		c, err := google.DefaultClient(ctx, gcpCloudPlatformScope)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Error obtaining GCP credentials")
+		}
+		client = c
+	}
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = defaultGCPSecretManagerEndpoint
+	}
+
+	body, err := getSecret(ctx, client, fmt.Sprintf("%s/v1/projects/%s/secrets/%s/versions/%s:access",
+		endpoint, url.PathEscape(s.Project), url.PathEscape(s.Secret), url.PathEscape(s.Version)), nil)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error accessing secret %s of project %s", s.Secret, s.Project)
+	}
+	var resp struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, stacktrace.Propagate(err, "Error decoding Secret Manager response")
+	}
+	data, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error decoding secret payload")
+	}
+	return data, nil
+}
+
+// VaultSource fetches a field of a secret from HashiCorp Vault. Both version 1
+// and version 2 of the key/value secrets engine are supported; with version 2,
+// Path includes the data/ segment (e.g. secret/data/dss).
+type VaultSource struct {
+	Address *url.URL
+	Path    string
+	Field   string
+	Token   string
+	// Client overrides http.DefaultClient if not nil.
+	Client *http.Client
+}
+
+// FetchSecret implements SecretSource.
+func (s *VaultSource) FetchSecret(ctx context.Context) ([]byte, error) {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	body, err := getSecret(ctx, client, s.Address.JoinPath("v1", s.Path).String(), http.Header{"X-Vault-Token": {s.Token}})
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error reading Vault secret %s", s.Path)
+	}
+	var resp struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, stacktrace.Propagate(err, "Error decoding Vault response")
+	}
+	fields := resp.Data
+	// Version 2 of the key/value engine nests the fields in data.data.
+	var nested map[string]json.RawMessage
+	if err := json.Unmarshal(resp.Data["data"], &nested); err == nil {
+		fields = nested
+	}
+	raw, ok := fields[s.Field]
+	if !ok {
+		return nil, stacktrace.NewError("Vault secret %s has no field %s", s.Path, s.Field)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, stacktrace.Propagate(err, "Field %s of Vault secret %s is not a string", s.Field, s.Path)
+	}
+	return []byte(value), nil
+}
+
+func getSecret(ctx context.Context, client *http.Client, u string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error creating request")
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error sending request")
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error reading response")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, stacktrace.NewError("Unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// SecretKeyResolver resolves the PEM-encoded RSA public keys stored in a
+// secret. The secret is fetched every time keys are resolved so that keys
+// rotated in the secret manager are picked up when the Authorizer refreshes
+// its keys.
+type SecretKeyResolver struct {
+	Source SecretSource
+}
+
+// ResolveKeys implements KeyResolver.
+func (r *SecretKeyResolver) ResolveKeys(ctx context.Context) ([]interface{}, error) {
+	data, err := r.Source.FetchSecret(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error fetching public keys secret")
+	}
+	var keys []interface{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		parsedKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Error parsing key as x509 public key")
+		}
+		key, ok := parsedKey.(*rsa.PublicKey)
+		if !ok {
+			return nil, stacktrace.NewError("Secret contains a public key that is not an RSA key")
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, stacktrace.NewError("Failed to decode any public key from secret")
+	}
+	return keys, nil
+}
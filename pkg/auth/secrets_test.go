@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretSourceFromURI(t *testing.T) {
+	source, err := SecretSourceFromURI("gcpsm://my-project/dss-keys")
+	require.NoError(t, err)
+	require.Equal(t, &GCPSecretManagerSource{Project: "my-project", Secret: "dss-keys", Version: "latest"}, source)
+
+	source, err = SecretSourceFromURI("gcpsm://my-project/dss-keys/3")
+	require.NoError(t, err)
+	require.Equal(t, "3", source.(*GCPSecretManagerSource).Version)
+
+	t.Setenv("VAULT_TOKEN", "s.token")
+	source, err = SecretSourceFromURI("vault://vault.example.com:8200/secret/data/dss#public_keys")
+	require.NoError(t, err)
+	require.Equal(t, &VaultSource{
+		Address: &url.URL{Scheme: "https", Host: "vault.example.com:8200"},
+		Path:    "secret/data/dss",
+		Field:   "public_keys",
+		Token:   "s.token",
+	}, source)
+
+	for _, uri := range []string{
+		"gcpsm://my-project",
+		"gcpsm://my-project/dss-keys/3/extra",
+		"vault://vault.example.com/secret/data/dss",
+		"awssm://region/secret",
+		"/path/to/key.pem",
+	} {
+		_, err := SecretSourceFromURI(uri)
+		require.Error(t, err, uri)
+	}
+}
+
+func publicKeysPEM(t *testing.T, keys ...*rsa.PrivateKey) []byte {
+	var data []byte
+	for _, k := range keys {
+		der, err := x509.MarshalPKIXPublicKey(&k.PublicKey)
+		require.NoError(t, err)
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})...)
+	}
+	return data
+}
+
+func TestGCPSecretManagerKeyResolver(t *testing.T) {
+	key1, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key2, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	payload := publicKeysPEM(t, key1, key2)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/projects/my-project/secrets/dss-keys/versions/latest:access" {
+			http.NotFound(w, r)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+			"payload": map[string]string{"data": base64.StdEncoding.EncodeToString(payload)},
+		}))
+	}))
+	defer server.Close()
+
+	resolver := &SecretKeyResolver{Source: &GCPSecretManagerSource{
+		Project: "my-project", Secret: "dss-keys", Version: "latest", Endpoint: server.URL, Client: server.Client(),
+	}}
+	keys, err := resolver.ResolveKeys(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{&key1.PublicKey, &key2.PublicKey}, keys)
+
+	resolver.Source.(*GCPSecretManagerSource).Secret = "missing"
+	_, err = resolver.ResolveKeys(context.Background())
+	require.Error(t, err)
+}
+
+func TestVaultKeyResolver(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	payload := string(publicKeysPEM(t, key))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/dss": // Key/value engine version 2
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"data":     map[string]string{"public_keys": payload},
+					"metadata": map[string]int{"version": 1},
+				},
+			}))
+		case "/v1/kv/dss": // Key/value engine version 1
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]string{"public_keys": payload},
+			}))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	address, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	for _, path := range []string{"secret/data/dss", "kv/dss"} {
+		resolver := &SecretKeyResolver{Source: &VaultSource{
+			Address: address, Path: path, Field: "public_keys", Token: "s.token", Client: server.Client(),
+		}}
+		keys, err := resolver.ResolveKeys(context.Background())
+		require.NoError(t, err, path)
+		require.Equal(t, []interface{}{&key.PublicKey}, keys)
+	}
+
+	for _, source := range []*VaultSource{
+		{Address: address, Path: "kv/dss", Field: "public_keys", Token: "bad", Client: server.Client()},
+		{Address: address, Path: "kv/dss", Field: "other", Token: "s.token", Client: server.Client()},
+	} {
+		_, err := (&SecretKeyResolver{Source: source}).ResolveKeys(context.Background())
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	tokenCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dss_auth_token_cache_lookups_total",
+		Help: "Number of lookups of access tokens in the cache of verified tokens, by result (hit or miss).",
+	}, []string{"result"})
+	tokenCacheEntries = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "dss_auth_token_cache_entries",
+		Help: "Number of verified access tokens cached.",
+	})
+)
+
+// tokenCache holds the claims of verified access tokens until they expire, so
+// that tokens presented repeatedly are parsed and verified once. Tokens are
+// identified by their SHA-256 hash rather than kept in memory.
+type tokenCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[[sha256.Size]byte]claims
+}
+
+// newTokenCache returns an empty tokenCache holding at most size tokens, or
+// nil if size is not positive.
+func newTokenCache(size int) *tokenCache {
+	if size <= 0 {
+		return nil
+	}
+	return &tokenCache{size: size, entries: make(map[[sha256.Size]byte]claims)}
+}
+
+// Get returns the claims of token if it was verified and has not expired
+// since.
+func (c *tokenCache) Get(token string) (claims, bool) {
+	if c == nil {
+		return claims{}, false
+	}
+	key := sha256.Sum256([]byte(token))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cl, ok := c.entries[key]
+	if ok && !Now().Before(time.Unix(cl.ExpiresAt, 0)) {
+		delete(c.entries, key)
+		tokenCacheEntries.Set(float64(len(c.entries)))
+		ok = false
+	}
+	if ok {
+		tokenCacheLookups.WithLabelValues("hit").Inc()
+	} else {
+		tokenCacheLookups.WithLabelValues("miss").Inc()
+	}
+	return cl, ok
+}
+
+// Add records that token was verified with claims cl. When the cache is full,
+// expired tokens are removed and, if none expired, an arbitrary one.
+func (c *tokenCache) Add(token string, cl claims) {
+	if c == nil {
+		return
+	}
+	key := sha256.Sum256([]byte(token))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size {
+		now := Now()
+		for k, e := range c.entries {
+			if !now.Before(time.Unix(e.ExpiresAt, 0)) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < c.size {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cl
+	tokenCacheEntries.Set(float64(len(c.entries)))
+}
+
+// Clear removes all the tokens, e.g. as the keys which verified them are no
+// longer trusted.
+func (c *tokenCache) Clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[[sha256.Size]byte]claims)
+	tokenCacheEntries.Set(0)
+}
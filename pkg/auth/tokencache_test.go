@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenCache(t *testing.T) {
+	Now = func() time.Time { return time.Unix(42, 0) }
+	defer func() { Now = time.Now }()
+
+	c := newTokenCache(2)
+	_, ok := c.Get("a")
+	require.False(t, ok)
+
+	c.Add("a", claims{StandardClaims: jwt.StandardClaims{Subject: "uss1", ExpiresAt: 100}})
+	cl, ok := c.Get("a")
+	require.True(t, ok)
+	require.Equal(t, "uss1", cl.Subject)
+
+	// Expired tokens are not returned, and make room for new ones.
+	c.Add("b", claims{StandardClaims: jwt.StandardClaims{ExpiresAt: 42}})
+	_, ok = c.Get("b")
+	require.False(t, ok)
+	c.Add("b", claims{StandardClaims: jwt.StandardClaims{ExpiresAt: 42}})
+	c.Add("c", claims{StandardClaims: jwt.StandardClaims{ExpiresAt: 100}})
+	require.Len(t, c.entries, 2)
+	_, ok = c.Get("a")
+	require.True(t, ok)
+
+	// Without expired tokens, one is evicted.
+	c.Add("d", claims{StandardClaims: jwt.StandardClaims{ExpiresAt: 100}})
+	require.Len(t, c.entries, 2)
+	_, ok = c.Get("d")
+	require.True(t, ok)
+
+	c.Clear()
+	_, ok = c.Get("d")
+	require.False(t, ok)
+
+	// Caching is disabled by a size of 0.
+	disabled := newTokenCache(0)
+	disabled.Add("a", claims{StandardClaims: jwt.StandardClaims{ExpiresAt: 100}})
+	_, ok = disabled.Get("a")
+	require.False(t, ok)
+}
+
+func TestAuthorizerCachesTokens(t *testing.T) {
+	jwt.TimeFunc = func() time.Time { return time.Unix(42, 0) }
+	Now = jwt.TimeFunc
+	defer func() {
+		jwt.TimeFunc = time.Now
+		Now = time.Now
+	}()
+
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+	a, err := NewRSAAuthorizer(context.Background(), Configuration{
+		KeyResolver:       &fromMemoryKeyResolver{Keys: []interface{}{&key.PublicKey}},
+		KeyRefreshTimeout: time.Hour,
+		AcceptedAudiences: []string{""},
+		TokenCacheSize:    10,
+	})
+	require.NoError(t, err)
+
+	req := rsaTokenReq(key, 100, 20)
+	require.NoError(t, a.Authorize(nil, req, nil).Error)
+
+	// The token is not verified again while cached.
+	a.keys = []interface{}{&otherKey.PublicKey}
+	res := a.Authorize(nil, req, nil)
+	require.NoError(t, res.Error)
+	require.Equal(t, "real_owner", *res.ClientID)
+
+	// Unless the keys change.
+	a.setKeys([]interface{}{&key.PublicKey})
+	a.setKeys([]interface{}{&otherKey.PublicKey})
+	require.Error(t, a.Authorize(nil, req, nil).Error)
+}
@@ -0,0 +1,60 @@
+package aux
+
+import (
+	"context"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+)
+
+// GetRIDActivity returns time-bucketed counts of the activity of the remote ID
+// pool.
+func (a *Server) GetRIDActivity(ctx context.Context, req *restapi.GetRIDActivityRequest) restapi.GetRIDActivityResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.GetRIDActivityResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	if req.Hours == nil || *req.Hours <= 0 {
+		return restapi.GetRIDActivityResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing or invalid hours: expected a positive integer"))}}
+	}
+	bucketMinutes := int32(60)
+	if req.BucketMinutes != nil {
+		bucketMinutes = *req.BucketMinutes
+	}
+	if bucketMinutes <= 0 {
+		return restapi.GetRIDActivityResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Invalid bucket_minutes: expected a positive integer"))}}
+	}
+
+	buckets, err := a.RIDApp.GetActivity(ctx, time.Duration(*req.Hours)*time.Hour, time.Duration(bucketMinutes)*time.Minute)
+	if err != nil {
+		err = stacktrace.Propagate(err, "Unable to get remote ID activity")
+		errResp := &restapi.ErrorResponse{Message: dsserr.Handle(ctx, err)}
+		switch stacktrace.GetCode(err) {
+		case dsserr.BadRequest:
+			return restapi.GetRIDActivityResponseSet{Response400: errResp}
+		case dsserr.NotFound:
+			return restapi.GetRIDActivityResponseSet{Response404: errResp}
+		default:
+			return restapi.GetRIDActivityResponseSet{Response500: &api.InternalServerErrorBody{
+				ErrorMessage: *errResp.Message}}
+		}
+	}
+
+	resp := &restapi.RIDActivityResponse{Buckets: make([]restapi.RIDActivityBucket, 0, len(buckets))}
+	for _, b := range buckets {
+		resp.Buckets = append(resp.Buckets, restapi.RIDActivityBucket{
+			TimeStart:           formatTime(&b.Start),
+			TimeEnd:             formatTime(&b.End),
+			IsasCreated:         int32(b.ISAsCreated),
+			IsasDeleted:         int32(b.ISAsDeleted),
+			ActiveSubscriptions: int32(b.ActiveSubscriptions),
+		})
+	}
+	return restapi.GetRIDActivityResponseSet{Response200: resp}
+}
@@ -0,0 +1,53 @@
+package aux
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/rid/application"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+// activityApp reports a single bucket of activity, if enabled.
+type activityApp struct {
+	application.App
+	enabled bool
+}
+
+func (a *activityApp) GetActivity(ctx context.Context, period time.Duration, bucket time.Duration) ([]*application.ActivityBucket, error) {
+	if !a.enabled {
+		return nil, stacktrace.NewErrorWithCode(dsserr.NotFound, "Not recorded")
+	}
+	end := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	return []*application.ActivityBucket{{Start: end.Add(-bucket), End: end, ISAsCreated: 2, ActiveSubscriptions: 1}}, nil
+}
+
+func TestGetRIDActivity(t *testing.T) {
+	var (
+		ctx           = context.Background()
+		one, zero     = int32(1), int32(0)
+		thirtyMinutes = int32(30)
+	)
+
+	resp := (&Server{RIDApp: &activityApp{enabled: true}}).GetRIDActivity(ctx, &restapi.GetRIDActivityRequest{
+		Hours: &one, BucketMinutes: &thirtyMinutes, Auth: api.AuthorizationResult{}})
+	require.NotNil(t, resp.Response200)
+	require.Equal(t, []restapi.RIDActivityBucket{{
+		TimeStart:           "2024-01-01T11:30:00Z",
+		TimeEnd:             "2024-01-01T12:00:00Z",
+		IsasCreated:         2,
+		ActiveSubscriptions: 1,
+	}}, resp.Response200.Buckets)
+
+	resp = (&Server{RIDApp: &activityApp{enabled: true}}).GetRIDActivity(ctx, &restapi.GetRIDActivityRequest{})
+	require.NotNil(t, resp.Response400)
+	resp = (&Server{RIDApp: &activityApp{enabled: true}}).GetRIDActivity(ctx, &restapi.GetRIDActivityRequest{Hours: &one, BucketMinutes: &zero})
+	require.NotNil(t, resp.Response400)
+	resp = (&Server{RIDApp: &activityApp{}}).GetRIDActivity(ctx, &restapi.GetRIDActivityRequest{Hours: &one})
+	require.NotNil(t, resp.Response404)
+}
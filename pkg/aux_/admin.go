@@ -0,0 +1,52 @@
+package aux
+
+import (
+	"net/http"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+)
+
+// adminOperations are the methods and example paths of the operations
+// administering the pool, which require the dss.admin or
+// dss.transfer_ownership scopes: reconciliation, expiry and transfer of ISAs,
+// activity and coverage statistics, label searches and owner aliases.
+var adminOperations = []struct {
+	method string
+	path   string
+}{
+	{http.MethodPost, "/aux/v1/reconciliation/rid/isas"},
+	{http.MethodPost, "/aux/v1/rid/identification_service_areas/expire"},
+	{http.MethodPost, "/aux/v1/rid/transfer_ownership"},
+	{http.MethodGet, "/aux/v1/rid/activity"},
+	{http.MethodGet, "/aux/v1/rid/tiles/0/0/0"},
+	{http.MethodGet, "/aux/v1/rid/identification_service_areas"},
+	{http.MethodGet, "/aux/v1/rid/subscriptions"},
+	{http.MethodGet, "/aux/v1/owner_aliases"},
+	{http.MethodPut, "/aux/v1/owner_aliases"},
+	{http.MethodDelete, "/aux/v1/owner_aliases"},
+}
+
+// IsAdminRoute returns whether route serves one of the operations
+// administering the pool.
+func IsAdminRoute(route *api.Route) bool {
+	for _, op := range adminOperations {
+		if route.Method == op.method && route.Pattern.MatchString(op.path) {
+			return true
+		}
+	}
+	return false
+}
+
+// KeepAdminRoutes removes from router the routes of the operations other than
+// those administering the pool if admin, or the routes of those operations
+// otherwise, so that they can be served on a listener of their own.
+func KeepAdminRoutes(router *restapi.APIRouter, admin bool) {
+	routes := make([]*api.Route, 0, len(router.Routes))
+	for _, route := range router.Routes {
+		if IsAdminRoute(route) == admin {
+			routes = append(routes, route)
+		}
+	}
+	router.Routes = routes
+}
@@ -0,0 +1,38 @@
+package aux
+
+import (
+	"net/http"
+	"testing"
+
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeepAdminRoutes(t *testing.T) {
+	admin := restapi.MakeAPIRouter(&Server{}, nil)
+	KeepAdminRoutes(&admin, true)
+	public := restapi.MakeAPIRouter(&Server{}, nil)
+	KeepAdminRoutes(&public, false)
+
+	// Every administrative operation is served by exactly one route, which
+	// the public router no longer serves.
+	require.Len(t, admin.Routes, len(adminOperations))
+	require.Len(t, public.Routes, len(restapi.MakeAPIRouter(&Server{}, nil).Routes)-len(adminOperations))
+	for _, route := range public.Routes {
+		require.False(t, IsAdminRoute(route), "%s %s", route.Method, route.Pattern)
+	}
+
+	served := func(router restapi.APIRouter, method, path string) bool {
+		for _, route := range router.Routes {
+			if route.Method == method && route.Pattern.MatchString(path) {
+				return true
+			}
+		}
+		return false
+	}
+	require.True(t, served(admin, http.MethodPost, "/aux/v1/rid/transfer_ownership"))
+	require.False(t, served(public, http.MethodPost, "/aux/v1/rid/transfer_ownership"))
+	require.True(t, served(public, http.MethodGet, "/aux/v1/rid/identification_service_areas/count"))
+	require.False(t, served(admin, http.MethodGet, "/aux/v1/rid/identification_service_areas/count"))
+	require.True(t, served(public, http.MethodGet, "/aux/v1/version"))
+}
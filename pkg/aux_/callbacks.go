@@ -0,0 +1,60 @@
+package aux
+
+import (
+	"context"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
+	"github.com/interuss/stacktrace"
+)
+
+// SetSubscriptionCallbacks replaces the URLs at which a subscription owned by
+// the client is notified besides its callback URL.
+func (a *Server) SetSubscriptionCallbacks(ctx context.Context, req *restapi.SetSubscriptionCallbacksRequest) restapi.SetSubscriptionCallbacksResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.SetSubscriptionCallbacksResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	if req.Auth.ClientID == nil {
+		return restapi.SetSubscriptionCallbacksResponseSet{Response403: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Missing owner"))}}
+	}
+	if err := ridserver.CheckBody(req.Body, req.BodyParseError); err != nil {
+		return restapi.SetSubscriptionCallbacksResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, err)}}
+	}
+	id, err := dssmodels.IDFromString(req.Id)
+	if err != nil {
+		return restapi.SetSubscriptionCallbacksResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Invalid ID format"))}}
+	}
+	for _, url := range req.Body.CallbackUrls {
+		if err := a.URLPolicy.Validate(url); err != nil {
+			return restapi.SetSubscriptionCallbacksResponseSet{Response400: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, stacktrace.Propagate(err, "Invalid callback URL"))}}
+		}
+	}
+
+	sub, err := a.RIDApp.SetSubscriptionCallbacks(ctx, id, dssmodels.Owner(*req.Auth.ClientID), req.Body.CallbackUrls)
+	if err != nil {
+		err = stacktrace.Propagate(err, "Could not set Subscription callbacks")
+		errResp := &restapi.ErrorResponse{Message: dsserr.Handle(ctx, err)}
+		switch stacktrace.GetCode(err) {
+		case dsserr.BadRequest:
+			return restapi.SetSubscriptionCallbacksResponseSet{Response400: errResp}
+		case dsserr.PermissionDenied:
+			return restapi.SetSubscriptionCallbacksResponseSet{Response403: errResp}
+		case dsserr.NotFound:
+			return restapi.SetSubscriptionCallbacksResponseSet{Response404: errResp}
+		default:
+			return restapi.SetSubscriptionCallbacksResponseSet{Response500: &api.InternalServerErrorBody{
+				ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Got an unexpected error"))}}
+		}
+	}
+	ref := subscriptionToReference(sub)
+	return restapi.SetSubscriptionCallbacksResponseSet{Response200: &ref}
+}
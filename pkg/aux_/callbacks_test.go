@@ -0,0 +1,68 @@
+package aux
+
+import (
+	"context"
+	"testing"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/dss/pkg/rid/application"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+// callbacksApp keeps the callback URLs of a subscription owned by uss1.
+type callbacksApp struct {
+	application.App
+	sub *ridmodels.Subscription
+}
+
+func (a *callbacksApp) SetSubscriptionCallbacks(_ context.Context, id dssmodels.ID, owner dssmodels.Owner, urls []string) (*ridmodels.Subscription, error) {
+	switch {
+	case id != a.sub.ID:
+		return nil, stacktrace.NewErrorWithCode(dsserr.NotFound, "Subscription %s not found", id)
+	case owner != a.sub.Owner:
+		return nil, stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Subscription owned by %s", a.sub.Owner)
+	}
+	a.sub.CallbackURLs = urls
+	return a.sub, nil
+}
+
+func TestSetSubscriptionCallbacks(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		client = "uss1"
+		other  = "uss2"
+		id     = "4348c8e5-0b1c-43cf-9114-2e67a4532765"
+		app    = &callbacksApp{sub: &ridmodels.Subscription{
+			ID:    dssmodels.ID(id),
+			Owner: "uss1",
+			URL:   "https://uss1.example/isa",
+		}}
+		server = &Server{RIDApp: app}
+		set    = func(client *string, urls ...string) restapi.SetSubscriptionCallbacksResponseSet {
+			return server.SetSubscriptionCallbacks(ctx, &restapi.SetSubscriptionCallbacksRequest{
+				Id: id, Body: &restapi.SetSubscriptionCallbacksParameters{CallbackUrls: urls},
+				Auth: api.AuthorizationResult{ClientID: client}})
+		}
+	)
+
+	resp := set(&client, "https://uss1.example/flights")
+	require.NotNil(t, resp.Response200)
+	require.Equal(t, "https://uss1.example/isa", resp.Response200.CallbackUrl)
+	require.Equal(t, &[]string{"https://uss1.example/flights"}, resp.Response200.CallbackUrls)
+
+	// Clearing the callback URLs leaves the callback URL only.
+	resp = set(&client)
+	require.NotNil(t, resp.Response200)
+	require.Nil(t, resp.Response200.CallbackUrls)
+
+	// Callback URLs must satisfy the URL policy.
+	require.NotNil(t, set(&client, "http://uss1.example/flights").Response400)
+
+	require.NotNil(t, set(&other, "https://uss2.example/flights").Response403)
+	require.NotNil(t, set(nil, "https://uss1.example/flights").Response403)
+}
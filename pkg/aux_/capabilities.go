@@ -0,0 +1,88 @@
+package aux
+
+import (
+	"context"
+	"sync/atomic"
+
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	"github.com/interuss/dss/pkg/geo"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	scdmodels "github.com/interuss/dss/pkg/scd/models"
+)
+
+// Names of the optional features reported by GetCapabilities.
+const (
+	FeatureOwnerImpersonation         = "owner_impersonation"
+	FeatureWriteTokenReplayProtection = "write_token_replay_protection"
+	FeatureNotificationCounters       = "rid_notification_counters"
+	FeatureActivity                   = "rid_activity"
+	FeatureCORS                       = "cors"
+	FeatureMapUI                      = "rid_map_ui"
+)
+
+// Capabilities describes the parts of the DSS that depend on the
+// configuration of this instance.
+type Capabilities struct {
+	// SCDEnabled is true if the strategic coordination API is served.
+	SCDEnabled bool
+	// Features lists the optional features enabled on this instance.
+	Features []string
+	// MaxResults is the maximum number of entities returned by a search, or
+	// zero if only the store limit applies.
+	MaxResults int
+}
+
+// SwappableCapabilities holds Capabilities which can be replaced while in use,
+// e.g. when the configuration is reloaded.
+type SwappableCapabilities struct {
+	current atomic.Pointer[Capabilities]
+}
+
+// Swap replaces the capabilities held by c.
+func (c *SwappableCapabilities) Swap(capabilities Capabilities) {
+	c.current.Store(&capabilities)
+}
+
+// Load returns the capabilities held by c, the zero value if c is nil or
+// holds none.
+func (c *SwappableCapabilities) Load() Capabilities {
+	if c == nil {
+		return Capabilities{}
+	}
+	if current := c.current.Load(); current != nil {
+		return *current
+	}
+	return Capabilities{}
+}
+
+// GetCapabilities returns the APIs, limits and optional features supported by
+// the server.
+func (a *Server) GetCapabilities(context.Context, *restapi.GetCapabilitiesRequest) restapi.GetCapabilitiesResponseSet {
+	capabilities := a.Capabilities.Load()
+	resp := &restapi.CapabilitiesResponse{
+		Apis: []restapi.APICapability{
+			{Name: "ASTM F3411-19 remote ID", BasePath: "/v1/dss"},
+			{Name: "ASTM F3411-22a remote ID", BasePath: "/rid/v2/dss"},
+			{Name: "DSS auxiliary", BasePath: "/aux/v1"},
+		},
+		EntityTypes:                       []string{"identification_service_area", "rid_subscription"},
+		MaxAreaKm2:                        geo.MaxAllowedAreaKm2,
+		MaxRidSubscriptionDurationSeconds: float32(ridmodels.MaxSubscriptionDuration().Seconds()),
+		MaxResults:                        dssmodels.MaxResultLimit,
+		Features:                          capabilities.Features,
+	}
+	if capabilities.MaxResults > 0 {
+		resp.MaxResults = float32(capabilities.MaxResults)
+	}
+	if capabilities.SCDEnabled {
+		resp.Apis = append(resp.Apis, restapi.APICapability{Name: "ASTM F3548-21 strategic coordination", BasePath: "/dss/v1"})
+		resp.EntityTypes = append(resp.EntityTypes, "operational_intent_reference", "constraint_reference", "scd_subscription", "uss_availability")
+		scdDuration := float32(scdmodels.MaxSubscriptionDuration().Seconds())
+		resp.MaxScdSubscriptionDurationSeconds = &scdDuration
+	}
+	if resp.Features == nil {
+		resp.Features = []string{}
+	}
+	return restapi.GetCapabilitiesResponseSet{Response200: resp}
+}
@@ -0,0 +1,38 @@
+package aux
+
+import (
+	"context"
+	"testing"
+
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCapabilities(t *testing.T) {
+	ctx := context.Background()
+
+	resp := (&Server{}).GetCapabilities(ctx, &restapi.GetCapabilitiesRequest{}).Response200
+	require.NotNil(t, resp)
+	require.Len(t, resp.Apis, 3)
+	require.NotContains(t, resp.EntityTypes, "operational_intent_reference")
+	require.Nil(t, resp.MaxScdSubscriptionDurationSeconds)
+	require.Equal(t, float32(24*60*60), resp.MaxRidSubscriptionDurationSeconds)
+	require.NotNil(t, resp.Features)
+	require.Empty(t, resp.Features)
+	require.Equal(t, float32(dssmodels.MaxResultLimit), resp.MaxResults)
+
+	s := &Server{Capabilities: &SwappableCapabilities{}}
+	s.Capabilities.Swap(Capabilities{SCDEnabled: true, Features: []string{FeatureCORS}, MaxResults: 500})
+	resp = s.GetCapabilities(ctx, &restapi.GetCapabilitiesRequest{}).Response200
+	require.Len(t, resp.Apis, 4)
+	require.Contains(t, resp.EntityTypes, "operational_intent_reference")
+	require.NotNil(t, resp.MaxScdSubscriptionDurationSeconds)
+	require.Equal(t, []string{FeatureCORS}, resp.Features)
+	require.Equal(t, float32(500), resp.MaxResults)
+
+	s.Capabilities.Swap(Capabilities{})
+	resp = s.GetCapabilities(ctx, &restapi.GetCapabilitiesRequest{}).Response200
+	require.Len(t, resp.Apis, 3)
+	require.Empty(t, resp.Features)
+}
@@ -0,0 +1,85 @@
+package aux
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/geo"
+	"github.com/interuss/dss/pkg/limits"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
+	"github.com/interuss/stacktrace"
+)
+
+// CountISAs returns the number of active ISAs a remote ID search of the area
+// and time range would find, without fetching them.
+func (a *Server) CountISAs(ctx context.Context, req *restapi.CountISAsRequest) restapi.CountISAsResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.CountISAsResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+
+	cells, earliest, latest, err := parseSearch(req.Area, req.EarliestTime, req.LatestTime)
+	if err != nil {
+		if stacktrace.GetCode(err) == dsserr.AreaTooLarge {
+			return restapi.CountISAsResponseSet{Response413: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, err)}}
+		}
+		return restapi.CountISAsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, err)}}
+	}
+	ridserver.ObserveSearchArea(ctx, "isa", cells)
+
+	count, err := a.RIDApp.CountISAs(ctx, cells, earliest, latest)
+	if err != nil {
+		err = stacktrace.Propagate(err, "Unable to count ISAs")
+		if stacktrace.GetCode(err) == dsserr.BadRequest {
+			return restapi.CountISAsResponseSet{Response400: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, err)}}
+		}
+		return restapi.CountISAsResponseSet{Response500: &api.InternalServerErrorBody{
+			ErrorMessage: *dsserr.Handle(ctx, err)}}
+	}
+	return restapi.CountISAsResponseSet{Response200: &restapi.ISACountResponse{
+		Count:      int32(count),
+		MaxResults: int32(limits.MaxResults(ctx)),
+	}}
+}
+
+// parseSearch parses the area and time range of a search as remote ID
+// searches do. The error has code dsserr.AreaTooLarge if the area is too large
+// and dsserr.BadRequest if the parameters are otherwise invalid.
+func parseSearch(area, earliestTime, latestTime *string) (s2.CellUnion, *time.Time, *time.Time, error) {
+	if area == nil {
+		return nil, nil, nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing area")
+	}
+	cells, err := geo.AreaToCellIDs(*area)
+	if err != nil {
+		if errors.Is(err, geo.ErrAreaTooLarge) {
+			return nil, nil, nil, stacktrace.Propagate(err, "Invalid area")
+		}
+		return nil, nil, nil, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Invalid area")
+	}
+
+	var earliest, latest *time.Time
+	if earliestTime != nil {
+		ts, err := ridserver.ParseQueryTime("earliest_time", *earliestTime)
+		if err != nil {
+			return nil, nil, nil, stacktrace.Propagate(err, "Unable to convert earliest timestamp")
+		}
+		earliest = &ts
+	}
+	if latestTime != nil {
+		ts, err := ridserver.ParseQueryTime("latest_time", *latestTime)
+		if err != nil {
+			return nil, nil, nil, stacktrace.Propagate(err, "Unable to convert latest timestamp")
+		}
+		latest = &ts
+	}
+	return cells, earliest, latest, nil
+}
@@ -0,0 +1,50 @@
+package aux
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/dss/pkg/rid/application"
+	"github.com/stretchr/testify/require"
+)
+
+// countApp counts 3 ISAs, recording the time range counted.
+type countApp struct {
+	application.App
+	earliest, latest *time.Time
+}
+
+func (a *countApp) CountISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time) (int, error) {
+	a.earliest, a.latest = earliest, latest
+	return 3, nil
+}
+
+func TestCountISAs(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		area     = "37.4047,-122.1474,37.4037,-122.1485,37.4035,-122.1466"
+		large    = "0,0,0,1,1,1,1,0"
+		earliest = "2024-01-02T05:04:05+02:00"
+		invalid  = "2024-01-02"
+		app      = &countApp{}
+	)
+
+	resp := (&Server{RIDApp: app}).CountISAs(ctx, &restapi.CountISAsRequest{
+		Area: &area, EarliestTime: &earliest, Auth: api.AuthorizationResult{}})
+	require.NotNil(t, resp.Response200)
+	require.Equal(t, restapi.ISACountResponse{Count: 3, MaxResults: dssmodels.MaxResultLimit}, *resp.Response200)
+	require.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), *app.earliest)
+	require.Nil(t, app.latest)
+
+	resp = (&Server{RIDApp: app}).CountISAs(ctx, &restapi.CountISAsRequest{})
+	require.NotNil(t, resp.Response400)
+	resp = (&Server{RIDApp: app}).CountISAs(ctx, &restapi.CountISAsRequest{Area: &area, LatestTime: &invalid})
+	require.NotNil(t, resp.Response400)
+	resp = (&Server{RIDApp: app}).CountISAs(ctx, &restapi.CountISAsRequest{Area: &large})
+	require.NotNil(t, resp.Response413)
+}
@@ -0,0 +1,44 @@
+package aux
+
+import (
+	"context"
+
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/geo"
+	"github.com/interuss/stacktrace"
+)
+
+// GetCovering returns the S2 cells covering an area, as the DSS computes them
+// for searches and entities, to diagnose unexpected results or rejections.
+func (a *Server) GetCovering(ctx context.Context, req *restapi.GetCoveringRequest) restapi.GetCoveringResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.GetCoveringResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	if req.Area == nil {
+		return restapi.GetCoveringResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing area"))}}
+	}
+
+	covering, err := geo.DescribeAreaCovering(*req.Area)
+	if err != nil {
+		return restapi.GetCoveringResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Invalid area"))}}
+	}
+
+	resp := &restapi.CoveringResponse{
+		AreaKm2:        covering.AreaKm2,
+		MaxAreaKm2:     geo.MaxAllowedAreaKm2,
+		ExceedsMaxArea: covering.ExceedsMaxArea(),
+		MinLevel:       int32(geo.RegionCoverer.MinLevel),
+		MaxLevel:       int32(geo.RegionCoverer.MaxLevel),
+		Cells:          make([]restapi.CoveringCell, 0, len(covering.Cells)),
+		CellsAreaKm2:   geo.CellUnionAreaKm2(covering.Cells),
+	}
+	for _, cell := range covering.Cells {
+		resp.Cells = append(resp.Cells, restapi.CoveringCell{Token: cell.ToToken(), Level: int32(cell.Level())})
+	}
+	return restapi.GetCoveringResponseSet{Response200: resp}
+}
@@ -0,0 +1,38 @@
+package aux
+
+import (
+	"context"
+	"testing"
+
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCovering(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		small = "37.4047,-122.1474,37.4037,-122.1485,37.4035,-122.1466"
+		large = "0,0,0,1,1,1,1,0"
+		bad   = "0,0,0,1"
+	)
+
+	resp := (&Server{}).GetCovering(ctx, &restapi.GetCoveringRequest{Area: &small})
+	require.NotNil(t, resp.Response200)
+	require.False(t, resp.Response200.ExceedsMaxArea)
+	require.NotEmpty(t, resp.Response200.Cells)
+	for _, cell := range resp.Response200.Cells {
+		require.GreaterOrEqual(t, cell.Level, resp.Response200.MinLevel)
+		require.LessOrEqual(t, cell.Level, resp.Response200.MaxLevel)
+	}
+	require.GreaterOrEqual(t, resp.Response200.CellsAreaKm2, resp.Response200.AreaKm2)
+
+	resp = (&Server{}).GetCovering(ctx, &restapi.GetCoveringRequest{Area: &large})
+	require.NotNil(t, resp.Response200)
+	require.True(t, resp.Response200.ExceedsMaxArea)
+	require.Empty(t, resp.Response200.Cells)
+
+	resp = (&Server{}).GetCovering(ctx, &restapi.GetCoveringRequest{Area: &bad})
+	require.NotNil(t, resp.Response400)
+	resp = (&Server{}).GetCovering(ctx, &restapi.GetCoveringRequest{})
+	require.NotNil(t, resp.Response400)
+}
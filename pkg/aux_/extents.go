@@ -0,0 +1,149 @@
+package aux
+
+import (
+	"context"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/limits"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
+	"github.com/interuss/stacktrace"
+)
+
+// GetISAExtents returns the 4D volume of an ISA as written, which the ISAs of
+// remote ID responses do not carry.
+func (a *Server) GetISAExtents(ctx context.Context, req *restapi.GetISAExtentsRequest) restapi.GetISAExtentsResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.GetISAExtentsResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	id, err := dssmodels.IDFromString(req.Id)
+	if err != nil {
+		return restapi.GetISAExtentsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Invalid ID format"))}}
+	}
+
+	isa, err := a.RIDApp.GetISA(ctx, id)
+	if err != nil {
+		return restapi.GetISAExtentsResponseSet{Response500: &api.InternalServerErrorBody{
+			ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Could not get ISA"))}}
+	}
+	if isa == nil {
+		return restapi.GetISAExtentsResponseSet{Response404: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.NotFound, "ISA %s not found", req.Id))}}
+	}
+	return restapi.GetISAExtentsResponseSet{Response200: isaExtentsToRest(isa)}
+}
+
+// SearchISAExtents searches ISAs as remote ID searches do and returns their
+// extents, such that clients may discard the ISAs whose outline does not
+// intersect the area although their cell coverings do.
+func (a *Server) SearchISAExtents(ctx context.Context, req *restapi.SearchISAExtentsRequest) restapi.SearchISAExtentsResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.SearchISAExtentsResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	isas, err := a.searchISAs(ctx, req.Auth.ClientID, req.Area, req.EarliestTime, req.LatestTime)
+	if err != nil {
+		switch stacktrace.GetCode(err) {
+		case dsserr.AreaTooLarge:
+			return restapi.SearchISAExtentsResponseSet{Response413: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, err)}}
+		case dsserr.BadRequest:
+			return restapi.SearchISAExtentsResponseSet{Response400: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, err)}}
+		default:
+			return restapi.SearchISAExtentsResponseSet{Response500: &api.InternalServerErrorBody{
+				ErrorMessage: *dsserr.Handle(ctx, err)}}
+		}
+	}
+
+	areas := make([]restapi.ISAExtentsResponse, 0, len(isas))
+	for _, isa := range isas {
+		areas = append(areas, *isaExtentsToRest(isa))
+	}
+	return restapi.SearchISAExtentsResponseSet{Response200: &restapi.SearchISAExtentsResponse{
+		ServiceAreas: areas,
+	}}
+}
+
+// searchISAs searches ISAs as remote ID searches do on behalf of clientID.
+// Errors have the code dsserr.BadRequest if the search is invalid, and
+// dsserr.AreaTooLarge if its area is too large or it finds too many ISAs.
+func (a *Server) searchISAs(ctx context.Context, clientID *string, area, earliestTime, latestTime *string) ([]*ridmodels.IdentificationServiceArea, error) {
+	cells, earliest, latest, err := parseSearch(area, earliestTime, latestTime)
+	if err != nil {
+		if stacktrace.GetCode(err) == dsserr.AreaTooLarge {
+			return nil, err
+		}
+		return nil, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Invalid search")
+	}
+	ridserver.ObserveSearchArea(ctx, "isa", cells)
+
+	var excludeOwner dssmodels.Owner
+	if ridserver.ExcludeSelf(ctx) && clientID != nil {
+		excludeOwner = dssmodels.Owner(*clientID)
+	}
+	isas, err := a.RIDApp.SearchISAs(ctx, cells, earliest, latest, excludeOwner)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to search ISAs")
+	}
+	isas, err = ridserver.FilterISAsByArea(ctx, *area, isas)
+	if err != nil {
+		return nil, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Invalid area")
+	}
+	isas, err = limits.Apply(ctx, isas)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Too many ISAs found")
+	}
+	return isas, nil
+}
+
+// isaExtentsToRest returns the extents of isa in the auxiliary API.
+func isaExtentsToRest(isa *ridmodels.IdentificationServiceArea) *restapi.ISAExtentsResponse {
+	return &restapi.ISAExtentsResponse{
+		Id:      isa.ID.String(),
+		Version: isa.Version.String(),
+		Extents: volume4DToRest(isa.Extents()),
+	}
+}
+
+// volume4DToRest converts a 4D volume to its auxiliary API representation.
+// Footprints other than polygons and circles are omitted.
+func volume4DToRest(vol4 *dssmodels.Volume4D) restapi.Volume4D {
+	result := restapi.Volume4D{}
+	if vol4.StartTime != nil {
+		ts := vol4.StartTime.Format(time.RFC3339Nano)
+		result.TimeStart = &ts
+	}
+	if vol4.EndTime != nil {
+		ts := vol4.EndTime.Format(time.RFC3339Nano)
+		result.TimeEnd = &ts
+	}
+	vol3 := vol4.SpatialVolume
+	if vol3 == nil {
+		return result
+	}
+	result.Volume.AltitudeLower = vol3.AltitudeLo
+	result.Volume.AltitudeUpper = vol3.AltitudeHi
+	switch footprint := vol3.Footprint.(type) {
+	case *dssmodels.GeoPolygon:
+		polygon := &restapi.Polygon{Vertices: make([]restapi.LatLngPoint, 0, len(footprint.Vertices))}
+		for _, v := range footprint.Vertices {
+			polygon.Vertices = append(polygon.Vertices, restapi.LatLngPoint{Lat: v.Lat, Lng: v.Lng})
+		}
+		result.Volume.OutlinePolygon = polygon
+	case *dssmodels.GeoCircle:
+		result.Volume.OutlineCircle = &restapi.Circle{
+			Center:  restapi.LatLngPoint{Lat: footprint.Center.Lat, Lng: footprint.Center.Lng},
+			RadiusM: footprint.RadiusMeter,
+		}
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package aux
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/s2"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/dss/pkg/rid/application"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+)
+
+// extentsApp returns isa if requested and finds it in any search.
+type extentsApp struct {
+	application.App
+	isa *ridmodels.IdentificationServiceArea
+}
+
+func (a *extentsApp) SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time, excludeOwner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error) {
+	return []*ridmodels.IdentificationServiceArea{a.isa}, nil
+}
+
+func (a *extentsApp) GetISA(ctx context.Context, id dssmodels.ID) (*ridmodels.IdentificationServiceArea, error) {
+	if id != a.isa.ID {
+		return nil, nil
+	}
+	return a.isa, nil
+}
+
+func TestGetISAExtents(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		start        = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		end          = start.Add(time.Hour)
+		lower, upper = float32(20), float32(120)
+		isa          = &ridmodels.IdentificationServiceArea{
+			ID:         dssmodels.ID("4348c8e5-0b1c-43cf-9114-2e67a4532765"),
+			StartTime:  &start,
+			EndTime:    &end,
+			Version:    dssmodels.VersionFromTime(start),
+			AltitudeLo: &lower,
+			AltitudeHi: &upper,
+			Footprint:  &dssmodels.GeoPolygon{Vertices: []*dssmodels.LatLngPoint{{Lat: 46.9, Lng: 7.4}, {Lat: 46.9, Lng: 7.41}, {Lat: 46.91, Lng: 7.41}}},
+		}
+		server = &Server{RIDApp: &extentsApp{isa: isa}}
+	)
+
+	resp := server.GetISAExtents(ctx, &restapi.GetISAExtentsRequest{Id: isa.ID.String()})
+	require.NotNil(t, resp.Response200)
+	require.Equal(t, isa.Version.String(), resp.Response200.Version)
+	startTime, endTime := "2024-01-02T03:04:05Z", "2024-01-02T04:04:05Z"
+	require.Equal(t, restapi.Volume4D{
+		Volume: restapi.Volume3D{
+			OutlinePolygon: &restapi.Polygon{Vertices: []restapi.LatLngPoint{{Lat: 46.9, Lng: 7.4}, {Lat: 46.9, Lng: 7.41}, {Lat: 46.91, Lng: 7.41}}},
+			AltitudeLower:  &lower,
+			AltitudeUpper:  &upper,
+		},
+		TimeStart: &startTime,
+		TimeEnd:   &endTime,
+	}, resp.Response200.Extents)
+
+	// ISAs written before footprints were stored have none.
+	isa.Footprint, isa.AltitudeLo, isa.AltitudeHi = nil, nil, nil
+	resp = server.GetISAExtents(ctx, &restapi.GetISAExtentsRequest{Id: isa.ID.String()})
+	require.NotNil(t, resp.Response200)
+	require.Equal(t, restapi.Volume3D{}, resp.Response200.Extents.Volume)
+
+	resp = server.GetISAExtents(ctx, &restapi.GetISAExtentsRequest{Id: "a3cde7e1-bc1c-4a95-bc94-0e2ba0e0dbbb"})
+	require.NotNil(t, resp.Response404)
+	resp = server.GetISAExtents(ctx, &restapi.GetISAExtentsRequest{Id: "invalid"})
+	require.NotNil(t, resp.Response400)
+}
+
+func TestSearchISAExtents(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		area  = "46.9,7.4,46.9,7.41,46.91,7.41"
+		large = "0,0,0,1,1,1,1,0"
+		start = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		isa   = &ridmodels.IdentificationServiceArea{
+			ID:        dssmodels.ID("4348c8e5-0b1c-43cf-9114-2e67a4532765"),
+			StartTime: &start,
+			Version:   dssmodels.VersionFromTime(start),
+			Footprint: &dssmodels.GeoCircle{Center: dssmodels.LatLngPoint{Lat: 46.9, Lng: 7.4}, RadiusMeter: 300},
+		}
+		server = &Server{RIDApp: &extentsApp{isa: isa}}
+	)
+
+	resp := server.SearchISAExtents(ctx, &restapi.SearchISAExtentsRequest{Area: &area})
+	require.NotNil(t, resp.Response200)
+	require.Len(t, resp.Response200.ServiceAreas, 1)
+	require.Equal(t, isa.ID.String(), resp.Response200.ServiceAreas[0].Id)
+	require.Equal(t, &restapi.Circle{Center: restapi.LatLngPoint{Lat: 46.9, Lng: 7.4}, RadiusM: 300},
+		resp.Response200.ServiceAreas[0].Extents.Volume.OutlineCircle)
+
+	resp = server.SearchISAExtents(ctx, &restapi.SearchISAExtentsRequest{})
+	require.NotNil(t, resp.Response400)
+	resp = server.SearchISAExtents(ctx, &restapi.SearchISAExtentsRequest{Area: &large})
+	require.NotNil(t, resp.Response413)
+}
@@ -0,0 +1,128 @@
+package aux
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/geo"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+)
+
+// circleVertices is the number of vertices of the polygons approximating
+// circular ISAs in GeoJSON, which has no circles.
+const circleVertices = 32
+
+// SearchISAsGeoJSON searches ISAs as remote ID searches do and returns them as
+// a GeoJSON FeatureCollection (RFC 7946), such that GIS tools and map UIs may
+// render the contents of the DSS directly. ISAs without a stored footprint,
+// i.e. last written before the DSS stored footprints, are omitted.
+func (a *Server) SearchISAsGeoJSON(ctx context.Context, req *restapi.SearchISAsGeoJSONRequest) restapi.SearchISAsGeoJSONResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.SearchISAsGeoJSONResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	isas, err := a.searchISAs(ctx, req.Auth.ClientID, req.Area, req.EarliestTime, req.LatestTime)
+	if err != nil {
+		switch stacktrace.GetCode(err) {
+		case dsserr.AreaTooLarge:
+			return restapi.SearchISAsGeoJSONResponseSet{Response413: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, err)}}
+		case dsserr.BadRequest:
+			return restapi.SearchISAsGeoJSONResponseSet{Response400: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, err)}}
+		default:
+			return restapi.SearchISAsGeoJSONResponseSet{Response500: &api.InternalServerErrorBody{
+				ErrorMessage: *dsserr.Handle(ctx, err)}}
+		}
+	}
+
+	features := make([]restapi.ISAFeature, 0, len(isas))
+	for _, isa := range isas {
+		if feature := isaToGeoJSON(isa); feature != nil {
+			features = append(features, *feature)
+		}
+	}
+	return restapi.SearchISAsGeoJSONResponseSet{Response200: &restapi.ISAFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: features,
+	}}
+}
+
+// isaToGeoJSON returns the GeoJSON Feature of isa, nil if isa has no polygon
+// or circle footprint.
+func isaToGeoJSON(isa *ridmodels.IdentificationServiceArea) *restapi.ISAFeature {
+	ring := footprintToGeoJSON(isa.Footprint)
+	if ring == nil {
+		return nil
+	}
+	feature := &restapi.ISAFeature{
+		Type: "Feature",
+		Id:   isa.ID.String(),
+		Geometry: restapi.GeoJSONPolygon{
+			Type:        "Polygon",
+			Coordinates: []restapi.GeoJSONPolygonCoordinatesItem{ring},
+		},
+		Properties: restapi.ISAFeatureProperties{
+			Owner:         isa.Owner.String(),
+			FlightsUrl:    isa.URL,
+			AltitudeLower: isa.AltitudeLo,
+			AltitudeUpper: isa.AltitudeHi,
+		},
+	}
+	if isa.Version != nil {
+		feature.Properties.Version = isa.Version.String()
+	}
+	if isa.StartTime != nil {
+		ts := isa.StartTime.Format(time.RFC3339Nano)
+		feature.Properties.TimeStart = &ts
+	}
+	if isa.EndTime != nil {
+		ts := isa.EndTime.Format(time.RFC3339Nano)
+		feature.Properties.TimeEnd = &ts
+	}
+	return feature
+}
+
+// footprintToGeoJSON returns the closed, counterclockwise ring of [lng, lat]
+// positions outlining footprint, circles being approximated by polygons of
+// circleVertices vertices. It returns nil for other footprints.
+func footprintToGeoJSON(footprint dssmodels.Geometry) restapi.GeoJSONPolygonCoordinatesItem {
+	var ring restapi.GeoJSONPolygonCoordinatesItem
+	switch footprint := footprint.(type) {
+	case *dssmodels.GeoPolygon:
+		for _, v := range footprint.Vertices {
+			ring = append(ring, restapi.GeoJSONPolygonCoordinatesItemItem{v.Lng, v.Lat})
+		}
+	case *dssmodels.GeoCircle:
+		center := s2.PointFromLatLng(s2.LatLngFromDegrees(footprint.Center.Lat, footprint.Center.Lng))
+		loop := s2.RegularLoop(center, geo.DistanceMetersToAngle(float64(footprint.RadiusMeter)), circleVertices)
+		for _, v := range loop.Vertices() {
+			ll := s2.LatLngFromPoint(v)
+			ring = append(ring, restapi.GeoJSONPolygonCoordinatesItemItem{ll.Lng.Degrees(), ll.Lat.Degrees()})
+		}
+	}
+	if len(ring) < 3 {
+		return nil
+	}
+
+	// RFC 7946 requires exterior rings to be counterclockwise, which the
+	// vertices of polygons written by USSs need not be.
+	var area float64
+	for i := range ring {
+		next := ring[(i+1)%len(ring)]
+		area += ring[i][0]*next[1] - next[0]*ring[i][1]
+	}
+	if area < 0 {
+		for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+			ring[i], ring[j] = ring[j], ring[i]
+		}
+	}
+	return append(ring, ring[0])
+}
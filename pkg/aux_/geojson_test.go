@@ -0,0 +1,86 @@
+package aux
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchISAsGeoJSON(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		area         = "46.9,7.4,46.9,7.41,46.91,7.41"
+		large        = "0,0,0,1,1,1,1,0"
+		start        = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		lower, upper = float32(20), float32(120)
+		isa          = &ridmodels.IdentificationServiceArea{
+			ID:         dssmodels.ID("4348c8e5-0b1c-43cf-9114-2e67a4532765"),
+			URL:        "https://uss.example.com/flights",
+			Owner:      dssmodels.Owner("uss1"),
+			StartTime:  &start,
+			Version:    dssmodels.VersionFromTime(start),
+			AltitudeLo: &lower,
+			AltitudeHi: &upper,
+			// Clockwise.
+			Footprint: &dssmodels.GeoPolygon{Vertices: []*dssmodels.LatLngPoint{{Lat: 46.9, Lng: 7.4}, {Lat: 46.91, Lng: 7.41}, {Lat: 46.9, Lng: 7.41}}},
+		}
+		server = &Server{RIDApp: &extentsApp{isa: isa}}
+	)
+
+	resp := server.SearchISAsGeoJSON(ctx, &restapi.SearchISAsGeoJSONRequest{Area: &area})
+	require.NotNil(t, resp.Response200)
+	startTime := "2024-01-02T03:04:05Z"
+	require.Equal(t, &restapi.ISAFeatureCollection{
+		Type: "FeatureCollection",
+		Features: []restapi.ISAFeature{{
+			Type: "Feature",
+			Id:   isa.ID.String(),
+			Geometry: restapi.GeoJSONPolygon{
+				Type: "Polygon",
+				Coordinates: []restapi.GeoJSONPolygonCoordinatesItem{{
+					{7.41, 46.9}, {7.41, 46.91}, {7.4, 46.9}, {7.41, 46.9},
+				}},
+			},
+			Properties: restapi.ISAFeatureProperties{
+				Owner:         "uss1",
+				Version:       isa.Version.String(),
+				FlightsUrl:    "https://uss.example.com/flights",
+				TimeStart:     &startTime,
+				AltitudeLower: &lower,
+				AltitudeUpper: &upper,
+			},
+		}},
+	}, resp.Response200)
+	body, err := json.Marshal(resp.Response200)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `"coordinates":[[[7.41,46.9],[7.41,46.91],[7.4,46.9],[7.41,46.9]]]`)
+
+	// Circles are approximated by closed polygons.
+	isa.Footprint = &dssmodels.GeoCircle{Center: dssmodels.LatLngPoint{Lat: 46.9, Lng: 7.4}, RadiusMeter: 300}
+	resp = server.SearchISAsGeoJSON(ctx, &restapi.SearchISAsGeoJSONRequest{Area: &area})
+	require.NotNil(t, resp.Response200)
+	ring := resp.Response200.Features[0].Geometry.Coordinates[0]
+	require.Len(t, ring, circleVertices+1)
+	require.Equal(t, ring[0], ring[circleVertices])
+	for _, position := range ring {
+		require.InDelta(t, 7.4, position[0], 0.005)
+		require.InDelta(t, 46.9, position[1], 0.003)
+	}
+
+	// ISAs written before footprints were stored are omitted.
+	isa.Footprint = nil
+	resp = server.SearchISAsGeoJSON(ctx, &restapi.SearchISAsGeoJSONRequest{Area: &area})
+	require.NotNil(t, resp.Response200)
+	require.Empty(t, resp.Response200.Features)
+
+	resp = server.SearchISAsGeoJSON(ctx, &restapi.SearchISAsGeoJSONRequest{})
+	require.NotNil(t, resp.Response400)
+	resp = server.SearchISAsGeoJSON(ctx, &restapi.SearchISAsGeoJSONRequest{Area: &large})
+	require.NotNil(t, resp.Response413)
+}
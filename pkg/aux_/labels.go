@@ -0,0 +1,215 @@
+package aux
+
+import (
+	"context"
+	"sort"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
+	"github.com/interuss/stacktrace"
+)
+
+func labelsFromRest(labels []restapi.Label) (ridmodels.Labels, error) {
+	result := make(ridmodels.Labels, len(labels))
+	for _, l := range labels {
+		if _, ok := result[l.Key]; ok {
+			return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Duplicate label `%s`", l.Key)
+		}
+		result[l.Key] = l.Value
+	}
+	return result, result.Validate()
+}
+
+func labelsToRest(labels ridmodels.Labels) []restapi.Label {
+	result := make([]restapi.Label, 0, len(labels))
+	for k, v := range labels {
+		result = append(result, restapi.Label{Key: k, Value: v})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+func isaToLabeledEntity(isa *ridmodels.IdentificationServiceArea) *restapi.LabeledEntity {
+	return &restapi.LabeledEntity{
+		Id:      isa.ID.String(),
+		Owner:   isa.Owner.String(),
+		Version: isa.Version.String(),
+		Labels:  labelsToRest(isa.Labels),
+	}
+}
+
+func subscriptionToLabeledEntity(sub *ridmodels.Subscription) *restapi.LabeledEntity {
+	return &restapi.LabeledEntity{
+		Id:      sub.ID.String(),
+		Owner:   sub.Owner.String(),
+		Version: sub.Version.String(),
+		Labels:  labelsToRest(sub.Labels),
+	}
+}
+
+// SetISALabels replaces the labels of an ISA owned by the client.
+func (a *Server) SetISALabels(ctx context.Context, req *restapi.SetISALabelsRequest) restapi.SetISALabelsResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.SetISALabelsResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	if req.Auth.ClientID == nil {
+		return restapi.SetISALabelsResponseSet{Response403: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Missing owner"))}}
+	}
+	if err := ridserver.CheckBody(req.Body, req.BodyParseError); err != nil {
+		return restapi.SetISALabelsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, err)}}
+	}
+	id, err := dssmodels.IDFromString(req.Id)
+	if err != nil {
+		return restapi.SetISALabelsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Invalid ID format"))}}
+	}
+	labels, err := labelsFromRest(req.Body.Labels)
+	if err != nil {
+		return restapi.SetISALabelsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.Propagate(err, "Invalid labels"))}}
+	}
+
+	isa, err := a.RIDApp.SetISALabels(ctx, id, dssmodels.Owner(*req.Auth.ClientID), labels)
+	if err != nil {
+		err = stacktrace.Propagate(err, "Could not set ISA labels")
+		errResp := &restapi.ErrorResponse{Message: dsserr.Handle(ctx, err)}
+		switch stacktrace.GetCode(err) {
+		case dsserr.BadRequest:
+			return restapi.SetISALabelsResponseSet{Response400: errResp}
+		case dsserr.PermissionDenied:
+			return restapi.SetISALabelsResponseSet{Response403: errResp}
+		case dsserr.NotFound:
+			return restapi.SetISALabelsResponseSet{Response404: errResp}
+		default:
+			return restapi.SetISALabelsResponseSet{Response500: &api.InternalServerErrorBody{
+				ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Got an unexpected error"))}}
+		}
+	}
+	return restapi.SetISALabelsResponseSet{Response200: isaToLabeledEntity(isa)}
+}
+
+// SetSubscriptionLabels replaces the labels of a subscription owned by the
+// client.
+func (a *Server) SetSubscriptionLabels(ctx context.Context, req *restapi.SetSubscriptionLabelsRequest) restapi.SetSubscriptionLabelsResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.SetSubscriptionLabelsResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	if req.Auth.ClientID == nil {
+		return restapi.SetSubscriptionLabelsResponseSet{Response403: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Missing owner"))}}
+	}
+	if err := ridserver.CheckBody(req.Body, req.BodyParseError); err != nil {
+		return restapi.SetSubscriptionLabelsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, err)}}
+	}
+	id, err := dssmodels.IDFromString(req.Id)
+	if err != nil {
+		return restapi.SetSubscriptionLabelsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Invalid ID format"))}}
+	}
+	labels, err := labelsFromRest(req.Body.Labels)
+	if err != nil {
+		return restapi.SetSubscriptionLabelsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.Propagate(err, "Invalid labels"))}}
+	}
+
+	sub, err := a.RIDApp.SetSubscriptionLabels(ctx, id, dssmodels.Owner(*req.Auth.ClientID), labels)
+	if err != nil {
+		err = stacktrace.Propagate(err, "Could not set Subscription labels")
+		errResp := &restapi.ErrorResponse{Message: dsserr.Handle(ctx, err)}
+		switch stacktrace.GetCode(err) {
+		case dsserr.BadRequest:
+			return restapi.SetSubscriptionLabelsResponseSet{Response400: errResp}
+		case dsserr.PermissionDenied:
+			return restapi.SetSubscriptionLabelsResponseSet{Response403: errResp}
+		case dsserr.NotFound:
+			return restapi.SetSubscriptionLabelsResponseSet{Response404: errResp}
+		default:
+			return restapi.SetSubscriptionLabelsResponseSet{Response500: &api.InternalServerErrorBody{
+				ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Got an unexpected error"))}}
+		}
+	}
+	return restapi.SetSubscriptionLabelsResponseSet{Response200: subscriptionToLabeledEntity(sub)}
+}
+
+// SearchISAsByLabels returns the active ISAs carrying all the requested
+// labels.
+func (a *Server) SearchISAsByLabels(ctx context.Context, req *restapi.SearchISAsByLabelsRequest) restapi.SearchISAsByLabelsResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.SearchISAsByLabelsResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	if req.Labels == nil {
+		return restapi.SearchISAsByLabelsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing labels"))}}
+	}
+	labels, err := ridmodels.LabelsFromString(*req.Labels)
+	if err != nil {
+		return restapi.SearchISAsByLabelsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.Propagate(err, "Invalid labels"))}}
+	}
+
+	isas, err := a.RIDApp.SearchISAsByLabels(ctx, labels)
+	if err != nil {
+		err = stacktrace.Propagate(err, "Unable to search ISAs")
+		if stacktrace.GetCode(err) == dsserr.BadRequest {
+			return restapi.SearchISAsByLabelsResponseSet{Response400: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, err)}}
+		}
+		return restapi.SearchISAsByLabelsResponseSet{Response500: &api.InternalServerErrorBody{
+			ErrorMessage: *dsserr.Handle(ctx, err)}}
+	}
+
+	resp := &restapi.SearchLabeledEntitiesResponse{Entities: make([]restapi.LabeledEntity, 0, len(isas))}
+	for _, isa := range isas {
+		resp.Entities = append(resp.Entities, *isaToLabeledEntity(isa))
+	}
+	return restapi.SearchISAsByLabelsResponseSet{Response200: resp}
+}
+
+// SearchSubscriptionsByLabels returns the active subscriptions carrying all
+// the requested labels.
+func (a *Server) SearchSubscriptionsByLabels(ctx context.Context, req *restapi.SearchSubscriptionsByLabelsRequest) restapi.SearchSubscriptionsByLabelsResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.SearchSubscriptionsByLabelsResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	if req.Labels == nil {
+		return restapi.SearchSubscriptionsByLabelsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing labels"))}}
+	}
+	labels, err := ridmodels.LabelsFromString(*req.Labels)
+	if err != nil {
+		return restapi.SearchSubscriptionsByLabelsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.Propagate(err, "Invalid labels"))}}
+	}
+
+	subs, err := a.RIDApp.SearchSubscriptionsByLabels(ctx, labels)
+	if err != nil {
+		err = stacktrace.Propagate(err, "Unable to search Subscriptions")
+		if stacktrace.GetCode(err) == dsserr.BadRequest {
+			return restapi.SearchSubscriptionsByLabelsResponseSet{Response400: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, err)}}
+		}
+		return restapi.SearchSubscriptionsByLabelsResponseSet{Response500: &api.InternalServerErrorBody{
+			ErrorMessage: *dsserr.Handle(ctx, err)}}
+	}
+
+	resp := &restapi.SearchLabeledEntitiesResponse{Entities: make([]restapi.LabeledEntity, 0, len(subs))}
+	for _, sub := range subs {
+		resp.Entities = append(resp.Entities, *subscriptionToLabeledEntity(sub))
+	}
+	return restapi.SearchSubscriptionsByLabelsResponseSet{Response200: resp}
+}
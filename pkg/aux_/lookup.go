@@ -0,0 +1,128 @@
+package aux
+
+import (
+	"context"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+)
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func isaToReference(isa *ridmodels.IdentificationServiceArea) restapi.ISAReference {
+	return restapi.ISAReference{
+		Id:         isa.ID.String(),
+		Owner:      isa.Owner.String(),
+		UssBaseUrl: isa.URL,
+		Version:    isa.Version.String(),
+		TimeStart:  formatTime(isa.StartTime),
+		TimeEnd:    formatTime(isa.EndTime),
+	}
+}
+
+func subscriptionToReference(sub *ridmodels.Subscription) restapi.SubscriptionReference {
+	ref := restapi.SubscriptionReference{
+		Id:                sub.ID.String(),
+		Owner:             sub.Owner.String(),
+		CallbackUrl:       sub.URL,
+		Version:           sub.Version.String(),
+		NotificationIndex: int32(sub.NotificationIndex),
+		TimeStart:         formatTime(sub.StartTime),
+		TimeEnd:           formatTime(sub.EndTime),
+	}
+	if len(sub.CallbackURLs) > 0 {
+		urls := append([]string(nil), sub.CallbackURLs...)
+		ref.CallbackUrls = &urls
+	}
+	if sub.MinNotificationInterval > 0 {
+		seconds := int32(sub.MinNotificationInterval / time.Second)
+		ref.MinNotificationIntervalSeconds = &seconds
+	}
+	if sub.NotifyOwnISAs {
+		notify := true
+		ref.NotifyOwnIsas = &notify
+	}
+	return ref
+}
+
+// SearchISAsByURL returns the active ISAs of all owners referencing the
+// requested flights URL or URL prefix.
+func (a *Server) SearchISAsByURL(ctx context.Context, req *restapi.SearchISAsByURLRequest) restapi.SearchISAsByURLResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.SearchISAsByURLResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	if req.Url == nil {
+		return restapi.SearchISAsByURLResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing url"))}}
+	}
+	prefix := false
+	if req.Match != nil {
+		switch *req.Match {
+		case "exact":
+		case "prefix":
+			prefix = true
+		default:
+			return restapi.SearchISAsByURLResponseSet{Response400: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Invalid match `%s`: expected exact or prefix", *req.Match))}}
+		}
+	}
+
+	isas, err := a.RIDApp.SearchISAsByURL(ctx, *req.Url, prefix)
+	if err != nil {
+		err = stacktrace.Propagate(err, "Unable to search ISAs")
+		if stacktrace.GetCode(err) == dsserr.BadRequest {
+			return restapi.SearchISAsByURLResponseSet{Response400: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, err)}}
+		}
+		return restapi.SearchISAsByURLResponseSet{Response500: &api.InternalServerErrorBody{
+			ErrorMessage: *dsserr.Handle(ctx, err)}}
+	}
+
+	resp := &restapi.SearchISAsByURLResponse{ServiceAreas: make([]restapi.ISAReference, 0, len(isas))}
+	for _, isa := range isas {
+		resp.ServiceAreas = append(resp.ServiceAreas, isaToReference(isa))
+	}
+	return restapi.SearchISAsByURLResponseSet{Response200: resp}
+}
+
+// SearchSubscriptionsByURL returns the active subscriptions of the client
+// whose callback URL contains the requested text.
+func (a *Server) SearchSubscriptionsByURL(ctx context.Context, req *restapi.SearchSubscriptionsByURLRequest) restapi.SearchSubscriptionsByURLResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.SearchSubscriptionsByURLResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	if req.Auth.ClientID == nil {
+		return restapi.SearchSubscriptionsByURLResponseSet{Response403: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Missing owner"))}}
+	}
+	substring := ""
+	if req.UrlContains != nil {
+		substring = *req.UrlContains
+	}
+
+	subs, err := a.RIDApp.SearchSubscriptionsByURL(ctx, dssmodels.Owner(*req.Auth.ClientID), substring)
+	if err != nil {
+		return restapi.SearchSubscriptionsByURLResponseSet{Response500: &api.InternalServerErrorBody{
+			ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Unable to search Subscriptions"))}}
+	}
+
+	resp := &restapi.SearchSubscriptionsByURLResponse{Subscriptions: make([]restapi.SubscriptionReference, 0, len(subs))}
+	for _, sub := range subs {
+		resp.Subscriptions = append(resp.Subscriptions, subscriptionToReference(sub))
+	}
+	return restapi.SearchSubscriptionsByURLResponseSet{Response200: resp}
+}
@@ -0,0 +1,72 @@
+package aux
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/dss/pkg/rid/application"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+)
+
+// lookupApp returns subs as the subscriptions of any owner, recording the
+// search.
+type lookupApp struct {
+	application.App
+	subs      []*ridmodels.Subscription
+	owner     dssmodels.Owner
+	substring string
+}
+
+func (a *lookupApp) SearchSubscriptionsByURL(ctx context.Context, owner dssmodels.Owner, substring string) ([]*ridmodels.Subscription, error) {
+	a.owner, a.substring = owner, substring
+	return a.subs, nil
+}
+
+func TestSearchSubscriptionsByURL(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		client = "uss1"
+		start  = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		end    = start.Add(time.Hour)
+		app    = &lookupApp{subs: []*ridmodels.Subscription{{
+			ID:                "4348c8e5-0b1c-43cf-9114-2e67a4532765",
+			Owner:             "uss1",
+			URL:               "https://old.uss1.example/notify",
+			Version:           dssmodels.VersionFromTime(start),
+			NotificationIndex: 3,
+			StartTime:         &start,
+			EndTime:           &end,
+		}}}
+		server   = &Server{RIDApp: app}
+		contains = "old.uss1"
+	)
+
+	resp := server.SearchSubscriptionsByURL(ctx, &restapi.SearchSubscriptionsByURLRequest{
+		UrlContains: &contains, Auth: api.AuthorizationResult{ClientID: &client}})
+	require.NotNil(t, resp.Response200)
+	require.Equal(t, []restapi.SubscriptionReference{{
+		Id:                "4348c8e5-0b1c-43cf-9114-2e67a4532765",
+		Owner:             "uss1",
+		CallbackUrl:       "https://old.uss1.example/notify",
+		Version:           dssmodels.VersionFromTime(start).String(),
+		NotificationIndex: 3,
+		TimeStart:         "2026-01-02T03:04:05Z",
+		TimeEnd:           "2026-01-02T04:04:05Z",
+	}}, resp.Response200.Subscriptions)
+	require.Equal(t, dssmodels.Owner("uss1"), app.owner)
+	require.Equal(t, "old.uss1", app.substring)
+
+	// Without a filter, all the subscriptions of the client are searched.
+	resp = server.SearchSubscriptionsByURL(ctx, &restapi.SearchSubscriptionsByURLRequest{
+		Auth: api.AuthorizationResult{ClientID: &client}})
+	require.NotNil(t, resp.Response200)
+	require.Equal(t, "", app.substring)
+
+	resp = server.SearchSubscriptionsByURL(ctx, &restapi.SearchSubscriptionsByURLRequest{})
+	require.NotNil(t, resp.Response403)
+}
@@ -0,0 +1,53 @@
+package aux
+
+import (
+	"embed"
+	"net/http"
+	"strings"
+)
+
+// mapUIPath is the path of the map UI.
+const mapUIPath = "/aux/v1/map/"
+
+//go:embed mapui
+var mapUIFiles embed.FS
+
+// MapUIRouter is an api.PartialRouter serving the static files of the map UI
+// under /aux/v1/map/. The files carry no data: the page gets the coverage of
+// the pool from GetRIDTile with an access token entered by the operator.
+type MapUIRouter struct{}
+
+// Handle implements api.PartialRouter.
+func (MapUIRouter) Handle(w http.ResponseWriter, r *http.Request) bool {
+	if r.URL.Path == strings.TrimSuffix(mapUIPath, "/") {
+		http.Redirect(w, r, mapUIPath, http.StatusMovedPermanently)
+		return true
+	}
+	name, ok := strings.CutPrefix(r.URL.Path, mapUIPath)
+	if !ok || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		return false
+	}
+	if name == "" {
+		name = "index.html"
+	}
+	content, err := mapUIFiles.ReadFile("mapui/" + name)
+	if err != nil {
+		return false
+	}
+
+	contentType := "text/html; charset=utf-8"
+	switch {
+	case strings.HasSuffix(name, ".js"):
+		contentType = "text/javascript; charset=utf-8"
+	case strings.HasSuffix(name, ".css"):
+		contentType = "text/css; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Security-Policy", "default-src 'self'")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(content)
+	}
+	return true
+}
@@ -0,0 +1,210 @@
+// Renders the remote ID coverage of the pool, as returned by
+// GET /aux/v1/rid/tiles/{z}/{x}/{y}, on a Web Mercator canvas without base
+// map. Tiles larger than the DSS searches are not requested.
+"use strict";
+
+const TILE_SIZE = 256;
+const MIN_ZOOM = 10;
+const MAX_ZOOM = 18;
+
+const canvas = document.getElementById("map");
+const context = canvas.getContext("2d");
+const tokenInput = document.getElementById("token");
+const gotoInput = document.getElementById("goto");
+const statusLine = document.getElementById("status");
+
+let zoom = 12;
+// Center of the view, in pixels of the world at zoom.
+let center = project(46.95, 7.45, zoom);
+// Tiles by "z/x/y": {data} once loaded, {error} if failed, {} while loading.
+let tiles = new Map();
+
+function project(lat, lng, z) {
+  const size = TILE_SIZE * 2 ** z;
+  const sin = Math.sin((lat * Math.PI) / 180);
+  return {
+    x: ((lng + 180) / 360) * size,
+    y: (0.5 - Math.log((1 + sin) / (1 - sin)) / (4 * Math.PI)) * size,
+  };
+}
+
+function unproject(x, y, z) {
+  const size = TILE_SIZE * 2 ** z;
+  const n = Math.PI - (2 * Math.PI * y) / size;
+  return {
+    lat: (180 / Math.PI) * Math.atan(Math.sinh(n)),
+    lng: (x / size) * 360 - 180,
+  };
+}
+
+// toScreen returns the position on the canvas of a GeoJSON position.
+function toScreen([lng, lat]) {
+  const p = project(lat, lng, zoom);
+  return [p.x - center.x + canvas.width / 2, p.y - center.y + canvas.height / 2];
+}
+
+function tracePolygon(polygon) {
+  context.beginPath();
+  for (const ring of polygon.coordinates) {
+    ring.forEach((position, i) => {
+      const [x, y] = toScreen(position);
+      i === 0 ? context.moveTo(x, y) : context.lineTo(x, y);
+    });
+    context.closePath();
+  }
+}
+
+function visibleTiles() {
+  const n = 2 ** zoom;
+  const left = center.x - canvas.width / 2;
+  const top = center.y - canvas.height / 2;
+  const result = [];
+  for (let x = Math.floor(left / TILE_SIZE); x * TILE_SIZE < left + canvas.width; x++) {
+    for (let y = Math.max(0, Math.floor(top / TILE_SIZE)); y * TILE_SIZE < top + canvas.height && y < n; y++) {
+      result.push({ z: zoom, x: ((x % n) + n) % n, y, left: x * TILE_SIZE - left, top: y * TILE_SIZE - top });
+    }
+  }
+  return result;
+}
+
+function load(tile) {
+  const key = `${tile.z}/${tile.x}/${tile.y}`;
+  if (tiles.has(key) || !tokenInput.value) {
+    return tiles.get(key);
+  }
+  const entry = {};
+  tiles.set(key, entry);
+  fetch(`../rid/tiles/${key}`, { headers: { Authorization: `Bearer ${tokenInput.value}` } })
+    .then(async (response) => {
+      const body = await response.json();
+      if (!response.ok) {
+        throw new Error(body.message || response.statusText);
+      }
+      entry.data = body;
+    })
+    .catch((error) => {
+      entry.error = error.message;
+    })
+    .finally(draw);
+  return entry;
+}
+
+function draw() {
+  canvas.width = canvas.clientWidth;
+  canvas.height = canvas.clientHeight;
+  context.clearRect(0, 0, canvas.width, canvas.height);
+  if (zoom < MIN_ZOOM) {
+    statusLine.textContent = "Zoom in to see the coverage.";
+    return;
+  }
+
+  const drawn = new Set();
+  let loading = 0;
+  let error = "";
+  for (const tile of visibleTiles()) {
+    const entry = load(tile);
+    context.strokeStyle = "#ddd";
+    context.strokeRect(tile.left, tile.top, TILE_SIZE, TILE_SIZE);
+    if (!entry || entry.error || !entry.data) {
+      loading += entry && !entry.error ? 1 : 0;
+      error = (entry && entry.error) || error;
+      continue;
+    }
+
+    // Cells are counted per tile, so they are clipped to their tile.
+    context.save();
+    context.beginPath();
+    context.rect(tile.left, tile.top, TILE_SIZE, TILE_SIZE);
+    context.clip();
+    for (const cell of entry.data.cells) {
+      const count = cell.isa_count || cell.subscription_count;
+      const alpha = Math.min(0.15 + 0.1 * count, 0.7);
+      context.fillStyle = cell.isa_count > 0 ? `rgba(204, 0, 0, ${alpha})` : `rgba(0, 80, 204, ${alpha})`;
+      tracePolygon(cell.outline);
+      context.fill();
+    }
+    context.restore();
+
+    context.strokeStyle = "#c00";
+    context.lineWidth = 2;
+    for (const feature of entry.data.isas.features) {
+      if (!drawn.has(feature.id)) {
+        drawn.add(feature.id);
+        tracePolygon(feature.geometry);
+        context.stroke();
+      }
+    }
+    context.lineWidth = 1;
+  }
+
+  const c = unproject(center.x, center.y, zoom);
+  if (!tokenInput.value) {
+    statusLine.textContent = "Enter an access token with the dss.admin scope.";
+  } else if (error) {
+    statusLine.textContent = `Error: ${error}`;
+  } else {
+    statusLine.textContent = `${c.lat.toFixed(5)},${c.lng.toFixed(5)} zoom ${zoom}, ${drawn.size} ISAs` +
+      (loading ? `, loading ${loading} tiles` : "");
+  }
+}
+
+// describeCell shows the counts of the cells at a position of the canvas.
+function describeCell(x, y) {
+  for (const tile of visibleTiles()) {
+    const entry = tiles.get(`${tile.z}/${tile.x}/${tile.y}`);
+    if (!entry || !entry.data || x < tile.left || x >= tile.left + TILE_SIZE || y < tile.top || y >= tile.top + TILE_SIZE) {
+      continue;
+    }
+    for (const cell of entry.data.cells) {
+      tracePolygon(cell.outline);
+      if (context.isPointInPath(x, y)) {
+        statusLine.textContent = `Cell ${cell.cell} (level ${cell.level}): ${cell.isa_count} ISAs, ` +
+          `${cell.subscription_count} subscriptions in this tile`;
+        return;
+      }
+    }
+  }
+}
+
+function setZoom(z, anchorX, anchorY) {
+  z = Math.min(Math.max(z, 0), MAX_ZOOM);
+  const scale = 2 ** (z - zoom);
+  const dx = anchorX - canvas.width / 2;
+  const dy = anchorY - canvas.height / 2;
+  center = { x: (center.x + dx) * scale - dx, y: (center.y + dy) * scale - dy };
+  zoom = z;
+  draw();
+}
+
+let drag = null;
+canvas.addEventListener("mousedown", (e) => {
+  drag = { x: e.offsetX, y: e.offsetY, moved: false };
+});
+canvas.addEventListener("mousemove", (e) => {
+  if (drag) {
+    center = { x: center.x - (e.offsetX - drag.x), y: center.y - (e.offsetY - drag.y) };
+    drag = { x: e.offsetX, y: e.offsetY, moved: true };
+    draw();
+  }
+});
+canvas.addEventListener("mouseup", (e) => {
+  if (drag && !drag.moved) {
+    describeCell(e.offsetX, e.offsetY);
+  }
+  drag = null;
+});
+canvas.addEventListener("wheel", (e) => {
+  e.preventDefault();
+  setZoom(zoom + (e.deltaY < 0 ? 1 : -1), e.offsetX, e.offsetY);
+});
+document.getElementById("controls").addEventListener("submit", (e) => {
+  e.preventDefault();
+  const [lat, lng] = gotoInput.value.split(",").map(Number);
+  if (!isNaN(lat) && !isNaN(lng) && gotoInput.value) {
+    center = project(lat, lng, zoom);
+  }
+  tiles = new Map();
+  draw();
+});
+window.addEventListener("resize", draw);
+draw();
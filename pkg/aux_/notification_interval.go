@@ -0,0 +1,56 @@
+package aux
+
+import (
+	"context"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
+	"github.com/interuss/stacktrace"
+)
+
+// SetSubscriptionNotificationInterval replaces the minimum interval between
+// the notifications of a subscription owned by the client.
+func (a *Server) SetSubscriptionNotificationInterval(ctx context.Context, req *restapi.SetSubscriptionNotificationIntervalRequest) restapi.SetSubscriptionNotificationIntervalResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.SetSubscriptionNotificationIntervalResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	if req.Auth.ClientID == nil {
+		return restapi.SetSubscriptionNotificationIntervalResponseSet{Response403: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Missing owner"))}}
+	}
+	if err := ridserver.CheckBody(req.Body, req.BodyParseError); err != nil {
+		return restapi.SetSubscriptionNotificationIntervalResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, err)}}
+	}
+	id, err := dssmodels.IDFromString(req.Id)
+	if err != nil {
+		return restapi.SetSubscriptionNotificationIntervalResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Invalid ID format"))}}
+	}
+
+	interval := time.Duration(req.Body.MinNotificationIntervalSeconds) * time.Second
+	sub, err := a.RIDApp.SetSubscriptionNotificationInterval(ctx, id, dssmodels.Owner(*req.Auth.ClientID), interval)
+	if err != nil {
+		err = stacktrace.Propagate(err, "Could not set Subscription notification interval")
+		errResp := &restapi.ErrorResponse{Message: dsserr.Handle(ctx, err)}
+		switch stacktrace.GetCode(err) {
+		case dsserr.BadRequest:
+			return restapi.SetSubscriptionNotificationIntervalResponseSet{Response400: errResp}
+		case dsserr.PermissionDenied:
+			return restapi.SetSubscriptionNotificationIntervalResponseSet{Response403: errResp}
+		case dsserr.NotFound:
+			return restapi.SetSubscriptionNotificationIntervalResponseSet{Response404: errResp}
+		default:
+			return restapi.SetSubscriptionNotificationIntervalResponseSet{Response500: &api.InternalServerErrorBody{
+				ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Got an unexpected error"))}}
+		}
+	}
+	ref := subscriptionToReference(sub)
+	return restapi.SetSubscriptionNotificationIntervalResponseSet{Response200: &ref}
+}
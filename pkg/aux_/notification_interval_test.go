@@ -0,0 +1,70 @@
+package aux
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/dss/pkg/rid/application"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+// notificationIntervalApp keeps the minimum notification interval of a
+// subscription owned by uss1.
+type notificationIntervalApp struct {
+	application.App
+	sub *ridmodels.Subscription
+}
+
+func (a *notificationIntervalApp) SetSubscriptionNotificationInterval(_ context.Context, id dssmodels.ID, owner dssmodels.Owner, interval time.Duration) (*ridmodels.Subscription, error) {
+	switch {
+	case id != a.sub.ID:
+		return nil, stacktrace.NewErrorWithCode(dsserr.NotFound, "Subscription %s not found", id)
+	case owner != a.sub.Owner:
+		return nil, stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Subscription owned by %s", a.sub.Owner)
+	}
+	if err := ridmodels.ValidateNotificationInterval(interval); err != nil {
+		return nil, err
+	}
+	a.sub.MinNotificationInterval = interval
+	return a.sub, nil
+}
+
+func TestSetSubscriptionNotificationInterval(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		client = "uss1"
+		other  = "uss2"
+		id     = "4348c8e5-0b1c-43cf-9114-2e67a4532765"
+		app    = &notificationIntervalApp{sub: &ridmodels.Subscription{
+			ID:    dssmodels.ID(id),
+			Owner: "uss1",
+			URL:   "https://uss1.example/isa",
+		}}
+		server = &Server{RIDApp: app}
+		set    = func(client *string, seconds int32) restapi.SetSubscriptionNotificationIntervalResponseSet {
+			return server.SetSubscriptionNotificationInterval(ctx, &restapi.SetSubscriptionNotificationIntervalRequest{
+				Id: id, Body: &restapi.SetSubscriptionNotificationIntervalParameters{MinNotificationIntervalSeconds: seconds},
+				Auth: api.AuthorizationResult{ClientID: client}})
+		}
+	)
+
+	resp := set(&client, 30)
+	require.NotNil(t, resp.Response200)
+	require.Equal(t, int32(30), *resp.Response200.MinNotificationIntervalSeconds)
+
+	// No minimum interval is omitted.
+	resp = set(&client, 0)
+	require.NotNil(t, resp.Response200)
+	require.Nil(t, resp.Response200.MinNotificationIntervalSeconds)
+
+	require.NotNil(t, set(&client, -1).Response400)
+	require.NotNil(t, set(&other, 30).Response403)
+	require.NotNil(t, set(nil, 30).Response403)
+}
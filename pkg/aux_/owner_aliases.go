@@ -0,0 +1,155 @@
+package aux
+
+import (
+	"context"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/logging"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/dss/pkg/rid/application"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
+	"github.com/interuss/stacktrace"
+	"go.uber.org/zap"
+)
+
+// OwnerAliasLoader returns a function loading the owner aliases of app, as
+// mapped by auth.OwnerAliases.
+func OwnerAliasLoader(app application.OwnerAliasApp) func(context.Context) (map[string]string, error) {
+	return func(ctx context.Context) (map[string]string, error) {
+		aliases, err := app.ListOwnerAliases(ctx)
+		if err != nil {
+			return nil, err // No need to Propagate this error as this stack layer does not add useful information
+		}
+		owners := make(map[string]string, len(aliases))
+		for _, alias := range aliases {
+			owners[alias.Subject.String()] = alias.Owner.String()
+		}
+		return owners, nil
+	}
+}
+
+func ownerAliasToRest(alias *ridmodels.OwnerAlias) *restapi.OwnerAlias {
+	result := &restapi.OwnerAlias{
+		Subject: alias.Subject.String(),
+		Owner:   alias.Owner.String(),
+	}
+	if alias.UpdatedAt != nil {
+		updatedAt := formatTime(alias.UpdatedAt)
+		result.UpdatedAt = &updatedAt
+	}
+	return result
+}
+
+// reloadOwnerAliases applies the changes of owner aliases to the requests
+// authorized by this instance without waiting for the next refresh.
+func (a *Server) reloadOwnerAliases(ctx context.Context, logger *zap.Logger) {
+	if a.OwnerAliases == nil {
+		return
+	}
+	if err := a.OwnerAliases.Load(ctx, OwnerAliasLoader(a.RIDApp)); err != nil {
+		logger.Warn("Failed to reload owner aliases", zap.Error(err))
+	}
+}
+
+// ListOwnerAliases returns all the owner aliases.
+func (a *Server) ListOwnerAliases(ctx context.Context, req *restapi.ListOwnerAliasesRequest) restapi.ListOwnerAliasesResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.ListOwnerAliasesResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+
+	aliases, err := a.RIDApp.ListOwnerAliases(ctx)
+	if err != nil {
+		return restapi.ListOwnerAliasesResponseSet{Response500: &api.InternalServerErrorBody{
+			ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Could not list owner aliases"))}}
+	}
+	resp := &restapi.OwnerAliasesResponse{Aliases: make([]restapi.OwnerAlias, 0, len(aliases))}
+	for _, alias := range aliases {
+		resp.Aliases = append(resp.Aliases, *ownerAliasToRest(alias))
+	}
+	return restapi.ListOwnerAliasesResponseSet{Response200: resp}
+}
+
+// SetOwnerAlias makes the access tokens of a subject act as a canonical owner.
+// Every change is logged for audit.
+func (a *Server) SetOwnerAlias(ctx context.Context, req *restapi.SetOwnerAliasRequest) restapi.SetOwnerAliasResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.SetOwnerAliasResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+
+	if err := ridserver.CheckBody(req.Body, req.BodyParseError); err != nil {
+		return restapi.SetOwnerAliasResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, err)}}
+	}
+	if req.Body.Reason == "" {
+		return restapi.SetOwnerAliasResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing reason"))}}
+	}
+
+	logger := logging.WithValuesFromContext(ctx, logging.Logger).With(
+		zap.String("subject", req.Body.Subject),
+		zap.String("canonical_owner", req.Body.Owner),
+		zap.String("reason", req.Body.Reason),
+	)
+	if req.Auth.ClientID != nil {
+		logger = logger.With(zap.String("actor", *req.Auth.ClientID))
+	}
+
+	alias, err := a.RIDApp.SetOwnerAlias(ctx, dssmodels.Owner(req.Body.Subject), dssmodels.Owner(req.Body.Owner))
+	if err != nil {
+		logger.Warn("audit: owner alias rejected", zap.Error(err))
+		err = stacktrace.Propagate(err, "Could not set owner alias")
+		switch stacktrace.GetCode(err) {
+		case dsserr.BadRequest:
+			return restapi.SetOwnerAliasResponseSet{Response400: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, err)}}
+		case dsserr.NotFound:
+			return restapi.SetOwnerAliasResponseSet{Response404: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, err)}}
+		}
+		return restapi.SetOwnerAliasResponseSet{Response500: &api.InternalServerErrorBody{
+			ErrorMessage: *dsserr.Handle(ctx, err)}}
+	}
+	logger.Info("audit: owner alias set")
+	a.reloadOwnerAliases(ctx, logger)
+	return restapi.SetOwnerAliasResponseSet{Response200: ownerAliasToRest(alias)}
+}
+
+// DeleteOwnerAlias deletes the alias of a subject. Every deletion is logged
+// for audit.
+func (a *Server) DeleteOwnerAlias(ctx context.Context, req *restapi.DeleteOwnerAliasRequest) restapi.DeleteOwnerAliasResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.DeleteOwnerAliasResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	if req.Subject == nil || *req.Subject == "" {
+		return restapi.DeleteOwnerAliasResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing subject"))}}
+	}
+
+	logger := logging.WithValuesFromContext(ctx, logging.Logger).With(zap.String("subject", *req.Subject))
+	if req.Auth.ClientID != nil {
+		logger = logger.With(zap.String("actor", *req.Auth.ClientID))
+	}
+
+	alias, err := a.RIDApp.DeleteOwnerAlias(ctx, dssmodels.Owner(*req.Subject))
+	if err != nil {
+		err = stacktrace.Propagate(err, "Could not delete owner alias")
+		if stacktrace.GetCode(err) == dsserr.NotFound {
+			return restapi.DeleteOwnerAliasResponseSet{Response404: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, err)}}
+		}
+		return restapi.DeleteOwnerAliasResponseSet{Response500: &api.InternalServerErrorBody{
+			ErrorMessage: *dsserr.Handle(ctx, err)}}
+	}
+	logger.Info("audit: owner alias deleted", zap.String("canonical_owner", alias.Owner.String()))
+	a.reloadOwnerAliases(ctx, logger)
+	return restapi.DeleteOwnerAliasResponseSet{Response200: ownerAliasToRest(alias)}
+}
@@ -0,0 +1,81 @@
+package aux
+
+import (
+	"context"
+	"testing"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	"github.com/interuss/dss/pkg/auth"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/dss/pkg/rid/application"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+// ownerAliasApp keeps aliases in memory.
+type ownerAliasApp struct {
+	application.App
+	aliases map[dssmodels.Owner]dssmodels.Owner
+}
+
+func (a *ownerAliasApp) ListOwnerAliases(context.Context) ([]*ridmodels.OwnerAlias, error) {
+	var aliases []*ridmodels.OwnerAlias
+	for subject, owner := range a.aliases {
+		aliases = append(aliases, &ridmodels.OwnerAlias{Subject: subject, Owner: owner})
+	}
+	return aliases, nil
+}
+
+func (a *ownerAliasApp) SetOwnerAlias(_ context.Context, subject, owner dssmodels.Owner) (*ridmodels.OwnerAlias, error) {
+	if subject == owner {
+		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Subject may not be its own alias")
+	}
+	a.aliases[subject] = owner
+	return &ridmodels.OwnerAlias{Subject: subject, Owner: owner}, nil
+}
+
+func (a *ownerAliasApp) DeleteOwnerAlias(_ context.Context, subject dssmodels.Owner) (*ridmodels.OwnerAlias, error) {
+	owner, ok := a.aliases[subject]
+	if !ok {
+		return nil, stacktrace.NewErrorWithCode(dsserr.NotFound, "Subject has no alias")
+	}
+	delete(a.aliases, subject)
+	return &ridmodels.OwnerAlias{Subject: subject, Owner: owner}, nil
+}
+
+func TestOwnerAliases(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		actor   = "admin"
+		authRes = api.AuthorizationResult{ClientID: &actor}
+		aliases = &auth.OwnerAliases{}
+		server  = &Server{RIDApp: &ownerAliasApp{aliases: map[dssmodels.Owner]dssmodels.Owner{}}, OwnerAliases: aliases}
+		set     = func(subject, owner, reason string) restapi.SetOwnerAliasResponseSet {
+			return server.SetOwnerAlias(ctx, &restapi.SetOwnerAliasRequest{
+				Body: &restapi.SetOwnerAliasParameters{Subject: subject, Owner: owner, Reason: reason}, Auth: authRes})
+		}
+	)
+
+	resp := set("new-idp-subject", "uss1", "identity provider migration")
+	require.NotNil(t, resp.Response200)
+	require.Equal(t, "uss1", resp.Response200.Owner)
+	// The aliases of this instance apply the change immediately.
+	require.Equal(t, "uss1", aliases.CanonicalOwner("new-idp-subject"))
+
+	require.NotNil(t, set("uss1", "uss1", "loop").Response400)
+	require.NotNil(t, set("other-subject", "uss1", "").Response400)
+
+	list := server.ListOwnerAliases(ctx, &restapi.ListOwnerAliasesRequest{Auth: authRes})
+	require.NotNil(t, list.Response200)
+	require.Equal(t, []restapi.OwnerAlias{{Subject: "new-idp-subject", Owner: "uss1"}}, list.Response200.Aliases)
+
+	subject := "new-idp-subject"
+	deleted := server.DeleteOwnerAlias(ctx, &restapi.DeleteOwnerAliasRequest{Subject: &subject, Auth: authRes})
+	require.NotNil(t, deleted.Response200)
+	require.Equal(t, "new-idp-subject", aliases.CanonicalOwner("new-idp-subject"))
+	require.NotNil(t, server.DeleteOwnerAlias(ctx, &restapi.DeleteOwnerAliasRequest{Subject: &subject, Auth: authRes}).Response404)
+	require.NotNil(t, server.DeleteOwnerAlias(ctx, &restapi.DeleteOwnerAliasRequest{Auth: authRes}).Response400)
+}
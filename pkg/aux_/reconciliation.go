@@ -0,0 +1,127 @@
+package aux
+
+import (
+	"context"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/logging"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/dss/pkg/rid/application"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
+	"github.com/interuss/stacktrace"
+	"go.uber.org/zap"
+)
+
+// ReconcileISAs compares the ISAs claimed by a USS against the DSS index and
+// optionally removes the ISAs the USS does not claim.
+func (a *Server) ReconcileISAs(ctx context.Context, req *restapi.ReconcileISAsRequest) restapi.ReconcileISAsResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.ReconcileISAsResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+
+	if err := ridserver.CheckBody(req.Body, req.BodyParseError); err != nil {
+		return restapi.ReconcileISAsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, err)}}
+	}
+	if req.Body.Owner == "" {
+		return restapi.ReconcileISAsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing owner"))}}
+	}
+	claimed := make([]application.ClaimedISA, 0, len(req.Body.Isas))
+	for _, isa := range req.Body.Isas {
+		id, err := dssmodels.IDFromString(isa.Id)
+		if err != nil {
+			return restapi.ReconcileISAsResponseSet{Response400: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Invalid ID format: `%s`", isa.Id))}}
+		}
+		c := application.ClaimedISA{ID: id}
+		if isa.Version != nil {
+			c.Version, err = dssmodels.VersionFromString(*isa.Version)
+			if err != nil {
+				return restapi.ReconcileISAsResponseSet{Response400: &restapi.ErrorResponse{
+					Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Invalid version of ISA %s", id))}}
+			}
+		}
+		claimed = append(claimed, c)
+	}
+	repair := req.Body.Repair != nil && *req.Body.Repair
+
+	result, err := a.RIDApp.ReconcileISAs(ctx, dssmodels.Owner(req.Body.Owner), claimed, repair)
+	if err != nil {
+		return restapi.ReconcileISAsResponseSet{Response500: &api.InternalServerErrorBody{
+			ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Could not reconcile ISAs"))}}
+	}
+
+	resp := &restapi.ReconcileISAsResponse{
+		Missing:           make([]string, 0, len(result.Missing)),
+		Unclaimed:         make([]string, 0, len(result.Unclaimed)),
+		VersionMismatches: make([]restapi.ISAVersionMismatch, 0, len(result.VersionMismatches)),
+		Removed:           make([]string, 0, len(result.Removed)),
+	}
+	for _, id := range result.Missing {
+		resp.Missing = append(resp.Missing, id.String())
+	}
+	for _, isa := range result.Unclaimed {
+		resp.Unclaimed = append(resp.Unclaimed, isa.ID.String())
+	}
+	for _, m := range result.VersionMismatches {
+		resp.VersionMismatches = append(resp.VersionMismatches, restapi.ISAVersionMismatch{
+			Id:             m.ISA.ID.String(),
+			DssVersion:     m.ISA.Version.String(),
+			ClaimedVersion: m.ClaimedVersion.String(),
+		})
+	}
+	for _, isa := range result.Removed {
+		resp.Removed = append(resp.Removed, isa.ID.String())
+	}
+	return restapi.ReconcileISAsResponseSet{Response200: resp}
+}
+
+// ExpireISAs ends all the active ISAs of a USS now, e.g. to cut off a USS
+// publishing bogus airspace data. Every expiration is logged for audit.
+func (a *Server) ExpireISAs(ctx context.Context, req *restapi.ExpireISAsRequest) restapi.ExpireISAsResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.ExpireISAsResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+
+	if err := ridserver.CheckBody(req.Body, req.BodyParseError); err != nil {
+		return restapi.ExpireISAsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, err)}}
+	}
+	if req.Body.Owner == "" {
+		return restapi.ExpireISAsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing owner"))}}
+	}
+	if req.Body.Reason == "" {
+		return restapi.ExpireISAsResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing reason"))}}
+	}
+
+	logger := logging.WithValuesFromContext(ctx, logging.Logger).With(
+		zap.String("expired_owner", req.Body.Owner),
+		zap.String("reason", req.Body.Reason),
+	)
+	if req.Auth.ClientID != nil {
+		logger = logger.With(zap.String("actor", *req.Auth.ClientID))
+	}
+
+	expired, err := a.RIDApp.ExpireISAsByOwner(ctx, dssmodels.Owner(req.Body.Owner))
+	if err != nil {
+		logger.Warn("audit: ISA expiration failed", zap.Error(err))
+		return restapi.ExpireISAsResponseSet{Response500: &api.InternalServerErrorBody{
+			ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Could not expire ISAs"))}}
+	}
+
+	resp := &restapi.ExpireISAsResponse{Expired: make([]string, 0, len(expired))}
+	for _, isa := range expired {
+		resp.Expired = append(resp.Expired, isa.ID.String())
+	}
+	logger.Info("audit: ISAs expired", zap.Strings("isas", resp.Expired))
+	return restapi.ExpireISAsResponseSet{Response200: resp}
+}
@@ -0,0 +1,54 @@
+package aux
+
+import (
+	"context"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
+	"github.com/interuss/stacktrace"
+)
+
+// SetSubscriptionSelfNotification sets whether a subscription owned by the
+// client is notified of the changes to the ISAs of the client.
+func (a *Server) SetSubscriptionSelfNotification(ctx context.Context, req *restapi.SetSubscriptionSelfNotificationRequest) restapi.SetSubscriptionSelfNotificationResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.SetSubscriptionSelfNotificationResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	if req.Auth.ClientID == nil {
+		return restapi.SetSubscriptionSelfNotificationResponseSet{Response403: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Missing owner"))}}
+	}
+	if err := ridserver.CheckBody(req.Body, req.BodyParseError); err != nil {
+		return restapi.SetSubscriptionSelfNotificationResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, err)}}
+	}
+	id, err := dssmodels.IDFromString(req.Id)
+	if err != nil {
+		return restapi.SetSubscriptionSelfNotificationResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Invalid ID format"))}}
+	}
+
+	sub, err := a.RIDApp.SetSubscriptionSelfNotification(ctx, id, dssmodels.Owner(*req.Auth.ClientID), req.Body.NotifyOwnIsas)
+	if err != nil {
+		err = stacktrace.Propagate(err, "Could not set Subscription self-notification")
+		errResp := &restapi.ErrorResponse{Message: dsserr.Handle(ctx, err)}
+		switch stacktrace.GetCode(err) {
+		case dsserr.BadRequest:
+			return restapi.SetSubscriptionSelfNotificationResponseSet{Response400: errResp}
+		case dsserr.PermissionDenied:
+			return restapi.SetSubscriptionSelfNotificationResponseSet{Response403: errResp}
+		case dsserr.NotFound:
+			return restapi.SetSubscriptionSelfNotificationResponseSet{Response404: errResp}
+		default:
+			return restapi.SetSubscriptionSelfNotificationResponseSet{Response500: &api.InternalServerErrorBody{
+				ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Got an unexpected error"))}}
+		}
+	}
+	ref := subscriptionToReference(sub)
+	return restapi.SetSubscriptionSelfNotificationResponseSet{Response200: &ref}
+}
@@ -0,0 +1,67 @@
+package aux
+
+import (
+	"context"
+	"testing"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/dss/pkg/rid/application"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+// selfNotificationApp keeps whether a subscription owned by uss1 is notified
+// of the ISAs of uss1.
+type selfNotificationApp struct {
+	application.App
+	sub *ridmodels.Subscription
+}
+
+func (a *selfNotificationApp) SetSubscriptionSelfNotification(_ context.Context, id dssmodels.ID, owner dssmodels.Owner, notify bool) (*ridmodels.Subscription, error) {
+	switch {
+	case id != a.sub.ID:
+		return nil, stacktrace.NewErrorWithCode(dsserr.NotFound, "Subscription %s not found", id)
+	case owner != a.sub.Owner:
+		return nil, stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Subscription owned by %s", a.sub.Owner)
+	}
+	a.sub.NotifyOwnISAs = notify
+	return a.sub, nil
+}
+
+func TestSetSubscriptionSelfNotification(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		client = "uss1"
+		other  = "uss2"
+		id     = "4348c8e5-0b1c-43cf-9114-2e67a4532765"
+		app    = &selfNotificationApp{sub: &ridmodels.Subscription{
+			ID:    dssmodels.ID(id),
+			Owner: "uss1",
+			URL:   "https://uss1.example/isa",
+		}}
+		server = &Server{RIDApp: app}
+		set    = func(client *string, id string, notify bool) restapi.SetSubscriptionSelfNotificationResponseSet {
+			return server.SetSubscriptionSelfNotification(ctx, &restapi.SetSubscriptionSelfNotificationRequest{
+				Id: id, Body: &restapi.SetSubscriptionSelfNotificationParameters{NotifyOwnIsas: notify},
+				Auth: api.AuthorizationResult{ClientID: client}})
+		}
+	)
+
+	resp := set(&client, id, true)
+	require.NotNil(t, resp.Response200)
+	require.True(t, *resp.Response200.NotifyOwnIsas)
+
+	// Not notifying the owner, the default, is omitted.
+	resp = set(&client, id, false)
+	require.NotNil(t, resp.Response200)
+	require.Nil(t, resp.Response200.NotifyOwnIsas)
+
+	require.NotNil(t, set(&client, "not-a-uuid", true).Response400)
+	require.NotNil(t, set(&client, "a3cde7e1-bc1c-4a95-bc94-0e2ba0e0dbbb", true).Response404)
+	require.NotNil(t, set(&other, id, true).Response403)
+	require.NotNil(t, set(nil, id, true).Response403)
+}
@@ -5,13 +5,43 @@ import (
 
 	"github.com/interuss/dss/pkg/api"
 	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	"github.com/interuss/dss/pkg/auth"
 	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/rid/application"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
 	"github.com/interuss/dss/pkg/version"
 	"github.com/interuss/stacktrace"
 )
 
 // Server implements auxv1.Implementation.
-type Server struct{}
+type Server struct {
+	// RIDApp is the remote ID application reconciled by ReconcileISAs.
+	RIDApp application.App
+	// Capabilities holds the configuration-dependent capabilities reported by
+	// GetCapabilities, none if nil.
+	Capabilities *SwappableCapabilities
+	// OwnerAliases, if not nil, are reloaded whenever an alias is changed.
+	OwnerAliases *auth.OwnerAliases
+	// MapUI enables GetRIDTile, which serves the map UI.
+	MapUI bool
+	// URLPolicy validates the callback URLs set by SetSubscriptionCallbacks.
+	URLPolicy ridmodels.URLPolicy
+	// Rejections, if not nil, counts the rejected requests reported by
+	// GetMyUsage.
+	Rejections *ridserver.RejectionCounter
+}
+
+func setAuthError(ctx context.Context, authErr error, resp401, resp403 **restapi.ErrorResponse, resp500 **api.InternalServerErrorBody) {
+	switch stacktrace.GetCode(authErr) {
+	case dsserr.Unauthenticated:
+		*resp401 = &restapi.ErrorResponse{Message: dsserr.Handle(ctx, stacktrace.Propagate(authErr, "Authentication failed"))}
+	case dsserr.PermissionDenied:
+		*resp403 = &restapi.ErrorResponse{Message: dsserr.Handle(ctx, stacktrace.Propagate(authErr, "Authorization failed"))}
+	default:
+		*resp500 = &api.InternalServerErrorBody{ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(authErr, "Could not perform authorization"))}
+	}
+}
 
 // GetVersion returns information about the version of the server.
 func (a *Server) GetVersion(context.Context, *restapi.GetVersionRequest) restapi.GetVersionResponseSet {
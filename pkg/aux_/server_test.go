@@ -0,0 +1,46 @@
+package aux
+
+import (
+	"context"
+	"testing"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlersRejectMissingBody(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		owner = "foo"
+		a     = &Server{}
+		auth  = api.AuthorizationResult{ClientID: &owner}
+		id    = "4348c8e5-0b1c-43cf-9114-2e67a4532765"
+	)
+	// The Server has no RIDApp: handlers must reject the requests before
+	// using it.
+	for name, handle := range map[string]func() *restapi.ErrorResponse{
+		"ReconcileISAs": func() *restapi.ErrorResponse {
+			return a.ReconcileISAs(ctx, &restapi.ReconcileISAsRequest{Auth: auth}).Response400
+		},
+		"ExpireISAs": func() *restapi.ErrorResponse {
+			return a.ExpireISAs(ctx, &restapi.ExpireISAsRequest{Auth: auth}).Response400
+		},
+		"TransferOwnership": func() *restapi.ErrorResponse {
+			return a.TransferOwnership(ctx, &restapi.TransferOwnershipRequest{Auth: auth}).Response400
+		},
+		"SetISALabels": func() *restapi.ErrorResponse {
+			return a.SetISALabels(ctx, &restapi.SetISALabelsRequest{Id: id, Auth: auth}).Response400
+		},
+		"SetSubscriptionLabels": func() *restapi.ErrorResponse {
+			return a.SetSubscriptionLabels(ctx, &restapi.SetSubscriptionLabelsRequest{Id: id, Auth: auth}).Response400
+		},
+		"SetSubscriptionCallbacks": func() *restapi.ErrorResponse {
+			return a.SetSubscriptionCallbacks(ctx, &restapi.SetSubscriptionCallbacksRequest{Id: id, Auth: auth}).Response400
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.NotNil(t, handle())
+		})
+	}
+}
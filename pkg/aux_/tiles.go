@@ -0,0 +1,124 @@
+package aux
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/golang/geo/s2"
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/geo"
+	"github.com/interuss/stacktrace"
+)
+
+// maxTileZoom is the highest zoom level of the tiles of GetRIDTile.
+const maxTileZoom = 22
+
+// GetRIDTile returns the ISAs of a Web Mercator tile and the numbers of ISAs
+// and subscriptions in its cells, for the map UI to render the coverage of
+// the pool. The cells are about a quarter of the tile wide, but never finer
+// than the cells the entities are stored with.
+func (a *Server) GetRIDTile(ctx context.Context, req *restapi.GetRIDTileRequest) restapi.GetRIDTileResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.GetRIDTileResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	if !a.MapUI {
+		return restapi.GetRIDTileResponseSet{Response404: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.NotFound, "The map UI is not enabled"))}}
+	}
+
+	z, x, y, err := parseTile(req.Z, req.X, req.Y)
+	if err != nil {
+		return restapi.GetRIDTileResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Invalid tile"))}}
+	}
+	cells, err := geo.Covering(tileOutline(z, x, y))
+	if err != nil {
+		if errors.Is(err, geo.ErrAreaTooLarge) {
+			return restapi.GetRIDTileResponseSet{Response413: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, stacktrace.Propagate(err, "Tile too large; zoom in"))}}
+		}
+		return restapi.GetRIDTileResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Invalid tile"))}}
+	}
+
+	coverage, err := a.RIDApp.GetCoverage(ctx, cells, min(z+2, geo.RegionCoverer.MaxLevel))
+	if err != nil {
+		return restapi.GetRIDTileResponseSet{Response500: &api.InternalServerErrorBody{
+			ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Unable to get coverage"))}}
+	}
+	resp := &restapi.RIDTileResponse{
+		Isas:  restapi.ISAFeatureCollection{Type: "FeatureCollection", Features: make([]restapi.ISAFeature, 0, len(coverage.ISAs))},
+		Cells: make([]restapi.CellDensity, 0, len(coverage.Cells)),
+	}
+	for _, isa := range coverage.ISAs {
+		if feature := isaToGeoJSON(isa); feature != nil {
+			resp.Isas.Features = append(resp.Isas.Features, *feature)
+		}
+	}
+	for _, d := range coverage.Cells {
+		resp.Cells = append(resp.Cells, restapi.CellDensity{
+			Cell:              d.Cell.ToToken(),
+			Level:             int32(d.Cell.Level()),
+			IsaCount:          int32(d.ISAs),
+			SubscriptionCount: int32(d.Subscriptions),
+			Outline:           cellToGeoJSON(d.Cell),
+		})
+	}
+	return restapi.GetRIDTileResponseSet{Response200: resp}
+}
+
+// parseTile parses the zoom level, column and row of a tile.
+func parseTile(zoom, column, row string) (int, int, int, error) {
+	z, err := strconv.Atoi(zoom)
+	if err != nil || z < 0 || z > maxTileZoom {
+		return 0, 0, 0, stacktrace.NewError("Zoom level must be an integer from 0 to %d", maxTileZoom)
+	}
+	x, err := strconv.Atoi(column)
+	if err != nil || x < 0 || x >= 1<<z {
+		return 0, 0, 0, stacktrace.NewError("Column must be an integer from 0 to %d", 1<<z-1)
+	}
+	y, err := strconv.Atoi(row)
+	if err != nil || y < 0 || y >= 1<<z {
+		return 0, 0, 0, stacktrace.NewError("Row must be an integer from 0 to %d", 1<<z-1)
+	}
+	return z, x, y, nil
+}
+
+// tileOutline returns the counterclockwise vertices of the Web Mercator tile
+// of zoom level z, column x and row y.
+func tileOutline(z, x, y int) []s2.Point {
+	var (
+		n     = float64(int(1) << z)
+		west  = float64(x)/n*360 - 180
+		east  = float64(x+1)/n*360 - 180
+		lat   = func(row int) float64 { return math.Atan(math.Sinh(math.Pi*(1-2*float64(row)/n))) * 180 / math.Pi }
+		north = lat(y)
+		south = lat(y + 1)
+	)
+	return []s2.Point{
+		s2.PointFromLatLng(s2.LatLngFromDegrees(south, west)),
+		s2.PointFromLatLng(s2.LatLngFromDegrees(south, east)),
+		s2.PointFromLatLng(s2.LatLngFromDegrees(north, east)),
+		s2.PointFromLatLng(s2.LatLngFromDegrees(north, west)),
+	}
+}
+
+// cellToGeoJSON returns the outline of cell as a GeoJSON polygon.
+func cellToGeoJSON(cell s2.CellID) restapi.GeoJSONPolygon {
+	c := s2.CellFromCellID(cell)
+	ring := make(restapi.GeoJSONPolygonCoordinatesItem, 0, 5)
+	for i := 0; i < 4; i++ {
+		ll := s2.LatLngFromPoint(c.Vertex(i))
+		ring = append(ring, restapi.GeoJSONPolygonCoordinatesItemItem{ll.Lng.Degrees(), ll.Lat.Degrees()})
+	}
+	return restapi.GeoJSONPolygon{
+		Type:        "Polygon",
+		Coordinates: []restapi.GeoJSONPolygonCoordinatesItem{append(ring, ring[0])},
+	}
+}
@@ -0,0 +1,108 @@
+package aux
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/dss/pkg/rid/application"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+)
+
+// coverageApp returns isa and a density in the first cell searched.
+type coverageApp struct {
+	application.App
+	isa   *ridmodels.IdentificationServiceArea
+	level int
+}
+
+func (a *coverageApp) GetCoverage(ctx context.Context, cells s2.CellUnion, level int) (*application.Coverage, error) {
+	a.level = level
+	return &application.Coverage{
+		ISAs:  []*ridmodels.IdentificationServiceArea{a.isa},
+		Cells: []*application.CellDensity{{Cell: cells[0].Parent(level), ISAs: 1, Subscriptions: 2}},
+	}, nil
+}
+
+func TestGetRIDTile(t *testing.T) {
+	var (
+		ctx = context.Background()
+		isa = &ridmodels.IdentificationServiceArea{
+			ID:        dssmodels.ID("4348c8e5-0b1c-43cf-9114-2e67a4532765"),
+			Owner:     dssmodels.Owner("uss1"),
+			Footprint: &dssmodels.GeoCircle{Center: dssmodels.LatLngPoint{Lat: 46.95, Lng: 7.45}, RadiusMeter: 300},
+		}
+		app    = &coverageApp{isa: isa}
+		server = &Server{RIDApp: app}
+		// Tile of zoom level 14 containing 46.95,7.45.
+		tile = &restapi.GetRIDTileRequest{Z: "14", X: "8531", Y: "5766"}
+	)
+
+	resp := server.GetRIDTile(ctx, tile)
+	require.NotNil(t, resp.Response404)
+
+	server.MapUI = true
+	resp = server.GetRIDTile(ctx, tile)
+	require.NotNil(t, resp.Response200)
+	require.Equal(t, 13, app.level)
+	require.Len(t, resp.Response200.Isas.Features, 1)
+	require.Equal(t, isa.ID.String(), resp.Response200.Isas.Features[0].Id)
+	require.Len(t, resp.Response200.Cells, 1)
+	cell := resp.Response200.Cells[0]
+	require.Equal(t, int32(13), cell.Level)
+	require.Equal(t, int32(1), cell.IsaCount)
+	require.Equal(t, int32(2), cell.SubscriptionCount)
+	require.Len(t, cell.Outline.Coordinates[0], 5)
+	require.Equal(t, 13, s2.CellIDFromToken(cell.Cell).Level())
+
+	// Cells are coarser at lower zoom levels.
+	resp = server.GetRIDTile(ctx, &restapi.GetRIDTileRequest{Z: "10", X: "533", Y: "360"})
+	require.NotNil(t, resp.Response200)
+	require.Equal(t, 12, app.level)
+
+	resp = server.GetRIDTile(ctx, &restapi.GetRIDTileRequest{Z: "5", X: "16", Y: "11"})
+	require.NotNil(t, resp.Response413)
+	for _, invalid := range []*restapi.GetRIDTileRequest{
+		{Z: "23", X: "0", Y: "0"},
+		{Z: "1", X: "2", Y: "0"},
+		{Z: "1", X: "0", Y: "-1"},
+		{Z: "z", X: "0", Y: "0"},
+	} {
+		resp = server.GetRIDTile(ctx, invalid)
+		require.NotNil(t, resp.Response400, "%v", invalid)
+	}
+}
+
+func TestMapUIRouter(t *testing.T) {
+	serve := func(method, path string) (*httptest.ResponseRecorder, bool) {
+		w := httptest.NewRecorder()
+		handled := MapUIRouter{}.Handle(w, httptest.NewRequest(method, path, nil))
+		return w, handled
+	}
+
+	w, handled := serve(http.MethodGet, "/aux/v1/map")
+	require.True(t, handled)
+	require.Equal(t, http.StatusMovedPermanently, w.Code)
+
+	w, handled = serve(http.MethodGet, "/aux/v1/map/")
+	require.True(t, handled)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	require.Contains(t, w.Body.String(), `<script src="map.js">`)
+
+	w, handled = serve(http.MethodGet, "/aux/v1/map/map.js")
+	require.True(t, handled)
+	require.Equal(t, "text/javascript; charset=utf-8", w.Header().Get("Content-Type"))
+
+	_, handled = serve(http.MethodGet, "/aux/v1/map/missing.js")
+	require.False(t, handled)
+	_, handled = serve(http.MethodPost, "/aux/v1/map/")
+	require.False(t, handled)
+	_, handled = serve(http.MethodGet, "/aux/v1/version")
+	require.False(t, handled)
+}
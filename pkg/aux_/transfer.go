@@ -0,0 +1,120 @@
+package aux
+
+import (
+	"context"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/logging"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
+	"github.com/interuss/stacktrace"
+	"go.uber.org/zap"
+)
+
+// TransferOwnership hands ISAs and subscriptions of a USS over to another
+// USS, e.g. when the former shuts down. Every transfer is logged for audit.
+func (a *Server) TransferOwnership(ctx context.Context, req *restapi.TransferOwnershipRequest) restapi.TransferOwnershipResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.TransferOwnershipResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+
+	if err := ridserver.CheckBody(req.Body, req.BodyParseError); err != nil {
+		return restapi.TransferOwnershipResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, err)}}
+	}
+	switch {
+	case req.Body.FromOwner == "" || req.Body.ToOwner == "":
+		return restapi.TransferOwnershipResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing owner"))}}
+	case req.Body.Reason == "":
+		return restapi.TransferOwnershipResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing reason"))}}
+	}
+	isaIDs, err := idsFromStrings(req.Body.Isas)
+	if err != nil {
+		return restapi.TransferOwnershipResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, err)}}
+	}
+	subscriptionIDs, err := idsFromStrings(req.Body.Subscriptions)
+	if err != nil {
+		return restapi.TransferOwnershipResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, err)}}
+	}
+	if len(isaIDs) == 0 && len(subscriptionIDs) == 0 {
+		return restapi.TransferOwnershipResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "No entity to transfer"))}}
+	}
+
+	logger := logging.WithValuesFromContext(ctx, logging.Logger).With(
+		zap.String("from_owner", req.Body.FromOwner),
+		zap.String("to_owner", req.Body.ToOwner),
+		zap.String("reason", req.Body.Reason),
+	)
+	if req.Auth.ClientID != nil {
+		logger = logger.With(zap.String("actor", *req.Auth.ClientID))
+	}
+
+	result, err := a.RIDApp.TransferOwnership(ctx, dssmodels.Owner(req.Body.FromOwner), dssmodels.Owner(req.Body.ToOwner), isaIDs, subscriptionIDs)
+	if err != nil {
+		logger.Warn("audit: ownership transfer failed", zap.Error(err))
+		err = stacktrace.Propagate(err, "Could not transfer ownership")
+		switch stacktrace.GetCode(err) {
+		case dsserr.BadRequest:
+			return restapi.TransferOwnershipResponseSet{Response400: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, err)}}
+		case dsserr.NotFound:
+			return restapi.TransferOwnershipResponseSet{Response404: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, err)}}
+		}
+		return restapi.TransferOwnershipResponseSet{Response500: &api.InternalServerErrorBody{
+			ErrorMessage: *dsserr.Handle(ctx, err)}}
+	}
+
+	resp := &restapi.TransferOwnershipResponse{
+		Isas:                  make([]restapi.TransferredEntity, 0, len(result.ISAs)),
+		Subscriptions:         make([]restapi.TransferredEntity, 0, len(result.Subscriptions)),
+		NotifiedSubscriptions: make([]string, 0, len(result.Notified)),
+	}
+	for _, isa := range result.ISAs {
+		resp.Isas = append(resp.Isas, restapi.TransferredEntity{Id: isa.ID.String(), Version: isa.Version.String()})
+	}
+	for _, sub := range result.Subscriptions {
+		resp.Subscriptions = append(resp.Subscriptions, restapi.TransferredEntity{Id: sub.ID.String(), Version: sub.Version.String()})
+	}
+	for _, sub := range result.Notified {
+		resp.NotifiedSubscriptions = append(resp.NotifiedSubscriptions, sub.ID.String())
+	}
+	ridserver.RecordNotificationDeltas(ctx, result.Notified)
+	logger.Info("audit: ownership transferred",
+		zap.Strings("isas", entityIDs(resp.Isas)), zap.Strings("subscriptions", entityIDs(resp.Subscriptions)))
+	return restapi.TransferOwnershipResponseSet{Response200: resp}
+}
+
+// idsFromStrings parses the IDs of ids.
+func idsFromStrings(ids *[]string) ([]dssmodels.ID, error) {
+	if ids == nil {
+		return nil, nil
+	}
+	result := make([]dssmodels.ID, 0, len(*ids))
+	for _, s := range *ids {
+		id, err := dssmodels.IDFromString(s)
+		if err != nil {
+			return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Invalid ID format: `%s`", s)
+		}
+		result = append(result, id)
+	}
+	return result, nil
+}
+
+// entityIDs returns the IDs of entities.
+func entityIDs(entities []restapi.TransferredEntity) []string {
+	ids := make([]string, 0, len(entities))
+	for _, e := range entities {
+		ids = append(ids, e.Id)
+	}
+	return ids
+}
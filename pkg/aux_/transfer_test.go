@@ -0,0 +1,70 @@
+package aux
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/dss/pkg/rid/application"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+// transferApp transfers the ISAs requested, failing with err if set.
+type transferApp struct {
+	application.App
+	from, to dssmodels.Owner
+	err      error
+}
+
+func (a *transferApp) TransferOwnership(ctx context.Context, from, to dssmodels.Owner, isaIDs, subscriptionIDs []dssmodels.ID) (*application.TransferResult, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	a.from, a.to = from, to
+	result := &application.TransferResult{}
+	for _, id := range isaIDs {
+		result.ISAs = append(result.ISAs, &ridmodels.IdentificationServiceArea{ID: id, Owner: to, Version: dssmodels.VersionFromTime(time.Unix(1, 0))})
+	}
+	return result, nil
+}
+
+func TestTransferOwnership(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		actor  = "admin"
+		app    = &transferApp{}
+		server = &Server{RIDApp: app}
+		id     = "4348c8e5-0b1c-43cf-9114-2e67a4532765"
+		params = func(isas ...string) *restapi.TransferOwnershipParameters {
+			return &restapi.TransferOwnershipParameters{FromOwner: "uss1", ToOwner: "uss2", Reason: "wind-down", Isas: &isas}
+		}
+		transfer = func(params *restapi.TransferOwnershipParameters) restapi.TransferOwnershipResponseSet {
+			return server.TransferOwnership(ctx, &restapi.TransferOwnershipRequest{
+				Body: params, Auth: api.AuthorizationResult{ClientID: &actor}})
+		}
+	)
+
+	resp := transfer(params(id))
+	require.NotNil(t, resp.Response200)
+	require.Equal(t, []restapi.TransferredEntity{{Id: id, Version: dssmodels.VersionFromTime(time.Unix(1, 0)).String()}}, resp.Response200.Isas)
+	require.Empty(t, resp.Response200.Subscriptions)
+	require.Equal(t, dssmodels.Owner("uss1"), app.from)
+	require.Equal(t, dssmodels.Owner("uss2"), app.to)
+
+	require.NotNil(t, transfer(params()).Response400)
+	require.NotNil(t, transfer(params("invalid")).Response400)
+	noReason := params(id)
+	noReason.Reason = ""
+	require.NotNil(t, transfer(noReason).Response400)
+
+	app.err = stacktrace.NewErrorWithCode(dsserr.NotFound, "ISA not found")
+	require.NotNil(t, transfer(params(id)).Response404)
+	app.err = stacktrace.NewErrorWithCode(dsserr.BadRequest, "ISA not owned by uss1")
+	require.NotNil(t, transfer(params(id)).Response400)
+}
@@ -0,0 +1,58 @@
+package aux
+
+import (
+	"context"
+	"sort"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/stacktrace"
+)
+
+// GetMyUsage returns the remote ID entities, quotas and recently rejected
+// requests of the client.
+func (a *Server) GetMyUsage(ctx context.Context, req *restapi.GetMyUsageRequest) restapi.GetMyUsageResponseSet {
+	if req.Auth.Error != nil {
+		resp := restapi.GetMyUsageResponseSet{}
+		setAuthError(ctx, req.Auth.Error, &resp.Response401, &resp.Response403, &resp.Response500)
+		return resp
+	}
+	if req.Auth.ClientID == nil {
+		return restapi.GetMyUsageResponseSet{Response403: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Missing owner"))}}
+	}
+	owner := dssmodels.Owner(*req.Auth.ClientID)
+
+	usage, err := a.RIDApp.GetUsage(ctx, owner)
+	if err != nil {
+		return restapi.GetMyUsageResponseSet{Response500: &api.InternalServerErrorBody{
+			ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Could not get usage"))}}
+	}
+	resp := &restapi.UsageResponse{
+		Owner:               owner.String(),
+		ActiveIsas:          int32(usage.ActiveISAs),
+		ActiveSubscriptions: int32(usage.ActiveSubscriptions),
+		Limits: restapi.UsageLimits{
+			MaxSubscriptionsPerArea: int32(usage.MaxSubscriptionsPerArea),
+			MaxResults:              dssmodels.MaxResultLimit,
+		},
+	}
+	if maxResults := a.Capabilities.Load().MaxResults; maxResults > 0 {
+		resp.Limits.MaxResults = int32(maxResults)
+	}
+	if a.Rejections != nil {
+		counts := a.Rejections.Counts(owner.String())
+		rejections := &restapi.RecentRejections{
+			WindowSeconds: int32(a.Rejections.Window.Seconds()),
+			Counts:        make([]restapi.RejectionCount, 0, len(counts)),
+		}
+		for status, n := range counts {
+			rejections.Counts = append(rejections.Counts, restapi.RejectionCount{Status: int32(status), Count: int32(n)})
+		}
+		sort.Slice(rejections.Counts, func(i, j int) bool { return rejections.Counts[i].Status < rejections.Counts[j].Status })
+		resp.RecentRejections = rejections
+	}
+	return restapi.GetMyUsageResponseSet{Response200: resp}
+}
@@ -0,0 +1,71 @@
+package aux
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/auxv1"
+	"github.com/interuss/dss/pkg/logging"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/dss/pkg/rid/application"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// usageApp reports the usage of uss1.
+type usageApp struct {
+	application.App
+}
+
+func (usageApp) GetUsage(_ context.Context, owner dssmodels.Owner) (*application.Usage, error) {
+	if owner != "uss1" {
+		return &application.Usage{MaxSubscriptionsPerArea: 10}, nil
+	}
+	return &application.Usage{ActiveISAs: 3, ActiveSubscriptions: 2, MaxSubscriptionsPerArea: 10}, nil
+}
+
+func TestGetMyUsage(t *testing.T) {
+	var (
+		ctx        = context.Background()
+		client     = "uss1"
+		rejections = &ridserver.RejectionCounter{Window: time.Hour}
+		server     = &Server{RIDApp: usageApp{}, Capabilities: &SwappableCapabilities{}}
+		get        = func(client *string) restapi.GetMyUsageResponseSet {
+			return server.GetMyUsage(ctx, &restapi.GetMyUsageRequest{Auth: api.AuthorizationResult{ClientID: client}})
+		}
+		reject = rejections.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logging.WithFields(r.Context(), zap.String("owner", client))
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+	)
+
+	server.Capabilities.Swap(Capabilities{MaxResults: 100})
+	resp := get(&client)
+	require.NotNil(t, resp.Response200)
+	require.Equal(t, &restapi.UsageResponse{
+		Owner:               "uss1",
+		ActiveIsas:          3,
+		ActiveSubscriptions: 2,
+		Limits:              restapi.UsageLimits{MaxSubscriptionsPerArea: 10, MaxResults: 100},
+	}, resp.Response200)
+
+	// Rejections are reported once counted.
+	server.Rejections = rejections
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPut, "/v1/dss/subscriptions/a3cde7e1-bc1c-4a95-bc94-0e2ba0e0dbbb", nil)
+		reject.ServeHTTP(httptest.NewRecorder(), req.WithContext(logging.NewContext(req.Context())))
+	}
+	resp = get(&client)
+	require.NotNil(t, resp.Response200)
+	require.Equal(t, &restapi.RecentRejections{
+		WindowSeconds: 3600,
+		Counts:        []restapi.RejectionCount{{Status: http.StatusTooManyRequests, Count: 2}},
+	}, resp.Response200.RecentRejections)
+
+	require.NotNil(t, get(nil).Response403)
+}
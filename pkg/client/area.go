@@ -0,0 +1,39 @@
+package client
+
+import (
+	"strconv"
+	"strings"
+
+	restapi "github.com/interuss/dss/pkg/api/ridv2"
+	dssmodels "github.com/interuss/dss/pkg/models"
+)
+
+// AreaFromVertices formats vertices into the "lat1,lng1,lat2,lng2,..." area
+// string accepted by the search endpoints.
+func AreaFromVertices(vertices []restapi.LatLngPoint) restapi.GeoPolygonString {
+	coords := make([]string, 0, 2*len(vertices))
+	for _, v := range vertices {
+		coords = append(coords,
+			strconv.FormatFloat(float64(v.Lat), 'f', -1, 64),
+			strconv.FormatFloat(float64(v.Lng), 'f', -1, 64))
+	}
+	return restapi.GeoPolygonString(strings.Join(coords, ","))
+}
+
+// AreaFromPolygon formats the outline of polygon into an area string.
+func AreaFromPolygon(polygon *restapi.Polygon) restapi.GeoPolygonString {
+	return AreaFromVertices(polygon.Vertices)
+}
+
+// AreaFromGeoPolygon formats a footprint expressed as a business object into
+// an area string.
+func AreaFromGeoPolygon(polygon *dssmodels.GeoPolygon) restapi.GeoPolygonString {
+	vertices := make([]restapi.LatLngPoint, 0, len(polygon.Vertices))
+	for _, v := range polygon.Vertices {
+		vertices = append(vertices, restapi.LatLngPoint{
+			Lat: restapi.Latitude(v.Lat),
+			Lng: restapi.Longitude(v.Lng),
+		})
+	}
+	return AreaFromVertices(vertices)
+}
@@ -0,0 +1,272 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/interuss/dss/pkg/api"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+)
+
+const (
+	// DefaultMaxAttempts is the number of times a retryable request is
+	// attempted when Options.MaxAttempts is not set.
+	DefaultMaxAttempts = 3
+
+	// DefaultInitialBackoff is the delay before the first retry when
+	// Options.InitialBackoff is not set. It doubles after every attempt.
+	DefaultInitialBackoff = 500 * time.Millisecond
+
+	// Unavailable is the error code used when the DSS could not be reached or
+	// returned a server-side error, even after retries.
+	Unavailable = stacktrace.ErrorCode(1000)
+
+	// InvalidSignature is the error code used when a response is not signed
+	// with Options.ResponseKey.
+	InvalidSignature = stacktrace.ErrorCode(1001)
+
+	// idempotencyKeyHeader identifies a write across its attempts, so that
+	// the DSS answers its retries with the response to the first attempt.
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+// TokenSource provides access tokens for requests made to the DSS.
+type TokenSource interface {
+	// Token returns an access token granting scope.
+	Token(ctx context.Context, scope api.RequiredScope) (string, error)
+}
+
+// TokenSourceFunc adapts a function to the TokenSource interface.
+type TokenSourceFunc func(ctx context.Context, scope api.RequiredScope) (string, error)
+
+// Token implements TokenSource.
+func (f TokenSourceFunc) Token(ctx context.Context, scope api.RequiredScope) (string, error) {
+	return f(ctx, scope)
+}
+
+// Options configures a Client.
+type Options struct {
+	// HTTPClient performs the requests; http.DefaultClient is used if nil.
+	HTTPClient *http.Client
+	// Tokens provides access tokens; requests are sent without an
+	// Authorization header if nil.
+	Tokens TokenSource
+	// MaxAttempts bounds the number of attempts of a retryable request.
+	MaxAttempts int
+	// IdempotentWrites sends each write with a new Idempotency-Key header and
+	// retries it like reads are. Only enable it against a DSS which keeps the
+	// responses to idempotency keys (--rid_idempotency_key_retention), as
+	// other writes may be applied more than once; writes are not retried
+	// otherwise.
+	IdempotentWrites bool
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// ResponseKey is the public key of the DSS with which the signatures of
+	// its responses are verified; responses are not verified if nil.
+	ResponseKey crypto.PublicKey
+}
+
+// Client is a client of a single DSS instance.
+type Client struct {
+	baseURL *url.URL
+	opts    Options
+}
+
+// New returns a Client sending requests to the DSS at baseURL, e.g.
+// https://dss.example.com.
+func New(baseURL string, opts Options) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error parsing DSS base URL")
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = DefaultInitialBackoff
+	}
+	return &Client{baseURL: u, opts: opts}, nil
+}
+
+// errorCodeFromStatus maps an HTTP status returned by the DSS to the error
+// code used by the DSS to produce it.
+func errorCodeFromStatus(status int) stacktrace.ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return dsserr.BadRequest
+	case http.StatusUnauthorized:
+		return dsserr.Unauthenticated
+	case http.StatusForbidden:
+		return dsserr.PermissionDenied
+	case http.StatusNotFound:
+		return dsserr.NotFound
+	case http.StatusConflict:
+		// The DSS also responds with 409 when creating an entity that already
+		// exists; both cases are resolved by fetching the current version.
+		return dsserr.VersionMismatch
+	case http.StatusRequestEntityTooLarge:
+		return dsserr.AreaTooLarge
+	case http.StatusTooManyRequests:
+		return dsserr.Exhausted
+	default:
+		return Unavailable
+	}
+}
+
+// isRetryable returns true if a request that failed with status may succeed
+// when attempted again.
+func isRetryable(status int) bool {
+	switch status {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// do sends a request to the DSS with the given method and escaped path, encoding
+// body as JSON if not nil and decoding a successful response into out if not
+// nil. Server-side and transport errors of reads, and of writes when
+// Options.IdempotentWrites is set, are retried with exponential backoff.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, scope api.RequiredScope, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return stacktrace.Propagate(err, "Error encoding request body")
+		}
+	}
+
+	u := *c.baseURL
+	u.RawPath = c.baseURL.EscapedPath() + path
+	unescapedPath, err := url.PathUnescape(u.RawPath)
+	if err != nil {
+		return stacktrace.Propagate(err, "Error unescaping request path %s", path)
+	}
+	u.Path = unescapedPath
+	u.RawQuery = query.Encode()
+
+	retryable := method == http.MethodGet
+	var idempotencyKey string
+	if !retryable && c.opts.IdempotentWrites {
+		idempotencyKey = uuid.NewString()
+		retryable = true
+	}
+
+	backoff := c.opts.InitialBackoff
+	var lastErr error
+	for attempt := 1; attempt <= c.opts.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return stacktrace.Propagate(ctx.Err(), "Context done while waiting to retry %s %s", method, path)
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		status, respBody, err := c.send(ctx, method, u.String(), scope, payload, idempotencyKey)
+		if err != nil {
+			if stacktrace.GetCode(err) == InvalidSignature {
+				return stacktrace.Propagate(err, "Error verifying response to %s %s", method, path)
+			}
+			if ctx.Err() != nil {
+				return stacktrace.Propagate(err, "Error sending %s %s", method, path)
+			}
+			lastErr = stacktrace.PropagateWithCode(err, Unavailable, "Error sending %s %s", method, path)
+			if !retryable {
+				return lastErr
+			}
+			continue
+		}
+
+		if status == http.StatusOK {
+			if out == nil {
+				return nil
+			}
+			if err := json.Unmarshal(respBody, out); err != nil {
+				return stacktrace.Propagate(err, "Error decoding response to %s %s", method, path)
+			}
+			return nil
+		}
+
+		lastErr = stacktrace.NewErrorWithCode(errorCodeFromStatus(status), "DSS responded to %s %s with %d: %s", method, path, status, errorMessage(respBody))
+		if !retryable || !isRetryable(status) {
+			return lastErr
+		}
+	}
+	return stacktrace.Propagate(lastErr, "Giving up after %d attempts", c.opts.MaxAttempts)
+}
+
+// send performs a single HTTP exchange and returns the status and body of the
+// response. idempotencyKey is sent unless empty.
+func (c *Client) send(ctx context.Context, method, u string, scope api.RequiredScope, payload []byte, idempotencyKey string) (int, []byte, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return 0, nil, stacktrace.Propagate(err, "Error creating request")
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if idempotencyKey != "" {
+		req.Header.Set(idempotencyKeyHeader, idempotencyKey)
+	}
+	if c.opts.Tokens != nil {
+		token, err := c.opts.Tokens.Token(ctx, scope)
+		if err != nil {
+			return 0, nil, stacktrace.Propagate(err, "Error obtaining access token for scope %s", scope)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.opts.HTTPClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, stacktrace.Propagate(err, "Error reading response body")
+	}
+	if c.opts.ResponseKey != nil {
+		if _, err := VerifyResponse(c.opts.ResponseKey, resp, respBody); err != nil {
+			return 0, nil, stacktrace.PropagateWithCode(err, InvalidSignature, "Response is not signed by the DSS")
+		}
+	}
+	return resp.StatusCode, respBody, nil
+}
+
+// errorMessage extracts the human-readable message of an error response
+// returned by the DSS.
+func errorMessage(body []byte) string {
+	var resp struct {
+		Message      *string `json:"message"`
+		ErrorMessage *string `json:"error_message"`
+	}
+	if err := json.Unmarshal(body, &resp); err == nil {
+		switch {
+		case resp.Message != nil:
+			return *resp.Message
+		case resp.ErrorMessage != nil:
+			return *resp.ErrorMessage
+		}
+	}
+	return string(body)
+}
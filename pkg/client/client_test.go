@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/ridv2"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+const isaID = restapi.EntityUUID("4348c8e5-0b1c-43cf-9114-2e67a4532765")
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	return newTestClientWithOptions(t, Options{}, handler)
+}
+
+func newTestClientWithOptions(t *testing.T, opts Options, handler http.HandlerFunc) *Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	opts.Tokens = TokenSourceFunc(func(ctx context.Context, scope api.RequiredScope) (string, error) {
+		return "token-" + string(scope), nil
+	})
+	opts.InitialBackoff = time.Millisecond
+	c, err := New(srv.URL, opts)
+	require.NoError(t, err)
+	return c
+}
+
+func TestRetryOnServerError(t *testing.T) {
+	attempts := 0
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		require.Equal(t, "Bearer token-rid.display_provider", r.Header.Get("Authorization"))
+		if attempts < DefaultMaxAttempts {
+			api.WriteJSON(w, http.StatusServiceUnavailable, api.InternalServerErrorBody{ErrorMessage: "unavailable"})
+			return
+		}
+		api.WriteJSON(w, http.StatusOK, restapi.GetIdentificationServiceAreaResponse{
+			ServiceArea: restapi.IdentificationServiceArea{Id: isaID, Version: "v1"}})
+	})
+
+	isa, err := c.GetISA(context.Background(), isaID)
+	require.NoError(t, err)
+	require.Equal(t, restapi.Version("v1"), isa.Version)
+	require.Equal(t, DefaultMaxAttempts, attempts)
+}
+
+func TestNoRetryOnClientError(t *testing.T) {
+	attempts := 0
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		msg := "ISA not found"
+		api.WriteJSON(w, http.StatusNotFound, restapi.ErrorResponse{Message: &msg})
+	})
+
+	_, err := c.GetISA(context.Background(), isaID)
+	require.Error(t, err)
+	require.Equal(t, dsserr.NotFound, stacktrace.GetCode(err))
+	require.Contains(t, err.Error(), "ISA not found")
+	require.Equal(t, 1, attempts)
+}
+
+func TestNoRetryOfWrites(t *testing.T) {
+	version := restapi.Version("v1")
+	attempts := 0
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		require.Empty(t, r.Header.Get(idempotencyKeyHeader))
+		api.WriteJSON(w, http.StatusServiceUnavailable, api.InternalServerErrorBody{ErrorMessage: "unavailable"})
+	})
+
+	_, err := c.DeleteISA(context.Background(), isaID, &version)
+	require.Error(t, err)
+	require.Equal(t, Unavailable, stacktrace.GetCode(err))
+	require.Equal(t, 1, attempts)
+}
+
+func TestRetryOfIdempotentWrites(t *testing.T) {
+	version := restapi.Version("v1")
+	var keys []string
+	c := newTestClientWithOptions(t, Options{IdempotentWrites: true}, func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(idempotencyKeyHeader))
+		if len(keys) < DefaultMaxAttempts {
+			api.WriteJSON(w, http.StatusServiceUnavailable, api.InternalServerErrorBody{ErrorMessage: "unavailable"})
+			return
+		}
+		api.WriteJSON(w, http.StatusOK, restapi.DeleteIdentificationServiceAreaResponse{})
+	})
+
+	_, err := c.DeleteISA(context.Background(), isaID, &version)
+	require.NoError(t, err)
+	require.Len(t, keys, DefaultMaxAttempts)
+	require.NotEmpty(t, keys[0])
+	for _, key := range keys {
+		require.Equal(t, keys[0], key)
+	}
+}
+
+func TestPutISA(t *testing.T) {
+	var (
+		exists  bool
+		putPath string
+	)
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			if !exists {
+				api.WriteJSON(w, http.StatusNotFound, restapi.ErrorResponse{})
+				return
+			}
+			api.WriteJSON(w, http.StatusOK, restapi.GetIdentificationServiceAreaResponse{
+				ServiceArea: restapi.IdentificationServiceArea{Id: isaID, Version: "v1"}})
+		case http.MethodPut:
+			require.Equal(t, "Bearer token-rid.service_provider", r.Header.Get("Authorization"))
+			params := &restapi.CreateIdentificationServiceAreaParameters{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(params))
+			require.Equal(t, restapi.FlightsUSSBaseURL("https://uss.example.com"), params.UssBaseUrl)
+			putPath = r.URL.Path
+			api.WriteJSON(w, http.StatusOK, restapi.PutIdentificationServiceAreaResponse{})
+		}
+	})
+	params := &restapi.CreateIdentificationServiceAreaParameters{UssBaseUrl: "https://uss.example.com"}
+
+	_, err := c.PutISA(context.Background(), isaID, params)
+	require.NoError(t, err)
+	require.Equal(t, "/rid/v2/dss/identification_service_areas/"+string(isaID), putPath)
+
+	exists = true
+	_, err = c.PutISA(context.Background(), isaID, params)
+	require.NoError(t, err)
+	require.Equal(t, "/rid/v2/dss/identification_service_areas/"+string(isaID)+"/v1", putPath)
+}
+
+func TestUpdateISAEscapesPath(t *testing.T) {
+	var putPath string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		putPath = r.URL.EscapedPath()
+		api.WriteJSON(w, http.StatusOK, restapi.PutIdentificationServiceAreaResponse{})
+	})
+
+	_, err := c.UpdateISA(context.Background(), isaID, "v1/50%", &restapi.UpdateIdentificationServiceAreaParameters{})
+	require.NoError(t, err)
+	require.Equal(t, "/rid/v2/dss/identification_service_areas/"+string(isaID)+"/v1%2F50%25", putPath)
+}
+
+func TestAreaFromVertices(t *testing.T) {
+	area := AreaFromVertices([]restapi.LatLngPoint{
+		{Lat: 37.427636, Lng: -122.170502},
+		{Lat: 37.408799, Lng: -122.064069},
+		{Lat: 37.421265, Lng: -122.032604},
+	})
+	require.Equal(t, restapi.GeoPolygonString("37.427636,-122.170502,37.408799,-122.064069,37.421265,-122.032604"), area)
+}
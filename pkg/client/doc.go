@@ -0,0 +1,4 @@
+// Package client provides a typed Go client for the DSS HTTP APIs, intended to
+// save USS implementers from reimplementing token acquisition, version
+// handling, retries and area formatting.
+package client
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	restapi "github.com/interuss/dss/pkg/api/ridv2"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+)
+
+const ridV2Prefix = "/rid/v2/dss"
+
+func isaPath(id restapi.EntityUUID) string {
+	return ridV2Prefix + "/identification_service_areas/" + url.PathEscape(string(id))
+}
+
+func subscriptionPath(id restapi.SubscriptionUUID) string {
+	return ridV2Prefix + "/subscriptions/" + url.PathEscape(string(id))
+}
+
+// GetISA returns the Identification Service Area identified by id.
+func (c *Client) GetISA(ctx context.Context, id restapi.EntityUUID) (*restapi.IdentificationServiceArea, error) {
+	resp := &restapi.GetIdentificationServiceAreaResponse{}
+	if err := c.do(ctx, http.MethodGet, isaPath(id), nil, restapi.RidDisplayProviderScope, nil, resp); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return &resp.ServiceArea, nil
+}
+
+// SearchISAs returns the Identification Service Areas intersecting area,
+// optionally restricted to the [earliest, latest] time range.
+func (c *Client) SearchISAs(ctx context.Context, area restapi.GeoPolygonString, earliest, latest *string) ([]restapi.IdentificationServiceArea, error) {
+	query := url.Values{"area": {string(area)}}
+	if earliest != nil {
+		query.Set("earliest_time", *earliest)
+	}
+	if latest != nil {
+		query.Set("latest_time", *latest)
+	}
+	resp := &restapi.SearchIdentificationServiceAreasResponse{}
+	if err := c.do(ctx, http.MethodGet, ridV2Prefix+"/identification_service_areas", query, restapi.RidDisplayProviderScope, nil, resp); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if resp.ServiceAreas == nil {
+		return nil, nil
+	}
+	return *resp.ServiceAreas, nil
+}
+
+// CreateISA creates the Identification Service Area identified by id.
+func (c *Client) CreateISA(ctx context.Context, id restapi.EntityUUID, params *restapi.CreateIdentificationServiceAreaParameters) (*restapi.PutIdentificationServiceAreaResponse, error) {
+	resp := &restapi.PutIdentificationServiceAreaResponse{}
+	if err := c.do(ctx, http.MethodPut, isaPath(id), nil, restapi.RidServiceProviderScope, params, resp); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return resp, nil
+}
+
+// UpdateISA updates the Identification Service Area identified by id, which
+// must currently be at version.
+func (c *Client) UpdateISA(ctx context.Context, id restapi.EntityUUID, version restapi.Version, params *restapi.UpdateIdentificationServiceAreaParameters) (*restapi.PutIdentificationServiceAreaResponse, error) {
+	resp := &restapi.PutIdentificationServiceAreaResponse{}
+	path := isaPath(id) + "/" + url.PathEscape(string(version))
+	if err := c.do(ctx, http.MethodPut, path, nil, restapi.RidServiceProviderScope, params, resp); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return resp, nil
+}
+
+// PutISA creates the Identification Service Area identified by id or, if it
+// already exists, updates it at its current version.
+func (c *Client) PutISA(ctx context.Context, id restapi.EntityUUID, params *restapi.CreateIdentificationServiceAreaParameters) (*restapi.PutIdentificationServiceAreaResponse, error) {
+	old, err := c.GetISA(ctx, id)
+	switch {
+	case stacktrace.GetCode(err) == dsserr.NotFound:
+		return c.CreateISA(ctx, id, params)
+	case err != nil:
+		return nil, stacktrace.Propagate(err, "Error getting current version of ISA %s", id)
+	}
+	return c.UpdateISA(ctx, id, old.Version, (*restapi.UpdateIdentificationServiceAreaParameters)(params))
+}
+
+// DeleteISA deletes the Identification Service Area identified by id. If
+// version is nil, the current version is fetched from the DSS first.
+func (c *Client) DeleteISA(ctx context.Context, id restapi.EntityUUID, version *restapi.Version) (*restapi.DeleteIdentificationServiceAreaResponse, error) {
+	if version == nil {
+		old, err := c.GetISA(ctx, id)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Error getting current version of ISA %s", id)
+		}
+		version = &old.Version
+	}
+	resp := &restapi.DeleteIdentificationServiceAreaResponse{}
+	path := isaPath(id) + "/" + url.PathEscape(string(*version))
+	if err := c.do(ctx, http.MethodDelete, path, nil, restapi.RidServiceProviderScope, nil, resp); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return resp, nil
+}
+
+// GetSubscription returns the Subscription identified by id.
+func (c *Client) GetSubscription(ctx context.Context, id restapi.SubscriptionUUID) (*restapi.Subscription, error) {
+	resp := &restapi.GetSubscriptionResponse{}
+	if err := c.do(ctx, http.MethodGet, subscriptionPath(id), nil, restapi.RidDisplayProviderScope, nil, resp); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return &resp.Subscription, nil
+}
+
+// SearchSubscriptions returns the Subscriptions of the caller intersecting
+// area.
+func (c *Client) SearchSubscriptions(ctx context.Context, area restapi.GeoPolygonString) ([]restapi.Subscription, error) {
+	query := url.Values{"area": {string(area)}}
+	resp := &restapi.SearchSubscriptionsResponse{}
+	if err := c.do(ctx, http.MethodGet, ridV2Prefix+"/subscriptions", query, restapi.RidDisplayProviderScope, nil, resp); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if resp.Subscriptions == nil {
+		return nil, nil
+	}
+	return *resp.Subscriptions, nil
+}
+
+// CreateSubscription creates the Subscription identified by id.
+func (c *Client) CreateSubscription(ctx context.Context, id restapi.SubscriptionUUID, params *restapi.CreateSubscriptionParameters) (*restapi.PutSubscriptionResponse, error) {
+	resp := &restapi.PutSubscriptionResponse{}
+	if err := c.do(ctx, http.MethodPut, subscriptionPath(id), nil, restapi.RidDisplayProviderScope, params, resp); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return resp, nil
+}
+
+// UpdateSubscription updates the Subscription identified by id, which must
+// currently be at version.
+func (c *Client) UpdateSubscription(ctx context.Context, id restapi.SubscriptionUUID, version restapi.Version, params *restapi.UpdateSubscriptionParameters) (*restapi.PutSubscriptionResponse, error) {
+	resp := &restapi.PutSubscriptionResponse{}
+	path := subscriptionPath(id) + "/" + url.PathEscape(string(version))
+	if err := c.do(ctx, http.MethodPut, path, nil, restapi.RidDisplayProviderScope, params, resp); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return resp, nil
+}
+
+// PutSubscription creates the Subscription identified by id or, if it
+// already exists, updates it at its current version.
+func (c *Client) PutSubscription(ctx context.Context, id restapi.SubscriptionUUID, params *restapi.CreateSubscriptionParameters) (*restapi.PutSubscriptionResponse, error) {
+	old, err := c.GetSubscription(ctx, id)
+	switch {
+	case stacktrace.GetCode(err) == dsserr.NotFound:
+		return c.CreateSubscription(ctx, id, params)
+	case err != nil:
+		return nil, stacktrace.Propagate(err, "Error getting current version of Subscription %s", id)
+	}
+	return c.UpdateSubscription(ctx, id, old.Version, (*restapi.UpdateSubscriptionParameters)(params))
+}
+
+// DeleteSubscription deletes the Subscription identified by id. If version is
+// nil, the current version is fetched from the DSS first.
+func (c *Client) DeleteSubscription(ctx context.Context, id restapi.SubscriptionUUID, version *restapi.Version) (*restapi.DeleteSubscriptionResponse, error) {
+	if version == nil {
+		old, err := c.GetSubscription(ctx, id)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Error getting current version of Subscription %s", id)
+		}
+		version = &old.Version
+	}
+	resp := &restapi.DeleteSubscriptionResponse{}
+	path := subscriptionPath(id) + "/" + url.PathEscape(string(*version))
+	if err := c.do(ctx, http.MethodDelete, path, nil, restapi.RidDisplayProviderScope, nil, resp); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return resp, nil
+}
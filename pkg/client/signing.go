@@ -0,0 +1,17 @@
+package client
+
+import (
+	"crypto"
+	"net/http"
+	"time"
+
+	"github.com/interuss/dss/pkg/signing"
+)
+
+// VerifyResponse verifies that resp, whose body was read into body, was
+// signed by the DSS with the private key of key, and returns the time at
+// which it was signed. Keeping the signing.Header of resp along with the
+// request and body proves what the DSS responded and when.
+func VerifyResponse(key crypto.PublicKey, resp *http.Response, body []byte) (time.Time, error) {
+	return signing.Verify(key, resp.Header.Get(signing.Header), resp.Request.Method, resp.Request.URL.RequestURI(), resp.StatusCode, body)
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/ridv2"
+	"github.com/interuss/dss/pkg/signing"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyResponses(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer, err := signing.NewSigner(key, "")
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(signer.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.WriteJSON(w, http.StatusOK, restapi.GetIdentificationServiceAreaResponse{
+			ServiceArea: restapi.IdentificationServiceArea{Id: isaID, Version: "v1"}})
+	})))
+	t.Cleanup(srv.Close)
+	// The proxy tampers with the body of signed responses.
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, err := http.Get(srv.URL + r.URL.RequestURI())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		w.Header().Set(signing.Header, resp.Header.Get(signing.Header))
+		api.WriteJSON(w, http.StatusOK, restapi.GetIdentificationServiceAreaResponse{
+			ServiceArea: restapi.IdentificationServiceArea{Id: isaID, Version: "v3"}})
+	}))
+	t.Cleanup(proxy.Close)
+
+	c, err := New(srv.URL, Options{ResponseKey: &key.PublicKey, InitialBackoff: time.Millisecond})
+	require.NoError(t, err)
+	isa, err := c.GetISA(context.Background(), isaID)
+	require.NoError(t, err)
+	require.Equal(t, restapi.Version("v1"), isa.Version)
+
+	c, err = New(proxy.URL, Options{ResponseKey: &key.PublicKey, InitialBackoff: time.Millisecond})
+	require.NoError(t, err)
+	_, err = c.GetISA(context.Background(), isaID)
+	require.Error(t, err)
+	require.Equal(t, InvalidSignature, stacktrace.GetCode(err))
+}
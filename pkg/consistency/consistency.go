@@ -0,0 +1,105 @@
+package consistency
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	"github.com/interuss/stacktrace"
+)
+
+// TokenHeader is the header of the responses carrying the token of the writes
+// of the request, and of the requests passing it back.
+const TokenHeader = "X-DSS-Consistency-Token"
+
+// Policy routes the reads of requests carrying a token to the primary
+// database while the token is more recent than MaxStaleness, the maximum lag
+// of the replicas serving reads.
+type Policy struct {
+	MaxStaleness time.Duration
+}
+
+// state is the consistency of a single request.
+type state struct {
+	requiresPrimary bool
+	wrote           atomic.Bool
+}
+
+type contextKey struct{}
+
+// Middleware returns an http.Handler making the token of each request
+// available to the stores of next through RequiresPrimary, and setting the
+// TokenHeader of the responses to requests whose stores called RecordWrite.
+func (p Policy) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := &state{}
+		if v := r.Header.Get(TokenHeader); v != "" {
+			written, err := parseToken(v)
+			if err != nil {
+				api.WriteJSON(w, http.StatusBadRequest, map[string]string{
+					"message": fmt.Sprintf("Invalid %s header `%s`: expected a token from a previous response", TokenHeader, v)})
+				return
+			}
+			s.requiresPrimary = time.Since(written) < p.MaxStaleness
+		}
+		next.ServeHTTP(&responseWriter{ResponseWriter: w, state: s}, r.WithContext(context.WithValue(r.Context(), contextKey{}, s)))
+	})
+}
+
+// formatToken returns the token of writes committed before t.
+func formatToken(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// parseToken returns the time before which the writes of token committed.
+func parseToken(token string) (time.Time, error) {
+	nanos, err := strconv.ParseInt(token, 10, 64)
+	if err != nil || nanos <= 0 {
+		return time.Time{}, stacktrace.NewError("Invalid consistency token")
+	}
+	return time.Unix(0, nanos), nil
+}
+
+// responseWriter sets TokenHeader in the responses to requests which wrote
+// to the database, with the time of the response, which follows the commit
+// of the writes.
+type responseWriter struct {
+	http.ResponseWriter
+	state       *state
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader && w.state.wrote.Load() {
+		w.Header().Set(TokenHeader, formatToken(time.Now()))
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// RecordWrite notes that the request of ctx committed writes to the primary
+// database, so that its response carries a token.
+func RecordWrite(ctx context.Context) {
+	if s, ok := ctx.Value(contextKey{}).(*state); ok {
+		s.wrote.Store(true)
+	}
+}
+
+// RequiresPrimary returns whether the request of ctx must read from the
+// primary database, as its token may be more recent than the data of the
+// replicas.
+func RequiresPrimary(ctx context.Context) bool {
+	s, ok := ctx.Value(contextKey{}).(*state)
+	return ok && s.requiresPrimary
+}
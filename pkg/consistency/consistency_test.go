@@ -0,0 +1,67 @@
+package consistency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	p := Policy{MaxStaleness: 10 * time.Second}
+
+	var requiresPrimary bool
+	serve := func(token string, write bool) *httptest.ResponseRecorder {
+		h := p.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requiresPrimary = RequiresPrimary(r.Context())
+			if write {
+				RecordWrite(r.Context())
+			}
+			_, _ = w.Write([]byte("{}"))
+		}))
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if token != "" {
+			r.Header.Set(TokenHeader, token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// Reads without tokens are served by replicas and carry no token.
+	w := serve("", false)
+	require.False(t, requiresPrimary)
+	require.Empty(t, w.Header().Get(TokenHeader))
+
+	// Writes carry a token following their commit.
+	before := time.Now()
+	w = serve("", true)
+	token := w.Header().Get(TokenHeader)
+	require.NotEmpty(t, token)
+	written, err := parseToken(token)
+	require.NoError(t, err)
+	require.False(t, written.Before(before))
+
+	// Reads with the recent token are served by the primary.
+	serve(token, false)
+	require.True(t, requiresPrimary)
+
+	// Reads with tokens older than the maximum staleness are served by
+	// replicas.
+	serve(strconv.FormatInt(time.Now().Add(-time.Minute).UnixNano(), 10), false)
+	require.False(t, requiresPrimary)
+
+	// Invalid tokens are rejected.
+	w = serve("yesterday", false)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOutsideRequests(t *testing.T) {
+	ctx := context.Background()
+	RecordWrite(ctx)
+	require.False(t, RequiresPrimary(ctx))
+}
@@ -0,0 +1,6 @@
+// Package consistency gives clients read-your-writes consistency across the
+// instances of a DSS pool whose reads are served by replicas. Responses to
+// requests which wrote to the database carry a token, which clients pass back
+// in later requests so that those read from the primary database until the
+// replicas are known to have caught up with the write.
+package consistency
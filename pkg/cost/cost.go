@@ -0,0 +1,129 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Header is the response header reporting the cost of the request, e.g.
+// "units=42, cells=12, statements=2, rows=10".
+const Header = "X-DSS-Request-Cost"
+
+// StatementUnits is the cost in units of a statement, against one unit per
+// cell and per row.
+const StatementUnits = 10
+
+var requestCost = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "dss_request_cost",
+	Help:    "Cost of requests to the database, by measure: units, cells, statements or rows.",
+	Buckets: prometheus.ExponentialBuckets(1, 2, 16),
+}, []string{"measure"})
+
+// Cost is the cost of a request.
+type Cost struct {
+	// Cells is the number of S2 cells covered by the request.
+	Cells int64
+	// Statements is the number of SQL statements executed.
+	Statements int64
+	// Rows is the number of rows returned or affected by the statements.
+	Rows int64
+}
+
+// Units returns c as a single number: one unit per cell and per row, and
+// StatementUnits per statement.
+func (c Cost) Units() int64 {
+	return c.Cells + c.Rows + StatementUnits*c.Statements
+}
+
+func (c Cost) String() string {
+	return fmt.Sprintf("units=%d, cells=%d, statements=%d, rows=%d", c.Units(), c.Cells, c.Statements, c.Rows)
+}
+
+// state is the cost of a single request as it is served.
+type state struct {
+	cells, statements, rows atomic.Int64
+}
+
+func (s *state) cost() Cost {
+	return Cost{Cells: s.cells.Load(), Statements: s.statements.Load(), Rows: s.rows.Load()}
+}
+
+type contextKey struct{}
+
+// Middleware returns an http.Handler accounting for the cost of the requests
+// to next, which it reports in the Header of their responses and in metrics.
+// Requests which did not reach the database carry no cost.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := &state{}
+		next.ServeHTTP(&responseWriter{ResponseWriter: w, state: s}, r.WithContext(context.WithValue(r.Context(), contextKey{}, s)))
+		if c := s.cost(); c != (Cost{}) {
+			requestCost.WithLabelValues("units").Observe(float64(c.Units()))
+			requestCost.WithLabelValues("cells").Observe(float64(c.Cells))
+			requestCost.WithLabelValues("statements").Observe(float64(c.Statements))
+			requestCost.WithLabelValues("rows").Observe(float64(c.Rows))
+		}
+	})
+}
+
+// responseWriter sets Header in the responses to requests with a cost.
+type responseWriter struct {
+	http.ResponseWriter
+	state       *state
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		if c := w.state.cost(); c != (Cost{}) {
+			w.Header().Set(Header, c.String())
+		}
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// AddCells accounts for n cells covered by the request of ctx.
+func AddCells(ctx context.Context, n int) {
+	if s, ok := ctx.Value(contextKey{}).(*state); ok {
+		s.cells.Add(int64(n))
+	}
+}
+
+// Of returns the cost of the request of ctx so far.
+func Of(ctx context.Context) Cost {
+	if s, ok := ctx.Value(contextKey{}).(*state); ok {
+		return s.cost()
+	}
+	return Cost{}
+}
+
+// QueryTracer is a pgx.QueryTracer accounting for the statements executed,
+// and the rows they return or affect, while serving requests.
+type QueryTracer struct{}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return ctx
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	if s, ok := ctx.Value(contextKey{}).(*state); ok {
+		s.statements.Add(1)
+		s.rows.Add(data.CommandTag.RowsAffected())
+	}
+}
@@ -0,0 +1,43 @@
+package cost
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	serve := func(f func(ctx context.Context)) *httptest.ResponseRecorder {
+		h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			f(r.Context())
+			_, _ = w.Write([]byte("{}"))
+		}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w
+	}
+
+	// Requests not reaching the database have no cost.
+	w := serve(func(context.Context) {})
+	require.Empty(t, w.Header().Get(Header))
+
+	w = serve(func(ctx context.Context) {
+		AddCells(ctx, 12)
+		QueryTracer{}.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 7")})
+		QueryTracer{}.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("UPDATE 3")})
+		require.Equal(t, Cost{Cells: 12, Statements: 2, Rows: 10}, Of(ctx))
+	})
+	require.Equal(t, "units=42, cells=12, statements=2, rows=10", w.Header().Get(Header))
+}
+
+func TestOutsideRequests(t *testing.T) {
+	ctx := context.Background()
+	AddCells(ctx, 1)
+	QueryTracer{}.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+	require.Equal(t, Cost{}, Of(ctx))
+}
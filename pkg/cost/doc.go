@@ -0,0 +1,6 @@
+// Package cost accounts for the rough cost of each request to the database:
+// the S2 cells it covers, the SQL statements it executes and the rows they
+// return or affect. The cost is reported to clients in the response header
+// and to operators in metrics, as feedback on query patterns and a basis for
+// fair-use policies.
+package cost
@@ -23,15 +23,22 @@ type (
 
 	// ConnectParameters bundles up parameters used for connecting to a CRDB instance.
 	ConnectParameters struct {
-		ApplicationName    string
-		Host               string
-		Port               int
-		DBName             string
+		ApplicationName string
+		Host            string
+		Port            int
+		DBName          string
+		// Schema, if set, is the schema holding the tables, which lets
+		// several deployments share a database.
+		Schema             string
 		Credentials        Credentials
 		SSL                SSL
 		MaxOpenConns       int
 		MaxConnIdleSeconds int
 		MaxRetries         int
+		// StatementCacheCapacity is the number of prepared statements
+		// cached per connection; statements are prepared on every
+		// execution if 0.
+		StatementCacheCapacity int
 	}
 )
 
@@ -84,6 +91,7 @@ func (cp ConnectParameters) BuildDSN() (string, error) {
 	dsnMap["application_name"] = an
 
 	dsnMap["dbname"] = cp.DBName
+	dsnMap["search_path"] = cp.Schema
 
 	sslMode := cp.SSL.Mode
 	if sslMode == "" {
@@ -10,6 +10,7 @@ func connectParametersFromMap(m map[string]string) ConnectParameters {
 	return ConnectParameters{
 		ApplicationName: m["application_name"],
 		DBName:          m["db_name"],
+		Schema:          m["schema"],
 		Host:            m["host"],
 		Port:            int(parseIntOrDefault(m["port"], 0)),
 		Credentials: Credentials{
@@ -91,6 +92,18 @@ func TestBuildDSN(t *testing.T) {
 			},
 			want: "application_name=dss host=localhost pool_max_conns=4 port=26257 sslmode=disable user=root",
 		},
+		{
+			name: "schema",
+			params: map[string]string{
+				"host":     "localhost",
+				"port":     "26257",
+				"user":     "root",
+				"ssl_mode": "disable",
+				"db_name":  "rid",
+				"schema":   "staging",
+			},
+			want: "application_name=dss dbname=rid host=localhost pool_max_conns=4 port=26257 search_path=staging sslmode=disable user=root",
+		},
 		{
 			name: "missing ssl_dir",
 			params: map[string]string{
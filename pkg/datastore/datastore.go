@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/coreos/go-semver/semver"
 	"github.com/interuss/stacktrace"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"time"
 )
@@ -16,6 +17,10 @@ type Datastore struct {
 
 var UnknownVersion = &semver.Version{}
 
+// QueryTracer, if set, traces the queries of the datastores dialed
+// afterwards.
+var QueryTracer pgx.QueryTracer
+
 func Dial(ctx context.Context, connParams ConnectParameters) (*Datastore, error) {
 	dsn, err := connParams.BuildDSN()
 	if err != nil {
@@ -32,8 +37,33 @@ func Dial(ctx context.Context, connParams ConnectParameters) (*Datastore, error)
 	}
 	config.MaxConns = int32(connParams.MaxOpenConns)
 	config.MaxConnIdleTime = (time.Duration(connParams.MaxConnIdleSeconds) * time.Second)
-	config.HealthCheckPeriod = (1 * time.Second)
 	config.MinConns = 1
+	config.ConnConfig.StatementCacheCapacity = connParams.StatementCacheCapacity
+	if connParams.StatementCacheCapacity <= 0 {
+		config.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeDescribeExec
+	}
+	return dial(ctx, config)
+}
+
+// DialURI connects to the database located by the postgres URI uri, whose
+// pool_* query parameters configure the pool of connections.
+func DialURI(ctx context.Context, uri string) (*Datastore, error) {
+	config, err := pgxpool.ParseConfig(uri)
+	if err != nil {
+		// The URI may hold credentials, so the error, which repeats it, is not.
+		return nil, stacktrace.NewError("Failed to parse datastore URI")
+	}
+	if config.MinConns == 0 {
+		config.MinConns = 1
+	}
+	return dial(ctx, config)
+}
+
+func dial(ctx context.Context, config *pgxpool.Config) (*Datastore, error) {
+	config.HealthCheckPeriod = (1 * time.Second)
+	if QueryTracer != nil {
+		config.ConnConfig.Tracer = QueryTracer
+	}
 
 	dbPool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
@@ -76,7 +106,7 @@ func fetchVersion(ctx context.Context, pool *pgxpool.Pool) (*Version, error) {
 }
 
 func (ds *Datastore) CreateDatabase(ctx context.Context, dbName string) error {
-	createDB := fmt.Sprintf("CREATE DATABASE %s", dbName)
+	createDB := fmt.Sprintf("CREATE DATABASE %s", pgx.Identifier{dbName}.Sanitize())
 	if _, err := ds.Pool.Exec(ctx, createDB); err != nil {
 		return stacktrace.Propagate(err, "failed to create new database %s", dbName)
 	}
@@ -97,6 +127,25 @@ func (ds *Datastore) DatabaseExists(ctx context.Context, dbName string) (bool, e
 	return exists, nil
 }
 
+// CreateSchema creates the schema named schemaName in the connected database
+// unless it already exists.
+func (ds *Datastore) CreateSchema(ctx context.Context, schemaName string) error {
+	createSchema := fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", pgx.Identifier{schemaName}.Sanitize())
+	if _, err := ds.Pool.Exec(ctx, createSchema); err != nil {
+		return stacktrace.Propagate(err, "failed to create schema %s", schemaName)
+	}
+	return nil
+}
+
+// Schema returns the name of the schema holding the tables of ds, which is
+// the one set by ConnectParameters.Schema or else public.
+func (ds *Datastore) Schema() string {
+	if schema := ds.Pool.Config().ConnConfig.RuntimeParams["search_path"]; schema != "" {
+		return schema
+	}
+	return "public"
+}
+
 // GetSchemaVersion returns the Schema Version of the requested DB Name
 func (ds *Datastore) GetSchemaVersion(ctx context.Context, dbName string) (*semver.Version, error) {
 	if dbName == "" {
@@ -117,7 +166,9 @@ func (ds *Datastore) GetSchemaVersion(ctx context.Context, dbName string) (*semv
           table_name = 'schema_versions'
         AND
           table_catalog = $1
-      )`, dbName)
+        AND
+          table_schema = $2
+      )`, pgx.Identifier{dbName}.Sanitize())
 		exists          bool
 		getVersionQuery = `
       SELECT
@@ -128,7 +179,7 @@ func (ds *Datastore) GetSchemaVersion(ctx context.Context, dbName string) (*semv
         onerow_enforcer = TRUE`
 	)
 
-	if err := ds.Pool.QueryRow(ctx, checkTableQuery, dbName).Scan(&exists); err != nil {
+	if err := ds.Pool.QueryRow(ctx, checkTableQuery, dbName, ds.Schema()).Scan(&exists); err != nil {
 		return nil, stacktrace.Propagate(err, "Error scanning table listing row")
 	}
 
@@ -7,6 +7,12 @@ import (
 
 var (
 	connectParameters datastore.ConnectParameters
+
+	readHost string
+	readPort int
+
+	ridDBName string
+	scdDBName string
 )
 
 // ConnectParameters returns a ConnectParameters instance that gets populated from well-known CLI flags.
@@ -14,15 +20,47 @@ func ConnectParameters() datastore.ConnectParameters {
 	return connectParameters
 }
 
+// ReadConnectParameters returns the ConnectParameters to use for read-only
+// queries and whether a distinct read endpoint was configured. When no read
+// host is set, the returned parameters are identical to ConnectParameters.
+func ReadConnectParameters() (datastore.ConnectParameters, bool) {
+	params := connectParameters
+	if readHost == "" {
+		return params, false
+	}
+	params.Host = readHost
+	if readPort != 0 {
+		params.Port = readPort
+	}
+	return params, true
+}
+
+// RIDDatabaseName returns the name of the database holding remote ID data.
+func RIDDatabaseName() string {
+	return ridDBName
+}
+
+// SCDDatabaseName returns the name of the database holding strategic
+// conflict detection data.
+func SCDDatabaseName() string {
+	return scdDBName
+}
+
 func init() {
 	flag.StringVar(&connectParameters.ApplicationName, "cockroach_application_name", "dss", "application name for tagging the connection to cockroach")
 	flag.StringVar(&connectParameters.DBName, "cockroach_db_name", "dss", "application name for tagging the connection to cockroach")
+	flag.StringVar(&ridDBName, "rid_db_name", "rid", "name of the database holding remote ID data, e.g. defaultdb for deployments predating schema 4.0.0")
+	flag.StringVar(&scdDBName, "scd_db_name", "scd", "name of the database holding strategic conflict detection data")
+	flag.StringVar(&connectParameters.Schema, "cockroach_schema", "", "schema holding the DSS tables in each database, which lets several DSS environments share a cluster; defaults to public")
 	flag.StringVar(&connectParameters.Host, "cockroach_host", "", "cockroach host to connect to")
 	flag.IntVar(&connectParameters.Port, "cockroach_port", 26257, "cockroach port to connect to")
+	flag.StringVar(&readHost, "cockroach_read_host", "", "cockroach host (or load balancer) to send non-transactional read queries to; defaults to cockroach_host")
+	flag.IntVar(&readPort, "cockroach_read_port", 0, "cockroach port to send non-transactional read queries to; defaults to cockroach_port")
 	flag.StringVar(&connectParameters.SSL.Mode, "cockroach_ssl_mode", "disable", "cockroach sslmode")
 	flag.StringVar(&connectParameters.SSL.Dir, "cockroach_ssl_dir", "", "directory to ssl certificates. Must contain files: ca.crt, client.<user>.crt, client.<user>.key")
 	flag.StringVar(&connectParameters.Credentials.Username, "cockroach_user", "root", "cockroach user to authenticate as")
 	flag.IntVar(&connectParameters.MaxOpenConns, "max_open_conns", 4, "maximum number of open connections to the database, default is 4")
 	flag.IntVar(&connectParameters.MaxConnIdleSeconds, "max_conn_idle_secs", 30, "maximum amount of time in seconds a connection may be idle, default is 30 seconds")
 	flag.IntVar(&connectParameters.MaxRetries, "cockroach_max_retries", 100, "maximum number of attempts to retry a query in case of contention, default is 100")
+	flag.IntVar(&connectParameters.StatementCacheCapacity, "cockroach_statement_cache_capacity", 512, "number of prepared statements cached per database connection, so that queries executed again on a connection skip parsing and planning; statements are prepared on every execution if 0, e.g. behind a connection pooler in transaction mode")
 }
@@ -0,0 +1,28 @@
+package flags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConnectParameters(t *testing.T) {
+	defer func(host string, port int) { readHost, readPort = host, port }(readHost, readPort)
+
+	readHost, readPort = "", 0
+	params, ok := ReadConnectParameters()
+	require.False(t, ok)
+	require.Equal(t, ConnectParameters(), params)
+
+	readHost = "crdb-read"
+	params, ok = ReadConnectParameters()
+	require.True(t, ok)
+	require.Equal(t, "crdb-read", params.Host)
+	require.Equal(t, ConnectParameters().Port, params.Port)
+	require.Equal(t, ConnectParameters().DBName, params.DBName)
+
+	readPort = 26258
+	params, ok = ReadConnectParameters()
+	require.True(t, ok)
+	require.Equal(t, 26258, params.Port)
+}
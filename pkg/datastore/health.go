@@ -0,0 +1,119 @@
+package datastore
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	"go.uber.org/zap"
+)
+
+// Health is a circuit breaker tracking the availability of a datastore by
+// pinging it periodically. After Threshold consecutive failed pings, the
+// datastore is considered unavailable and pings back off exponentially, up to
+// MaxInterval, until one succeeds. Requests received in the meantime fail
+// fast rather than piling up on a datastore that cannot serve them.
+type Health struct {
+	// Threshold is the number of consecutive failed pings after which the
+	// datastore is considered unavailable.
+	Threshold int
+	// Interval is the period of pings while the datastore is available.
+	Interval time.Duration
+	// MaxInterval bounds the period of pings while the datastore is
+	// unavailable.
+	MaxInterval time.Duration
+
+	mu       sync.RWMutex
+	failures int
+	nextPing time.Time
+}
+
+// Available returns whether the datastore is available and, if not, how long
+// until it is pinged again.
+func (h *Health) Available() (bool, time.Duration) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.failures < h.Threshold {
+		return true, 0
+	}
+	return false, time.Until(h.nextPing)
+}
+
+// interval returns how long to wait before the next ping after failures
+// consecutive failed pings.
+func (h *Health) interval(failures int) time.Duration {
+	if failures < h.Threshold {
+		return h.Interval
+	}
+	backoff := float64(h.Interval) * math.Pow(2, float64(failures-h.Threshold+1))
+	if backoff > float64(h.MaxInterval) {
+		return h.MaxInterval
+	}
+	return time.Duration(backoff)
+}
+
+// record accounts for the outcome of a ping and returns how long to wait
+// before the next one.
+func (h *Health) record(err error, logger *zap.Logger) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	wasAvailable := h.failures < h.Threshold
+	if err == nil {
+		if !wasAvailable {
+			logger.Info("Datastore available again", zap.Int("failed_pings", h.failures))
+		}
+		h.failures = 0
+	} else {
+		h.failures++
+		if wasAvailable && h.failures >= h.Threshold {
+			logger.Warn("Datastore unavailable; failing requests until it recovers", zap.Int("failed_pings", h.failures), zap.Error(err))
+		}
+	}
+	wait := h.interval(h.failures)
+	h.nextPing = time.Now().Add(wait)
+	return wait
+}
+
+// Monitor pings the datastore with ping until ctx is done, each ping being
+// given at most Interval to succeed.
+func (h *Health) Monitor(ctx context.Context, ping func(context.Context) error, logger *zap.Logger) {
+	for {
+		pingCtx, cancel := context.WithTimeout(ctx, h.Interval)
+		err := ping(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		timer := time.NewTimer(h.record(err, logger))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Middleware returns an http.Handler responding to requests with 503 Service
+// Unavailable and a Retry-After header while the datastore is unavailable,
+// and passing them to next otherwise.
+func (h *Health) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		available, retryAfter := h.Available()
+		if available {
+			next.ServeHTTP(w, r)
+			return
+		}
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		api.WriteJSON(w, http.StatusServiceUnavailable, map[string]string{
+			"message": "The datastore is currently unavailable; retry later"})
+	})
+}
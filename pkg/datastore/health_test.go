@@ -0,0 +1,91 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var errPing = errors.New("ping failed")
+
+func TestHealthTransitions(t *testing.T) {
+	h := &Health{Threshold: 2, Interval: time.Second, MaxInterval: 5 * time.Second}
+	logger := zap.NewNop()
+
+	available, _ := h.Available()
+	require.True(t, available)
+
+	require.Equal(t, time.Second, h.record(errPing, logger))
+	available, _ = h.Available()
+	require.True(t, available)
+
+	require.Equal(t, 2*time.Second, h.record(errPing, logger))
+	available, retryAfter := h.Available()
+	require.False(t, available)
+	require.InDelta(t, 2*time.Second, retryAfter, float64(time.Second))
+
+	require.Equal(t, 4*time.Second, h.record(errPing, logger))
+	require.Equal(t, 5*time.Second, h.record(errPing, logger))
+	require.Equal(t, 5*time.Second, h.record(errPing, logger))
+
+	require.Equal(t, time.Second, h.record(nil, logger))
+	available, _ = h.Available()
+	require.True(t, available)
+}
+
+func TestHealthMiddleware(t *testing.T) {
+	h := &Health{Threshold: 1, Interval: time.Second, MaxInterval: time.Minute}
+	handler := h.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	h.record(errPing, zap.NewNop())
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, "2", w.Header().Get("Retry-After"))
+}
+
+func TestHealthMonitor(t *testing.T) {
+	h := &Health{Threshold: 1, Interval: time.Millisecond, MaxInterval: time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	failing := make(chan bool, 1)
+	failing <- true
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.Monitor(ctx, func(context.Context) error {
+			if <-failing {
+				failing <- true
+				return errPing
+			}
+			failing <- false
+			return nil
+		}, zap.NewNop())
+	}()
+
+	require.Eventually(t, func() bool {
+		available, _ := h.Available()
+		return !available
+	}, time.Second, time.Millisecond)
+
+	<-failing
+	failing <- false
+	require.Eventually(t, func() bool {
+		available, _ := h.Available()
+		return available
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,4 @@
+// Package owners transforms the owner identifiers stored in the database, so
+// that deployments with strict data handling requirements can keep database
+// dumps from revealing the identity of USSs.
+package owners
@@ -0,0 +1,99 @@
+package owners
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/stacktrace"
+)
+
+const (
+	// MinKeySize is the minimum size in bytes of the keys of Sealed.
+	MinKeySize = 32
+
+	sealedPrefix = "sealed:"
+)
+
+// Codec transforms owners into the values stored in the database and back.
+// Encode must be deterministic so that entities can be searched by owner.
+type Codec interface {
+	Encode(owner dssmodels.Owner) string
+	Decode(stored string) (dssmodels.Owner, error)
+}
+
+// Plain stores owners as they are.
+type Plain struct{}
+
+// Encode implements Codec.
+func (Plain) Encode(owner dssmodels.Owner) string {
+	return owner.String()
+}
+
+// Decode implements Codec.
+func (Plain) Decode(stored string) (dssmodels.Owner, error) {
+	return dssmodels.Owner(stored), nil
+}
+
+// Sealed stores owners encrypted deterministically with AES-GCM, the nonce of
+// an owner being derived from the owner with HMAC-SHA256 so that an owner is
+// always stored as the same value. Values not encrypted, e.g. stored before
+// owners were sealed, are decoded as they are.
+type Sealed struct {
+	aead   cipher.AEAD
+	macKey []byte
+}
+
+// NewSealed returns a Sealed codec deriving its encryption and nonce keys from
+// key, which must be at least MinKeySize bytes long.
+func NewSealed(key []byte) (*Sealed, error) {
+	if len(key) < MinKeySize {
+		return nil, stacktrace.NewError("Owner encryption key must be at least %d bytes long, got %d", MinKeySize, len(key))
+	}
+	block, err := aes.NewCipher(deriveKey(key, "owner encryption"))
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error creating owner cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error creating owner AEAD")
+	}
+	return &Sealed{aead: aead, macKey: deriveKey(key, "owner nonce")}, nil
+}
+
+func deriveKey(key []byte, label string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
+
+// Encode implements Codec.
+func (s *Sealed) Encode(owner dssmodels.Owner) string {
+	mac := hmac.New(sha256.New, s.macKey)
+	mac.Write([]byte(owner))
+	nonce := mac.Sum(nil)[:s.aead.NonceSize()]
+	sealed := s.aead.Seal(nonce, nonce, []byte(owner), nil)
+	return sealedPrefix + base64.RawURLEncoding.EncodeToString(sealed)
+}
+
+// Decode implements Codec.
+func (s *Sealed) Decode(stored string) (dssmodels.Owner, error) {
+	encoded, ok := strings.CutPrefix(stored, sealedPrefix)
+	if !ok {
+		return dssmodels.Owner(stored), nil
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return "", stacktrace.NewError("Malformed sealed owner")
+	}
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	owner, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "Error opening sealed owner; was the owner encryption key changed?")
+	}
+	return dssmodels.Owner(owner), nil
+}
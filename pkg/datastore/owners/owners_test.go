@@ -0,0 +1,53 @@
+package owners
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+var _ Codec = Plain{}
+var _ Codec = &Sealed{}
+
+func TestPlain(t *testing.T) {
+	require.Equal(t, "uss1", Plain{}.Encode("uss1"))
+	owner, err := Plain{}.Decode("uss1")
+	require.NoError(t, err)
+	require.Equal(t, dssmodels.Owner("uss1"), owner)
+}
+
+func TestNewSealedRequiresLongKey(t *testing.T) {
+	_, err := NewSealed(bytes.Repeat([]byte{1}, MinKeySize-1))
+	require.Error(t, err)
+}
+
+func TestSealed(t *testing.T) {
+	codec, err := NewSealed(bytes.Repeat([]byte{1}, MinKeySize))
+	require.NoError(t, err)
+
+	stored := codec.Encode("uss1")
+	require.NotContains(t, stored, "uss1")
+	require.True(t, strings.HasPrefix(stored, sealedPrefix))
+	// Owners are always stored as the same value so that they can be searched.
+	require.Equal(t, stored, codec.Encode("uss1"))
+	require.NotEqual(t, stored, codec.Encode("uss2"))
+
+	owner, err := codec.Decode(stored)
+	require.NoError(t, err)
+	require.Equal(t, dssmodels.Owner("uss1"), owner)
+
+	// Owners stored before they were sealed are decoded as they are.
+	owner, err = codec.Decode("uss3")
+	require.NoError(t, err)
+	require.Equal(t, dssmodels.Owner("uss3"), owner)
+
+	other, err := NewSealed(bytes.Repeat([]byte{2}, MinKeySize))
+	require.NoError(t, err)
+	_, err = other.Decode(stored)
+	require.Error(t, err)
+	_, err = codec.Decode(sealedPrefix + "!")
+	require.Error(t, err)
+}
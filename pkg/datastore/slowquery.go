@@ -0,0 +1,168 @@
+package datastore
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/interuss/dss/pkg/logging"
+	dssql "github.com/interuss/dss/pkg/sql"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// maxSlowQueryIDs bounds the number of IDs logged with a slow query.
+const maxSlowQueryIDs = 10
+
+// QueryTracers is a pgx.QueryTracer calling each of its tracers in order, e.g.
+// to set several of them as QueryTracer.
+type QueryTracers []pgx.QueryTracer
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (ts QueryTracers) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	for _, t := range ts {
+		ctx = t.TraceQueryStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (ts QueryTracers) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	for _, t := range ts {
+		t.TraceQueryEnd(ctx, conn, data)
+	}
+}
+
+// SlowQueryTracer is a pgx.QueryTracer logging the queries taking at least
+// Threshold, with the fields of the request they serve, so that slowness in
+// production can be diagnosed without enabling the logging of the database.
+// Queries are logged by name, with the IDs among their arguments, but neither
+// their full text nor their other arguments, e.g. geometries or owners.
+type SlowQueryTracer struct {
+	Threshold time.Duration
+	Logger    *zap.Logger
+}
+
+type slowQueryKey struct{}
+
+// tracedQuery is a query whose duration is traced.
+type tracedQuery struct {
+	start time.Time
+	sql   string
+	args  []any
+}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (t *SlowQueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, slowQueryKey{}, &tracedQuery{start: time.Now(), sql: data.SQL, args: data.Args})
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (t *SlowQueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	q, ok := ctx.Value(slowQueryKey{}).(*tracedQuery)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(q.start)
+	if elapsed < t.Threshold {
+		return
+	}
+	fields := []zap.Field{
+		zap.String("query", queryName(q.sql)),
+		zap.Duration("duration", elapsed),
+		zap.Strings("ids", queryIDs(q.args)),
+		zap.Int("args", len(q.args)),
+		zap.Int64("rows", data.CommandTag.RowsAffected()),
+		zap.Int("tx_retries", dssql.TxRetries(ctx)),
+	}
+	if data.Err != nil {
+		fields = append(fields, zap.Error(data.Err))
+	}
+	logging.WithValuesFromContext(ctx, t.Logger).Warn("Slow query", fields...)
+}
+
+// queryName names query after its statement and the tables it refers to, e.g.
+// "DELETE subscriptions", which tells the queries of the store apart without
+// logging their full text.
+func queryName(query string) string {
+	var (
+		words     = strings.Fields(query)
+		statement string
+		tables    []string
+	)
+	for i, word := range words {
+		keyword := strings.ToUpper(word)
+		switch keyword {
+		case "SELECT", "INSERT", "UPSERT", "UPDATE", "DELETE":
+			if statement == "" {
+				statement = keyword
+			}
+		}
+		switch keyword {
+		case "FROM", "INTO", "UPDATE", "JOIN":
+			if i+1 < len(words) {
+				if table := strings.TrimRight(words[i+1], ",;)"); isTableName(table) && !contains(tables, table) {
+					tables = append(tables, table)
+				}
+			}
+		}
+	}
+	if statement == "" && len(words) > 0 {
+		statement = strings.ToUpper(words[0])
+	}
+	if len(tables) == 0 {
+		return statement
+	}
+	return statement + " " + strings.Join(tables, ",")
+}
+
+// isTableName returns whether s is the lowercase name of a table, as opposed
+// to a subquery, a function call or a keyword.
+func isTableName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// queryIDs returns the UUIDs among args, including those in slices, up to
+// maxSlowQueryIDs.
+func queryIDs(args []any) []string {
+	ids := []string{}
+	add := func(v reflect.Value) {
+		if v.Kind() != reflect.String || len(ids) >= maxSlowQueryIDs {
+			return
+		}
+		if _, err := uuid.Parse(v.String()); err == nil && len(v.String()) == 36 {
+			ids = append(ids, v.String())
+		}
+	}
+	for _, arg := range args {
+		v := reflect.ValueOf(arg)
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				add(v.Index(i))
+			}
+		default:
+			add(v)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,79 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/interuss/dss/pkg/logging"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestQueryName(t *testing.T) {
+	for query, want := range map[string]string{
+		`SELECT id, owner FROM identification_service_areas WHERE id = $1`: "SELECT identification_service_areas",
+		`
+		INSERT INTO
+		  subscriptions (id, owner)
+		VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET owner = $2`: "INSERT subscriptions",
+		`UPDATE subscriptions SET notification_index = notification_index + 1 WHERE cells && $1`: "UPDATE subscriptions",
+		`DELETE FROM subscriptions WHERE id = $1 RETURNING id`:                                   "DELETE subscriptions",
+		`SELECT count(*) FROM (SELECT id FROM subscriptions) AS s JOIN cells ON true`:            "SELECT subscriptions,cells",
+		`SAVEPOINT cockroach_restart`:                                                            "SAVEPOINT",
+	} {
+		require.Equal(t, want, queryName(query), query)
+	}
+}
+
+func TestQueryIDs(t *testing.T) {
+	type id string
+	ids := queryIDs([]any{
+		id("4348c8e5-0b1c-43cf-9114-2e67a4532765"),
+		"uss1",
+		[]int64{1, 2},
+		[]string{"00000000-0000-4000-8000-000000000001", "not-an-id"},
+		nil,
+	})
+	require.Equal(t, []string{"4348c8e5-0b1c-43cf-9114-2e67a4532765", "00000000-0000-4000-8000-000000000001"}, ids)
+
+	many := make([]string, 2*maxSlowQueryIDs)
+	for i := range many {
+		many[i] = "00000000-0000-4000-8000-000000000001"
+	}
+	require.Len(t, queryIDs([]any{many}), maxSlowQueryIDs)
+}
+
+func TestSlowQueryTracer(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	tracer := QueryTracers{&SlowQueryTracer{Threshold: 20 * time.Millisecond, Logger: zap.New(core)}}
+	ctx := logging.NewContext(context.Background())
+	logging.WithFields(ctx, zap.String("req_id", "r1"))
+	query := pgx.TraceQueryStartData{
+		SQL:  "SELECT * FROM subscriptions WHERE id = $1 AND cells && $2",
+		Args: []any{"4348c8e5-0b1c-43cf-9114-2e67a4532765", []int64{1, 2, 3}},
+	}
+
+	// Fast queries are not logged.
+	tracer.TraceQueryEnd(tracer.TraceQueryStart(ctx, nil, query), nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+	require.Zero(t, logs.Len())
+
+	queryCtx := tracer.TraceQueryStart(ctx, nil, query)
+	time.Sleep(30 * time.Millisecond)
+	tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1"), Err: errors.New("failed")})
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	require.Equal(t, "SELECT subscriptions", fields["query"])
+	require.Equal(t, []interface{}{"4348c8e5-0b1c-43cf-9114-2e67a4532765"}, fields["ids"])
+	require.Equal(t, int64(2), fields["args"])
+	require.Equal(t, int64(1), fields["rows"])
+	require.Equal(t, int64(0), fields["tx_retries"])
+	require.Equal(t, "r1", fields["req_id"])
+	require.Equal(t, "failed", fields["error"])
+	require.GreaterOrEqual(t, fields["duration"], 20*time.Millisecond)
+}
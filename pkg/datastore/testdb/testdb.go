@@ -0,0 +1,205 @@
+// Package testdb provides the database used by store tests: either the one
+// designated by the --cockroach_* flags or, when the DSS_TEST_AUTO_DB
+// environment variable is set to true, a single-node CockroachDB container
+// started on demand, migrated to the latest schemas and removed once the tests
+// complete.
+package testdb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/interuss/dss/pkg/datastore"
+	"github.com/interuss/dss/pkg/datastore/flags"
+	"github.com/interuss/stacktrace"
+)
+
+const (
+	// AutoDBEnvVar is the environment variable that enables the automatic
+	// provisioning of a CockroachDB container for tests.
+	AutoDBEnvVar = "DSS_TEST_AUTO_DB"
+
+	// ImageEnvVar may be set to override the CockroachDB image used.
+	ImageEnvVar = "DSS_TEST_AUTO_DB_IMAGE"
+
+	defaultImage   = "cockroachdb/cockroach:v24.1.3"
+	startupTimeout = 60 * time.Second
+)
+
+var (
+	once        sync.Once
+	containerID string
+	autoParams  datastore.ConnectParameters
+	autoErr     error
+
+	upToRegexp = regexp.MustCompile(`^upto-v(\d+\.\d+\.\d+)-.*\.sql$`)
+)
+
+// Main runs the tests of a package and removes the CockroachDB container
+// afterwards if one was started. It is intended to be called from TestMain.
+func Main(m *testing.M) {
+	code := m.Run()
+	if containerID != "" {
+		if out, err := exec.Command("docker", "rm", "-f", containerID).CombinedOutput(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to remove test CockroachDB container %s: %v: %s\n", containerID, err, out)
+		}
+	}
+	os.Exit(code)
+}
+
+// Enabled returns true if a test database is configured, either through the
+// --cockroach_host flag or DSS_TEST_AUTO_DB set to a true value as parsed by
+// strconv.ParseBool; any other value of DSS_TEST_AUTO_DB disables it.
+func Enabled() bool {
+	params := flags.ConnectParameters()
+	if params.Host != "" && params.Port != 0 {
+		return true
+	}
+	enabled, err := strconv.ParseBool(os.Getenv(AutoDBEnvVar))
+	return err == nil && enabled
+}
+
+// ConnectParameters returns the parameters to connect to the test database
+// dbName. If no test database is configured, t is skipped.
+func ConnectParameters(t testing.TB, dbName string) datastore.ConnectParameters {
+	t.Helper()
+
+	if !Enabled() {
+		t.Skipf("No database available: set --cockroach_host or %s", AutoDBEnvVar)
+	}
+	params := flags.ConnectParameters()
+	if params.Host != "" && params.Port != 0 {
+		params.DBName = dbName
+		return params
+	}
+
+	once.Do(func() {
+		autoParams, autoErr = startCockroach(context.Background())
+	})
+	if autoErr != nil {
+		t.Fatalf("Failed to provision test database: %v", autoErr)
+	}
+	params = autoParams
+	params.DBName = dbName
+	return params
+}
+
+// startCockroach runs a single-node CockroachDB container, waits for it to
+// accept connections and migrates the rid and scd databases to their latest
+// versions.
+func startCockroach(ctx context.Context) (datastore.ConnectParameters, error) {
+	image := os.Getenv(ImageEnvVar)
+	if image == "" {
+		image = defaultImage
+	}
+
+	out, err := exec.CommandContext(ctx, "docker", "run", "-d", "--rm", "-p", "127.0.0.1::26257",
+		image, "start-single-node", "--insecure").Output()
+	if err != nil {
+		return datastore.ConnectParameters{}, stacktrace.Propagate(err, "Error starting CockroachDB container from %s", image)
+	}
+	containerID = strings.TrimSpace(string(out))
+
+	out, err = exec.CommandContext(ctx, "docker", "port", containerID, "26257/tcp").Output()
+	if err != nil {
+		return datastore.ConnectParameters{}, stacktrace.Propagate(err, "Error getting CockroachDB container port")
+	}
+	hostPort := strings.Split(strings.TrimSpace(string(out)), "\n")[0]
+	port, err := strconv.Atoi(hostPort[strings.LastIndex(hostPort, ":")+1:])
+	if err != nil {
+		return datastore.ConnectParameters{}, stacktrace.Propagate(err, "Error parsing CockroachDB container port `%s`", hostPort)
+	}
+
+	params := flags.ConnectParameters()
+	params.Host = "127.0.0.1"
+	params.Port = port
+	params.SSL.Mode = "disable"
+	params.Credentials.Username = "root"
+	params.DBName = "postgres"
+
+	var ds *datastore.Datastore
+	deadline := time.Now().Add(startupTimeout)
+	for {
+		ds, err = datastore.Dial(ctx, params)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return datastore.ConnectParameters{}, stacktrace.Propagate(err, "CockroachDB container did not become ready within %s", startupTimeout)
+		}
+		time.Sleep(time.Second)
+	}
+	defer ds.Pool.Close()
+
+	for _, dbName := range []string{"rid", "scd"} {
+		if err := migrate(ctx, ds, dbName); err != nil {
+			return datastore.ConnectParameters{}, stacktrace.Propagate(err, "Error migrating %s database", dbName)
+		}
+	}
+	return params, nil
+}
+
+// schemasDir returns the directory holding the migration files of dbName.
+func schemasDir(dbName string) string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "..", "build", "db_schemas", dbName)
+}
+
+// migrate creates dbName and applies all of its upgrade steps in order.
+func migrate(ctx context.Context, ds *datastore.Datastore, dbName string) error {
+	// The rid schema starts out in defaultdb, which always exists, and renames
+	// it to rid when upgrading to 4.0.0.
+	if dbName != "rid" {
+		if err := ds.CreateDatabase(ctx, dbName); err != nil {
+			return stacktrace.Propagate(err, "Error creating database")
+		}
+	}
+
+	dir := schemasDir(dbName)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return stacktrace.Propagate(err, "Error reading schema files directory %s", dir)
+	}
+	type step struct {
+		version *semver.Version
+		file    string
+	}
+	var steps []step
+	for _, f := range files {
+		if match := upToRegexp.FindStringSubmatch(f.Name()); match != nil {
+			steps = append(steps, step{version: semver.New(match[1]), file: f.Name()})
+		}
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].version.LessThan(*steps[j].version) })
+
+	for _, s := range steps {
+		sql, err := os.ReadFile(filepath.Join(dir, s.file))
+		if err != nil {
+			return stacktrace.Propagate(err, "Error reading %s", s.file)
+		}
+		// Versions up to and including 4.0.0 of the rid schema are applied to
+		// defaultdb, which the 4.0.0 step renames to rid.
+		target := dbName
+		if dbName == "rid" && !semver.New("4.0.0").LessThan(*s.version) {
+			target = "defaultdb"
+		}
+		stmt := "SET enable_implicit_transaction_for_batch_statements = false;\n" +
+			fmt.Sprintf("USE %s;\n", target) + string(sql)
+		if _, err := ds.Pool.Exec(ctx, stmt); err != nil {
+			return stacktrace.Propagate(err, "Error applying %s", s.file)
+		}
+	}
+	return nil
+}
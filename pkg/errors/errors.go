@@ -42,6 +42,10 @@ const (
 
 	// Unauthenticated is used when an OAuth token is invalid or not supplied.
 	Unauthenticated
+
+	// StaleVersion is used when deleting a resource with a version other than
+	// its current one, e.g. because it changed since the client read it.
+	StaleVersion
 )
 
 func init() {
@@ -83,6 +87,6 @@ func Handle(ctx context.Context, err error) *string {
 		logger.Error("Uncoded error during unary server call")
 	}
 
-	errMsg := fmt.Sprintf("%s (%s)", rootErr.Error(), errID)
+	errMsg := fmt.Sprintf("%s (%s)", translate(ctx, rootErr.Error()), errID)
 	return &errMsg
 }
@@ -0,0 +1,178 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/interuss/stacktrace"
+	"golang.org/x/text/language"
+)
+
+// verbPattern matches the formatting verbs of the messages of errors, e.g. %s
+// or %[2]v, and escaped percent signs.
+var verbPattern = regexp.MustCompile(`%%|%(\[\d+\])?[-+# 0]*\d*(\.\d+)?[a-zA-Z]`)
+
+// translation turns a message formatted from an English template into a
+// language other than English.
+type translation struct {
+	// pattern matches the messages formatted from the English template and
+	// captures their arguments.
+	pattern *regexp.Regexp
+	// format is the translated template, with the arguments as strings.
+	format string
+}
+
+// Messages translates the messages of the errors returned to clients into the
+// languages they accept in their Accept-Language header.  Messages are in
+// English unless a catalog translates them.
+type Messages struct {
+	matcher language.Matcher
+	// translations are indexed like the tags of matcher, English first.
+	translations [][]translation
+}
+
+// LoadMessages returns the Messages of the catalog at path, a JSON object
+// mapping BCP 47 language tags to objects mapping English message templates,
+// e.g. "ISA %s not found", to their translation, e.g. "ISA %s introuvable".
+func LoadMessages(path string) (*Messages, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error reading error message catalog")
+	}
+	var catalog map[string]map[string]string
+	if err := json.Unmarshal(content, &catalog); err != nil {
+		return nil, stacktrace.Propagate(err, "Error parsing error message catalog")
+	}
+	return NewMessages(catalog)
+}
+
+// NewMessages returns the Messages translating the English message templates
+// of catalog, by language tag.
+func NewMessages(catalog map[string]map[string]string) (*Messages, error) {
+	locales := make([]string, 0, len(catalog))
+	for locale := range catalog {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	m := &Messages{translations: [][]translation{nil}}
+	tags := []language.Tag{language.English}
+	for _, locale := range locales {
+		tag, err := language.Parse(locale)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Invalid language tag %s in error message catalog", locale)
+		}
+		if tag == language.English {
+			return nil, stacktrace.NewError("Error messages are already in %s", locale)
+		}
+		templates := make([]string, 0, len(catalog[locale]))
+		for template := range catalog[locale] {
+			templates = append(templates, template)
+		}
+		// Longer templates are more specific, so they are tried first.
+		sort.Slice(templates, func(i, j int) bool {
+			if len(templates[i]) != len(templates[j]) {
+				return len(templates[i]) > len(templates[j])
+			}
+			return templates[i] < templates[j]
+		})
+		var translations []translation
+		for _, template := range templates {
+			t, err := newTranslation(template, catalog[locale][template])
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "Invalid %s translation of `%s`", locale, template)
+			}
+			translations = append(translations, t)
+		}
+		tags = append(tags, tag)
+		m.translations = append(m.translations, translations)
+	}
+	m.matcher = language.NewMatcher(tags)
+	return m, nil
+}
+
+func newTranslation(template, translated string) (translation, error) {
+	var (
+		pattern strings.Builder
+		args    int
+		last    int
+	)
+	pattern.WriteString("^")
+	for _, loc := range verbPattern.FindAllStringIndex(template, -1) {
+		pattern.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		if template[loc[0]:loc[1]] == "%%" {
+			pattern.WriteString("%")
+		} else {
+			pattern.WriteString("(.*?)")
+			args++
+		}
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(template[last:]))
+	pattern.WriteString("$")
+
+	// The arguments are captured as strings, so every verb formats strings.
+	format := verbPattern.ReplaceAllStringFunc(translated, func(verb string) string {
+		if verb == "%%" {
+			return verb
+		}
+		if match := verbPattern.FindStringSubmatch(verb); match[1] != "" {
+			return "%" + match[1] + "s"
+		}
+		return "%s"
+	})
+	placeholders := make([]interface{}, args)
+	for i := range placeholders {
+		placeholders[i] = ""
+	}
+	if strings.Contains(fmt.Sprintf(format, placeholders...), "%!") {
+		return translation{}, stacktrace.NewError("Translation `%s` does not use the %d arguments of the template", translated, args)
+	}
+	return translation{pattern: regexp.MustCompile(pattern.String()), format: format}, nil
+}
+
+type messagesKey struct{}
+
+// Middleware returns an http.Handler making the translations of m into the
+// language preferred by the Accept-Language header of each request available
+// to Handle.  A nil m leaves messages in English.
+func (m *Messages) Middleware(next http.Handler) http.Handler {
+	if m == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accepted := r.Header.Get("Accept-Language"); accepted != "" {
+			tags, _, err := language.ParseAcceptLanguage(accepted)
+			if err == nil && len(tags) > 0 {
+				if _, i, confidence := m.matcher.Match(tags...); confidence != language.No && i > 0 {
+					r = r.WithContext(context.WithValue(r.Context(), messagesKey{}, m.translations[i]))
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// translate returns msg in the language of the request of ctx, or unchanged
+// if the catalog does not translate it.
+func translate(ctx context.Context, msg string) string {
+	translations, _ := ctx.Value(messagesKey{}).([]translation)
+	for _, t := range translations {
+		match := t.pattern.FindStringSubmatch(msg)
+		if match == nil {
+			continue
+		}
+		args := make([]interface{}, len(match)-1)
+		for i, arg := range match[1:] {
+			args[i] = arg
+		}
+		return fmt.Sprintf(t.format, args...)
+	}
+	return msg
+}
@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessagesTranslate(t *testing.T) {
+	m, err := NewMessages(map[string]map[string]string{
+		"fr": {
+			"Missing access token":                        "Jeton d'accès manquant",
+			"ISA %s not found":                            "ISA %s introuvable",
+			"Area of %.1f km² exceeds %v km²":             "La zone de %[1]v km² dépasse %[2]v km²",
+			"Altitude %v is below the minimum of %v (%%)": "L'altitude %v est sous le minimum de %v (%%)",
+		},
+		"de": {
+			"ISA %s not found": "ISA %s nicht gefunden",
+		},
+	})
+	require.NoError(t, err)
+
+	handle := func(acceptLanguage string, err error) string {
+		var msg string
+		h := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			msg = *Handle(r.Context(), err)
+		}))
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if acceptLanguage != "" {
+			r.Header.Set("Accept-Language", acceptLanguage)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		// Strip the error ID.
+		return msg[:strings.LastIndex(msg, " (E:")]
+	}
+
+	notFound := stacktrace.Propagate(stacktrace.NewErrorWithCode(NotFound, "ISA %s not found", "a-b-c"), "Could not get ISA")
+	require.Equal(t, "ISA a-b-c not found", handle("", notFound))
+	require.Equal(t, "ISA a-b-c introuvable", handle("fr-CA,fr;q=0.9,en;q=0.5", notFound))
+	require.Equal(t, "ISA a-b-c nicht gefunden", handle("de", notFound))
+	require.Equal(t, "ISA a-b-c not found", handle("ja", notFound))
+	require.Equal(t, "ISA a-b-c not found", handle("en-US,fr;q=0.5", notFound))
+	require.Equal(t, "Jeton d'accès manquant", handle("fr", stacktrace.NewErrorWithCode(Unauthenticated, "Missing access token")))
+	require.Equal(t, "La zone de 2500.0 km² dépasse 2500 km²", handle("fr", stacktrace.NewErrorWithCode(AreaTooLarge, "Area of %.1f km² exceeds %v km²", 2500.0, 2500)))
+	require.Equal(t, "L'altitude -10 est sous le minimum de 0 (%)", handle("fr", stacktrace.NewErrorWithCode(BadRequest, "Altitude %v is below the minimum of %v (%%)", -10, 0)))
+	require.Equal(t, "Untranslated", handle("fr", stacktrace.NewErrorWithCode(BadRequest, "Untranslated")))
+}
+
+func TestNilMessages(t *testing.T) {
+	var m *Messages
+	next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	require.NotNil(t, m.Middleware(next))
+	require.Equal(t, "message", translate(context.Background(), "message"))
+}
+
+func TestLoadMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"fr": {"ISA %s not found": "ISA %s introuvable"}}`), 0600))
+	_, err := LoadMessages(path)
+	require.NoError(t, err)
+
+	for _, catalog := range []map[string]map[string]string{
+		{"not a tag!": {"a": "b"}},
+		{"en": {"a": "b"}},
+		{"fr": {"ISA %s not found": "ISA introuvable"}},
+		{"fr": {"ISA %s not found": "ISA %s de %s introuvable"}},
+	} {
+		_, err := NewMessages(catalog)
+		require.Error(t, err, catalog)
+	}
+}
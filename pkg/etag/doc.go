@@ -0,0 +1,6 @@
+// Package etag supports conditional HTTP requests: GET responses carry an
+// entity tag derived from their content, which includes the versions of the
+// entities represented, so that polling clients can revalidate them with
+// If-None-Match, and writes can be made conditional on the current state of
+// the entity with If-Match.
+package etag
@@ -0,0 +1,148 @@
+package etag
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/interuss/dss/pkg/api"
+)
+
+// entityPathPattern matches the path of the entity targeted by a request, up
+// to the UUID identifying it and excluding the version or OVN which may
+// follow.
+var entityPathPattern = regexp.MustCompile(`^.*/[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+// Of returns the strong entity tag of a response body.
+func Of(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// matches returns whether the value of an If-Match or If-None-Match header
+// lists tag, or is *. Weak tags in header only match if weak is set, as
+// If-None-Match compares tags weakly and If-Match strongly.
+func matches(header, tag string, weak bool) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if weak {
+			t = strings.TrimPrefix(t, "W/")
+		}
+		if t == "*" || t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// recorder buffers a response.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newRecorder() *recorder {
+	return &recorder{header: http.Header{}, status: http.StatusOK}
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+// copyTo writes the buffered response to w.
+func (r *recorder) copyTo(w http.ResponseWriter) {
+	for k, v := range r.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(r.status)
+	_, _ = w.Write(r.body.Bytes())
+}
+
+// Middleware returns an http.Handler supporting conditional requests to next:
+//   - Successful GET responses carry an ETag header. They are replaced with 304
+//     Not Modified when the request's If-None-Match header lists that tag.
+//   - Other requests with an If-Match header, i.e. writes, are passed to next
+//     only if the header lists the tag of the entity targeted, as returned by a
+//     GET of the path up to the entity ID with the same credentials, and are
+//     rejected with 412 Precondition Failed otherwise.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet:
+			serveGet(next, w, r)
+		case r.Header.Get("If-Match") != "":
+			serveConditionalWrite(next, w, r)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+func serveGet(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	rec := newRecorder()
+	next.ServeHTTP(rec, r)
+	if rec.status != http.StatusOK {
+		rec.copyTo(w)
+		return
+	}
+	tag := Of(rec.body.Bytes())
+	rec.header.Set("ETag", tag)
+	if inm := r.Header.Get("If-None-Match"); inm != "" && matches(inm, tag, true) {
+		for _, k := range []string{"ETag", "Cache-Control", "Vary"} {
+			if v := rec.header.Values(k); len(v) > 0 {
+				w.Header()[http.CanonicalHeaderKey(k)] = v
+			}
+		}
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	rec.copyTo(w)
+}
+
+func serveConditionalWrite(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	path := entityPathPattern.FindString(r.URL.Path)
+	if path == "" {
+		// Not a request about a single entity, which cannot be tagged.
+		next.ServeHTTP(w, r)
+		return
+	}
+	get := r.Clone(r.Context())
+	get.Method = http.MethodGet
+	get.URL.Path = path
+	get.URL.RawPath = ""
+	get.URL.RawQuery = ""
+	get.Body = http.NoBody
+	get.ContentLength = 0
+	get.Header.Del("If-Match")
+	get.Header.Del("If-None-Match")
+	rec := newRecorder()
+	next.ServeHTTP(rec, get)
+
+	switch rec.status {
+	case http.StatusOK:
+		if matches(r.Header.Get("If-Match"), Of(rec.body.Bytes()), false) {
+			next.ServeHTTP(w, r)
+			return
+		}
+	case http.StatusNotFound:
+		// No current representation matches any tag.
+	default:
+		// E.g. authentication failures, which next reports for r as well.
+		next.ServeHTTP(w, r)
+		return
+	}
+	api.WriteJSON(w, http.StatusPreconditionFailed, map[string]string{
+		"message": "The entity does not match the If-Match header; get it again before modifying it"})
+}
@@ -0,0 +1,108 @@
+package etag
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const entityPath = "/rid/v2/dss/identification_service_areas/4f1a8a36-8f4e-4b1c-9b7d-2c6e1f0a3b5d"
+
+// entityServer serves an entity whose version is bumped by every PUT.
+type entityServer struct {
+	version int
+	puts    int
+}
+
+func (s *entityServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == entityPath:
+		_ = json.NewEncoder(w).Encode(map[string]int{"version": s.version})
+	case r.Method == http.MethodGet:
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, entityPath+"/"):
+		s.puts++
+		s.version++
+		_ = json.NewEncoder(w).Encode(map[string]int{"version": s.version})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func serve(h http.Handler, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	r.Header.Set("Authorization", "Bearer token")
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestConditionalGet(t *testing.T) {
+	s := &entityServer{}
+	h := Middleware(s)
+
+	w := serve(h, http.MethodGet, entityPath, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	tag := w.Header().Get("ETag")
+	require.Equal(t, Of(w.Body.Bytes()), tag)
+
+	w = serve(h, http.MethodGet, entityPath, map[string]string{"If-None-Match": `"other", ` + tag})
+	require.Equal(t, http.StatusNotModified, w.Code)
+	require.Equal(t, tag, w.Header().Get("ETag"))
+	require.Empty(t, w.Body.Bytes())
+
+	w = serve(h, http.MethodGet, entityPath, map[string]string{"If-None-Match": "W/" + tag})
+	require.Equal(t, http.StatusNotModified, w.Code)
+
+	s.version++
+	w = serve(h, http.MethodGet, entityPath, map[string]string{"If-None-Match": tag})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotEqual(t, tag, w.Header().Get("ETag"))
+
+	// Errors are not tagged.
+	w = serve(h, http.MethodGet, entityPath+"x", nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Empty(t, w.Header().Get("ETag"))
+}
+
+func TestConditionalWrite(t *testing.T) {
+	s := &entityServer{}
+	h := Middleware(s)
+	tag := serve(h, http.MethodGet, entityPath, nil).Header().Get("ETag")
+
+	// Writes without If-Match are not conditional.
+	require.Equal(t, http.StatusOK, serve(h, http.MethodPut, entityPath+"/v0", nil).Code)
+	require.Equal(t, 1, s.puts)
+
+	// The entity changed since tag was returned.
+	require.Equal(t, http.StatusPreconditionFailed, serve(h, http.MethodPut, entityPath+"/v1", map[string]string{"If-Match": tag}).Code)
+	require.Equal(t, 1, s.puts)
+
+	tag = serve(h, http.MethodGet, entityPath, nil).Header().Get("ETag")
+	require.Equal(t, http.StatusPreconditionFailed, serve(h, http.MethodPut, entityPath+"/v1", map[string]string{"If-Match": "W/" + tag}).Code)
+	require.Equal(t, http.StatusOK, serve(h, http.MethodPut, entityPath+"/v1", map[string]string{"If-Match": tag}).Code)
+	require.Equal(t, http.StatusOK, serve(h, http.MethodPut, entityPath+"/v2", map[string]string{"If-Match": "*"}).Code)
+	require.Equal(t, 3, s.puts)
+
+	// No entity matches If-Match.
+	other := "/rid/v2/dss/identification_service_areas/00000000-0000-4000-8000-000000000000/v0"
+	require.Equal(t, http.StatusPreconditionFailed, serve(h, http.MethodPut, other, map[string]string{"If-Match": "*"}).Code)
+
+	// Authentication failures are reported as without If-Match.
+	r := httptest.NewRequest(http.MethodPut, entityPath+"/v3", nil)
+	r.Header.Set("If-Match", tag)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
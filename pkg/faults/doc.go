@@ -0,0 +1,5 @@
+// Package faults injects latency, errors and database slowness into a
+// percentage of the requests served by the DSS, so that failover drills can
+// validate the retry behavior of clients and the alerting of a pool. It must
+// never be enabled in production.
+package faults
@@ -0,0 +1,205 @@
+package faults
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	"github.com/interuss/stacktrace"
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// InjectedHeader is the response header listing the faults injected into a
+// request, so that drill participants can tell them from genuine failures.
+const InjectedHeader = "DSS-Injected-Fault"
+
+// Kinds of faults, as named in a Plan specification.
+const (
+	KindLatency   = "latency"
+	KindError     = "error"
+	KindDBLatency = "db_latency"
+)
+
+var injectedFaults = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "dss_injected_faults_total",
+	Help: "Number of faults injected into requests, by kind.",
+}, []string{"kind"})
+
+// Fault is a fault injected into Percent percent of the requests.
+type Fault struct {
+	// Delay is the latency injected by latency and db_latency faults.
+	Delay time.Duration
+	// Status is the HTTP status with which error faults answer.
+	Status int
+	// Percent is the percentage of the requests, between 0 and 100, into
+	// which the fault is injected; never injected if 0.
+	Percent float64
+}
+
+// Plan describes the faults injected into requests, each being drawn
+// independently for every request.
+type Plan struct {
+	// Latency delays requests before they are served.
+	Latency Fault
+	// Error answers requests with Status instead of serving them.
+	Error Fault
+	// DBLatency delays each database query made while serving requests, if
+	// QueryTracer traces the queries of the datastore.
+	DBLatency Fault
+
+	roll func() float64
+}
+
+// Parse returns the Plan of spec, a comma-separated list of kind=value@percent
+// entries such as "latency=500ms@10,error=503@5,db_latency=2s@20", or nil if
+// spec is empty.
+func Parse(spec string) (*Plan, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	p := &Plan{}
+	for _, entry := range strings.Split(spec, ",") {
+		kind, rest, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return nil, stacktrace.NewError("Expected kind=value@percent, got `%s`", entry)
+		}
+		value, percentValue, ok := strings.Cut(rest, "@")
+		if !ok {
+			return nil, stacktrace.NewError("Missing percentage of requests in `%s`", entry)
+		}
+		percent, err := strconv.ParseFloat(percentValue, 64)
+		if err != nil || percent <= 0 || percent > 100 {
+			return nil, stacktrace.NewError("Percentage of requests in `%s` must be greater than 0 and at most 100", entry)
+		}
+
+		var fault *Fault
+		switch kind {
+		case KindLatency:
+			fault = &p.Latency
+		case KindError:
+			fault = &p.Error
+		case KindDBLatency:
+			fault = &p.DBLatency
+		default:
+			return nil, stacktrace.NewError("Unknown fault `%s`; expected %s, %s or %s", kind, KindLatency, KindError, KindDBLatency)
+		}
+		if fault.Percent > 0 {
+			return nil, stacktrace.NewError("Fault %s specified more than once", kind)
+		}
+		fault.Percent = percent
+
+		if kind == KindError {
+			fault.Status, err = strconv.Atoi(value)
+			if err != nil || fault.Status < 400 || fault.Status > 599 {
+				return nil, stacktrace.NewError("Status of `%s` must be an HTTP error status between 400 and 599", entry)
+			}
+		} else {
+			fault.Delay, err = time.ParseDuration(value)
+			if err != nil || fault.Delay <= 0 {
+				return nil, stacktrace.NewError("Delay of `%s` must be a positive duration", entry)
+			}
+		}
+	}
+	return p, nil
+}
+
+// String returns the specification of p.
+func (p *Plan) String() string {
+	var entries []string
+	if p.Latency.Percent > 0 {
+		entries = append(entries, fmt.Sprintf("%s=%s@%g", KindLatency, p.Latency.Delay, p.Latency.Percent))
+	}
+	if p.Error.Percent > 0 {
+		entries = append(entries, fmt.Sprintf("%s=%d@%g", KindError, p.Error.Status, p.Error.Percent))
+	}
+	if p.DBLatency.Percent > 0 {
+		entries = append(entries, fmt.Sprintf("%s=%s@%g", KindDBLatency, p.DBLatency.Delay, p.DBLatency.Percent))
+	}
+	return strings.Join(entries, ",")
+}
+
+// draws returns whether f is injected into the current request.
+func (p *Plan) draws(f Fault) bool {
+	if f.Percent <= 0 {
+		return false
+	}
+	roll := rand.Float64
+	if p.roll != nil {
+		roll = p.roll
+	}
+	return roll()*100 < f.Percent
+}
+
+type contextKey struct{}
+
+// QueryDelay returns the latency to inject into each database query made with
+// ctx.
+func QueryDelay(ctx context.Context) time.Duration {
+	d, _ := ctx.Value(contextKey{}).(time.Duration)
+	return d
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
+// Middleware returns an http.Handler injecting the faults of p into the
+// requests passed to next, listing them in the InjectedHeader of the
+// response. It returns next if p is nil.
+func (p *Plan) Middleware(next http.Handler) http.Handler {
+	if p == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if p.draws(p.Latency) {
+			injectedFaults.WithLabelValues(KindLatency).Inc()
+			w.Header().Add(InjectedHeader, KindLatency)
+			sleep(ctx, p.Latency.Delay)
+		}
+		if p.draws(p.Error) {
+			injectedFaults.WithLabelValues(KindError).Inc()
+			w.Header().Add(InjectedHeader, KindError)
+			if p.Error.Status == http.StatusServiceUnavailable || p.Error.Status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "1")
+			}
+			api.WriteJSON(w, p.Error.Status, map[string]string{"message": "Fault injected for a failover drill"})
+			return
+		}
+		if p.draws(p.DBLatency) {
+			injectedFaults.WithLabelValues(KindDBLatency).Inc()
+			w.Header().Add(InjectedHeader, KindDBLatency)
+			r = r.WithContext(context.WithValue(ctx, contextKey{}, p.DBLatency.Delay))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// QueryTracer is a pgx.QueryTracer delaying the queries made while serving
+// requests into which Plan.Middleware injected a db_latency fault.
+type QueryTracer struct{}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	if d := QueryDelay(ctx); d > 0 {
+		sleep(ctx, d)
+	}
+	return ctx
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (QueryTracer) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
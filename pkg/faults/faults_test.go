@@ -0,0 +1,94 @@
+package faults
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	p, err := Parse("")
+	require.NoError(t, err)
+	require.Nil(t, p)
+
+	p, err = Parse("latency=500ms@10, error=503@2.5,db_latency=2s@100")
+	require.NoError(t, err)
+	require.Equal(t, Fault{Delay: 500 * time.Millisecond, Percent: 10}, p.Latency)
+	require.Equal(t, Fault{Status: http.StatusServiceUnavailable, Percent: 2.5}, p.Error)
+	require.Equal(t, Fault{Delay: 2 * time.Second, Percent: 100}, p.DBLatency)
+	require.Equal(t, "latency=500ms@10,error=503@2.5,db_latency=2s@100", p.String())
+
+	for _, spec := range []string{
+		"latency",
+		"latency=1s",
+		"latency=1s@0",
+		"latency=1s@101",
+		"latency=-1s@10",
+		"error=200@10",
+		"error=abc@10",
+		"timeout=1s@10",
+		"latency=1s@10,latency=2s@10",
+	} {
+		_, err := Parse(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var delay time.Duration
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delay = QueryDelay(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(p *Plan) *httptest.ResponseRecorder {
+		delay = 0
+		w := httptest.NewRecorder()
+		p.Middleware(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/dss/identification_service_areas", nil))
+		return w
+	}
+
+	// A nil plan injects nothing.
+	w := serve(nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Values(InjectedHeader))
+
+	p, err := Parse("latency=1ms@50,error=503@50,db_latency=1s@50")
+	require.NoError(t, err)
+
+	p.roll = func() float64 { return 0.6 }
+	w = serve(p)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Values(InjectedHeader))
+	require.Zero(t, delay)
+
+	p.roll = func() float64 { return 0.4 }
+	w = serve(p)
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, []string{KindLatency, KindError}, w.Header().Values(InjectedHeader))
+	require.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	p.Error.Percent = 0
+	w = serve(p)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, []string{KindLatency, KindDBLatency}, w.Header().Values(InjectedHeader))
+	require.Equal(t, time.Second, delay)
+}
+
+func TestQueryTracer(t *testing.T) {
+	ctx := context.WithValue(context.Background(), contextKey{}, 50*time.Millisecond)
+	start := time.Now()
+	QueryTracer{}.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{})
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// The delay ends with the request.
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey{}, time.Hour))
+	cancel()
+	start = time.Now()
+	QueryTracer{}.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{})
+	require.Less(t, time.Since(start), time.Second)
+}
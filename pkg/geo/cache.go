@@ -0,0 +1,100 @@
+package geo
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/golang/geo/s2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// DefaultCoveringCacheSize is the default number of area coverings kept in
+// memory by AreaToCellIDs.
+const DefaultCoveringCacheSize = 1024
+
+var (
+	coveringCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dss_geo_covering_cache_lookups_total",
+		Help: "Number of area covering cache lookups, by result (hit or miss).",
+	}, []string{"result"})
+	coveringCacheEntries = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "dss_geo_covering_cache_entries",
+		Help: "Number of area coverings currently cached.",
+	})
+
+	areaCoveringCache = newCoveringCache(DefaultCoveringCacheSize)
+)
+
+// SetCoveringCacheSize replaces the area covering cache with an empty one
+// holding at most size entries. A size of 0 disables caching.
+func SetCoveringCacheSize(size int) {
+	areaCoveringCache = newCoveringCache(size)
+	coveringCacheEntries.Set(0)
+}
+
+// coveringCache is a least-recently-used cache of cell coverings, safe for
+// concurrent use.
+type coveringCache struct {
+	sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type coveringCacheEntry struct {
+	key   string
+	cells s2.CellUnion
+}
+
+func newCoveringCache(capacity int) *coveringCache {
+	return &coveringCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// get returns a copy of the covering cached under key, if any.
+func (c *coveringCache) get(key string) (s2.CellUnion, bool) {
+	if c.capacity <= 0 {
+		return nil, false
+	}
+	c.Lock()
+	defer c.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		coveringCacheLookups.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+	coveringCacheLookups.WithLabelValues("hit").Inc()
+	c.order.MoveToFront(elem)
+	cells := elem.Value.(*coveringCacheEntry).cells
+	return append(s2.CellUnion(nil), cells...), true
+}
+
+// add caches a copy of cells under key, evicting the least recently used
+// entry if the cache is full.
+func (c *coveringCache) add(key string, cells s2.CellUnion) {
+	if c.capacity <= 0 {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&coveringCacheEntry{
+		key:   key,
+		cells: append(s2.CellUnion(nil), cells...),
+	})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*coveringCacheEntry).key)
+	}
+	coveringCacheEntries.Set(float64(c.order.Len()))
+}
@@ -0,0 +1,61 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoveringCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newCoveringCache(2)
+	c.add("a", s2.CellUnion{1})
+	c.add("b", s2.CellUnion{2})
+
+	_, ok := c.get("a")
+	require.True(t, ok)
+	c.add("c", s2.CellUnion{3})
+
+	_, ok = c.get("b")
+	require.False(t, ok)
+	cells, ok := c.get("a")
+	require.True(t, ok)
+	require.Equal(t, s2.CellUnion{1}, cells)
+	cells, ok = c.get("c")
+	require.True(t, ok)
+	require.Equal(t, s2.CellUnion{3}, cells)
+}
+
+func TestCoveringCacheReturnsCopies(t *testing.T) {
+	c := newCoveringCache(1)
+	c.add("a", s2.CellUnion{1, 2})
+
+	cells, ok := c.get("a")
+	require.True(t, ok)
+	cells[0] = 42
+
+	cells, ok = c.get("a")
+	require.True(t, ok)
+	require.Equal(t, s2.CellUnion{1, 2}, cells)
+}
+
+func TestAreaToCellIDsUsesCanonicalKey(t *testing.T) {
+	SetCoveringCacheSize(DefaultCoveringCacheSize)
+	defer SetCoveringCacheSize(DefaultCoveringCacheSize)
+
+	want, err := AreaToCellIDs(`37.4047,-122.1474,37.4037,-122.1485,37.4035,-122.1466`)
+	require.NoError(t, err)
+	_, ok := areaCoveringCache.get(`37.4035,-122.1466,37.4047,-122.1474,37.4037,-122.1485`)
+	require.True(t, ok)
+
+	got, err := AreaToCellIDs(`37.4047, -122.1474, 37.4037, -122.1485, 37.4035, -122.1466`)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func TestCoveringCacheDisabled(t *testing.T) {
+	c := newCoveringCache(0)
+	c.add("a", s2.CellUnion{1})
+	_, ok := c.get("a")
+	require.False(t, ok)
+}
@@ -0,0 +1,78 @@
+package geo
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/golang/geo/s2"
+)
+
+// canonicalDecimals is the number of decimals, about 1cm, to which the
+// coordinates of canonical areas are rounded.
+const canonicalDecimals = 7
+
+// CanonicalArea returns the canonical form of "area", in the format of
+// AreaToCellIDs, shared by the areas differing only by whitespace, the
+// formatting of their coordinates beyond canonicalDecimals decimals, a
+// closing vertex repeating the first one, or the vertex they start from.
+// Vertices keep their order, the first being the smallest by latitude then
+// longitude, so that canonical areas describe the same polygon.
+func CanonicalArea(area string) (string, error) {
+	points, err := parseArea(area)
+	if err != nil {
+		return "", err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	type vertex struct{ lat, lng float64 }
+	vertices := make([]vertex, 0, len(points))
+	for _, p := range points {
+		ll := s2.LatLngFromPoint(p)
+		vertices = append(vertices, vertex{lat: roundCanonical(ll.Lat.Degrees()), lng: roundCanonical(ll.Lng.Degrees())})
+	}
+	if len(vertices) > 3 && vertices[0] == vertices[len(vertices)-1] {
+		vertices = vertices[:len(vertices)-1]
+	}
+
+	first := 0
+	for i, v := range vertices {
+		if v.lat < vertices[first].lat || (v.lat == vertices[first].lat && v.lng < vertices[first].lng) {
+			first = i
+		}
+	}
+	coords := make([]string, 0, 2*len(vertices))
+	for i := range vertices {
+		v := vertices[(first+i)%len(vertices)]
+		coords = append(coords,
+			strconv.FormatFloat(v.lat, 'f', -1, 64), strconv.FormatFloat(v.lng, 'f', -1, 64))
+	}
+	return strings.Join(coords, ","), nil
+}
+
+// roundCanonical rounds the coordinate x to canonicalDecimals decimals.
+func roundCanonical(x float64) float64 {
+	scale := math.Pow10(canonicalDecimals)
+	x = math.Round(x*scale) / scale
+	if x == 0 {
+		// Negative zero would be formatted differently.
+		return 0
+	}
+	return x
+}
+
+// CellUnionHash returns a short stable hash of the normalized cells, shared
+// by all the areas covered by the same cells, e.g. to tell in logs the
+// searches of the same area apart from others.
+func CellUnionHash(cells s2.CellUnion) string {
+	normalized := append(s2.CellUnion(nil), cells...)
+	normalized.Normalize()
+	h := sha256.New()
+	var b [8]byte
+	for _, cell := range normalized {
+		binary.BigEndian.PutUint64(b[:], uint64(cell))
+		h.Write(b[:])
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
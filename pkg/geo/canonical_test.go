@@ -0,0 +1,44 @@
+package geo_test
+
+import (
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/interuss/dss/pkg/geo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalArea(t *testing.T) {
+	const canonical = "37.4035,-122.1466,37.4047,-122.1474,37.4037,-122.1485"
+	for _, area := range []string{
+		canonical,
+		"37.4047,-122.1474,37.4037,-122.1485,37.4035,-122.1466",
+		" 37.4037, -122.1485, 37.4035, -122.1466, 37.4047, -122.1474 ",
+		"37.40470000001,-122.1474,37.4037,-122.14850,37.4035,-122.1466,37.4047,-122.1474",
+	} {
+		got, err := geo.CanonicalArea(area)
+		require.NoError(t, err, area)
+		require.Equal(t, canonical, got, area)
+	}
+
+	// The reverse winding order describes another polygon.
+	got, err := geo.CanonicalArea("37.4035,-122.1466,37.4037,-122.1485,37.4047,-122.1474")
+	require.NoError(t, err)
+	require.NotEqual(t, canonical, got)
+
+	got, err = geo.CanonicalArea("-0.00000001,0,1,0,1,1")
+	require.NoError(t, err)
+	require.Equal(t, "0,0,1,0,1,1", got)
+
+	_, err = geo.CanonicalArea("37.4035,-122.1466")
+	require.Error(t, err)
+}
+
+func TestCellUnionHash(t *testing.T) {
+	cells := s2.CellUnion{s2.CellIDFromToken("89c25"), s2.CellIDFromToken("89c24")}
+	require.Len(t, geo.CellUnionHash(cells), 16)
+	require.Equal(t, geo.CellUnionHash(cells), geo.CellUnionHash(s2.CellUnion{cells[1], cells[0]}))
+	require.NotEqual(t, geo.CellUnionHash(cells), geo.CellUnionHash(cells[:1]))
+	// The cells are left as they were.
+	require.Equal(t, s2.CellIDFromToken("89c25"), cells[0])
+}
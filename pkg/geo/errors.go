@@ -27,7 +27,7 @@ var (
 	ErrRadiusMustBeLargerThan0 = stacktrace.NewErrorWithCode(dsserr.BadRequest, "Radius must be larger than 0")
 
 	// ErrAreaTooLarge is the error passed back when the requested Area is larger
-	// than maxAllowedAreaKm2
+	// than MaxAllowedAreaKm2
 	ErrAreaTooLarge = stacktrace.NewErrorWithCode(dsserr.AreaTooLarge, "Area too large")
 
 	// ErrOddNumberOfCoordinatesInAreaString indicates that an area string that
@@ -0,0 +1,72 @@
+package geo
+
+import (
+	"math"
+
+	"github.com/golang/geo/s2"
+)
+
+// SearchArea is the polygon of a search area, against which the exact
+// geometries of the entities found by their coverings can be tested. Since
+// coverings include cells along the boundary of the area, they find entities
+// merely nearby.
+type SearchArea struct {
+	// loop is the polygon of the area, nil if it has no interior.
+	loop *s2.Loop
+}
+
+// ParseSearchArea parses "area", in the format of AreaToCellIDs.
+func ParseSearchArea(area string) (*SearchArea, error) {
+	points, err := parseArea(area)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	region, _, err := polygonRegion(points)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	loop, _ := region.(*s2.Loop)
+	return &SearchArea{loop: loop}, nil
+}
+
+// IntersectsPolygon returns whether the polygon of vertices intersects a,
+// ordered such that it encloses the smaller area. Invalid polygons, and any
+// polygon if a has no interior, are deemed to intersect a.
+func (a *SearchArea) IntersectsPolygon(vertices []s2.LatLng) bool {
+	if a.loop == nil {
+		return true
+	}
+	points := make([]s2.Point, 0, len(vertices))
+	for _, v := range vertices {
+		points = append(points, s2.PointFromLatLng(v))
+	}
+	loop := s2.LoopFromPoints(points)
+	if len(points) < 3 || loop.Validate() != nil {
+		return true
+	}
+	if loop.Area() > 2*math.Pi {
+		loop.Invert()
+	}
+	return a.loop.Intersects(loop)
+}
+
+// IntersectsCircle returns whether the circle of radius radiusMeter around
+// center intersects a. Any circle is deemed to intersect a if a has no
+// interior.
+func (a *SearchArea) IntersectsCircle(center s2.LatLng, radiusMeter float64) bool {
+	if a.loop == nil {
+		return true
+	}
+	c := s2.PointFromLatLng(center)
+	if a.loop.ContainsPoint(c) {
+		return true
+	}
+	radius := DistanceMetersToAngle(radiusMeter)
+	for i := 0; i < a.loop.NumEdges(); i++ {
+		e := a.loop.Edge(i)
+		if s2.DistanceFromSegment(c, e.V0, e.V1) <= radius {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package geo_test
+
+import (
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/interuss/dss/pkg/geo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchAreaIntersections(t *testing.T) {
+	area, err := geo.ParseSearchArea("46.90,7.40,46.90,7.41,46.91,7.41,46.91,7.40")
+	require.NoError(t, err)
+
+	polygon := func(vertices ...float64) []s2.LatLng {
+		var result []s2.LatLng
+		for i := 0; i < len(vertices); i += 2 {
+			result = append(result, s2.LatLngFromDegrees(vertices[i], vertices[i+1]))
+		}
+		return result
+	}
+	// Inside the area, in both winding orders.
+	require.True(t, area.IntersectsPolygon(polygon(46.902, 7.402, 46.902, 7.404, 46.904, 7.404)))
+	require.True(t, area.IntersectsPolygon(polygon(46.904, 7.404, 46.902, 7.404, 46.902, 7.402)))
+	// Enclosing the area.
+	require.True(t, area.IntersectsPolygon(polygon(46.8, 7.3, 46.8, 7.5, 47.0, 7.5, 47.0, 7.3)))
+	// Crossing its boundary.
+	require.True(t, area.IntersectsPolygon(polygon(46.905, 7.405, 46.905, 7.415, 46.906, 7.415)))
+	// Next to it.
+	require.False(t, area.IntersectsPolygon(polygon(46.902, 7.412, 46.902, 7.414, 46.904, 7.414)))
+	// Invalid polygons are deemed to intersect.
+	require.True(t, area.IntersectsPolygon(polygon(46.95, 7.45, 46.96, 7.46)))
+
+	// About 760m east of the area.
+	center := s2.LatLngFromDegrees(46.905, 7.42)
+	require.False(t, area.IntersectsCircle(center, 500))
+	require.True(t, area.IntersectsCircle(center, 1000))
+	require.True(t, area.IntersectsCircle(s2.LatLngFromDegrees(46.905, 7.405), 10))
+
+	_, err = geo.ParseSearchArea("46.90,7.40")
+	require.Error(t, err)
+}
@@ -19,10 +19,17 @@ const (
 	// DefaultMaximumCellLevel is the default minimum cell level, chosen such
 	// that the maximum cell size is ~1km^2.
 	DefaultMaximumCellLevel = 13
-	maxAllowedAreaKm2       = 2500.0
-	radiusEarthMeter        = 6371010.0
+	// MaxAllowedAreaKm2 is the largest area, in square kilometers, that may be
+	// covered.
+	MaxAllowedAreaKm2 = 2500.0
+	radiusEarthMeter  = 6371010.0
 
 	earthAreaKm2 = 510072000.0 // rough area of the earth in KM².
+
+	minLat = -90.0
+	maxLat = 90.0
+	minLng = -180.0
+	maxLng = 180.0
 )
 
 var (
@@ -36,21 +43,107 @@ var (
 	RegionCoverer = defaultRegionCoverer
 )
 
+// ConfigureCoverings replaces RegionCoverer with one covering areas with
+// cells of levels between minLevel and maxLevel. When maxCells is positive
+// and maxLevel exceeds minLevel, the finest level used to cover an area is the
+// finest one at which about maxCells cells of average size cover it, such that
+// small areas are covered with small cells and large areas with few large
+// cells. Since stored and searched coverings are compared cell by cell, all
+// the instances sharing a database must use the same configuration.
+func ConfigureCoverings(minLevel, maxLevel, maxCells int) error {
+	switch {
+	case minLevel < 0 || maxLevel > s2.MaxLevel:
+		return stacktrace.NewError("Cell levels [%d, %d] are outside [0, %d]", minLevel, maxLevel, s2.MaxLevel)
+	case minLevel > maxLevel:
+		return stacktrace.NewError("Minimum cell level %d exceeds maximum cell level %d", minLevel, maxLevel)
+	case maxCells < 0:
+		return stacktrace.NewError("Maximum number of covering cells %d is negative", maxCells)
+	}
+	RegionCoverer = &s2.RegionCoverer{MinLevel: minLevel, MaxLevel: maxLevel, MaxCells: maxCells}
+	return nil
+}
+
+// MultiLevelCoverings returns whether coverings may contain cells of different
+// levels, in which case intersecting coverings do not necessarily share cells.
+func MultiLevelCoverings() bool {
+	return RegionCoverer.MinLevel < RegionCoverer.MaxLevel
+}
+
+// cover returns the covering of region, of area areaKm2, by RegionCoverer. The
+// covering is normalized, then denormalized to RegionCoverer.MinLevel so that
+// it contains no cell coarser than that level.
+func cover(region s2.Region, areaKm2 float64) s2.CellUnion {
+	rc := *RegionCoverer
+	if rc.MaxCells > 0 && rc.MinLevel < rc.MaxLevel {
+		cellArea := (areaKm2 / earthAreaKm2) * 4.0 * math.Pi / float64(rc.MaxCells)
+		if level := s2.AvgAreaMetric.MaxLevel(cellArea); level < rc.MaxLevel {
+			rc.MaxLevel = max(level, rc.MinLevel)
+		}
+	}
+	return rc.Covering(region)
+}
+
 // Levelify takes a cell union that might have been normalized and returns to
 // the appropriate level
 func Levelify(cells *s2.CellUnion) {
-	// thirty is the number of s2 cells, we make it negative to get the number
-	// of cells we want
-	cells.Denormalize(DefaultMinimumCellLevel, 1)
+	cells.Denormalize(RegionCoverer.MinLevel, 1)
+}
+
+// NormalizeCells returns a copy of cells without duplicate or overlapping
+// cells, e.g. of coverings provided by clients, so that each area is stored
+// once.  Cells merged by normalization are returned to the levels of
+// coverings as Levelify does.
+func NormalizeCells(cells s2.CellUnion) s2.CellUnion {
+	normalized := append(s2.CellUnion(nil), cells...)
+	normalized.Normalize()
+	Levelify(&normalized)
+	return normalized
+}
+
+// CoarsenCovering returns a covering of cells coarser by up to levels cell
+// levels, but not coarser than RegionCoverer.MinLevel, which thus has fewer
+// cells and covers a larger area. Coverings of a single cell level cannot be
+// coarsened.
+func CoarsenCovering(cells s2.CellUnion, levels int) s2.CellUnion {
+	coarse := make(s2.CellUnion, 0, len(cells))
+	for _, cell := range cells {
+		level := max(cell.Level()-levels, RegionCoverer.MinLevel)
+		if level < cell.Level() {
+			cell = cell.Parent(level)
+		}
+		coarse = append(coarse, cell)
+	}
+	coarse.Normalize()
+	Levelify(&coarse)
+	return coarse
 }
 
+// ValidateCell returns an error if cell is not of a level that coverings may
+// contain.
 func ValidateCell(cell s2.CellID) error {
-	if cell.Level() < DefaultMinimumCellLevel || cell.Level() > DefaultMaximumCellLevel {
-		return stacktrace.NewError("Cells must be at level 13 at current implementation")
+	if cell.Level() < RegionCoverer.MinLevel || cell.Level() > RegionCoverer.MaxLevel {
+		return stacktrace.NewError("Cells must be of levels [%d, %d], got level %d", RegionCoverer.MinLevel, RegionCoverer.MaxLevel, cell.Level())
 	}
 	return nil
 }
 
+// NormalizeLatLng validates that lat and lng, in degrees, designate a point on
+// earth and returns them normalized such that the antimeridian is always
+// expressed as a longitude of 180. Out-of-range or non-finite coordinates are
+// rejected with an error wrapping ErrBadCoordSet.
+func NormalizeLatLng(lat, lng float64) (float64, float64, error) {
+	if math.IsNaN(lat) || lat < minLat || lat > maxLat {
+		return 0, 0, stacktrace.Propagate(ErrBadCoordSet, "Latitude %v is outside of [%v, %v]", lat, minLat, maxLat)
+	}
+	if math.IsNaN(lng) || lng < minLng || lng > maxLng {
+		return 0, 0, stacktrace.Propagate(ErrBadCoordSet, "Longitude %v is outside of [%v, %v]", lng, minLng, maxLng)
+	}
+	if lng == minLng {
+		lng = maxLng
+	}
+	return lat, lng, nil
+}
+
 func splitAtComma(data []byte, atEOF bool) (int, []byte, error) {
 	if atEOF && len(data) == 0 {
 		return 0, nil, nil
@@ -82,6 +175,29 @@ func DistanceMetersToAngle(distance float64) s1.Angle {
 	return s1.Angle(distance / radiusEarthMeter)
 }
 
+// CircleCovering returns a covering of the circle of radius radiusMeter
+// around center. The covering is computed from an s2.Cap so that, unlike an
+// inscribed polygon, it covers the whole circle.
+func CircleCovering(center s2.LatLng, radiusMeter float64) (s2.CellUnion, error) {
+	if !(radiusMeter > 0) {
+		return nil, ErrRadiusMustBeLargerThan0
+	}
+	c := s2.CapFromCenterAngle(s2.PointFromLatLng(center), DistanceMetersToAngle(radiusMeter))
+	area := (c.Area() * earthAreaKm2) / (4.0 * math.Pi)
+	if area > MaxAllowedAreaKm2 {
+		return nil, stacktrace.Propagate(
+			ErrAreaTooLarge, "Area is too large (%fkm² > %fkm²)",
+			area, MaxAllowedAreaKm2)
+	}
+	return cover(c, area), nil
+}
+
+// CellUnionAreaKm2 returns the area of cells in km², which exceeds the area
+// they cover by up to the area of the cells along its boundary.
+func CellUnionAreaKm2(cells s2.CellUnion) float64 {
+	return (cells.ExactArea() * earthAreaKm2) / (4.0 * math.Pi)
+}
+
 func loopAreaKm2(loop *s2.Loop) float64 {
 	if loop.IsEmpty() {
 		return 0
@@ -154,17 +270,33 @@ func validateLoop(points []s2.Point) error {
 // Covering calculates the S2 covering of a set of S2 points representing a
 // polygon. Will try the loop in both clockwise and counter clockwise.
 func Covering(points []s2.Point) (s2.CellUnion, error) {
+	region, area, err := polygonRegion(points)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if area > MaxAllowedAreaKm2 {
+		return nil, stacktrace.Propagate(
+			ErrAreaTooLarge, "Area is too large (%fkm² > %fkm²)",
+			area, MaxAllowedAreaKm2)
+	}
+	return cover(region, area), nil
+}
+
+// polygonRegion returns the region of the polygon of points and its area in
+// km², trying the loop in both clockwise and counter clockwise order. The
+// region is a polyline if the polygon has no area.
+func polygonRegion(points []s2.Point) (s2.Region, float64, error) {
 	err := validateLoop(points)
 	if err != nil {
-		return nil, stacktrace.Propagate(err, "Error validating polygon")
+		return nil, 0, stacktrace.Propagate(err, "Error validating polygon")
 	}
 	loop := s2.LoopFromPoints(points)
 	err = loop.Validate()
 	if err != nil {
-		return nil, stacktrace.Propagate(err, "Error validating loop")
+		return nil, 0, stacktrace.Propagate(err, "Error validating loop")
 	}
 	area := loopAreaKm2(loop)
-	if area > maxAllowedAreaKm2 {
+	if area > MaxAllowedAreaKm2 {
 		// This may have happened because the vertices were not ordered counter-clockwise.
 		// We can try reversing to see if that's the case.
 		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
@@ -173,17 +305,44 @@ func Covering(points []s2.Point) (s2.CellUnion, error) {
 		loop = s2.LoopFromPoints(points)
 		area = loopAreaKm2(loop)
 	}
-	if area > maxAllowedAreaKm2 {
-		return nil, stacktrace.Propagate(
-			ErrAreaTooLarge, "Area is too large (%fkm² > %fkm²)",
-			area, maxAllowedAreaKm2)
-	}
 	if area <= 0 {
 		// Since the loop has no area, try a PolyLine
 		pl := s2.Polyline(loop.Vertices())
-		return RegionCoverer.Covering(&pl), nil
+		return &pl, 0, nil
+	}
+	return loop, area, nil
+}
+
+// AreaCovering describes how an area is covered.
+type AreaCovering struct {
+	// AreaKm2 is the area of the polygon in km².
+	AreaKm2 float64
+	// Cells cover the area, unless it exceeds MaxAllowedAreaKm2.
+	Cells s2.CellUnion
+}
+
+// ExceedsMaxArea returns whether the area is too large to be covered.
+func (c *AreaCovering) ExceedsMaxArea() bool {
+	return c.AreaKm2 > MaxAllowedAreaKm2
+}
+
+// DescribeAreaCovering returns how "area", in the format of AreaToCellIDs,
+// is covered, including when it is too large to be covered, bypassing the
+// cache of coverings.
+func DescribeAreaCovering(area string) (*AreaCovering, error) {
+	points, err := parseArea(area)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	region, areaKm2, err := polygonRegion(points)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	c := &AreaCovering{AreaKm2: areaKm2}
+	if !c.ExceedsMaxArea() {
+		c.Cells = cover(region, areaKm2)
 	}
-	return RegionCoverer.Covering(loop), nil
+	return c, nil
 }
 
 // AreaToCellIDs parses "area" in the format 'lat0,lon0,lat1,lon1,...'
@@ -192,9 +351,37 @@ func Covering(points []s2.Point) (s2.CellUnion, error) {
 // * ErrNotEnoughPointsInPolygon
 // * ErrBadCoordSet
 //
+// Coverings of recently requested areas are cached under their CanonicalArea,
+// see SetCoveringCacheSize.
+//
 // TODO(tvoss):
 // * Agree and implement a maximum number of points in area
 func AreaToCellIDs(area string) (s2.CellUnion, error) {
+	key, err := CanonicalArea(area)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if cells, ok := areaCoveringCache.get(key); ok {
+		return cells, nil
+	}
+	cells, err := areaToCellIDs(key)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	areaCoveringCache.add(key, cells)
+	return cells, nil
+}
+
+func areaToCellIDs(area string) (s2.CellUnion, error) {
+	points, err := parseArea(area)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return Covering(points)
+}
+
+// parseArea returns the vertices of "area", in the format of AreaToCellIDs.
+func parseArea(area string) ([]s2.Point, error) {
 	var (
 		lat, lng float64
 		points   = []s2.Point{}
@@ -225,10 +412,14 @@ func AreaToCellIDs(area string) (s2.CellUnion, error) {
 				return nil, stacktrace.Propagate(ErrBadCoordSet, "Unable to parse lng: %s", err.Error())
 			}
 			lng = f
+			lat, lng, err = NormalizeLatLng(lat, lng)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "Invalid coordinates for point %d", counter/2)
+			}
 			points = append(points, s2.PointFromLatLng(s2.LatLngFromDegrees(lat, lng)))
 		}
 
 		counter++
 	}
-	return Covering(points)
+	return points, nil
 }
@@ -1,8 +1,10 @@
 package geo_test
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/golang/geo/s2"
 	"github.com/interuss/dss/pkg/geo"
 	"github.com/interuss/dss/pkg/geo/testdata"
 
@@ -50,3 +52,175 @@ func TestParseAreaFailsForLoopWithOddNumberOfCoordinates(t *testing.T) {
 	require.Error(t, err)
 	require.Nil(t, cells)
 }
+
+func TestParseAreaFailsForOutOfRangeCoordinates(t *testing.T) {
+	for _, area := range []string{
+		`91.0,0.0,0.0,0.005,-0.005,0.0025`,
+		`0.0,0.0,0.0,180.5,-0.005,0.0025`,
+		`0.0,0.0,0.0,NaN,-0.005,0.0025`,
+	} {
+		cells, err := geo.AreaToCellIDs(area)
+		require.ErrorIs(t, err, geo.ErrBadCoordSet, area)
+		require.Nil(t, cells)
+	}
+}
+
+func TestNormalizeLatLng(t *testing.T) {
+	lat, lng, err := geo.NormalizeLatLng(-90, -180)
+	require.NoError(t, err)
+	require.Equal(t, -90.0, lat)
+	require.Equal(t, 180.0, lng)
+
+	_, _, err = geo.NormalizeLatLng(-90.1, 0)
+	require.ErrorIs(t, err, geo.ErrBadCoordSet)
+}
+
+func TestParseAreaAcrossAntimeridian(t *testing.T) {
+	west, err := geo.AreaToCellIDs(`0.0,179.99,0.01,-180.0,-0.01,-180.0`)
+	require.NoError(t, err)
+	east, err := geo.AreaToCellIDs(`0.0,179.99,0.01,180.0,-0.01,180.0`)
+	require.NoError(t, err)
+	require.Equal(t, east, west)
+}
+
+func TestCircleCovering(t *testing.T) {
+	center := s2.LatLngFromDegrees(37.4, -122.1)
+	const radiusMeter = 5000.0
+	cells, err := geo.CircleCovering(center, radiusMeter)
+	require.NoError(t, err)
+
+	// Points just inside the circle in every direction must be covered,
+	// including those an inscribed polygon would miss between its vertices.
+	loop := s2.RegularLoop(s2.PointFromLatLng(center), geo.DistanceMetersToAngle(radiusMeter*0.999), 360)
+	for i, p := range loop.Vertices() {
+		require.True(t, cells.ContainsCellID(s2.CellIDFromLatLng(s2.LatLngFromPoint(p))), "vertex %d", i)
+	}
+}
+
+func TestCircleCoveringValidation(t *testing.T) {
+	center := s2.LatLngFromDegrees(37.4, -122.1)
+
+	_, err := geo.CircleCovering(center, 0)
+	require.ErrorIs(t, err, geo.ErrRadiusMustBeLargerThan0)
+
+	// A circle of 30km radius covers more than 2500km².
+	_, err = geo.CircleCovering(center, 30000)
+	require.True(t, errors.Is(err, geo.ErrAreaTooLarge))
+}
+
+func configureCoverings(t *testing.T, minLevel, maxLevel, maxCells int) {
+	previous := geo.RegionCoverer
+	t.Cleanup(func() { geo.RegionCoverer = previous })
+	require.NoError(t, geo.ConfigureCoverings(minLevel, maxLevel, maxCells))
+}
+
+func TestConfigureCoveringsValidation(t *testing.T) {
+	previous := geo.RegionCoverer
+	require.Error(t, geo.ConfigureCoverings(-1, 13, 0))
+	require.Error(t, geo.ConfigureCoverings(13, 31, 0))
+	require.Error(t, geo.ConfigureCoverings(14, 13, 0))
+	require.Error(t, geo.ConfigureCoverings(13, 13, -1))
+	require.Equal(t, previous, geo.RegionCoverer)
+	require.False(t, geo.MultiLevelCoverings())
+}
+
+func TestAdaptiveCoverings(t *testing.T) {
+	const maxCells = 16
+	configureCoverings(t, 8, 18, maxCells)
+	require.True(t, geo.MultiLevelCoverings())
+
+	center := s2.LatLngFromDegrees(37.4, -122.1)
+	small, err := geo.CircleCovering(center, 100)
+	require.NoError(t, err)
+	large, err := geo.CircleCovering(center, 25000)
+	require.NoError(t, err)
+
+	maxLevel := func(cells s2.CellUnion) int {
+		level := 0
+		for _, cell := range cells {
+			require.NoError(t, geo.ValidateCell(cell))
+			level = max(level, cell.Level())
+		}
+		return level
+	}
+	require.Greater(t, maxLevel(small), maxLevel(large))
+	require.LessOrEqual(t, len(large), 2*maxCells)
+
+	// Coverings are deterministic and contain no overlapping cells.
+	again, err := geo.CircleCovering(center, 25000)
+	require.NoError(t, err)
+	require.Equal(t, large, again)
+	require.True(t, large.IsValid())
+}
+
+func TestCoarsenCovering(t *testing.T) {
+	center := s2.LatLngFromDegrees(37.4, -122.1)
+	fine, err := geo.CircleCovering(center, 5000)
+	require.NoError(t, err)
+	require.Equal(t, fine, geo.CoarsenCovering(fine, 2))
+
+	configureCoverings(t, 8, 18, 64)
+	fine, err = geo.CircleCovering(center, 5000)
+	require.NoError(t, err)
+	coarse := geo.CoarsenCovering(fine, 2)
+	require.Less(t, len(coarse), len(fine))
+	require.True(t, coarse.Contains(fine))
+	for _, cell := range coarse {
+		require.NoError(t, geo.ValidateCell(cell))
+	}
+
+	// Coverings are not coarsened beyond the minimum cell level.
+	coarsest := geo.CoarsenCovering(fine, 30)
+	for _, cell := range coarsest {
+		require.Equal(t, 8, cell.Level())
+	}
+}
+
+func TestValidateCellFollowsConfiguredLevels(t *testing.T) {
+	cell := s2.CellIDFromLatLng(s2.LatLngFromDegrees(37.4, -122.1))
+	require.NoError(t, geo.ValidateCell(cell.Parent(geo.DefaultMinimumCellLevel)))
+	require.Error(t, geo.ValidateCell(cell.Parent(12)))
+
+	configureCoverings(t, 10, 14, 8)
+	require.NoError(t, geo.ValidateCell(cell.Parent(12)))
+	require.Error(t, geo.ValidateCell(cell.Parent(15)))
+}
+
+func TestNormalizeCells(t *testing.T) {
+	parent := s2.CellIDFromLatLng(s2.LatLngFromDegrees(37.4, -122.1)).Parent(geo.DefaultMinimumCellLevel - 1)
+	children := parent.Children()
+	other := children[0].Next().Next().Next().Next()
+
+	// Duplicates are removed and all the children of a cell, once merged
+	// into it, are returned at the level of coverings.
+	denormalized := s2.CellUnion{other, children[2], children[0], other, children[1], children[3], children[0]}
+	normalized := geo.NormalizeCells(denormalized)
+	require.Equal(t, s2.CellUnion{children[0], children[1], children[2], children[3], other}, normalized)
+	require.Len(t, denormalized, 7)
+
+	// Cells within other cells of the covering are removed.
+	configureCoverings(t, 10, 14, 8)
+	grandchild := children[1].ChildBegin()
+	require.Equal(t, s2.CellUnion{children[1]}, geo.NormalizeCells(s2.CellUnion{grandchild, children[1], grandchild}))
+
+	require.Empty(t, geo.NormalizeCells(nil))
+}
+
+func TestDescribeAreaCovering(t *testing.T) {
+	covering, err := geo.DescribeAreaCovering(`37.4047,-122.1474,37.4037,-122.1485,37.4035,-122.1466`)
+	require.NoError(t, err)
+	require.False(t, covering.ExceedsMaxArea())
+	require.Greater(t, covering.AreaKm2, 0.0)
+	cells, err := geo.AreaToCellIDs(`37.4047,-122.1474,37.4037,-122.1485,37.4035,-122.1466`)
+	require.NoError(t, err)
+	require.Equal(t, cells, covering.Cells)
+
+	// Areas too large are described without cells.
+	covering, err = geo.DescribeAreaCovering(`0,0,0,1,1,1,1,0`)
+	require.NoError(t, err)
+	require.True(t, covering.ExceedsMaxArea())
+	require.Empty(t, covering.Cells)
+
+	_, err = geo.DescribeAreaCovering(`0,0,0,1`)
+	require.Error(t, err)
+}
@@ -0,0 +1,4 @@
+// Package headers provides the HTTP header policy of the DSS: cross-origin
+// resource sharing (CORS) for browser-based clients and security response
+// headers.
+package headers
@@ -0,0 +1,105 @@
+package headers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// DefaultAllowedMethods are the methods allowed in cross-origin requests
+	// when none are configured.
+	DefaultAllowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete}
+
+	// DefaultAllowedHeaders are the request headers allowed in cross-origin
+	// requests when none are configured. Authorization must be allowed for
+	// browsers to send access tokens.
+	DefaultAllowedHeaders = []string{"Authorization", "Content-Type"}
+
+	securityHeaders = map[string]string{
+		"X-Content-Type-Options": "nosniff",
+		"X-Frame-Options":        "DENY",
+		"Referrer-Policy":        "no-referrer",
+	}
+)
+
+// Policy describes the headers added to responses.
+type Policy struct {
+	// AllowedOrigins are the origins allowed to make cross-origin requests.
+	// "*" allows any origin. CORS is disabled if empty.
+	AllowedOrigins []string
+	// AllowedMethods are the methods allowed in cross-origin requests.
+	AllowedMethods []string
+	// AllowedHeaders are the request headers allowed in cross-origin requests.
+	AllowedHeaders []string
+	// MaxAge is how long browsers may cache the result of a preflight request.
+	// Not advertised if zero.
+	MaxAge time.Duration
+	// SecurityHeaders enables headers instructing browsers not to sniff
+	// content types, frame responses or send referrers.
+	SecurityHeaders bool
+}
+
+// SplitList splits a comma-separated flag value, dropping empty items.
+func SplitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (p Policy) allowsOrigin(origin string) bool {
+	for _, o := range p.AllowedOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware returns an http.Handler applying p to the responses of next and
+// answering CORS preflight requests from allowed origins.
+func (p Policy) Middleware(next http.Handler) http.Handler {
+	methods := p.AllowedMethods
+	if len(methods) == 0 {
+		methods = DefaultAllowedMethods
+	}
+	allowHeaders := p.AllowedHeaders
+	if len(allowHeaders) == 0 {
+		allowHeaders = DefaultAllowedHeaders
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeadersValue := strings.Join(allowHeaders, ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		if p.SecurityHeaders {
+			for k, v := range securityHeaders {
+				h.Set(k, v)
+			}
+		}
+
+		origin := r.Header.Get("Origin")
+		if origin == "" || !p.allowsOrigin(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h.Add("Vary", "Origin")
+		h.Set("Access-Control-Allow-Origin", origin)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			h.Set("Access-Control-Allow-Methods", allowMethods)
+			h.Set("Access-Control-Allow-Headers", allowHeadersValue)
+			if p.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(int(p.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,57 @@
+package headers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestPreflight(t *testing.T) {
+	h := Policy{AllowedOrigins: []string{"https://tools.example.com"}, MaxAge: time.Minute}.Middleware(okHandler)
+
+	r := httptest.NewRequest(http.MethodOptions, "/rid/v2/dss/subscriptions", nil)
+	r.Header.Set("Origin", "https://tools.example.com")
+	r.Header.Set("Access-Control-Request-Method", http.MethodPut)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, "https://tools.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "GET, POST, PUT, DELETE", w.Header().Get("Access-Control-Allow-Methods"))
+	require.Equal(t, "Authorization, Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+	require.Equal(t, "60", w.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestDisallowedOrigin(t *testing.T) {
+	h := Policy{AllowedOrigins: []string{"https://tools.example.com"}}.Middleware(okHandler)
+
+	r := httptest.NewRequest(http.MethodGet, "/aux/v1/version", nil)
+	r.Header.Set("Origin", "https://evil.example.com")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	h := Policy{SecurityHeaders: true}.Middleware(okHandler)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/aux/v1/version", nil))
+
+	require.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	require.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+}
+
+func TestSplitList(t *testing.T) {
+	require.Equal(t, []string{"a", "b"}, SplitList(" a, ,b,"))
+	require.Empty(t, SplitList(""))
+}
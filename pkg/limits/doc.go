@@ -0,0 +1,4 @@
+// Package limits bounds the number of entities returned by the search
+// endpoints of the DSS, either truncating the results or rejecting searches
+// that find too many entities, as configured by the operator.
+package limits
@@ -0,0 +1,141 @@
+package limits
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/interuss/dss/pkg/api"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/stacktrace"
+)
+
+const (
+	// MaxResultsHeader is the request header through which a client may
+	// lower the maximum number of entities returned by a search.
+	MaxResultsHeader = "DSS-Max-Results"
+
+	// TruncatedHeader is the response header set to "true" when the entities
+	// found by a search were truncated.
+	TruncatedHeader = "DSS-Results-Truncated"
+)
+
+// Overflow describes how searches finding more entities than allowed are
+// handled.
+type Overflow string
+
+const (
+	// OverflowTruncate returns the allowed number of entities and sets
+	// TruncatedHeader in the response.
+	OverflowTruncate Overflow = "truncate"
+
+	// OverflowReject fails the search, instructing the client to narrow its
+	// search area.
+	OverflowReject Overflow = "reject"
+)
+
+// Policy bounds the number of entities returned by searches.
+type Policy struct {
+	// MaxResults is the maximum number of entities returned by a search. If
+	// zero, searches are only bounded by dssmodels.MaxResultLimit.
+	MaxResults int
+	// Overflow is how searches finding more than MaxResults entities are
+	// handled.
+	Overflow Overflow
+}
+
+// Validate returns an error if p cannot be enforced.
+func (p Policy) Validate() error {
+	// Stores return at most dssmodels.MaxResultLimit entities, so a larger
+	// limit could never be detected as exceeded.
+	if p.MaxResults < 0 || p.MaxResults >= dssmodels.MaxResultLimit {
+		return stacktrace.NewError("Maximum number of results must be between 0 and %d, got %d", dssmodels.MaxResultLimit-1, p.MaxResults)
+	}
+	switch p.Overflow {
+	case OverflowTruncate, OverflowReject:
+		return nil
+	default:
+		return stacktrace.NewError("Unknown overflow behavior `%s`; expected %s or %s", p.Overflow, OverflowTruncate, OverflowReject)
+	}
+}
+
+// state is the limit applying to a single request.
+type state struct {
+	maxResults int
+	overflow   Overflow
+	truncated  bool
+}
+
+type contextKey struct{}
+
+// Middleware returns an http.Handler making the limit of p, possibly lowered
+// by the MaxResultsHeader of the request, available to the searches of next
+// through Apply.
+func (p Policy) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := &state{maxResults: p.MaxResults, overflow: p.Overflow}
+		if v := r.Header.Get(MaxResultsHeader); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				api.WriteJSON(w, http.StatusBadRequest, map[string]string{
+					"message": fmt.Sprintf("Invalid %s header `%s`: expected a positive integer", MaxResultsHeader, v)})
+				return
+			}
+			if s.maxResults == 0 || n < s.maxResults {
+				s.maxResults = n
+			}
+		}
+		next.ServeHTTP(&responseWriter{ResponseWriter: w, state: s}, r.WithContext(context.WithValue(r.Context(), contextKey{}, s)))
+	})
+}
+
+// responseWriter sets TruncatedHeader in responses to searches that were
+// truncated.
+type responseWriter struct {
+	http.ResponseWriter
+	state       *state
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader && w.state.truncated {
+		w.Header().Set(TruncatedHeader, "true")
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Apply enforces the limit of the request in ctx on the entities found by a
+// search and returns those to respond with. It returns an error with code
+// dsserr.AreaTooLarge if the search must be rejected.
+func Apply[T any](ctx context.Context, entities []T) ([]T, error) {
+	s, ok := ctx.Value(contextKey{}).(*state)
+	if !ok || s.maxResults == 0 || len(entities) <= s.maxResults {
+		return entities, nil
+	}
+	if s.overflow == OverflowReject {
+		return nil, stacktrace.NewErrorWithCode(dsserr.AreaTooLarge,
+			"Search found more than %d entities; narrow the search area or time range", s.maxResults)
+	}
+	s.truncated = true
+	return entities[:s.maxResults], nil
+}
+
+// MaxResults returns the maximum number of entities returned by a search in
+// the request of ctx, dssmodels.MaxResultLimit if unbounded.
+func MaxResults(ctx context.Context) int {
+	s, ok := ctx.Value(contextKey{}).(*state)
+	if !ok || s.maxResults == 0 {
+		return dssmodels.MaxResultLimit
+	}
+	return s.maxResults
+}
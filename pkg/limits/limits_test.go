@@ -0,0 +1,100 @@
+package limits
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	require.NoError(t, Policy{Overflow: OverflowTruncate}.Validate())
+	require.NoError(t, Policy{MaxResults: 100, Overflow: OverflowReject}.Validate())
+	require.Error(t, Policy{MaxResults: -1, Overflow: OverflowTruncate}.Validate())
+	require.Error(t, Policy{MaxResults: dssmodels.MaxResultLimit, Overflow: OverflowTruncate}.Validate())
+	require.Error(t, Policy{MaxResults: 100, Overflow: "drop"}.Validate())
+}
+
+func TestApplyWithoutPolicy(t *testing.T) {
+	entities, err := Apply(context.Background(), []int{1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2, 3}, entities)
+}
+
+func TestMaxResults(t *testing.T) {
+	require.Equal(t, dssmodels.MaxResultLimit, MaxResults(context.Background()))
+
+	var got int
+	handler := Policy{MaxResults: 10, Overflow: OverflowTruncate}.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = MaxResults(r.Context())
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/search", nil))
+	require.Equal(t, 10, got)
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	req.Header.Set(MaxResultsHeader, "5")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	require.Equal(t, 5, got)
+}
+
+// search serves a search finding n entities, recording those returned.
+func search(n int, returned *[]int, err *error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		found := make([]int, n)
+		*returned, *err = Apply(r.Context(), found)
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		policy    Policy
+		header    string
+		found     int
+		status    int
+		returned  int
+		truncated bool
+		rejected  bool
+	}{
+		{"unlimited", Policy{Overflow: OverflowTruncate}, "", 20, http.StatusOK, 20, false, false},
+		{"within limit", Policy{MaxResults: 10, Overflow: OverflowTruncate}, "", 10, http.StatusOK, 10, false, false},
+		{"truncated", Policy{MaxResults: 10, Overflow: OverflowTruncate}, "", 11, http.StatusOK, 10, true, false},
+		{"rejected", Policy{MaxResults: 10, Overflow: OverflowReject}, "", 11, http.StatusOK, 0, false, true},
+		{"lowered by client", Policy{MaxResults: 10, Overflow: OverflowTruncate}, "5", 8, http.StatusOK, 5, true, false},
+		{"client cannot raise", Policy{MaxResults: 10, Overflow: OverflowTruncate}, "50", 20, http.StatusOK, 10, true, false},
+		{"client limit only", Policy{Overflow: OverflowTruncate}, "5", 8, http.StatusOK, 5, true, false},
+		{"invalid header", Policy{Overflow: OverflowTruncate}, "zero", 8, http.StatusBadRequest, 0, false, false},
+		{"non-positive header", Policy{Overflow: OverflowTruncate}, "0", 8, http.StatusBadRequest, 0, false, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				returned []int
+				err      error
+				rec      = httptest.NewRecorder()
+				req      = httptest.NewRequest(http.MethodGet, "/search", nil)
+			)
+			if tc.header != "" {
+				req.Header.Set(MaxResultsHeader, tc.header)
+			}
+			tc.policy.Middleware(search(tc.found, &returned, &err)).ServeHTTP(rec, req)
+
+			require.Equal(t, tc.status, rec.Code)
+			require.Len(t, returned, tc.returned)
+			if tc.truncated {
+				require.Equal(t, "true", rec.Header().Get(TruncatedHeader))
+			} else {
+				require.Empty(t, rec.Header().Get(TruncatedHeader))
+			}
+			if tc.rejected {
+				require.Equal(t, dsserr.AreaTooLarge, stacktrace.GetCode(err))
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type contextKey struct{}
+
+// contextFields are the fields attributing the log entries of a request. They
+// are shared by all the contexts derived from the request context so that
+// fields learnt while handling the request, e.g. the authenticated owner, are
+// visible to the code logging afterwards.
+type contextFields struct {
+	mu     sync.Mutex
+	fields []zap.Field
+}
+
+// entityIDPattern matches the UUIDs identifying entities in request paths.
+var entityIDPattern = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+
+// NewContext returns a copy of ctx able to carry fields added with
+// WithFields, if ctx does not carry fields already.
+func NewContext(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(contextKey{}).(*contextFields); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, contextKey{}, &contextFields{})
+}
+
+// NewRequestContext returns a copy of the context of r carrying the fields
+// attributing r: the method, i.e. the HTTP method and path with entity IDs
+// replaced by {id}, and the ID of the entity the request is about, if any.
+func NewRequestContext(r *http.Request) context.Context {
+	ctx := NewContext(r.Context())
+	path := r.URL.Path
+	WithFields(ctx, zap.String("method", r.Method+" "+entityIDPattern.ReplaceAllLiteralString(path, "{id}")))
+	if id := entityIDPattern.FindString(path); id != "" {
+		WithFields(ctx, zap.String("entity_id", id))
+	}
+	return ctx
+}
+
+// WithFields adds fields to the fields carried by ctx, which are then added to
+// the loggers returned by WithValuesFromContext for ctx or any context derived
+// from it. WithFields does nothing if ctx was not returned by NewContext.
+func WithFields(ctx context.Context, fields ...zap.Field) {
+	cf, ok := ctx.Value(contextKey{}).(*contextFields)
+	if !ok {
+		return
+	}
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+	cf.fields = append(cf.fields, fields...)
+}
+
+// StringField returns the value of the string field "key" carried by ctx, if
+// any, e.g. the authenticated owner of a request.
+func StringField(ctx context.Context, key string) (string, bool) {
+	cf, ok := ctx.Value(contextKey{}).(*contextFields)
+	if !ok {
+		return "", false
+	}
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+	for _, f := range cf.fields {
+		if f.Key == key && f.Type == zapcore.StringType {
+			return f.String, true
+		}
+	}
+	return "", false
+}
+
+// WithValuesFromContext augments logger with relevant fields from ctx and returns
+// the resulting logger.
+func WithValuesFromContext(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	cf, ok := ctx.Value(contextKey{}).(*contextFields)
+	if !ok {
+		return logger
+	}
+	cf.mu.Lock()
+	defer cf.mu.Unlock()
+	if len(cf.fields) == 0 {
+		return logger
+	}
+	return logger.With(cf.fields...)
+}
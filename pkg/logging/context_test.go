@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNewRequestContext(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	r := httptest.NewRequest(http.MethodPut, "/rid/v2/dss/identification_service_areas/189ec22f-5e61-418a-940b-36de2d201fd5", nil)
+	ctx := NewRequestContext(r)
+
+	// Fields added to derived contexts are shared with the request context.
+	derived, cancel := context.WithCancel(ctx)
+	defer cancel()
+	WithFields(derived, zap.String("owner", "uss1"))
+
+	WithValuesFromContext(ctx, zap.New(core)).Info("test")
+	require.Equal(t, map[string]interface{}{
+		"method":    "PUT /rid/v2/dss/identification_service_areas/{id}",
+		"entity_id": "189ec22f-5e61-418a-940b-36de2d201fd5",
+		"owner":     "uss1",
+	}, logs.All()[0].ContextMap())
+}
+
+func TestWithValuesFromContextWithoutFields(t *testing.T) {
+	logger := zap.NewNop()
+	ctx := context.Background()
+	WithFields(ctx, zap.String("owner", "uss1"))
+	require.Same(t, logger, WithValuesFromContext(ctx, logger))
+	require.Same(t, logger, WithValuesFromContext(NewContext(ctx), logger))
+}
+
+func TestStringField(t *testing.T) {
+	_, ok := StringField(context.Background(), "owner")
+	require.False(t, ok)
+
+	ctx := NewContext(context.Background())
+	WithFields(ctx, zap.Int("count", 1), zap.String("owner", "uss1"))
+	owner, ok := StringField(ctx, "owner")
+	require.True(t, ok)
+	require.Equal(t, "uss1", owner)
+	_, ok = StringField(ctx, "count")
+	require.False(t, ok)
+}
+
+func TestHTTPMiddlewareLogsRequestAttribution(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	handler := HTTPMiddleware(zap.New(core), false, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WithFields(r.Context(), zap.String("owner", "uss1"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/aux/v1/version", nil))
+
+	fields := logs.All()[0].ContextMap()
+	require.Equal(t, "GET /aux/v1/version", fields["method"])
+	require.Equal(t, "uss1", fields["owner"])
+	require.NotContains(t, fields, "entity_id")
+}
@@ -40,9 +40,12 @@ func (w *tracingResponseWriter) WriteHeader(statusCode int) {
 }
 
 // HTTPMiddleware installs a logging http.Handler that logs requests and
-// selected aspects of responses to 'logger'.
+// selected aspects of responses to 'logger'. The context of requests carries
+// the fields attributing them (see NewRequestContext), which are added to the
+// loggers returned by WithValuesFromContext while handling them.
 func HTTPMiddleware(logger *zap.Logger, dump bool, handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(NewRequestContext(r))
 		var (
 			logger = logger
 			start  = time.Now()
@@ -70,6 +73,7 @@ func HTTPMiddleware(logger *zap.Logger, dump bool, handler http.Handler) http.Ha
 		}
 
 		handler.ServeHTTP(trw, r)
+		logger = WithValuesFromContext(r.Context(), logger)
 
 		if dump {
 			// dump response in logs
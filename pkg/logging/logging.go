@@ -1,7 +1,6 @@
 package logging
 
 import (
-	"context"
 	"os"
 
 	"go.uber.org/zap"
@@ -74,9 +73,8 @@ func Configure(level string, format string) error {
 	return setUpLogger(level, format)
 }
 
-// WithValuesFromContext augments logger with relevant fields from ctx and returns
-// the resulting logger.
-func WithValuesFromContext(ctx context.Context, logger *zap.Logger) *zap.Logger {
-	// Naive implementation for now, meant to evolve over time.
-	return logger
+// SetLevel changes the level of the loggers created by Configure while they
+// are in use.
+func SetLevel(level string) error {
+	return DefaultLevel.UnmarshalText([]byte(level))
 }
@@ -0,0 +1,4 @@
+// Package metrics counts the HTTP requests served by the DSS and those answered
+// with an error, optionally attributing them to the authenticated owner with a
+// bounded number of distinct owner labels.
+package metrics
@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/interuss/dss/pkg/logging"
+	"github.com/interuss/stacktrace"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// OtherOwner labels the requests of authenticated owners not labelled
+	// individually.
+	OtherOwner = "other"
+	// NoOwner labels the requests not attributed to an owner, e.g. rejected
+	// before authentication.
+	NoOwner = "none"
+)
+
+var (
+	requests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dss_http_requests_total",
+		Help: "Number of HTTP requests served, by method, status code and owner.",
+	}, []string{"method", "code", "owner"})
+	requestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dss_http_request_errors_total",
+		Help: "Number of HTTP requests answered with a 4xx or 5xx status code, by method, status code and owner.",
+	}, []string{"method", "code", "owner"})
+)
+
+// OwnerLabels bounds the cardinality of the owner label of request metrics.
+// Owners in the allow-list, or else the first MaxOwners owners seen, are
+// labelled individually while the others are counted under OtherOwner. Series
+// cannot be relabelled once exported, so the owners labelled without an
+// allow-list are those seen first since the process started rather than the
+// busiest ones. The zero value labels no owner individually.
+type OwnerLabels struct {
+	mu        sync.Mutex
+	allowed   map[string]bool
+	maxOwners int
+	seen      map[string]bool
+}
+
+// NewOwnerLabels returns OwnerLabels labelling the owners in allowed if not
+// empty, or else at most maxOwners owners. Requests are not labelled by owner
+// if neither is set.
+func NewOwnerLabels(allowed []string, maxOwners int) (*OwnerLabels, error) {
+	l := &OwnerLabels{}
+	if err := l.Configure(allowed, maxOwners); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return l, nil
+}
+
+// Configure replaces the owners labelled by l, as NewOwnerLabels selects
+// them, e.g. when the configuration is reloaded. The owners already labelled
+// without an allow-list keep their label and count towards maxOwners, so that
+// reconfiguring l never exports more owner labels than the largest maximum
+// configured.
+func (l *OwnerLabels) Configure(allowed []string, maxOwners int) error {
+	if maxOwners < 0 {
+		return stacktrace.NewError("Maximum number of owner labels %d is negative", maxOwners)
+	}
+	if len(allowed) > 0 && maxOwners > 0 {
+		return stacktrace.NewError("Owner labels are limited by either an allow-list or a maximum number, not both")
+	}
+	var allowedSet map[string]bool
+	if len(allowed) > 0 {
+		allowedSet = map[string]bool{}
+		for _, owner := range allowed {
+			allowedSet[owner] = true
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.allowed = allowedSet
+	l.maxOwners = maxOwners
+	if l.seen == nil {
+		l.seen = map[string]bool{}
+	}
+	return nil
+}
+
+// Label returns the value of the owner label of the requests of owner.
+func (l *OwnerLabels) Label(owner string) string {
+	if owner == "" {
+		return NoOwner
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.allowed != nil {
+		if l.allowed[owner] {
+			return owner
+		}
+		return OtherOwner
+	}
+	if l.seen[owner] {
+		return owner
+	}
+	if len(l.seen) < l.maxOwners {
+		l.seen[owner] = true
+		return owner
+	}
+	return OtherOwner
+}
+
+// statusResponseWriter records the status code of a response.
+type statusResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *statusResponseWriter) WriteHeader(code int) {
+	if w.statusCode == 0 {
+		w.statusCode = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusResponseWriter) Write(b []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Middleware returns an http.Handler counting the requests passed to next and
+// those answered with an error. Requests are attributed to the owner recorded
+// in their logging context while authorizing them, so Middleware must be
+// installed within logging.HTTPMiddleware.
+func (l *OwnerLabels) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+		if sw.statusCode == 0 {
+			sw.statusCode = http.StatusOK
+		}
+		owner, _ := logging.StringField(r.Context(), "owner")
+		labels := []string{r.Method, strconv.Itoa(sw.statusCode), l.Label(owner)}
+		requests.WithLabelValues(labels...).Inc()
+		if sw.statusCode >= http.StatusBadRequest {
+			requestErrors.WithLabelValues(labels...).Inc()
+		}
+	})
+}
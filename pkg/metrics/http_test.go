@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/interuss/dss/pkg/logging"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewOwnerLabels(t *testing.T) {
+	_, err := NewOwnerLabels(nil, -1)
+	require.Error(t, err)
+	_, err = NewOwnerLabels([]string{"uss1"}, 2)
+	require.Error(t, err)
+}
+
+func TestOwnerLabelsDisabled(t *testing.T) {
+	l, err := NewOwnerLabels(nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, OtherOwner, l.Label("uss1"))
+	require.Equal(t, NoOwner, l.Label(""))
+}
+
+func TestOwnerLabelsAllowList(t *testing.T) {
+	l, err := NewOwnerLabels([]string{"uss1", "uss2"}, 0)
+	require.NoError(t, err)
+	require.Equal(t, "uss1", l.Label("uss1"))
+	require.Equal(t, "uss2", l.Label("uss2"))
+	require.Equal(t, OtherOwner, l.Label("uss3"))
+}
+
+func TestOwnerLabelsMaxOwners(t *testing.T) {
+	l, err := NewOwnerLabels(nil, 2)
+	require.NoError(t, err)
+	require.Equal(t, "uss1", l.Label("uss1"))
+	require.Equal(t, "uss2", l.Label("uss2"))
+	require.Equal(t, OtherOwner, l.Label("uss3"))
+	require.Equal(t, "uss1", l.Label("uss1"))
+}
+
+func TestOwnerLabelsConfigure(t *testing.T) {
+	l, err := NewOwnerLabels(nil, 2)
+	require.NoError(t, err)
+	require.Equal(t, "uss1", l.Label("uss1"))
+	require.Equal(t, "uss2", l.Label("uss2"))
+
+	require.NoError(t, l.Configure(nil, 2))
+	require.Equal(t, OtherOwner, l.Label("uss3"))
+	require.Equal(t, "uss2", l.Label("uss2"))
+
+	require.NoError(t, l.Configure(nil, 3))
+	require.Equal(t, "uss3", l.Label("uss3"))
+	require.Equal(t, OtherOwner, l.Label("uss4"))
+
+	require.Error(t, l.Configure([]string{"uss4"}, 1))
+	require.Equal(t, OtherOwner, l.Label("uss4"))
+	require.NoError(t, l.Configure([]string{"uss4"}, 0))
+	require.Equal(t, "uss4", l.Label("uss4"))
+	require.Equal(t, OtherOwner, l.Label("uss1"))
+}
+
+func TestMiddleware(t *testing.T) {
+	l, err := NewOwnerLabels([]string{"uss-metrics-test"}, 0)
+	require.NoError(t, err)
+	handler := logging.HTTPMiddleware(zap.NewNop(), false, l.Middleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logging.WithFields(r.Context(), zap.String("owner", "uss-metrics-test"))
+			if r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte("ok"))
+		})))
+
+	for _, method := range []string{http.MethodGet, http.MethodGet, http.MethodDelete} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/v1/dss/subscriptions", nil))
+	}
+
+	require.Equal(t, 2.0, testutil.ToFloat64(requests.WithLabelValues(http.MethodGet, "200", "uss-metrics-test")))
+	require.Equal(t, 1.0, testutil.ToFloat64(requests.WithLabelValues(http.MethodDelete, "404", "uss-metrics-test")))
+	require.Equal(t, 0.0, testutil.ToFloat64(requestErrors.WithLabelValues(http.MethodGet, "200", "uss-metrics-test")))
+	require.Equal(t, 1.0, testutil.ToFloat64(requestErrors.WithLabelValues(http.MethodDelete, "404", "uss-metrics-test")))
+}
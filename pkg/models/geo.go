@@ -11,10 +11,6 @@ import (
 const (
 	// TimeFormatRFC3339 is the string used for RFC3339
 	TimeFormatRFC3339 = "RFC3339"
-	minLat            = -90.0
-	maxLat            = 90.0
-	minLng            = -180.0
-	maxLng            = 180.0
 	UnitsM            = "M"
 	ReferenceW84      = "W84"
 )
@@ -78,6 +74,23 @@ type Geometry interface {
 	CalculateCovering() (s2.CellUnion, error)
 }
 
+// IntersectsSearchArea returns whether g intersects area. Geometries other
+// than polygons and circles, whose exact shape is unknown, are deemed to
+// intersect it.
+func IntersectsSearchArea(g Geometry, area *geo.SearchArea) bool {
+	switch g := g.(type) {
+	case *GeoPolygon:
+		vertices := make([]s2.LatLng, 0, len(g.Vertices))
+		for _, v := range g.Vertices {
+			vertices = append(vertices, s2.LatLngFromDegrees(v.Lat, v.Lng))
+		}
+		return area.IntersectsPolygon(vertices)
+	case *GeoCircle:
+		return area.IntersectsCircle(s2.LatLngFromDegrees(g.Center.Lat, g.Center.Lng), float64(g.RadiusMeter))
+	}
+	return true
+}
+
 // GeometryFunc is an implementation of Geometry
 type GeometryFunc func() (s2.CellUnion, error)
 
@@ -221,20 +234,12 @@ type GeoCircle struct {
 
 // CalculateCovering returns the spatial covering of gc.
 func (gc *GeoCircle) CalculateCovering() (s2.CellUnion, error) {
-	if (gc.Center.Lat > maxLat) || (gc.Center.Lat < minLat) || (gc.Center.Lng > maxLng) || (gc.Center.Lng < minLng) {
-		return nil, geo.ErrBadCoordSet
-	}
-
-	if !(gc.RadiusMeter > 0) {
-		return nil, geo.ErrRadiusMustBeLargerThan0
+	lat, lng, err := geo.NormalizeLatLng(gc.Center.Lat, gc.Center.Lng)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Invalid circle center")
 	}
 
-	// TODO: Use an S2 Cap as an inscribed polygon does not fully cover the defined circle
-	return geo.RegionCoverer.Covering(s2.RegularLoop(
-		s2.PointFromLatLng(s2.LatLngFromDegrees(gc.Center.Lat, gc.Center.Lng)),
-		geo.DistanceMetersToAngle(float64(gc.RadiusMeter)),
-		20,
-	)), nil
+	return geo.CircleCovering(s2.LatLngFromDegrees(lat, lng), float64(gc.RadiusMeter))
 }
 
 // GeoPolygon models an enclosed area on the earth.
@@ -253,12 +258,13 @@ func (gp *GeoPolygon) CalculateCovering() (s2.CellUnion, error) {
 	if gp == nil {
 		return nil, geo.ErrBadCoordSet
 	}
-	for _, v := range gp.Vertices {
+	for i, v := range gp.Vertices {
 		// ensure that coordinates passed are actually on earth
-		if (v.Lat > maxLat) || (v.Lat < minLat) || (v.Lng > maxLng) || (v.Lng < minLng) {
-			return nil, geo.ErrBadCoordSet
+		lat, lng, err := geo.NormalizeLatLng(v.Lat, v.Lng)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Invalid coordinates for vertex %d", i)
 		}
-		points = append(points, s2.PointFromLatLng(s2.LatLngFromDegrees(v.Lat, v.Lng)))
+		points = append(points, s2.PointFromLatLng(s2.LatLngFromDegrees(lat, lng)))
 	}
 	if len(points) < 3 {
 		return nil, geo.ErrNotEnoughPointsInPolygon
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/golang/geo/s2"
+	"github.com/interuss/dss/pkg/geo"
 	"github.com/stretchr/testify/require"
 )
 
@@ -53,3 +54,25 @@ func TestPolygonCovering(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, want, got)
 }
+
+func TestCircleCovering(t *testing.T) {
+	got, err := (&GeoCircle{Center: LatLngPoint{Lat: 37.4, Lng: -122.1}, RadiusMeter: 1000}).CalculateCovering()
+	require.NoError(t, err)
+	require.True(t, got.ContainsCellID(s2.CellIDFromLatLng(s2.LatLngFromDegrees(37.4, -122.1))))
+
+	_, err = (&GeoCircle{Center: LatLngPoint{Lat: 37.4, Lng: -122.1}}).CalculateCovering()
+	require.Error(t, err)
+
+	_, err = (&GeoCircle{Center: LatLngPoint{Lat: 91, Lng: -122.1}, RadiusMeter: 1000}).CalculateCovering()
+	require.Error(t, err)
+}
+
+func TestIntersectsSearchArea(t *testing.T) {
+	area, err := geo.ParseSearchArea("46.90,7.40,46.90,7.41,46.91,7.41,46.91,7.40")
+	require.NoError(t, err)
+
+	require.True(t, IntersectsSearchArea(&GeoPolygon{Vertices: []*LatLngPoint{{Lat: 46.902, Lng: 7.402}, {Lat: 46.902, Lng: 7.404}, {Lat: 46.904, Lng: 7.404}}}, area))
+	require.False(t, IntersectsSearchArea(&GeoPolygon{Vertices: []*LatLngPoint{{Lat: 46.902, Lng: 7.412}, {Lat: 46.902, Lng: 7.414}, {Lat: 46.904, Lng: 7.414}}}, area))
+	require.False(t, IntersectsSearchArea(&GeoCircle{Center: LatLngPoint{Lat: 46.905, Lng: 7.42}, RadiusMeter: 500}, area))
+	require.True(t, IntersectsSearchArea(nil, area))
+}
@@ -0,0 +1,4 @@
+// Package payload bounds the size of HTTP request bodies accepted by the DSS
+// and handles gzip content coding: compressed request bodies are decompressed
+// and, if enabled, responses are compressed for clients accepting it.
+package payload
@@ -0,0 +1,144 @@
+package payload
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/interuss/dss/pkg/api"
+	"github.com/interuss/stacktrace"
+)
+
+const gzipEncoding = "gzip"
+
+// Policy describes how request and response bodies are handled.
+type Policy struct {
+	// MaxRequestBytes bounds the size of request bodies, after
+	// decompression. Unlimited if 0.
+	MaxRequestBytes int64
+	// CompressResponses enables the gzip compression of responses to clients
+	// accepting it.
+	CompressResponses bool
+}
+
+// Validate returns an error if p is not a valid policy.
+func (p Policy) Validate() error {
+	if p.MaxRequestBytes < 0 {
+		return stacktrace.NewError("Maximum request body size %d is negative", p.MaxRequestBytes)
+	}
+	return nil
+}
+
+var gzipWriters = sync.Pool{New: func() interface{} { return gzip.NewWriter(io.Discard) }}
+
+// Middleware returns an http.Handler enforcing p on the requests passed to
+// next and their responses. Requests whose declared body size exceeds
+// MaxRequestBytes are rejected with 413; bodies exceeding it otherwise, e.g.
+// once decompressed, fail to be read entirely and are rejected by next as
+// malformed.
+func (p Policy) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p.MaxRequestBytes > 0 && r.ContentLength > p.MaxRequestBytes {
+			api.WriteJSON(w, http.StatusRequestEntityTooLarge, map[string]string{
+				"message": fmt.Sprintf("Request body of %d bytes exceeds the maximum of %d bytes", r.ContentLength, p.MaxRequestBytes)})
+			return
+		}
+		if isGzip(r.Header.Get("Content-Encoding")) {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				api.WriteJSON(w, http.StatusBadRequest, map[string]string{
+					"message": "Invalid gzip request body"})
+				return
+			}
+			defer zr.Close()
+			r.Body = zr
+			r.Header.Del("Content-Encoding")
+			r.Header.Del("Content-Length")
+			r.ContentLength = -1
+		}
+		if p.MaxRequestBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, p.MaxRequestBytes)
+		}
+
+		if !p.CompressResponses || r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		zw := gzipWriters.Get().(*gzip.Writer)
+		defer gzipWriters.Put(zw)
+		gw := &gzipResponseWriter{ResponseWriter: w, zw: zw}
+		next.ServeHTTP(gw, r)
+		// The status has already been sent if flushing fails; the client then
+		// detects the truncated gzip stream.
+		_ = gw.close()
+	})
+}
+
+// gzipResponseWriter compresses the bodies of responses that may have one.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	zw          *gzip.Writer
+	wroteHeader bool
+	compress    bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.compress = code != http.StatusNoContent && code != http.StatusNotModified && w.Header().Get("Content-Encoding") == ""
+	if w.compress {
+		w.Header().Set("Content-Encoding", gzipEncoding)
+		w.Header().Del("Content-Length")
+		w.zw.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.compress {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.zw.Write(b)
+}
+
+// close flushes the compressed body, if any.
+func (w *gzipResponseWriter) close() error {
+	if !w.compress {
+		return nil
+	}
+	return w.zw.Close()
+}
+
+// isGzip returns whether the Content-Encoding header value encoding designates
+// gzip.
+func isGzip(encoding string) bool {
+	encoding = strings.ToLower(strings.TrimSpace(encoding))
+	return encoding == gzipEncoding || encoding == "x-gzip"
+}
+
+// acceptsGzip returns whether the Accept-Encoding header value accept allows
+// gzip responses.
+func acceptsGzip(accept string) bool {
+	for _, coding := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(coding, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != gzipEncoding && name != "x-gzip" && name != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); ok && strings.Trim(q, "0.") == "" {
+			// q=0 rejects the coding.
+			continue
+		}
+		return true
+	}
+	return false
+}
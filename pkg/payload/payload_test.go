@@ -0,0 +1,137 @@
+package payload
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// echo responds with the request body, or 400 if it cannot be read.
+var echo = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	_, _ = w.Write(body)
+})
+
+func gzipped(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestPolicyValidate(t *testing.T) {
+	require.NoError(t, Policy{}.Validate())
+	require.NoError(t, Policy{MaxRequestBytes: 1024, CompressResponses: true}.Validate())
+	require.Error(t, Policy{MaxRequestBytes: -1}.Validate())
+}
+
+func TestMaxRequestBytes(t *testing.T) {
+	handler := Policy{MaxRequestBytes: 10}.Middleware(echo)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/", strings.NewReader("0123456789")))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "0123456789", w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/", strings.NewReader("0123456789a")))
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// Bodies of unknown size are cut short.
+	r := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("0123456789a"))
+	r.ContentLength = -1
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGzipRequest(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		max    int64
+		body   string
+		status int
+	}{
+		{"unlimited", 0, strings.Repeat("a", 100), http.StatusOK},
+		{"within limit", 100, strings.Repeat("a", 100), http.StatusOK},
+		{"decompressed beyond limit", 50, strings.Repeat("a", 100), http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(gzipped(t, tc.body)))
+			r.Header.Set("Content-Encoding", "gzip")
+			w := httptest.NewRecorder()
+			Policy{MaxRequestBytes: tc.max}.Middleware(echo).ServeHTTP(w, r)
+			require.Equal(t, tc.status, w.Code)
+			if tc.status == http.StatusOK {
+				require.Equal(t, tc.body, w.Body.String())
+			}
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("not gzip"))
+	r.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	Policy{}.Middleware(echo).ServeHTTP(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCompressResponses(t *testing.T) {
+	body := strings.Repeat("{\"area\": 1}", 100)
+	for _, tc := range []struct {
+		name       string
+		compress   bool
+		accept     string
+		compressed bool
+	}{
+		{"disabled", false, "gzip", false},
+		{"not accepted", true, "", false},
+		{"accepted", true, "deflate, gzip;q=0.8", true},
+		{"any accepted", true, "*", true},
+		{"refused", true, "gzip;q=0", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+			r.Header.Set("Accept-Encoding", tc.accept)
+			w := httptest.NewRecorder()
+			Policy{CompressResponses: tc.compress}.Middleware(echo).ServeHTTP(w, r)
+			require.Equal(t, http.StatusOK, w.Code)
+			if !tc.compressed {
+				require.Empty(t, w.Header().Get("Content-Encoding"))
+				require.Equal(t, body, w.Body.String())
+				return
+			}
+			require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+			require.Less(t, w.Body.Len(), len(body))
+			zr, err := gzip.NewReader(w.Body)
+			require.NoError(t, err)
+			data, err := io.ReadAll(zr)
+			require.NoError(t, err)
+			require.Equal(t, body, string(data))
+		})
+	}
+}
+
+func TestCompressResponsesWithoutBody(t *testing.T) {
+	handler := Policy{CompressResponses: true}.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Empty(t, w.Header().Get("Content-Encoding"))
+	require.Zero(t, w.Body.Len())
+}
@@ -0,0 +1,5 @@
+// Package retry hints clients, through the Retry-After header of the responses
+// rejecting their requests for lack of capacity, when to retry them. Hints
+// grow with the rate of rejections and are spread at random, so that clients
+// honoring them back off under load without retrying in lockstep.
+package retry
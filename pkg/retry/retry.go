@@ -0,0 +1,113 @@
+package retry
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// AfterHeader is the response header hinting when to retry a request.
+const AfterHeader = "Retry-After"
+
+var hintSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+	Name:    "dss_retry_after_seconds",
+	Help:    "Retry-After hints of the responses rejecting requests with 429 or 503.",
+	Buckets: []float64{1, 2, 5, 10, 20, 30, 60, 120},
+})
+
+// Hints sets the Retry-After header of the responses rejecting requests for
+// lack of capacity, i.e. 429 Too Many Requests and 503 Service Unavailable.
+// The delay hinted grows with the square root of the number of such
+// responses over the last Window, from Base up to Max. A Retry-After header
+// set by the handler, e.g. the time until the datastore is pinged again, is a
+// lower bound of the delay. Up to half of the delay is added at random, such
+// that the clients rejected at the same time do not retry at the same time.
+type Hints struct {
+	// Base is the delay hinted while rejections are rare.
+	Base time.Duration
+	// Max bounds the delay derived from the rate of rejections.
+	Max time.Duration
+	// Window is the time constant with which past rejections are forgotten.
+	Window time.Duration
+
+	mu sync.Mutex
+	// rejections is the exponentially decaying number of rejections, as of
+	// updated.
+	rejections float64
+	updated    time.Time
+}
+
+// delay accounts for a rejection at now and returns the delay to hint, before
+// jitter, given the lower bound floor.
+func (h *Hints) delay(now time.Time, floor time.Duration) time.Duration {
+	h.mu.Lock()
+	if !h.updated.IsZero() && h.Window > 0 {
+		h.rejections *= math.Exp(-float64(now.Sub(h.updated)) / float64(h.Window))
+	}
+	h.rejections++
+	h.updated = now
+	rejections := h.rejections
+	h.mu.Unlock()
+
+	delay := time.Duration(float64(h.Base) * math.Sqrt(rejections))
+	if delay > h.Max {
+		delay = h.Max
+	}
+	if delay < floor {
+		delay = floor
+	}
+	return delay
+}
+
+// hint returns the value of the Retry-After header for delay and the random
+// fraction jitter in [0, 1): delay plus jitter times half of it, rounded up
+// to whole seconds.
+func hint(delay time.Duration, jitter float64) int {
+	seconds := int(math.Ceil((delay + time.Duration(jitter*float64(delay/2))).Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// Middleware returns an http.Handler setting the Retry-After header of the
+// 429 and 503 responses of next.
+func (h *Hints) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&responseWriter{ResponseWriter: w, hints: h}, r)
+	})
+}
+
+// responseWriter sets the Retry-After header of rejections.
+type responseWriter struct {
+	http.ResponseWriter
+	hints       *Hints
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader && (code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable) {
+		var floor time.Duration
+		if seconds, err := strconv.Atoi(w.Header().Get(AfterHeader)); err == nil && seconds > 0 {
+			floor = time.Duration(seconds) * time.Second
+		}
+		seconds := hint(w.hints.delay(time.Now(), floor), rand.Float64())
+		hintSeconds.Observe(float64(seconds))
+		w.Header().Set(AfterHeader, strconv.Itoa(seconds))
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
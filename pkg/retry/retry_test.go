@@ -0,0 +1,77 @@
+package retry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelay(t *testing.T) {
+	var (
+		h   = &Hints{Base: time.Second, Max: 10 * time.Second, Window: time.Minute}
+		now = time.Now()
+	)
+
+	require.Equal(t, time.Second, h.delay(now, 0))
+	for i := 0; i < 2; i++ {
+		h.delay(now, 0)
+	}
+	// 4 rejections.
+	require.Equal(t, 2*time.Second, h.delay(now, 0))
+	for i := 0; i < 1000; i++ {
+		h.delay(now, 0)
+	}
+	require.Equal(t, 10*time.Second, h.delay(now, 0))
+
+	// Rejections are forgotten over time.
+	require.Equal(t, time.Second, h.delay(now.Add(time.Hour), 0))
+
+	// The Retry-After of the handler is a lower bound.
+	require.Equal(t, 30*time.Second, h.delay(now.Add(time.Hour), 30*time.Second))
+}
+
+func TestHint(t *testing.T) {
+	require.Equal(t, 1, hint(0, 0))
+	require.Equal(t, 2, hint(2*time.Second, 0))
+	require.Equal(t, 3, hint(2*time.Second, 0.99))
+	require.Equal(t, 15, hint(10*time.Second, 0.99))
+}
+
+func TestMiddleware(t *testing.T) {
+	var (
+		h      = &Hints{Base: 4 * time.Second, Max: time.Minute, Window: time.Minute}
+		status int
+		after  string
+	)
+	handler := h.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if after != "" {
+			w.Header().Set(AfterHeader, after)
+		}
+		w.WriteHeader(status)
+	}))
+	serve := func() string {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w.Header().Get(AfterHeader)
+	}
+
+	status = http.StatusOK
+	require.Empty(t, serve())
+
+	status = http.StatusTooManyRequests
+	seconds, err := strconv.Atoi(serve())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, seconds, 4)
+	require.LessOrEqual(t, seconds, 6)
+
+	// The hint of the handler is spread rather than repeated.
+	status, after = http.StatusServiceUnavailable, "20"
+	seconds, err = strconv.Atoi(serve())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, seconds, 20)
+	require.LessOrEqual(t, seconds, 30)
+}
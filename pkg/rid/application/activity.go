@@ -0,0 +1,97 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+)
+
+// maxActivityBuckets is the largest number of buckets returned by
+// GetActivity.
+const maxActivityBuckets = 1000
+
+// ActivityBucket counts the activity of the pool in [Start, End).
+type ActivityBucket struct {
+	Start time.Time
+	End   time.Time
+	// ISAsCreated and ISAsDeleted are the numbers of ISAs created and deleted
+	// in the bucket, deletions including the removal of expired ISAs.
+	ISAsCreated int
+	ISAsDeleted int
+	// ActiveSubscriptions is the number of subscriptions active at End.
+	ActiveSubscriptions int
+}
+
+// ActivityApp provides the application logic to report the activity of the
+// pool over time, e.g. for operators to plot it.
+type ActivityApp interface {
+	// GetActivity returns the activity of the pool over the last "period", in
+	// consecutive buckets of duration "bucket", the last one ending now.
+	GetActivity(ctx context.Context, period time.Duration, bucket time.Duration) ([]*ActivityBucket, error)
+}
+
+func (a *app) GetActivity(ctx context.Context, period time.Duration, bucket time.Duration) ([]*ActivityBucket, error) {
+	if period <= 0 || bucket <= 0 {
+		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Period and bucket durations must be positive")
+	}
+	n := int((period + bucket - 1) / bucket)
+	if n > maxActivityBuckets {
+		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Too many buckets: %d, maximum is %d", n, maxActivityBuckets)
+	}
+
+	to := a.clock.Now()
+	from := to.Add(-time.Duration(n) * bucket)
+	repo, err := a.Store.Interact(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to interact with store")
+	}
+	events, err := repo.ListActivity(ctx, from, to)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to list activity")
+	}
+	return bucketActivity(events, from, bucket, n), nil
+}
+
+// bucketActivity counts events, ordered by time, in n buckets of duration
+// "bucket" starting at "from".
+func bucketActivity(events []*ridmodels.ActivityEvent, from time.Time, bucket time.Duration, n int) []*ActivityBucket {
+	var (
+		buckets = make([]*ActivityBucket, n)
+		// subs holds the latest event of each subscription seen so far.
+		subs = map[dssmodels.ID]*ridmodels.ActivityEvent{}
+		next = 0
+	)
+	for i := range buckets {
+		b := &ActivityBucket{Start: from.Add(time.Duration(i) * bucket), End: from.Add(time.Duration(i+1) * bucket)}
+		for ; next < len(events) && events[next].OccurredAt.Before(b.End); next++ {
+			e := events[next]
+			switch e.Entity {
+			case ridmodels.ActivitySubscription:
+				subs[e.EntityID] = e
+			case ridmodels.ActivityISA:
+				if e.OccurredAt.Before(b.Start) {
+					continue
+				}
+				switch e.Kind {
+				case ridmodels.ActivityCreated:
+					b.ISAsCreated++
+				case ridmodels.ActivityDeleted:
+					b.ISAsDeleted++
+				}
+			}
+		}
+		for _, e := range subs {
+			if e.Kind != ridmodels.ActivityDeleted &&
+				(e.StartTime == nil || !e.StartTime.After(b.End)) &&
+				(e.EndTime == nil || e.EndTime.After(b.End)) {
+				b.ActiveSubscriptions++
+			}
+		}
+		buckets[i] = b
+	}
+	return buckets
+}
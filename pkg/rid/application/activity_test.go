@@ -0,0 +1,75 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/interuss/dss/pkg/datastore/testdb"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var _ ActivityApp = &app{}
+
+type activityStore struct {
+	events []*ridmodels.ActivityEvent
+}
+
+// Implements repos.Activity.ListActivity
+func (store *activityStore) ListActivity(ctx context.Context, from time.Time, to time.Time) ([]*ridmodels.ActivityEvent, error) {
+	var events []*ridmodels.ActivityEvent
+	for _, e := range store.events {
+		if !e.OccurredAt.After(to) {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func TestGetActivity(t *testing.T) {
+	if testdb.Enabled() {
+		t.Skip("activity events are recorded by the store")
+	}
+	var (
+		ctx      = context.Background()
+		now      = fakeClock.Now()
+		hoursAgo = func(h float64) *time.Time { t := now.Add(-time.Duration(h * float64(time.Hour))); return &t }
+		store, _ = setUpStore(ctx, t, zap.NewNop())
+		app      = NewFromTransactor(store, zap.NewNop()).(*app)
+		sub1     = dssmodels.ID("00000000-0000-4000-8000-000000000001")
+		sub2     = dssmodels.ID("00000000-0000-4000-8000-000000000002")
+		isa      = dssmodels.ID("00000000-0000-4000-8000-000000000003")
+		event    = func(entity ridmodels.ActivityEntity, id dssmodels.ID, kind ridmodels.ActivityKind, at, start, end *time.Time) *ridmodels.ActivityEvent {
+			return &ridmodels.ActivityEvent{Entity: entity, EntityID: id, Kind: kind, OccurredAt: *at, StartTime: start, EndTime: end}
+		}
+	)
+	store.(*mockRepo).events = []*ridmodels.ActivityEvent{
+		// Created before the period, active until it is deleted.
+		event(ridmodels.ActivitySubscription, sub1, ridmodels.ActivityCreated, hoursAgo(5), hoursAgo(5), hoursAgo(-10)),
+		event(ridmodels.ActivityISA, isa, ridmodels.ActivityCreated, hoursAgo(2.5), hoursAgo(2.5), hoursAgo(-1)),
+		// Starting an hour after it was created, then shortened.
+		event(ridmodels.ActivitySubscription, sub2, ridmodels.ActivityCreated, hoursAgo(2.5), hoursAgo(1.5), hoursAgo(-1)),
+		event(ridmodels.ActivitySubscription, sub1, ridmodels.ActivityDeleted, hoursAgo(0.5), hoursAgo(5), hoursAgo(-10)),
+		event(ridmodels.ActivitySubscription, sub2, ridmodels.ActivityUpdated, hoursAgo(0.5), hoursAgo(1.5), hoursAgo(0.25)),
+		event(ridmodels.ActivityISA, isa, ridmodels.ActivityDeleted, hoursAgo(0.25), hoursAgo(2.5), hoursAgo(-1)),
+	}
+
+	buckets, err := app.GetActivity(ctx, 3*time.Hour, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, buckets, 3)
+	for i, want := range []ActivityBucket{
+		{Start: *hoursAgo(3), End: *hoursAgo(2), ISAsCreated: 1, ActiveSubscriptions: 1},
+		{Start: *hoursAgo(2), End: *hoursAgo(1), ActiveSubscriptions: 2},
+		{Start: *hoursAgo(1), End: now, ISAsDeleted: 1, ActiveSubscriptions: 0},
+	} {
+		require.Equal(t, want, *buckets[i], "bucket %d", i)
+	}
+
+	_, err = app.GetActivity(ctx, 30*24*time.Hour, time.Minute)
+	require.Error(t, err)
+	_, err = app.GetActivity(ctx, time.Hour, 0)
+	require.Error(t, err)
+}
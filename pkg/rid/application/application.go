@@ -1,6 +1,8 @@
 package application
 
 import (
+	"time"
+
 	"github.com/interuss/dss/pkg/rid/store"
 	"github.com/jonboulle/clockwork"
 	"go.uber.org/zap"
@@ -17,19 +19,55 @@ type app struct {
 	store.Store
 	clock  clockwork.Clock
 	logger *zap.Logger
+
+	// policy vets the entities written, if not nil.
+	policy WritePolicy
+
+	// hedgeDelay is the time after which reads are hedged, never if 0.
+	hedgeDelay time.Duration
+
+	// events receives the changes of entities, if not nil.
+	events EventSink
 }
 
 type App interface {
 	ISAApp
 	SubscriptionApp
+	ReconciliationApp
+	LabelApp
+	CallbackApp
+	NotificationIntervalApp
+	SelfNotificationApp
+	ExpirationApp
+	LookupApp
+	ActivityApp
+	TransferApp
+	OwnerAliasApp
+	CoverageApp
+	UsageApp
+}
+
+// Option configures an App created by NewFromTransactor.
+type Option func(*app)
+
+// WithWritePolicy makes the App reject the ISAs and subscriptions violating
+// policy.
+func WithWritePolicy(policy WritePolicy) Option {
+	return func(a *app) {
+		a.policy = policy
+	}
 }
 
 // NewFromTransactor is a convenience function for creating an App
 // with the given store.
-func NewFromTransactor(store store.Store, logger *zap.Logger) App {
-	return &app{
+func NewFromTransactor(store store.Store, logger *zap.Logger, opts ...Option) App {
+	a := &app{
 		Store:  store,
 		clock:  DefaultClock,
 		logger: logger,
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
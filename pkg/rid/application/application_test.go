@@ -6,7 +6,7 @@ import (
 	"time"
 
 	"github.com/interuss/dss/pkg/datastore"
-	"github.com/interuss/dss/pkg/datastore/flags"
+	"github.com/interuss/dss/pkg/datastore/testdb"
 	dssmodels "github.com/interuss/dss/pkg/models"
 	ridmodels "github.com/interuss/dss/pkg/rid/models"
 	"github.com/interuss/dss/pkg/rid/repos"
@@ -28,6 +28,8 @@ var (
 type mockRepo struct {
 	*isaStore
 	*subscriptionStore
+	*activityStore
+	*ownerAliasStore
 	dssql.Queryable
 }
 
@@ -43,11 +45,14 @@ func (s *mockRepo) Close() error {
 	return nil
 }
 
+func TestMain(m *testing.M) {
+	testdb.Main(m)
+}
+
 func setUpStore(ctx context.Context, t *testing.T, logger *zap.Logger) (store.Store, func()) {
 	DefaultClock = fakeClock
-	connectParameters := flags.ConnectParameters()
 
-	if connectParameters.Host == "" || connectParameters.Port == 0 {
+	if !testdb.Enabled() {
 		logger.Info("using the stubbed in memory store.")
 		return &mockRepo{
 			isaStore: &isaStore{
@@ -56,11 +61,11 @@ func setUpStore(ctx context.Context, t *testing.T, logger *zap.Logger) (store.St
 			subscriptionStore: &subscriptionStore{
 				subs: make(map[dssmodels.ID]*ridmodels.Subscription),
 			},
+			activityStore:   &activityStore{},
+			ownerAliasStore: &ownerAliasStore{aliases: make(map[dssmodels.Owner]*ridmodels.OwnerAlias)},
 		}, func() {}
 	}
-	if !(connectParameters.DBName == "rid" || connectParameters.DBName == "scd") {
-		connectParameters.DBName = "rid"
-	}
+	connectParameters := testdb.ConnectParameters(t, "rid")
 	ridc.DefaultClock = fakeClock
 	ridCrdb, err := datastore.Dial(ctx, connectParameters)
 	require.NoError(t, err)
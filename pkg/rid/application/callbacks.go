@@ -0,0 +1,46 @@
+package application
+
+import (
+	"context"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/dss/pkg/rid/repos"
+	"github.com/interuss/stacktrace"
+)
+
+// CallbackApp provides the application logic for the callback URLs at which
+// Subscriptions are notified besides their URL.
+type CallbackApp interface {
+	// SetSubscriptionCallbacks replaces the callback URLs, besides its URL, of
+	// the Subscription identified by "id" and owned by "owner".
+	SetSubscriptionCallbacks(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, urls []string) (*ridmodels.Subscription, error)
+}
+
+func (a *app) SetSubscriptionCallbacks(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, urls []string) (*ridmodels.Subscription, error) {
+	if err := ridmodels.ValidateCallbackURLs(urls); err != nil {
+		return nil, stacktrace.Propagate(err, "Invalid callback URLs")
+	}
+	var ret *ridmodels.Subscription
+	// The following will automatically retry TXN retry errors.
+	err := a.Store.Transact(ctx, func(repo repos.Repository) error {
+		old, err := repo.GetSubscription(ctx, id, true)
+		switch {
+		case err != nil:
+			return stacktrace.Propagate(err, "Error getting Subscription from repo")
+		case old == nil:
+			return stacktrace.NewErrorWithCode(dsserr.NotFound, "Subscription %s not found", id.String())
+		case old.Owner != owner:
+			return stacktrace.NewErrorWithCode(dsserr.PermissionDenied,
+				"Subscription owned by %s, but %s attempted to set its callbacks", old.Owner, owner)
+		}
+
+		ret, err = repo.UpdateSubscriptionCallbacks(ctx, id, urls)
+		if err != nil {
+			return stacktrace.Propagate(err, "Error updating Subscription callbacks")
+		}
+		return nil
+	})
+	return ret, err // No need to Propagate this error as this stack layer does not add useful information
+}
@@ -0,0 +1,105 @@
+package application
+
+import (
+	"context"
+	"sort"
+
+	"github.com/golang/geo/s2"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+)
+
+// CellDensity counts the active entities in a cell.
+type CellDensity struct {
+	Cell          s2.CellID
+	ISAs          int
+	Subscriptions int
+}
+
+// Coverage is the remote ID airspace coverage of an area.
+type Coverage struct {
+	// ISAs are the active ISAs in the area.
+	ISAs []*ridmodels.IdentificationServiceArea
+	// Cells count the active ISAs and subscriptions in the cells of the area
+	// containing any, ordered by cell ID.
+	Cells []*CellDensity
+}
+
+// CoverageApp provides the application logic to visualize the airspace
+// coverage of the pool, e.g. on a map.
+type CoverageApp interface {
+	// GetCoverage returns the active ISAs in "cells" and the number of active
+	// ISAs and subscriptions in each of the cells of level "level" containing
+	// "cells", counting each entity once per cell.
+	GetCoverage(ctx context.Context, cells s2.CellUnion, level int) (*Coverage, error)
+}
+
+func (a *app) GetCoverage(ctx context.Context, cells s2.CellUnion, level int) (*Coverage, error) {
+	if level < 0 || level > s2.MaxLevel {
+		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Invalid cell level %d", level)
+	}
+	now := a.clock.Now()
+	repo, err := a.Store.Interact(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to interact with store")
+	}
+
+	observeCells(ctx, "isa", "coverage", cells)
+	isas, err := repo.SearchISAs(ctx, cells, &now, nil, "")
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to search ISAs")
+	}
+	subs, err := repo.SearchSubscriptions(ctx, cells)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to search subscriptions")
+	}
+
+	densities := map[s2.CellID]*CellDensity{}
+	density := func(cell s2.CellID) *CellDensity {
+		d, ok := densities[cell]
+		if !ok {
+			d = &CellDensity{Cell: cell}
+			densities[cell] = d
+		}
+		return d
+	}
+	for _, isa := range isas {
+		for _, cell := range coverageCells(isa.Cells, cells, level) {
+			density(cell).ISAs++
+		}
+	}
+	for _, sub := range subs {
+		for _, cell := range coverageCells(sub.Cells, cells, level) {
+			density(cell).Subscriptions++
+		}
+	}
+
+	coverage := &Coverage{ISAs: isas, Cells: make([]*CellDensity, 0, len(densities))}
+	for _, d := range densities {
+		coverage.Cells = append(coverage.Cells, d)
+	}
+	sort.Slice(coverage.Cells, func(i, j int) bool { return coverage.Cells[i].Cell < coverage.Cells[j].Cell })
+	return coverage, nil
+}
+
+// coverageCells returns the distinct cells of level "level" containing the
+// cells of an entity which are in "area". Cells coarser than "level" are
+// returned as they are.
+func coverageCells(entity s2.CellUnion, area s2.CellUnion, level int) []s2.CellID {
+	var result []s2.CellID
+	seen := map[s2.CellID]bool{}
+	for _, cell := range entity {
+		if !area.IntersectsCellID(cell) {
+			continue
+		}
+		if cell.Level() > level {
+			cell = cell.Parent(level)
+		}
+		if !seen[cell] {
+			seen[cell] = true
+			result = append(result, cell)
+		}
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/uuid"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+)
+
+var _ CoverageApp = &app{}
+
+func TestGetCoverage(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		app, cleanup = setUpISAApp(ctx, t)
+		parent       = s2.CellIDFromLatLng(s2.LatLngFromDegrees(46.9, 7.4)).Parent(12)
+		// Two cells of parent and one of another cell of level 12.
+		cell1 = parent.ChildBeginAtLevel(13)
+		cell2 = cell1.Next()
+		cell3 = parent.Next().ChildBeginAtLevel(13)
+		area  = s2.CellUnion{cell1, cell2, cell3}
+	)
+	defer cleanup()
+
+	isa, _, err := app.InsertISA(ctx, &ridmodels.IdentificationServiceArea{
+		ID:        dssmodels.ID(uuid.New().String()),
+		Owner:     "owner",
+		StartTime: &startTime,
+		EndTime:   &endTime,
+		Cells:     s2.CellUnion{cell1, cell2},
+	})
+	require.NoError(t, err)
+	_, err = app.InsertSubscription(ctx, &ridmodels.Subscription{
+		ID:        dssmodels.ID(uuid.New().String()),
+		Owner:     "owner",
+		StartTime: &startTime,
+		EndTime:   &endTime,
+		Cells:     s2.CellUnion{cell2, cell3},
+	})
+	require.NoError(t, err)
+
+	coverage, err := app.GetCoverage(ctx, area, 12)
+	require.NoError(t, err)
+	require.Len(t, coverage.ISAs, 1)
+	require.Equal(t, isa.ID, coverage.ISAs[0].ID)
+	// The ISA is counted once in parent although it has two cells in it.
+	require.Equal(t, []*CellDensity{
+		{Cell: parent, ISAs: 1, Subscriptions: 1},
+		{Cell: parent.Next(), Subscriptions: 1},
+	}, coverage.Cells)
+
+	// Cells of the entities outside the area are not counted.
+	coverage, err = app.GetCoverage(ctx, s2.CellUnion{cell3}, 13)
+	require.NoError(t, err)
+	require.Empty(t, coverage.ISAs)
+	require.Equal(t, []*CellDensity{{Cell: cell3, Subscriptions: 1}}, coverage.Cells)
+
+	_, err = app.GetCoverage(ctx, area, 31)
+	require.Error(t, err)
+}
@@ -0,0 +1,72 @@
+package application
+
+import (
+	"context"
+
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+)
+
+// EntityEvent is a committed change of a remote ID entity.
+type EntityEvent struct {
+	ridmodels.ActivityEvent
+	// Owner is the owner of the entity after the change.
+	Owner dssmodels.Owner
+	// Version is the version of the entity after the change or, for
+	// deletions, when it was deleted.
+	Version *dssmodels.Version
+}
+
+// EventSink receives the changes of remote ID entities made through the App
+// once committed, e.g. to forward them to the systems of operators. Changes
+// made by the garbage collector or directly in the database are not
+// published. Publish must not block.
+type EventSink interface {
+	Publish(ctx context.Context, event *EntityEvent)
+}
+
+// WithEventSink makes the App publish the changes of entities to sink.
+func WithEventSink(sink EventSink) Option {
+	return func(a *app) {
+		a.events = sink
+	}
+}
+
+// publishISA publishes the change "kind" of isa, if the App has an EventSink.
+func (a *app) publishISA(ctx context.Context, kind ridmodels.ActivityKind, isa *ridmodels.IdentificationServiceArea) {
+	if a.events == nil || isa == nil {
+		return
+	}
+	a.events.Publish(ctx, &EntityEvent{
+		ActivityEvent: ridmodels.ActivityEvent{
+			Entity:     ridmodels.ActivityISA,
+			EntityID:   isa.ID,
+			Kind:       kind,
+			OccurredAt: a.clock.Now(),
+			StartTime:  isa.StartTime,
+			EndTime:    isa.EndTime,
+		},
+		Owner:   isa.Owner,
+		Version: isa.Version,
+	})
+}
+
+// publishSubscription publishes the change "kind" of sub, if the App has an
+// EventSink.
+func (a *app) publishSubscription(ctx context.Context, kind ridmodels.ActivityKind, sub *ridmodels.Subscription) {
+	if a.events == nil || sub == nil {
+		return
+	}
+	a.events.Publish(ctx, &EntityEvent{
+		ActivityEvent: ridmodels.ActivityEvent{
+			Entity:     ridmodels.ActivitySubscription,
+			EntityID:   sub.ID,
+			Kind:       kind,
+			OccurredAt: a.clock.Now(),
+			StartTime:  sub.StartTime,
+			EndTime:    sub.EndTime,
+		},
+		Owner:   sub.Owner,
+		Version: sub.Version,
+	})
+}
@@ -0,0 +1,78 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/uuid"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type recordingSink struct {
+	events []*EntityEvent
+}
+
+func (s *recordingSink) Publish(ctx context.Context, event *EntityEvent) {
+	s.events = append(s.events, event)
+}
+
+func TestAppPublishesEvents(t *testing.T) {
+	var (
+		ctx            = context.Background()
+		sink           = &recordingSink{}
+		store, cleanup = setUpStore(ctx, t, zap.NewNop())
+		app            = NewFromTransactor(store, zap.NewNop(), WithEventSink(sink)).(*app)
+		owner          = dssmodels.Owner(uuid.New().String())
+		cells          = s2.CellUnion{s2.CellID(12494535935418957824)}
+	)
+	defer cleanup()
+
+	isa, _, err := app.InsertISA(ctx, &ridmodels.IdentificationServiceArea{
+		ID: dssmodels.ID(uuid.New().String()), Owner: owner, URL: "https://example.com/isa",
+		StartTime: &startTime, EndTime: &endTime, Cells: cells,
+	})
+	require.NoError(t, err)
+	update := *isa
+	update.URL = "https://example.com/isa2"
+	isa, _, err = app.UpdateISA(ctx, &update)
+	require.NoError(t, err)
+	_, _, err = app.DeleteISA(ctx, isa.ID, isa.Owner, isa.Version)
+	require.NoError(t, err)
+
+	sub, err := app.InsertSubscription(ctx, &ridmodels.Subscription{
+		ID: dssmodels.ID(uuid.New().String()), Owner: owner, URL: "https://example.com/sub",
+		StartTime: &startTime, EndTime: &endTime, Cells: cells,
+	})
+	require.NoError(t, err)
+	_, err = app.DeleteSubscription(ctx, sub.ID, sub.Owner, sub.Version)
+	require.NoError(t, err)
+
+	// Failed changes are not published.
+	_, _, err = app.DeleteISA(ctx, isa.ID, isa.Owner, isa.Version)
+	require.Error(t, err)
+
+	require.Len(t, sink.events, 5)
+	for i, want := range []struct {
+		entity ridmodels.ActivityEntity
+		id     dssmodels.ID
+		kind   ridmodels.ActivityKind
+	}{
+		{ridmodels.ActivityISA, isa.ID, ridmodels.ActivityCreated},
+		{ridmodels.ActivityISA, isa.ID, ridmodels.ActivityUpdated},
+		{ridmodels.ActivityISA, isa.ID, ridmodels.ActivityDeleted},
+		{ridmodels.ActivitySubscription, sub.ID, ridmodels.ActivityCreated},
+		{ridmodels.ActivitySubscription, sub.ID, ridmodels.ActivityDeleted},
+	} {
+		e := sink.events[i]
+		require.Equal(t, want.entity, e.Entity, "event %d", i)
+		require.Equal(t, want.id, e.EntityID, "event %d", i)
+		require.Equal(t, want.kind, e.Kind, "event %d", i)
+		require.Equal(t, owner, e.Owner, "event %d", i)
+		require.NotNil(t, e.Version, "event %d", i)
+	}
+	require.Equal(t, isa.Version, sink.events[1].Version)
+}
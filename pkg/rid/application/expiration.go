@@ -0,0 +1,59 @@
+package application
+
+import (
+	"context"
+
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/dss/pkg/rid/repos"
+	"github.com/interuss/stacktrace"
+)
+
+// ExpirationApp provides the application logic to cut off a USS, e.g. one
+// publishing bogus airspace data.
+type ExpirationApp interface {
+	// ExpireISAsByOwner ends all the active ISAs of "owner" now, in a single
+	// transaction, increments the notification indices of their subscribers
+	// and returns the expired ISAs.
+	ExpireISAsByOwner(ctx context.Context, owner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error)
+}
+
+func (a *app) ExpireISAsByOwner(ctx context.Context, owner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error) {
+	var expired []*ridmodels.IdentificationServiceArea
+	// The following will automatically retry TXN retry errors.
+	err := a.Store.Transact(ctx, func(repo repos.Repository) error {
+		expired = nil
+		now := a.clock.Now()
+
+		active, err := repo.ListISAsByOwner(ctx, owner)
+		if err != nil {
+			return stacktrace.Propagate(err, "Error listing ISAs of %s", owner)
+		}
+		for _, old := range active {
+			isa := *old
+			isa.EndTime = &now
+			if isa.StartTime != nil && isa.StartTime.After(now) {
+				isa.StartTime = &now
+			}
+			updated, err := repo.UpdateISA(ctx, &isa)
+			if err != nil {
+				return stacktrace.Propagate(err, "Error expiring ISA %s", isa.ID)
+			}
+			if updated == nil {
+				return stacktrace.NewError("ISA %s was modified concurrently", isa.ID)
+			}
+			// Subscribers to the former extent of the ISA need to be notified.
+			if _, err := repo.UpdateNotificationIdxsInCells(ctx, old.Cells, owner, old.StartTime, old.EndTime); err != nil {
+				return stacktrace.Propagate(err, "Error updating notification indices")
+			}
+			expired = append(expired, updated)
+		}
+		return nil
+	})
+	if err == nil {
+		for _, isa := range expired {
+			a.publishISA(ctx, ridmodels.ActivityUpdated, isa)
+		}
+	}
+	return expired, err // No need to Propagate this error as this stack layer does not add useful information
+}
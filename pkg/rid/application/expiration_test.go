@@ -0,0 +1,64 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/uuid"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+)
+
+var _ ExpirationApp = &app{}
+
+func TestExpireISAsByOwner(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		app, cleanup = setUpISAApp(ctx, t)
+		owner        = dssmodels.Owner(uuid.New().String())
+		cells        = s2.CellUnion{12494535935418957824}
+	)
+	defer cleanup()
+
+	insert := func(owner dssmodels.Owner) *ridmodels.IdentificationServiceArea {
+		isa, _, err := app.InsertISA(ctx, &ridmodels.IdentificationServiceArea{
+			ID:        dssmodels.ID(uuid.New().String()),
+			Owner:     owner,
+			URL:       "https://no/place/like/home/for/flights",
+			StartTime: &startTime,
+			EndTime:   &endTime,
+			Cells:     cells,
+		})
+		require.NoError(t, err)
+		return isa
+	}
+	bogus := []*ridmodels.IdentificationServiceArea{insert(owner), insert(owner)}
+	legitimate := insert("other owner")
+
+	sub, err := app.InsertSubscription(ctx, &ridmodels.Subscription{
+		ID:        dssmodels.ID(uuid.New().String()),
+		Owner:     "subscriber",
+		StartTime: &startTime,
+		EndTime:   &endTime,
+		Cells:     cells,
+	})
+	require.NoError(t, err)
+
+	expired, err := app.ExpireISAsByOwner(ctx, owner)
+	require.NoError(t, err)
+	require.Len(t, expired, 2)
+	require.ElementsMatch(t, []dssmodels.ID{bogus[0].ID, bogus[1].ID}, []dssmodels.ID{expired[0].ID, expired[1].ID})
+	for _, isa := range expired {
+		require.True(t, isa.EndTime.Equal(fakeClock.Now()))
+	}
+
+	isa, err := app.GetISA(ctx, legitimate.ID)
+	require.NoError(t, err)
+	require.True(t, isa.EndTime.Equal(endTime))
+
+	notified, err := app.GetSubscription(ctx, sub.ID)
+	require.NoError(t, err)
+	require.Equal(t, sub.NotificationIndex+2, notified.NotificationIndex)
+}
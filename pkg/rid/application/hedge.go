@@ -0,0 +1,95 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/interuss/dss/pkg/rid/repos"
+	"github.com/interuss/dss/pkg/rid/store"
+	"github.com/interuss/stacktrace"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var hedgedReads = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "dss_rid_hedged_reads_total",
+	Help: "Number of remote ID reads hedged with a second attempt, by operation and attempt returning first: first, hedge or none if both failed.",
+}, []string{"operation", "winner"})
+
+// WithHedgedReads makes the App issue a second attempt of the gets and
+// searches of ISAs and subscriptions not completed after delay, and return the
+// first successful result. The second attempt uses the primary datastore if
+// the store routes reads elsewhere.
+func WithHedgedReads(delay time.Duration) Option {
+	return func(a *app) {
+		a.hedgeDelay = delay
+	}
+}
+
+// hedgeInteract returns the function obtaining the repository of the second
+// attempts of hedged reads.
+func (a *app) hedgeInteract() func(context.Context) (repos.Repository, error) {
+	if p, ok := a.Store.(store.PrimaryInteractor); ok {
+		return p.InteractPrimary
+	}
+	return a.Store.Interact
+}
+
+// hedgedRead returns the result of read with a repository of a, hedged as
+// configured by WithHedgedReads. read must be safe to call concurrently.
+func hedgedRead[T any](ctx context.Context, a *app, operation string, read func(context.Context, repos.Repository) (T, error)) (T, error) {
+	attempt := func(ctx context.Context, interact func(context.Context) (repos.Repository, error)) (T, error) {
+		repo, err := interact(ctx)
+		if err != nil {
+			var zero T
+			return zero, stacktrace.Propagate(err, "Unable to interact with store")
+		}
+		return read(ctx, repo)
+	}
+	if a.hedgeDelay <= 0 {
+		return attempt(ctx, a.Store.Interact)
+	}
+
+	type result struct {
+		value T
+		err   error
+		hedge bool
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan result, 2)
+	run := func(hedge bool, interact func(context.Context) (repos.Repository, error)) {
+		go func() {
+			value, err := attempt(ctx, interact)
+			results <- result{value: value, err: err, hedge: hedge}
+		}()
+	}
+
+	run(false, a.Store.Interact)
+	timer := a.clock.NewTimer(a.hedgeDelay)
+	defer timer.Stop()
+	select {
+	case r := <-results:
+		return r.value, r.err
+	case <-timer.Chan():
+	}
+
+	run(true, a.hedgeInteract())
+	first := <-results
+	if first.err != nil {
+		// Return the error of the first attempt failing only if the other
+		// fails too.
+		if second := <-results; second.err == nil {
+			first = second
+		}
+	}
+	winner := "first"
+	switch {
+	case first.err != nil:
+		winner = "none"
+	case first.hedge:
+		winner = "hedge"
+	}
+	hedgedReads.WithLabelValues(operation, winner).Inc()
+	return first.value, first.err
+}
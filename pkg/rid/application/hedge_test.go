@@ -0,0 +1,113 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/dss/pkg/rid/repos"
+	"github.com/interuss/dss/pkg/rid/store"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// hedgeRepo returns isa or err, or blocks until its context is done if block
+// is set.
+type hedgeRepo struct {
+	repos.Repository
+	isa   *ridmodels.IdentificationServiceArea
+	err   error
+	block bool
+}
+
+func (r *hedgeRepo) GetISA(ctx context.Context, id dssmodels.ID, forUpdate bool) (*ridmodels.IdentificationServiceArea, error) {
+	if r.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return r.isa, r.err
+}
+
+// hedgeStore reads from read, and from primary when reading from the primary
+// datastore.
+type hedgeStore struct {
+	store.Store
+	read, primary *hedgeRepo
+}
+
+func (s *hedgeStore) Interact(ctx context.Context) (repos.Repository, error) {
+	return s.read, nil
+}
+
+func (s *hedgeStore) InteractPrimary(ctx context.Context) (repos.Repository, error) {
+	return s.primary, nil
+}
+
+func TestHedgedReads(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		clock     = clockwork.NewFakeClock()
+		readISA   = &ridmodels.IdentificationServiceArea{ID: dssmodels.ID("4348c8e5-0b1c-43cf-9114-2e67a4532765")}
+		hedgedISA = &ridmodels.IdentificationServiceArea{ID: readISA.ID}
+		s         = &hedgeStore{read: &hedgeRepo{isa: readISA}, primary: &hedgeRepo{isa: hedgedISA}}
+		a         = &app{Store: s, clock: clock, logger: zap.NewNop(), hedgeDelay: time.Second}
+	)
+
+	// Reads completing in time are not hedged.
+	isa, err := a.GetISA(ctx, readISA.ID)
+	require.NoError(t, err)
+	require.Same(t, readISA, isa)
+	s.read.isa, s.read.err = nil, errors.New("read failed")
+	_, err = a.GetISA(ctx, readISA.ID)
+	require.Error(t, err)
+
+	// Slow reads are hedged to the primary datastore.
+	s.read.block = true
+	// Fake clocks count stopped timers as blocked, hence a new clock.
+	clock = clockwork.NewFakeClock()
+	a.clock = clock
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		isa, err = a.GetISA(ctx, readISA.ID)
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	<-done
+	require.NoError(t, err)
+	require.Same(t, hedgedISA, isa)
+
+	// Reads fail if both attempts fail.
+	s.read.block, s.read.err = false, errors.New("read failed")
+	s.primary.isa, s.primary.err = nil, errors.New("hedge failed")
+	release := make(chan struct{})
+	clock = clockwork.NewFakeClock()
+	a.clock = clock
+	done = make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err = hedgedRead(ctx, a, "test", func(ctx context.Context, repo repos.Repository) (*ridmodels.IdentificationServiceArea, error) {
+			if repo == s.read {
+				<-release
+			}
+			return repo.GetISA(ctx, readISA.ID, false)
+		})
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	close(release)
+	<-done
+	require.Error(t, err)
+
+	// Without delay, reads are not hedged.
+	a.hedgeDelay = 0
+	s.read.err = nil
+	s.read.isa = readISA
+	isa, err = a.GetISA(ctx, readISA.ID)
+	require.NoError(t, err)
+	require.Same(t, readISA, isa)
+}
@@ -19,7 +19,8 @@ import (
 type ISAApp interface {
 	GetISA(ctx context.Context, id dssmodels.ID) (*ridmodels.IdentificationServiceArea, error)
 
-	// DeleteISA deletes the IdentificationServiceArea identified by "id" and owned by "owner".
+	// DeleteISA deletes the IdentificationServiceArea identified by "id" and owned by "owner",
+	// provided that it is at "version", which is required.
 	// Returns the delete IdentificationServiceArea and all Subscriptions affected by the delete.
 	DeleteISA(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, version *dssmodels.Version) (*ridmodels.IdentificationServiceArea, []*ridmodels.Subscription, error)
 
@@ -29,20 +30,36 @@ type ISAApp interface {
 	// UpdateISA
 	UpdateISA(ctx context.Context, isa *ridmodels.IdentificationServiceArea) (*ridmodels.IdentificationServiceArea, []*ridmodels.Subscription, error)
 
-	// SearchISAs returns all subscriptions ownded by "owner" in "cells".
-	SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time) ([]*ridmodels.IdentificationServiceArea, error)
+	// SearchISAs returns all ISAs in "cells", excluding those owned by
+	// "excludeOwner" if set.
+	SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time, excludeOwner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error)
+
+	// CountISAs returns the number, up to dssmodels.MaxResultLimit, of the
+	// ISAs SearchISAs returns without an excluded owner.
+	CountISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time) (int, error)
 }
 
 func (a *app) GetISA(ctx context.Context, id dssmodels.ID) (*ridmodels.IdentificationServiceArea, error) {
-	repo, err := a.Store.Interact(ctx)
-	if err != nil {
-		return nil, stacktrace.Propagate(err, "Unable to interact with store")
-	}
-	return repo.GetISA(ctx, id, false)
+	return hedgedRead(ctx, a, "get_isa", func(ctx context.Context, repo repos.Repository) (*ridmodels.IdentificationServiceArea, error) {
+		return repo.GetISA(ctx, id, false)
+	})
 }
 
 // SearchISAs for ISA within the volume bounds.
-func (a *app) SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time) ([]*ridmodels.IdentificationServiceArea, error) {
+func (a *app) SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time, excludeOwner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error) {
+	now := a.clock.Now()
+	if earliest == nil || earliest.Before(now) {
+		earliest = &now
+	}
+
+	observeCells(ctx, "isa", "search", cells)
+	return hedgedRead(ctx, a, "search_isas", func(ctx context.Context, repo repos.Repository) ([]*ridmodels.IdentificationServiceArea, error) {
+		return repo.SearchISAs(ctx, cells, earliest, latest, excludeOwner)
+	})
+}
+
+// CountISAs counts the ISAs within the volume bounds.
+func (a *app) CountISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time) (int, error) {
 	now := a.clock.Now()
 	if earliest == nil || earliest.Before(now) {
 		earliest = &now
@@ -50,14 +67,19 @@ func (a *app) SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *time
 
 	repo, err := a.Store.Interact(ctx)
 	if err != nil {
-		return nil, stacktrace.Propagate(err, "Unable to interact with store")
+		return 0, stacktrace.Propagate(err, "Unable to interact with store")
 	}
 
-	return repo.SearchISAs(ctx, cells, earliest, latest)
+	observeCells(ctx, "isa", "count", cells)
+	return repo.CountISAs(ctx, cells, earliest, latest, dssmodels.MaxResultLimit)
 }
 
-// DeleteISA the given ISA
+// DeleteISA the given ISA, if still at version. An ISA changed since read is
+// not deleted, as when read at another version.
 func (a *app) DeleteISA(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, version *dssmodels.Version) (*ridmodels.IdentificationServiceArea, []*ridmodels.Subscription, error) {
+	if version.Empty() {
+		return nil, nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing version of ISA %s to delete", id)
+	}
 	var (
 		ret  *ridmodels.IdentificationServiceArea
 		subs []*ridmodels.Subscription
@@ -65,30 +87,44 @@ func (a *app) DeleteISA(ctx context.Context, id dssmodels.ID, owner dssmodels.Ow
 	// The following will automatically retry TXN retry errors.
 	err := a.Store.Transact(ctx, func(repo repos.Repository) error {
 		old, err := repo.GetISA(ctx, id, true)
+		// The owner is checked before the version so that other owners learn
+		// nothing of the ISA from the error.
 		switch {
 		case err != nil:
 			return stacktrace.Propagate(err, "Error getting ISA")
 		case old == nil:
 			return stacktrace.NewErrorWithCode(dsserr.NotFound, "ISA %s not found", id.String())
-		case !version.Matches(old.Version):
-			return stacktrace.NewErrorWithCode(dsserr.VersionMismatch,
-				"ISA currently at version %s but client specified %s", old.Version, version)
 		case old.Owner != owner:
 			return stacktrace.NewErrorWithCode(dsserr.PermissionDenied,
 				"ISA owned by %s, but %s attempted to delete", old.Owner, owner)
+		case !version.Matches(old.Version):
+			return stacktrace.NewErrorWithCode(dsserr.StaleVersion,
+				"ISA currently at version %s but client specified %s", old.Version, version)
 		}
 
 		ret, err = repo.DeleteISA(ctx, old)
 		if err != nil {
 			return stacktrace.Propagate(err, "Error deleting ISA")
 		}
+		if ret == nil {
+			return stacktrace.NewErrorWithCode(dsserr.StaleVersion,
+				"ISA %s changed since read at version %s", id, version)
+		}
 
-		subs, err = repo.UpdateNotificationIdxsInCells(ctx, old.Cells)
+		subs, err = repo.UpdateNotificationIdxsInCells(ctx, old.Cells, old.Owner, old.StartTime, old.EndTime)
 		if err != nil {
 			return stacktrace.Propagate(err, "Error updating notification indices")
 		}
+		subs, err = a.collapseNotifications(ctx, repo, "delete", subs)
+		if err != nil {
+			return err // No need to Propagate this error as this stack layer does not add useful information
+		}
 		return nil
 	})
+	if err == nil {
+		observeFanout("delete", subs)
+		a.publishISA(ctx, ridmodels.ActivityDeleted, ret)
+	}
 	return ret, subs, err // No need to Propagate this error as this stack layer does not add useful information
 }
 
@@ -98,6 +134,11 @@ func (a *app) InsertISA(ctx context.Context, isa *ridmodels.IdentificationServic
 	if err := isa.AdjustTimeRange(a.clock.Now(), nil); err != nil {
 		return nil, nil, stacktrace.Propagate(err, "Error adjusting time range")
 	}
+	if a.policy != nil {
+		if err := a.policy.CheckISA(ctx, isa); err != nil {
+			return nil, nil, stacktrace.Propagate(err, "ISA rejected by write policy")
+		}
+	}
 	// Update the notification index for both cells removed and added.
 	var (
 		ret  *ridmodels.IdentificationServiceArea
@@ -117,16 +158,25 @@ func (a *app) InsertISA(ctx context.Context, isa *ridmodels.IdentificationServic
 		// UpdateNotificationIdxsInCells is done in a Txn along with insert since
 		// they are both modifying the db. Insert a susbcription alone does
 		// not do this, so that does not need to use a txn (in subscription.go).
-		subs, err = repo.UpdateNotificationIdxsInCells(ctx, isa.Cells)
+		subs, err = repo.UpdateNotificationIdxsInCells(ctx, isa.Cells, isa.Owner, isa.StartTime, isa.EndTime)
 		if err != nil {
 			return stacktrace.Propagate(err, "Error updating notification indices")
 		}
+		subs, err = a.collapseNotifications(ctx, repo, "insert", subs)
+		if err != nil {
+			return err // No need to Propagate this error as this stack layer does not add useful information
+		}
 		ret, err = repo.InsertISA(ctx, isa)
 		if err != nil {
 			return stacktrace.Propagate(err, "Error inserting ISA")
 		}
 		return nil
 	})
+	if err == nil {
+		observeFanout("insert", subs)
+		observeCells(ctx, "isa", "insert", isa.Cells)
+		a.publishISA(ctx, ridmodels.ActivityCreated, ret)
+	}
 	return ret, subs, err // No need to Propagate this error as this stack layer does not add useful information
 }
 
@@ -158,6 +208,11 @@ func (a *app) UpdateISA(ctx context.Context, isa *ridmodels.IdentificationServic
 		if err := isa.AdjustTimeRange(a.clock.Now(), old); err != nil {
 			return stacktrace.Propagate(err, "Error adjusting time range")
 		}
+		if a.policy != nil {
+			if err := a.policy.CheckISA(ctx, isa); err != nil {
+				return stacktrace.Propagate(err, "ISA rejected by write policy")
+			}
+		}
 
 		ret, err = repo.UpdateISA(ctx, isa)
 		if err != nil {
@@ -171,12 +226,29 @@ func (a *app) UpdateISA(ctx context.Context, isa *ridmodels.IdentificationServic
 		// UpdateNotificationIdxsInCells is done in a Txn along with insert since
 		// they are both modifying the db. Insert a susbcription alone does
 		// not do this, so that does not need to use a txn (in subscription.go).
-		subs, err = repo.UpdateNotificationIdxsInCells(ctx, cells)
+		// Subscribers to either the old or the new extents need to be notified.
+		startTime, endTime := old.StartTime, old.EndTime
+		if isa.StartTime != nil && (startTime == nil || isa.StartTime.Before(*startTime)) {
+			startTime = isa.StartTime
+		}
+		if isa.EndTime != nil && (endTime == nil || isa.EndTime.After(*endTime)) {
+			endTime = isa.EndTime
+		}
+		subs, err = repo.UpdateNotificationIdxsInCells(ctx, cells, isa.Owner, startTime, endTime)
 		if err != nil {
 			return stacktrace.Propagate(err, "Error updating notification indices")
 		}
+		subs, err = a.collapseNotifications(ctx, repo, "update", subs)
+		if err != nil {
+			return err // No need to Propagate this error as this stack layer does not add useful information
+		}
 		return nil
 	})
 
+	if err == nil {
+		observeFanout("update", subs)
+		observeCells(ctx, "isa", "update", isa.Cells)
+		a.publishISA(ctx, ridmodels.ActivityUpdated, ret)
+	}
 	return ret, subs, err // No need to Propagate this error as this stack layer does not add useful information
 }
@@ -2,12 +2,14 @@ package application
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/coreos/go-semver/semver"
 	"github.com/golang/geo/s2"
 	"github.com/google/uuid"
+	"github.com/interuss/dss/pkg/datastore/testdb"
 	dsserr "github.com/interuss/dss/pkg/errors"
 	dssmodels "github.com/interuss/dss/pkg/models"
 	ridmodels "github.com/interuss/dss/pkg/rid/models"
@@ -40,13 +42,13 @@ func (store *isaStore) GetISA(ctx context.Context, id dssmodels.ID, forUpdate bo
 
 // Implements repos.ISA.DeleteISA
 func (store *isaStore) DeleteISA(ctx context.Context, isa *ridmodels.IdentificationServiceArea) (*ridmodels.IdentificationServiceArea, error) {
-	isa, ok := store.isas[isa.ID]
-	if !ok {
+	old, ok := store.isas[isa.ID]
+	if !ok || !old.Version.Matches(isa.Version) {
 		return nil, nil
 	}
 	delete(store.isas, isa.ID)
 
-	return isa, nil
+	return old, nil
 }
 
 // Implements repos.ISA.InsertISA
@@ -73,11 +75,94 @@ func (store *isaStore) GetVersion(ctx context.Context) (*semver.Version, error)
 }
 
 // Implements repos.ISA.SearchISA
-func (store *isaStore) SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time) ([]*ridmodels.IdentificationServiceArea, error) {
+func (store *isaStore) SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time, excludeOwner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error) {
+	var isas []*ridmodels.IdentificationServiceArea
+
+	for _, isa := range store.isas {
+		if isa.Cells.Intersects(cells) && (excludeOwner == "" || isa.Owner != excludeOwner) {
+			isas = append(isas, isa)
+		}
+	}
+	return isas, nil
+}
+
+// Implements repos.ISA.CountISAs
+func (store *isaStore) CountISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time, limit int) (int, error) {
+	isas, err := store.SearchISAs(ctx, cells, earliest, latest, "")
+	return min(len(isas), limit), err
+}
+
+// Implements repos.ISA.UpdateISALabels
+func (store *isaStore) UpdateISALabels(ctx context.Context, id dssmodels.ID, labels ridmodels.Labels) (*ridmodels.IdentificationServiceArea, error) {
+	isa, ok := store.isas[id]
+	if !ok {
+		return nil, nil
+	}
+	isa.Labels = labels
+	returnedCopy := *isa
+	return &returnedCopy, nil
+}
+
+// Implements repos.ISA.TransferISA
+func (store *isaStore) TransferISA(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner) (*ridmodels.IdentificationServiceArea, error) {
+	isa, ok := store.isas[id]
+	if !ok {
+		return nil, nil
+	}
+	storedCopy := *isa
+	storedCopy.Owner = owner
+	storedCopy.Version = dssmodels.VersionFromTime(time.Now())
+	store.isas[id] = &storedCopy
+	returnedCopy := storedCopy
+	return &returnedCopy, nil
+}
+
+// Implements repos.ISA.SearchISAsByLabels
+func (store *isaStore) SearchISAsByLabels(ctx context.Context, labels ridmodels.Labels) ([]*ridmodels.IdentificationServiceArea, error) {
+	var isas []*ridmodels.IdentificationServiceArea
+
+	for _, isa := range store.isas {
+		if hasLabels(isa.Labels, labels) {
+			isas = append(isas, isa)
+		}
+	}
+	return isas, nil
+}
+
+// hasLabels returns true if all of "want" are in "labels".
+func hasLabels(labels, want ridmodels.Labels) bool {
+	for k, v := range want {
+		if got, ok := labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Implements repos.ISA.ListISAsByOwner
+func (store *isaStore) ListISAsByOwner(ctx context.Context, owner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error) {
+	var isas []*ridmodels.IdentificationServiceArea
+
+	for _, isa := range store.isas {
+		if isa.Owner == owner {
+			isas = append(isas, isa)
+		}
+	}
+	return isas, nil
+}
+
+// Implements repos.ISA.CountISAsByOwner
+func (store *isaStore) CountISAsByOwner(ctx context.Context, owner dssmodels.Owner) (int, error) {
+	isas, _ := store.ListISAsByOwner(ctx, owner)
+	return len(isas), nil
+}
+
+// Implements repos.ISA.SearchISAsByURL
+func (store *isaStore) SearchISAsByURL(ctx context.Context, url string, prefix bool) ([]*ridmodels.IdentificationServiceArea, error) {
 	var isas []*ridmodels.IdentificationServiceArea
 
 	for _, isa := range store.isas {
-		if isa.Cells.Intersects(cells) {
+		if isa.URL == url || (prefix && strings.HasPrefix(isa.URL, url)) {
 			isas = append(isas, isa)
 		}
 	}
@@ -112,11 +197,12 @@ func TestISAUpdateIdxCells(t *testing.T) {
 	require.NotNil(t, isa)
 	// Now insert 2 subs, one overlaps with the original isa, and the second, overlaps
 	// with the soon to be new version of the isa. both should increase their
-	// notification index.
+	// notification index. They belong to another owner since the ISA owner is
+	// not notified of its own changes.
 
 	_, err = app.InsertSubscription(ctx, &ridmodels.Subscription{
 		ID:        dssmodels.ID(uuid.New().String()),
-		Owner:     "owner",
+		Owner:     "other owner",
 		StartTime: &startTime,
 		EndTime:   &endTime,
 		Cells:     s2.CellUnion{17106221850767130624, 17106221919486607360},
@@ -125,7 +211,7 @@ func TestISAUpdateIdxCells(t *testing.T) {
 
 	_, err = app.InsertSubscription(ctx, &ridmodels.Subscription{
 		ID:        dssmodels.ID(uuid.New().String()),
-		Owner:     "owner",
+		Owner:     "other owner",
 		StartTime: &startTime,
 		EndTime:   &endTime,
 		Cells:     s2.CellUnion{17106221953846345728},
@@ -142,10 +228,18 @@ func TestISAUpdateIdxCells(t *testing.T) {
 		require.Equal(t, 1, sub.NotificationIndex)
 	}
 
-	isas, err := app.SearchISAs(ctx, isa.Cells, &startTime, nil)
+	isas, err := app.SearchISAs(ctx, isa.Cells, &startTime, nil, "")
 	require.NoError(t, err)
 	require.NotNil(t, isas)
 	require.Len(t, isas, 1)
+
+	isas, err = app.SearchISAs(ctx, isa.Cells, &startTime, nil, "other owner")
+	require.NoError(t, err)
+	require.Len(t, isas, 1)
+
+	isas, err = app.SearchISAs(ctx, isa.Cells, &startTime, nil, "owner")
+	require.NoError(t, err)
+	require.Empty(t, isas)
 }
 
 func TestInsertISA(t *testing.T) {
@@ -356,9 +450,23 @@ func TestAppDeleteISAs(t *testing.T) {
 	for i := range insertedSubscriptions {
 		require.Equal(t, 43, subscriptionsOut[i].NotificationIndex)
 	}
-	// Can't delete with different owner.
-	_, _, err = app.DeleteISA(ctx, isa.ID, "bad-owner", isa.Version)
-	require.Error(t, err)
+	staleVersion := dssmodels.VersionFromTime(isa.Version.ToTimestamp().Add(-time.Second))
+	for _, r := range []struct {
+		name     string
+		owner    dssmodels.Owner
+		version  *dssmodels.Version
+		wantCode stacktrace.ErrorCode
+	}{
+		{"other owner", "bad-owner", isa.Version, dsserr.PermissionDenied},
+		{"other owner at stale version", "bad-owner", staleVersion, dsserr.PermissionDenied},
+		{"stale version", isa.Owner, staleVersion, dsserr.StaleVersion},
+		{"missing version", isa.Owner, nil, dsserr.BadRequest},
+	} {
+		_, _, err = app.DeleteISA(ctx, isa.ID, r.owner, r.version)
+		require.Equal(t, r.wantCode, stacktrace.GetCode(err), r.name)
+	}
+	_, _, err = app.DeleteISA(ctx, dssmodels.ID(uuid.New().String()), isa.Owner, isa.Version)
+	require.Equal(t, dsserr.NotFound, stacktrace.GetCode(err))
 
 	// Delete the ISA.
 	// Ensure a fresh Get, then delete still updates the subscription indexes
@@ -378,3 +486,131 @@ func TestAppDeleteISAs(t *testing.T) {
 		require.Equal(t, 44, subscriptionsOut[i].NotificationIndex)
 	}
 }
+
+func TestAppInsertISANotifiesOnlyRelevantSubscriptions(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		app, cleanup = setUpISAApp(ctx, t)
+		owner        = dssmodels.Owner(uuid.New().String())
+		later        = endTime.Add(time.Hour)
+		evenLater    = endTime.Add(2 * time.Hour)
+	)
+	defer cleanup()
+
+	subs := map[string]*ridmodels.Subscription{
+		"foreign": {
+			Owner:     dssmodels.Owner(uuid.New().String()),
+			StartTime: &startTime,
+			EndTime:   &endTime,
+		},
+		"own": {
+			Owner:     owner,
+			StartTime: &startTime,
+			EndTime:   &endTime,
+		},
+		"after": {
+			Owner:     dssmodels.Owner(uuid.New().String()),
+			StartTime: &later,
+			EndTime:   &evenLater,
+		},
+	}
+	for _, s := range subs {
+		s.ID = dssmodels.ID(uuid.New().String())
+		s.URL = "https://no/place/like/home"
+		s.Cells = s2.CellUnion{12494535935418957824}
+		_, err := app.InsertSubscription(ctx, s)
+		require.NoError(t, err)
+	}
+
+	_, subscriptionsOut, err := app.InsertISA(ctx, &ridmodels.IdentificationServiceArea{
+		ID:        dssmodels.ID(uuid.New().String()),
+		Owner:     owner,
+		URL:       "https://no/place/like/home/for/flights",
+		StartTime: &startTime,
+		EndTime:   &endTime,
+		Cells:     s2.CellUnion{12494535935418957824},
+	})
+	require.NoError(t, err)
+	require.Len(t, subscriptionsOut, 1)
+	require.Equal(t, subs["foreign"].ID, subscriptionsOut[0].ID)
+}
+
+func TestAppConcurrentUpdateISA(t *testing.T) {
+	if !testdb.Enabled() {
+		t.Skip("The in-memory store does not support concurrent access")
+	}
+	var (
+		ctx          = context.Background()
+		app, cleanup = setUpISAApp(ctx, t)
+		owner        = dssmodels.Owner(uuid.New().String())
+	)
+	defer cleanup()
+
+	isa, _, err := app.InsertISA(ctx, &ridmodels.IdentificationServiceArea{
+		ID:        dssmodels.ID(uuid.New().String()),
+		Owner:     owner,
+		URL:       "https://no/place/like/home/for/flights",
+		StartTime: &startTime,
+		EndTime:   &endTime,
+		Cells:     s2.CellUnion{12494535935418957824},
+	})
+	require.NoError(t, err)
+
+	const writers = 5
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		go func() {
+			update := *isa
+			_, _, err := app.UpdateISA(ctx, &update)
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < writers; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.Equal(t, dsserr.VersionMismatch, stacktrace.GetCode(err))
+	}
+	require.Equal(t, 1, succeeded)
+}
+
+func TestAppISALabels(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		app, cleanup = setUpISAApp(ctx, t)
+		owner        = dssmodels.Owner(uuid.New().String())
+	)
+	defer cleanup()
+
+	isa, _, err := app.InsertISA(ctx, &ridmodels.IdentificationServiceArea{
+		ID:        dssmodels.ID(uuid.New().String()),
+		Owner:     owner,
+		URL:       "https://no/place/like/home/for/flights",
+		StartTime: &startTime,
+		EndTime:   &endTime,
+		Cells:     s2.CellUnion{12494535935418957824},
+	})
+	require.NoError(t, err)
+
+	labels := ridmodels.Labels{"flight": "F42", "env": "test"}
+	_, err = app.SetISALabels(ctx, isa.ID, "bad-owner", labels)
+	require.Equal(t, dsserr.PermissionDenied, stacktrace.GetCode(err))
+
+	labeled, err := app.SetISALabels(ctx, isa.ID, owner, labels)
+	require.NoError(t, err)
+	require.Equal(t, labels, labeled.Labels)
+	require.Equal(t, isa.Version, labeled.Version)
+
+	isas, err := app.SearchISAsByLabels(ctx, ridmodels.Labels{"flight": "F42"})
+	require.NoError(t, err)
+	require.Len(t, isas, 1)
+	require.Equal(t, isa.ID, isas[0].ID)
+
+	isas, err = app.SearchISAsByLabels(ctx, ridmodels.Labels{"flight": "F43"})
+	require.NoError(t, err)
+	require.Empty(t, isas)
+}
@@ -0,0 +1,100 @@
+package application
+
+import (
+	"context"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/dss/pkg/rid/repos"
+	"github.com/interuss/stacktrace"
+)
+
+// LabelApp provides the application logic for the labels attached to ISAs and
+// Subscriptions.
+type LabelApp interface {
+	// SetISALabels replaces the labels of the ISA identified by "id" and owned
+	// by "owner".
+	SetISALabels(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, labels ridmodels.Labels) (*ridmodels.IdentificationServiceArea, error)
+
+	// SetSubscriptionLabels replaces the labels of the Subscription identified
+	// by "id" and owned by "owner".
+	SetSubscriptionLabels(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, labels ridmodels.Labels) (*ridmodels.Subscription, error)
+
+	// SearchISAsByLabels returns the active ISAs carrying all of "labels".
+	SearchISAsByLabels(ctx context.Context, labels ridmodels.Labels) ([]*ridmodels.IdentificationServiceArea, error)
+
+	// SearchSubscriptionsByLabels returns the active Subscriptions carrying
+	// all of "labels".
+	SearchSubscriptionsByLabels(ctx context.Context, labels ridmodels.Labels) ([]*ridmodels.Subscription, error)
+}
+
+func (a *app) SetISALabels(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, labels ridmodels.Labels) (*ridmodels.IdentificationServiceArea, error) {
+	if err := labels.Validate(); err != nil {
+		return nil, stacktrace.Propagate(err, "Invalid labels")
+	}
+	var ret *ridmodels.IdentificationServiceArea
+	// The following will automatically retry TXN retry errors.
+	err := a.Store.Transact(ctx, func(repo repos.Repository) error {
+		old, err := repo.GetISA(ctx, id, true)
+		switch {
+		case err != nil:
+			return stacktrace.Propagate(err, "Error getting ISA")
+		case old == nil:
+			return stacktrace.NewErrorWithCode(dsserr.NotFound, "ISA %s not found", id.String())
+		case old.Owner != owner:
+			return stacktrace.NewErrorWithCode(dsserr.PermissionDenied,
+				"ISA owned by %s, but %s attempted to label", old.Owner, owner)
+		}
+
+		ret, err = repo.UpdateISALabels(ctx, id, labels)
+		if err != nil {
+			return stacktrace.Propagate(err, "Error updating ISA labels")
+		}
+		return nil
+	})
+	return ret, err // No need to Propagate this error as this stack layer does not add useful information
+}
+
+func (a *app) SetSubscriptionLabels(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, labels ridmodels.Labels) (*ridmodels.Subscription, error) {
+	if err := labels.Validate(); err != nil {
+		return nil, stacktrace.Propagate(err, "Invalid labels")
+	}
+	var ret *ridmodels.Subscription
+	// The following will automatically retry TXN retry errors.
+	err := a.Store.Transact(ctx, func(repo repos.Repository) error {
+		old, err := repo.GetSubscription(ctx, id, true)
+		switch {
+		case err != nil:
+			return stacktrace.Propagate(err, "Error getting Subscription from repo")
+		case old == nil:
+			return stacktrace.NewErrorWithCode(dsserr.NotFound, "Subscription %s not found", id.String())
+		case old.Owner != owner:
+			return stacktrace.NewErrorWithCode(dsserr.PermissionDenied,
+				"Subscription owned by %s, but %s attempted to label", old.Owner, owner)
+		}
+
+		ret, err = repo.UpdateSubscriptionLabels(ctx, id, labels)
+		if err != nil {
+			return stacktrace.Propagate(err, "Error updating Subscription labels")
+		}
+		return nil
+	})
+	return ret, err // No need to Propagate this error as this stack layer does not add useful information
+}
+
+func (a *app) SearchISAsByLabels(ctx context.Context, labels ridmodels.Labels) ([]*ridmodels.IdentificationServiceArea, error) {
+	repo, err := a.Store.Interact(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to interact with store")
+	}
+	return repo.SearchISAsByLabels(ctx, labels)
+}
+
+func (a *app) SearchSubscriptionsByLabels(ctx context.Context, labels ridmodels.Labels) ([]*ridmodels.Subscription, error) {
+	repo, err := a.Store.Interact(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to interact with store")
+	}
+	return repo.SearchSubscriptionsByLabels(ctx, labels)
+}
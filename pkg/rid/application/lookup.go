@@ -0,0 +1,38 @@
+package application
+
+import (
+	"context"
+
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+)
+
+// LookupApp provides the application logic to find entities across owners,
+// e.g. those an operations team only knows by the URL of a misbehaving feed.
+type LookupApp interface {
+	// SearchISAsByURL returns the active ISAs of all owners whose flights URL
+	// is "url" or, if "prefix" is set, starts with "url".
+	SearchISAsByURL(ctx context.Context, url string, prefix bool) ([]*ridmodels.IdentificationServiceArea, error)
+
+	// SearchSubscriptionsByURL returns the active subscriptions of "owner"
+	// whose callback URL contains "substring", e.g. for a USS to find its
+	// subscriptions pointing at a decommissioned endpoint.
+	SearchSubscriptionsByURL(ctx context.Context, owner dssmodels.Owner, substring string) ([]*ridmodels.Subscription, error)
+}
+
+func (a *app) SearchISAsByURL(ctx context.Context, url string, prefix bool) ([]*ridmodels.IdentificationServiceArea, error) {
+	repo, err := a.Store.Interact(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to interact with store")
+	}
+	return repo.SearchISAsByURL(ctx, url, prefix)
+}
+
+func (a *app) SearchSubscriptionsByURL(ctx context.Context, owner dssmodels.Owner, substring string) ([]*ridmodels.Subscription, error) {
+	repo, err := a.Store.Interact(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to interact with store")
+	}
+	return repo.SearchSubscriptionsByURL(ctx, owner, substring)
+}
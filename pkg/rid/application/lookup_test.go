@@ -0,0 +1,83 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/uuid"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+)
+
+var _ LookupApp = &app{}
+
+func TestSearchISAsByURL(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		app, cleanup = setUpISAApp(ctx, t)
+		cells        = s2.CellUnion{12494535935418957824}
+	)
+	defer cleanup()
+
+	insert := func(owner dssmodels.Owner, url string) *ridmodels.IdentificationServiceArea {
+		isa, _, err := app.InsertISA(ctx, &ridmodels.IdentificationServiceArea{
+			ID:        dssmodels.ID(uuid.New().String()),
+			Owner:     owner,
+			URL:       url,
+			StartTime: &startTime,
+			EndTime:   &endTime,
+			Cells:     cells,
+		})
+		require.NoError(t, err)
+		return isa
+	}
+	feed := insert("uss1", "https://uss1.example/flights")
+	mirror := insert("uss2", "https://uss1.example/flights/mirror")
+	insert("uss3", "https://uss3.example/flights")
+
+	isas, err := app.SearchISAsByURL(ctx, "https://uss1.example/flights", false)
+	require.NoError(t, err)
+	require.Len(t, isas, 1)
+	require.Equal(t, feed.ID, isas[0].ID)
+
+	isas, err = app.SearchISAsByURL(ctx, "https://uss1.example/", true)
+	require.NoError(t, err)
+	require.Len(t, isas, 2)
+	require.ElementsMatch(t, []dssmodels.ID{feed.ID, mirror.ID}, []dssmodels.ID{isas[0].ID, isas[1].ID})
+}
+
+func TestSearchSubscriptionsByURL(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		app, cleanup = setUpSubApp(ctx, t)
+		cells        = s2.CellUnion{12494535935418957824}
+	)
+	defer cleanup()
+
+	insert := func(owner dssmodels.Owner, url string) *ridmodels.Subscription {
+		sub, err := app.InsertSubscription(ctx, &ridmodels.Subscription{
+			ID:        dssmodels.ID(uuid.New().String()),
+			Owner:     owner,
+			URL:       url,
+			StartTime: &startTime,
+			EndTime:   &endTime,
+			Cells:     cells,
+		})
+		require.NoError(t, err)
+		return sub
+	}
+	stale := insert("uss1", "https://old.uss1.example/notify")
+	insert("uss1", "https://new.uss1.example/notify")
+	insert("uss2", "https://old.uss1.example/notify")
+
+	subs, err := app.SearchSubscriptionsByURL(ctx, "uss1", "old.uss1")
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	require.Equal(t, stale.ID, subs[0].ID)
+
+	subs, err = app.SearchSubscriptionsByURL(ctx, "uss1", "")
+	require.NoError(t, err)
+	require.Len(t, subs, 2)
+}
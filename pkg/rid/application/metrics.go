@@ -0,0 +1,41 @@
+package application
+
+import (
+	"context"
+
+	"github.com/golang/geo/s2"
+	"github.com/interuss/dss/pkg/cost"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	notificationFanout = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dss_rid_isa_notification_fanout",
+		Help:    "Number of subscriptions to notify per ISA mutation, by operation.",
+		Buckets: []float64{0, 1, 2, 5, 10, 20, 50, 100, 200, 500, 1000},
+	}, []string{"operation"})
+	suppressedNotifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dss_rid_suppressed_notifications_total",
+		Help: "Number of subscriptions left out of the subscribers to notify of an ISA mutation by their minimum notification interval, by operation.",
+	}, []string{"operation"})
+	requestCells = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dss_rid_request_cells",
+		Help:    "Number of S2 cells covered per remote ID request, by entity and operation.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+	}, []string{"entity", "operation"})
+)
+
+// observeFanout records the number of subscriptions notified of an ISA
+// mutation.
+func observeFanout(operation string, subs []*ridmodels.Subscription) {
+	notificationFanout.WithLabelValues(operation).Observe(float64(len(subs)))
+}
+
+// observeCells records the number of cells covered by a request, which adds
+// to its cost.
+func observeCells(ctx context.Context, entity, operation string, cells s2.CellUnion) {
+	requestCells.WithLabelValues(entity, operation).Observe(float64(len(cells)))
+	cost.AddCells(ctx, len(cells))
+}
@@ -0,0 +1,60 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/uuid"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+)
+
+// histogram returns the sample count and sum of h.
+func histogram(t *testing.T, h prometheus.Observer) (uint64, float64) {
+	m := &dto.Metric{}
+	require.NoError(t, h.(prometheus.Metric).Write(m))
+	return m.GetHistogram().GetSampleCount(), m.GetHistogram().GetSampleSum()
+}
+
+func TestInsertISAObservesFanoutAndCells(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		app, cleanup = setUpISAApp(ctx, t)
+		fanout       = notificationFanout.WithLabelValues("insert")
+		cells        = requestCells.WithLabelValues("isa", "insert")
+	)
+	defer cleanup()
+
+	_, err := app.InsertSubscription(ctx, &ridmodels.Subscription{
+		ID:        dssmodels.ID(uuid.New().String()),
+		Owner:     dssmodels.Owner(uuid.New().String()),
+		URL:       "https://no/place/like/home",
+		StartTime: &startTime,
+		EndTime:   &endTime,
+		Cells:     s2.CellUnion{12494535935418957824},
+	})
+	require.NoError(t, err)
+
+	fanoutCount, fanoutSum := histogram(t, fanout)
+	cellsCount, cellsSum := histogram(t, cells)
+	_, _, err = app.InsertISA(ctx, &ridmodels.IdentificationServiceArea{
+		ID:        dssmodels.ID(uuid.New().String()),
+		Owner:     dssmodels.Owner(uuid.New().String()),
+		URL:       "https://no/place/like/home/for/flights",
+		StartTime: &startTime,
+		EndTime:   &endTime,
+		Cells:     s2.CellUnion{12494535935418957824, 12494535952598827008},
+	})
+	require.NoError(t, err)
+
+	count, sum := histogram(t, fanout)
+	require.Equal(t, fanoutCount+1, count)
+	require.Equal(t, fanoutSum+1, sum)
+	count, sum = histogram(t, cells)
+	require.Equal(t, cellsCount+1, count)
+	require.Equal(t, cellsSum+2, sum)
+}
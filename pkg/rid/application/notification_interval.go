@@ -0,0 +1,77 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/dss/pkg/rid/repos"
+	"github.com/interuss/stacktrace"
+)
+
+// NotificationIntervalApp provides the application logic for the minimum
+// intervals between the notifications of Subscriptions.
+type NotificationIntervalApp interface {
+	// SetSubscriptionNotificationInterval replaces the minimum interval
+	// between the notifications of the Subscription identified by "id" and
+	// owned by "owner", 0 for none.
+	SetSubscriptionNotificationInterval(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, interval time.Duration) (*ridmodels.Subscription, error)
+}
+
+func (a *app) SetSubscriptionNotificationInterval(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, interval time.Duration) (*ridmodels.Subscription, error) {
+	if err := ridmodels.ValidateNotificationInterval(interval); err != nil {
+		return nil, stacktrace.Propagate(err, "Invalid minimum notification interval")
+	}
+	var ret *ridmodels.Subscription
+	// The following will automatically retry TXN retry errors.
+	err := a.Store.Transact(ctx, func(repo repos.Repository) error {
+		old, err := repo.GetSubscription(ctx, id, true)
+		switch {
+		case err != nil:
+			return stacktrace.Propagate(err, "Error getting Subscription from repo")
+		case old == nil:
+			return stacktrace.NewErrorWithCode(dsserr.NotFound, "Subscription %s not found", id.String())
+		case old.Owner != owner:
+			return stacktrace.NewErrorWithCode(dsserr.PermissionDenied,
+				"Subscription owned by %s, but %s attempted to set its notification interval", old.Owner, owner)
+		}
+
+		ret, err = repo.UpdateSubscriptionNotificationInterval(ctx, id, interval)
+		if err != nil {
+			return stacktrace.Propagate(err, "Error updating Subscription notification interval")
+		}
+		return nil
+	})
+	return ret, err // No need to Propagate this error as this stack layer does not add useful information
+}
+
+// collapseNotifications returns the subscriptions of subs, whose notification
+// indices were just incremented, that are due a notification: those notified
+// less than their minimum notification interval ago are left out, so that the
+// changes within the interval are collapsed into the notification of the
+// first. The notifications of the others with an interval are recorded.
+func (a *app) collapseNotifications(ctx context.Context, repo repos.Repository, operation string, subs []*ridmodels.Subscription) ([]*ridmodels.Subscription, error) {
+	var (
+		now      = a.clock.Now()
+		due      = make([]*ridmodels.Subscription, 0, len(subs))
+		recorded []dssmodels.ID
+	)
+	for _, sub := range subs {
+		if !sub.NotificationDue(now) {
+			continue
+		}
+		if sub.MinNotificationInterval > 0 {
+			recorded = append(recorded, sub.ID)
+		}
+		due = append(due, sub)
+	}
+	if len(recorded) > 0 {
+		if err := repo.RecordSubscriptionNotifications(ctx, recorded, now); err != nil {
+			return nil, stacktrace.Propagate(err, "Error recording Subscription notifications")
+		}
+	}
+	suppressedNotifications.WithLabelValues(operation).Add(float64(len(subs) - len(due)))
+	return due, nil
+}
@@ -0,0 +1,83 @@
+package application
+
+import (
+	"context"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/dss/pkg/rid/repos"
+	"github.com/interuss/stacktrace"
+)
+
+// OwnerAliasApp provides the application logic to map the subjects of access
+// tokens to canonical owners, e.g. so that a USS migrating to another
+// authorization server keeps access to its entities.
+type OwnerAliasApp interface {
+	// ListOwnerAliases returns all the aliases, ordered by subject.
+	ListOwnerAliases(ctx context.Context) ([]*ridmodels.OwnerAlias, error)
+
+	// SetOwnerAlias makes the tokens of subject act as owner. Aliases do not
+	// chain: owner may not be the subject of an alias, nor subject the owner
+	// of one.
+	SetOwnerAlias(ctx context.Context, subject dssmodels.Owner, owner dssmodels.Owner) (*ridmodels.OwnerAlias, error)
+
+	// DeleteOwnerAlias deletes the alias of subject, whose tokens then act as
+	// subject again.
+	DeleteOwnerAlias(ctx context.Context, subject dssmodels.Owner) (*ridmodels.OwnerAlias, error)
+}
+
+func (a *app) ListOwnerAliases(ctx context.Context) ([]*ridmodels.OwnerAlias, error) {
+	repo, err := a.Store.Interact(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to interact with store")
+	}
+	aliases, err := repo.ListOwnerAliases(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to list owner aliases")
+	}
+	return aliases, nil
+}
+
+func (a *app) SetOwnerAlias(ctx context.Context, subject dssmodels.Owner, owner dssmodels.Owner) (*ridmodels.OwnerAlias, error) {
+	switch {
+	case subject == "" || owner == "":
+		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing subject or owner")
+	case subject == owner:
+		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Subject %s may not be its own alias", subject)
+	}
+
+	var result *ridmodels.OwnerAlias
+	err := a.Store.Transact(ctx, func(repo repos.Repository) error {
+		aliases, err := repo.ListOwnerAliases(ctx)
+		if err != nil {
+			return stacktrace.Propagate(err, "Unable to list owner aliases")
+		}
+		for _, alias := range aliases {
+			switch {
+			case alias.Subject == owner:
+				return stacktrace.NewErrorWithCode(dsserr.BadRequest, "Owner %s is itself an alias of %s", owner, alias.Owner)
+			case alias.Owner == subject:
+				return stacktrace.NewErrorWithCode(dsserr.BadRequest, "Subject %s is the owner of the alias %s", subject, alias.Subject)
+			}
+		}
+		result, err = repo.UpsertOwnerAlias(ctx, &ridmodels.OwnerAlias{Subject: subject, Owner: owner})
+		return err // No need to Propagate this error as this stack layer does not add useful information
+	})
+	return result, err // No need to Propagate this error as this stack layer does not add useful information
+}
+
+func (a *app) DeleteOwnerAlias(ctx context.Context, subject dssmodels.Owner) (*ridmodels.OwnerAlias, error) {
+	repo, err := a.Store.Interact(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to interact with store")
+	}
+	alias, err := repo.DeleteOwnerAlias(ctx, subject)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to delete owner alias")
+	}
+	if alias == nil {
+		return nil, stacktrace.NewErrorWithCode(dsserr.NotFound, "Subject %s has no alias", subject)
+	}
+	return alias, nil
+}
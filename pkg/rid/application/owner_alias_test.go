@@ -0,0 +1,85 @@
+package application
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var _ OwnerAliasApp = &app{}
+
+type ownerAliasStore struct {
+	aliases map[dssmodels.Owner]*ridmodels.OwnerAlias
+}
+
+// Implements repos.OwnerAlias.ListOwnerAliases
+func (store *ownerAliasStore) ListOwnerAliases(ctx context.Context) ([]*ridmodels.OwnerAlias, error) {
+	var aliases []*ridmodels.OwnerAlias
+	for _, alias := range store.aliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Subject < aliases[j].Subject })
+	return aliases, nil
+}
+
+// Implements repos.OwnerAlias.UpsertOwnerAlias
+func (store *ownerAliasStore) UpsertOwnerAlias(ctx context.Context, alias *ridmodels.OwnerAlias) (*ridmodels.OwnerAlias, error) {
+	now := fakeClock.Now()
+	stored := &ridmodels.OwnerAlias{Subject: alias.Subject, Owner: alias.Owner, UpdatedAt: &now}
+	store.aliases[alias.Subject] = stored
+	return stored, nil
+}
+
+// Implements repos.OwnerAlias.DeleteOwnerAlias
+func (store *ownerAliasStore) DeleteOwnerAlias(ctx context.Context, subject dssmodels.Owner) (*ridmodels.OwnerAlias, error) {
+	alias := store.aliases[subject]
+	delete(store.aliases, subject)
+	return alias, nil
+}
+
+func TestOwnerAliases(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t, zap.NewNop())
+		app                  = NewFromTransactor(store, zap.NewNop())
+	)
+	defer tearDownStore()
+
+	alias, err := app.SetOwnerAlias(ctx, "new-idp-subject", "uss1")
+	require.NoError(t, err)
+	require.EqualValues(t, "uss1", alias.Owner)
+	_, err = app.SetOwnerAlias(ctx, "other-idp-subject", "uss1")
+	require.NoError(t, err)
+
+	aliases, err := app.ListOwnerAliases(ctx)
+	require.NoError(t, err)
+	require.Len(t, aliases, 2)
+	require.EqualValues(t, "new-idp-subject", aliases[0].Subject)
+	require.EqualValues(t, "other-idp-subject", aliases[1].Subject)
+
+	// Aliases do not chain.
+	for _, invalid := range []struct{ subject, owner dssmodels.Owner }{
+		{"", "uss1"},
+		{"uss1", "uss1"},
+		{"uss2", "new-idp-subject"},
+		{"uss1", "uss2"},
+	} {
+		_, err = app.SetOwnerAlias(ctx, invalid.subject, invalid.owner)
+		require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err), "%s -> %s", invalid.subject, invalid.owner)
+	}
+
+	_, err = app.DeleteOwnerAlias(ctx, "new-idp-subject")
+	require.NoError(t, err)
+	_, err = app.DeleteOwnerAlias(ctx, "new-idp-subject")
+	require.Equal(t, dsserr.NotFound, stacktrace.GetCode(err))
+	aliases, err = app.ListOwnerAliases(ctx)
+	require.NoError(t, err)
+	require.Len(t, aliases, 1)
+}
@@ -0,0 +1,149 @@
+package application
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/geo/s2"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/geo"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+)
+
+// WritePolicy vets the ISAs and subscriptions written by USSs before they are
+// stored, so that deployments can encode their regulatory constraints without
+// changing the handlers. Its methods are called once the time range of the
+// entity has been adjusted and return an error with code dsserr.BadRequest
+// describing the first violation of the policy, or nil.
+type WritePolicy interface {
+	CheckISA(ctx context.Context, isa *ridmodels.IdentificationServiceArea) error
+	CheckSubscription(ctx context.Context, sub *ridmodels.Subscription) error
+}
+
+// WritePolicies is a WritePolicy requiring entities to satisfy all of its
+// policies, which are checked in order.
+type WritePolicies []WritePolicy
+
+// CheckISA implements WritePolicy.
+func (ps WritePolicies) CheckISA(ctx context.Context, isa *ridmodels.IdentificationServiceArea) error {
+	for _, p := range ps {
+		if err := p.CheckISA(ctx, isa); err != nil {
+			return err // No need to Propagate this error as this stack layer does not add useful information
+		}
+	}
+	return nil
+}
+
+// CheckSubscription implements WritePolicy.
+func (ps WritePolicies) CheckSubscription(ctx context.Context, sub *ridmodels.Subscription) error {
+	for _, p := range ps {
+		if err := p.CheckSubscription(ctx, sub); err != nil {
+			return err // No need to Propagate this error as this stack layer does not add useful information
+		}
+	}
+	return nil
+}
+
+// Limits is a WritePolicy rejecting implausible extents. The zero value only
+// requires the lower altitude of an entity not to exceed its upper altitude.
+type Limits struct {
+	// MaxISAAreaKm2 bounds the area of the cells covering an ISA; unbounded if
+	// 0.
+	MaxISAAreaKm2 float64
+	// MaxISADuration bounds the interval between the start and end times of an
+	// ISA; unbounded if 0.
+	MaxISADuration time.Duration
+	// MinAltitude and MaxAltitude bound the altitudes of ISAs and
+	// subscriptions, in meters above the WGS84 ellipsoid; unbounded if nil.
+	MinAltitude *float32
+	MaxAltitude *float32
+	// MaxCells bounds the number of cells covering an ISA or a subscription,
+	// which bloat the cell indices and slow writes down; unbounded if 0.
+	MaxCells int
+}
+
+// CheckISA implements WritePolicy.
+func (l Limits) CheckISA(_ context.Context, isa *ridmodels.IdentificationServiceArea) error {
+	if err := l.checkCells("ISA", isa.Cells); err != nil {
+		return err
+	}
+	if l.MaxISAAreaKm2 > 0 {
+		if area := geo.CellUnionAreaKm2(isa.Cells); area > l.MaxISAAreaKm2 {
+			return stacktrace.NewErrorWithCode(dsserr.BadRequest,
+				"ISA covers %.1fkm², more than the %.1fkm² allowed by this DSS", area, l.MaxISAAreaKm2)
+		}
+	}
+	if l.MaxISADuration > 0 && isa.StartTime != nil && isa.EndTime != nil {
+		if d := isa.EndTime.Sub(*isa.StartTime); d > l.MaxISADuration {
+			return stacktrace.NewErrorWithCode(dsserr.BadRequest,
+				"ISA lasts %s, longer than the %s allowed by this DSS", d, l.MaxISADuration)
+		}
+	}
+	return l.checkAltitudes("ISA", isa.AltitudeLo, isa.AltitudeHi)
+}
+
+// CheckSubscription implements WritePolicy.
+func (l Limits) CheckSubscription(_ context.Context, sub *ridmodels.Subscription) error {
+	if err := l.checkCells("Subscription", sub.Cells); err != nil {
+		return err
+	}
+	return l.checkAltitudes("Subscription", sub.AltitudeLo, sub.AltitudeHi)
+}
+
+func (l Limits) checkCells(entity string, cells s2.CellUnion) error {
+	if l.MaxCells > 0 && len(cells) > l.MaxCells {
+		return stacktrace.NewErrorWithCode(dsserr.BadRequest,
+			"%s area is covered by %d cells, more than the %d allowed by this DSS; simplify or split the area", entity, len(cells), l.MaxCells)
+	}
+	return nil
+}
+
+func (l Limits) checkAltitudes(entity string, lo, hi *float32) error {
+	if lo != nil && hi != nil && *lo > *hi {
+		return stacktrace.NewErrorWithCode(dsserr.BadRequest,
+			"%s altitude_lo %gm exceeds its altitude_hi %gm", entity, *lo, *hi)
+	}
+	if l.MinAltitude != nil && lo != nil && *lo < *l.MinAltitude {
+		return stacktrace.NewErrorWithCode(dsserr.BadRequest,
+			"%s altitude_lo %gm is below the minimum of %gm allowed by this DSS", entity, *lo, *l.MinAltitude)
+	}
+	if l.MaxAltitude != nil && hi != nil && *hi > *l.MaxAltitude {
+		return stacktrace.NewErrorWithCode(dsserr.BadRequest,
+			"%s altitude_hi %gm is above the maximum of %gm allowed by this DSS", entity, *hi, *l.MaxAltitude)
+	}
+	return nil
+}
+
+// SwappableWritePolicy is a WritePolicy delegating to a policy which can be
+// replaced while in use, e.g. when the configuration is reloaded. It accepts
+// all entities while it delegates to no policy.
+type SwappableWritePolicy struct {
+	current atomic.Pointer[writePolicyRef]
+}
+
+type writePolicyRef struct {
+	policy WritePolicy
+}
+
+// Swap makes p delegate to policy, or to no policy if policy is nil.
+func (p *SwappableWritePolicy) Swap(policy WritePolicy) {
+	p.current.Store(&writePolicyRef{policy: policy})
+}
+
+// CheckISA implements WritePolicy.
+func (p *SwappableWritePolicy) CheckISA(ctx context.Context, isa *ridmodels.IdentificationServiceArea) error {
+	if ref := p.current.Load(); ref != nil && ref.policy != nil {
+		return ref.policy.CheckISA(ctx, isa)
+	}
+	return nil
+}
+
+// CheckSubscription implements WritePolicy.
+func (p *SwappableWritePolicy) CheckSubscription(ctx context.Context, sub *ridmodels.Subscription) error {
+	if ref := p.current.Load(); ref != nil && ref.policy != nil {
+		return ref.policy.CheckSubscription(ctx, sub)
+	}
+	return nil
+}
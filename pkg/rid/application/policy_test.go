@@ -0,0 +1,190 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/uuid"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+func float32Ptr(f float32) *float32 {
+	return &f
+}
+
+func TestLimitsCheckISA(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		start = fakeClock.Now()
+		end   = start.Add(time.Hour)
+		// A level 13 cell covers about 1km².
+		cells = s2.CellUnion{12494535935418957824}
+	)
+	for _, r := range []struct {
+		name    string
+		limits  Limits
+		isa     ridmodels.IdentificationServiceArea
+		wantErr bool
+	}{
+		{
+			name: "zero-value",
+			isa:  ridmodels.IdentificationServiceArea{Cells: cells, StartTime: &start, EndTime: &end},
+		},
+		{
+			name:   "area-within-limit",
+			limits: Limits{MaxISAAreaKm2: 2},
+			isa:    ridmodels.IdentificationServiceArea{Cells: cells},
+		},
+		{
+			name:    "area-above-limit",
+			limits:  Limits{MaxISAAreaKm2: 0.5},
+			isa:     ridmodels.IdentificationServiceArea{Cells: cells},
+			wantErr: true,
+		},
+		{
+			name:   "cells-within-limit",
+			limits: Limits{MaxCells: 1},
+			isa:    ridmodels.IdentificationServiceArea{Cells: cells},
+		},
+		{
+			name:    "cells-above-limit",
+			limits:  Limits{MaxCells: 1},
+			isa:     ridmodels.IdentificationServiceArea{Cells: append(cells, cells[0].Next())},
+			wantErr: true,
+		},
+		{
+			name:   "duration-within-limit",
+			limits: Limits{MaxISADuration: time.Hour},
+			isa:    ridmodels.IdentificationServiceArea{StartTime: &start, EndTime: &end},
+		},
+		{
+			name:    "duration-above-limit",
+			limits:  Limits{MaxISADuration: 30 * time.Minute},
+			isa:     ridmodels.IdentificationServiceArea{StartTime: &start, EndTime: &end},
+			wantErr: true,
+		},
+		{
+			name:    "altitude-lo-above-altitude-hi",
+			isa:     ridmodels.IdentificationServiceArea{AltitudeLo: float32Ptr(120), AltitudeHi: float32Ptr(20)},
+			wantErr: true,
+		},
+		{
+			name:   "altitudes-within-limits",
+			limits: Limits{MinAltitude: float32Ptr(-500), MaxAltitude: float32Ptr(10000)},
+			isa:    ridmodels.IdentificationServiceArea{AltitudeLo: float32Ptr(0), AltitudeHi: float32Ptr(120)},
+		},
+		{
+			name:    "altitude-below-minimum",
+			limits:  Limits{MinAltitude: float32Ptr(-500)},
+			isa:     ridmodels.IdentificationServiceArea{AltitudeLo: float32Ptr(-1000), AltitudeHi: float32Ptr(120)},
+			wantErr: true,
+		},
+		{
+			name:    "altitude-above-maximum",
+			limits:  Limits{MaxAltitude: float32Ptr(10000)},
+			isa:     ridmodels.IdentificationServiceArea{AltitudeLo: float32Ptr(0), AltitudeHi: float32Ptr(20000)},
+			wantErr: true,
+		},
+	} {
+		t.Run(r.name, func(t *testing.T) {
+			err := r.limits.CheckISA(ctx, &r.isa)
+			if r.wantErr {
+				require.Error(t, err)
+				require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err))
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLimitsCheckSubscription(t *testing.T) {
+	ctx := context.Background()
+	limits := Limits{MaxAltitude: float32Ptr(10000)}
+
+	require.NoError(t, limits.CheckSubscription(ctx, &ridmodels.Subscription{AltitudeLo: float32Ptr(0), AltitudeHi: float32Ptr(120)}))
+
+	err := limits.CheckSubscription(ctx, &ridmodels.Subscription{AltitudeLo: float32Ptr(0), AltitudeHi: float32Ptr(20000)})
+	require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err))
+
+	limits = Limits{MaxCells: 2}
+	cells := s2.CellUnion{12494535935418957824, 12494535935418957824 + 1<<33, 12494535935418957824 + 2<<33}
+	require.NoError(t, limits.CheckSubscription(ctx, &ridmodels.Subscription{Cells: cells[:2]}))
+	err = limits.CheckSubscription(ctx, &ridmodels.Subscription{Cells: cells})
+	require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err))
+	require.Contains(t, err.Error(), "covered by 3 cells, more than the 2 allowed")
+}
+
+func TestWritePolicies(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		start = fakeClock.Now()
+		end   = start.Add(time.Hour)
+		isa   = &ridmodels.IdentificationServiceArea{StartTime: &start, EndTime: &end}
+	)
+	require.NoError(t, WritePolicies{}.CheckISA(ctx, isa))
+	require.NoError(t, WritePolicies{Limits{}, Limits{MaxISADuration: time.Hour}}.CheckISA(ctx, isa))
+	require.Error(t, WritePolicies{Limits{}, Limits{MaxISADuration: time.Minute}}.CheckISA(ctx, isa))
+}
+
+func TestAppEnforcesWritePolicy(t *testing.T) {
+	ctx := context.Background()
+	app, cleanup := setUpISAApp(ctx, t)
+	defer cleanup()
+	app.policy = Limits{MaxISADuration: 2 * time.Hour}
+
+	start := fakeClock.Now()
+	end := start.Add(3 * time.Hour)
+	_, _, err := app.InsertISA(ctx, &ridmodels.IdentificationServiceArea{
+		ID:        dssmodels.ID(uuid.New().String()),
+		Owner:     dssmodels.Owner(uuid.New().String()),
+		Cells:     s2.CellUnion{12494535935418957824},
+		StartTime: &start,
+		EndTime:   &end,
+	})
+	require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err))
+
+	end = start.Add(time.Hour)
+	isa, _, err := app.InsertISA(ctx, &ridmodels.IdentificationServiceArea{
+		ID:        dssmodels.ID(uuid.New().String()),
+		Owner:     dssmodels.Owner(uuid.New().String()),
+		Cells:     s2.CellUnion{12494535935418957824},
+		StartTime: &start,
+		EndTime:   &end,
+	})
+	require.NoError(t, err)
+
+	end = start.Add(3 * time.Hour)
+	_, _, err = app.UpdateISA(ctx, &ridmodels.IdentificationServiceArea{
+		ID:        isa.ID,
+		Owner:     isa.Owner,
+		Cells:     isa.Cells,
+		StartTime: &start,
+		EndTime:   &end,
+		Version:   isa.Version,
+	})
+	require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err))
+}
+
+func TestSwappableWritePolicy(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		start  = fakeClock.Now()
+		end    = start.Add(time.Hour)
+		isa    = &ridmodels.IdentificationServiceArea{StartTime: &start, EndTime: &end}
+		policy SwappableWritePolicy
+	)
+	require.NoError(t, policy.CheckISA(ctx, isa))
+
+	policy.Swap(Limits{MaxISADuration: time.Minute})
+	require.Error(t, policy.CheckISA(ctx, isa))
+
+	policy.Swap(nil)
+	require.NoError(t, policy.CheckISA(ctx, isa))
+}
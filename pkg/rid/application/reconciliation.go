@@ -0,0 +1,105 @@
+package application
+
+import (
+	"context"
+
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/dss/pkg/rid/repos"
+	"github.com/interuss/stacktrace"
+)
+
+// ClaimedISA is an ISA that a USS reports as active.
+type ClaimedISA struct {
+	ID dssmodels.ID
+	// Version is nil if the USS does not know the version of the ISA.
+	Version *dssmodels.Version
+}
+
+// ISAVersionMismatch is an ISA whose version in the DSS differs from the one
+// claimed by its owner.
+type ISAVersionMismatch struct {
+	ISA            *ridmodels.IdentificationServiceArea
+	ClaimedVersion *dssmodels.Version
+}
+
+// ISAReconciliation is the outcome of comparing the ISAs claimed by a USS
+// against the ISAs indexed by the DSS for that USS.
+type ISAReconciliation struct {
+	// Missing are the claimed ISAs not indexed by the DSS.
+	Missing []dssmodels.ID
+	// Unclaimed are the ISAs indexed by the DSS but not claimed.
+	Unclaimed []*ridmodels.IdentificationServiceArea
+	// VersionMismatches are the ISAs indexed at a different version than
+	// claimed.
+	VersionMismatches []ISAVersionMismatch
+	// Removed are the unclaimed ISAs removed when repairing.
+	Removed []*ridmodels.IdentificationServiceArea
+}
+
+// ReconciliationApp provides the application logic to reconcile the DSS index
+// with the state reported by USSs, e.g. after a netsplit or a partial outage.
+type ReconciliationApp interface {
+	// ReconcileISAs compares the complete set of active ISAs claimed by
+	// "owner" with the ISAs the DSS indexes for "owner". If "repair" is true,
+	// the unclaimed ISAs are removed and the notification indices of their
+	// subscribers incremented.
+	ReconcileISAs(ctx context.Context, owner dssmodels.Owner, claimed []ClaimedISA, repair bool) (*ISAReconciliation, error)
+}
+
+func (a *app) ReconcileISAs(ctx context.Context, owner dssmodels.Owner, claimed []ClaimedISA, repair bool) (*ISAReconciliation, error) {
+	var result *ISAReconciliation
+	// The following will automatically retry TXN retry errors.
+	err := a.Store.Transact(ctx, func(repo repos.Repository) error {
+		result = &ISAReconciliation{}
+
+		indexed, err := repo.ListISAsByOwner(ctx, owner)
+		if err != nil {
+			return stacktrace.Propagate(err, "Error listing ISAs of %s", owner)
+		}
+		claims := make(map[dssmodels.ID]ClaimedISA, len(claimed))
+		for _, c := range claimed {
+			claims[c.ID] = c
+		}
+
+		for _, isa := range indexed {
+			c, ok := claims[isa.ID]
+			if !ok {
+				result.Unclaimed = append(result.Unclaimed, isa)
+				continue
+			}
+			delete(claims, isa.ID)
+			if c.Version != nil && !c.Version.Matches(isa.Version) {
+				result.VersionMismatches = append(result.VersionMismatches, ISAVersionMismatch{
+					ISA:            isa,
+					ClaimedVersion: c.Version,
+				})
+			}
+		}
+		for _, c := range claimed {
+			if _, ok := claims[c.ID]; ok {
+				result.Missing = append(result.Missing, c.ID)
+			}
+		}
+
+		if !repair {
+			return nil
+		}
+		for _, isa := range result.Unclaimed {
+			removed, err := repo.DeleteISA(ctx, isa)
+			if err != nil {
+				return stacktrace.Propagate(err, "Error deleting ISA %s", isa.ID)
+			}
+			if removed == nil {
+				// Modified concurrently; the next reconciliation will report it again.
+				continue
+			}
+			if _, err := repo.UpdateNotificationIdxsInCells(ctx, isa.Cells, isa.Owner, isa.StartTime, isa.EndTime); err != nil {
+				return stacktrace.Propagate(err, "Error updating notification indices")
+			}
+			result.Removed = append(result.Removed, removed)
+		}
+		return nil
+	})
+	return result, err // No need to Propagate this error as this stack layer does not add useful information
+}
@@ -0,0 +1,67 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/uuid"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+)
+
+var _ ReconciliationApp = &app{}
+
+func TestReconcileISAs(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		app, cleanup = setUpISAApp(ctx, t)
+		owner        = dssmodels.Owner(uuid.New().String())
+	)
+	defer cleanup()
+
+	insert := func() *ridmodels.IdentificationServiceArea {
+		isa, _, err := app.InsertISA(ctx, &ridmodels.IdentificationServiceArea{
+			ID:        dssmodels.ID(uuid.New().String()),
+			Owner:     owner,
+			URL:       "https://no/place/like/home/for/flights",
+			StartTime: &startTime,
+			EndTime:   &endTime,
+			Cells:     s2.CellUnion{12494535935418957824},
+		})
+		require.NoError(t, err)
+		return isa
+	}
+	claimedISA := insert()
+	unclaimedISA := insert()
+	missingID := dssmodels.ID(uuid.New().String())
+	staleVersion := dssmodels.VersionFromTime(startTime)
+
+	claimed := []ClaimedISA{
+		{ID: claimedISA.ID, Version: staleVersion},
+		{ID: missingID},
+	}
+
+	result, err := app.ReconcileISAs(ctx, owner, claimed, false)
+	require.NoError(t, err)
+	require.Equal(t, []dssmodels.ID{missingID}, result.Missing)
+	require.Len(t, result.Unclaimed, 1)
+	require.Equal(t, unclaimedISA.ID, result.Unclaimed[0].ID)
+	require.Len(t, result.VersionMismatches, 1)
+	require.Equal(t, claimedISA.ID, result.VersionMismatches[0].ISA.ID)
+	require.Empty(t, result.Removed)
+
+	isa, err := app.GetISA(ctx, unclaimedISA.ID)
+	require.NoError(t, err)
+	require.NotNil(t, isa)
+
+	result, err = app.ReconcileISAs(ctx, owner, claimed, true)
+	require.NoError(t, err)
+	require.Len(t, result.Removed, 1)
+	require.Equal(t, unclaimedISA.ID, result.Removed[0].ID)
+
+	isa, err = app.GetISA(ctx, unclaimedISA.ID)
+	require.NoError(t, err)
+	require.Nil(t, isa)
+}
@@ -0,0 +1,44 @@
+package application
+
+import (
+	"context"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/dss/pkg/rid/repos"
+	"github.com/interuss/stacktrace"
+)
+
+// SelfNotificationApp provides the application logic for the notifications of
+// Subscriptions of the changes to the ISAs of their owner.
+type SelfNotificationApp interface {
+	// SetSubscriptionSelfNotification sets whether the Subscription
+	// identified by "id" and owned by "owner" is notified of the changes to
+	// the ISAs of "owner".
+	SetSubscriptionSelfNotification(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, notify bool) (*ridmodels.Subscription, error)
+}
+
+func (a *app) SetSubscriptionSelfNotification(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, notify bool) (*ridmodels.Subscription, error) {
+	var ret *ridmodels.Subscription
+	// The following will automatically retry TXN retry errors.
+	err := a.Store.Transact(ctx, func(repo repos.Repository) error {
+		old, err := repo.GetSubscription(ctx, id, true)
+		switch {
+		case err != nil:
+			return stacktrace.Propagate(err, "Error getting Subscription from repo")
+		case old == nil:
+			return stacktrace.NewErrorWithCode(dsserr.NotFound, "Subscription %s not found", id.String())
+		case old.Owner != owner:
+			return stacktrace.NewErrorWithCode(dsserr.PermissionDenied,
+				"Subscription owned by %s, but %s attempted to set its self-notification", old.Owner, owner)
+		}
+
+		ret, err = repo.UpdateSubscriptionSelfNotification(ctx, id, notify)
+		if err != nil {
+			return stacktrace.Propagate(err, "Error updating Subscription self-notification")
+		}
+		return nil
+	})
+	return ret, err // No need to Propagate this error as this stack layer does not add useful information
+}
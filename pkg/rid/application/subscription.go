@@ -39,19 +39,16 @@ type SubscriptionApp interface {
 }
 
 func (a *app) GetSubscription(ctx context.Context, id dssmodels.ID) (*ridmodels.Subscription, error) {
-	repo, err := a.Store.Interact(ctx)
-	if err != nil {
-		return nil, stacktrace.Propagate(err, "Unable to interact with store")
-	}
-	return repo.GetSubscription(ctx, id)
+	return hedgedRead(ctx, a, "get_subscription", func(ctx context.Context, repo repos.Repository) (*ridmodels.Subscription, error) {
+		return repo.GetSubscription(ctx, id, false)
+	})
 }
 
 func (a *app) SearchSubscriptionsByOwner(ctx context.Context, cells s2.CellUnion, owner dssmodels.Owner) ([]*ridmodels.Subscription, error) {
-	repo, err := a.Store.Interact(ctx)
-	if err != nil {
-		return nil, stacktrace.Propagate(err, "Unable to interact with store")
-	}
-	return repo.SearchSubscriptionsByOwner(ctx, cells, owner)
+	observeCells(ctx, "subscription", "search", cells)
+	return hedgedRead(ctx, a, "search_subscriptions", func(ctx context.Context, repo repos.Repository) ([]*ridmodels.Subscription, error) {
+		return repo.SearchSubscriptionsByOwner(ctx, cells, owner)
+	})
 }
 
 func (a *app) InsertSubscription(ctx context.Context, s *ridmodels.Subscription) (*ridmodels.Subscription, error) {
@@ -59,11 +56,16 @@ func (a *app) InsertSubscription(ctx context.Context, s *ridmodels.Subscription)
 	if err := s.AdjustTimeRange(a.clock.Now(), nil); err != nil {
 		return nil, stacktrace.Propagate(err, "Unable to adjust time range")
 	}
+	if a.policy != nil {
+		if err := a.policy.CheckSubscription(ctx, s); err != nil {
+			return nil, stacktrace.Propagate(err, "Subscription rejected by write policy")
+		}
+	}
 	var sub *ridmodels.Subscription
 	err := a.Store.Transact(ctx, func(repo repos.Repository) error {
 
 		// ensure it doesn't exist yet
-		old, err := repo.GetSubscription(ctx, s.ID)
+		old, err := repo.GetSubscription(ctx, s.ID, false)
 		if err != nil {
 			return stacktrace.Propagate(err, "Error getting Subscription from repo")
 		}
@@ -91,6 +93,10 @@ func (a *app) InsertSubscription(ctx context.Context, s *ridmodels.Subscription)
 
 		return nil
 	})
+	if err == nil {
+		observeCells(ctx, "subscription", "insert", s.Cells)
+		a.publishSubscription(ctx, ridmodels.ActivityCreated, sub)
+	}
 	return sub, err
 }
 
@@ -99,7 +105,7 @@ func (a *app) UpdateSubscription(ctx context.Context, s *ridmodels.Subscription)
 	var sub *ridmodels.Subscription
 
 	err := a.Store.Transact(ctx, func(repo repos.Repository) error {
-		old, err := repo.GetSubscription(ctx, s.ID)
+		old, err := repo.GetSubscription(ctx, s.ID, true)
 		switch {
 		case err != nil:
 			return stacktrace.Propagate(err, "Error getting Subscription from repo")
@@ -120,6 +126,11 @@ func (a *app) UpdateSubscription(ctx context.Context, s *ridmodels.Subscription)
 		if err := s.AdjustTimeRange(a.clock.Now(), old); err != nil {
 			return stacktrace.Propagate(err, "Error adjusting time range")
 		}
+		if a.policy != nil {
+			if err := a.policy.CheckSubscription(ctx, s); err != nil {
+				return stacktrace.Propagate(err, "Subscription rejected by write policy")
+			}
+		}
 
 		// Check the user hasn't created too many subscriptions in this area.
 		count, err := repo.MaxSubscriptionCountInCellsByOwner(ctx, s.Cells, s.Owner)
@@ -139,6 +150,10 @@ func (a *app) UpdateSubscription(ctx context.Context, s *ridmodels.Subscription)
 		}
 		return nil
 	})
+	if err == nil {
+		observeCells(ctx, "subscription", "update", s.Cells)
+		a.publishSubscription(ctx, ridmodels.ActivityUpdated, sub)
+	}
 	return sub, err
 }
 
@@ -147,7 +162,7 @@ func (a *app) DeleteSubscription(ctx context.Context, id dssmodels.ID, owner dss
 	var ret *ridmodels.Subscription
 	err := a.Store.Transact(ctx, func(repo repos.Repository) error {
 		var err error
-		old, err := repo.GetSubscription(ctx, id)
+		old, err := repo.GetSubscription(ctx, id, true)
 		switch {
 		case err != nil:
 			return stacktrace.Propagate(err, "Error getting Subscription from repo")
@@ -169,5 +184,8 @@ func (a *app) DeleteSubscription(ctx context.Context, id dssmodels.ID, owner dss
 		}
 		return nil
 	})
+	if err == nil {
+		a.publishSubscription(ctx, ridmodels.ActivityDeleted, ret)
+	}
 	return ret, err
 }
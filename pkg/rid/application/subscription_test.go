@@ -2,11 +2,13 @@ package application
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/geo/s2"
 	"github.com/google/uuid"
+	"github.com/interuss/dss/pkg/datastore/testdb"
 	dsserr "github.com/interuss/dss/pkg/errors"
 	dssmodels "github.com/interuss/dss/pkg/models"
 	ridmodels "github.com/interuss/dss/pkg/rid/models"
@@ -62,7 +64,7 @@ type subscriptionStore struct {
 	subs map[dssmodels.ID]*ridmodels.Subscription
 }
 
-func (store *subscriptionStore) GetSubscription(ctx context.Context, id dssmodels.ID) (*ridmodels.Subscription, error) {
+func (store *subscriptionStore) GetSubscription(ctx context.Context, id dssmodels.ID, forUpdate bool) (*ridmodels.Subscription, error) {
 	if sub, ok := store.subs[id]; ok {
 		return sub, nil
 	}
@@ -109,14 +111,117 @@ func (store *subscriptionStore) SearchSubscriptionsByOwner(ctx context.Context,
 	return subs, nil
 }
 
-func (store *subscriptionStore) UpdateNotificationIdxsInCells(ctx context.Context, cells s2.CellUnion) ([]*ridmodels.Subscription, error) {
-	subs, _ := store.SearchSubscriptions(ctx, cells)
-	for i := range subs {
-		subs[i].NotificationIndex++
+func (store *subscriptionStore) UpdateNotificationIdxsInCells(ctx context.Context, cells s2.CellUnion, owner dssmodels.Owner, startTime, endTime *time.Time) ([]*ridmodels.Subscription, error) {
+	var subs []*ridmodels.Subscription
+
+	res, _ := store.SearchSubscriptions(ctx, cells)
+	for _, s := range res {
+		switch {
+		case s.Owner == owner:
+			continue
+		case startTime != nil && s.EndTime != nil && s.EndTime.Before(*startTime):
+			continue
+		case endTime != nil && s.StartTime != nil && s.StartTime.After(*endTime):
+			continue
+		}
+		s.NotificationIndex++
+		subs = append(subs, s)
 	}
 	return subs, nil
 }
 
+func (store *subscriptionStore) UpdateSubscriptionLabels(ctx context.Context, id dssmodels.ID, labels ridmodels.Labels) (*ridmodels.Subscription, error) {
+	sub, ok := store.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	sub.Labels = labels
+	returnedCopy := *sub
+	return &returnedCopy, nil
+}
+
+func (store *subscriptionStore) UpdateSubscriptionCallbacks(ctx context.Context, id dssmodels.ID, urls []string) (*ridmodels.Subscription, error) {
+	sub, ok := store.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	sub.CallbackURLs = urls
+	returnedCopy := *sub
+	return &returnedCopy, nil
+}
+
+func (store *subscriptionStore) UpdateSubscriptionNotificationInterval(ctx context.Context, id dssmodels.ID, interval time.Duration) (*ridmodels.Subscription, error) {
+	sub, ok := store.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	sub.MinNotificationInterval = interval
+	returnedCopy := *sub
+	return &returnedCopy, nil
+}
+
+func (store *subscriptionStore) RecordSubscriptionNotifications(ctx context.Context, ids []dssmodels.ID, at time.Time) error {
+	for _, id := range ids {
+		if sub, ok := store.subs[id]; ok {
+			sub.LastNotifiedAt = &at
+		}
+	}
+	return nil
+}
+
+func (store *subscriptionStore) UpdateSubscriptionSelfNotification(ctx context.Context, id dssmodels.ID, notify bool) (*ridmodels.Subscription, error) {
+	sub, ok := store.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	sub.NotifyOwnISAs = notify
+	returnedCopy := *sub
+	return &returnedCopy, nil
+}
+
+func (store *subscriptionStore) TransferSubscription(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner) (*ridmodels.Subscription, error) {
+	sub, ok := store.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	storedCopy := *sub
+	storedCopy.Owner = owner
+	storedCopy.Version = dssmodels.VersionFromTime(time.Now())
+	store.subs[id] = &storedCopy
+	returnedCopy := storedCopy
+	return &returnedCopy, nil
+}
+
+func (store *subscriptionStore) SearchSubscriptionsByLabels(ctx context.Context, labels ridmodels.Labels) ([]*ridmodels.Subscription, error) {
+	var subs []*ridmodels.Subscription
+	for _, s := range store.subs {
+		if hasLabels(s.Labels, labels) {
+			subs = append(subs, s)
+		}
+	}
+	return subs, nil
+}
+
+func (store *subscriptionStore) SearchSubscriptionsByURL(ctx context.Context, owner dssmodels.Owner, substring string) ([]*ridmodels.Subscription, error) {
+	var subs []*ridmodels.Subscription
+	for _, s := range store.subs {
+		if s.Owner == owner && strings.Contains(s.URL, substring) {
+			subs = append(subs, s)
+		}
+	}
+	return subs, nil
+}
+
+func (store *subscriptionStore) CountSubscriptionsByOwner(ctx context.Context, owner dssmodels.Owner) (int, error) {
+	count := 0
+	for _, s := range store.subs {
+		if s.Owner == owner {
+			count++
+		}
+	}
+	return count, nil
+}
+
 func (store *subscriptionStore) MaxSubscriptionCountInCellsByOwner(ctx context.Context, cells s2.CellUnion, owner dssmodels.Owner) (int, error) {
 	max := 0
 	subs, _ := store.SearchSubscriptionsByOwner(ctx, cells, owner)
@@ -451,3 +556,43 @@ func TestInsertTooManySubscription(t *testing.T) {
 	require.Equal(t, stacktrace.GetCode(err), dsserr.Exhausted)
 	require.Nil(t, ret)
 }
+
+func TestConcurrentUpdateSubscription(t *testing.T) {
+	if !testdb.Enabled() {
+		t.Skip("The in-memory store does not support concurrent access")
+	}
+	ctx := context.Background()
+	app, cleanup := setUpSubApp(ctx, t)
+	defer cleanup()
+
+	sub, err := app.InsertSubscription(ctx, &ridmodels.Subscription{
+		ID:        dssmodels.ID(uuid.New().String()),
+		Owner:     dssmodels.Owner(uuid.New().String()),
+		URL:       "https://no/place/like/home",
+		StartTime: &startTime,
+		EndTime:   &endTime,
+		Cells:     s2.CellUnion{12494535935418957824},
+	})
+	require.NoError(t, err)
+
+	const writers = 5
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		go func() {
+			update := *sub
+			_, err := app.UpdateSubscription(ctx, &update)
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < writers; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.Equal(t, dsserr.VersionMismatch, stacktrace.GetCode(err))
+	}
+	require.Equal(t, 1, succeeded)
+}
@@ -0,0 +1,107 @@
+package application
+
+import (
+	"context"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/dss/pkg/rid/repos"
+	"github.com/interuss/stacktrace"
+)
+
+// TransferResult describes the outcome of a transfer of ownership.
+type TransferResult struct {
+	// ISAs are the transferred ISAs, with their new versions.
+	ISAs []*ridmodels.IdentificationServiceArea
+	// Subscriptions are the transferred subscriptions, with their new
+	// versions.
+	Subscriptions []*ridmodels.Subscription
+	// Notified are the subscriptions whose notification index was
+	// incremented because of the transferred ISAs, with their final index.
+	Notified []*ridmodels.Subscription
+}
+
+// TransferApp provides the application logic to hand the entities of a USS
+// winding down over to another USS rather than abandoning them.
+type TransferApp interface {
+	// TransferOwnership makes "to" the owner of the ISAs and subscriptions of
+	// "from" identified by "isaIDs" and "subscriptionIDs", in a single
+	// transaction, and increments the notification indices of the
+	// subscribers to the transferred ISAs. It fails with code
+	// dsserr.NotFound if an entity does not exist and dsserr.BadRequest if
+	// it is not owned by "from".
+	TransferOwnership(ctx context.Context, from, to dssmodels.Owner, isaIDs, subscriptionIDs []dssmodels.ID) (*TransferResult, error)
+}
+
+func (a *app) TransferOwnership(ctx context.Context, from, to dssmodels.Owner, isaIDs, subscriptionIDs []dssmodels.ID) (*TransferResult, error) {
+	if from == to {
+		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Entities cannot be transferred to their owner")
+	}
+	var result *TransferResult
+	// The following will automatically retry TXN retry errors.
+	err := a.Store.Transact(ctx, func(repo repos.Repository) error {
+		result = &TransferResult{}
+		for _, id := range subscriptionIDs {
+			old, err := repo.GetSubscription(ctx, id, true)
+			switch {
+			case err != nil:
+				return stacktrace.Propagate(err, "Error getting Subscription %s", id)
+			case old == nil:
+				return stacktrace.NewErrorWithCode(dsserr.NotFound, "Subscription %s not found", id)
+			case old.Owner != from:
+				return stacktrace.NewErrorWithCode(dsserr.BadRequest, "Subscription %s is not owned by %s", id, from)
+			}
+			sub, err := repo.TransferSubscription(ctx, id, to)
+			if err != nil {
+				return stacktrace.Propagate(err, "Error transferring Subscription %s", id)
+			}
+			result.Subscriptions = append(result.Subscriptions, sub)
+		}
+
+		notified := map[dssmodels.ID]int{}
+		for _, id := range isaIDs {
+			old, err := repo.GetISA(ctx, id, true)
+			switch {
+			case err != nil:
+				return stacktrace.Propagate(err, "Error getting ISA %s", id)
+			case old == nil:
+				return stacktrace.NewErrorWithCode(dsserr.NotFound, "ISA %s not found", id)
+			case old.Owner != from:
+				return stacktrace.NewErrorWithCode(dsserr.BadRequest, "ISA %s is not owned by %s", id, from)
+			}
+			isa, err := repo.TransferISA(ctx, id, to)
+			if err != nil {
+				return stacktrace.Propagate(err, "Error transferring ISA %s", id)
+			}
+			result.ISAs = append(result.ISAs, isa)
+
+			// Subscribers need to learn the new owner of the ISA.
+			subs, err := repo.UpdateNotificationIdxsInCells(ctx, old.Cells, to, old.StartTime, old.EndTime)
+			if err != nil {
+				return stacktrace.Propagate(err, "Error updating notification indices")
+			}
+			for _, sub := range subs {
+				if i, ok := notified[sub.ID]; ok {
+					// Keep the index preceding the first increment.
+					sub.PreviousNotificationIndex = result.Notified[i].PreviousNotificationIndex
+					result.Notified[i] = sub
+					continue
+				}
+				notified[sub.ID] = len(result.Notified)
+				result.Notified = append(result.Notified, sub)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	for _, isa := range result.ISAs {
+		a.publishISA(ctx, ridmodels.ActivityUpdated, isa)
+	}
+	for _, sub := range result.Subscriptions {
+		a.publishSubscription(ctx, ridmodels.ActivityUpdated, sub)
+	}
+	return result, nil
+}
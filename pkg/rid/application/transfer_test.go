@@ -0,0 +1,85 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/uuid"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+var _ TransferApp = &app{}
+
+func TestTransferOwnership(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		app, cleanup = setUpISAApp(ctx, t)
+		from         = dssmodels.Owner(uuid.New().String())
+		to           = dssmodels.Owner(uuid.New().String())
+		cells        = s2.CellUnion{12494535935418957824}
+	)
+	defer cleanup()
+
+	insertISA := func(owner dssmodels.Owner) *ridmodels.IdentificationServiceArea {
+		isa, _, err := app.InsertISA(ctx, &ridmodels.IdentificationServiceArea{
+			ID:        dssmodels.ID(uuid.New().String()),
+			Owner:     owner,
+			URL:       "https://no/place/like/home/for/flights",
+			StartTime: &startTime,
+			EndTime:   &endTime,
+			Cells:     cells,
+		})
+		require.NoError(t, err)
+		return isa
+	}
+	insertSubscription := func(owner dssmodels.Owner) *ridmodels.Subscription {
+		sub, err := app.InsertSubscription(ctx, &ridmodels.Subscription{
+			ID:        dssmodels.ID(uuid.New().String()),
+			Owner:     owner,
+			URL:       "https://no/place/like/home/for/callbacks",
+			StartTime: &startTime,
+			EndTime:   &endTime,
+			Cells:     cells,
+		})
+		require.NoError(t, err)
+		return sub
+	}
+	isas := []*ridmodels.IdentificationServiceArea{insertISA(from), insertISA(from)}
+	kept := insertISA(from)
+	sub := insertSubscription(from)
+	subscriber := insertSubscription("subscriber")
+
+	result, err := app.TransferOwnership(ctx, from, to, []dssmodels.ID{isas[0].ID, isas[1].ID}, []dssmodels.ID{sub.ID})
+	require.NoError(t, err)
+	require.Len(t, result.ISAs, 2)
+	require.Len(t, result.Subscriptions, 1)
+	for _, isa := range result.ISAs {
+		require.Equal(t, to, isa.Owner)
+	}
+	require.Equal(t, to, result.Subscriptions[0].Owner)
+	// The subscriber is notified once per ISA, and reported once.
+	require.Len(t, result.Notified, 1)
+	require.Equal(t, subscriber.ID, result.Notified[0].ID)
+	require.Equal(t, subscriber.NotificationIndex+2, result.Notified[0].NotificationIndex)
+	require.Equal(t, subscriber.NotificationIndex, result.Notified[0].PreviousNotificationIndex)
+
+	isa, err := app.GetISA(ctx, isas[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, to, isa.Owner)
+	isa, err = app.GetISA(ctx, kept.ID)
+	require.NoError(t, err)
+	require.Equal(t, from, isa.Owner)
+
+	// Entities not owned by the former owner are not transferred.
+	_, err = app.TransferOwnership(ctx, from, to, []dssmodels.ID{isas[0].ID}, nil)
+	require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err))
+	_, err = app.TransferOwnership(ctx, from, to, nil, []dssmodels.ID{dssmodels.ID(uuid.New().String())})
+	require.Equal(t, dsserr.NotFound, stacktrace.GetCode(err))
+	_, err = app.TransferOwnership(ctx, from, from, []dssmodels.ID{kept.ID}, nil)
+	require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err))
+}
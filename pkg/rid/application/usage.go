@@ -0,0 +1,48 @@
+package application
+
+import (
+	"context"
+
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/stacktrace"
+)
+
+// Usage is what an owner currently uses of the remote ID DSS, and the quotas
+// bounding it.
+type Usage struct {
+	// ActiveISAs is the number of ISAs of the owner which have not ended.
+	ActiveISAs int
+	// ActiveSubscriptions is the number of subscriptions of the owner which
+	// have not ended.
+	ActiveSubscriptions int
+	// MaxSubscriptionsPerArea is the number of subscriptions the owner may
+	// have in any area.
+	MaxSubscriptionsPerArea int
+}
+
+// UsageApp provides the application logic for owners to find out what they
+// use of the DSS, e.g. to diagnose why their writes are rejected.
+type UsageApp interface {
+	// GetUsage returns the usage of "owner".
+	GetUsage(ctx context.Context, owner dssmodels.Owner) (*Usage, error)
+}
+
+func (a *app) GetUsage(ctx context.Context, owner dssmodels.Owner) (*Usage, error) {
+	repo, err := a.Store.Interact(ctx)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Unable to interact with store")
+	}
+	isas, err := repo.CountISAsByOwner(ctx, owner)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error counting ISAs of %s", owner)
+	}
+	subs, err := repo.CountSubscriptionsByOwner(ctx, owner)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error counting Subscriptions of %s", owner)
+	}
+	return &Usage{
+		ActiveISAs:              isas,
+		ActiveSubscriptions:     subs,
+		MaxSubscriptionsPerArea: maxSubscriptionsPerArea,
+	}, nil
+}
@@ -0,0 +1,53 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/uuid"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+)
+
+var _ UsageApp = &app{}
+
+func TestGetUsage(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		app, cleanup = setUpISAApp(ctx, t)
+		cells        = s2.CellUnion{12494535935418957824}
+	)
+	defer cleanup()
+
+	for _, owner := range []dssmodels.Owner{"uss1", "uss1", "uss2"} {
+		_, _, err := app.InsertISA(ctx, &ridmodels.IdentificationServiceArea{
+			ID:        dssmodels.ID(uuid.New().String()),
+			Owner:     owner,
+			URL:       "https://" + owner.String() + ".example/flights",
+			StartTime: &startTime,
+			EndTime:   &endTime,
+			Cells:     cells,
+		})
+		require.NoError(t, err)
+	}
+	_, err := app.InsertSubscription(ctx, &ridmodels.Subscription{
+		ID:        dssmodels.ID(uuid.New().String()),
+		Owner:     "uss1",
+		URL:       "https://uss1.example/notify",
+		StartTime: &startTime,
+		EndTime:   &endTime,
+		Cells:     cells,
+	})
+	require.NoError(t, err)
+
+	usage, err := app.GetUsage(ctx, "uss1")
+	require.NoError(t, err)
+	require.Equal(t, &Usage{ActiveISAs: 2, ActiveSubscriptions: 1, MaxSubscriptionsPerArea: maxSubscriptionsPerArea}, usage)
+
+	usage, err = app.GetUsage(ctx, "uss3")
+	require.NoError(t, err)
+	require.Zero(t, usage.ActiveISAs)
+	require.Zero(t, usage.ActiveSubscriptions)
+}
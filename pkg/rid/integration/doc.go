@@ -0,0 +1,13 @@
+// Package integration holds end-to-end tests of the remote ID API: they serve
+// the RID v2 router wired the way the core-service wires it, backed by a
+// CockroachDB test database and an access token signing key generated for the
+// test, and drive every endpoint through a real HTTP client. The pool tests run
+// several such instances against the same database and interleave their writes
+// to the same entities, as the instances of a DSS pool do.
+//
+// The tests are only built with the integration build tag:
+//
+//	DSS_TEST_AUTO_DB=1 go test -tags integration ./pkg/rid/integration
+//
+// The pool tests alone are run by make test-go-pool.
+package integration
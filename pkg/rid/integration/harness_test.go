@@ -0,0 +1,212 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/interuss/dss/pkg/api"
+	apiridv2 "github.com/interuss/dss/pkg/api/ridv2"
+	"github.com/interuss/dss/pkg/auth"
+	"github.com/interuss/dss/pkg/datastore"
+	"github.com/interuss/dss/pkg/datastore/testdb"
+	"github.com/interuss/dss/pkg/rid/application"
+	rid_v2 "github.com/interuss/dss/pkg/rid/server/v2"
+	ridc "github.com/interuss/dss/pkg/rid/store/cockroach"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	audience = "dss.integration.test"
+	issuer   = "https://auth.integration.test"
+	baseURL  = "https://uss.integration.test"
+)
+
+func TestMain(m *testing.M) {
+	testdb.Main(m)
+}
+
+// staticKeyResolver resolves a fixed set of keys.
+type staticKeyResolver []interface{}
+
+func (r staticKeyResolver) ResolveKeys(context.Context) ([]interface{}, error) {
+	return r, nil
+}
+
+// harness is a DSS serving the remote ID API over HTTP for the duration of a
+// test.
+type harness struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+}
+
+func newHarness(t *testing.T) *harness {
+	return newPool(t, 1)[0]
+}
+
+// newPool returns size DSS instances sharing the test database and access
+// token signing key, like the instances of a DSS pool. Each instance has its
+// own store, hence its own database connections.
+func newPool(t *testing.T, size int) []*harness {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	logger := zap.NewNop()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	pool := make([]*harness, size)
+	for i := range pool {
+		db, err := datastore.Dial(ctx, testdb.ConnectParameters(t, "rid"))
+		require.NoError(t, err)
+		store, err := ridc.NewStore(ctx, db, "rid", logger)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, store.CleanUp(context.Background()))
+			require.NoError(t, store.Close())
+		})
+		require.NoError(t, store.CleanUp(ctx))
+
+		authorizer, err := auth.NewRSAAuthorizer(ctx, auth.Configuration{
+			KeyResolver:       staticKeyResolver{&key.PublicKey},
+			KeyRefreshTimeout: time.Hour,
+			AcceptedAudiences: []string{audience},
+		})
+		require.NoError(t, err)
+
+		router := apiridv2.MakeAPIRouter(&rid_v2.Server{
+			App:      application.NewFromTransactor(store, logger),
+			Timeout:  10 * time.Second,
+			Locality: fmt.Sprintf("integration-%d", i),
+		}, authorizer)
+		server := httptest.NewServer(&api.MultiRouter{Routers: []api.PartialRouter{&router}})
+		t.Cleanup(server.Close)
+
+		pool[i] = &harness{t: t, server: server, key: key}
+	}
+	return pool
+}
+
+// token returns an access token for owner signed with key.
+func token(t *testing.T, key *rsa.PrivateKey, owner, aud string, scopes ...api.RequiredScope) string {
+	scopeStrings := make([]string, len(scopes))
+	for i, s := range scopes {
+		scopeStrings[i] = string(s)
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub":   owner,
+		"iss":   issuer,
+		"aud":   aud,
+		"scope": strings.Join(scopeStrings, " "),
+		"exp":   time.Now().Add(30 * time.Minute).Unix(),
+	}).SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+// serviceProvider returns a valid service provider token for owner.
+func (h *harness) serviceProvider(owner string) string {
+	return token(h.t, h.key, owner, audience, apiridv2.RidServiceProviderScope)
+}
+
+// displayProvider returns a valid display provider token for owner.
+func (h *harness) displayProvider(owner string) string {
+	return token(h.t, h.key, owner, audience, apiridv2.RidDisplayProviderScope)
+}
+
+// do sends a request with body encoded as JSON to path, decodes the response
+// into out if it succeeded and returns its status code.
+func (h *harness) do(method, path, accessToken string, body, out interface{}) int {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(h.t, err)
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, h.server.URL+path, reader)
+	require.NoError(h.t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := h.server.Client().Do(req)
+	require.NoError(h.t, err)
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK && out != nil {
+		require.NoError(h.t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp.StatusCode
+}
+
+// square is a polygon of about 1km x 1km with its south west corner at
+// (lat, lng).
+type square struct {
+	lat, lng float64
+}
+
+func (s square) polygon() *apiridv2.Polygon {
+	const side = 0.01
+	return &apiridv2.Polygon{Vertices: []apiridv2.LatLngPoint{
+		{Lat: apiridv2.Latitude(s.lat), Lng: apiridv2.Longitude(s.lng)},
+		{Lat: apiridv2.Latitude(s.lat), Lng: apiridv2.Longitude(s.lng + side)},
+		{Lat: apiridv2.Latitude(s.lat + side), Lng: apiridv2.Longitude(s.lng + side)},
+		{Lat: apiridv2.Latitude(s.lat + side), Lng: apiridv2.Longitude(s.lng)},
+	}}
+}
+
+// area returns the square in the format of search area query parameters.
+func (s square) area() string {
+	var points []string
+	for _, v := range s.polygon().Vertices {
+		points = append(points, fmt.Sprintf("%f,%f", v.Lat, v.Lng))
+	}
+	return strings.Join(points, ",")
+}
+
+// extents returns the volume of s from now until d from now.
+func (s square) extents(d time.Duration) apiridv2.Volume4D {
+	return apiridv2.Volume4D{
+		Volume: apiridv2.Volume3D{
+			OutlinePolygon: s.polygon(),
+			AltitudeLower:  &apiridv2.Altitude{Value: 0, Reference: "W84", Units: "M"},
+			AltitudeUpper:  &apiridv2.Altitude{Value: 120, Reference: "W84", Units: "M"},
+		},
+		TimeEnd: &apiridv2.Time{Value: time.Now().Add(d).UTC().Format(time.RFC3339Nano), Format: "RFC3339"},
+	}
+}
+
+// notifiedIndex returns the notification index reported for the subscription
+// subID in subscribers, if any.
+func notifiedIndex(subscribers *[]apiridv2.SubscriberToNotify, subID string) (apiridv2.SubscriptionNotificationIndex, bool) {
+	if subscribers == nil {
+		return 0, false
+	}
+	for _, s := range *subscribers {
+		for _, state := range s.Subscriptions {
+			if state.SubscriptionId == apiridv2.SubscriptionUUID(subID) {
+				return *state.NotificationIndex, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func newID() string {
+	return uuid.New().String()
+}
@@ -0,0 +1,247 @@
+//go:build integration
+
+package integration
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	apiridv2 "github.com/interuss/dss/pkg/api/ridv2"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests of this file run several DSS instances against the same database
+// and interleave their writes to the same entities, as the instances of a DSS
+// pool do.
+
+const poolSize = 2
+
+// extentsUntil returns the volume of area ending at end.
+func extentsUntil(end time.Time) apiridv2.Volume4D {
+	extents := area.extents(0)
+	extents.TimeEnd = &apiridv2.Time{Value: end.UTC().Format(time.RFC3339Nano), Format: "RFC3339"}
+	return extents
+}
+
+func parseTime(t *testing.T, v apiridv2.Time) time.Time {
+	parsed, err := time.Parse(time.RFC3339Nano, v.Value)
+	require.NoError(t, err)
+	return parsed
+}
+
+func TestPoolConcurrentISAUpdates(t *testing.T) {
+	var (
+		pool  = newPool(t, poolSize)
+		id    = newID()
+		owner = pool[0].serviceProvider("uss1")
+		put   apiridv2.PutIdentificationServiceAreaResponse
+	)
+	require.Equal(t, http.StatusOK, pool[0].do(http.MethodPut, isasPath+"/"+id, owner,
+		apiridv2.CreateIdentificationServiceAreaParameters{Extents: area.extents(time.Hour), UssBaseUrl: baseURL}, &put))
+	version := put.ServiceArea.Version
+
+	for round := 0; round < 10; round++ {
+		var (
+			wg        sync.WaitGroup
+			statuses  = make([]int, len(pool))
+			responses = make([]apiridv2.PutIdentificationServiceAreaResponse, len(pool))
+		)
+		for i, h := range pool {
+			wg.Add(1)
+			go func(i int, h *harness) {
+				defer wg.Done()
+				update := apiridv2.UpdateIdentificationServiceAreaParameters{
+					Extents: area.extents(time.Duration(round+2) * time.Hour), UssBaseUrl: baseURL}
+				statuses[i] = h.do(http.MethodPut, isasPath+"/"+id+"/"+string(version), owner, update, &responses[i])
+			}(i, h)
+		}
+		wg.Wait()
+
+		// Exactly one instance updates the ISA at the version both read, the
+		// others see the version moved on.
+		winners := 0
+		for i, status := range statuses {
+			if status == http.StatusOK {
+				winners++
+				require.NotEqual(t, version, responses[i].ServiceArea.Version)
+				version = responses[i].ServiceArea.Version
+			} else {
+				require.Equal(t, http.StatusConflict, status)
+			}
+		}
+		require.Equal(t, 1, winners, "round %d", round)
+
+		for _, h := range pool {
+			var get apiridv2.GetIdentificationServiceAreaResponse
+			require.Equal(t, http.StatusOK, h.do(http.MethodGet, isasPath+"/"+id, owner, nil, &get))
+			require.Equal(t, version, get.ServiceArea.Version)
+		}
+	}
+}
+
+func TestPoolConcurrentISAUpdateAndDelete(t *testing.T) {
+	var (
+		pool  = newPool(t, poolSize)
+		owner = pool[0].serviceProvider("uss1")
+	)
+	for round := 0; round < 10; round++ {
+		var (
+			id  = newID()
+			put apiridv2.PutIdentificationServiceAreaResponse
+			wg  sync.WaitGroup
+		)
+		require.Equal(t, http.StatusOK, pool[0].do(http.MethodPut, isasPath+"/"+id, owner,
+			apiridv2.CreateIdentificationServiceAreaParameters{Extents: area.extents(time.Hour), UssBaseUrl: baseURL}, &put))
+		path := isasPath + "/" + id + "/" + string(put.ServiceArea.Version)
+
+		var updateStatus, deleteStatus int
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			updateStatus = pool[0].do(http.MethodPut, path, owner,
+				apiridv2.UpdateIdentificationServiceAreaParameters{Extents: area.extents(2 * time.Hour), UssBaseUrl: baseURL}, nil)
+		}()
+		go func() {
+			defer wg.Done()
+			deleteStatus = pool[1].do(http.MethodDelete, path, owner, nil, nil)
+		}()
+		wg.Wait()
+
+		// Either the update or the deletion applies to the version both
+		// targeted, never both.
+		getStatus := pool[1].do(http.MethodGet, isasPath+"/"+id, owner, nil, nil)
+		if updateStatus == http.StatusOK {
+			require.Equal(t, http.StatusConflict, deleteStatus, "round %d", round)
+			require.Equal(t, http.StatusOK, getStatus)
+		} else {
+			require.Contains(t, []int{http.StatusConflict, http.StatusNotFound}, updateStatus, "round %d", round)
+			require.Equal(t, http.StatusOK, deleteStatus, "round %d", round)
+			require.Equal(t, http.StatusNotFound, getStatus)
+		}
+	}
+}
+
+func TestPoolNoLostUpdates(t *testing.T) {
+	const (
+		workersPerInstance = 3
+		updatesPerWorker   = 3
+	)
+	var (
+		pool  = newPool(t, poolSize)
+		id    = newID()
+		owner = pool[0].serviceProvider("uss1")
+		put   apiridv2.PutIdentificationServiceAreaResponse
+	)
+	require.Equal(t, http.StatusOK, pool[0].do(http.MethodPut, isasPath+"/"+id, owner,
+		apiridv2.CreateIdentificationServiceAreaParameters{Extents: area.extents(time.Hour), UssBaseUrl: baseURL}, &put))
+	initialEnd := parseTime(t, put.ServiceArea.TimeEnd)
+
+	// Every worker extends the ISA by a minute updatesPerWorker times, reading
+	// the ISA again whenever another worker updated it in the meantime.
+	var wg sync.WaitGroup
+	for _, h := range pool {
+		for w := 0; w < workersPerInstance; w++ {
+			wg.Add(1)
+			go func(h *harness) {
+				defer wg.Done()
+				for updated := 0; updated < updatesPerWorker; {
+					var get apiridv2.GetIdentificationServiceAreaResponse
+					if h.do(http.MethodGet, isasPath+"/"+id, owner, nil, &get) != http.StatusOK {
+						t.Errorf("Failed to get ISA %s", id)
+						return
+					}
+					update := apiridv2.UpdateIdentificationServiceAreaParameters{
+						Extents: extentsUntil(parseTime(t, get.ServiceArea.TimeEnd).Add(time.Minute)), UssBaseUrl: baseURL}
+					switch status := h.do(http.MethodPut, isasPath+"/"+id+"/"+string(get.ServiceArea.Version), owner, update, nil); status {
+					case http.StatusOK:
+						updated++
+					case http.StatusConflict:
+					default:
+						t.Errorf("Unexpected status %d updating ISA %s", status, id)
+						return
+					}
+				}
+			}(h)
+		}
+	}
+	wg.Wait()
+
+	var get apiridv2.GetIdentificationServiceAreaResponse
+	require.Equal(t, http.StatusOK, pool[1].do(http.MethodGet, isasPath+"/"+id, owner, nil, &get))
+	extension := time.Duration(len(pool)*workersPerInstance*updatesPerWorker) * time.Minute
+	require.Equal(t, initialEnd.Add(extension), parseTime(t, get.ServiceArea.TimeEnd))
+}
+
+func TestPoolNotificationIndices(t *testing.T) {
+	const isasPerInstance = 5
+	var (
+		pool   = newPool(t, poolSize)
+		subID  = newID()
+		dp     = pool[0].displayProvider("dp1")
+		sp     = pool[0].serviceProvider("uss1")
+		subPut apiridv2.PutSubscriptionResponse
+	)
+	require.Equal(t, http.StatusOK, pool[0].do(http.MethodPut, subscriptionsPath+"/"+subID, dp,
+		apiridv2.CreateSubscriptionParameters{Extents: area.extents(time.Hour), UssBaseUrl: baseURL}, &subPut))
+	require.Equal(t, apiridv2.SubscriptionNotificationIndex(0), *subPut.Subscription.NotificationIndex)
+
+	// Every instance creates then deletes ISAs in the subscription area,
+	// reporting the notification index of every mutation.
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		indices []apiridv2.SubscriptionNotificationIndex
+	)
+	report := func(subscribers *[]apiridv2.SubscriberToNotify) {
+		index, ok := notifiedIndex(subscribers, subID)
+		if !ok {
+			t.Errorf("Subscription %s not notified", subID)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		indices = append(indices, index)
+	}
+	for _, h := range pool {
+		wg.Add(1)
+		go func(h *harness) {
+			defer wg.Done()
+			for i := 0; i < isasPerInstance; i++ {
+				var (
+					id  = newID()
+					put apiridv2.PutIdentificationServiceAreaResponse
+					del apiridv2.DeleteIdentificationServiceAreaResponse
+				)
+				if status := h.do(http.MethodPut, isasPath+"/"+id, sp,
+					apiridv2.CreateIdentificationServiceAreaParameters{Extents: area.extents(time.Hour), UssBaseUrl: baseURL}, &put); status != http.StatusOK {
+					t.Errorf("Unexpected status %d creating ISA %s", status, id)
+					return
+				}
+				report(put.Subscribers)
+				if status := h.do(http.MethodDelete, isasPath+"/"+id+"/"+string(put.ServiceArea.Version), sp, nil, &del); status != http.StatusOK {
+					t.Errorf("Unexpected status %d deleting ISA %s", status, id)
+					return
+				}
+				report(del.Subscribers)
+			}
+		}(h)
+	}
+	wg.Wait()
+
+	// Every mutation incremented the notification index once: the indices
+	// reported are all distinct and the subscription ends up at the last one.
+	mutations := 2 * isasPerInstance * len(pool)
+	var expected []apiridv2.SubscriptionNotificationIndex
+	for i := 1; i <= mutations; i++ {
+		expected = append(expected, apiridv2.SubscriptionNotificationIndex(i))
+	}
+	require.ElementsMatch(t, expected, indices)
+
+	for _, h := range pool {
+		var get apiridv2.GetSubscriptionResponse
+		require.Equal(t, http.StatusOK, h.do(http.MethodGet, subscriptionsPath+"/"+subID, dp, nil, &get))
+		require.Equal(t, apiridv2.SubscriptionNotificationIndex(mutations), *get.Subscription.NotificationIndex)
+	}
+}
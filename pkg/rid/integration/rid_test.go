@@ -0,0 +1,209 @@
+//go:build integration
+
+package integration
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"testing"
+	"time"
+
+	apiridv2 "github.com/interuss/dss/pkg/api/ridv2"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	isasPath          = "/rid/v2/dss/identification_service_areas"
+	subscriptionsPath = "/rid/v2/dss/subscriptions"
+)
+
+var (
+	area       = square{lat: 37.42, lng: -122.08}
+	remoteArea = square{lat: 46.52, lng: 6.63}
+
+	// staleVersion is a well-formed version no entity is at.
+	staleVersion = dssmodels.VersionFromTime(time.Unix(0, 1)).String()
+)
+
+func TestAuthorization(t *testing.T) {
+	h := newHarness(t)
+	params := apiridv2.CreateIdentificationServiceAreaParameters{Extents: area.extents(time.Hour), UssBaseUrl: baseURL}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"missing token", "", http.StatusUnauthorized},
+		{"malformed token", "not-a-token", http.StatusUnauthorized},
+		{"unknown signing key", token(t, otherKey, "uss1", audience, apiridv2.RidServiceProviderScope), http.StatusUnauthorized},
+		{"wrong audience", token(t, h.key, "uss1", "other.audience", apiridv2.RidServiceProviderScope), http.StatusUnauthorized},
+		{"missing scope", token(t, h.key, "uss1", audience), http.StatusForbidden},
+		{"wrong scope", h.displayProvider("uss1"), http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.status, h.do(http.MethodPut, isasPath+"/"+newID(), tc.token, params, nil))
+		})
+	}
+
+	require.Equal(t, http.StatusForbidden, h.do(http.MethodGet, isasPath+"?area="+area.area(), h.serviceProvider("uss1"), nil, nil))
+	require.Equal(t, http.StatusUnauthorized, h.do(http.MethodGet, subscriptionsPath+"?area="+area.area(), token(t, h.key, "", audience, apiridv2.RidDisplayProviderScope), nil, nil))
+}
+
+func TestISALifecycle(t *testing.T) {
+	var (
+		h     = newHarness(t)
+		id    = newID()
+		owner = h.serviceProvider("uss1")
+		other = h.serviceProvider("uss2")
+		put   apiridv2.PutIdentificationServiceAreaResponse
+	)
+
+	create := apiridv2.CreateIdentificationServiceAreaParameters{Extents: area.extents(time.Hour), UssBaseUrl: baseURL}
+	require.Equal(t, http.StatusOK, h.do(http.MethodPut, isasPath+"/"+id, owner, create, &put))
+	require.Equal(t, apiridv2.EntityUUID(id), put.ServiceArea.Id)
+	require.Equal(t, "uss1", put.ServiceArea.Owner)
+	created := put.ServiceArea
+
+	require.Equal(t, http.StatusConflict, h.do(http.MethodPut, isasPath+"/"+id, owner, create, nil))
+
+	var get apiridv2.GetIdentificationServiceAreaResponse
+	require.Equal(t, http.StatusOK, h.do(http.MethodGet, isasPath+"/"+id, owner, nil, &get))
+	require.Equal(t, created, get.ServiceArea)
+
+	var search apiridv2.SearchIdentificationServiceAreasResponse
+	require.Equal(t, http.StatusOK, h.do(http.MethodGet, isasPath+"?area="+area.area(), h.displayProvider("dp1"), nil, &search))
+	require.NotNil(t, search.ServiceAreas)
+	require.Len(t, *search.ServiceAreas, 1)
+	require.Equal(t, created.Id, (*search.ServiceAreas)[0].Id)
+
+	search = apiridv2.SearchIdentificationServiceAreasResponse{}
+	require.Equal(t, http.StatusOK, h.do(http.MethodGet, isasPath+"?area="+remoteArea.area(), h.displayProvider("dp1"), nil, &search))
+	require.True(t, search.ServiceAreas == nil || len(*search.ServiceAreas) == 0)
+
+	update := apiridv2.UpdateIdentificationServiceAreaParameters{Extents: area.extents(2 * time.Hour), UssBaseUrl: baseURL}
+	require.Equal(t, http.StatusConflict, h.do(http.MethodPut, isasPath+"/"+id+"/"+staleVersion, owner, update, nil))
+	require.Equal(t, http.StatusForbidden, h.do(http.MethodPut, isasPath+"/"+id+"/"+string(created.Version), other, update, nil))
+
+	put = apiridv2.PutIdentificationServiceAreaResponse{}
+	require.Equal(t, http.StatusOK, h.do(http.MethodPut, isasPath+"/"+id+"/"+string(created.Version), owner, update, &put))
+	require.NotEqual(t, created.Version, put.ServiceArea.Version)
+	updated := put.ServiceArea
+
+	// The version of the ISA before the update is now stale.
+	require.Equal(t, http.StatusConflict, h.do(http.MethodPut, isasPath+"/"+id+"/"+string(created.Version), owner, update, nil))
+	require.Equal(t, http.StatusConflict, h.do(http.MethodDelete, isasPath+"/"+id+"/"+string(created.Version), owner, nil, nil))
+	require.Equal(t, http.StatusForbidden, h.do(http.MethodDelete, isasPath+"/"+id+"/"+string(updated.Version), other, nil, nil))
+
+	var del apiridv2.DeleteIdentificationServiceAreaResponse
+	require.Equal(t, http.StatusOK, h.do(http.MethodDelete, isasPath+"/"+id+"/"+string(updated.Version), owner, nil, &del))
+	require.Equal(t, updated.Id, del.ServiceArea.Id)
+
+	require.Equal(t, http.StatusNotFound, h.do(http.MethodGet, isasPath+"/"+id, owner, nil, nil))
+	require.Equal(t, http.StatusNotFound, h.do(http.MethodDelete, isasPath+"/"+id+"/"+string(updated.Version), owner, nil, nil))
+}
+
+func TestSubscriptionLifecycle(t *testing.T) {
+	var (
+		h     = newHarness(t)
+		id    = newID()
+		owner = h.displayProvider("dp1")
+		other = h.displayProvider("dp2")
+		put   apiridv2.PutSubscriptionResponse
+	)
+
+	create := apiridv2.CreateSubscriptionParameters{Extents: area.extents(time.Hour), UssBaseUrl: baseURL}
+	require.Equal(t, http.StatusOK, h.do(http.MethodPut, subscriptionsPath+"/"+id, owner, create, &put))
+	require.Equal(t, apiridv2.SubscriptionUUID(id), put.Subscription.Id)
+	require.Equal(t, "dp1", put.Subscription.Owner)
+	created := put.Subscription
+
+	require.Equal(t, http.StatusConflict, h.do(http.MethodPut, subscriptionsPath+"/"+id, owner, create, nil))
+
+	var get apiridv2.GetSubscriptionResponse
+	require.Equal(t, http.StatusOK, h.do(http.MethodGet, subscriptionsPath+"/"+id, owner, nil, &get))
+	require.Equal(t, created, get.Subscription)
+
+	// Subscriptions are only visible to their owner in searches.
+	var search apiridv2.SearchSubscriptionsResponse
+	require.Equal(t, http.StatusOK, h.do(http.MethodGet, subscriptionsPath+"?area="+area.area(), owner, nil, &search))
+	require.NotNil(t, search.Subscriptions)
+	require.Len(t, *search.Subscriptions, 1)
+	search = apiridv2.SearchSubscriptionsResponse{}
+	require.Equal(t, http.StatusOK, h.do(http.MethodGet, subscriptionsPath+"?area="+area.area(), other, nil, &search))
+	require.True(t, search.Subscriptions == nil || len(*search.Subscriptions) == 0)
+
+	update := apiridv2.UpdateSubscriptionParameters{Extents: area.extents(2 * time.Hour), UssBaseUrl: baseURL}
+	require.Equal(t, http.StatusConflict, h.do(http.MethodPut, subscriptionsPath+"/"+id+"/"+staleVersion, owner, update, nil))
+	require.Equal(t, http.StatusForbidden, h.do(http.MethodPut, subscriptionsPath+"/"+id+"/"+string(created.Version), other, update, nil))
+
+	put = apiridv2.PutSubscriptionResponse{}
+	require.Equal(t, http.StatusOK, h.do(http.MethodPut, subscriptionsPath+"/"+id+"/"+string(created.Version), owner, update, &put))
+	require.NotEqual(t, created.Version, put.Subscription.Version)
+	updated := put.Subscription
+
+	require.Equal(t, http.StatusConflict, h.do(http.MethodDelete, subscriptionsPath+"/"+id+"/"+string(created.Version), owner, nil, nil))
+	require.Equal(t, http.StatusForbidden, h.do(http.MethodDelete, subscriptionsPath+"/"+id+"/"+string(updated.Version), other, nil, nil))
+
+	var del apiridv2.DeleteSubscriptionResponse
+	require.Equal(t, http.StatusOK, h.do(http.MethodDelete, subscriptionsPath+"/"+id+"/"+string(updated.Version), owner, nil, &del))
+	require.Equal(t, updated.Id, del.Subscription.Id)
+
+	require.Equal(t, http.StatusNotFound, h.do(http.MethodGet, subscriptionsPath+"/"+id, owner, nil, nil))
+}
+
+func TestNotificationIndex(t *testing.T) {
+	var (
+		h      = newHarness(t)
+		subID  = newID()
+		isaID  = newID()
+		dp     = h.displayProvider("dp1")
+		sp     = h.serviceProvider("uss1")
+		subPut apiridv2.PutSubscriptionResponse
+		isaPut apiridv2.PutIdentificationServiceAreaResponse
+	)
+
+	require.Equal(t, http.StatusOK, h.do(http.MethodPut, subscriptionsPath+"/"+subID, dp,
+		apiridv2.CreateSubscriptionParameters{Extents: area.extents(time.Hour), UssBaseUrl: baseURL}, &subPut))
+	require.Equal(t, apiridv2.SubscriptionNotificationIndex(0), *subPut.Subscription.NotificationIndex)
+
+	// ISAs outside of the subscription area do not notify it.
+	require.Equal(t, http.StatusOK, h.do(http.MethodPut, isasPath+"/"+newID(), sp,
+		apiridv2.CreateIdentificationServiceAreaParameters{Extents: remoteArea.extents(time.Hour), UssBaseUrl: baseURL}, &isaPut))
+	_, ok := notifiedIndex(isaPut.Subscribers, subID)
+	require.False(t, ok)
+
+	isaPut = apiridv2.PutIdentificationServiceAreaResponse{}
+	require.Equal(t, http.StatusOK, h.do(http.MethodPut, isasPath+"/"+isaID, sp,
+		apiridv2.CreateIdentificationServiceAreaParameters{Extents: area.extents(time.Hour), UssBaseUrl: baseURL}, &isaPut))
+	index, ok := notifiedIndex(isaPut.Subscribers, subID)
+	require.True(t, ok)
+	require.Equal(t, apiridv2.SubscriptionNotificationIndex(1), index)
+
+	version := isaPut.ServiceArea.Version
+	isaPut = apiridv2.PutIdentificationServiceAreaResponse{}
+	require.Equal(t, http.StatusOK, h.do(http.MethodPut, isasPath+"/"+isaID+"/"+string(version), sp,
+		apiridv2.UpdateIdentificationServiceAreaParameters{Extents: area.extents(2 * time.Hour), UssBaseUrl: baseURL}, &isaPut))
+	index, ok = notifiedIndex(isaPut.Subscribers, subID)
+	require.True(t, ok)
+	require.Equal(t, apiridv2.SubscriptionNotificationIndex(2), index)
+
+	// A failed update does not notify the subscription.
+	require.Equal(t, http.StatusConflict, h.do(http.MethodPut, isasPath+"/"+isaID+"/"+string(version), sp,
+		apiridv2.UpdateIdentificationServiceAreaParameters{Extents: area.extents(2 * time.Hour), UssBaseUrl: baseURL}, nil))
+
+	var isaDel apiridv2.DeleteIdentificationServiceAreaResponse
+	require.Equal(t, http.StatusOK, h.do(http.MethodDelete, isasPath+"/"+isaID+"/"+string(isaPut.ServiceArea.Version), sp, nil, &isaDel))
+	index, ok = notifiedIndex(isaDel.Subscribers, subID)
+	require.True(t, ok)
+	require.Equal(t, apiridv2.SubscriptionNotificationIndex(3), index)
+
+	var get apiridv2.GetSubscriptionResponse
+	require.Equal(t, http.StatusOK, h.do(http.MethodGet, subscriptionsPath+"/"+subID, dp, nil, &get))
+	require.Equal(t, apiridv2.SubscriptionNotificationIndex(3), *get.Subscription.NotificationIndex)
+}
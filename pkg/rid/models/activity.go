@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	dssmodels "github.com/interuss/dss/pkg/models"
+)
+
+// ActivityEntity is the type of entity an ActivityEvent is about.
+type ActivityEntity string
+
+// ActivityKind is what happened to the entity of an ActivityEvent.
+type ActivityKind string
+
+const (
+	// ActivityISA is the ActivityEntity of identification service areas.
+	ActivityISA = ActivityEntity("isa")
+	// ActivitySubscription is the ActivityEntity of subscriptions.
+	ActivitySubscription = ActivityEntity("subscription")
+
+	// ActivityCreated records the creation of an entity.
+	ActivityCreated = ActivityKind("created")
+	// ActivityUpdated records the update of an entity.
+	ActivityUpdated = ActivityKind("updated")
+	// ActivityDeleted records the deletion of an entity, including the
+	// deletion of expired entities.
+	ActivityDeleted = ActivityKind("deleted")
+)
+
+// ActivityEvent records a change of a remote ID entity, from which the
+// activity of the pool over time is derived.
+type ActivityEvent struct {
+	Entity     ActivityEntity
+	EntityID   dssmodels.ID
+	Kind       ActivityKind
+	OccurredAt time.Time
+	// StartTime and EndTime are the time bounds of the entity after the
+	// change or, for deletions, when it was deleted.
+	StartTime *time.Time
+	EndTime   *time.Time
+}
@@ -163,8 +163,9 @@ func ToIdentificationServiceArea(i *ridmodels.IdentificationServiceArea) *restap
 	return result
 }
 
-// MakeSubscribersToNotify groups the passed subscriptions by their callback URL,
+// MakeSubscribersToNotify groups the passed subscriptions by their callback URLs,
 // returning a collection of subscribers to notify that contains one entry per distinct callback URL.
+// Subscriptions with several callback URLs are listed in the entry of each.
 func MakeSubscribersToNotify(subscriptions []*ridmodels.Subscription) []restapi.SubscriberToNotify {
 	subscriptionsByURL := map[string][]restapi.SubscriptionState{}
 	for _, sub := range subscriptions {
@@ -174,7 +175,9 @@ func MakeSubscribersToNotify(subscriptions []*ridmodels.Subscription) []restapi.
 			SubscriptionId:    &subID,
 			NotificationIndex: &notifIdx,
 		}
-		subscriptionsByURL[sub.URL] = append(subscriptionsByURL[sub.URL], subState)
+		for _, url := range sub.URLs() {
+			subscriptionsByURL[url] = append(subscriptionsByURL[url], subState)
+		}
 	}
 
 	result := []restapi.SubscriberToNotify{}
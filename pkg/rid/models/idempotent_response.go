@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	dssmodels "github.com/interuss/dss/pkg/models"
+)
+
+// IdempotentResponse is the response to a write sent by Owner with an
+// idempotency key, replayed to the retries of the write until ExpiresAt.
+type IdempotentResponse struct {
+	Owner dssmodels.Owner
+	Key   string
+	// RequestHash identifies the request of the response, so that a key
+	// reused for another request is not answered with it.
+	RequestHash []byte
+	Status      int
+	Body        []byte
+	ExpiresAt   time.Time
+}
@@ -21,7 +21,10 @@ type IdentificationServiceArea struct {
 	Version    *dssmodels.Version
 	AltitudeHi *float32
 	AltitudeLo *float32
-	Writer     string
+	// Footprint is the area requested for the ISA, which Cells covers.
+	Footprint dssmodels.Geometry
+	Writer    string
+	Labels    Labels
 }
 
 // SetCells is a convenience function that accepts an int64 array and converts
@@ -44,6 +47,7 @@ func (i *IdentificationServiceArea) SetExtents(extents *dssmodels.Volume4D) erro
 	i.EndTime = extents.EndTime
 	i.AltitudeHi = extents.SpatialVolume.AltitudeHi
 	i.AltitudeLo = extents.SpatialVolume.AltitudeLo
+	i.Footprint = extents.SpatialVolume.Footprint
 	i.Cells, err = extents.SpatialVolume.Footprint.CalculateCovering()
 	if err != nil {
 		return stacktrace.Propagate(err, "Error calculating covering for ISA")
@@ -51,6 +55,20 @@ func (i *IdentificationServiceArea) SetExtents(extents *dssmodels.Volume4D) erro
 	return nil
 }
 
+// Extents returns the 4D volume of the IdentificationServiceArea, whose
+// footprint and altitudes are missing if they were not stored.
+func (i *IdentificationServiceArea) Extents() *dssmodels.Volume4D {
+	return &dssmodels.Volume4D{
+		SpatialVolume: &dssmodels.Volume3D{
+			AltitudeHi: i.AltitudeHi,
+			AltitudeLo: i.AltitudeLo,
+			Footprint:  i.Footprint,
+		},
+		StartTime: i.StartTime,
+		EndTime:   i.EndTime,
+	}
+}
+
 // AdjustTimeRange adjusts the time range to the max allowed ranges on a
 // IdentificationServiceArea.
 func (i *IdentificationServiceArea) AdjustTimeRange(now time.Time, old *IdentificationServiceArea) error {
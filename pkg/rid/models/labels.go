@@ -0,0 +1,57 @@
+package models
+
+import (
+	"strings"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+)
+
+const (
+	// maxLabels is the largest number of labels on a single entity.
+	maxLabels = 16
+	// maxLabelKeyLength is the longest allowed label key.
+	maxLabelKeyLength = 63
+	// maxLabelValueLength is the longest allowed label value.
+	maxLabelValueLength = 255
+)
+
+// Labels are operator- or USS-defined key-value tags attached to an entity,
+// e.g. to record a flight ID, an operation type or an environment marker.
+type Labels map[string]string
+
+// Validate returns an error if l has too many labels, or a label with an empty
+// or too long key or a too long value.
+func (l Labels) Validate() error {
+	if len(l) > maxLabels {
+		return stacktrace.NewErrorWithCode(dsserr.BadRequest, "Too many labels: %d, maximum is %d", len(l), maxLabels)
+	}
+	for k, v := range l {
+		switch {
+		case k == "":
+			return stacktrace.NewErrorWithCode(dsserr.BadRequest, "Label keys may not be empty")
+		case len(k) > maxLabelKeyLength:
+			return stacktrace.NewErrorWithCode(dsserr.BadRequest, "Label key `%s` is longer than %d characters", k, maxLabelKeyLength)
+		case len(v) > maxLabelValueLength:
+			return stacktrace.NewErrorWithCode(dsserr.BadRequest, "Value of label `%s` is longer than %d characters", k, maxLabelValueLength)
+		}
+	}
+	return nil
+}
+
+// LabelsFromString parses a comma-separated list of key=value pairs (e.g.
+// "flight=F42,env=test") into Labels.
+func LabelsFromString(s string) (Labels, error) {
+	labels := Labels{}
+	if s == "" {
+		return labels, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Label `%s` is not of the form key=value", part)
+		}
+		labels[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return labels, labels.Validate()
+}
@@ -1,24 +1,118 @@
 package models
 
 import (
-	"github.com/interuss/stacktrace"
+	"net"
 	"net/url"
+	"strconv"
+	"strings"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
 )
 
-// ValidateURL ensures https
-func ValidateURL(s string) error {
+// PortRange is an inclusive range of TCP ports.
+type PortRange struct {
+	Min int
+	Max int
+}
+
+// Contains returns true if port lies within the range.
+func (r PortRange) Contains(port int) bool {
+	return port >= r.Min && port <= r.Max
+}
+
+// PortRangesFromString parses a comma-separated list of ports and port ranges
+// (e.g. "443,8443-8453") into a slice of PortRange.
+func PortRangesFromString(s string) ([]PortRange, error) {
+	var ranges []PortRange
+	if s == "" {
+		return ranges, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		lo, hi, isRange := strings.Cut(part, "-")
+		min, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Invalid port `%s`", lo)
+		}
+		max := min
+		if isRange {
+			max, err = strconv.Atoi(hi)
+			if err != nil {
+				return nil, stacktrace.Propagate(err, "Invalid port `%s`", hi)
+			}
+		}
+		if min < 1 || max > 65535 || min > max {
+			return nil, stacktrace.NewError("Invalid port range `%s`", part)
+		}
+		ranges = append(ranges, PortRange{Min: min, Max: max})
+	}
+	return ranges, nil
+}
+
+// URLPolicy describes the constraints that URLs provided by USSs (flights_url,
+// callback URLs) must satisfy before being stored, since they are later
+// dereferenced by other USSs. The zero value only requires the https scheme.
+type URLPolicy struct {
+	// AllowHTTP permits the http scheme in addition to https.
+	AllowHTTP bool
+	// RejectIPLiterals rejects URLs whose host is an IP address rather than a
+	// domain name.
+	RejectIPLiterals bool
+	// AllowedPorts restricts explicit ports to the given ranges. When empty,
+	// any port is accepted.
+	AllowedPorts []PortRange
+	// MaxLength is the maximum length of the URL in bytes. When 0, no limit is
+	// enforced.
+	MaxLength int
+}
+
+// Validate returns an error with code BadRequest describing the first
+// violation of the policy by the URL s, or nil if s satisfies the policy.
+func (p URLPolicy) Validate(s string) error {
+	if p.MaxLength > 0 && len(s) > p.MaxLength {
+		return stacktrace.NewErrorWithCode(dsserr.BadRequest, "URL length %d exceeds maximum of %d", len(s), p.MaxLength)
+	}
+
 	u, err := url.Parse(s)
 	if err != nil {
-		return stacktrace.Propagate(err, "Error parsing URL")
+		return stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Error parsing URL")
 	}
 
 	switch u.Scheme {
 	case "https":
 		// All good, proceed normally.
 	case "http":
-		return stacktrace.NewError("rid url must use TLS")
+		if !p.AllowHTTP {
+			return stacktrace.NewErrorWithCode(dsserr.BadRequest, "rid url must use TLS")
+		}
 	default:
-		return stacktrace.NewError("rid url must support https scheme")
+		return stacktrace.NewErrorWithCode(dsserr.BadRequest, "rid url must support https scheme, got `%s`", u.Scheme)
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return stacktrace.NewErrorWithCode(dsserr.BadRequest, "rid url must specify a host")
+	}
+	if p.RejectIPLiterals && net.ParseIP(host) != nil {
+		return stacktrace.NewErrorWithCode(dsserr.BadRequest, "rid url host must be a domain name, got IP address `%s`", host)
+	}
+
+	if len(p.AllowedPorts) > 0 && u.Port() != "" {
+		port, err := strconv.Atoi(u.Port())
+		if err != nil {
+			return stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Invalid port `%s`", u.Port())
+		}
+		allowed := false
+		for _, r := range p.AllowedPorts {
+			if r.Contains(port) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return stacktrace.NewErrorWithCode(dsserr.BadRequest, "rid url port %d is not within the allowed port ranges", port)
+		}
 	}
 
 	return nil
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortRangesFromString(t *testing.T) {
+	ranges, err := PortRangesFromString("443, 8443-8453")
+	require.NoError(t, err)
+	require.Equal(t, []PortRange{{Min: 443, Max: 443}, {Min: 8443, Max: 8453}}, ranges)
+
+	ranges, err = PortRangesFromString("")
+	require.NoError(t, err)
+	require.Empty(t, ranges)
+
+	for _, s := range []string{"abc", "0", "443-80", "1-70000", "443-"} {
+		_, err := PortRangesFromString(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestURLPolicyValidate(t *testing.T) {
+	strict := URLPolicy{
+		RejectIPLiterals: true,
+		AllowedPorts:     []PortRange{{Min: 443, Max: 443}, {Min: 8443, Max: 8453}},
+		MaxLength:        40,
+	}
+
+	for _, tc := range []struct {
+		name   string
+		policy URLPolicy
+		url    string
+		valid  bool
+	}{
+		{"default https", URLPolicy{}, "https://uss.example.com/flights", true},
+		{"default http", URLPolicy{}, "http://uss.example.com/flights", false},
+		{"allowed http", URLPolicy{AllowHTTP: true}, "http://uss.example.com/flights", true},
+		{"unsupported scheme", URLPolicy{AllowHTTP: true}, "ftp://uss.example.com", false},
+		{"missing host", URLPolicy{}, "https:///flights", false},
+		{"default IP literal", URLPolicy{}, "https://10.0.0.1/flights", true},
+		{"rejected IPv4 literal", strict, "https://10.0.0.1/flights", false},
+		{"rejected IPv6 literal", strict, "https://[::1]/flights", false},
+		{"implicit port", strict, "https://uss.example.com/flights", true},
+		{"allowed port", strict, "https://uss.example.com:8450/f", true},
+		{"disallowed port", strict, "https://uss.example.com:8080/f", false},
+		{"too long", strict, "https://uss.example.com/flights/are/over/here", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Validate(tc.url)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err))
+			}
+		})
+	}
+}
+
+func TestLabelsFromString(t *testing.T) {
+	labels, err := LabelsFromString("flight=F42, env = test")
+	require.NoError(t, err)
+	require.Equal(t, Labels{"flight": "F42", "env": "test"}, labels)
+
+	_, err = LabelsFromString("flight")
+	require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err))
+
+	_, err = LabelsFromString("=F42")
+	require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err))
+}
+
+func TestSubscriptionURLs(t *testing.T) {
+	sub := &Subscription{URL: "https://uss.example/isa"}
+	require.Equal(t, []string{"https://uss.example/isa"}, sub.URLs())
+
+	sub.CallbackURLs = []string{"https://uss.example/flights", "https://uss.example/isa"}
+	require.Equal(t, []string{"https://uss.example/isa", "https://uss.example/flights"}, sub.URLs())
+}
+
+func TestValidateCallbackURLs(t *testing.T) {
+	require.NoError(t, ValidateCallbackURLs(nil))
+	require.NoError(t, ValidateCallbackURLs([]string{"https://uss.example/a", "https://uss.example/b"}))
+
+	err := ValidateCallbackURLs([]string{"https://uss.example/a", "https://uss.example/a"})
+	require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err))
+
+	err = ValidateCallbackURLs([]string{""})
+	require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err))
+
+	err = ValidateCallbackURLs(make([]string, maxCallbackURLs+1))
+	require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err))
+}
+
+func TestValidateNotificationInterval(t *testing.T) {
+	require.NoError(t, ValidateNotificationInterval(0))
+	require.NoError(t, ValidateNotificationInterval(maxNotificationInterval))
+	require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(ValidateNotificationInterval(-time.Second)))
+	require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(ValidateNotificationInterval(maxNotificationInterval+time.Second)))
+}
+
+func TestSubscriptionNotificationDue(t *testing.T) {
+	now := time.Now()
+	sub := &Subscription{}
+	require.True(t, sub.NotificationDue(now))
+
+	sub.MinNotificationInterval = time.Minute
+	require.True(t, sub.NotificationDue(now))
+
+	last := now.Add(-30 * time.Second)
+	sub.LastNotifiedAt = &last
+	require.False(t, sub.NotificationDue(now))
+	require.True(t, sub.NotificationDue(now.Add(30*time.Second)))
+}
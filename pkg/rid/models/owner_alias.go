@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	dssmodels "github.com/interuss/dss/pkg/models"
+)
+
+// OwnerAlias maps the subject of access tokens to the canonical owner they
+// act as, e.g. the subject a USS is known by at another authorization server.
+type OwnerAlias struct {
+	Subject   dssmodels.Owner
+	Owner     dssmodels.Owner
+	UpdatedAt *time.Time
+}
@@ -18,8 +18,21 @@ var (
 	// maxClockSkew is the largest allowed interval between the StartTime of a new
 	// subscription and the server's idea of the current time.
 	maxClockSkew = time.Minute * 5
+
+	// maxCallbackURLs is the largest number of CallbackURLs of a subscription.
+	maxCallbackURLs = 8
+
+	// maxNotificationInterval is the largest MinNotificationInterval of a
+	// subscription.
+	maxNotificationInterval = time.Hour
 )
 
+// MaxSubscriptionDuration returns the largest allowed interval between the
+// StartTime and EndTime of a Subscription.
+func MaxSubscriptionDuration() time.Duration {
+	return maxSubscriptionDuration
+}
+
 // Subscription represents a USS subscription over a given 4D volume.
 type Subscription struct {
 	ID                dssmodels.ID
@@ -33,6 +46,77 @@ type Subscription struct {
 	AltitudeHi        *float32
 	AltitudeLo        *float32
 	Writer            string
+	Labels            Labels
+
+	// CallbackURLs are the URLs at which the subscriber is notified besides
+	// URL, e.g. the distinct endpoints of future entity types.
+	CallbackURLs []string
+
+	// MinNotificationInterval is the minimum interval between the
+	// notifications of the subscriber, no minimum if 0: the changes within
+	// this interval of LastNotifiedAt still increment NotificationIndex but
+	// are not notified.
+	MinNotificationInterval time.Duration
+	// LastNotifiedAt is the time at which the subscriber was last notified,
+	// only recorded if MinNotificationInterval is set.
+	LastNotifiedAt *time.Time
+
+	// NotifyOwnISAs is set if the subscriber is notified of the changes to
+	// the ISAs of its owner too, e.g. when the publishing and monitoring
+	// systems of a USS are separate.
+	NotifyOwnISAs bool
+
+	// PreviousNotificationIndex is the NotificationIndex before it was
+	// incremented, only set in the Subscriptions returned by updates of
+	// notification indices.
+	PreviousNotificationIndex int
+}
+
+// URLs returns the URLs at which the subscriber is notified: URL followed by
+// the CallbackURLs.
+func (s *Subscription) URLs() []string {
+	urls := []string{s.URL}
+	for _, u := range s.CallbackURLs {
+		if u != s.URL {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// ValidateCallbackURLs returns an error with code BadRequest if urls cannot be
+// the CallbackURLs of a subscription: if there are too many of them, or if
+// any is empty or repeated.
+func ValidateCallbackURLs(urls []string) error {
+	if len(urls) > maxCallbackURLs {
+		return stacktrace.NewErrorWithCode(dsserr.BadRequest, "%d callback URLs exceed the maximum of %d", len(urls), maxCallbackURLs)
+	}
+	seen := make(map[string]bool, len(urls))
+	for _, u := range urls {
+		switch {
+		case u == "":
+			return stacktrace.NewErrorWithCode(dsserr.BadRequest, "Empty callback URL")
+		case seen[u]:
+			return stacktrace.NewErrorWithCode(dsserr.BadRequest, "Duplicate callback URL `%s`", u)
+		}
+		seen[u] = true
+	}
+	return nil
+}
+
+// ValidateNotificationInterval returns an error with code BadRequest if
+// interval cannot be the MinNotificationInterval of a subscription.
+func ValidateNotificationInterval(interval time.Duration) error {
+	if interval < 0 || interval > maxNotificationInterval {
+		return stacktrace.NewErrorWithCode(dsserr.BadRequest, "Minimum notification interval %s is outside [0s, %s]", interval, maxNotificationInterval)
+	}
+	return nil
+}
+
+// NotificationDue returns whether the subscriber is to be notified of a change
+// at now, i.e. at least MinNotificationInterval after it was last notified.
+func (s *Subscription) NotificationDue(now time.Time) bool {
+	return s.MinNotificationInterval <= 0 || s.LastNotifiedAt == nil || now.Sub(*s.LastNotifiedAt) >= s.MinNotificationInterval
 }
 
 // SetCells is a convenience function that accepts an int64 array and converts
@@ -0,0 +1,17 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+)
+
+// Activity is an interface to the history of changes of remote ID entities.
+type Activity interface {
+	// ListActivity returns, ordered by time, the events which occurred up to
+	// "to" and either after "from" or to subscriptions which may be active
+	// after "from", i.e. all the events needed to count the subscriptions
+	// active at any time between "from" and "to".
+	ListActivity(ctx context.Context, from time.Time, to time.Time) ([]*ridmodels.ActivityEvent, error)
+}
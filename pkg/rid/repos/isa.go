@@ -26,8 +26,39 @@ type ISA interface {
 	// Returns nil, nil if ID, version not found
 	UpdateISA(ctx context.Context, isa *ridmodels.IdentificationServiceArea) (*ridmodels.IdentificationServiceArea, error)
 
-	// SearchISAs returns all subscriptions ownded by "owner" in "cells".
-	SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time) ([]*ridmodels.IdentificationServiceArea, error)
+	// SearchISAs returns all ISAs in "cells", excluding those owned by
+	// "excludeOwner" if set.
+	SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time, excludeOwner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error)
+
+	// CountISAs returns the number, up to "limit", of the ISAs SearchISAs
+	// returns without an excluded owner.
+	CountISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time, limit int) (int, error)
+
+	// UpdateISALabels replaces the labels of the ISA identified by "id".
+	// Returns nil, nil if not found
+	UpdateISALabels(ctx context.Context, id dssmodels.ID, labels ridmodels.Labels) (*ridmodels.IdentificationServiceArea, error)
+
+	// TransferISA makes "owner" the owner of the ISA identified by "id",
+	// giving it a new version.
+	// Returns nil, nil if not found
+	TransferISA(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner) (*ridmodels.IdentificationServiceArea, error)
+
+	// SearchISAsByLabels returns the ISAs that have not ended yet and carry
+	// all of "labels".
+	SearchISAsByLabels(ctx context.Context, labels ridmodels.Labels) ([]*ridmodels.IdentificationServiceArea, error)
+
+	// ListISAsByOwner returns all IdentificationServiceAreas owned by "owner"
+	// that have not ended yet.
+	ListISAsByOwner(ctx context.Context, owner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error)
+
+	// CountISAsByOwner returns the number of IdentificationServiceAreas owned
+	// by "owner" that have not ended yet.
+	CountISAsByOwner(ctx context.Context, owner dssmodels.Owner) (int, error)
+
+	// SearchISAsByURL returns the ISAs of all owners that have not ended yet
+	// and whose flights URL is "url" or, if "prefix" is set, starts with
+	// "url".
+	SearchISAsByURL(ctx context.Context, url string, prefix bool) ([]*ridmodels.IdentificationServiceArea, error)
 
 	// ListExpiredISAs lists all expired ISAs based on writer
 	ListExpiredISAs(ctx context.Context, writer string) ([]*ridmodels.IdentificationServiceArea, error)
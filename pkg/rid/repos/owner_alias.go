@@ -0,0 +1,23 @@
+package repos
+
+import (
+	"context"
+
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+)
+
+// OwnerAlias is an interface to the mapping of the subjects of access tokens
+// to canonical owners.
+type OwnerAlias interface {
+	// ListOwnerAliases returns all the aliases, ordered by subject.
+	ListOwnerAliases(ctx context.Context) ([]*ridmodels.OwnerAlias, error)
+
+	// UpsertOwnerAlias maps alias.Subject to alias.Owner, replacing the
+	// previous alias of alias.Subject.
+	UpsertOwnerAlias(ctx context.Context, alias *ridmodels.OwnerAlias) (*ridmodels.OwnerAlias, error)
+
+	// DeleteOwnerAlias deletes the alias of subject, returning nil if it had
+	// none.
+	DeleteOwnerAlias(ctx context.Context, subject dssmodels.Owner) (*ridmodels.OwnerAlias, error)
+}
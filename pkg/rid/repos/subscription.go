@@ -2,6 +2,7 @@ package repos
 
 import (
 	"context"
+	"time"
 
 	"github.com/golang/geo/s2"
 	dssmodels "github.com/interuss/dss/pkg/models"
@@ -10,8 +11,9 @@ import (
 
 // Subscription is an interface to a storage layer for the Subscription entity
 type Subscription interface {
-	// Returns nil, nil if not found
-	GetSubscription(ctx context.Context, id dssmodels.ID) (*ridmodels.Subscription, error)
+	// Returns nil, nil if not found. If forUpdate is true, the row is locked
+	// until the end of the enclosing transaction.
+	GetSubscription(ctx context.Context, id dssmodels.ID, forUpdate bool) (*ridmodels.Subscription, error)
 
 	// DeleteSubscription deletes the IdentificationServiceArea identified by "id" and owned by "owner".
 	// Returns the delete IdentificationServiceArea and all Subscriptions affected by the delete.
@@ -31,8 +33,55 @@ type Subscription interface {
 	// SearchSubscriptionsByOwner returns all subscriptions ownded by "owner" in "cells".
 	SearchSubscriptionsByOwner(ctx context.Context, cells s2.CellUnion, owner dssmodels.Owner) ([]*ridmodels.Subscription, error)
 
-	// UpdateNotificationIdxsInCells incremement the notification for each sub in the given cells.
-	UpdateNotificationIdxsInCells(ctx context.Context, cells s2.CellUnion) ([]*ridmodels.Subscription, error)
+	// UpdateNotificationIdxsInCells increments the notification index of, and
+	// returns, the Subscriptions to notify of a change to an
+	// IdentificationServiceArea owned by "owner" in "cells" between "startTime"
+	// and "endTime": active Subscriptions in "cells", not owned by "owner"
+	// unless notified of their owner's ISAs, whose time range overlaps the
+	// given one. Nil bounds are open. The returned Subscriptions carry both
+	// their previous and new indices.
+	UpdateNotificationIdxsInCells(ctx context.Context, cells s2.CellUnion, owner dssmodels.Owner, startTime, endTime *time.Time) ([]*ridmodels.Subscription, error)
+
+	// UpdateSubscriptionLabels replaces the labels of the Subscription
+	// identified by "id".
+	// Returns nil, nil if not found
+	UpdateSubscriptionLabels(ctx context.Context, id dssmodels.ID, labels ridmodels.Labels) (*ridmodels.Subscription, error)
+
+	// UpdateSubscriptionCallbacks replaces the callback URLs of the
+	// Subscription identified by "id" besides its URL.
+	// Returns nil, nil if not found
+	UpdateSubscriptionCallbacks(ctx context.Context, id dssmodels.ID, urls []string) (*ridmodels.Subscription, error)
+
+	// UpdateSubscriptionNotificationInterval replaces the minimum interval
+	// between the notifications of the Subscription identified by "id".
+	// Returns nil, nil if not found
+	UpdateSubscriptionNotificationInterval(ctx context.Context, id dssmodels.ID, interval time.Duration) (*ridmodels.Subscription, error)
+
+	// RecordSubscriptionNotifications records that the Subscriptions
+	// identified by "ids" were notified at "at".
+	RecordSubscriptionNotifications(ctx context.Context, ids []dssmodels.ID, at time.Time) error
+
+	// UpdateSubscriptionSelfNotification sets whether the Subscription
+	// identified by "id" is notified of the changes to the ISAs of its owner.
+	// Returns nil, nil if not found
+	UpdateSubscriptionSelfNotification(ctx context.Context, id dssmodels.ID, notify bool) (*ridmodels.Subscription, error)
+
+	// TransferSubscription makes "owner" the owner of the Subscription
+	// identified by "id", giving it a new version.
+	// Returns nil, nil if not found
+	TransferSubscription(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner) (*ridmodels.Subscription, error)
+
+	// SearchSubscriptionsByLabels returns the Subscriptions that have not
+	// ended yet and carry all of "labels".
+	SearchSubscriptionsByLabels(ctx context.Context, labels ridmodels.Labels) ([]*ridmodels.Subscription, error)
+
+	// SearchSubscriptionsByURL returns the Subscriptions owned by "owner" that
+	// have not ended yet and whose URL contains "substring".
+	SearchSubscriptionsByURL(ctx context.Context, owner dssmodels.Owner, substring string) ([]*ridmodels.Subscription, error)
+
+	// CountSubscriptionsByOwner returns the number of Subscriptions owned by
+	// "owner" that have not ended yet.
+	CountSubscriptionsByOwner(ctx context.Context, owner dssmodels.Owner) (int, error)
 
 	// MaxSubscriptionCountInCellsByOwner finds, out of a set of cells, the cell with the most subscriptions
 	// belonging to the given owner, and returns that number.
@@ -0,0 +1,6 @@
+// Package ridtest provides test doubles of the remote ID service of the DSS
+// for hermetic unit tests, including those of USSs using this module: the
+// in-memory Store of package memory, a testify MockStore, and a fake DSS
+// server without authentication. It must only be imported by tests, so that
+// testify is not linked into binaries.
+package ridtest
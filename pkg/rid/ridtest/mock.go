@@ -0,0 +1,261 @@
+package ridtest
+
+import (
+	"context"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/golang/geo/s2"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/dss/pkg/rid/repos"
+	"github.com/interuss/dss/pkg/rid/store"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockStore is a testify mock of both store.Store and repos.Repository.
+// Interact, Transact, GetVersion and Close are not mocked: Interact and
+// Transact provide the MockStore itself as the repository, so that the
+// expectations are set on the repository methods only, and transactions are
+// not rolled back.
+type MockStore struct {
+	mock.Mock
+}
+
+var (
+	_ store.Store      = &MockStore{}
+	_ repos.Repository = &MockStore{}
+)
+
+// Interact implements store.Interactor.
+func (m *MockStore) Interact(context.Context) (repos.Repository, error) {
+	return m, nil
+}
+
+// Transact implements store.Transactor.
+func (m *MockStore) Transact(_ context.Context, f func(repos.Repository) error) error {
+	return f(m)
+}
+
+// GetVersion implements store.Store.
+func (m *MockStore) GetVersion(context.Context) (*semver.Version, error) {
+	return semver.New("0.0.0"), nil
+}
+
+// Close implements io.Closer.
+func (m *MockStore) Close() error {
+	return nil
+}
+
+// isaResult returns the ISA and error of args.
+func isaResult(args mock.Arguments) (*ridmodels.IdentificationServiceArea, error) {
+	isa, _ := args.Get(0).(*ridmodels.IdentificationServiceArea)
+	return isa, args.Error(1)
+}
+
+// isasResult returns the ISAs and error of args.
+func isasResult(args mock.Arguments) ([]*ridmodels.IdentificationServiceArea, error) {
+	isas, _ := args.Get(0).([]*ridmodels.IdentificationServiceArea)
+	return isas, args.Error(1)
+}
+
+// subscriptionResult returns the subscription and error of args.
+func subscriptionResult(args mock.Arguments) (*ridmodels.Subscription, error) {
+	sub, _ := args.Get(0).(*ridmodels.Subscription)
+	return sub, args.Error(1)
+}
+
+// subscriptionsResult returns the subscriptions and error of args.
+func subscriptionsResult(args mock.Arguments) ([]*ridmodels.Subscription, error) {
+	subs, _ := args.Get(0).([]*ridmodels.Subscription)
+	return subs, args.Error(1)
+}
+
+// ownerAliasResult returns the alias and error of args.
+func ownerAliasResult(args mock.Arguments) (*ridmodels.OwnerAlias, error) {
+	alias, _ := args.Get(0).(*ridmodels.OwnerAlias)
+	return alias, args.Error(1)
+}
+
+// GetISA implements repos.ISA.
+func (m *MockStore) GetISA(ctx context.Context, id dssmodels.ID, forUpdate bool) (*ridmodels.IdentificationServiceArea, error) {
+	return isaResult(m.Called(ctx, id, forUpdate))
+}
+
+// DeleteISA implements repos.ISA.
+func (m *MockStore) DeleteISA(ctx context.Context, isa *ridmodels.IdentificationServiceArea) (*ridmodels.IdentificationServiceArea, error) {
+	return isaResult(m.Called(ctx, isa))
+}
+
+// InsertISA implements repos.ISA.
+func (m *MockStore) InsertISA(ctx context.Context, isa *ridmodels.IdentificationServiceArea) (*ridmodels.IdentificationServiceArea, error) {
+	return isaResult(m.Called(ctx, isa))
+}
+
+// UpdateISA implements repos.ISA.
+func (m *MockStore) UpdateISA(ctx context.Context, isa *ridmodels.IdentificationServiceArea) (*ridmodels.IdentificationServiceArea, error) {
+	return isaResult(m.Called(ctx, isa))
+}
+
+// SearchISAs implements repos.ISA.
+func (m *MockStore) SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time, excludeOwner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error) {
+	return isasResult(m.Called(ctx, cells, earliest, latest, excludeOwner))
+}
+
+// CountISAs implements repos.ISA.
+func (m *MockStore) CountISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time, limit int) (int, error) {
+	args := m.Called(ctx, cells, earliest, latest, limit)
+	return args.Int(0), args.Error(1)
+}
+
+// UpdateISALabels implements repos.ISA.
+func (m *MockStore) UpdateISALabels(ctx context.Context, id dssmodels.ID, labels ridmodels.Labels) (*ridmodels.IdentificationServiceArea, error) {
+	return isaResult(m.Called(ctx, id, labels))
+}
+
+// TransferISA implements repos.ISA.
+func (m *MockStore) TransferISA(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner) (*ridmodels.IdentificationServiceArea, error) {
+	return isaResult(m.Called(ctx, id, owner))
+}
+
+// SearchISAsByLabels implements repos.ISA.
+func (m *MockStore) SearchISAsByLabels(ctx context.Context, labels ridmodels.Labels) ([]*ridmodels.IdentificationServiceArea, error) {
+	return isasResult(m.Called(ctx, labels))
+}
+
+// ListISAsByOwner implements repos.ISA.
+func (m *MockStore) ListISAsByOwner(ctx context.Context, owner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error) {
+	return isasResult(m.Called(ctx, owner))
+}
+
+// CountISAsByOwner implements repos.ISA.
+func (m *MockStore) CountISAsByOwner(ctx context.Context, owner dssmodels.Owner) (int, error) {
+	args := m.Called(ctx, owner)
+	return args.Int(0), args.Error(1)
+}
+
+// SearchISAsByURL implements repos.ISA.
+func (m *MockStore) SearchISAsByURL(ctx context.Context, url string, prefix bool) ([]*ridmodels.IdentificationServiceArea, error) {
+	return isasResult(m.Called(ctx, url, prefix))
+}
+
+// ListExpiredISAs implements repos.ISA.
+func (m *MockStore) ListExpiredISAs(ctx context.Context, writer string) ([]*ridmodels.IdentificationServiceArea, error) {
+	return isasResult(m.Called(ctx, writer))
+}
+
+// GetSubscription implements repos.Subscription.
+func (m *MockStore) GetSubscription(ctx context.Context, id dssmodels.ID, forUpdate bool) (*ridmodels.Subscription, error) {
+	return subscriptionResult(m.Called(ctx, id, forUpdate))
+}
+
+// DeleteSubscription implements repos.Subscription.
+func (m *MockStore) DeleteSubscription(ctx context.Context, sub *ridmodels.Subscription) (*ridmodels.Subscription, error) {
+	return subscriptionResult(m.Called(ctx, sub))
+}
+
+// InsertSubscription implements repos.Subscription.
+func (m *MockStore) InsertSubscription(ctx context.Context, sub *ridmodels.Subscription) (*ridmodels.Subscription, error) {
+	return subscriptionResult(m.Called(ctx, sub))
+}
+
+// UpdateSubscription implements repos.Subscription.
+func (m *MockStore) UpdateSubscription(ctx context.Context, sub *ridmodels.Subscription) (*ridmodels.Subscription, error) {
+	return subscriptionResult(m.Called(ctx, sub))
+}
+
+// SearchSubscriptions implements repos.Subscription.
+func (m *MockStore) SearchSubscriptions(ctx context.Context, cells s2.CellUnion) ([]*ridmodels.Subscription, error) {
+	return subscriptionsResult(m.Called(ctx, cells))
+}
+
+// SearchSubscriptionsByOwner implements repos.Subscription.
+func (m *MockStore) SearchSubscriptionsByOwner(ctx context.Context, cells s2.CellUnion, owner dssmodels.Owner) ([]*ridmodels.Subscription, error) {
+	return subscriptionsResult(m.Called(ctx, cells, owner))
+}
+
+// UpdateNotificationIdxsInCells implements repos.Subscription.
+func (m *MockStore) UpdateNotificationIdxsInCells(ctx context.Context, cells s2.CellUnion, owner dssmodels.Owner, startTime, endTime *time.Time) ([]*ridmodels.Subscription, error) {
+	return subscriptionsResult(m.Called(ctx, cells, owner, startTime, endTime))
+}
+
+// UpdateSubscriptionLabels implements repos.Subscription.
+func (m *MockStore) UpdateSubscriptionLabels(ctx context.Context, id dssmodels.ID, labels ridmodels.Labels) (*ridmodels.Subscription, error) {
+	return subscriptionResult(m.Called(ctx, id, labels))
+}
+
+// UpdateSubscriptionCallbacks implements repos.Subscription.
+func (m *MockStore) UpdateSubscriptionCallbacks(ctx context.Context, id dssmodels.ID, urls []string) (*ridmodels.Subscription, error) {
+	return subscriptionResult(m.Called(ctx, id, urls))
+}
+
+// UpdateSubscriptionNotificationInterval implements repos.Subscription.
+func (m *MockStore) UpdateSubscriptionNotificationInterval(ctx context.Context, id dssmodels.ID, interval time.Duration) (*ridmodels.Subscription, error) {
+	return subscriptionResult(m.Called(ctx, id, interval))
+}
+
+// RecordSubscriptionNotifications implements repos.Subscription.
+func (m *MockStore) RecordSubscriptionNotifications(ctx context.Context, ids []dssmodels.ID, at time.Time) error {
+	return m.Called(ctx, ids, at).Error(0)
+}
+
+// UpdateSubscriptionSelfNotification implements repos.Subscription.
+func (m *MockStore) UpdateSubscriptionSelfNotification(ctx context.Context, id dssmodels.ID, notify bool) (*ridmodels.Subscription, error) {
+	return subscriptionResult(m.Called(ctx, id, notify))
+}
+
+// TransferSubscription implements repos.Subscription.
+func (m *MockStore) TransferSubscription(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner) (*ridmodels.Subscription, error) {
+	return subscriptionResult(m.Called(ctx, id, owner))
+}
+
+// SearchSubscriptionsByLabels implements repos.Subscription.
+func (m *MockStore) SearchSubscriptionsByLabels(ctx context.Context, labels ridmodels.Labels) ([]*ridmodels.Subscription, error) {
+	return subscriptionsResult(m.Called(ctx, labels))
+}
+
+// SearchSubscriptionsByURL implements repos.Subscription.
+func (m *MockStore) SearchSubscriptionsByURL(ctx context.Context, owner dssmodels.Owner, substring string) ([]*ridmodels.Subscription, error) {
+	return subscriptionsResult(m.Called(ctx, owner, substring))
+}
+
+// CountSubscriptionsByOwner implements repos.Subscription.
+func (m *MockStore) CountSubscriptionsByOwner(ctx context.Context, owner dssmodels.Owner) (int, error) {
+	args := m.Called(ctx, owner)
+	return args.Int(0), args.Error(1)
+}
+
+// MaxSubscriptionCountInCellsByOwner implements repos.Subscription.
+func (m *MockStore) MaxSubscriptionCountInCellsByOwner(ctx context.Context, cells s2.CellUnion, owner dssmodels.Owner) (int, error) {
+	args := m.Called(ctx, cells, owner)
+	return args.Int(0), args.Error(1)
+}
+
+// ListExpiredSubscriptions implements repos.Subscription.
+func (m *MockStore) ListExpiredSubscriptions(ctx context.Context, writer string) ([]*ridmodels.Subscription, error) {
+	return subscriptionsResult(m.Called(ctx, writer))
+}
+
+// ListActivity implements repos.Activity.
+func (m *MockStore) ListActivity(ctx context.Context, from time.Time, to time.Time) ([]*ridmodels.ActivityEvent, error) {
+	args := m.Called(ctx, from, to)
+	events, _ := args.Get(0).([]*ridmodels.ActivityEvent)
+	return events, args.Error(1)
+}
+
+// ListOwnerAliases implements repos.OwnerAlias.
+func (m *MockStore) ListOwnerAliases(ctx context.Context) ([]*ridmodels.OwnerAlias, error) {
+	args := m.Called(ctx)
+	aliases, _ := args.Get(0).([]*ridmodels.OwnerAlias)
+	return aliases, args.Error(1)
+}
+
+// UpsertOwnerAlias implements repos.OwnerAlias.
+func (m *MockStore) UpsertOwnerAlias(ctx context.Context, alias *ridmodels.OwnerAlias) (*ridmodels.OwnerAlias, error) {
+	return ownerAliasResult(m.Called(ctx, alias))
+}
+
+// DeleteOwnerAlias implements repos.OwnerAlias.
+func (m *MockStore) DeleteOwnerAlias(ctx context.Context, subject dssmodels.Owner) (*ridmodels.OwnerAlias, error) {
+	return ownerAliasResult(m.Called(ctx, subject))
+}
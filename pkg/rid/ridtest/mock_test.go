@@ -0,0 +1,60 @@
+package ridtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/s2"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/dss/pkg/rid/application"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var cells = s2.CellUnion{s2.CellIDFromToken("479c7ffc"), s2.CellIDFromToken("479c8004")}
+
+func newISA(owner dssmodels.Owner) *ridmodels.IdentificationServiceArea {
+	start := time.Now()
+	end := start.Add(time.Hour)
+	return &ridmodels.IdentificationServiceArea{
+		ID:        dssmodels.ID("4348c8e5-0b1c-43cf-9114-2e67a4532765"),
+		Owner:     owner,
+		URL:       "https://uss1.example/rid",
+		Cells:     cells,
+		StartTime: &start,
+		EndTime:   &end,
+	}
+}
+
+func TestStoreRejectsDeletesOfChangedISAs(t *testing.T) {
+	ctx := context.Background()
+	m := &MockStore{}
+	app := application.NewFromTransactor(m, zap.NewNop())
+
+	isa := newISA("uss1")
+	isa.Version = dssmodels.VersionFromTime(time.Now())
+	m.On("GetISA", mock.Anything, isa.ID, true).Return(isa, nil)
+	// The ISA changed between its read and its delete.
+	m.On("DeleteISA", mock.Anything, isa).Return(nil, nil)
+
+	_, _, err := app.DeleteISA(ctx, isa.ID, "uss1", isa.Version)
+	require.Equal(t, dsserr.StaleVersion, stacktrace.GetCode(err))
+	m.AssertExpectations(t)
+}
+
+func TestMockStore(t *testing.T) {
+	ctx := context.Background()
+	m := &MockStore{}
+	m.On("GetISA", mock.Anything, dssmodels.ID("4348c8e5-0b1c-43cf-9114-2e67a4532765"), false).Return(newISA("uss1"), nil)
+	app := application.NewFromTransactor(m, zap.NewNop())
+
+	isa, err := app.GetISA(ctx, "4348c8e5-0b1c-43cf-9114-2e67a4532765")
+	require.NoError(t, err)
+	require.Equal(t, dssmodels.Owner("uss1"), isa.Owner)
+	m.AssertExpectations(t)
+}
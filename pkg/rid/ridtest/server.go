@@ -0,0 +1,81 @@
+package ridtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/interuss/dss/pkg/api"
+	apiauxv1 "github.com/interuss/dss/pkg/api/auxv1"
+	apiridv1 "github.com/interuss/dss/pkg/api/ridv1"
+	apiridv2 "github.com/interuss/dss/pkg/api/ridv2"
+	aux "github.com/interuss/dss/pkg/aux_"
+	"github.com/interuss/dss/pkg/rid/application"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
+	rid_v1 "github.com/interuss/dss/pkg/rid/server/v1"
+	rid_v2 "github.com/interuss/dss/pkg/rid/server/v2"
+	"github.com/interuss/dss/pkg/rid/store"
+	"go.uber.org/zap"
+)
+
+// DefaultOwner is the owner of the requests to a fake DSS which carry no
+// access token.
+const DefaultOwner = "uss1"
+
+// Locality is the writer of the entities written through a fake DSS.
+const Locality = "ridtest"
+
+// authorizer authorizes every request with all the scopes required, on
+// behalf of the subject of its access token, which is not verified.
+type authorizer struct{}
+
+// Authorize implements api.Authorizer.
+func (authorizer) Authorize(_ http.ResponseWriter, r *http.Request, authOptions []api.AuthorizationOption) api.AuthorizationResult {
+	owner := DefaultOwner
+	if token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "); token != "" {
+		owner = token
+		claims := &jwt.RegisteredClaims{}
+		if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err == nil && claims.Subject != "" {
+			owner = claims.Subject
+		}
+	}
+	var scopes []string
+	for _, option := range authOptions {
+		for _, required := range option {
+			for _, scope := range required {
+				scopes = append(scopes, string(scope))
+			}
+		}
+	}
+	return api.AuthorizationResult{ClientID: &owner, Scopes: scopes}
+}
+
+// NewHandler returns a fake DSS serving the remote ID APIs of versions 1 and
+// 2 and the auxiliary API from s, without authentication: requests are
+// made on behalf of the subject of their access token, which is not
+// verified, or of the access token itself if it is not a JWT, or of
+// DefaultOwner if they carry none. http URLs are accepted.
+func NewHandler(s store.Store) http.Handler {
+	app := application.NewFromTransactor(s, zap.NewNop())
+	urlPolicy := ridmodels.URLPolicy{AllowHTTP: true}
+	v1 := &rid_v1.Server{App: app, Timeout: 10 * time.Second, Locality: Locality, URLPolicy: urlPolicy}
+	v2 := &rid_v2.Server{App: app, Timeout: 10 * time.Second, Locality: Locality, URLPolicy: urlPolicy}
+	auxV1 := &aux.Server{RIDApp: app}
+
+	auxV1Router := apiauxv1.MakeAPIRouter(auxV1, authorizer{})
+	ridV1Router := apiridv1.MakeAPIRouter(v1, authorizer{})
+	ridV2Router := apiridv2.MakeAPIRouter(v2, authorizer{})
+	return ridserver.ExcludeSelfMiddleware(ridserver.ExactGeometryMiddleware(ridserver.NotificationDeltasMiddleware(&api.MultiRouter{
+		Routers: []api.PartialRouter{&auxV1Router, &ridV1Router, &ridV2Router},
+	})))
+}
+
+// NewServer starts a fake DSS, as served by NewHandler, over a new empty
+// Store. The caller should call Close on the server when finished.
+func NewServer() (*httptest.Server, *Store) {
+	s := NewStore()
+	return httptest.NewServer(NewHandler(s)), s
+}
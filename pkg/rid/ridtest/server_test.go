@@ -0,0 +1,130 @@
+package ridtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	restapi "github.com/interuss/dss/pkg/api/ridv2"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
+	"github.com/stretchr/testify/require"
+)
+
+func isaParameters(start, end time.Time) restapi.CreateIdentificationServiceAreaParameters {
+	return restapi.CreateIdentificationServiceAreaParameters{
+		Extents: restapi.Volume4D{
+			Volume: restapi.Volume3D{
+				OutlinePolygon: &restapi.Polygon{Vertices: []restapi.LatLngPoint{
+					{Lat: 46.9, Lng: 7.4}, {Lat: 46.9, Lng: 7.41}, {Lat: 46.91, Lng: 7.41}, {Lat: 46.91, Lng: 7.4},
+				}},
+				AltitudeLower: &restapi.Altitude{Value: 0, Reference: "W84", Units: "M"},
+				AltitudeUpper: &restapi.Altitude{Value: 120, Reference: "W84", Units: "M"},
+			},
+			TimeStart: &restapi.Time{Value: start.Format(time.RFC3339), Format: "RFC3339"},
+			TimeEnd:   &restapi.Time{Value: end.Format(time.RFC3339), Format: "RFC3339"},
+		},
+		UssBaseUrl: "http://uss1.example/rid",
+	}
+}
+
+func do(t *testing.T, method, url, owner string, body interface{}, header http.Header) (*http.Response, map[string]interface{}) {
+	var content []byte
+	if body != nil {
+		var err error
+		content, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(content))
+	require.NoError(t, err)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if owner != "" {
+		req.Header.Set("Authorization", "Bearer "+owner)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	result := map[string]interface{}{}
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+	return resp, result
+}
+
+func TestServer(t *testing.T) {
+	server, store := NewServer()
+	defer server.Close()
+
+	var (
+		now   = time.Now()
+		isaID = uuid.New().String()
+		isas  = server.URL + "/rid/v2/dss/identification_service_areas"
+	)
+	resp, result := do(t, http.MethodPut, isas+"/"+isaID, "", isaParameters(now, now.Add(time.Hour)), nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, result)
+	isa := result["service_area"].(map[string]interface{})
+	require.Equal(t, DefaultOwner, isa["owner"])
+	version := isa["version"].(string)
+
+	stored, err := store.Interact(context.Background())
+	require.NoError(t, err)
+	isas1, err := stored.ListISAsByOwner(context.Background(), DefaultOwner)
+	require.NoError(t, err)
+	require.Len(t, isas1, 1)
+	require.Equal(t, Locality, isas1[0].Writer)
+	require.Equal(t, float32(0), *isas1[0].AltitudeLo)
+	require.Equal(t, float32(120), *isas1[0].AltitudeHi)
+	require.Len(t, isas1[0].Footprint.(*dssmodels.GeoPolygon).Vertices, 4)
+
+	// Updates of another version conflict.
+	resp, _ = do(t, http.MethodPut, isas+"/"+isaID+"/"+version, "", isaParameters(now, now.Add(2*time.Hour)), nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = do(t, http.MethodPut, isas+"/"+isaID+"/"+version, "", isaParameters(now, now.Add(3*time.Hour)), nil)
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	// Other owners cannot modify the ISA but find it.
+	resp, result = do(t, http.MethodGet, isas+"/"+isaID, "uss2", nil, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	version = result["service_area"].(map[string]interface{})["version"].(string)
+	resp, _ = do(t, http.MethodDelete, isas+"/"+isaID+"/"+version, "uss2", nil, nil)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	search := isas + "?area=46.89,7.39,46.89,7.42,46.92,7.42,46.92,7.39"
+	resp, result = do(t, http.MethodGet, search, "uss2", nil, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, result["service_areas"], 1)
+	resp, result = do(t, http.MethodGet, search, "", nil, http.Header{ridserver.ExcludeSelfHeader: {"true"}})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Empty(t, result["service_areas"])
+
+	// Areas next to the ISA share cells with it, unless searched exactly.
+	nearby := isas + "?area=46.9,7.4102,46.9,7.411,46.91,7.411,46.91,7.4102"
+	resp, result = do(t, http.MethodGet, nearby, "uss2", nil, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, result["service_areas"], 1)
+	resp, result = do(t, http.MethodGet, nearby, "uss2", nil, http.Header{ridserver.ExactGeometryHeader: {"true"}})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Empty(t, result["service_areas"])
+	resp, result = do(t, http.MethodGet, search, "uss2", nil, http.Header{ridserver.ExactGeometryHeader: {"true"}})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, result["service_areas"], 1)
+
+	// Responses report the notification indices before and after the write.
+	subID := uuid.New().String()
+	params := isaParameters(now, now.Add(time.Hour))
+	resp, result = do(t, http.MethodPut, server.URL+"/rid/v2/dss/subscriptions/"+subID, "uss2",
+		restapi.CreateSubscriptionParameters{Extents: params.Extents, UssBaseUrl: "http://uss2.example/rid"}, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode, result)
+	require.Empty(t, resp.Header.Get(ridserver.NotificationDeltasHeader))
+
+	resp, _ = do(t, http.MethodDelete, isas+"/"+isaID+"/"+version, "", nil, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, subID+":0:1", resp.Header.Get(ridserver.NotificationDeltasHeader))
+	resp, _ = do(t, http.MethodGet, isas+"/"+isaID, "", nil, nil)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,16 @@
+package ridtest
+
+import "github.com/interuss/dss/pkg/rid/store/memory"
+
+// Store is the in-memory store of package memory, behaving like the
+// CockroachDB store.
+type Store = memory.Store
+
+// MemoryScheme is the URI scheme of the memory Stores opened through
+// store.Open.
+const MemoryScheme = memory.Scheme
+
+// NewStore returns an empty Store using the real clock.
+func NewStore() *Store {
+	return memory.NewStore()
+}
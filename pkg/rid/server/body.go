@@ -0,0 +1,19 @@
+package server
+
+import (
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+)
+
+// CheckBody returns a BadRequest error if the body of a request could not be
+// parsed, as reported by parseErr, or is missing. Handlers must check it
+// before mapping body to business objects.
+func CheckBody[T any](body *T, parseErr error) error {
+	if parseErr != nil {
+		return stacktrace.PropagateWithCode(parseErr, dsserr.BadRequest, "Malformed params")
+	}
+	if body == nil {
+		return stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing params")
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	"github.com/interuss/stacktrace"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+// churnWindow is the period over which the writes of an ISA are counted.
+const churnWindow = time.Minute
+
+var (
+	// isaPathPattern matches the paths of the ISAs of the remote ID APIs,
+	// capturing their ID and, for updates and deletions, their version.
+	isaPathPattern = regexp.MustCompile(`^(?:/rid/v2|/v1)/dss/identification_service_areas/([^/]+)(?:/([^/]+))?$`)
+
+	churnDetections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dss_rid_isa_churn_detections_total",
+		Help: "Number of times an ISA was found to churn, by kind of churn (updates or recreations).",
+	}, []string{"kind"})
+	churnRejections = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "dss_rid_isa_churn_rejections_total",
+		Help: "Number of writes of churning ISAs rejected with 429 Too Many Requests.",
+	})
+)
+
+// ChurnDetector detects the ISAs written pathologically often through the
+// remote ID APIs: updated more than MaxUpdates times per minute, or created
+// again after being deleted more than MaxRecreations times per minute. Each
+// detection is counted in dss_rid_isa_churn_detections_total and logged. If
+// Cooldown is set, the further writes of a churning ISA are rejected with 429
+// Too Many Requests for Cooldown. Writes are only counted by the instance
+// serving them.
+type ChurnDetector struct {
+	// MaxUpdates is the number of updates of an ISA per minute beyond which
+	// it churns; unbounded if 0.
+	MaxUpdates int
+	// MaxRecreations is the number of creations of an ISA deleted less than a
+	// minute before, per minute, beyond which it churns; unbounded if 0.
+	MaxRecreations int
+	// Cooldown is how long the writes of a churning ISA are rejected, never
+	// if 0.
+	Cooldown time.Duration
+	// Logger logs the detections.
+	Logger *zap.Logger
+
+	// now returns the current time, time.Now unless tested.
+	now func() time.Time
+
+	mu        sync.Mutex
+	isas      map[string]*isaChurn
+	lastSweep time.Time
+}
+
+// isaChurn are the recent writes of an ISA.
+type isaChurn struct {
+	updates      []time.Time
+	recreations  []time.Time
+	deletedAt    time.Time
+	blockedUntil time.Time
+}
+
+// Validate returns an error if d cannot be enforced.
+func (d *ChurnDetector) Validate() error {
+	if d.MaxUpdates < 0 || d.MaxRecreations < 0 || d.Cooldown < 0 {
+		return stacktrace.NewError("ISA churn thresholds and cooldown must not be negative")
+	}
+	if d.Cooldown > 0 && d.MaxUpdates == 0 && d.MaxRecreations == 0 {
+		return stacktrace.NewError("ISA churn cooldown requires a maximum number of updates or recreations per minute")
+	}
+	return nil
+}
+
+// Middleware returns an http.Handler counting the successful writes of ISAs
+// passed to next, and rejecting those of churning ISAs during their cooldown.
+// A nil d passes all requests to next.
+func (d *ChurnDetector) Middleware(next http.Handler) http.Handler {
+	if d == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		match := isaPathPattern.FindStringSubmatch(r.URL.Path)
+		if match == nil || (r.Method != http.MethodPut && r.Method != http.MethodDelete) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		id := match[1]
+		if remaining := d.blocked(id); remaining > 0 {
+			churnRejections.Inc()
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+			api.WriteJSON(w, http.StatusTooManyRequests, map[string]string{
+				"message": fmt.Sprintf("ISA %s is written too often; retry later", id)})
+			return
+		}
+
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+		if sw.status != http.StatusOK {
+			return
+		}
+		switch {
+		case r.Method == http.MethodDelete:
+			d.record(id, func(c *isaChurn, now time.Time) string {
+				c.deletedAt = now
+				return ""
+			})
+		case match[2] != "":
+			d.record(id, func(c *isaChurn, now time.Time) string {
+				c.updates = append(recent(c.updates, now), now)
+				if d.MaxUpdates > 0 && len(c.updates) > d.MaxUpdates {
+					return "updates"
+				}
+				return ""
+			})
+		default:
+			d.record(id, func(c *isaChurn, now time.Time) string {
+				if c.deletedAt.IsZero() || now.Sub(c.deletedAt) >= churnWindow {
+					return ""
+				}
+				c.recreations = append(recent(c.recreations, now), now)
+				if d.MaxRecreations > 0 && len(c.recreations) > d.MaxRecreations {
+					return "recreations"
+				}
+				return ""
+			})
+		}
+	})
+}
+
+// blocked returns how long the writes of the ISA identified by id are still
+// rejected, 0 if they are accepted.
+func (d *ChurnDetector) blocked(id string) time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if c, ok := d.isas[id]; ok {
+		return c.blockedUntil.Sub(d.currentTime())
+	}
+	return 0
+}
+
+// record applies a successful write to the churn of the ISA identified by
+// id, which returns the kind of churn detected, if any.
+func (d *ChurnDetector) record(id string, write func(c *isaChurn, now time.Time) string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.currentTime()
+	d.sweep(now)
+	if d.isas == nil {
+		d.isas = map[string]*isaChurn{}
+	}
+	c, ok := d.isas[id]
+	if !ok {
+		c = &isaChurn{}
+		d.isas[id] = c
+	}
+	kind := write(c, now)
+	if kind == "" {
+		return
+	}
+
+	churnDetections.WithLabelValues(kind).Inc()
+	if d.Logger != nil {
+		d.Logger.Warn("ISA churn detected", zap.String("isa_id", id), zap.String("kind", kind),
+			zap.Int("updates", len(c.updates)), zap.Int("recreations", len(c.recreations)), zap.Duration("cooldown", d.Cooldown))
+	}
+	// The writes counted are not counted again after the cooldown.
+	c.updates, c.recreations = nil, nil
+	if d.Cooldown > 0 {
+		c.blockedUntil = now.Add(d.Cooldown)
+	}
+}
+
+// sweep forgets the ISAs neither written within the last churnWindow nor
+// blocked, at most once per churnWindow.
+func (d *ChurnDetector) sweep(now time.Time) {
+	if now.Sub(d.lastSweep) < churnWindow {
+		return
+	}
+	d.lastSweep = now
+	for id, c := range d.isas {
+		if len(recent(c.updates, now)) == 0 && len(recent(c.recreations, now)) == 0 &&
+			now.Sub(c.deletedAt) >= churnWindow && !c.blockedUntil.After(now) {
+			delete(d.isas, id)
+		}
+	}
+}
+
+func (d *ChurnDetector) currentTime() time.Time {
+	if d.now != nil {
+		return d.now()
+	}
+	return time.Now()
+}
+
+// recent returns the times within the last churnWindow of now.
+func recent(times []time.Time, now time.Time) []time.Time {
+	i := 0
+	for i < len(times) && now.Sub(times[i]) >= churnWindow {
+		i++
+	}
+	return times[i:]
+}
+
+// statusWriter records the status of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChurnDetectorValidate(t *testing.T) {
+	require.NoError(t, (&ChurnDetector{}).Validate())
+	require.NoError(t, (&ChurnDetector{MaxUpdates: 10, Cooldown: time.Minute}).Validate())
+	require.Error(t, (&ChurnDetector{MaxUpdates: -1}).Validate())
+	require.Error(t, (&ChurnDetector{Cooldown: time.Minute}).Validate())
+}
+
+func TestChurnDetectorMiddleware(t *testing.T) {
+	const (
+		isa    = "/rid/v2/dss/identification_service_areas/4348c8e5-0b1c-43cf-9114-2e67a4532765"
+		other  = "/v1/dss/identification_service_areas/a3cde7e1-bc1c-4a95-bc94-0e2ba0e0dbbb"
+		status = http.StatusOK
+	)
+	var (
+		now      = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		detector = &ChurnDetector{MaxUpdates: 2, MaxRecreations: 1, Cooldown: 30 * time.Second, now: func() time.Time { return now }}
+		served   int
+		handler  = detector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			served++
+			_, _ = w.Write([]byte("{}"))
+		}))
+		write = func(method, path string) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+			return rec
+		}
+	)
+
+	// A third update within a minute makes the ISA churn.
+	require.Equal(t, status, write(http.MethodPut, isa).Code)
+	require.Equal(t, status, write(http.MethodPut, isa+"/v1").Code)
+	require.Equal(t, status, write(http.MethodPut, isa+"/v2").Code)
+	require.Equal(t, status, write(http.MethodPut, isa+"/v3").Code)
+	rec := write(http.MethodPut, isa+"/v4")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "30", rec.Header().Get("Retry-After"))
+	require.Equal(t, 4, served)
+
+	// Reads and other ISAs are not affected.
+	require.Equal(t, status, write(http.MethodGet, isa).Code)
+	require.Equal(t, status, write(http.MethodPut, other+"/v1").Code)
+
+	// Updates spread over more than a minute do not churn.
+	now = now.Add(30 * time.Second)
+	for i := 0; i < 4; i++ {
+		require.Equal(t, status, write(http.MethodPut, isa+"/v5").Code)
+		now = now.Add(40 * time.Second)
+	}
+
+	// Creating the ISA again twice within a minute of its deletion makes it
+	// churn.
+	require.Equal(t, status, write(http.MethodDelete, other+"/v1").Code)
+	require.Equal(t, status, write(http.MethodPut, other).Code)
+	require.Equal(t, status, write(http.MethodDelete, other+"/v2").Code)
+	require.Equal(t, status, write(http.MethodPut, other).Code)
+	require.Equal(t, http.StatusTooManyRequests, write(http.MethodDelete, other+"/v3").Code)
+
+	// Without a cooldown, churn is only detected.
+	detector.Cooldown = 0
+	now = now.Add(time.Minute)
+	for i := 0; i < 5; i++ {
+		require.Equal(t, status, write(http.MethodPut, isa+"/v6").Code)
+	}
+
+	var none *ChurnDetector
+	rec = httptest.NewRecorder()
+	none.Middleware(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodPut, isa, nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
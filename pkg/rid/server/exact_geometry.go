@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/interuss/dss/pkg/geo"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+)
+
+// ExactGeometryHeader is the request header through which a client searching
+// ISAs may have the ISAs whose footprint does not intersect the search area
+// filtered out, although the cells covering both do.
+const ExactGeometryHeader = "DSS-Exact-Geometry"
+
+type exactGeometryKey struct{}
+
+// ExactGeometryMiddleware returns an http.Handler making the value of the
+// ExactGeometryHeader of requests available to next through ExactGeometry.
+func ExactGeometryMiddleware(next http.Handler) http.Handler {
+	return boolHeaderMiddleware(ExactGeometryHeader, exactGeometryKey{}, next)
+}
+
+// ExactGeometry returns whether the client of the request in ctx asked for
+// search results to be filtered by their exact geometry.
+func ExactGeometry(ctx context.Context) bool {
+	exact, _ := ctx.Value(exactGeometryKey{}).(bool)
+	return exact
+}
+
+// FilterISAsByArea returns the ISAs of isas whose footprint intersects area,
+// in the format of geo.AreaToCellIDs, if the client of the request in ctx
+// asked for it, and isas otherwise. ISAs stored without footprint are kept.
+func FilterISAsByArea(ctx context.Context, area string, isas []*ridmodels.IdentificationServiceArea) ([]*ridmodels.IdentificationServiceArea, error) {
+	if !ExactGeometry(ctx) {
+		return isas, nil
+	}
+	searchArea, err := geo.ParseSearchArea(area)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	result := make([]*ridmodels.IdentificationServiceArea, 0, len(isas))
+	for _, isa := range isas {
+		if dssmodels.IntersectsSearchArea(isa.Footprint, searchArea) {
+			result = append(result, isa)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/interuss/dss/pkg/api"
+)
+
+// ExcludeSelfHeader is the request header through which a client searching
+// ISAs may exclude its own ISAs from the results, the standard search API
+// having no such parameter.
+const ExcludeSelfHeader = "DSS-Exclude-Self"
+
+type excludeSelfKey struct{}
+
+// ExcludeSelfMiddleware returns an http.Handler making the value of the
+// ExcludeSelfHeader of requests available to next through ExcludeSelf.
+func ExcludeSelfMiddleware(next http.Handler) http.Handler {
+	return boolHeaderMiddleware(ExcludeSelfHeader, excludeSelfKey{}, next)
+}
+
+// boolHeaderMiddleware returns an http.Handler storing the boolean value of
+// header, if present in requests, in their context under key, and rejecting
+// the requests where it is not a boolean.
+func boolHeaderMiddleware(header string, key interface{}, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v := r.Header.Get(header)
+		if v == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			api.WriteJSON(w, http.StatusBadRequest, map[string]string{
+				"message": fmt.Sprintf("Invalid %s header `%s`: expected true or false", header, v)})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), key, b)))
+	})
+}
+
+// ExcludeSelf returns whether the client of the request in ctx asked for its
+// own ISAs to be excluded from search results.
+func ExcludeSelf(ctx context.Context) bool {
+	exclude, _ := ctx.Value(excludeSelfKey{}).(bool)
+	return exclude
+}
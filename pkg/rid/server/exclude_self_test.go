@@ -0,0 +1,40 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExcludeSelfMiddleware(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		header  string
+		status  int
+		exclude bool
+	}{
+		{"absent", "", http.StatusOK, false},
+		{"true", "true", http.StatusOK, true},
+		{"false", "false", http.StatusOK, false},
+		{"invalid", "me", http.StatusBadRequest, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				exclude bool
+				rec     = httptest.NewRecorder()
+				req     = httptest.NewRequest(http.MethodGet, "/v1/dss/identification_service_areas", nil)
+			)
+			if tc.header != "" {
+				req.Header.Set(ExcludeSelfHeader, tc.header)
+			}
+			ExcludeSelfMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				exclude = ExcludeSelf(r.Context())
+			})).ServeHTTP(rec, req)
+
+			require.Equal(t, tc.status, rec.Code)
+			require.Equal(t, tc.exclude, exclude)
+		})
+	}
+}
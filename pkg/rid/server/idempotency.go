@@ -0,0 +1,207 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	"github.com/interuss/dss/pkg/logging"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+const (
+	// IdempotencyKeyHeader is the header of the writes identifying them
+	// across retries.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on the responses replayed to the
+	// retries of a write.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotencyKeyLength is the maximum length of idempotency keys.
+	maxIdempotencyKeyLength = 255
+)
+
+var (
+	// writablePathPattern matches the paths of the ISAs and subscriptions of
+	// the remote ID APIs.
+	writablePathPattern = regexp.MustCompile(`^(?:/rid/v2|/v1)/dss/(?:identification_service_areas|subscriptions)/[^/]+(?:/[^/]+)?$`)
+
+	idempotentReplays = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "dss_rid_idempotent_replays_total",
+		Help: "Number of retried writes answered with the response to their idempotency key.",
+	})
+)
+
+// IdempotentResponses stores the responses to the writes sent with
+// idempotency keys.
+type IdempotentResponses interface {
+	// GetIdempotentResponse returns the unexpired response recorded for the
+	// idempotency key of owner, nil if there is none.
+	GetIdempotentResponse(ctx context.Context, owner dssmodels.Owner, key string) (*ridmodels.IdempotentResponse, error)
+	// RecordIdempotentResponse records resp, unless an unexpired response is
+	// already recorded for its idempotency key.
+	RecordIdempotentResponse(ctx context.Context, resp *ridmodels.IdempotentResponse) error
+}
+
+// Idempotency makes the writes of ISAs and subscriptions sent with an
+// Idempotency-Key header safe to retry: the successful response to a write is
+// recorded for Retention, and a retry of the write with the same key which
+// conflicts with the write itself, i.e. fails with 409 Conflict or, for
+// deletions, 404 Not Found, is answered with that response instead. A key
+// reused by its owner for another request is rejected with 422 Unprocessable
+// Entity. Keys are scoped to the owner of the request, recorded in its
+// logging context while authorizing it, so the middleware must be installed
+// within logging.HTTPMiddleware.
+type Idempotency struct {
+	Store     IdempotentResponses
+	Retention time.Duration
+	// Logger logs the failures to record or look up responses, which leave
+	// the responses of the writes unchanged.
+	Logger *zap.Logger
+}
+
+// validIdempotencyKey returns whether key may identify a write.
+func validIdempotencyKey(key string) bool {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// requestHash returns the hash identifying the write r with body.
+func requestHash(r *http.Request, body []byte) []byte {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s %s\n", r.Method, r.URL.Path)
+	_, _ = h.Write(body)
+	return h.Sum(nil)
+}
+
+// Middleware returns an http.Handler recording the successful responses of
+// the writes passed to next with an idempotency key, and replaying them to
+// the retries of these writes. A nil i passes all requests to next.
+func (i *Idempotency) Middleware(next http.Handler) http.Handler {
+	if i == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || !writablePathPattern.MatchString(r.URL.Path) ||
+			(r.Method != http.MethodPut && r.Method != http.MethodPost && r.Method != http.MethodDelete) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !validIdempotencyKey(key) {
+			api.WriteJSON(w, http.StatusBadRequest, map[string]string{
+				"message": fmt.Sprintf("%s must be 1 to %d printable ASCII characters", IdempotencyKeyHeader, maxIdempotencyKeyLength)})
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			api.WriteJSON(w, http.StatusBadRequest, map[string]string{"message": "Error reading request body"})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		hash := requestHash(r, body)
+
+		bw := &bufferedWriter{header: make(http.Header)}
+		next.ServeHTTP(bw, r)
+		if bw.status == 0 {
+			bw.status = http.StatusOK
+		}
+		owner, ok := logging.StringField(r.Context(), "owner")
+		if !ok {
+			bw.flush(w)
+			return
+		}
+
+		switch {
+		case bw.status >= 200 && bw.status < 300:
+			if err := i.Store.RecordIdempotentResponse(r.Context(), &ridmodels.IdempotentResponse{
+				Owner:       dssmodels.Owner(owner),
+				Key:         key,
+				RequestHash: hash,
+				Status:      bw.status,
+				Body:        bw.body.Bytes(),
+				ExpiresAt:   time.Now().Add(i.Retention),
+			}); err != nil {
+				i.warn("Failed to record idempotent response", key, err)
+			}
+		case bw.status == http.StatusConflict || (bw.status == http.StatusNotFound && r.Method == http.MethodDelete):
+			recorded, err := i.Store.GetIdempotentResponse(r.Context(), dssmodels.Owner(owner), key)
+			if err != nil {
+				i.warn("Failed to get idempotent response", key, err)
+				break
+			}
+			if recorded == nil {
+				break
+			}
+			if !bytes.Equal(recorded.RequestHash, hash) {
+				api.WriteJSON(w, http.StatusUnprocessableEntity, map[string]string{
+					"message": fmt.Sprintf("%s %s was used for another request", IdempotencyKeyHeader, key)})
+				return
+			}
+			idempotentReplays.Inc()
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(recorded.Status)
+			_, _ = w.Write(recorded.Body)
+			return
+		}
+		bw.flush(w)
+	})
+}
+
+func (i *Idempotency) warn(msg string, key string, err error) {
+	if i.Logger != nil {
+		i.Logger.Warn(msg, zap.String("idempotency_key", key), zap.Error(err))
+	}
+}
+
+// bufferedWriter buffers a response until it is known whether it is sent or
+// replaced by a recorded response.
+type bufferedWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+// flush sends the response buffered to w.
+func (w *bufferedWriter) flush(rw http.ResponseWriter) {
+	for k, v := range w.header {
+		rw.Header()[k] = v
+	}
+	rw.WriteHeader(w.status)
+	_, _ = rw.Write(w.body.Bytes())
+}
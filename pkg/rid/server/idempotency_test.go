@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/interuss/dss/pkg/logging"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeIdempotentResponses stores idempotent responses in memory.
+type fakeIdempotentResponses map[string]*ridmodels.IdempotentResponse
+
+func (f fakeIdempotentResponses) GetIdempotentResponse(ctx context.Context, owner dssmodels.Owner, key string) (*ridmodels.IdempotentResponse, error) {
+	return f[owner.String()+"/"+key], nil
+}
+
+func (f fakeIdempotentResponses) RecordIdempotentResponse(ctx context.Context, resp *ridmodels.IdempotentResponse) error {
+	if _, ok := f[resp.Owner.String()+"/"+resp.Key]; !ok {
+		f[resp.Owner.String()+"/"+resp.Key] = resp
+	}
+	return nil
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	const isa = "/rid/v2/dss/identification_service_areas/4348c8e5-0b1c-43cf-9114-2e67a4532765"
+	var (
+		store   = fakeIdempotentResponses{}
+		created bool
+		handler = (&Idempotency{Store: store, Retention: time.Hour}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logging.WithFields(r.Context(), zap.String("owner", r.Header.Get("X-Owner")))
+			if created {
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(`{"message":"already exists"}`))
+				return
+			}
+			created = true
+			_, _ = w.Write([]byte(`{"service_area":{"version":"v1"}}`))
+		}))
+		write = func(owner, key, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPut, isa, strings.NewReader(body))
+			req = req.WithContext(logging.NewContext(req.Context()))
+			req.Header.Set("X-Owner", owner)
+			if key != "" {
+				req.Header.Set(IdempotencyKeyHeader, key)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			return rec
+		}
+	)
+
+	rec := write("uss1", "retry-1", `{"extents":{}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, rec.Header().Get(IdempotentReplayedHeader))
+
+	// The retry is answered with the original response.
+	rec = write("uss1", "retry-1", `{"extents":{}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "true", rec.Header().Get(IdempotentReplayedHeader))
+	require.JSONEq(t, `{"service_area":{"version":"v1"}}`, rec.Body.String())
+
+	// The key of another request, or of another owner, is not replayed.
+	require.Equal(t, http.StatusUnprocessableEntity, write("uss1", "retry-1", `{"extents":{"other":true}}`).Code)
+	require.Equal(t, http.StatusConflict, write("uss2", "retry-1", `{"extents":{}}`).Code)
+	require.Equal(t, http.StatusConflict, write("uss1", "", `{"extents":{}}`).Code)
+
+	require.Equal(t, http.StatusBadRequest, write("uss1", strings.Repeat("k", 256), `{}`).Code)
+	require.Equal(t, http.StatusBadRequest, write("uss1", "new\tline", `{}`).Code)
+}
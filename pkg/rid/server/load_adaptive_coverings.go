@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/golang/geo/s2"
+	"github.com/interuss/dss/pkg/geo"
+	"github.com/interuss/dss/pkg/logging"
+	"github.com/interuss/stacktrace"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var (
+	inFlightRequests = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "dss_rid_in_flight_requests",
+		Help: "Number of requests in flight from which the coarsening of search coverings is derived.",
+	})
+	coveringCoarseningLevels = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "dss_rid_search_covering_coarsening_levels",
+		Help: "Number of cell levels by which the coverings of remote ID searches are currently coarsened, 0 if they are not.",
+	})
+	coarsenedSearches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dss_rid_coarsened_searches_total",
+		Help: "Number of remote ID searches whose covering was coarsened under load, by number of cell levels.",
+	}, []string{"levels"})
+)
+
+type coarseningLevelsKey struct{}
+
+// LoadAdaptiveCoverings degrades remote ID searches gracefully under load:
+// beyond Threshold requests in flight, the coverings of the searched areas
+// are coarsened by one cell level, and by one more level for each further
+// Threshold requests, up to MaxLevels. Coarser coverings have fewer cells,
+// which are cheaper to search, but cover a larger area, so that searches may
+// return entities a little outside the area searched. Coverings are never
+// coarsened beyond the minimum cell level, so coverings must mix cell levels
+// for searches to be coarsened at all.
+type LoadAdaptiveCoverings struct {
+	// Threshold is the number of requests in flight beyond which searches
+	// are coarsened; never if 0.
+	Threshold int
+	// MaxLevels is the largest number of levels by which searches are
+	// coarsened; bounded by the cell levels of coverings alone if 0.
+	MaxLevels int
+
+	inFlight atomic.Int64
+}
+
+// Validate returns an error if l cannot be enforced with the coverings
+// configured in package geo.
+func (l *LoadAdaptiveCoverings) Validate() error {
+	if l.Threshold < 0 || l.MaxLevels < 0 {
+		return stacktrace.NewError("Load-adaptive covering threshold and maximum levels must not be negative")
+	}
+	if l.Threshold > 0 && !geo.MultiLevelCoverings() {
+		return stacktrace.NewError("Load-adaptive coverings require coverings of several cell levels")
+	}
+	return nil
+}
+
+// levels returns the number of levels by which searches are coarsened with
+// inFlight requests in flight.
+func (l *LoadAdaptiveCoverings) levels(inFlight int64) int {
+	if inFlight <= int64(l.Threshold) {
+		return 0
+	}
+	levels := int((inFlight - 1) / int64(l.Threshold))
+	if l.MaxLevels > 0 && levels > l.MaxLevels {
+		levels = l.MaxLevels
+	}
+	return levels
+}
+
+// Middleware returns an http.Handler counting the requests in flight through
+// it and making the number of levels by which searches are coarsened when
+// they arrive available to next through CoarsenSearchCovering. A nil l
+// passes all requests to next.
+func (l *LoadAdaptiveCoverings) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := l.inFlight.Add(1)
+		inFlightRequests.Set(float64(n))
+		levels := l.levels(n)
+		coveringCoarseningLevels.Set(float64(levels))
+		defer func() {
+			n := l.inFlight.Add(-1)
+			inFlightRequests.Set(float64(n))
+			coveringCoarseningLevels.Set(float64(l.levels(n)))
+		}()
+		if levels > 0 {
+			r = r.WithContext(context.WithValue(r.Context(), coarseningLevelsKey{}, levels))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CoarsenSearchCovering returns the covering of the area searched by the
+// request in ctx, cells, coarsened according to the load when the request
+// arrived, if at all.
+func CoarsenSearchCovering(ctx context.Context, cells s2.CellUnion) s2.CellUnion {
+	levels, _ := ctx.Value(coarseningLevelsKey{}).(int)
+	if levels <= 0 {
+		return cells
+	}
+	coarse := geo.CoarsenCovering(cells, levels)
+	if len(coarse) < len(cells) {
+		coarsenedSearches.WithLabelValues(strconv.Itoa(levels)).Inc()
+		logging.WithFields(ctx, zap.Int("covering_coarsened_levels", levels), zap.Int("covering_cells", len(coarse)))
+	}
+	return coarse
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/interuss/dss/pkg/geo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAdaptiveCoveringsValidate(t *testing.T) {
+	require.NoError(t, (&LoadAdaptiveCoverings{}).Validate())
+	require.Error(t, (&LoadAdaptiveCoverings{Threshold: -1}).Validate())
+	require.Error(t, (&LoadAdaptiveCoverings{Threshold: 10}).Validate())
+
+	previous := geo.RegionCoverer
+	t.Cleanup(func() { geo.RegionCoverer = previous })
+	require.NoError(t, geo.ConfigureCoverings(8, 18, 64))
+	require.NoError(t, (&LoadAdaptiveCoverings{Threshold: 10}).Validate())
+	require.Error(t, (&LoadAdaptiveCoverings{Threshold: 10, MaxLevels: -1}).Validate())
+}
+
+func TestLoadAdaptiveCoveringsLevels(t *testing.T) {
+	l := &LoadAdaptiveCoverings{Threshold: 10, MaxLevels: 2}
+	require.Equal(t, 0, l.levels(10))
+	require.Equal(t, 1, l.levels(11))
+	require.Equal(t, 1, l.levels(20))
+	require.Equal(t, 2, l.levels(21))
+	require.Equal(t, 2, l.levels(100))
+}
+
+func TestLoadAdaptiveCoveringsMiddleware(t *testing.T) {
+	previous := geo.RegionCoverer
+	t.Cleanup(func() { geo.RegionCoverer = previous })
+	require.NoError(t, geo.ConfigureCoverings(8, 18, 64))
+	cells, err := geo.CircleCovering(s2.LatLngFromDegrees(37.4, -122.1), 5000)
+	require.NoError(t, err)
+
+	var (
+		release  = make(chan struct{})
+		started  sync.WaitGroup
+		finished sync.WaitGroup
+		searched s2.CellUnion
+		handler  = (&LoadAdaptiveCoverings{Threshold: 2}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/busy" {
+				started.Done()
+				<-release
+				return
+			}
+			searched = CoarsenSearchCovering(r.Context(), cells)
+		}))
+		search = func() s2.CellUnion {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/search", nil))
+			return searched
+		}
+	)
+
+	// Up to the threshold, searches are not coarsened.
+	require.Equal(t, cells, search())
+	started.Add(2)
+	finished.Add(2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			defer finished.Done()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/busy", nil))
+		}()
+	}
+	started.Wait()
+	coarse := search()
+	require.Less(t, len(coarse), len(cells))
+	require.True(t, coarse.Contains(cells))
+
+	close(release)
+	finished.Wait()
+	require.Equal(t, cells, search())
+
+	// Without the middleware, searches are never coarsened.
+	require.Equal(t, cells, CoarsenSearchCovering(context.Background(), cells))
+}
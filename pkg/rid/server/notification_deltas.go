@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+)
+
+// NotificationDeltasHeader is the response header listing, for each
+// subscription whose notification index a write incremented, its index before
+// and after the write, as comma-separated <subscription_id>:<previous>:<new>
+// entries. The standard subscription states only carry the new index, from
+// which the notifying USS cannot tell whether notifications were missed.
+const NotificationDeltasHeader = "DSS-Notification-Deltas"
+
+// notificationDeltas are the notification index increments of a request.
+type notificationDeltas struct {
+	entries []string
+}
+
+type notificationDeltasKey struct{}
+
+// NotificationDeltasMiddleware returns an http.Handler setting the
+// NotificationDeltasHeader in the responses of next to the increments
+// recorded with RecordNotificationDeltas.
+func NotificationDeltasMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d := &notificationDeltas{}
+		next.ServeHTTP(&notificationDeltasWriter{ResponseWriter: w, deltas: d},
+			r.WithContext(context.WithValue(r.Context(), notificationDeltasKey{}, d)))
+	})
+}
+
+// RecordNotificationDeltas records the notification index increments of subs,
+// as returned by the updates of notification indices, for the response to the
+// request in ctx.
+func RecordNotificationDeltas(ctx context.Context, subs []*ridmodels.Subscription) {
+	d, ok := ctx.Value(notificationDeltasKey{}).(*notificationDeltas)
+	if !ok {
+		return
+	}
+	for _, sub := range subs {
+		d.entries = append(d.entries, fmt.Sprintf("%s:%d:%d", sub.ID, sub.PreviousNotificationIndex, sub.NotificationIndex))
+	}
+}
+
+// notificationDeltasWriter sets NotificationDeltasHeader in responses to
+// writes which incremented notification indices.
+type notificationDeltasWriter struct {
+	http.ResponseWriter
+	deltas      *notificationDeltas
+	wroteHeader bool
+}
+
+func (w *notificationDeltasWriter) WriteHeader(code int) {
+	if !w.wroteHeader && len(w.deltas.entries) > 0 {
+		w.Header().Set(NotificationDeltasHeader, strings.Join(w.deltas.entries, ","))
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *notificationDeltasWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationDeltasMiddleware(t *testing.T) {
+	serve := func(subs []*ridmodels.Subscription) http.Header {
+		rec := httptest.NewRecorder()
+		NotificationDeltasMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			RecordNotificationDeltas(r.Context(), subs)
+			_, _ = w.Write([]byte("{}"))
+		})).ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/rid/v2/dss/identification_service_areas", nil))
+		return rec.Header()
+	}
+
+	require.Equal(t, "a3cde7e1-bc1c-4a95-bc94-0e2ba0e0dbbb:4:5,4348c8e5-0b1c-43cf-9114-2e67a4532765:0:1", serve([]*ridmodels.Subscription{
+		{ID: dssmodels.ID("a3cde7e1-bc1c-4a95-bc94-0e2ba0e0dbbb"), PreviousNotificationIndex: 4, NotificationIndex: 5},
+		{ID: dssmodels.ID("4348c8e5-0b1c-43cf-9114-2e67a4532765"), PreviousNotificationIndex: 0, NotificationIndex: 1},
+	}).Get(NotificationDeltasHeader))
+	require.Empty(t, serve(nil).Values(NotificationDeltasHeader))
+
+	// Requests not going through the middleware are left alone.
+	RecordNotificationDeltas(context.Background(), []*ridmodels.Subscription{{ID: dssmodels.ID("a3cde7e1-bc1c-4a95-bc94-0e2ba0e0dbbb")}})
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/interuss/dss/pkg/logging"
+	"github.com/interuss/stacktrace"
+)
+
+// rejectionBucket is the period over which the rejections of an owner are
+// counted together.
+const rejectionBucket = time.Minute
+
+// RejectionCounter counts the requests of each owner rejected with a 4xx
+// status over the last Window, so that owners can find out why their requests
+// fail, e.g. because of a quota, without asking the operator of the DSS for
+// its logs. Requests are counted by the instance serving them, by the minute,
+// and only once their owner is known, i.e. once their access token has been
+// authorized: the owner is read from the logging context of the request, so
+// the middleware must be installed within logging.HTTPMiddleware.
+type RejectionCounter struct {
+	Window time.Duration
+
+	// now returns the current time, time.Now unless tested.
+	now func() time.Time
+
+	mu        sync.Mutex
+	owners    map[string][]*rejections
+	lastSweep time.Time
+}
+
+// rejections are the rejections of an owner during the minute starting at
+// start, by status.
+type rejections struct {
+	start    time.Time
+	statuses map[int]int
+}
+
+// Validate returns an error if c cannot count rejections.
+func (c *RejectionCounter) Validate() error {
+	if c.Window < rejectionBucket {
+		return stacktrace.NewError("Rejection counting window must be at least %s, got %s", rejectionBucket, c.Window)
+	}
+	return nil
+}
+
+// Middleware returns an http.Handler counting the requests passed to next
+// rejected with a 4xx status. A nil c passes all requests to next.
+func (c *RejectionCounter) Middleware(next http.Handler) http.Handler {
+	if c == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+		if sw.status < http.StatusBadRequest || sw.status >= http.StatusInternalServerError {
+			return
+		}
+		if owner, ok := logging.StringField(r.Context(), "owner"); ok {
+			c.record(owner, sw.status)
+		}
+	})
+}
+
+func (c *RejectionCounter) record(owner string, status int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.currentTime()
+	c.sweep(now)
+	if c.owners == nil {
+		c.owners = map[string][]*rejections{}
+	}
+	start := now.Truncate(rejectionBucket)
+	buckets := c.recent(c.owners[owner], now)
+	if len(buckets) == 0 || !buckets[len(buckets)-1].start.Equal(start) {
+		buckets = append(buckets, &rejections{start: start, statuses: map[int]int{}})
+	}
+	buckets[len(buckets)-1].statuses[status]++
+	c.owners[owner] = buckets
+}
+
+// Counts returns the number of requests of owner rejected over the last
+// Window, by status.
+func (c *RejectionCounter) Counts(owner string) map[int]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := map[int]int{}
+	for _, b := range c.recent(c.owners[owner], c.currentTime()) {
+		for status, n := range b.statuses {
+			counts[status] += n
+		}
+	}
+	return counts
+}
+
+// recent returns the buckets of the last Window.
+func (c *RejectionCounter) recent(buckets []*rejections, now time.Time) []*rejections {
+	i := 0
+	for i < len(buckets) && now.Sub(buckets[i].start) >= c.Window {
+		i++
+	}
+	return buckets[i:]
+}
+
+// sweep forgets the owners without rejections within the last Window, at
+// most once per Window.
+func (c *RejectionCounter) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < c.Window {
+		return
+	}
+	c.lastSweep = now
+	for owner, buckets := range c.owners {
+		if len(c.recent(buckets, now)) == 0 {
+			delete(c.owners, owner)
+		}
+	}
+}
+
+func (c *RejectionCounter) currentTime() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/interuss/dss/pkg/logging"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRejectionCounterValidate(t *testing.T) {
+	require.NoError(t, (&RejectionCounter{Window: time.Hour}).Validate())
+	require.Error(t, (&RejectionCounter{}).Validate())
+	require.Error(t, (&RejectionCounter{Window: time.Second}).Validate())
+}
+
+func TestRejectionCounterMiddleware(t *testing.T) {
+	var (
+		now     = time.Date(2024, 1, 1, 0, 0, 30, 0, time.UTC)
+		counter = &RejectionCounter{Window: 10 * time.Minute, now: func() time.Time { return now }}
+		handler = counter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if owner := r.Header.Get("X-Owner"); owner != "" {
+				logging.WithFields(r.Context(), zap.String("owner", owner))
+			}
+			w.WriteHeader(map[string]int{
+				"/quota":    http.StatusTooManyRequests,
+				"/invalid":  http.StatusBadRequest,
+				"/broken":   http.StatusInternalServerError,
+				"/accepted": http.StatusOK,
+			}[r.URL.Path])
+		}))
+		request = func(owner, path string) {
+			req := httptest.NewRequest(http.MethodPut, path, nil)
+			req = req.WithContext(logging.NewContext(req.Context()))
+			req.Header.Set("X-Owner", owner)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}
+	)
+
+	request("uss1", "/quota")
+	request("uss1", "/quota")
+	request("uss1", "/invalid")
+	request("uss1", "/accepted")
+	request("uss1", "/broken")
+	request("uss2", "/invalid")
+	request("", "/invalid")
+	require.Equal(t, map[int]int{http.StatusTooManyRequests: 2, http.StatusBadRequest: 1}, counter.Counts("uss1"))
+	require.Equal(t, map[int]int{http.StatusBadRequest: 1}, counter.Counts("uss2"))
+	require.Empty(t, counter.Counts("uss3"))
+
+	// Rejections are forgotten once out of the window.
+	now = now.Add(5 * time.Minute)
+	request("uss1", "/quota")
+	require.Equal(t, map[int]int{http.StatusTooManyRequests: 3, http.StatusBadRequest: 1}, counter.Counts("uss1"))
+	now = now.Add(5 * time.Minute)
+	require.Equal(t, map[int]int{http.StatusTooManyRequests: 1}, counter.Counts("uss1"))
+	require.Empty(t, counter.Counts("uss2"))
+
+	// Owners without recent rejections are swept.
+	now = now.Add(10 * time.Minute)
+	request("uss1", "/invalid")
+	require.Len(t, counter.owners, 1)
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"context"
+
+	"github.com/golang/geo/s2"
+	"github.com/interuss/dss/pkg/geo"
+	"github.com/interuss/dss/pkg/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var searches = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "dss_rid_searches_total",
+	Help: "Number of remote ID searches, by entity searched, sampling the hash of the searched cells as the area_hash exemplar label.",
+}, []string{"entity"})
+
+// ObserveSearchArea records the search of entity, isa or subscription, in
+// cells: the geo.CellUnionHash of cells is logged with the request of ctx and
+// sampled as an exemplar of dss_rid_searches_total, so that clients repeating
+// the same search can be told from the logs.
+func ObserveSearchArea(ctx context.Context, entity string, cells s2.CellUnion) {
+	hash := geo.CellUnionHash(cells)
+	logging.WithFields(ctx, zap.String("area_hash", hash))
+	searches.WithLabelValues(entity).(prometheus.ExemplarAdder).AddWithExemplar(1, prometheus.Labels{"area_hash": hash})
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/interuss/dss/pkg/geo"
+	"github.com/interuss/dss/pkg/logging"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveSearchArea(t *testing.T) {
+	var (
+		ctx    = logging.NewContext(context.Background())
+		cells  = s2.CellUnion{s2.CellIDFromToken("89c25")}
+		before = testutil.ToFloat64(searches.WithLabelValues("subscription"))
+	)
+
+	ObserveSearchArea(ctx, "subscription", cells)
+	hash, ok := logging.StringField(ctx, "area_hash")
+	require.True(t, ok)
+	require.Equal(t, geo.CellUnionHash(cells), hash)
+	require.Equal(t, before+1, testutil.ToFloat64(searches.WithLabelValues("subscription")))
+}
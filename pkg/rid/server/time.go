@@ -0,0 +1,31 @@
+package server
+
+import (
+	"strings"
+	"time"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+)
+
+// ParseQueryTime parses the value of the time query parameter name, an RFC
+// 3339 timestamp with optional fractional seconds, and returns it in UTC.
+// Beyond what time.RFC3339Nano accepts, the lowercase "t" and "z" and the
+// space separating date and time which RFC 3339 allows are accepted, as is a
+// space in place of the "+" of a positive UTC offset, which clients not
+// escaping it in URLs send once the query is decoded.
+func ParseQueryTime(name, value string) (time.Time, error) {
+	v := []byte(strings.ToUpper(value))
+	if len(v) > len("2006-01-02") && v[len("2006-01-02")] == ' ' {
+		v[len("2006-01-02")] = 'T'
+	}
+	if n := len(v) - len("+07:00"); n >= len("2006-01-02T15:04:05") && v[n] == ' ' && v[n+3] == ':' {
+		v[n] = '+'
+	}
+	t, err := time.Parse(time.RFC3339Nano, string(v))
+	if err != nil {
+		return time.Time{}, stacktrace.NewErrorWithCode(dsserr.BadRequest,
+			"Invalid %s `%s`: expected an RFC 3339 timestamp such as 2006-01-02T15:04:05.999Z or 2006-01-02T17:04:05+02:00", name, value)
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQueryTime(t *testing.T) {
+	want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, tc := range []struct {
+		value string
+		want  time.Time
+	}{
+		{"2024-01-02T03:04:05Z", want},
+		{"2024-01-02T03:04:05.123456789Z", want.Add(123456789)},
+		{"2024-01-02T05:04:05+02:00", want},
+		{"2024-01-02T05:04:05 02:00", want},
+		{"2024-01-01T22:04:05.5-05:00", want.Add(500 * time.Millisecond)},
+		{"2024-01-02t03:04:05z", want},
+		{"2024-01-02 03:04:05Z", want},
+	} {
+		got, err := ParseQueryTime("earliest_time", tc.value)
+		require.NoError(t, err, tc.value)
+		require.Equal(t, tc.want, got, tc.value)
+		require.Equal(t, time.UTC, got.Location(), tc.value)
+	}
+
+	for _, value := range []string{
+		"",
+		"2024-01-02",
+		"2024-01-02T03:04:05",
+		"2024-01-02T03:04Z",
+		"2024-13-02T03:04:05Z",
+		"1704164645",
+		"2024-01-02T03:04:05 0200",
+	} {
+		_, err := ParseQueryTime("earliest_time", value)
+		require.Error(t, err, value)
+		require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err), value)
+	}
+}
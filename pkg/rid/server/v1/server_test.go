@@ -13,6 +13,7 @@ import (
 	"github.com/interuss/dss/pkg/geo"
 	"github.com/interuss/dss/pkg/geo/testdata"
 	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/dss/pkg/rid/application"
 	ridmodels "github.com/interuss/dss/pkg/rid/models"
 	apiv1 "github.com/interuss/dss/pkg/rid/models/api/v1"
 	"github.com/interuss/stacktrace"
@@ -101,13 +102,108 @@ func (ma *mockApp) UpdateISA(ctx context.Context, isa *ridmodels.IdentificationS
 	return args.Get(0).(*ridmodels.IdentificationServiceArea), args.Get(1).([]*ridmodels.Subscription), args.Error(2)
 }
 
-func (ma *mockApp) SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time) ([]*ridmodels.IdentificationServiceArea, error) {
+func (ma *mockApp) SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time, excludeOwner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error) {
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
+	args := ma.Called(ctx, cells, earliest, latest, excludeOwner)
+	return args.Get(0).([]*ridmodels.IdentificationServiceArea), args.Error(1)
+}
+
+func (ma *mockApp) CountISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time) (int, error) {
 	args := ma.Called(ctx, cells, earliest, latest)
+	return args.Int(0), args.Error(1)
+}
+
+func (ma *mockApp) GetActivity(ctx context.Context, period time.Duration, bucket time.Duration) ([]*application.ActivityBucket, error) {
+	args := ma.Called(ctx, period, bucket)
+	return args.Get(0).([]*application.ActivityBucket), args.Error(1)
+}
+
+func (ma *mockApp) ReconcileISAs(ctx context.Context, owner dssmodels.Owner, claimed []application.ClaimedISA, repair bool) (*application.ISAReconciliation, error) {
+	args := ma.Called(ctx, owner, claimed, repair)
+	return args.Get(0).(*application.ISAReconciliation), args.Error(1)
+}
+
+func (ma *mockApp) ExpireISAsByOwner(ctx context.Context, owner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error) {
+	args := ma.Called(ctx, owner)
+	return args.Get(0).([]*ridmodels.IdentificationServiceArea), args.Error(1)
+}
+
+func (ma *mockApp) TransferOwnership(ctx context.Context, from, to dssmodels.Owner, isaIDs, subscriptionIDs []dssmodels.ID) (*application.TransferResult, error) {
+	args := ma.Called(ctx, from, to, isaIDs, subscriptionIDs)
+	return args.Get(0).(*application.TransferResult), args.Error(1)
+}
+
+func (ma *mockApp) ListOwnerAliases(ctx context.Context) ([]*ridmodels.OwnerAlias, error) {
+	args := ma.Called(ctx)
+	return args.Get(0).([]*ridmodels.OwnerAlias), args.Error(1)
+}
+
+func (ma *mockApp) SetOwnerAlias(ctx context.Context, subject dssmodels.Owner, owner dssmodels.Owner) (*ridmodels.OwnerAlias, error) {
+	args := ma.Called(ctx, subject, owner)
+	return args.Get(0).(*ridmodels.OwnerAlias), args.Error(1)
+}
+
+func (ma *mockApp) DeleteOwnerAlias(ctx context.Context, subject dssmodels.Owner) (*ridmodels.OwnerAlias, error) {
+	args := ma.Called(ctx, subject)
+	return args.Get(0).(*ridmodels.OwnerAlias), args.Error(1)
+}
+
+func (ma *mockApp) GetCoverage(ctx context.Context, cells s2.CellUnion, level int) (*application.Coverage, error) {
+	args := ma.Called(ctx, cells, level)
+	return args.Get(0).(*application.Coverage), args.Error(1)
+}
+
+func (ma *mockApp) SearchISAsByURL(ctx context.Context, url string, prefix bool) ([]*ridmodels.IdentificationServiceArea, error) {
+	args := ma.Called(ctx, url, prefix)
 	return args.Get(0).([]*ridmodels.IdentificationServiceArea), args.Error(1)
 }
 
+func (ma *mockApp) SetISALabels(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, labels ridmodels.Labels) (*ridmodels.IdentificationServiceArea, error) {
+	args := ma.Called(ctx, id, owner, labels)
+	return args.Get(0).(*ridmodels.IdentificationServiceArea), args.Error(1)
+}
+
+func (ma *mockApp) SetSubscriptionLabels(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, labels ridmodels.Labels) (*ridmodels.Subscription, error) {
+	args := ma.Called(ctx, id, owner, labels)
+	return args.Get(0).(*ridmodels.Subscription), args.Error(1)
+}
+
+func (ma *mockApp) SetSubscriptionCallbacks(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, urls []string) (*ridmodels.Subscription, error) {
+	args := ma.Called(ctx, id, owner, urls)
+	return args.Get(0).(*ridmodels.Subscription), args.Error(1)
+}
+
+func (ma *mockApp) SetSubscriptionNotificationInterval(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, interval time.Duration) (*ridmodels.Subscription, error) {
+	args := ma.Called(ctx, id, owner, interval)
+	return args.Get(0).(*ridmodels.Subscription), args.Error(1)
+}
+
+func (ma *mockApp) SetSubscriptionSelfNotification(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner, notify bool) (*ridmodels.Subscription, error) {
+	args := ma.Called(ctx, id, owner, notify)
+	return args.Get(0).(*ridmodels.Subscription), args.Error(1)
+}
+
+func (ma *mockApp) GetUsage(ctx context.Context, owner dssmodels.Owner) (*application.Usage, error) {
+	args := ma.Called(ctx, owner)
+	return args.Get(0).(*application.Usage), args.Error(1)
+}
+
+func (ma *mockApp) SearchISAsByLabels(ctx context.Context, labels ridmodels.Labels) ([]*ridmodels.IdentificationServiceArea, error) {
+	args := ma.Called(ctx, labels)
+	return args.Get(0).([]*ridmodels.IdentificationServiceArea), args.Error(1)
+}
+
+func (ma *mockApp) SearchSubscriptionsByURL(ctx context.Context, owner dssmodels.Owner, substring string) ([]*ridmodels.Subscription, error) {
+	args := ma.Called(ctx, owner, substring)
+	return args.Get(0).([]*ridmodels.Subscription), args.Error(1)
+}
+
+func (ma *mockApp) SearchSubscriptionsByLabels(ctx context.Context, labels ridmodels.Labels) ([]*ridmodels.Subscription, error) {
+	args := ma.Called(ctx, labels)
+	return args.Get(0).([]*ridmodels.Subscription), args.Error(1)
+}
+
 func TestDeleteSubscription(t *testing.T) {
 	var respSet restapi.DeleteSubscriptionResponseSet
 	for _, r := range []struct {
@@ -236,7 +332,7 @@ func TestCreateSubscription(t *testing.T) {
 		t.Run(r.name, func(t *testing.T) {
 			ma := &mockApp{}
 			if r.appErr == stacktrace.ErrorCode(0) {
-				ma.On("SearchISAs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
+				ma.On("SearchISAs", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(
 					[]*ridmodels.IdentificationServiceArea(nil), nil)
 				ma.On("InsertSubscription", mock.Anything, r.wantSubscription).Return(
 					r.wantSubscription, nil,
@@ -285,7 +381,7 @@ func TestCreateSubscriptionResponseIncludesISAs(t *testing.T) {
 
 	ma := &mockApp{}
 
-	ma.On("SearchISAs", mock.Anything, cells, mock.Anything, mock.Anything).Return(isas, nil)
+	ma.On("SearchISAs", mock.Anything, cells, mock.Anything, mock.Anything, dssmodels.Owner("")).Return(isas, nil)
 	ma.On("InsertSubscription", mock.Anything, sub).Return(sub, nil)
 	s := &Server{
 		App: ma,
@@ -442,6 +538,7 @@ func TestCreateISA(t *testing.T) {
 				EndTime:    mustTimestamp(testdata.LoopVolume4D.TimeEnd),
 				AltitudeHi: (*float32)(testdata.LoopVolume3D.AltitudeHi),
 				AltitudeLo: (*float32)(testdata.LoopVolume3D.AltitudeLo),
+				Footprint:  apiv1.FromGeoPolygon(&testdata.LoopPolygon),
 			},
 		},
 		{
@@ -544,6 +641,7 @@ func TestUpdateISA(t *testing.T) {
 				EndTime:    mustTimestamp(testdata.LoopVolume4D.TimeEnd),
 				AltitudeHi: (*float32)(testdata.LoopVolume3D.AltitudeHi),
 				AltitudeLo: (*float32)(testdata.LoopVolume3D.AltitudeLo),
+				Footprint:  apiv1.FromGeoPolygon(&testdata.LoopPolygon),
 				Writer:     "locality value",
 				Version:    testdata.Version,
 			},
@@ -648,6 +746,48 @@ func TestDeleteIdentificationServiceArea(t *testing.T) {
 	require.True(t, ma.AssertExpectations(t))
 }
 
+func TestDeleteIdentificationServiceAreaErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	id := dssmodels.ID(uuid.New().String())
+
+	for _, r := range []struct {
+		name string
+		code stacktrace.ErrorCode
+		want func(restapi.DeleteIdentificationServiceAreaResponseSet) interface{}
+	}{
+		{"other owner", dsserr.PermissionDenied, func(r restapi.DeleteIdentificationServiceAreaResponseSet) interface{} { return r.Response403 }},
+		{"stale version", dsserr.StaleVersion, func(r restapi.DeleteIdentificationServiceAreaResponseSet) interface{} { return r.Response409 }},
+		{"concurrent change", dsserr.VersionMismatch, func(r restapi.DeleteIdentificationServiceAreaResponseSet) interface{} { return r.Response409 }},
+		{"not found", dsserr.NotFound, func(r restapi.DeleteIdentificationServiceAreaResponseSet) interface{} { return r.Response404 }},
+		{"missing version", dsserr.BadRequest, func(r restapi.DeleteIdentificationServiceAreaResponseSet) interface{} { return r.Response400 }},
+	} {
+		t.Run(r.name, func(t *testing.T) {
+			ma := &mockApp{}
+			s := &Server{App: ma}
+			ma.On("DeleteISA", mock.Anything, id, dssmodels.Owner(testdata.Owner), mock.Anything).Return(
+				(*ridmodels.IdentificationServiceArea)(nil), []*ridmodels.Subscription(nil), stacktrace.NewErrorWithCode(r.code, "Failing"))
+
+			respSet := s.DeleteIdentificationServiceArea(ctx, &restapi.DeleteIdentificationServiceAreaRequest{
+				Id: restapi.EntityUUID(id.String()), Version: testdata.Version.String(),
+				Auth: api.AuthorizationResult{ClientID: &testdata.Owner},
+			})
+			require.NotNil(t, r.want(respSet))
+			require.Nil(t, respSet.Response200)
+			require.True(t, ma.AssertExpectations(t))
+		})
+	}
+
+	// Deletes without a version are rejected before reaching the application.
+	ma := &mockApp{}
+	respSet := (&Server{App: ma}).DeleteIdentificationServiceArea(ctx, &restapi.DeleteIdentificationServiceAreaRequest{
+		Id:   restapi.EntityUUID(id.String()),
+		Auth: api.AuthorizationResult{ClientID: &testdata.Owner},
+	})
+	require.NotNil(t, respSet.Response400)
+	require.True(t, ma.AssertExpectations(t))
+}
+
 func TestSearchIdentificationServiceAreas(t *testing.T) {
 	var (
 		ma = &mockApp{}
@@ -659,7 +799,7 @@ func TestSearchIdentificationServiceAreas(t *testing.T) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
-	ma.On("SearchISAs", mock.Anything, mock.Anything, (*time.Time)(nil), (*time.Time)(nil)).Return(
+	ma.On("SearchISAs", mock.Anything, mock.Anything, (*time.Time)(nil), (*time.Time)(nil), dssmodels.Owner("")).Return(
 		[]*ridmodels.IdentificationServiceArea{
 			{
 				ID:    dssmodels.ID(uuid.New().String()),
@@ -678,8 +818,76 @@ func TestSearchIdentificationServiceAreas(t *testing.T) {
 	require.True(t, ma.AssertExpectations(t))
 }
 
+func TestSearchIdentificationServiceAreasParsesTimes(t *testing.T) {
+	var (
+		ma = &mockApp{}
+
+		s = &Server{
+			App: ma,
+		}
+		earliest  = "2024-01-02T05:04:05.5 02:00"
+		latest    = "2024-01-02T04:04:05Z"
+		malformed = "2024-01-02 03:04"
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	ma.On("SearchISAs", mock.Anything, mock.Anything, mock.MatchedBy(func(ts *time.Time) bool {
+		return ts.Equal(time.Date(2024, 1, 2, 3, 4, 5, 5e8, time.UTC)) && ts.Location() == time.UTC
+	}), mock.Anything, dssmodels.Owner("")).Return([]*ridmodels.IdentificationServiceArea{}, error(nil))
+	respSet := s.SearchIdentificationServiceAreas(ctx, &restapi.SearchIdentificationServiceAreasRequest{
+		Area:         (*restapi.GeoPolygonString)(&testdata.Loop),
+		EarliestTime: &earliest,
+		LatestTime:   &latest,
+		Auth:         api.AuthorizationResult{ClientID: &testdata.Owner},
+	})
+	require.NotNil(t, respSet.Response200)
+	require.True(t, ma.AssertExpectations(t))
+
+	respSet = s.SearchIdentificationServiceAreas(ctx, &restapi.SearchIdentificationServiceAreasRequest{
+		Area:       (*restapi.GeoPolygonString)(&testdata.Loop),
+		LatestTime: &malformed,
+		Auth:       api.AuthorizationResult{ClientID: &testdata.Owner},
+	})
+	require.NotNil(t, respSet.Response400)
+}
+
 func TestDefaultRegionCovererProducesResults(t *testing.T) {
 	cover, err := geo.AreaToCellIDs(testdata.Loop)
 	require.NoError(t, err)
 	require.NotNil(t, cover)
 }
+
+func TestHandlersRejectMissingBody(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		s       = &Server{App: &mockApp{}, Timeout: timeout}
+		auth    = api.AuthorizationResult{ClientID: &testdata.Owner}
+		id      = "4348c8e5-0b1c-43cf-9114-2e67a4532765"
+		version = "1"
+	)
+	// The mock App has no expectations: handlers must reject the requests
+	// before using it.
+	for name, handle := range map[string]func() *restapi.ErrorResponse{
+		"CreateIdentificationServiceArea": func() *restapi.ErrorResponse {
+			return s.CreateIdentificationServiceArea(ctx, &restapi.CreateIdentificationServiceAreaRequest{
+				Id: restapi.EntityUUID(id), Auth: auth}).Response400
+		},
+		"UpdateIdentificationServiceArea": func() *restapi.ErrorResponse {
+			return s.UpdateIdentificationServiceArea(ctx, &restapi.UpdateIdentificationServiceAreaRequest{
+				Id: restapi.EntityUUID(id), Version: version, Auth: auth}).Response400
+		},
+		"CreateSubscription": func() *restapi.ErrorResponse {
+			return s.CreateSubscription(ctx, &restapi.CreateSubscriptionRequest{
+				Id: restapi.SubscriptionUUID(id), Auth: auth}).Response400
+		},
+		"UpdateSubscription": func() *restapi.ErrorResponse {
+			return s.UpdateSubscription(ctx, &restapi.UpdateSubscriptionRequest{
+				Id: restapi.SubscriptionUUID(id), Version: version, Auth: auth}).Response400
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.NotNil(t, handle())
+		})
+	}
+}
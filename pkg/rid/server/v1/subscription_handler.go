@@ -8,9 +8,11 @@ import (
 	dsserr "github.com/interuss/dss/pkg/errors"
 	"github.com/interuss/dss/pkg/geo"
 	geoerr "github.com/interuss/dss/pkg/geo"
+	"github.com/interuss/dss/pkg/limits"
 	dssmodels "github.com/interuss/dss/pkg/models"
 	ridmodels "github.com/interuss/dss/pkg/rid/models"
 	apiv1 "github.com/interuss/dss/pkg/rid/models/api/v1"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
 	"github.com/interuss/stacktrace"
 	"github.com/pkg/errors"
 )
@@ -92,6 +94,8 @@ func (s *Server) SearchSubscriptions(ctx context.Context, req *restapi.SearchSub
 		return restapi.SearchSubscriptionsResponseSet{Response400: &restapi.ErrorResponse{
 			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Invalid area"))}}
 	}
+	cu = ridserver.CoarsenSearchCovering(ctx, cu)
+	ridserver.ObserveSearchArea(ctx, "subscription", cu)
 
 	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
 	defer cancel()
@@ -105,6 +109,11 @@ func (s *Server) SearchSubscriptions(ctx context.Context, req *restapi.SearchSub
 		return restapi.SearchSubscriptionsResponseSet{Response500: &api.InternalServerErrorBody{
 			ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Got an unexpected error"))}}
 	}
+	subscriptions, err = limits.Apply(ctx, subscriptions)
+	if err != nil {
+		return restapi.SearchSubscriptionsResponseSet{Response413: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.Propagate(err, "Too many Subscriptions found"))}}
+	}
 
 	sp := make([]restapi.Subscription, 0, len(subscriptions))
 	for _, sub := range subscriptions {
@@ -164,9 +173,9 @@ func (s *Server) CreateSubscription(ctx context.Context, req *restapi.CreateSubs
 		return restapi.CreateSubscriptionResponseSet{Response403: &restapi.ErrorResponse{
 			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Missing owner"))}}
 	}
-	if req.BodyParseError != nil {
+	if err := ridserver.CheckBody(req.Body, req.BodyParseError); err != nil {
 		return restapi.CreateSubscriptionResponseSet{Response400: &restapi.ErrorResponse{
-			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(req.BodyParseError, dsserr.BadRequest, "Malformed params"))}}
+			Message: dsserr.Handle(ctx, err)}}
 	}
 	if req.Body.Callbacks.IdentificationServiceAreaUrl == nil {
 		return restapi.CreateSubscriptionResponseSet{Response400: &restapi.ErrorResponse{
@@ -187,12 +196,9 @@ func (s *Server) CreateSubscription(ctx context.Context, req *restapi.CreateSubs
 			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Invalid ID format"))}}
 	}
 
-	if !s.AllowHTTPBaseUrls {
-		err = ridmodels.ValidateURL(string(*req.Body.Callbacks.IdentificationServiceAreaUrl))
-		if err != nil {
-			return restapi.CreateSubscriptionResponseSet{Response400: &restapi.ErrorResponse{
-				Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Failed to validate IdentificationServiceAreaUrl"))}}
-		}
+	if err := s.URLPolicy.Validate(string(*req.Body.Callbacks.IdentificationServiceAreaUrl)); err != nil {
+		return restapi.CreateSubscriptionResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Failed to validate IdentificationServiceAreaUrl"))}}
 	}
 
 	sub := &ridmodels.Subscription{
@@ -225,7 +231,7 @@ func (s *Server) CreateSubscription(ctx context.Context, req *restapi.CreateSubs
 	}
 
 	// Find ISAs that were in this subscription's area.
-	isas, err := s.App.SearchISAs(ctx, sub.Cells, nil, nil)
+	isas, err := s.App.SearchISAs(ctx, sub.Cells, nil, nil, "")
 	if err != nil {
 		err = stacktrace.Propagate(err, "Could not search ISAs")
 		if stacktrace.GetCode(err) == dsserr.BadRequest {
@@ -276,9 +282,9 @@ func (s *Server) UpdateSubscription(ctx context.Context, req *restapi.UpdateSubs
 		return restapi.UpdateSubscriptionResponseSet{Response403: &restapi.ErrorResponse{
 			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Missing owner"))}}
 	}
-	if req.BodyParseError != nil {
+	if err := ridserver.CheckBody(req.Body, req.BodyParseError); err != nil {
 		return restapi.UpdateSubscriptionResponseSet{Response400: &restapi.ErrorResponse{
-			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(req.BodyParseError, dsserr.BadRequest, "Malformed params"))}}
+			Message: dsserr.Handle(ctx, err)}}
 	}
 	if req.Body.Callbacks.IdentificationServiceAreaUrl == nil {
 		return restapi.UpdateSubscriptionResponseSet{Response400: &restapi.ErrorResponse{
@@ -294,6 +300,11 @@ func (s *Server) UpdateSubscription(ctx context.Context, req *restapi.UpdateSubs
 			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Error parsing Volume4D: %v", stacktrace.RootCause(err)))}}
 	}
 
+	if err := s.URLPolicy.Validate(string(*req.Body.Callbacks.IdentificationServiceAreaUrl)); err != nil {
+		return restapi.UpdateSubscriptionResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Failed to validate IdentificationServiceAreaUrl"))}}
+	}
+
 	sub := &ridmodels.Subscription{
 		ID:      id,
 		Owner:   dssmodels.Owner(*req.Auth.ClientID),
@@ -327,7 +338,7 @@ func (s *Server) UpdateSubscription(ctx context.Context, req *restapi.UpdateSubs
 	}
 
 	// Find ISAs that were in this subscription's area.
-	isas, err := s.App.SearchISAs(ctx, sub.Cells, nil, nil)
+	isas, err := s.App.SearchISAs(ctx, sub.Cells, nil, nil, "")
 	if err != nil {
 		err = stacktrace.Propagate(err, "Could not search ISAs")
 		if stacktrace.GetCode(err) == dsserr.BadRequest {
@@ -9,9 +9,11 @@ import (
 	dsserr "github.com/interuss/dss/pkg/errors"
 	"github.com/interuss/dss/pkg/geo"
 	geoerr "github.com/interuss/dss/pkg/geo"
+	"github.com/interuss/dss/pkg/limits"
 	dssmodels "github.com/interuss/dss/pkg/models"
 	ridmodels "github.com/interuss/dss/pkg/rid/models"
 	apiv2 "github.com/interuss/dss/pkg/rid/models/api/v2"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
 	"github.com/interuss/stacktrace"
 	"github.com/pkg/errors"
 )
@@ -62,9 +64,9 @@ func (s *Server) CreateIdentificationServiceArea(ctx context.Context, req *resta
 		return restapi.CreateIdentificationServiceAreaResponseSet{Response403: &restapi.ErrorResponse{
 			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Missing owner"))}}
 	}
-	if req.BodyParseError != nil {
+	if err := ridserver.CheckBody(req.Body, req.BodyParseError); err != nil {
 		return restapi.CreateIdentificationServiceAreaResponseSet{Response400: &restapi.ErrorResponse{
-			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(req.BodyParseError, dsserr.BadRequest, "Malformed params"))}}
+			Message: dsserr.Handle(ctx, err)}}
 	}
 	// TODO: put the validation logic in the models layer
 	if req.Body.UssBaseUrl == "" {
@@ -82,12 +84,9 @@ func (s *Server) CreateIdentificationServiceArea(ctx context.Context, req *resta
 			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Invalid ID format"))}}
 	}
 
-	if !s.AllowHTTPBaseUrls {
-		err = ridmodels.ValidateURL(string(req.Body.UssBaseUrl))
-		if err != nil {
-			return restapi.CreateIdentificationServiceAreaResponseSet{Response400: &restapi.ErrorResponse{
-				Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Failed to validate base URL"))}}
-		}
+	if err := s.URLPolicy.Validate(string(req.Body.UssBaseUrl)); err != nil {
+		return restapi.CreateIdentificationServiceAreaResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Failed to validate base URL"))}}
 	}
 
 	isa := &ridmodels.IdentificationServiceArea{
@@ -98,6 +97,10 @@ func (s *Server) CreateIdentificationServiceArea(ctx context.Context, req *resta
 	}
 
 	if err := isa.SetExtents(extents); err != nil {
+		if errors.Is(err, geoerr.ErrAreaTooLarge) {
+			return restapi.CreateIdentificationServiceAreaResponseSet{Response413: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, stacktrace.Propagate(err, "Invalid extents"))}}
+		}
 		return restapi.CreateIdentificationServiceAreaResponseSet{Response400: &restapi.ErrorResponse{
 			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Invalid extents"))}}
 	}
@@ -117,6 +120,7 @@ func (s *Server) CreateIdentificationServiceArea(ctx context.Context, req *resta
 		}
 	}
 
+	ridserver.RecordNotificationDeltas(ctx, subscribers)
 	apiSubscribers := apiv2.MakeSubscribersToNotify(subscribers)
 
 	return restapi.CreateIdentificationServiceAreaResponseSet{Response200: &restapi.PutIdentificationServiceAreaResponse{
@@ -147,9 +151,9 @@ func (s *Server) UpdateIdentificationServiceArea(ctx context.Context, req *resta
 			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Missing owner"))}}
 	}
 	// TODO: put the validation logic in the models layer
-	if req.BodyParseError != nil {
+	if err := ridserver.CheckBody(req.Body, req.BodyParseError); err != nil {
 		return restapi.UpdateIdentificationServiceAreaResponseSet{Response400: &restapi.ErrorResponse{
-			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(req.BodyParseError, dsserr.BadRequest, "Malformed params"))}}
+			Message: dsserr.Handle(ctx, err)}}
 	}
 	if req.Body.UssBaseUrl == "" {
 		return restapi.UpdateIdentificationServiceAreaResponseSet{Response400: &restapi.ErrorResponse{
@@ -166,6 +170,11 @@ func (s *Server) UpdateIdentificationServiceArea(ctx context.Context, req *resta
 			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Invalid ID format"))}}
 	}
 
+	if err := s.URLPolicy.Validate(string(req.Body.UssBaseUrl)); err != nil {
+		return restapi.UpdateIdentificationServiceAreaResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Failed to validate base URL"))}}
+	}
+
 	isa := &ridmodels.IdentificationServiceArea{
 		ID:      id,
 		URL:     string(req.Body.UssBaseUrl),
@@ -175,6 +184,10 @@ func (s *Server) UpdateIdentificationServiceArea(ctx context.Context, req *resta
 	}
 
 	if err := isa.SetExtents(extents); err != nil {
+		if errors.Is(err, geoerr.ErrAreaTooLarge) {
+			return restapi.UpdateIdentificationServiceAreaResponseSet{Response413: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, stacktrace.Propagate(err, "Invalid extents"))}}
+		}
 		return restapi.UpdateIdentificationServiceAreaResponseSet{Response400: &restapi.ErrorResponse{
 			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Invalid extents"))}}
 	}
@@ -196,6 +209,7 @@ func (s *Server) UpdateIdentificationServiceArea(ctx context.Context, req *resta
 		}
 	}
 
+	ridserver.RecordNotificationDeltas(ctx, subscribers)
 	apiSubscribers := apiv2.MakeSubscribersToNotify(subscribers)
 
 	return restapi.UpdateIdentificationServiceAreaResponseSet{Response200: &restapi.PutIdentificationServiceAreaResponse{
@@ -237,16 +251,19 @@ func (s *Server) DeleteIdentificationServiceArea(ctx context.Context, req *resta
 		switch stacktrace.GetCode(err) {
 		case dsserr.PermissionDenied:
 			return restapi.DeleteIdentificationServiceAreaResponseSet{Response403: errResp}
-		case dsserr.VersionMismatch:
+		case dsserr.VersionMismatch, dsserr.StaleVersion:
 			return restapi.DeleteIdentificationServiceAreaResponseSet{Response409: errResp}
 		case dsserr.NotFound:
 			return restapi.DeleteIdentificationServiceAreaResponseSet{Response404: errResp}
+		case dsserr.BadRequest:
+			return restapi.DeleteIdentificationServiceAreaResponseSet{Response400: errResp}
 		default:
 			return restapi.DeleteIdentificationServiceAreaResponseSet{Response500: &api.InternalServerErrorBody{
 				ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Got an unexpected error"))}}
 		}
 	}
 
+	ridserver.RecordNotificationDeltas(ctx, subscribers)
 	apiSubscribers := apiv2.MakeSubscribersToNotify(subscribers)
 
 	return restapi.DeleteIdentificationServiceAreaResponseSet{Response200: &restapi.DeleteIdentificationServiceAreaResponse{
@@ -277,6 +294,8 @@ func (s *Server) SearchIdentificationServiceAreas(ctx context.Context, req *rest
 		return restapi.SearchIdentificationServiceAreasResponseSet{Response400: &restapi.ErrorResponse{
 			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Invalid area"))}}
 	}
+	cu = ridserver.CoarsenSearchCovering(ctx, cu)
+	ridserver.ObserveSearchArea(ctx, "isa", cu)
 
 	var (
 		earliest *time.Time
@@ -284,26 +303,30 @@ func (s *Server) SearchIdentificationServiceAreas(ctx context.Context, req *rest
 	)
 
 	if req.EarliestTime != nil {
-		ts, err := time.Parse(time.RFC3339Nano, *req.EarliestTime)
+		ts, err := ridserver.ParseQueryTime("earliest_time", *req.EarliestTime)
 		if err != nil {
 			return restapi.SearchIdentificationServiceAreasResponseSet{Response400: &restapi.ErrorResponse{
-				Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Unable to convert earliest timestamp"))}}
+				Message: dsserr.Handle(ctx, stacktrace.Propagate(err, "Unable to convert earliest timestamp"))}}
 		}
 		earliest = &ts
 	}
 
 	if req.LatestTime != nil {
-		ts, err := time.Parse(time.RFC3339Nano, *req.LatestTime)
+		ts, err := ridserver.ParseQueryTime("latest_time", *req.LatestTime)
 		if err != nil {
 			return restapi.SearchIdentificationServiceAreasResponseSet{Response400: &restapi.ErrorResponse{
-				Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Unable to convert latest timestamp"))}}
+				Message: dsserr.Handle(ctx, stacktrace.Propagate(err, "Unable to convert latest timestamp"))}}
 		}
 		latest = &ts
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
 	defer cancel()
-	isas, err := s.App.SearchISAs(ctx, cu, earliest, latest)
+	var excludeOwner dssmodels.Owner
+	if ridserver.ExcludeSelf(ctx) && req.Auth.ClientID != nil {
+		excludeOwner = dssmodels.Owner(*req.Auth.ClientID)
+	}
+	isas, err := s.App.SearchISAs(ctx, cu, earliest, latest, excludeOwner)
 	if err != nil {
 		err = stacktrace.Propagate(err, "Unable to search ISAs")
 		if stacktrace.GetCode(err) == dsserr.BadRequest {
@@ -313,6 +336,16 @@ func (s *Server) SearchIdentificationServiceAreas(ctx context.Context, req *rest
 		return restapi.SearchIdentificationServiceAreasResponseSet{Response500: &api.InternalServerErrorBody{
 			ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Got an unexpected error"))}}
 	}
+	isas, err = ridserver.FilterISAsByArea(ctx, string(*req.Area), isas)
+	if err != nil {
+		return restapi.SearchIdentificationServiceAreasResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Invalid area"))}}
+	}
+	isas, err = limits.Apply(ctx, isas)
+	if err != nil {
+		return restapi.SearchIdentificationServiceAreasResponseSet{Response413: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.Propagate(err, "Too many ISAs found"))}}
+	}
 
 	areas := make([]restapi.IdentificationServiceArea, 0, len(isas))
 	for _, isa := range isas {
@@ -11,15 +11,16 @@ import (
 	"github.com/robfig/cron/v3"
 
 	"github.com/interuss/dss/pkg/rid/application"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
 )
 
 // Server implements ridv2.Implementation.
 type Server struct {
-	App               application.App
-	Timeout           time.Duration
-	Locality          string
-	AllowHTTPBaseUrls bool
-	Cron              *cron.Cron
+	App       application.App
+	Timeout   time.Duration
+	Locality  string
+	URLPolicy ridmodels.URLPolicy
+	Cron      *cron.Cron
 }
 
 func setAuthError(ctx context.Context, authErr error, resp401, resp403 **restapi.ErrorResponse, resp500 **api.InternalServerErrorBody) {
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/interuss/dss/pkg/api"
+	restapi "github.com/interuss/dss/pkg/api/ridv2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlersRejectMissingBody(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		owner   = "foo"
+		s       = &Server{Timeout: 10 * time.Second}
+		auth    = api.AuthorizationResult{ClientID: &owner}
+		id      = "4348c8e5-0b1c-43cf-9114-2e67a4532765"
+		version = "1"
+	)
+	// The Server has no App: handlers must reject the requests before using
+	// it.
+	for name, handle := range map[string]func() *restapi.ErrorResponse{
+		"CreateIdentificationServiceArea": func() *restapi.ErrorResponse {
+			return s.CreateIdentificationServiceArea(ctx, &restapi.CreateIdentificationServiceAreaRequest{
+				Id: restapi.EntityUUID(id), Auth: auth}).Response400
+		},
+		"UpdateIdentificationServiceArea": func() *restapi.ErrorResponse {
+			return s.UpdateIdentificationServiceArea(ctx, &restapi.UpdateIdentificationServiceAreaRequest{
+				Id: restapi.EntityUUID(id), Version: version, Auth: auth}).Response400
+		},
+		"CreateSubscription": func() *restapi.ErrorResponse {
+			return s.CreateSubscription(ctx, &restapi.CreateSubscriptionRequest{
+				Id: restapi.SubscriptionUUID(id), Auth: auth}).Response400
+		},
+		"UpdateSubscription": func() *restapi.ErrorResponse {
+			return s.UpdateSubscription(ctx, &restapi.UpdateSubscriptionRequest{
+				Id: restapi.SubscriptionUUID(id), Version: version, Auth: auth}).Response400
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.NotNil(t, handle())
+		})
+	}
+}
@@ -8,9 +8,11 @@ import (
 	dsserr "github.com/interuss/dss/pkg/errors"
 	"github.com/interuss/dss/pkg/geo"
 	geoerr "github.com/interuss/dss/pkg/geo"
+	"github.com/interuss/dss/pkg/limits"
 	dssmodels "github.com/interuss/dss/pkg/models"
 	ridmodels "github.com/interuss/dss/pkg/rid/models"
 	apiv2 "github.com/interuss/dss/pkg/rid/models/api/v2"
+	ridserver "github.com/interuss/dss/pkg/rid/server"
 	"github.com/interuss/stacktrace"
 	"github.com/pkg/errors"
 )
@@ -90,6 +92,8 @@ func (s *Server) SearchSubscriptions(ctx context.Context, req *restapi.SearchSub
 		return restapi.SearchSubscriptionsResponseSet{Response400: &restapi.ErrorResponse{
 			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Invalid area"))}}
 	}
+	cu = ridserver.CoarsenSearchCovering(ctx, cu)
+	ridserver.ObserveSearchArea(ctx, "subscription", cu)
 
 	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
 	defer cancel()
@@ -103,6 +107,11 @@ func (s *Server) SearchSubscriptions(ctx context.Context, req *restapi.SearchSub
 		return restapi.SearchSubscriptionsResponseSet{Response500: &api.InternalServerErrorBody{
 			ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Got an unexpected error"))}}
 	}
+	subscriptions, err = limits.Apply(ctx, subscriptions)
+	if err != nil {
+		return restapi.SearchSubscriptionsResponseSet{Response413: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.Propagate(err, "Too many Subscriptions found"))}}
+	}
 
 	sp := make([]restapi.Subscription, 0, len(subscriptions))
 	for _, sub := range subscriptions {
@@ -160,9 +169,9 @@ func (s *Server) CreateSubscription(ctx context.Context, req *restapi.CreateSubs
 		return restapi.CreateSubscriptionResponseSet{Response403: &restapi.ErrorResponse{
 			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Missing owner"))}}
 	}
-	if req.BodyParseError != nil {
+	if err := ridserver.CheckBody(req.Body, req.BodyParseError); err != nil {
 		return restapi.CreateSubscriptionResponseSet{Response400: &restapi.ErrorResponse{
-			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(req.BodyParseError, dsserr.BadRequest, "Malformed params"))}}
+			Message: dsserr.Handle(ctx, err)}}
 	}
 	if req.Body.UssBaseUrl == "" {
 		return restapi.CreateSubscriptionResponseSet{Response400: &restapi.ErrorResponse{
@@ -179,12 +188,9 @@ func (s *Server) CreateSubscription(ctx context.Context, req *restapi.CreateSubs
 			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Invalid ID format"))}}
 	}
 
-	if !s.AllowHTTPBaseUrls {
-		err = ridmodels.ValidateURL(string(req.Body.UssBaseUrl))
-		if err != nil {
-			return restapi.CreateSubscriptionResponseSet{Response400: &restapi.ErrorResponse{
-				Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Failed to validate UssBaseUrl"))}}
-		}
+	if err := s.URLPolicy.Validate(string(req.Body.UssBaseUrl)); err != nil {
+		return restapi.CreateSubscriptionResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Failed to validate UssBaseUrl"))}}
 	}
 
 	sub := &ridmodels.Subscription{
@@ -217,7 +223,7 @@ func (s *Server) CreateSubscription(ctx context.Context, req *restapi.CreateSubs
 	}
 
 	// Find ISAs that were in this subscription's area.
-	isas, err := s.App.SearchISAs(ctx, sub.Cells, nil, nil)
+	isas, err := s.App.SearchISAs(ctx, sub.Cells, nil, nil, "")
 	if err != nil {
 		err = stacktrace.Propagate(err, "Could not search ISAs")
 		if stacktrace.GetCode(err) == dsserr.BadRequest {
@@ -267,9 +273,9 @@ func (s *Server) UpdateSubscription(ctx context.Context, req *restapi.UpdateSubs
 		return restapi.UpdateSubscriptionResponseSet{Response403: &restapi.ErrorResponse{
 			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.PermissionDenied, "Missing owner"))}}
 	}
-	if req.BodyParseError != nil {
+	if err := ridserver.CheckBody(req.Body, req.BodyParseError); err != nil {
 		return restapi.UpdateSubscriptionResponseSet{Response400: &restapi.ErrorResponse{
-			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(req.BodyParseError, dsserr.BadRequest, "Malformed params"))}}
+			Message: dsserr.Handle(ctx, err)}}
 	}
 	if req.Body.UssBaseUrl == "" {
 		return restapi.UpdateSubscriptionResponseSet{Response400: &restapi.ErrorResponse{
@@ -281,6 +287,11 @@ func (s *Server) UpdateSubscription(ctx context.Context, req *restapi.UpdateSubs
 			Message: dsserr.Handle(ctx, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Error parsing Volume4D: %v", stacktrace.RootCause(err)))}}
 	}
 
+	if err := s.URLPolicy.Validate(string(req.Body.UssBaseUrl)); err != nil {
+		return restapi.UpdateSubscriptionResponseSet{Response400: &restapi.ErrorResponse{
+			Message: dsserr.Handle(ctx, stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Failed to validate UssBaseUrl"))}}
+	}
+
 	sub := &ridmodels.Subscription{
 		ID:      id,
 		Owner:   dssmodels.Owner(*req.Auth.ClientID),
@@ -314,7 +325,7 @@ func (s *Server) UpdateSubscription(ctx context.Context, req *restapi.UpdateSubs
 	}
 
 	// Find ISAs that were in this subscription's area.
-	isas, err := s.App.SearchISAs(ctx, sub.Cells, nil, nil)
+	isas, err := s.App.SearchISAs(ctx, sub.Cells, nil, nil, "")
 	if err != nil {
 		err = stacktrace.Propagate(err, "Could not search ISAs")
 		if stacktrace.GetCode(err) == dsserr.BadRequest {
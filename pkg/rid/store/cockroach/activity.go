@@ -0,0 +1,124 @@
+package cockroach
+
+import (
+	"context"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+)
+
+var (
+	// activityEventsSchemaVersion is the schema version introducing the
+	// activity events table.
+	activityEventsSchemaVersion = semver.New("4.5.0")
+)
+
+// UseActivityLog makes the Store record the creations, updates and deletions
+// of ISAs and subscriptions, from which ListActivity derives the activity of
+// the pool. It must be called before the Store is used.
+func (s *Store) UseActivityLog() error {
+	if s.version != nil && s.version.LessThan(*activityEventsSchemaVersion) {
+		return stacktrace.NewError("The activity log requires remote ID schema version %s or later, got %s", activityEventsSchemaVersion, s.version)
+	}
+	s.activityLog = true
+	return nil
+}
+
+// PruneActivity deletes the events of at most "limit" entities whose events
+// all occurred before "before" and which ended before "before", and returns
+// the number of events deleted. The events of subscriptions which may still
+// be active are kept, as ListActivity needs them to count active
+// subscriptions.
+func (s *Store) PruneActivity(ctx context.Context, before time.Time, limit int) (int64, error) {
+	const query = `
+		DELETE FROM activity_events
+		WHERE entity_id IN (
+			SELECT entity_id FROM activity_events
+			GROUP BY entity_id
+			HAVING max(occurred_at) < $1 AND (max(ends_at) IS NULL OR max(ends_at) < $1)
+			LIMIT $2
+		)`
+
+	tag, err := s.db.Pool.Exec(ctx, query, before, limit)
+	if err != nil {
+		return 0, stacktrace.Propagate(err, "Error pruning activity events")
+	}
+	return tag.RowsAffected(), nil
+}
+
+// recordActivity records that "kind" happened to the entity identified by
+// "id", if the activity log is enabled. Deletions record the time bounds of
+// the entity deleted, so that its events are pruned together.
+func (r *repo) recordActivity(ctx context.Context, entity ridmodels.ActivityEntity, id dssmodels.ID, kind ridmodels.ActivityKind, startTime, endTime *time.Time) error {
+	if !r.activityLog {
+		return nil
+	}
+	const query = `
+		INSERT INTO
+			activity_events
+			(entity_type, entity_id, event, occurred_at, starts_at, ends_at)
+		VALUES
+			($1, $2, $3, $4, $5, $6)
+		ON CONFLICT DO NOTHING`
+
+	uid, err := id.PgUUID()
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to convert id to PgUUID")
+	}
+	defer r.timeStatement(entity, statementActivity)()
+	if _, err := r.Exec(ctx, query, string(entity), uid, string(kind), r.clock.Now(), startTime, endTime); err != nil {
+		return stacktrace.Propagate(err, "Error recording %s of %s %s", kind, entity, id)
+	}
+	return nil
+}
+
+// ListActivity implements repos.Activity.ListActivity.
+func (r *repo) ListActivity(ctx context.Context, from time.Time, to time.Time) ([]*ridmodels.ActivityEvent, error) {
+	if !r.activityLog {
+		return nil, stacktrace.NewErrorWithCode(dsserr.NotFound, "Remote ID activity is not recorded by this DSS instance")
+	}
+	const query = `
+		SELECT
+			entity_type, entity_id, event, occurred_at, starts_at, ends_at
+		FROM
+			activity_events
+		WHERE
+			occurred_at <= $2
+		AND (
+			occurred_at >= $1
+			OR entity_id IN (
+				SELECT entity_id FROM activity_events
+				WHERE entity_type = $3 AND occurred_at < $1 AND ends_at >= $1
+			)
+		)
+		ORDER BY
+			occurred_at`
+
+	rows, err := r.Query(ctx, query, from, to, string(ridmodels.ActivitySubscription))
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error in query: %s", query)
+	}
+	defer rows.Close()
+
+	var events []*ridmodels.ActivityEvent
+	for rows.Next() {
+		var (
+			e            = &ridmodels.ActivityEvent{}
+			entity, kind string
+		)
+		if err := rows.Scan(&entity, &e.EntityID, &kind, &e.OccurredAt, &e.StartTime, &e.EndTime); err != nil {
+			return nil, stacktrace.Propagate(err, "Error scanning activity event row")
+		}
+		e.Entity = ridmodels.ActivityEntity(entity)
+		e.Kind = ridmodels.ActivityKind(kind)
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, stacktrace.Propagate(err, "Error in rows query result")
+	}
+	return events, nil
+}
@@ -0,0 +1,53 @@
+package cockroach
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreActivity(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	defer tearDownStore()
+
+	repo, err := store.Interact(ctx)
+	require.NoError(t, err)
+	_, err = repo.ListActivity(ctx, fakeClock.Now().Add(-time.Hour), fakeClock.Now())
+	require.Error(t, err)
+
+	require.NoError(t, store.UseActivityLog())
+	repo, err = store.Interact(ctx)
+	require.NoError(t, err)
+
+	// The ISA ends before the subscription.
+	copy := *serviceArea
+	isaEnd := fakeClock.Now().Add(10 * time.Minute)
+	copy.EndTime = &isaEnd
+	isa, err := repo.InsertISA(ctx, &copy)
+	require.NoError(t, err)
+	sub := insertNotifiedSubscription(ctx, t, repo)
+	fakeClock.Advance(time.Minute)
+	_, err = repo.DeleteISA(ctx, isa)
+	require.NoError(t, err)
+
+	events, err := repo.ListActivity(ctx, fakeClock.Now().Add(-time.Hour), fakeClock.Now())
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	require.Equal(t, ridmodels.ActivityDeleted, events[2].Kind)
+	require.Equal(t, isa.ID, events[2].EntityID)
+
+	// The subscription may still be active, so its events are kept.
+	fakeClock.Advance(30 * time.Minute)
+	_, err = store.PruneActivity(ctx, fakeClock.Now(), 100)
+	require.NoError(t, err)
+	events, err = repo.ListActivity(ctx, fakeClock.Now().Add(-time.Hour), fakeClock.Now())
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, sub.ID, events[0].EntityID)
+}
@@ -0,0 +1,47 @@
+package cockroach
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/interuss/stacktrace"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// storesCells returns whether the row of table identified by id at version
+// already stores the cells cids, in which case updates leave the cells column,
+// and so its inverted index, untouched.
+func (r *repo) storesCells(ctx context.Context, table string, id pgtype.UUID, version *time.Time, cids []int64) (bool, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			cells
+		FROM
+			%s
+		WHERE
+			id = $1
+		AND
+			updated_at = $2`, table)
+	var stored []int64
+	if err := r.QueryRow(ctx, query, id, version).Scan(&stored); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, stacktrace.Propagate(err, "Error in query: %s", query)
+	}
+	return sameCells(stored, cids), nil
+}
+
+// sameCells returns whether a and b hold the same cells, in any order.
+func sameCells(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,45 @@
+package cockroach
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSameCells(t *testing.T) {
+	require.True(t, sameCells(nil, []int64{}))
+	require.True(t, sameCells([]int64{1, 2, 3}, []int64{3, 1, 2}))
+	require.False(t, sameCells([]int64{1, 2}, []int64{1, 2, 3}))
+	require.False(t, sameCells([]int64{1, 1, 2}, []int64{1, 2, 2}))
+}
+
+func TestStoreUpdateISAKeepsUnchangedCells(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	defer tearDownStore()
+
+	repo, err := store.Interact(ctx)
+	require.NoError(t, err)
+
+	isa, err := repo.InsertISA(ctx, serviceArea)
+	require.NoError(t, err)
+
+	// Only the URL changes.
+	copy := *isa
+	copy.URL = "https://no/place/like/home/for/flights/v2"
+	updated, err := repo.UpdateISA(ctx, &copy)
+	require.NoError(t, err)
+	require.Equal(t, copy.URL, updated.URL)
+	require.Equal(t, isa.Cells, updated.Cells)
+
+	// The cells change too.
+	copy = *updated
+	copy.Cells = s2.CellUnion{s2.CellID(overflow)}
+	updated, err = repo.UpdateISA(ctx, &copy)
+	require.NoError(t, err)
+	require.Equal(t, copy.Cells, updated.Cells)
+}
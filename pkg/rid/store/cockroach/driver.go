@@ -0,0 +1,53 @@
+package cockroach
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/interuss/dss/pkg/datastore"
+	"github.com/interuss/dss/pkg/rid/store"
+	"github.com/interuss/stacktrace"
+	"go.uber.org/zap"
+)
+
+// Schemes are the URI schemes of the stores opened by this package, CockroachDB
+// and Yugabyte databases being both reached through their postgres interface.
+var Schemes = []string{"cockroach", "postgres", "postgresql"}
+
+func init() {
+	for _, scheme := range Schemes {
+		store.Register(scheme, open)
+	}
+}
+
+// open opens the Store of the database located by uri, e.g.
+// cockroach://root@crdb:26257/rid?sslmode=disable.
+func open(ctx context.Context, uri *url.URL, logger *zap.Logger) (store.Store, error) {
+	dsn, dbName, err := connectionURI(uri)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	db, err := datastore.DialURI(ctx, dsn)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to connect to remote ID database %s", dbName)
+	}
+	s, err := NewStore(ctx, db, dbName, logger)
+	if err != nil {
+		db.Pool.Close()
+		return nil, stacktrace.Propagate(err, "Failed to create remote ID store")
+	}
+	return s, nil
+}
+
+// connectionURI returns the postgres URI for pgx of the database located by
+// uri, and the name of the database.
+func connectionURI(uri *url.URL) (string, string, error) {
+	dbName := strings.TrimPrefix(uri.Path, "/")
+	if dbName == "" || strings.Contains(dbName, "/") {
+		return "", "", stacktrace.NewError("Store URI must name the remote ID database as its path, e.g. /rid")
+	}
+	u := *uri
+	u.Scheme = "postgresql"
+	return u.String(), dbName, nil
+}
@@ -0,0 +1,76 @@
+package cockroach
+
+import (
+	"github.com/coreos/go-semver/semver"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/stacktrace"
+)
+
+var (
+	// isaExtentsSchemaVersion is the schema version introducing the
+	// altitudes and footprints of ISAs.
+	isaExtentsSchemaVersion = semver.New("4.7.0")
+)
+
+// isaExtentsFields are the columns storing the altitudes and footprints of
+// ISAs since isaExtentsSchemaVersion.
+const isaExtentsFields = "altitude_lower, altitude_upper, footprint"
+
+// storesISAExtents returns whether the schema of s stores the altitudes and
+// footprints of ISAs.
+func (s *Store) storesISAExtents() bool {
+	return s.version == nil || !s.version.LessThan(*isaExtentsSchemaVersion)
+}
+
+// storedPoint is the JSON representation of a point in stored footprints.
+type storedPoint struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// storedFootprint is the JSON representation of a footprint in the
+// database: either a polygon, by its vertices, or a circle.
+type storedFootprint struct {
+	Vertices    []storedPoint `json:"vertices,omitempty"`
+	Center      *storedPoint  `json:"center,omitempty"`
+	RadiusMeter float32       `json:"radius_m,omitempty"`
+}
+
+// footprintArg returns the query argument storing footprint, which is NULL
+// if there is none.
+func footprintArg(footprint dssmodels.Geometry) (interface{}, error) {
+	switch f := footprint.(type) {
+	case nil:
+		return nil, nil
+	case *dssmodels.GeoPolygon:
+		stored := &storedFootprint{Vertices: make([]storedPoint, 0, len(f.Vertices))}
+		for _, v := range f.Vertices {
+			stored.Vertices = append(stored.Vertices, storedPoint{Lat: v.Lat, Lng: v.Lng})
+		}
+		return stored, nil
+	case *dssmodels.GeoCircle:
+		return &storedFootprint{
+			Center:      &storedPoint{Lat: f.Center.Lat, Lng: f.Center.Lng},
+			RadiusMeter: f.RadiusMeter,
+		}, nil
+	}
+	return nil, stacktrace.NewError("Unsupported footprint type %T", footprint)
+}
+
+// footprintFromStored returns the footprint stored as stored, if any.
+func footprintFromStored(stored *storedFootprint) dssmodels.Geometry {
+	switch {
+	case stored == nil:
+		return nil
+	case stored.Center != nil:
+		return &dssmodels.GeoCircle{
+			Center:      dssmodels.LatLngPoint{Lat: stored.Center.Lat, Lng: stored.Center.Lng},
+			RadiusMeter: stored.RadiusMeter,
+		}
+	}
+	polygon := &dssmodels.GeoPolygon{}
+	for _, v := range stored.Vertices {
+		polygon.Vertices = append(polygon.Vertices, &dssmodels.LatLngPoint{Lat: v.Lat, Lng: v.Lng})
+	}
+	return polygon
+}
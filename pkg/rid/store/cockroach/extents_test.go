@@ -0,0 +1,72 @@
+package cockroach
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/coreos/go-semver/semver"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoredFootprints(t *testing.T) {
+	for _, footprint := range []dssmodels.Geometry{
+		&dssmodels.GeoPolygon{Vertices: []*dssmodels.LatLngPoint{{Lat: 46.9, Lng: 7.4}, {Lat: 46.9, Lng: 7.41}, {Lat: 46.91, Lng: 7.41}}},
+		&dssmodels.GeoCircle{Center: dssmodels.LatLngPoint{Lat: 46.9, Lng: 7.4}, RadiusMeter: 300},
+	} {
+		arg, err := footprintArg(footprint)
+		require.NoError(t, err)
+		data, err := json.Marshal(arg)
+		require.NoError(t, err)
+		stored := &storedFootprint{}
+		require.NoError(t, json.Unmarshal(data, stored))
+		require.Equal(t, footprint, footprintFromStored(stored))
+	}
+
+	arg, err := footprintArg(nil)
+	require.NoError(t, err)
+	require.Nil(t, arg)
+	require.Nil(t, footprintFromStored(nil))
+	_, err = footprintArg(dssmodels.GeometryFunc(nil))
+	require.Error(t, err)
+}
+
+func TestStoresISAExtents(t *testing.T) {
+	require.True(t, (&Store{}).storesISAExtents())
+	require.True(t, (&Store{version: semver.New("4.7.0")}).storesISAExtents())
+	require.False(t, (&Store{version: semver.New("4.6.0")}).storesISAExtents())
+}
+
+func TestStoreISAExtents(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+		lower, upper         = float32(20), float32(120)
+	)
+	defer tearDownStore()
+
+	repo, err := store.Interact(ctx)
+	require.NoError(t, err)
+
+	isa := *serviceArea
+	isa.AltitudeLo, isa.AltitudeHi = &lower, &upper
+	isa.Footprint = &dssmodels.GeoPolygon{Vertices: []*dssmodels.LatLngPoint{{Lat: 46.9, Lng: 7.4}, {Lat: 46.9, Lng: 7.41}, {Lat: 46.91, Lng: 7.41}}}
+	inserted, err := repo.InsertISA(ctx, &isa)
+	require.NoError(t, err)
+	require.Equal(t, isa.Footprint, inserted.Footprint)
+	require.Equal(t, lower, *inserted.AltitudeLo)
+	require.Equal(t, upper, *inserted.AltitudeHi)
+
+	update := *inserted
+	update.AltitudeLo, update.AltitudeHi = nil, &lower
+	update.Footprint = &dssmodels.GeoCircle{Center: dssmodels.LatLngPoint{Lat: 46.9, Lng: 7.4}, RadiusMeter: 300}
+	_, err = repo.UpdateISA(ctx, &update)
+	require.NoError(t, err)
+
+	stored, err := repo.GetISA(ctx, isa.ID, false)
+	require.NoError(t, err)
+	require.Equal(t, update.Footprint, stored.Footprint)
+	require.Nil(t, stored.AltitudeLo)
+	require.Equal(t, lower, *stored.AltitudeHi)
+}
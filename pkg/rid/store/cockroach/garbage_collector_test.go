@@ -86,7 +86,7 @@ func TestDeleteExpiredSubscriptions(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, subOut)
 
-	ret, err := repo.GetSubscription(ctx, subscription.ID)
+	ret, err := repo.GetSubscription(ctx, subscription.ID, false)
 	require.NoError(t, err)
 	require.NotNil(t, ret)
 
@@ -94,7 +94,7 @@ func TestDeleteExpiredSubscriptions(t *testing.T) {
 	err = gc.DeleteRIDExpiredRecords(ctx)
 	require.NoError(t, err)
 
-	ret, err = repo.GetSubscription(ctx, subscription.ID)
+	ret, err = repo.GetSubscription(ctx, subscription.ID, false)
 	require.NoError(t, err)
 	require.Nil(t, ret)
 }
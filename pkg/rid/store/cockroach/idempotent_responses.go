@@ -0,0 +1,108 @@
+package cockroach
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// idempotentResponsesSchemaVersion is the schema version introducing the
+	// idempotent responses table.
+	idempotentResponsesSchemaVersion = semver.New("4.11.0")
+)
+
+// UseIdempotencyKeys checks that the Store can record the responses to the
+// writes sent with idempotency keys. It must be called before the other
+// methods of idempotent responses are used.
+func (s *Store) UseIdempotencyKeys() error {
+	if s.version != nil && s.version.LessThan(*idempotentResponsesSchemaVersion) {
+		return stacktrace.NewError("Idempotency keys require remote ID schema version %s or later, got %s", idempotentResponsesSchemaVersion, s.version)
+	}
+	return nil
+}
+
+// idempotencyOwner returns the value storing owner in the idempotent
+// responses table.
+func (s *Store) idempotencyOwner(owner dssmodels.Owner) string {
+	if s.owners == nil {
+		return owner.String()
+	}
+	return s.owners.Encode(owner)
+}
+
+// GetIdempotentResponse returns the unexpired response recorded for the
+// idempotency key of owner, nil if there is none.
+func (s *Store) GetIdempotentResponse(ctx context.Context, owner dssmodels.Owner, key string) (*ridmodels.IdempotentResponse, error) {
+	const query = `
+		SELECT
+			request_hash, status, body, expires_at
+		FROM
+			idempotent_responses
+		WHERE
+			owner = $1
+		AND
+			idempotency_key = $2
+		AND
+			expires_at > $3`
+
+	resp := &ridmodels.IdempotentResponse{Owner: owner, Key: key}
+	err := s.db.Pool.QueryRow(ctx, query, s.idempotencyOwner(owner), key, s.clock.Now()).Scan(
+		&resp.RequestHash, &resp.Status, &resp.Body, &resp.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error getting response to idempotency key %s", key)
+	}
+	return resp, nil
+}
+
+// RecordIdempotentResponse records resp, unless an unexpired response is
+// already recorded for its idempotency key: the first response is the one
+// replayed.
+func (s *Store) RecordIdempotentResponse(ctx context.Context, resp *ridmodels.IdempotentResponse) error {
+	const query = `
+		INSERT INTO
+			idempotent_responses
+			(owner, idempotency_key, request_hash, status, body, expires_at)
+		VALUES
+			($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (owner, idempotency_key) DO UPDATE SET
+			request_hash = excluded.request_hash,
+			status = excluded.status,
+			body = excluded.body,
+			expires_at = excluded.expires_at
+		WHERE
+			idempotent_responses.expires_at <= $7`
+
+	if _, err := s.db.Pool.Exec(ctx, query, s.idempotencyOwner(resp.Owner), resp.Key, resp.RequestHash,
+		resp.Status, resp.Body, resp.ExpiresAt, s.clock.Now()); err != nil {
+		return stacktrace.Propagate(err, "Error recording response to idempotency key %s", resp.Key)
+	}
+	return nil
+}
+
+// PruneIdempotentResponses deletes at most "limit" responses which expired
+// before "before", and returns the number of responses deleted.
+func (s *Store) PruneIdempotentResponses(ctx context.Context, before time.Time, limit int) (int64, error) {
+	const query = `
+		DELETE FROM idempotent_responses
+		WHERE (owner, idempotency_key) IN (
+			SELECT owner, idempotency_key FROM idempotent_responses
+			WHERE expires_at < $1
+			LIMIT $2
+		)`
+
+	tag, err := s.db.Pool.Exec(ctx, query, before, limit)
+	if err != nil {
+		return 0, stacktrace.Propagate(err, "Error pruning idempotent responses")
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,63 @@
+package cockroach
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseIdempotencyKeysRequiresSchemaVersion(t *testing.T) {
+	store := &Store{version: semver.New("4.10.0")}
+	require.Error(t, store.UseIdempotencyKeys())
+
+	store.version = semver.New("4.11.0")
+	require.NoError(t, store.UseIdempotencyKeys())
+}
+
+func TestStoreIdempotentResponses(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	defer tearDownStore()
+	require.NoError(t, store.UseIdempotencyKeys())
+
+	resp, err := store.GetIdempotentResponse(ctx, "uss1", "retry-1")
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	first := &ridmodels.IdempotentResponse{
+		Owner:       "uss1",
+		Key:         "retry-1",
+		RequestHash: []byte{1},
+		Status:      http.StatusOK,
+		Body:        []byte(`{"service_area":{}}`),
+		ExpiresAt:   fakeClock.Now().Add(time.Hour),
+	}
+	require.NoError(t, store.RecordIdempotentResponse(ctx, first))
+	second := *first
+	second.RequestHash = []byte{2}
+	require.NoError(t, store.RecordIdempotentResponse(ctx, &second))
+
+	// The first response is replayed, to its owner only.
+	resp, err = store.GetIdempotentResponse(ctx, "uss1", "retry-1")
+	require.NoError(t, err)
+	require.Equal(t, first.RequestHash, resp.RequestHash)
+	require.Equal(t, first.Body, resp.Body)
+	resp, err = store.GetIdempotentResponse(ctx, "uss2", "retry-1")
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	fakeClock.Advance(2 * time.Hour)
+	resp, err = store.GetIdempotentResponse(ctx, "uss1", "retry-1")
+	require.NoError(t, err)
+	require.Nil(t, resp)
+	n, err := store.PruneIdempotentResponses(ctx, fakeClock.Now(), 100)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+}
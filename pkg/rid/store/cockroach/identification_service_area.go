@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"time"
+	"unicode/utf8"
 
 	dsserr "github.com/interuss/dss/pkg/errors"
 	"github.com/interuss/dss/pkg/geo"
@@ -17,10 +18,29 @@ import (
 )
 
 const (
-	isaFields       = "id, owner, url, cells, starts_at, ends_at, writer, updated_at"
-	updateISAFields = "id, url, cells, starts_at, ends_at, writer, updated_at"
+	isaFields       = "id, owner, url, cells, starts_at, ends_at, writer, updated_at, labels"
+	updateISAFields = "id, url, starts_at, ends_at, writer, updated_at"
 )
 
+// isaFields returns the columns of the ISAs read, and written with
+// isaExtentsFields last if r.isaExtents is set.
+func (r *repo) isaFields() string {
+	if r.isaExtents {
+		return isaFields + ", " + isaExtentsFields
+	}
+	return isaFields
+}
+
+// isaExtentsArgs returns the query arguments storing the altitudes and
+// footprint of isa, in the order of isaExtentsFields.
+func isaExtentsArgs(isa *ridmodels.IdentificationServiceArea) ([]interface{}, error) {
+	footprint, err := footprintArg(isa.Footprint)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error storing ISA footprint")
+	}
+	return []interface{}{isa.AltitudeLo, isa.AltitudeHi, footprint}, nil
+}
+
 func (r *repo) fetchISAs(ctx context.Context, query string, args ...interface{}) ([]*ridmodels.IdentificationServiceArea, error) {
 	rows, err := r.Query(ctx, query, args...)
 	if err != nil {
@@ -35,22 +55,35 @@ func (r *repo) fetchISAs(ctx context.Context, query string, args ...interface{})
 	for rows.Next() {
 		i := new(ridmodels.IdentificationServiceArea)
 
-		var updateTime time.Time
+		var (
+			updateTime time.Time
+			owner      string
+			footprint  *storedFootprint
+		)
 
-		err := rows.Scan(
+		dest := []interface{}{
 			&i.ID,
-			&i.Owner,
+			&owner,
 			&i.URL,
 			&cids,
 			&i.StartTime,
 			&i.EndTime,
 			&writer,
 			&updateTime,
-		)
+			&i.Labels,
+		}
+		if r.isaExtents {
+			dest = append(dest, &i.AltitudeLo, &i.AltitudeHi, &footprint)
+		}
+		err := rows.Scan(dest...)
 		if err != nil {
 			return nil, stacktrace.Propagate(err, "Error scanning ISA row")
 		}
+		if i.Owner, err = r.ownerFromStored(owner); err != nil {
+			return nil, err // No need to Propagate this error as this stack layer does not add useful information
+		}
 		i.Writer = writer.String
+		i.Footprint = footprintFromStored(footprint)
 		i.SetCells(cids)
 		i.Version = dssmodels.VersionFromTime(updateTime)
 		payload = append(payload, i)
@@ -79,16 +112,19 @@ func (r *repo) fetchISA(ctx context.Context, query string, args ...interface{})
 // GetISA returns the isa identified by "id".
 // Returns nil, nil if not found
 func (r *repo) GetISA(ctx context.Context, id dssmodels.ID, forUpdate bool) (*ridmodels.IdentificationServiceArea, error) {
-	var query = fmt.Sprintf(`
+	query := fmt.Sprintf(`
 		SELECT %s FROM
 			identification_service_areas
 		WHERE
 			id = $1
-        %s`, isaFields, dssql.ForUpdate(forUpdate))
+        %s`, r.isaFields(), dssql.ForUpdate(forUpdate))
 	uid, err := id.PgUUID()
 	if err != nil {
 		return nil, stacktrace.Propagate(err, "Failed to convert id to PgUUID")
 	}
+	if forUpdate {
+		defer r.timeStatement(ridmodels.ActivityISA, statementFetch)()
+	}
 	return r.fetchISA(ctx, query, uid)
 }
 
@@ -100,15 +136,19 @@ func (r *repo) GetISA(ctx context.Context, id dssmodels.ID, forUpdate bool) (*ri
 // TODO: Simplify the logic to insert without a query, such that the insert fails
 // if there's an existing entity.
 func (r *repo) InsertISA(ctx context.Context, isa *ridmodels.IdentificationServiceArea) (*ridmodels.IdentificationServiceArea, error) {
+	values := "$1, $2, $3, $4, $5, $6, $7, DEFAULT, $8"
+	if r.isaExtents {
+		values += ", $9, $10, $11"
+	}
 	var (
 		insertAreasQuery = fmt.Sprintf(`
 			INSERT INTO
 				identification_service_areas
 				(%s)
 			VALUES
-				($1, $2, $3, $4, $5, $6, $7, transaction_timestamp())
+				(%s)
 			RETURNING
-				%s`, isaFields, isaFields)
+				%s`, r.isaFields(), values, r.isaFields())
 	)
 
 	cids := make([]int64, len(isa.Cells))
@@ -124,8 +164,24 @@ func (r *repo) InsertISA(ctx context.Context, isa *ridmodels.IdentificationServi
 	if err != nil {
 		return nil, stacktrace.Propagate(err, "Failed to convert id to PgUUID")
 	}
-	return r.fetchISA(ctx, insertAreasQuery, id, isa.Owner, isa.URL, cids, isa.StartTime, isa.EndTime, isa.Writer)
-
+	args := []interface{}{id, r.storedOwner(isa.Owner), isa.URL, cids, isa.StartTime, isa.EndTime, isa.Writer, labelsArg(isa.Labels)}
+	if r.isaExtents {
+		extents, err := isaExtentsArgs(isa)
+		if err != nil {
+			return nil, err // No need to Propagate this error as this stack layer does not add useful information
+		}
+		args = append(args, extents...)
+	}
+	done := r.timeStatement(ridmodels.ActivityISA, statementInsert)
+	ret, err := r.fetchISA(ctx, insertAreasQuery, args...)
+	done()
+	if err != nil || ret == nil {
+		return ret, err
+	}
+	if err := r.recordActivity(ctx, ridmodels.ActivityISA, ret.ID, ridmodels.ActivityCreated, ret.StartTime, ret.EndTime); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return ret, nil
 }
 
 // UpdateISA updates the IdentificationServiceArea identified by "id" and owned
@@ -136,16 +192,6 @@ func (r *repo) InsertISA(ctx context.Context, isa *ridmodels.IdentificationServi
 // TODO: simplify the logic to just update, without the primary query.
 // Returns nil, nil if ID, version not found
 func (r *repo) UpdateISA(ctx context.Context, isa *ridmodels.IdentificationServiceArea) (*ridmodels.IdentificationServiceArea, error) {
-	var (
-		updateAreasQuery = fmt.Sprintf(`
-			UPDATE
-				identification_service_areas
-			SET	(%s) = ($1, $2, $3, $4, $5, $7, transaction_timestamp())
-			WHERE id = $1 AND updated_at = $6
-			RETURNING
-				%s`, updateISAFields, isaFields)
-	)
-
 	cids, err := dssql.CellUnionToCellIdsWithValidation(isa.Cells)
 	if err != nil {
 		return nil, stacktrace.Propagate(err, "Failed to convert array to jackc/pgtype")
@@ -154,7 +200,33 @@ func (r *repo) UpdateISA(ctx context.Context, isa *ridmodels.IdentificationServi
 	if err != nil {
 		return nil, stacktrace.Propagate(err, "Failed to convert id to PgUUID")
 	}
-	return r.fetchISA(ctx, updateAreasQuery, id, isa.URL, cids, isa.StartTime, isa.EndTime, isa.Version.ToTimestamp(), isa.Writer)
+	fields, values := updateISAFields, "$1, $2, $3, $4, $6, DEFAULT"
+	args := []interface{}{id, isa.URL, isa.StartTime, isa.EndTime, isa.Version.ToTimestamp(), isa.Writer}
+	unchanged, err := r.storesCells(ctx, "identification_service_areas", *id, isa.Version.ToTimestamp(), cids)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if !unchanged {
+		args = append(args, cids)
+		fields, values = fields+", cells", values+fmt.Sprintf(", $%d", len(args))
+	}
+	if r.isaExtents {
+		extents, err := isaExtentsArgs(isa)
+		if err != nil {
+			return nil, err // No need to Propagate this error as this stack layer does not add useful information
+		}
+		args = append(args, extents...)
+		fields, values = fields+", "+isaExtentsFields, values+fmt.Sprintf(", $%d, $%d, $%d", len(args)-2, len(args)-1, len(args))
+	}
+	updateAreasQuery := fmt.Sprintf(`
+			UPDATE
+				identification_service_areas
+			SET	(%s) = (%s)
+			WHERE id = $1 AND updated_at = $5
+			RETURNING
+				%s`, fields, values, r.isaFields())
+	defer r.timeStatement(ridmodels.ActivityISA, statementUpdate)()
+	return r.fetchISA(ctx, updateAreasQuery, args...)
 }
 
 // DeleteISA deletes the IdentificationServiceArea identified by "id" and owned by "owner".
@@ -169,23 +241,32 @@ func (r *repo) DeleteISA(ctx context.Context, isa *ridmodels.IdentificationServi
 				id = $1
 			AND
 				updated_at = $2
-			RETURNING %s`, isaFields)
+			RETURNING %s`, r.isaFields())
 	)
 	id, err := isa.ID.PgUUID()
 	if err != nil {
 		return nil, stacktrace.Propagate(err, "Failed to convert id to PgUUID")
 	}
-	return r.fetchISA(ctx, deleteQuery, id, isa.Version.ToTimestamp())
+	done := r.timeStatement(ridmodels.ActivityISA, statementDelete)
+	ret, err := r.fetchISA(ctx, deleteQuery, id, isa.Version.ToTimestamp())
+	done()
+	if err != nil || ret == nil {
+		return ret, err
+	}
+	if err := r.recordActivity(ctx, ridmodels.ActivityISA, ret.ID, ridmodels.ActivityDeleted, ret.StartTime, ret.EndTime); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return ret, nil
 }
 
 // SearchISAs searches IdentificationServiceArea
 // instances that intersect with "cells" and, if set, the temporal volume
-// defined by "earliest" and "latest".
-func (r *repo) SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time) ([]*ridmodels.IdentificationServiceArea, error) {
-	var (
-		// TODO: make earliest and latest required (NOT NULL) and remove coalesce.
-		// Make them real values (not pointers), on the model layer.
-		isasInCellsQuery = fmt.Sprintf(`
+// defined by "earliest" and "latest", excluding those owned by
+// "excludeOwner" if set.
+func (r *repo) SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time, excludeOwner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error) {
+	// TODO: make earliest and latest required (NOT NULL) and remove coalesce.
+	// Make them real values (not pointers), on the model layer.
+	isasInCellsQuery := fmt.Sprintf(`
 			SELECT
 				%s
 			FROM
@@ -195,9 +276,10 @@ func (r *repo) SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *tim
 			AND
 				COALESCE(starts_at <= $2, true)
 			AND
-				cells && $3
-			LIMIT $4`, isaFields)
-	)
+				%s
+			AND
+				($5 = '' OR owner <> $5)
+			LIMIT $4`, r.isaFields(), dssql.CellsIntersect("cells", "$3"))
 
 	if len(cells) == 0 {
 		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing cell IDs for query")
@@ -207,7 +289,223 @@ func (r *repo) SearchISAs(ctx context.Context, cells s2.CellUnion, earliest *tim
 		return nil, stacktrace.NewError("Earliest start time is missing")
 	}
 
-	return r.fetchISAs(ctx, isasInCellsQuery, earliest, latest, dssql.CellUnionToCellIds(cells), dssmodels.MaxResultLimit)
+	excluded := ""
+	if excludeOwner != "" {
+		excluded = r.storedOwner(excludeOwner)
+	}
+
+	return r.fetchISAs(ctx, isasInCellsQuery, earliest, latest, dssql.CellUnionToCellIds(cells), dssmodels.MaxResultLimit, excluded)
+}
+
+// CountISAs returns the number, up to "limit", of the
+// IdentificationServiceAreas in "cells" SearchISAs returns without an
+// excluded owner, without fetching them.
+func (r *repo) CountISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time, limit int) (int, error) {
+	var (
+		countISAsInCellsQuery = fmt.Sprintf(`
+			SELECT
+				COUNT(*)
+			FROM (
+				SELECT
+					1
+				FROM
+					identification_service_areas
+				WHERE
+					ends_at >= $1
+				AND
+					COALESCE(starts_at <= $2, true)
+				AND
+					%s
+				LIMIT $4
+			) AS matching`, dssql.CellsIntersect("cells", "$3"))
+	)
+
+	if len(cells) == 0 {
+		return 0, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing cell IDs for query")
+	}
+
+	if earliest == nil {
+		return 0, stacktrace.NewError("Earliest start time is missing")
+	}
+
+	var count int
+	err := r.QueryRow(ctx, countISAsInCellsQuery, earliest, latest, dssql.CellUnionToCellIds(cells), limit).Scan(&count)
+	if err != nil {
+		return 0, stacktrace.Propagate(err, "Error counting ISAs")
+	}
+	return count, nil
+}
+
+// ListISAsByOwner returns all IdentificationServiceAreas owned by "owner"
+// that have not ended yet.
+func (r *repo) ListISAsByOwner(ctx context.Context, owner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error) {
+	var (
+		isasByOwnerQuery = fmt.Sprintf(`
+			SELECT
+				%s
+			FROM
+				identification_service_areas
+			WHERE
+				owner = $1
+			AND
+				ends_at >= $2`, r.isaFields())
+	)
+
+	return r.fetchISAs(ctx, isasByOwnerQuery, r.storedOwner(owner), r.clock.Now())
+}
+
+// CountISAsByOwner returns the number of IdentificationServiceAreas owned by
+// "owner" that have not ended yet, without fetching them.
+func (r *repo) CountISAsByOwner(ctx context.Context, owner dssmodels.Owner) (int, error) {
+	const countISAsByOwnerQuery = `
+		SELECT
+			COUNT(*)
+		FROM
+			identification_service_areas
+		WHERE
+			owner = $1
+		AND
+			ends_at >= $2`
+
+	var count int
+	if err := r.QueryRow(ctx, countISAsByOwnerQuery, r.storedOwner(owner), r.clock.Now()).Scan(&count); err != nil {
+		return 0, stacktrace.Propagate(err, "Error counting ISAs of %s", owner)
+	}
+	return count, nil
+}
+
+// UpdateISALabels replaces the labels of the IdentificationServiceArea
+// identified by "id", leaving its version unchanged.
+// Returns nil, nil if not found
+func (r *repo) UpdateISALabels(ctx context.Context, id dssmodels.ID, labels ridmodels.Labels) (*ridmodels.IdentificationServiceArea, error) {
+	var (
+		updateLabelsQuery = fmt.Sprintf(`
+			UPDATE
+				identification_service_areas
+			SET labels = $2
+			WHERE id = $1
+			RETURNING
+				%s`, r.isaFields())
+	)
+	uid, err := id.PgUUID()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to convert id to PgUUID")
+	}
+	return r.fetchISA(ctx, updateLabelsQuery, uid, labelsArg(labels))
+}
+
+// TransferISA makes "owner" the owner of the IdentificationServiceArea
+// identified by "id", giving it a new version.
+// Returns nil, nil if not found
+func (r *repo) TransferISA(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner) (*ridmodels.IdentificationServiceArea, error) {
+	var (
+		transferQuery = fmt.Sprintf(`
+			UPDATE
+				identification_service_areas
+			SET (owner, updated_at) = ($2, DEFAULT)
+			WHERE id = $1
+			RETURNING
+				%s`, r.isaFields())
+	)
+	uid, err := id.PgUUID()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to convert id to PgUUID")
+	}
+	return r.fetchISA(ctx, transferQuery, uid, r.storedOwner(owner))
+}
+
+// SearchISAsByLabels returns the IdentificationServiceAreas that have not
+// ended yet and carry all of "labels".
+func (r *repo) SearchISAsByLabels(ctx context.Context, labels ridmodels.Labels) ([]*ridmodels.IdentificationServiceArea, error) {
+	var (
+		isasByLabelsQuery = fmt.Sprintf(`
+			SELECT
+				%s
+			FROM
+				identification_service_areas
+			WHERE
+				labels @> $1
+			AND
+				ends_at >= $2
+			LIMIT $3`, r.isaFields())
+	)
+
+	if len(labels) == 0 {
+		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing labels for query")
+	}
+
+	return r.fetchISAs(ctx, isasByLabelsQuery, labels, r.clock.Now(), dssmodels.MaxResultLimit)
+}
+
+// SearchISAsByURL returns the IdentificationServiceAreas of all owners that
+// have not ended yet and whose flights URL is "url" or, if "prefix" is set,
+// starts with "url". Prefixes are searched as a range of URLs so that the
+// index on URLs serves them.
+func (r *repo) SearchISAsByURL(ctx context.Context, url string, prefix bool) ([]*ridmodels.IdentificationServiceArea, error) {
+	if url == "" {
+		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing URL for query")
+	}
+	if !prefix {
+		isasByURLQuery := fmt.Sprintf(`
+			SELECT
+				%s
+			FROM
+				identification_service_areas
+			WHERE
+				url = $1
+			AND
+				ends_at >= $2
+			LIMIT $3`, r.isaFields())
+		return r.fetchISAs(ctx, isasByURLQuery, url, r.clock.Now(), dssmodels.MaxResultLimit)
+	}
+
+	upper, bounded := prefixUpperBound(url)
+	if !bounded {
+		isasByURLPrefixQuery := fmt.Sprintf(`
+			SELECT
+				%s
+			FROM
+				identification_service_areas
+			WHERE
+				url >= $1
+			AND
+				ends_at >= $2
+			LIMIT $3`, r.isaFields())
+		return r.fetchISAs(ctx, isasByURLPrefixQuery, url, r.clock.Now(), dssmodels.MaxResultLimit)
+	}
+	isasByURLRangeQuery := fmt.Sprintf(`
+		SELECT
+			%s
+		FROM
+			identification_service_areas
+		WHERE
+			url >= $1
+		AND
+			url < $2
+		AND
+			ends_at >= $3
+		LIMIT $4`, r.isaFields())
+	return r.fetchISAs(ctx, isasByURLRangeQuery, url, upper, r.clock.Now(), dssmodels.MaxResultLimit)
+}
+
+// prefixUpperBound returns the smallest string greater than all the strings
+// starting with prefix, or false if there is none. Strings are compared by
+// bytes, which orders UTF-8 strings by code point, so the bound is the prefix
+// truncated after its last code point below utf8.MaxRune, incremented.
+func prefixUpperBound(prefix string) (string, bool) {
+	runes := []rune(prefix)
+	for i := len(runes) - 1; i >= 0; i-- {
+		next := runes[i] + 1
+		if next >= 0xD800 && next <= 0xDFFF {
+			// Surrogates are not valid code points.
+			next = 0xE000
+		}
+		if next <= utf8.MaxRune {
+			runes[i] = next
+			return string(runes[:i+1]), true
+		}
+	}
+	return "", false
 }
 
 // ListExpiredISAs lists all expired ISAs based on writer.
@@ -229,7 +527,7 @@ func (r *repo) ListExpiredISAs(ctx context.Context, writer string) ([]*ridmodels
 		ends_at + INTERVAL '%d' MINUTE <= CURRENT_TIMESTAMP
 	AND
 		(writer = %s)
-	LIMIT $1`, isaFields, expiredDurationInMin, writerQuery)
+	LIMIT $1`, r.isaFields(), expiredDurationInMin, writerQuery)
 	)
 
 	return r.fetchISAs(ctx, isasInCellsQuery, dssmodels.MaxResultLimit)
@@ -1,12 +1,14 @@
 package cockroach
 
 import (
+	"bytes"
 	"context"
 	"testing"
 	"time"
 
 	"github.com/golang/geo/s2"
 	"github.com/google/uuid"
+	"github.com/interuss/dss/pkg/datastore/owners"
 	dssmodels "github.com/interuss/dss/pkg/models"
 	ridmodels "github.com/interuss/dss/pkg/rid/models"
 	"github.com/interuss/dss/pkg/rid/repos"
@@ -133,9 +135,13 @@ func TestStoreSearchISAs(t *testing.T) {
 		t.Run(r.name, func(t *testing.T) {
 			earliest, latest := r.timestampMutator(*saOut.StartTime, *saOut.EndTime)
 
-			serviceAreas, err := repo.SearchISAs(ctx, r.cells, earliest, latest)
+			serviceAreas, err := repo.SearchISAs(ctx, r.cells, earliest, latest, "")
 			require.NoError(t, err)
 			require.Len(t, serviceAreas, r.expectedLen)
+
+			count, err := repo.CountISAs(ctx, r.cells, earliest, latest, 10)
+			require.NoError(t, err)
+			require.Equal(t, r.expectedLen, count)
 		})
 	}
 }
@@ -182,7 +188,7 @@ func TestStoreExpiredISA(t *testing.T) {
 
 	// We should still be able to find the ISA by searching and by ID.
 	now := fakeClock.Now()
-	serviceAreas, err := repo.SearchISAs(ctx, serviceArea.Cells, &now, nil)
+	serviceAreas, err := repo.SearchISAs(ctx, serviceArea.Cells, &now, nil, "")
 	require.NoError(t, err)
 	require.Len(t, serviceAreas, 1)
 
@@ -194,7 +200,7 @@ func TestStoreExpiredISA(t *testing.T) {
 	fakeClock.Advance(2 * time.Minute)
 	now = fakeClock.Now()
 
-	serviceAreas, err = repo.SearchISAs(ctx, serviceArea.Cells, &now, nil)
+	serviceAreas, err = repo.SearchISAs(ctx, serviceArea.Cells, &now, nil, "")
 	require.NoError(t, err)
 	require.Len(t, serviceAreas, 0)
 
@@ -225,11 +231,205 @@ func TestStoreDeleteISAs(t *testing.T) {
 	isa, err = repo.GetISA(ctx, isa.ID, false)
 	require.NoError(t, err)
 
-	serviceAreaOut, err := repo.DeleteISA(ctx, isa)
+	// ISAs are only deleted at their current version.
+	stale := *isa
+	stale.Version = dssmodels.VersionFromTime(isa.Version.ToTimestamp().Add(-time.Second))
+	serviceAreaOut, err := repo.DeleteISA(ctx, &stale)
+	require.NoError(t, err)
+	require.Nil(t, serviceAreaOut)
+
+	serviceAreaOut, err = repo.DeleteISA(ctx, isa)
 	require.NoError(t, err)
 	require.Equal(t, isa, serviceAreaOut)
 }
 
+func TestStoreListISAsByOwner(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	defer tearDownStore()
+
+	repo, err := store.Interact(ctx)
+	require.NoError(t, err)
+
+	copy := *serviceArea
+	isa, err := repo.InsertISA(ctx, &copy)
+	require.NoError(t, err)
+
+	isas, err := repo.ListISAsByOwner(ctx, serviceArea.Owner)
+	require.NoError(t, err)
+	require.Len(t, isas, 1)
+	require.Equal(t, isa.ID, isas[0].ID)
+
+	isas, err = repo.ListISAsByOwner(ctx, dssmodels.Owner("another owner"))
+	require.NoError(t, err)
+	require.Empty(t, isas)
+
+	count, err := repo.CountISAsByOwner(ctx, serviceArea.Owner)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	count, err = repo.CountISAsByOwner(ctx, dssmodels.Owner("another owner"))
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestStoreSearchISAsByURL(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	defer tearDownStore()
+
+	repo, err := store.Interact(ctx)
+	require.NoError(t, err)
+
+	var ids []dssmodels.ID
+	for _, url := range []string{"https://uss1.example/flights", "https://uss1.example/flights/v2", "https://uss2.example/flights"} {
+		copy := *serviceArea
+		copy.ID = dssmodels.ID(uuid.New().String())
+		copy.Owner = dssmodels.Owner(uuid.New().String())
+		copy.URL = url
+		isa, err := repo.InsertISA(ctx, &copy)
+		require.NoError(t, err)
+		ids = append(ids, isa.ID)
+	}
+	idsOf := func(isas []*ridmodels.IdentificationServiceArea) []dssmodels.ID {
+		var found []dssmodels.ID
+		for _, isa := range isas {
+			found = append(found, isa.ID)
+		}
+		return found
+	}
+
+	isas, err := repo.SearchISAsByURL(ctx, "https://uss1.example/flights", false)
+	require.NoError(t, err)
+	require.ElementsMatch(t, ids[:1], idsOf(isas))
+
+	isas, err = repo.SearchISAsByURL(ctx, "https://uss1.example/", true)
+	require.NoError(t, err)
+	require.ElementsMatch(t, ids[:2], idsOf(isas))
+
+	isas, err = repo.SearchISAsByURL(ctx, "https://uss", true)
+	require.NoError(t, err)
+	require.ElementsMatch(t, ids, idsOf(isas))
+
+	isas, err = repo.SearchISAsByURL(ctx, "https://uss3.example/", true)
+	require.NoError(t, err)
+	require.Empty(t, isas)
+
+	_, err = repo.SearchISAsByURL(ctx, "", true)
+	require.Error(t, err)
+}
+
+func TestPrefixUpperBound(t *testing.T) {
+	for _, tc := range []struct {
+		prefix  string
+		upper   string
+		bounded bool
+	}{
+		{"https://uss1.example/", "https://uss1.example0", true},
+		{"https://uss1.example/\U0010FFFF", "https://uss1.example0", true},
+		{"https://uss1.example/\uD7FF", "https://uss1.example/\uE000", true},
+		{"\U0010FFFF", "", false},
+		{"", "", false},
+	} {
+		upper, bounded := prefixUpperBound(tc.prefix)
+		require.Equal(t, tc.bounded, bounded, tc.prefix)
+		require.Equal(t, tc.upper, upper, tc.prefix)
+		if bounded {
+			require.Less(t, tc.prefix+"\U0010FFFF", upper)
+		}
+	}
+}
+
+func TestStoreSealedOwners(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	defer tearDownStore()
+
+	codec, err := owners.NewSealed(bytes.Repeat([]byte{1}, owners.MinKeySize))
+	require.NoError(t, err)
+	store.UseOwnerCodec(codec)
+
+	repo, err := store.Interact(ctx)
+	require.NoError(t, err)
+
+	copy := *serviceArea
+	isa, err := repo.InsertISA(ctx, &copy)
+	require.NoError(t, err)
+	require.Equal(t, serviceArea.Owner, isa.Owner)
+
+	var stored string
+	require.NoError(t, store.db.Pool.QueryRow(ctx, "SELECT owner FROM identification_service_areas WHERE id = $1", isa.ID.String()).Scan(&stored))
+	require.NotContains(t, stored, serviceArea.Owner.String())
+
+	isas, err := repo.ListISAsByOwner(ctx, serviceArea.Owner)
+	require.NoError(t, err)
+	require.Len(t, isas, 1)
+	require.Equal(t, serviceArea.Owner, isas[0].Owner)
+}
+
+func TestStoreISALabels(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	defer tearDownStore()
+
+	repo, err := store.Interact(ctx)
+	require.NoError(t, err)
+
+	copy := *serviceArea
+	copy.Labels = ridmodels.Labels{"env": "test"}
+	isa, err := repo.InsertISA(ctx, &copy)
+	require.NoError(t, err)
+	require.Equal(t, copy.Labels, isa.Labels)
+
+	labeled, err := repo.UpdateISALabels(ctx, isa.ID, ridmodels.Labels{"env": "test", "flight": "F42"})
+	require.NoError(t, err)
+	require.Equal(t, isa.Version, labeled.Version)
+
+	isas, err := repo.SearchISAsByLabels(ctx, ridmodels.Labels{"flight": "F42"})
+	require.NoError(t, err)
+	require.Len(t, isas, 1)
+	require.Equal(t, isa.ID, isas[0].ID)
+
+	isas, err = repo.SearchISAsByLabels(ctx, ridmodels.Labels{"flight": "F43"})
+	require.NoError(t, err)
+	require.Empty(t, isas)
+}
+
+func TestStoreTransferISA(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	defer tearDownStore()
+
+	repo, err := store.Interact(ctx)
+	require.NoError(t, err)
+
+	isa, err := repo.InsertISA(ctx, serviceArea)
+	require.NoError(t, err)
+
+	transferred, err := repo.TransferISA(ctx, isa.ID, "new-owner")
+	require.NoError(t, err)
+	require.Equal(t, dssmodels.Owner("new-owner"), transferred.Owner)
+	require.NotEqual(t, isa.Version, transferred.Version)
+
+	isas, err := repo.ListISAsByOwner(ctx, "new-owner")
+	require.NoError(t, err)
+	require.Len(t, isas, 1)
+
+	transferred, err = repo.TransferISA(ctx, dssmodels.ID(uuid.New().String()), "new-owner")
+	require.NoError(t, err)
+	require.Nil(t, transferred)
+}
+
 func TestStoreISAWithNoGeoData(t *testing.T) {
 	ctx := context.Background()
 	store, tearDownStore := setUpStore(ctx, t)
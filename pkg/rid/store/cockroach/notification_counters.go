@@ -0,0 +1,193 @@
+package cockroach
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/golang/geo/s2"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	dssql "github.com/interuss/dss/pkg/sql"
+	"github.com/interuss/stacktrace"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Subscription notification indices are maintained in one of two ways:
+//
+//   - In subscription rows (the default): every ISA change increments the
+//     notification_index column of the affected subscriptions in place, which
+//     makes popular subscriptions contended rows.
+//   - In notification counters: every ISA change increments one of several
+//     narrow counter rows of each affected subscription, chosen at random, so
+//     that concurrent ISA changes rarely write the same row. The notification
+//     index of a subscription is then its notification_index column plus the
+//     increments pending in its counters, which FoldNotificationCounters
+//     periodically folds back into the column.
+
+var (
+	// notificationCountersSchemaVersion is the schema version introducing
+	// the notification counters table.
+	notificationCountersSchemaVersion = semver.New("4.3.0")
+)
+
+// UseNotificationCounters makes the Store maintain notification indices in
+// "shards" counters per subscription rather than in subscription rows. It must
+// be called before the Store is used.
+func (s *Store) UseNotificationCounters(shards int) error {
+	if shards < 1 {
+		return stacktrace.NewError("Notification counters require at least one shard, got %d", shards)
+	}
+	if s.version != nil && s.version.LessThan(*notificationCountersSchemaVersion) {
+		return stacktrace.NewError("Notification counters require remote ID schema version %s or later, got %s", notificationCountersSchemaVersion, s.version)
+	}
+	s.counterShards = shards
+	return nil
+}
+
+// FoldNotificationCounters moves the increments pending in the notification
+// counters of at most "limit" subscriptions into their notification_index
+// column, and returns the number of subscriptions updated.
+func (s *Store) FoldNotificationCounters(ctx context.Context, limit int) (int64, error) {
+	const query = `
+		WITH folded AS (
+			DELETE FROM subscription_notification_counters
+			WHERE subscription_id IN (
+				SELECT DISTINCT subscription_id FROM subscription_notification_counters LIMIT $1
+			)
+			RETURNING subscription_id, increments
+		)
+		UPDATE subscriptions
+		SET notification_index = notification_index + pending.increments
+		FROM (
+			SELECT subscription_id, sum(increments)::INT8 AS increments FROM folded GROUP BY subscription_id
+		) AS pending
+		WHERE subscriptions.id = pending.subscription_id`
+
+	tag, err := s.db.Pool.Exec(ctx, query, limit)
+	if err != nil {
+		return 0, stacktrace.Propagate(err, "Error folding notification counters")
+	}
+	return tag.RowsAffected(), nil
+}
+
+// addPendingNotifications adds to the notification index of subs the
+// increments pending in their notification counters.
+func (r *repo) addPendingNotifications(ctx context.Context, subs []*ridmodels.Subscription) error {
+	if r.counterShards == 0 || len(subs) == 0 {
+		return nil
+	}
+	const query = `
+		SELECT
+			subscription_id, sum(increments)::INT8
+		FROM
+			subscription_notification_counters
+		WHERE
+			subscription_id = ANY($1)
+		GROUP BY
+			subscription_id`
+
+	ids, err := subscriptionIDs(subs)
+	if err != nil {
+		return err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	rows, err := r.Query(ctx, query, ids)
+	if err != nil {
+		return stacktrace.Propagate(err, "Error in query: %s", query)
+	}
+	defer rows.Close()
+
+	pending := map[dssmodels.ID]int{}
+	for rows.Next() {
+		var (
+			id         dssmodels.ID
+			increments int64
+		)
+		if err := rows.Scan(&id, &increments); err != nil {
+			return stacktrace.Propagate(err, "Error scanning notification counter row")
+		}
+		pending[id] = int(increments)
+	}
+	if err := rows.Err(); err != nil {
+		return stacktrace.Propagate(err, "Error in rows query result")
+	}
+
+	for _, s := range subs {
+		s.NotificationIndex += pending[s.ID]
+	}
+	return nil
+}
+
+// clearNotificationCounters discards the increments pending in the
+// notification counters of the subscription identified by "id".
+func (r *repo) clearNotificationCounters(ctx context.Context, id dssmodels.ID) error {
+	if r.counterShards == 0 {
+		return nil
+	}
+	uid, err := id.PgUUID()
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to convert id to PgUUID")
+	}
+	defer r.timeStatement(ridmodels.ActivitySubscription, statementClearCounters)()
+	if _, err := r.Exec(ctx, `DELETE FROM subscription_notification_counters WHERE subscription_id = $1`, uid); err != nil {
+		return stacktrace.Propagate(err, "Error clearing notification counters")
+	}
+	return nil
+}
+
+// incrementNotificationCountersInCells is the notification counters
+// counterpart of UpdateNotificationIdxsInCells.
+func (r *repo) incrementNotificationCountersInCells(ctx context.Context, cells s2.CellUnion, owner dssmodels.Owner, startTime, endTime *time.Time) ([]*ridmodels.Subscription, error) {
+	var (
+		selectQuery = fmt.Sprintf(`
+			SELECT
+				%s
+			FROM
+				subscriptions
+			WHERE
+				%s`, r.subscriptionFields(), r.notifiedSubscriptionsCondition())
+		incrementQuery = `
+			INSERT INTO
+				subscription_notification_counters
+				(subscription_id, shard, increments)
+			SELECT
+				subscription_id, $2, 1
+			FROM
+				unnest($1::UUID[]) AS subscription_id
+			ON CONFLICT (subscription_id, shard) DO UPDATE
+			SET increments = subscription_notification_counters.increments + 1`
+	)
+
+	subs, err := r.scan(ctx, selectQuery, dssql.CellUnionToCellIds(cells), r.clock.Now(), r.storedOwner(owner), startTime, endTime)
+	if err != nil || len(subs) == 0 {
+		return subs, err
+	}
+
+	ids, err := subscriptionIDs(subs)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if _, err := r.Exec(ctx, incrementQuery, ids, rand.Intn(r.counterShards)); err != nil {
+		return nil, stacktrace.Propagate(err, "Error incrementing notification counters")
+	}
+
+	if err := r.addPendingNotifications(ctx, subs); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	setPreviousNotificationIndices(subs)
+	return subs, nil
+}
+
+func subscriptionIDs(subs []*ridmodels.Subscription) ([]pgtype.UUID, error) {
+	ids := make([]pgtype.UUID, len(subs))
+	for i, s := range subs {
+		id, err := s.ID.PgUUID()
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Failed to convert id to PgUUID")
+		}
+		ids[i] = *id
+	}
+	return ids, nil
+}
@@ -0,0 +1,136 @@
+package cockroach
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/uuid"
+	"github.com/interuss/dss/pkg/datastore/testdb"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/dss/pkg/rid/repos"
+	"github.com/stretchr/testify/require"
+)
+
+var notifiedCells = s2.CellUnion{12494535935418957824}
+
+func insertNotifiedSubscription(ctx context.Context, t testing.TB, repo repos.Repository) *ridmodels.Subscription {
+	sub, err := repo.InsertSubscription(ctx, &ridmodels.Subscription{
+		ID:        dssmodels.ID(uuid.New().String()),
+		Owner:     "subscriber",
+		URL:       "https://no/place/like/home",
+		StartTime: &startTime,
+		EndTime:   &endTime,
+		Cells:     notifiedCells,
+	})
+	require.NoError(t, err)
+	return sub
+}
+
+func TestNotificationCounters(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	defer tearDownStore()
+	require.NoError(t, store.UseNotificationCounters(4))
+
+	repo, err := store.Interact(ctx)
+	require.NoError(t, err)
+	sub := insertNotifiedSubscription(ctx, t, repo)
+	require.Equal(t, 0, sub.NotificationIndex)
+
+	for i := 1; i <= 10; i++ {
+		subs, err := repo.UpdateNotificationIdxsInCells(ctx, notifiedCells, "isa owner", nil, nil)
+		require.NoError(t, err)
+		require.Len(t, subs, 1)
+		require.Equal(t, i, subs[0].NotificationIndex)
+		require.Equal(t, i-1, subs[0].PreviousNotificationIndex)
+	}
+
+	// Subscriptions of the ISA owner are not notified.
+	subs, err := repo.UpdateNotificationIdxsInCells(ctx, notifiedCells, "subscriber", nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, subs)
+
+	got, err := repo.GetSubscription(ctx, sub.ID, false)
+	require.NoError(t, err)
+	require.Equal(t, 10, got.NotificationIndex)
+	require.Equal(t, sub.Version, got.Version)
+
+	// Folding counters preserves notification indices.
+	n, err := store.FoldNotificationCounters(ctx, 100)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+	got, err = repo.GetSubscription(ctx, sub.ID, false)
+	require.NoError(t, err)
+	require.Equal(t, 10, got.NotificationIndex)
+	require.Equal(t, sub.Version, got.Version)
+
+	subs, err = repo.UpdateNotificationIdxsInCells(ctx, notifiedCells, "isa owner", nil, nil)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	require.Equal(t, 11, subs[0].NotificationIndex)
+
+	// Updating a subscription sets its notification index.
+	got.NotificationIndex = 3
+	updated, err := repo.UpdateSubscription(ctx, got)
+	require.NoError(t, err)
+	require.Equal(t, 3, updated.NotificationIndex)
+	got, err = repo.GetSubscription(ctx, sub.ID, false)
+	require.NoError(t, err)
+	require.Equal(t, 3, got.NotificationIndex)
+
+	_, err = repo.UpdateNotificationIdxsInCells(ctx, notifiedCells, "isa owner", nil, nil)
+	require.NoError(t, err)
+	deleted, err := repo.DeleteSubscription(ctx, got)
+	require.NoError(t, err)
+	require.Equal(t, 4, deleted.NotificationIndex)
+}
+
+func TestUseNotificationCountersRequiresShards(t *testing.T) {
+	require.Error(t, (&Store{}).UseNotificationCounters(0))
+	require.NoError(t, (&Store{}).UseNotificationCounters(1))
+}
+
+// BenchmarkUpdateNotificationIdxsInCells measures the throughput of
+// concurrent ISA changes notifying the same subscriptions.
+func BenchmarkUpdateNotificationIdxsInCells(b *testing.B) {
+	for _, shards := range []int{0, 8} {
+		b.Run(fmt.Sprintf("counter_shards=%d", shards), func(b *testing.B) {
+			ctx := context.Background()
+			store, err := newStore(ctx, b, testdb.ConnectParameters(b, "rid"))
+			require.NoError(b, err)
+			defer func() {
+				require.NoError(b, CleanUp(ctx, store))
+				require.NoError(b, store.Close())
+			}()
+			if shards > 0 {
+				require.NoError(b, store.UseNotificationCounters(shards))
+			}
+
+			repo, err := store.Interact(ctx)
+			require.NoError(b, err)
+			for i := 0; i < 10; i++ {
+				insertNotifiedSubscription(ctx, b, repo)
+			}
+
+			var retries int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					err := store.Transact(ctx, func(repo repos.Repository) error {
+						atomic.AddInt64(&retries, 1)
+						_, err := repo.UpdateNotificationIdxsInCells(ctx, notifiedCells, "isa owner", nil, nil)
+						return err
+					})
+					require.NoError(b, err)
+				}
+			})
+			b.ReportMetric(float64(retries-int64(b.N))/float64(b.N), "retries/op")
+		})
+	}
+}
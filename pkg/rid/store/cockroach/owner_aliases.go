@@ -0,0 +1,127 @@
+package cockroach
+
+import (
+	"context"
+	"sort"
+
+	"github.com/coreos/go-semver/semver"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+)
+
+var (
+	// ownerAliasesSchemaVersion is the schema version introducing the owner
+	// aliases table.
+	ownerAliasesSchemaVersion = semver.New("4.8.0")
+)
+
+// storesOwnerAliases returns whether the schema of s stores owner aliases.
+func (s *Store) storesOwnerAliases() bool {
+	return s.version == nil || !s.version.LessThan(*ownerAliasesSchemaVersion)
+}
+
+// errOwnerAliasesUnsupported is returned by the changes of owner aliases on
+// schemas older than ownerAliasesSchemaVersion.
+func errOwnerAliasesUnsupported() error {
+	return stacktrace.NewErrorWithCode(dsserr.NotFound, "Owner aliases require remote ID schema version %s or later", ownerAliasesSchemaVersion)
+}
+
+// ListOwnerAliases implements repos.OwnerAlias.ListOwnerAliases. There are
+// no aliases on schemas older than ownerAliasesSchemaVersion.
+func (r *repo) ListOwnerAliases(ctx context.Context) ([]*ridmodels.OwnerAlias, error) {
+	if !r.ownerAliases {
+		return nil, nil
+	}
+	aliases, err := r.scanOwnerAliases(ctx, `SELECT subject, owner, updated_at FROM owner_aliases`)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	// Subjects are sorted once decoded, as the codec of owners does not
+	// preserve their order.
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Subject < aliases[j].Subject })
+	return aliases, nil
+}
+
+// UpsertOwnerAlias implements repos.OwnerAlias.UpsertOwnerAlias.
+func (r *repo) UpsertOwnerAlias(ctx context.Context, alias *ridmodels.OwnerAlias) (*ridmodels.OwnerAlias, error) {
+	if !r.ownerAliases {
+		return nil, errOwnerAliasesUnsupported()
+	}
+	const query = `
+		INSERT INTO
+			owner_aliases
+			(subject, owner, updated_at)
+		VALUES
+			($1, $2, $3)
+		ON CONFLICT (subject) DO UPDATE SET
+			owner = excluded.owner,
+			updated_at = excluded.updated_at
+		RETURNING
+			subject, owner, updated_at`
+
+	aliases, err := r.scanOwnerAliases(ctx, query, r.storedOwner(alias.Subject), r.storedOwner(alias.Owner), r.clock.Now())
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error upserting alias of %s", alias.Subject)
+	}
+	if len(aliases) != 1 {
+		return nil, stacktrace.NewError("Upsert of alias returned %d rows when 1 was expected", len(aliases))
+	}
+	return aliases[0], nil
+}
+
+// DeleteOwnerAlias implements repos.OwnerAlias.DeleteOwnerAlias.
+func (r *repo) DeleteOwnerAlias(ctx context.Context, subject dssmodels.Owner) (*ridmodels.OwnerAlias, error) {
+	if !r.ownerAliases {
+		return nil, errOwnerAliasesUnsupported()
+	}
+	const query = `
+		DELETE FROM
+			owner_aliases
+		WHERE
+			subject = $1
+		RETURNING
+			subject, owner, updated_at`
+
+	aliases, err := r.scanOwnerAliases(ctx, query, r.storedOwner(subject))
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error deleting alias of %s", subject)
+	}
+	if len(aliases) == 0 {
+		return nil, nil
+	}
+	return aliases[0], nil
+}
+
+// scanOwnerAliases returns the aliases in the subject, owner and updated_at
+// columns of the rows returned by query.
+func (r *repo) scanOwnerAliases(ctx context.Context, query string, args ...interface{}) ([]*ridmodels.OwnerAlias, error) {
+	rows, err := r.Query(ctx, query, args...)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error in query: %s", query)
+	}
+	defer rows.Close()
+
+	var aliases []*ridmodels.OwnerAlias
+	for rows.Next() {
+		var (
+			alias          = &ridmodels.OwnerAlias{}
+			subject, owner string
+		)
+		if err := rows.Scan(&subject, &owner, &alias.UpdatedAt); err != nil {
+			return nil, stacktrace.Propagate(err, "Error scanning owner alias row")
+		}
+		if alias.Subject, err = r.ownerFromStored(subject); err != nil {
+			return nil, stacktrace.Propagate(err, "Error decoding aliased subject")
+		}
+		if alias.Owner, err = r.ownerFromStored(owner); err != nil {
+			return nil, stacktrace.Propagate(err, "Error decoding canonical owner")
+		}
+		aliases = append(aliases, alias)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, stacktrace.Propagate(err, "Error in rows query result")
+	}
+	return aliases, nil
+}
@@ -0,0 +1,63 @@
+package cockroach
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coreos/go-semver/semver"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreOwnerAliases(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	defer tearDownStore()
+
+	repo, err := store.Interact(ctx)
+	require.NoError(t, err)
+
+	alias, err := repo.UpsertOwnerAlias(ctx, &ridmodels.OwnerAlias{Subject: "new-idp-subject", Owner: "uss1"})
+	require.NoError(t, err)
+	require.EqualValues(t, "new-idp-subject", alias.Subject)
+	require.EqualValues(t, "uss1", alias.Owner)
+	require.NotNil(t, alias.UpdatedAt)
+	_, err = repo.UpsertOwnerAlias(ctx, &ridmodels.OwnerAlias{Subject: "another-subject", Owner: "uss2"})
+	require.NoError(t, err)
+
+	// Aliases are replaced.
+	alias, err = repo.UpsertOwnerAlias(ctx, &ridmodels.OwnerAlias{Subject: "new-idp-subject", Owner: "uss3"})
+	require.NoError(t, err)
+	require.EqualValues(t, "uss3", alias.Owner)
+
+	aliases, err := repo.ListOwnerAliases(ctx)
+	require.NoError(t, err)
+	require.Len(t, aliases, 2)
+	require.EqualValues(t, "another-subject", aliases[0].Subject)
+	require.EqualValues(t, "new-idp-subject", aliases[1].Subject)
+	require.EqualValues(t, "uss3", aliases[1].Owner)
+
+	alias, err = repo.DeleteOwnerAlias(ctx, "new-idp-subject")
+	require.NoError(t, err)
+	require.EqualValues(t, "uss3", alias.Owner)
+	alias, err = repo.DeleteOwnerAlias(ctx, "new-idp-subject")
+	require.NoError(t, err)
+	require.Nil(t, alias)
+}
+
+func TestOwnerAliasesRequireSchema(t *testing.T) {
+	ctx := context.Background()
+	require.False(t, (&Store{version: semver.New("4.7.0")}).storesOwnerAliases())
+	require.True(t, (&Store{version: semver.New("4.8.0")}).storesOwnerAliases())
+
+	r := &repo{}
+	aliases, err := r.ListOwnerAliases(ctx)
+	require.NoError(t, err)
+	require.Empty(t, aliases)
+	_, err = r.UpsertOwnerAlias(ctx, &ridmodels.OwnerAlias{Subject: "subject", Owner: "uss1"})
+	require.Equal(t, dsserr.NotFound, stacktrace.GetCode(err))
+}
@@ -0,0 +1,53 @@
+package cockroach
+
+import (
+	"time"
+
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var writeStatementDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "dss_rid_write_statement_duration_seconds",
+	Help:    "Duration of the SQL statements of remote ID writes, by entity written and statement.",
+	Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+}, []string{"entity", "statement"})
+
+// Statements of the writes of remote ID entities, whose durations break down
+// the latency of writes.
+const (
+	// statementFetch locks the entity written by reading it for update.
+	statementFetch = "fetch"
+	// statementInsert, statementUpdate and statementDelete write the row of
+	// the entity, including its cells.
+	statementInsert = "insert"
+	statementUpdate = "update"
+	statementDelete = "delete"
+	// statementNotify increments the notification indices of the
+	// subscriptions to an ISA.
+	statementNotify = "notify"
+	// statementCount counts the subscriptions of an owner in the cells of a
+	// subscription.
+	statementCount = "count"
+	// statementActivity records the change in the activity of the pool.
+	statementActivity = "activity"
+	// statementClearCounters discards the notification counters of a
+	// subscription.
+	statementClearCounters = "clear_counters"
+)
+
+// timeStatement starts timing the statement of a write of entity, and returns
+// the function to call once it completed to record its duration.
+func (r *repo) timeStatement(entity ridmodels.ActivityEntity, statement string) func() {
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		writeStatementDuration.WithLabelValues(string(entity), statement).Observe(elapsed.Seconds())
+		if r.logger != nil {
+			r.logger.Debug("Write statement completed",
+				zap.String("entity", string(entity)), zap.String("statement", statement), zap.Duration("duration", elapsed))
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package cockroach
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/interuss/dss/pkg/datastore/testdb"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTimeStatement(t *testing.T) {
+	var (
+		core, logs = observer.New(zapcore.DebugLevel)
+		r          = &repo{logger: zap.New(core)}
+		histogram  = writeStatementDuration.WithLabelValues(string(ridmodels.ActivityISA), statementNotify).(prometheus.Histogram)
+		count      = func() uint64 {
+			m := &dto.Metric{}
+			require.NoError(t, histogram.Write(m))
+			return m.GetHistogram().GetSampleCount()
+		}
+		before = count()
+	)
+
+	r.timeStatement(ridmodels.ActivityISA, statementNotify)()
+	require.Equal(t, before+1, count())
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	require.Equal(t, "isa", fields["entity"])
+	require.Equal(t, "notify", fields["statement"])
+
+	// Repos without logger only record the metric.
+	(&repo{}).timeStatement(ridmodels.ActivityISA, statementNotify)()
+	require.Equal(t, before+2, count())
+}
+
+// BenchmarkHotQueries measures the throughput of concurrent gets and searches
+// of ISAs and notifications of subscriptions, with statements prepared once
+// per connection or on every execution.
+func BenchmarkHotQueries(b *testing.B) {
+	for _, capacity := range []int{0, 512} {
+		b.Run(fmt.Sprintf("statement_cache_capacity=%d", capacity), func(b *testing.B) {
+			ctx := context.Background()
+			connectParameters := testdb.ConnectParameters(b, "rid")
+			connectParameters.StatementCacheCapacity = capacity
+			store, err := newStore(ctx, b, connectParameters)
+			require.NoError(b, err)
+			defer func() {
+				require.NoError(b, CleanUp(ctx, store))
+				require.NoError(b, store.Close())
+			}()
+
+			repo, err := store.Interact(ctx)
+			require.NoError(b, err)
+			isa := *serviceArea
+			isa.ID = dssmodels.ID(uuid.New().String())
+			isa.Cells = notifiedCells
+			_, err = repo.InsertISA(ctx, &isa)
+			require.NoError(b, err)
+			insertNotifiedSubscription(ctx, b, repo)
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_, err := repo.GetISA(ctx, isa.ID, false)
+					require.NoError(b, err)
+					_, err = repo.SearchISAs(ctx, notifiedCells, &startTime, &endTime, "")
+					require.NoError(b, err)
+					_, err = repo.UpdateNotificationIdxsInCells(ctx, notifiedCells, "isa owner", nil, nil)
+					require.NoError(b, err)
+				}
+			})
+		})
+	}
+}
@@ -7,10 +7,13 @@ import (
 	dssql "github.com/interuss/dss/pkg/sql"
 	"time"
 
-	"github.com/cockroachdb/cockroach-go/v2/crdb/crdbpgxv5"
 	"github.com/coreos/go-semver/semver"
+	"github.com/interuss/dss/pkg/consistency"
 	"github.com/interuss/dss/pkg/datastore"
+	"github.com/interuss/dss/pkg/datastore/owners"
 	"github.com/interuss/dss/pkg/logging"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
 	"github.com/interuss/dss/pkg/rid/repos"
 	"github.com/interuss/stacktrace"
 	"github.com/jackc/pgx/v5"
@@ -27,6 +30,14 @@ const (
 )
 
 var (
+	// minimumSchemaVersion is the oldest schema version supported by the
+	// store, which relies on the column defaults introduced in it.
+	minimumSchemaVersion = semver.New("4.2.0")
+
+	// TargetSchemaVersion is the schema version of the latest migration, which
+	// the store is written for and db-manager applies with --db_version latest.
+	TargetSchemaVersion = semver.New("4.12.0")
+
 	// DefaultClock is what is used as the Store's clock, returned from Dial.
 	DefaultClock = clockwork.NewRealClock()
 	// DefaultTimeout is the timeout applied to the txn retrier.
@@ -42,6 +53,64 @@ type repo struct {
 	dssql.Queryable
 	clock  clockwork.Clock
 	logger *zap.Logger
+
+	// counterShards is the number of notification counters per subscription,
+	// or 0 if notification indices are maintained in subscription rows.
+	counterShards int
+
+	// activityLog is set if changes of entities are recorded as activity
+	// events.
+	activityLog bool
+
+	// isaExtents is set if the altitudes and footprints of ISAs are stored.
+	isaExtents bool
+
+	// subscriptionCallbacks is set if the callback URLs of subscriptions
+	// besides their URL are stored.
+	subscriptionCallbacks bool
+
+	// subscriptionNotificationIntervals is set if the minimum intervals
+	// between the notifications of subscriptions are stored.
+	subscriptionNotificationIntervals bool
+
+	// subscriptionSelfNotifications is set if whether subscriptions are
+	// notified of the changes to the ISAs of their owner is stored.
+	subscriptionSelfNotifications bool
+
+	// ownerAliases is set if the schema stores owner aliases.
+	ownerAliases bool
+
+	// owners transforms the owners stored in the database, if not nil.
+	owners owners.Codec
+}
+
+// storedOwner returns the value storing owner in the database.
+func (r *repo) storedOwner(owner dssmodels.Owner) string {
+	if r.owners == nil {
+		return owner.String()
+	}
+	return r.owners.Encode(owner)
+}
+
+// ownerFromStored returns the owner stored in the database as stored.
+func (r *repo) ownerFromStored(stored string) (dssmodels.Owner, error) {
+	if r.owners == nil {
+		return dssmodels.Owner(stored), nil
+	}
+	owner, err := r.owners.Decode(stored)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "Error decoding stored owner")
+	}
+	return owner, nil
+}
+
+// labelsArg returns the query argument storing labels, which is NULL rather
+// than a JSON null when there are none.
+func labelsArg(labels ridmodels.Labels) interface{} {
+	if len(labels) == 0 {
+		return nil
+	}
+	return labels
 }
 
 // Store is an implementation of store.Store using Cockroach DB as its backend
@@ -51,10 +120,15 @@ type repo struct {
 // outer pkg/cockroach
 type Store struct {
 	db      *datastore.Datastore
+	readDB  *datastore.Datastore
 	logger  *zap.Logger
 	clock   clockwork.Clock
 	version *semver.Version
 
+	counterShards int
+	activityLog   bool
+	owners        owners.Codec
+
 	// DatabaseName is the name of database storing remote ID data.
 	DatabaseName string
 }
@@ -87,6 +161,11 @@ func (s *Store) CheckCurrentMajorSchemaVersion(ctx context.Context) error {
 	if err != nil {
 		return stacktrace.Propagate(err, "Failed to get database schema version for remote ID")
 	}
+	return checkSchemaVersion(vs)
+}
+
+// checkSchemaVersion returns nil if the store supports the schema version vs.
+func checkSchemaVersion(vs *semver.Version) error {
 	if vs == datastore.UnknownVersion {
 		return stacktrace.NewError("Remote ID database has not been bootstrapped with Schema Manager, Please check https://github.com/interuss/dss/tree/master/build#updgrading-database-schemas")
 	}
@@ -95,22 +174,96 @@ func (s *Store) CheckCurrentMajorSchemaVersion(ctx context.Context) error {
 		return stacktrace.NewError("Unsupported schema version for remote ID! Got %s, requires major version of %d. Please check https://github.com/interuss/dss/tree/master/build#updgrading-database-schemas", vs, currentMajorSchemaVersion)
 	}
 
+	if vs.LessThan(*minimumSchemaVersion) {
+		return stacktrace.NewError("Unsupported schema version for remote ID! Got %s, requires at least %s. Please check https://github.com/interuss/dss/tree/master/build#upgrading-database-schemas", vs, minimumSchemaVersion)
+	}
+
 	return nil
 }
 
-// Interact implements store.Interactor interface.
+// UseOwnerCodec makes the Store transform the owners it stores with codec, e.g.
+// to keep database dumps from revealing the identity of USSs. It must be
+// called before the Store is used, and the same codec must then always be
+// used with the database: owners stored otherwise are not found by searches
+// by owner.
+func (s *Store) UseOwnerCodec(codec owners.Codec) {
+	s.owners = codec
+}
+
+// SetReadDatastore routes the non-transactional queries issued through
+// Interact to readDB instead of the primary datastore. Transactions are
+// always executed against the primary datastore.
+func (s *Store) SetReadDatastore(readDB *datastore.Datastore) {
+	s.readDB = readDB
+}
+
+// Datastore returns the primary datastore of s.
+func (s *Store) Datastore() *datastore.Datastore {
+	return s.db
+}
+
+// CheckTargetSchemaVersion returns nil if the schema version of s is
+// TargetSchemaVersion, i.e. if the database has been migrated to neither an
+// older nor a newer version than the one the store is written for.
+func (s *Store) CheckTargetSchemaVersion(ctx context.Context) error {
+	vs, err := s.GetVersion(ctx)
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to get database schema version for remote ID")
+	}
+	if !vs.Equal(*TargetSchemaVersion) {
+		return stacktrace.NewError("Schema version for remote ID is %s instead of %s; migrate the database with db-manager. Please check https://github.com/interuss/dss/tree/master/build#upgrading-database-schemas", vs, TargetSchemaVersion)
+	}
+	return nil
+}
+
+// Interact implements store.Interactor interface. Queries are sent to the
+// read datastore when one is configured, unless the consistency token of the
+// request may be more recent than its data.
 func (s *Store) Interact(ctx context.Context) (repos.Repository, error) {
+	db := s.db
+	if s.readDB != nil && !consistency.RequiresPrimary(ctx) {
+		db = s.readDB
+	}
 	logger := logging.WithValuesFromContext(ctx, s.logger)
 	return &repo{
-		Queryable: s.db.Pool,
-		clock:     s.clock,
-		logger:    logger,
+		Queryable:                         dssql.WithErrorTranslation(db.Pool),
+		clock:                             s.clock,
+		logger:                            logger,
+		counterShards:                     s.counterShards,
+		activityLog:                       s.activityLog,
+		isaExtents:                        s.storesISAExtents(),
+		subscriptionCallbacks:             s.storesSubscriptionCallbacks(),
+		subscriptionNotificationIntervals: s.storesSubscriptionNotificationIntervals(),
+		subscriptionSelfNotifications:     s.storesSubscriptionSelfNotifications(),
+		ownerAliases:                      s.storesOwnerAliases(),
+		owners:                            s.owners,
+	}, nil
+}
+
+// InteractPrimary is like Interact, but always uses the primary datastore.
+// It is intended for non-transactional writes.
+func (s *Store) InteractPrimary(ctx context.Context) (repos.Repository, error) {
+	logger := logging.WithValuesFromContext(ctx, s.logger)
+	return &repo{
+		Queryable:                         dssql.WithErrorTranslation(s.db.Pool),
+		clock:                             s.clock,
+		logger:                            logger,
+		counterShards:                     s.counterShards,
+		activityLog:                       s.activityLog,
+		isaExtents:                        s.storesISAExtents(),
+		subscriptionCallbacks:             s.storesSubscriptionCallbacks(),
+		subscriptionNotificationIntervals: s.storesSubscriptionNotificationIntervals(),
+		subscriptionSelfNotifications:     s.storesSubscriptionSelfNotifications(),
+		ownerAliases:                      s.storesOwnerAliases(),
+		owners:                            s.owners,
 	}, nil
 }
 
 // Transact supplies a new repo, that will perform all of the DB accesses
 // in a Txn, and will retry any Txn's that fail due to retry-able errors
-// (typically contention).
+// (typically contention). The Txn is rolled back if ctx is done before f
+// returns, and panics in f roll it back before being propagated. Committed
+// Txns give the response to the request of ctx a consistency token.
 func (s *Store) Transact(ctx context.Context, f func(repo repos.Repository) error) error {
 	logger := logging.WithValuesFromContext(ctx, s.logger)
 	// TODO: consider what tx opts we want to support.
@@ -121,31 +274,53 @@ func (s *Store) Transact(ctx context.Context, f func(repo repos.Repository) erro
 
 	ctx = crdb.WithMaxRetries(ctx, flags.ConnectParameters().MaxRetries)
 
-	return crdbpgx.ExecuteTx(ctx, s.db.Pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
-		// Is this recover still necessary?
-		defer recoverRollbackRepanic(ctx, tx)
+	err := dssql.TranslateError(dssql.ExecuteTx(ctx, s.db.Pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
 		return f(&repo{
-			Queryable: tx,
-			clock:     s.clock,
-			logger:    logger,
+			Queryable:                         dssql.WithErrorTranslation(tx),
+			clock:                             s.clock,
+			logger:                            logger,
+			counterShards:                     s.counterShards,
+			activityLog:                       s.activityLog,
+			isaExtents:                        s.storesISAExtents(),
+			subscriptionCallbacks:             s.storesSubscriptionCallbacks(),
+			subscriptionNotificationIntervals: s.storesSubscriptionNotificationIntervals(),
+			subscriptionSelfNotifications:     s.storesSubscriptionSelfNotifications(),
+			ownerAliases:                      s.storesOwnerAliases(),
+			owners:                            s.owners,
 		})
-	})
+	}))
+	if err == nil {
+		consistency.RecordWrite(ctx)
+	}
+	return err
+}
+
+// Ping verifies that the primary datastore can serve queries.
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.Pool.Ping(ctx)
+}
+
+// CheckReady returns nil if the primary datastore can serve queries and the
+// schema version it currently reports, rather than the one read when the
+// Store was created, is supported, e.g. to answer readiness probes.
+func (s *Store) CheckReady(ctx context.Context) error {
+	if err := s.Ping(ctx); err != nil {
+		return stacktrace.Propagate(err, "Remote ID database is unreachable")
+	}
+	vs, err := s.db.GetSchemaVersion(ctx, s.DatabaseName)
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to get database schema version for remote ID")
+	}
+	return checkSchemaVersion(vs)
 }
 
 // Close closes the underlying DB connection.
 func (s *Store) Close() error {
 	s.db.Pool.Close()
-	return nil
-}
-
-func recoverRollbackRepanic(ctx context.Context, tx pgx.Tx) {
-	if p := recover(); p != nil {
-		if err := tx.Rollback(ctx); err != nil {
-			logging.WithValuesFromContext(ctx, logging.Logger).Error(
-				"failed to rollback transaction", zap.Error(err),
-			)
-		}
+	if s.readDB != nil {
+		s.readDB.Pool.Close()
 	}
+	return nil
 }
 
 // CleanUp removes all database tables managed by s.
@@ -154,8 +329,19 @@ func (s *Store) CleanUp(ctx context.Context) error {
 	DELETE FROM subscriptions WHERE id IS NOT NULL;
 	DELETE FROM identification_service_areas WHERE id IS NOT NULL;`
 
-	_, err := s.db.Pool.Exec(ctx, query)
-	return err
+	if _, err := s.db.Pool.Exec(ctx, query); err != nil {
+		return err
+	}
+	if s.storesOwnerAliases() {
+		if _, err := s.db.Pool.Exec(ctx, `DELETE FROM owner_aliases WHERE subject IS NOT NULL`); err != nil {
+			return err
+		}
+	}
+	if s.activityLog {
+		_, err := s.db.Pool.Exec(ctx, `DELETE FROM activity_events WHERE entity_id IS NOT NULL`)
+		return err
+	}
+	return nil
 }
 
 // GetVersion returns the Version string for the Database.
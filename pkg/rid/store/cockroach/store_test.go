@@ -3,12 +3,15 @@ package cockroach
 import (
 	"context"
 	"errors"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/coreos/go-semver/semver"
 	"github.com/google/uuid"
 	"github.com/interuss/dss/pkg/datastore"
-	"github.com/interuss/dss/pkg/datastore/flags"
+	"github.com/interuss/dss/pkg/datastore/testdb"
 	"github.com/interuss/dss/pkg/logging"
 	dssmodels "github.com/interuss/dss/pkg/models"
 	ridmodels "github.com/interuss/dss/pkg/rid/models"
@@ -29,15 +32,28 @@ func init() {
 	DefaultTimeout = 500 * time.Millisecond
 }
 
-func setUpStore(ctx context.Context, t *testing.T) (*Store, func()) {
-	connectParameters := flags.ConnectParameters()
-	if connectParameters.Host == "" || connectParameters.Port == 0 {
-		t.Skip()
-	} else {
-		if !(connectParameters.DBName == "rid" || connectParameters.DBName == "scd") {
-			connectParameters.DBName = "rid"
+// TestTargetSchemaVersionIsLatest verifies that TargetSchemaVersion follows
+// the migrations applied by db-manager.
+func TestTargetSchemaVersionIsLatest(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "..", "..", "..", "build", "db_schemas", "rid", "upto-v*.sql"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	latest := semver.New("0.0.0")
+	for _, f := range files {
+		v := semver.New(strings.SplitN(strings.TrimPrefix(filepath.Base(f), "upto-v"), "-", 2)[0])
+		if latest.LessThan(*v) {
+			latest = v
 		}
 	}
+	require.Equal(t, *latest, *TargetSchemaVersion)
+}
+
+func TestMain(m *testing.M) {
+	testdb.Main(m)
+}
+
+func setUpStore(ctx context.Context, t *testing.T) (*Store, func()) {
+	connectParameters := testdb.ConnectParameters(t, "rid")
 	// Reset the clock for every test.
 	fakeClock = clockwork.NewFakeClock()
 
@@ -49,7 +65,7 @@ func setUpStore(ctx context.Context, t *testing.T) (*Store, func()) {
 	}
 }
 
-func newStore(ctx context.Context, t *testing.T, connectParameters datastore.ConnectParameters) (*Store, error) {
+func newStore(ctx context.Context, t testing.TB, connectParameters datastore.ConnectParameters) (*Store, error) {
 	db, err := datastore.Dial(ctx, connectParameters)
 	require.NoError(t, err)
 
@@ -65,6 +81,7 @@ func newStore(ctx context.Context, t *testing.T, connectParameters datastore.Con
 func CleanUp(ctx context.Context, s *Store) error {
 	const query = `
 	DELETE FROM subscriptions WHERE id IS NOT NULL;
+	DELETE FROM subscription_notification_counters WHERE subscription_id IS NOT NULL;
 	DELETE FROM identification_service_areas WHERE id IS NOT NULL;`
 
 	_, err := s.db.Pool.Exec(ctx, query)
@@ -137,6 +154,45 @@ func TestTxnRetrier(t *testing.T) {
 	require.Greater(t, count, 1)
 }
 
+func TestTransactRollsBackCanceledTransactions(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	require.NotNil(t, store)
+	defer tearDownStore()
+
+	// The client disconnects after the ISA is inserted, before the
+	// transaction completes.
+	txCtx, cancel := context.WithCancel(ctx)
+	err := store.Transact(txCtx, func(repo repos.Repository) error {
+		_, err := repo.InsertISA(txCtx, serviceArea)
+		require.NoError(t, err)
+		cancel()
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	// Queries issued after the client disconnects fail.
+	txCtx, cancel = context.WithCancel(ctx)
+	err = store.Transact(txCtx, func(repo repos.Repository) error {
+		_, err := repo.InsertISA(txCtx, serviceArea)
+		require.NoError(t, err)
+		cancel()
+		_, err = repo.GetISA(txCtx, serviceArea.ID, false)
+		return err
+	})
+	require.Error(t, err)
+
+	// Neither transaction left rows nor connections behind.
+	repo, err := store.Interact(ctx)
+	require.NoError(t, err)
+	isa, err := repo.GetISA(ctx, serviceArea.ID, false)
+	require.NoError(t, err)
+	require.Nil(t, isa)
+	require.Zero(t, store.db.Pool.Stat().AcquiredConns())
+}
+
 func TestTransactor(t *testing.T) {
 	var (
 		ctx                  = context.Background()
@@ -185,11 +241,11 @@ func TestTransactor(t *testing.T) {
 
 	require.Len(t, subs, 1)
 
-	s, err := repo.GetSubscription(ctx, subscription1.ID)
+	s, err := repo.GetSubscription(ctx, subscription1.ID, false)
 	require.NoError(t, err)
 	require.Nil(t, s)
 
-	s, err = repo.GetSubscription(ctx, subscription2.ID)
+	s, err = repo.GetSubscription(ctx, subscription2.ID, false)
 	require.NoError(t, err)
 	require.NotNil(t, s)
 
@@ -0,0 +1,49 @@
+package cockroach
+
+import (
+	"github.com/coreos/go-semver/semver"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+)
+
+var (
+	// subscriptionCallbacksSchemaVersion is the schema version introducing
+	// the callback URLs of subscriptions besides their URL.
+	subscriptionCallbacksSchemaVersion = semver.New("4.9.0")
+)
+
+// subscriptionCallbacksFields are the columns storing the callback URLs of
+// subscriptions since subscriptionCallbacksSchemaVersion.
+const subscriptionCallbacksFields = "callback_urls"
+
+// storesSubscriptionCallbacks returns whether the schema of s stores the
+// callback URLs of subscriptions.
+func (s *Store) storesSubscriptionCallbacks() bool {
+	return s.version == nil || !s.version.LessThan(*subscriptionCallbacksSchemaVersion)
+}
+
+// subscriptionFields returns the columns of the subscriptions read, and
+// written with subscriptionCallbacksFields last if r.subscriptionCallbacks is
+// set, followed by subscriptionNotificationIntervalFields if
+// r.subscriptionNotificationIntervals is set and
+// subscriptionSelfNotificationFields if r.subscriptionSelfNotifications is
+// set.
+func (r *repo) subscriptionFields() string {
+	fields := subscriptionFields
+	if r.subscriptionCallbacks {
+		fields += ", " + subscriptionCallbacksFields
+	}
+	if r.subscriptionNotificationIntervals {
+		fields += ", " + subscriptionNotificationIntervalFields
+	}
+	if r.subscriptionSelfNotifications {
+		fields += ", " + subscriptionSelfNotificationFields
+	}
+	return fields
+}
+
+// errSubscriptionCallbacksUnsupported is returned by the changes of callback
+// URLs on schemas older than subscriptionCallbacksSchemaVersion.
+func errSubscriptionCallbacksUnsupported() error {
+	return stacktrace.NewErrorWithCode(dsserr.BadRequest, "Callback URLs of subscriptions require remote ID schema version %s or later", subscriptionCallbacksSchemaVersion)
+}
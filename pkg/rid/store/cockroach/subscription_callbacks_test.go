@@ -0,0 +1,46 @@
+package cockroach
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoresSubscriptionCallbacks(t *testing.T) {
+	require.True(t, (&Store{}).storesSubscriptionCallbacks())
+	require.True(t, (&Store{version: semver.New("4.9.0")}).storesSubscriptionCallbacks())
+	require.False(t, (&Store{version: semver.New("4.8.0")}).storesSubscriptionCallbacks())
+}
+
+func TestStoreSubscriptionCallbacks(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	defer tearDownStore()
+
+	repo, err := store.Interact(ctx)
+	require.NoError(t, err)
+
+	sub := *subscriptionsPool[0].input
+	sub.CallbackURLs = []string{"https://no/place/like/flights"}
+	inserted, err := repo.InsertSubscription(ctx, &sub)
+	require.NoError(t, err)
+	require.Equal(t, sub.CallbackURLs, inserted.CallbackURLs)
+
+	updated, err := repo.UpdateSubscriptionCallbacks(ctx, sub.ID, []string{"https://no/place/like/operations"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://no/place/like/operations"}, updated.CallbackURLs)
+	require.Equal(t, inserted.Version, updated.Version)
+
+	// Updates of the subscription keep its callback URLs.
+	update := *updated
+	update.URL = "https://no/place/like/away"
+	_, err = repo.UpdateSubscription(ctx, &update)
+	require.NoError(t, err)
+	stored, err := repo.GetSubscription(ctx, sub.ID, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://no/place/like/operations"}, stored.CallbackURLs)
+}
@@ -0,0 +1,52 @@
+package cockroach
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/dss/pkg/rid/repos"
+	"github.com/interuss/stacktrace"
+)
+
+// DeleteSubscriptionsEndedBefore deletes at most "limit" subscriptions of
+// any writer which ended before "before", and returns the number of
+// subscriptions deleted. Subscriptions without an end time, which schema
+// versions before 4.6.0 allow, are considered to end the maximum
+// subscription duration after their start or, lacking one, their last update.
+func (s *Store) DeleteSubscriptionsEndedBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	deleted := 0
+	err := s.Transact(ctx, func(tx repos.Repository) error {
+		deleted = 0
+		r, ok := tx.(*repo)
+		if !ok {
+			return stacktrace.NewError("Unexpected repository %T", tx)
+		}
+		var query = fmt.Sprintf(`
+		SELECT
+			%s
+		FROM
+			subscriptions
+		WHERE
+			ends_at < $1
+		OR
+			(ends_at IS NULL AND COALESCE(starts_at, updated_at) < $2)
+		LIMIT $3`, r.subscriptionFields())
+		subs, err := r.scan(ctx, query, before, before.Add(-ridmodels.MaxSubscriptionDuration()), limit)
+		if err != nil {
+			return stacktrace.Propagate(err, "Error listing ended subscriptions")
+		}
+		for _, sub := range subs {
+			if _, err := r.DeleteSubscription(ctx, sub); err != nil {
+				return stacktrace.Propagate(err, "Error deleting subscription %s", sub.ID)
+			}
+			deleted++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return deleted, nil
+}
@@ -0,0 +1,32 @@
+package cockroach
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteSubscriptionsEndedBefore(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	defer tearDownStore()
+
+	repo, err := store.Interact(ctx)
+	require.NoError(t, err)
+	sub := insertNotifiedSubscription(ctx, t, repo)
+
+	n, err := store.DeleteSubscriptionsEndedBefore(ctx, *sub.EndTime, 100)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	n, err = store.DeleteSubscriptionsEndedBefore(ctx, sub.EndTime.Add(time.Minute), 100)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	got, err := repo.GetSubscription(ctx, sub.ID, false)
+	require.NoError(t, err)
+	require.Nil(t, got)
+}
@@ -0,0 +1,42 @@
+package cockroach
+
+import (
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// subscriptionNotificationIntervalsSchemaVersion is the schema version
+	// introducing the minimum intervals between the notifications of
+	// subscriptions.
+	subscriptionNotificationIntervalsSchemaVersion = semver.New("4.10.0")
+)
+
+// subscriptionNotificationIntervalFields are the columns storing the minimum
+// notification intervals of subscriptions and the time at which they were
+// last notified since subscriptionNotificationIntervalsSchemaVersion.
+const subscriptionNotificationIntervalFields = "min_notification_interval_seconds, last_notified_at"
+
+// storesSubscriptionNotificationIntervals returns whether the schema of s
+// stores the minimum notification intervals of subscriptions.
+func (s *Store) storesSubscriptionNotificationIntervals() bool {
+	return s.version == nil || !s.version.LessThan(*subscriptionNotificationIntervalsSchemaVersion)
+}
+
+// notificationIntervalArg returns the value of the
+// min_notification_interval_seconds column storing interval, NULL if there is
+// no minimum.
+func notificationIntervalArg(interval time.Duration) pgtype.Int8 {
+	return pgtype.Int8{Int64: int64(interval / time.Second), Valid: interval > 0}
+}
+
+// errSubscriptionNotificationIntervalsUnsupported is returned by the changes
+// of minimum notification intervals on schemas older than
+// subscriptionNotificationIntervalsSchemaVersion.
+func errSubscriptionNotificationIntervalsUnsupported() error {
+	return stacktrace.NewErrorWithCode(dsserr.BadRequest, "Minimum notification intervals of subscriptions require remote ID schema version %s or later", subscriptionNotificationIntervalsSchemaVersion)
+}
@@ -0,0 +1,45 @@
+package cockroach
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoresSubscriptionNotificationIntervals(t *testing.T) {
+	require.True(t, (&Store{}).storesSubscriptionNotificationIntervals())
+	require.True(t, (&Store{version: semver.New("4.10.0")}).storesSubscriptionNotificationIntervals())
+	require.False(t, (&Store{version: semver.New("4.9.0")}).storesSubscriptionNotificationIntervals())
+}
+
+func TestStoreSubscriptionNotificationIntervals(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	defer tearDownStore()
+
+	repo, err := store.Interact(ctx)
+	require.NoError(t, err)
+
+	sub := *subscriptionsPool[0].input
+	inserted, err := repo.InsertSubscription(ctx, &sub)
+	require.NoError(t, err)
+	require.Zero(t, inserted.MinNotificationInterval)
+
+	updated, err := repo.UpdateSubscriptionNotificationInterval(ctx, sub.ID, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, updated.MinNotificationInterval)
+	require.Equal(t, inserted.Version, updated.Version)
+
+	at := time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(t, repo.RecordSubscriptionNotifications(ctx, []dssmodels.ID{sub.ID}, at))
+	stored, err := repo.GetSubscription(ctx, sub.ID, false)
+	require.NoError(t, err)
+	require.True(t, at.Equal(*stored.LastNotifiedAt))
+	require.False(t, stored.NotificationDue(at.Add(30*time.Second)))
+}
@@ -0,0 +1,33 @@
+package cockroach
+
+import (
+	"github.com/coreos/go-semver/semver"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+)
+
+var (
+	// subscriptionSelfNotificationsSchemaVersion is the schema version
+	// introducing the notifications of subscriptions of the changes to the
+	// ISAs of their owner.
+	subscriptionSelfNotificationsSchemaVersion = semver.New("4.12.0")
+)
+
+// subscriptionSelfNotificationFields is the column storing whether
+// subscriptions are notified of the changes to the ISAs of their owner since
+// subscriptionSelfNotificationsSchemaVersion.
+const subscriptionSelfNotificationFields = "notify_own_isas"
+
+// storesSubscriptionSelfNotifications returns whether the schema of s stores
+// whether subscriptions are notified of the changes to the ISAs of their
+// owner.
+func (s *Store) storesSubscriptionSelfNotifications() bool {
+	return s.version == nil || !s.version.LessThan(*subscriptionSelfNotificationsSchemaVersion)
+}
+
+// errSubscriptionSelfNotificationsUnsupported is returned by the changes of
+// self-notifications on schemas older than
+// subscriptionSelfNotificationsSchemaVersion.
+func errSubscriptionSelfNotificationsUnsupported() error {
+	return stacktrace.NewErrorWithCode(dsserr.BadRequest, "Notifying subscriptions of the ISAs of their owner requires remote ID schema version %s or later", subscriptionSelfNotificationsSchemaVersion)
+}
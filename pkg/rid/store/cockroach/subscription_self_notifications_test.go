@@ -0,0 +1,47 @@
+package cockroach
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoresSubscriptionSelfNotifications(t *testing.T) {
+	require.True(t, (&Store{}).storesSubscriptionSelfNotifications())
+	require.True(t, (&Store{version: semver.New("4.12.0")}).storesSubscriptionSelfNotifications())
+	require.False(t, (&Store{version: semver.New("4.11.0")}).storesSubscriptionSelfNotifications())
+
+	r := &repo{}
+	require.NotContains(t, r.notifiedSubscriptionsCondition(), subscriptionSelfNotificationFields)
+	r.subscriptionSelfNotifications = true
+	require.Contains(t, r.notifiedSubscriptionsCondition(), subscriptionSelfNotificationFields)
+}
+
+func TestStoreSubscriptionSelfNotifications(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	defer tearDownStore()
+
+	repo, err := store.Interact(ctx)
+	require.NoError(t, err)
+	sub := insertNotifiedSubscription(ctx, t, repo)
+	require.False(t, sub.NotifyOwnISAs)
+
+	subs, err := repo.UpdateNotificationIdxsInCells(ctx, notifiedCells, sub.Owner, nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, subs)
+
+	updated, err := repo.UpdateSubscriptionSelfNotification(ctx, sub.ID, true)
+	require.NoError(t, err)
+	require.True(t, updated.NotifyOwnISAs)
+	require.Equal(t, sub.Version, updated.Version)
+
+	subs, err = repo.UpdateNotificationIdxsInCells(ctx, notifiedCells, sub.Owner, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	require.Equal(t, sub.ID, subs[0].ID)
+}
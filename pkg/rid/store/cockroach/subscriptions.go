@@ -6,6 +6,7 @@ import (
 	"time"
 
 	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/geo"
 	dssmodels "github.com/interuss/dss/pkg/models"
 	ridmodels "github.com/interuss/dss/pkg/rid/models"
 	dssql "github.com/interuss/dss/pkg/sql"
@@ -16,12 +17,43 @@ import (
 )
 
 const (
-	subscriptionFields       = "id, owner, url, notification_index, cells, starts_at, ends_at, writer, updated_at"
-	updateSubscriptionFields = "id, url, notification_index, cells, starts_at, ends_at, writer, updated_at"
+	subscriptionFields       = "id, owner, url, notification_index, cells, starts_at, ends_at, writer, updated_at, labels"
+	updateSubscriptionFields = "id, url, notification_index, starts_at, ends_at, writer, updated_at"
 )
 
-// process a query that should return one or many subscriptions.
+// notifiedSubscriptionsCondition selects the subscriptions to notify of a
+// change to an ISA, given the ISA's cells ($1), the current time ($2), the
+// ISA's owner ($3) and the ISA's time range ($4, $5). The subscriptions of the
+// ISA's owner are only notified if they opted into it.
+func (r *repo) notifiedSubscriptionsCondition() string {
+	ownerCondition := "owner != $3"
+	if r.subscriptionSelfNotifications {
+		ownerCondition = "(owner != $3 OR notify_own_isas)"
+	}
+	return fmt.Sprintf(`
+				%s
+				AND ends_at >= $2
+				AND %s
+				AND ($4::timestamptz IS NULL OR ends_at >= $4)
+				AND ($5::timestamptz IS NULL OR starts_at IS NULL OR starts_at <= $5)`, dssql.CellsIntersect("cells", "$1"), ownerCondition)
+}
+
+// process a query that should return one or many subscriptions, including the
+// notifications pending in their notification counters.
 func (r *repo) process(ctx context.Context, query string, args ...interface{}) ([]*ridmodels.Subscription, error) {
+	subs, err := r.scan(ctx, query, args...)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if err := r.addPendingNotifications(ctx, subs); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return subs, nil
+}
+
+// scan processes a query that should return one or many subscriptions as
+// stored in their rows.
+func (r *repo) scan(ctx context.Context, query string, args ...interface{}) ([]*ridmodels.Subscription, error) {
 	rows, err := r.Query(ctx, query, args...)
 	if err != nil {
 		return nil, stacktrace.Propagate(err, fmt.Sprintf("Error in query: %s", query))
@@ -35,11 +67,15 @@ func (r *repo) process(ctx context.Context, query string, args ...interface{}) (
 	for rows.Next() {
 		s := new(ridmodels.Subscription)
 
-		var updateTime time.Time
+		var (
+			updateTime      time.Time
+			owner           string
+			intervalSeconds pgtype.Int8
+		)
 
-		err := rows.Scan(
+		dest := []interface{}{
 			&s.ID,
-			&s.Owner,
+			&owner,
 			&s.URL,
 			&s.NotificationIndex,
 			&cids,
@@ -47,11 +83,26 @@ func (r *repo) process(ctx context.Context, query string, args ...interface{}) (
 			&s.EndTime,
 			&writer,
 			&updateTime,
-		)
+			&s.Labels,
+		}
+		if r.subscriptionCallbacks {
+			dest = append(dest, &s.CallbackURLs)
+		}
+		if r.subscriptionNotificationIntervals {
+			dest = append(dest, &intervalSeconds, &s.LastNotifiedAt)
+		}
+		if r.subscriptionSelfNotifications {
+			dest = append(dest, &s.NotifyOwnISAs)
+		}
+		err := rows.Scan(dest...)
 		if err != nil {
 			return nil, stacktrace.Propagate(err, "Error scanning Subscription row")
 		}
+		if s.Owner, err = r.ownerFromStored(owner); err != nil {
+			return nil, err // No need to Propagate this error as this stack layer does not add useful information
+		}
 		s.Writer = writer.String
+		s.MinNotificationInterval = time.Duration(intervalSeconds.Int64) * time.Second
 
 		s.SetCells(cids)
 		s.Version = dssmodels.VersionFromTime(updateTime)
@@ -65,7 +116,20 @@ func (r *repo) process(ctx context.Context, query string, args ...interface{}) (
 
 // processOne processes a query that should return exactly a single subscription.
 func (r *repo) processOne(ctx context.Context, query string, args ...interface{}) (*ridmodels.Subscription, error) {
-	subs, err := r.process(ctx, query, args...)
+	sub, err := r.scanOne(ctx, query, args...)
+	if err != nil || sub == nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if err := r.addPendingNotifications(ctx, []*ridmodels.Subscription{sub}); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return sub, nil
+}
+
+// scanOne is like processOne, but returns the subscription as stored in its
+// row.
+func (r *repo) scanOne(ctx context.Context, query string, args ...interface{}) (*ridmodels.Subscription, error) {
+	subs, err := r.scan(ctx, query, args...)
 	if err != nil {
 		return nil, err // No need to Propagate this error as this stack layer does not add useful information
 	}
@@ -101,8 +165,29 @@ func (r *repo) MaxSubscriptionCountInCellsByOwner(ctx context.Context, cells s2.
         cell_id = ANY($3)
       GROUP BY cell_id
     )`
+	if geo.MultiLevelCoverings() {
+		// Subscriptions are counted in each of the given cells they
+		// intersect, rather than in each of the cells they are made of.
+		query = fmt.Sprintf(`
+    SELECT
+      IFNULL(MAX(subscriptions_per_cell_id), 0)
+    FROM (
+      SELECT
+        COUNT(DISTINCT subscription_id) AS subscriptions_per_cell_id
+      FROM (
+      	SELECT id AS subscription_id, unnest(cells) AS cell_id
+      	FROM subscriptions
+      	WHERE owner = $1
+      		AND ends_at >= $2
+      ), unnest($3::INT8[]) AS searched_cell_id
+      WHERE
+        %s
+      GROUP BY searched_cell_id
+    )`, dssql.CellRangesIntersect("cell_id", "searched_cell_id"))
+	}
 
-	row := r.QueryRow(ctx, query, owner, r.clock.Now(), dssql.CellUnionToCellIds(cells))
+	defer r.timeStatement(ridmodels.ActivitySubscription, statementCount)()
+	row := r.QueryRow(ctx, query, r.storedOwner(owner), r.clock.Now(), dssql.CellUnionToCellIds(cells))
 	var ret int
 	err := row.Scan(&ret)
 	return ret, stacktrace.Propagate(err, "Error scanning subscription count row")
@@ -110,31 +195,25 @@ func (r *repo) MaxSubscriptionCountInCellsByOwner(ctx context.Context, cells s2.
 
 // GetSubscription returns the subscription identified by "id".
 // Returns nil, nil if not found
-func (r *repo) GetSubscription(ctx context.Context, id dssmodels.ID) (*ridmodels.Subscription, error) {
+func (r *repo) GetSubscription(ctx context.Context, id dssmodels.ID, forUpdate bool) (*ridmodels.Subscription, error) {
 	// TODO(steeling) we should enforce startTime and endTime to not be null at the DB level.
-	var query = fmt.Sprintf(`
+	query := fmt.Sprintf(`
 		SELECT %s FROM subscriptions
-		WHERE id = $1`, subscriptionFields)
+		WHERE id = $1
+		%s`, r.subscriptionFields(), dssql.ForUpdate(forUpdate))
 	uid, err := id.PgUUID()
 	if err != nil {
 		return nil, stacktrace.Propagate(err, "Failed to convert id to PgUUID")
 	}
+	if forUpdate {
+		defer r.timeStatement(ridmodels.ActivitySubscription, statementFetch)()
+	}
 	return r.processOne(ctx, query, uid)
 }
 
 // UpdateSubscription updates the Subscription.. not yet implemented.
 // Returns nil, nil if ID, version not found
 func (r *repo) UpdateSubscription(ctx context.Context, s *ridmodels.Subscription) (*ridmodels.Subscription, error) {
-	var (
-		updateQuery = fmt.Sprintf(`
-		UPDATE
-		  subscriptions
-		SET (%s) = ($1, $2, $3, $4, $5, $6, $7, transaction_timestamp())
-		WHERE id = $1 AND updated_at = $8
-		RETURNING
-			%s`, updateSubscriptionFields, subscriptionFields)
-	)
-
 	cids, err := dssql.CellUnionToCellIdsWithValidation(s.Cells)
 
 	if err != nil {
@@ -145,29 +224,62 @@ func (r *repo) UpdateSubscription(ctx context.Context, s *ridmodels.Subscription
 	if err != nil {
 		return nil, stacktrace.Propagate(err, "Failed to convert id to PgUUID")
 	}
-	return r.processOne(ctx, updateQuery,
-		id,
-		s.URL,
-		s.NotificationIndex,
-		cids,
-		s.StartTime,
-		s.EndTime,
-		s.Writer,
-		s.Version.ToTimestamp())
+	fields, values := updateSubscriptionFields, "$1, $2, $3, $4, $5, $6, DEFAULT"
+	args := []interface{}{id, s.URL, s.NotificationIndex, s.StartTime, s.EndTime, s.Writer, s.Version.ToTimestamp()}
+	unchanged, err := r.storesCells(ctx, "subscriptions", *id, s.Version.ToTimestamp(), cids)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if !unchanged {
+		args = append(args, cids)
+		fields, values = fields+", cells", values+fmt.Sprintf(", $%d", len(args))
+	}
+	updateQuery := fmt.Sprintf(`
+		UPDATE
+		  subscriptions
+		SET (%s) = (%s)
+		WHERE id = $1 AND updated_at = $7
+		RETURNING
+			%s`, fields, values, r.subscriptionFields())
+	done := r.timeStatement(ridmodels.ActivitySubscription, statementUpdate)
+	sub, err := r.scanOne(ctx, updateQuery, args...)
+	done()
+	if err != nil || sub == nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	// The notification index of the subscription is the one provided, so
+	// increments pending from before the update no longer apply.
+	if err := r.clearNotificationCounters(ctx, sub.ID); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if err := r.recordActivity(ctx, ridmodels.ActivitySubscription, sub.ID, ridmodels.ActivityUpdated, sub.StartTime, sub.EndTime); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return sub, nil
 }
 
 // InsertSubscription inserts subscription into the store and returns
 // the resulting subscription including its ID.
 func (r *repo) InsertSubscription(ctx context.Context, s *ridmodels.Subscription) (*ridmodels.Subscription, error) {
+	values := "$1, $2, $3, $4, $5, $6, $7, $8, DEFAULT, $9"
+	if r.subscriptionCallbacks {
+		values += ", $10"
+	}
+	if r.subscriptionNotificationIntervals {
+		values += ", $11, $12"
+	}
+	if r.subscriptionSelfNotifications {
+		values += ", $13"
+	}
 	var (
 		insertQuery = fmt.Sprintf(`
 		INSERT INTO
 		  subscriptions
 		  (%s)
 		VALUES
-			($1, $2, $3, $4, $5, $6, $7, $8, transaction_timestamp())
+			(%s)
 		RETURNING
-			%s`, subscriptionFields, subscriptionFields)
+			%s`, r.subscriptionFields(), values, r.subscriptionFields())
 	)
 
 	cids, err := dssql.CellUnionToCellIdsWithValidation(s.Cells)
@@ -180,15 +292,36 @@ func (r *repo) InsertSubscription(ctx context.Context, s *ridmodels.Subscription
 	if err != nil {
 		return nil, stacktrace.Propagate(err, "Failed to convert id to PgUUID")
 	}
-	return r.processOne(ctx, insertQuery,
+	args := []interface{}{
 		id,
-		s.Owner,
+		r.storedOwner(s.Owner),
 		s.URL,
 		s.NotificationIndex,
 		cids,
 		s.StartTime,
 		s.EndTime,
-		s.Writer)
+		s.Writer,
+		labelsArg(s.Labels),
+	}
+	if r.subscriptionCallbacks {
+		args = append(args, s.CallbackURLs)
+	}
+	if r.subscriptionNotificationIntervals {
+		args = append(args, notificationIntervalArg(s.MinNotificationInterval), s.LastNotifiedAt)
+	}
+	if r.subscriptionSelfNotifications {
+		args = append(args, s.NotifyOwnISAs)
+	}
+	done := r.timeStatement(ridmodels.ActivitySubscription, statementInsert)
+	sub, err := r.processOne(ctx, insertQuery, args...)
+	done()
+	if err != nil || sub == nil {
+		return sub, err
+	}
+	if err := r.recordActivity(ctx, ridmodels.ActivitySubscription, sub.ID, ridmodels.ActivityCreated, sub.StartTime, sub.EndTime); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return sub, nil
 }
 
 // DeleteSubscription deletes the subscription identified by ID.
@@ -202,31 +335,205 @@ func (r *repo) DeleteSubscription(ctx context.Context, s *ridmodels.Subscription
 		WHERE
 			id = $1
 			AND updated_at = $2
-		RETURNING %s`, subscriptionFields)
+		RETURNING %s`, r.subscriptionFields())
 	)
 	id, err := s.ID.PgUUID()
 	if err != nil {
 		return nil, stacktrace.Propagate(err, "Failed to convert id to PgUUID")
 	}
-	return r.processOne(ctx, query, id, s.Version.ToTimestamp())
+	done := r.timeStatement(ridmodels.ActivitySubscription, statementDelete)
+	sub, err := r.processOne(ctx, query, id, s.Version.ToTimestamp())
+	done()
+	if err != nil || sub == nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if err := r.clearNotificationCounters(ctx, sub.ID); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if err := r.recordActivity(ctx, ridmodels.ActivitySubscription, sub.ID, ridmodels.ActivityDeleted, sub.StartTime, sub.EndTime); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return sub, nil
 }
 
-// UpdateNotificationIdxsInCells incremement the notification for each sub in the given cells.
-func (r *repo) UpdateNotificationIdxsInCells(ctx context.Context, cells s2.CellUnion) ([]*ridmodels.Subscription, error) {
-	var updateQuery = fmt.Sprintf(`
+// UpdateNotificationIdxsInCells increments the notification index of, and
+// returns, every Subscription that must be notified of a change to an
+// IdentificationServiceArea owned by "owner" and covering "cells" between
+// "startTime" and "endTime": Subscriptions that are still active, are not
+// owned by "owner" unless notified of their owner's ISAs, and whose time range
+// overlaps [startTime, endTime]. A nil bound leaves that end of the range
+// open.
+func (r *repo) UpdateNotificationIdxsInCells(ctx context.Context, cells s2.CellUnion, owner dssmodels.Owner, startTime, endTime *time.Time) ([]*ridmodels.Subscription, error) {
+	defer r.timeStatement(ridmodels.ActivityISA, statementNotify)()
+	if r.counterShards > 0 {
+		return r.incrementNotificationCountersInCells(ctx, cells, owner, startTime, endTime)
+	}
+
+	updateQuery := fmt.Sprintf(`
 			UPDATE subscriptions
 			SET notification_index = notification_index + 1
 			WHERE
-				cells && $1
-				AND ends_at >= $2
-			RETURNING %s`, subscriptionFields)
+				%s
+			RETURNING %s`, r.notifiedSubscriptionsCondition(), r.subscriptionFields())
 
-	return r.process(
-		ctx, updateQuery, dssql.CellUnionToCellIds(cells), r.clock.Now())
+	subs, err := r.process(
+		ctx, updateQuery, dssql.CellUnionToCellIds(cells), r.clock.Now(), r.storedOwner(owner), startTime, endTime)
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	setPreviousNotificationIndices(subs)
+	return subs, nil
 }
 
-// SearchSubscriptions returns all subscriptions in "cells".
-func (r *repo) SearchSubscriptions(ctx context.Context, cells s2.CellUnion) ([]*ridmodels.Subscription, error) {
+// setPreviousNotificationIndices sets the notification index of subs before
+// they were incremented, by one, in this transaction.
+func setPreviousNotificationIndices(subs []*ridmodels.Subscription) {
+	for _, s := range subs {
+		s.PreviousNotificationIndex = s.NotificationIndex - 1
+	}
+}
+
+// UpdateSubscriptionLabels replaces the labels of the Subscription identified
+// by "id", leaving its version unchanged.
+// Returns nil, nil if not found
+func (r *repo) UpdateSubscriptionLabels(ctx context.Context, id dssmodels.ID, labels ridmodels.Labels) (*ridmodels.Subscription, error) {
+	var (
+		updateLabelsQuery = fmt.Sprintf(`
+		UPDATE
+		  subscriptions
+		SET labels = $2
+		WHERE id = $1
+		RETURNING
+			%s`, r.subscriptionFields())
+	)
+	uid, err := id.PgUUID()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to convert id to PgUUID")
+	}
+	return r.processOne(ctx, updateLabelsQuery, uid, labelsArg(labels))
+}
+
+// UpdateSubscriptionCallbacks replaces the callback URLs of the Subscription
+// identified by "id" besides its URL, leaving its version unchanged.
+// Returns nil, nil if not found
+func (r *repo) UpdateSubscriptionCallbacks(ctx context.Context, id dssmodels.ID, urls []string) (*ridmodels.Subscription, error) {
+	if !r.subscriptionCallbacks {
+		return nil, errSubscriptionCallbacksUnsupported()
+	}
+	var (
+		updateCallbacksQuery = fmt.Sprintf(`
+		UPDATE
+		  subscriptions
+		SET %s = $2
+		WHERE id = $1
+		RETURNING
+			%s`, subscriptionCallbacksFields, r.subscriptionFields())
+	)
+	uid, err := id.PgUUID()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to convert id to PgUUID")
+	}
+	return r.processOne(ctx, updateCallbacksQuery, uid, urls)
+}
+
+// UpdateSubscriptionNotificationInterval replaces the minimum interval between
+// the notifications of the Subscription identified by "id", leaving its
+// version unchanged.
+// Returns nil, nil if not found
+func (r *repo) UpdateSubscriptionNotificationInterval(ctx context.Context, id dssmodels.ID, interval time.Duration) (*ridmodels.Subscription, error) {
+	if !r.subscriptionNotificationIntervals {
+		return nil, errSubscriptionNotificationIntervalsUnsupported()
+	}
+	var (
+		updateIntervalQuery = fmt.Sprintf(`
+		UPDATE
+		  subscriptions
+		SET min_notification_interval_seconds = $2
+		WHERE id = $1
+		RETURNING
+			%s`, r.subscriptionFields())
+	)
+	uid, err := id.PgUUID()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to convert id to PgUUID")
+	}
+	return r.processOne(ctx, updateIntervalQuery, uid, notificationIntervalArg(interval))
+}
+
+// UpdateSubscriptionSelfNotification sets whether the Subscription identified
+// by "id" is notified of the changes to the ISAs of its owner, leaving its
+// version unchanged.
+// Returns nil, nil if not found
+func (r *repo) UpdateSubscriptionSelfNotification(ctx context.Context, id dssmodels.ID, notify bool) (*ridmodels.Subscription, error) {
+	if !r.subscriptionSelfNotifications {
+		return nil, errSubscriptionSelfNotificationsUnsupported()
+	}
+	var (
+		updateSelfNotificationQuery = fmt.Sprintf(`
+		UPDATE
+		  subscriptions
+		SET %s = $2
+		WHERE id = $1
+		RETURNING
+			%s`, subscriptionSelfNotificationFields, r.subscriptionFields())
+	)
+	uid, err := id.PgUUID()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to convert id to PgUUID")
+	}
+	return r.processOne(ctx, updateSelfNotificationQuery, uid, notify)
+}
+
+// RecordSubscriptionNotifications records that the Subscriptions identified
+// by "ids" were notified at "at", leaving their versions unchanged.
+func (r *repo) RecordSubscriptionNotifications(ctx context.Context, ids []dssmodels.ID, at time.Time) error {
+	if !r.subscriptionNotificationIntervals || len(ids) == 0 {
+		return nil
+	}
+	uids := make([]pgtype.UUID, len(ids))
+	for i, id := range ids {
+		uid, err := id.PgUUID()
+		if err != nil {
+			return stacktrace.Propagate(err, "Failed to convert id to PgUUID")
+		}
+		uids[i] = *uid
+	}
+	if _, err := r.Exec(ctx, `UPDATE subscriptions SET last_notified_at = $2 WHERE id = ANY($1)`, uids, at); err != nil {
+		return stacktrace.Propagate(err, "Error recording Subscription notifications")
+	}
+	return nil
+}
+
+// TransferSubscription makes "owner" the owner of the Subscription identified
+// by "id", giving it a new version.
+// Returns nil, nil if not found
+func (r *repo) TransferSubscription(ctx context.Context, id dssmodels.ID, owner dssmodels.Owner) (*ridmodels.Subscription, error) {
+	var (
+		transferQuery = fmt.Sprintf(`
+		UPDATE
+		  subscriptions
+		SET (owner, updated_at) = ($2, DEFAULT)
+		WHERE id = $1
+		RETURNING
+			%s`, r.subscriptionFields())
+	)
+	uid, err := id.PgUUID()
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to convert id to PgUUID")
+	}
+	sub, err := r.processOne(ctx, transferQuery, uid, r.storedOwner(owner))
+	if err != nil || sub == nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if err := r.recordActivity(ctx, ridmodels.ActivitySubscription, sub.ID, ridmodels.ActivityUpdated, sub.StartTime, sub.EndTime); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return sub, nil
+}
+
+// SearchSubscriptionsByLabels returns the Subscriptions that have not ended
+// yet and carry all of "labels".
+func (r *repo) SearchSubscriptionsByLabels(ctx context.Context, labels ridmodels.Labels) ([]*ridmodels.Subscription, error) {
 	var (
 		query = fmt.Sprintf(`
 			SELECT
@@ -234,12 +541,74 @@ func (r *repo) SearchSubscriptions(ctx context.Context, cells s2.CellUnion) ([]*
 			FROM
 				subscriptions
 			WHERE
-				cells && $1
+				labels @> $1
 			AND
 				ends_at >= $2
-			LIMIT $3`, subscriptionFields)
+			LIMIT $3`, r.subscriptionFields())
+	)
+
+	if len(labels) == 0 {
+		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing labels for query")
+	}
+
+	return r.process(ctx, query, labels, r.clock.Now(), dssmodels.MaxResultLimit)
+}
+
+// SearchSubscriptionsByURL returns the subscriptions owned by "owner" that have
+// not ended yet and whose URL contains "substring". The owner index serves the
+// query, URLs being matched among the subscriptions of the owner.
+func (r *repo) SearchSubscriptionsByURL(ctx context.Context, owner dssmodels.Owner, substring string) ([]*ridmodels.Subscription, error) {
+	var (
+		query = fmt.Sprintf(`
+			SELECT
+				%s
+			FROM
+				subscriptions
+			WHERE
+				owner = $1
+			AND
+				strpos(url, $2) > 0
+			AND
+				ends_at >= $3
+			LIMIT $4`, r.subscriptionFields())
 	)
 
+	return r.process(ctx, query, r.storedOwner(owner), substring, r.clock.Now(), dssmodels.MaxResultLimit)
+}
+
+// CountSubscriptionsByOwner returns the number of Subscriptions owned by
+// "owner" that have not ended yet, without fetching them.
+func (r *repo) CountSubscriptionsByOwner(ctx context.Context, owner dssmodels.Owner) (int, error) {
+	const countSubscriptionsByOwnerQuery = `
+		SELECT
+			COUNT(*)
+		FROM
+			subscriptions
+		WHERE
+			owner = $1
+		AND
+			ends_at >= $2`
+
+	var count int
+	if err := r.QueryRow(ctx, countSubscriptionsByOwnerQuery, r.storedOwner(owner), r.clock.Now()).Scan(&count); err != nil {
+		return 0, stacktrace.Propagate(err, "Error counting Subscriptions of %s", owner)
+	}
+	return count, nil
+}
+
+// SearchSubscriptions returns all subscriptions in "cells".
+func (r *repo) SearchSubscriptions(ctx context.Context, cells s2.CellUnion) ([]*ridmodels.Subscription, error) {
+	query := fmt.Sprintf(`
+			SELECT
+				%s
+			FROM
+				subscriptions
+			WHERE
+				%s
+			AND
+				ends_at >= $2
+			LIMIT $3`, r.subscriptionFields(), dssql.CellsIntersect("cells", "$1"))
+
 	if len(cells) == 0 {
 		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "no location provided")
 	}
@@ -256,19 +625,19 @@ func (r *repo) SearchSubscriptionsByOwner(ctx context.Context, cells s2.CellUnio
 			FROM
 				subscriptions
 			WHERE
-				cells && $1
+				%s
 			AND
 				subscriptions.owner = $2
 			AND
 				ends_at >= $3
-			LIMIT $4`, subscriptionFields)
+			LIMIT $4`, r.subscriptionFields(), dssql.CellsIntersect("cells", "$1"))
 	)
 
 	if len(cells) == 0 {
 		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "no location provided")
 	}
 
-	return r.process(ctx, query, dssql.CellUnionToCellIds(cells), owner, r.clock.Now(), dssmodels.MaxResultLimit)
+	return r.process(ctx, query, dssql.CellUnionToCellIds(cells), r.storedOwner(owner), r.clock.Now(), dssmodels.MaxResultLimit)
 }
 
 // ListExpiredSubscriptions lists all expired Subscriptions based on writer.
@@ -289,7 +658,7 @@ func (r *repo) ListExpiredSubscriptions(ctx context.Context, writer string) ([]*
 	WHERE
 		ends_at + INTERVAL '%d' MINUTE <= CURRENT_TIMESTAMP
 	AND
-		(writer = %s)`, subscriptionFields, expiredDurationInMin, writerQuery)
+		(writer = %s)`, r.subscriptionFields(), expiredDurationInMin, writerQuery)
 	)
 
 	return r.process(ctx, query)
@@ -83,7 +83,7 @@ func TestStoreGetSubscription(t *testing.T) {
 			require.NoError(t, err)
 			require.NotNil(t, sub1)
 
-			sub2, err := repo.GetSubscription(ctx, sub1.ID)
+			sub2, err := repo.GetSubscription(ctx, sub1.ID, false)
 			require.NoError(t, err)
 			require.NotNil(t, sub2)
 
@@ -132,7 +132,7 @@ func TestStoreInsertSubscription(t *testing.T) {
 			require.NoError(t, err)
 			require.Nil(t, sub4)
 
-			sub5, err := repo.GetSubscription(ctx, sub1.ID)
+			sub5, err := repo.GetSubscription(ctx, sub1.ID, false)
 			require.NoError(t, err)
 			require.NotNil(t, sub5)
 
@@ -224,6 +224,52 @@ func TestStoreSearchSubscription(t *testing.T) {
 	}
 }
 
+func TestStoreSearchSubscriptionsByURL(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	defer tearDownStore()
+
+	repo, err := store.Interact(ctx)
+	require.NoError(t, err)
+
+	var ids []dssmodels.ID
+	for _, r := range []struct {
+		owner dssmodels.Owner
+		url   string
+	}{
+		{"uss1", "https://old.uss1.example/notify"},
+		{"uss1", "https://new.uss1.example/notify"},
+		{"uss2", "https://old.uss1.example/notify"},
+	} {
+		subscription := *subscriptionsPool[0].input
+		subscription.ID = dssmodels.ID(uuid.New().String())
+		subscription.Owner = r.owner
+		subscription.URL = r.url
+		sub, err := repo.InsertSubscription(ctx, &subscription)
+		require.NoError(t, err)
+		ids = append(ids, sub.ID)
+	}
+
+	found, err := repo.SearchSubscriptionsByURL(ctx, "uss1", "old.uss1")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, ids[0], found[0].ID)
+
+	found, err = repo.SearchSubscriptionsByURL(ctx, "uss1", "")
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+
+	found, err = repo.SearchSubscriptionsByURL(ctx, "uss1", "decommissioned")
+	require.NoError(t, err)
+	require.Empty(t, found)
+
+	count, err := repo.CountSubscriptionsByOwner(ctx, "uss1")
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+}
+
 func TestStoreExpiredSubscription(t *testing.T) {
 	ctx := context.Background()
 	store, tearDownStore := setUpStore(ctx, t)
@@ -250,7 +296,7 @@ func TestStoreExpiredSubscription(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, subs, 1)
 
-	ret, err := repo.GetSubscription(ctx, sub.ID)
+	ret, err := repo.GetSubscription(ctx, sub.ID, false)
 	require.NoError(t, err)
 	require.NotNil(t, &ret)
 
@@ -261,7 +307,7 @@ func TestStoreExpiredSubscription(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, subs, 0)
 
-	ret, err = repo.GetSubscription(ctx, sub.ID)
+	ret, err = repo.GetSubscription(ctx, sub.ID, false)
 	require.NotNil(t, ret)
 	require.NoError(t, err)
 }
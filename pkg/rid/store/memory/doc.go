@@ -0,0 +1,5 @@
+// Package memory implements an in-memory remote ID store behaving like the
+// CockroachDB store, e.g. for a DSS instance to run without a database in
+// local development or for hermetic tests. Importing it registers the memory
+// scheme of store.Open.
+package memory
@@ -0,0 +1,728 @@
+package memory
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/golang/geo/s2"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/geo"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/dss/pkg/rid/repos"
+	"github.com/interuss/dss/pkg/rid/store"
+	ridc "github.com/interuss/dss/pkg/rid/store/cockroach"
+	"github.com/interuss/stacktrace"
+	"github.com/jonboulle/clockwork"
+	"go.uber.org/zap"
+)
+
+// expiredAfter is the time after their end at which entities are listed as
+// expired, as by the CockroachDB store.
+const expiredAfter = 30 * time.Minute
+
+// Store is an in-memory store.Store behaving like the CockroachDB store:
+// entities are versioned by the time of their last write, writes of a
+// version other than the current one are ignored, so that the application
+// reports version conflicts, and transactions failing are rolled back.
+// Altitudes of subscriptions are not stored, as by the CockroachDB store, and
+// footprints of ISAs are shared with callers, which must not modify them.
+// Store is safe for concurrent use; transactions are serialized.
+type Store struct {
+	// Clock is the clock against which entities are considered active or
+	// expired.
+	Clock clockwork.Clock
+
+	mu          sync.Mutex
+	isas        map[dssmodels.ID]*ridmodels.IdentificationServiceArea
+	subs        map[dssmodels.ID]*ridmodels.Subscription
+	events      []*ridmodels.ActivityEvent
+	aliases     map[dssmodels.Owner]*ridmodels.OwnerAlias
+	lastVersion time.Time
+}
+
+var _ store.Store = &Store{}
+
+// Scheme is the URI scheme of the empty Stores opened through store.Open,
+// e.g. for a DSS instance to run without a database in local development;
+// their contents are lost when the instance stops.
+const Scheme = "memory"
+
+func init() {
+	store.Register(Scheme, func(context.Context, *url.URL, *zap.Logger) (store.Store, error) {
+		return NewStore(), nil
+	})
+}
+
+// NewStore returns an empty Store using the real clock.
+func NewStore() *Store {
+	return &Store{
+		Clock:   clockwork.NewRealClock(),
+		isas:    map[dssmodels.ID]*ridmodels.IdentificationServiceArea{},
+		subs:    map[dssmodels.ID]*ridmodels.Subscription{},
+		aliases: map[dssmodels.Owner]*ridmodels.OwnerAlias{},
+	}
+}
+
+// Interact implements store.Interactor.
+func (s *Store) Interact(ctx context.Context) (repos.Repository, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, stacktrace.Propagate(err, "Context done before interacting with the store")
+	}
+	return &repo{store: s}, nil
+}
+
+// Transact implements store.Transactor. As by the CockroachDB store, the
+// transaction is rolled back if ctx is done before f returns.
+func (s *Store) Transact(ctx context.Context, f func(repos.Repository) error) error {
+	if err := ctx.Err(); err != nil {
+		return stacktrace.Propagate(err, "Context done before beginning transaction")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	isas := make(map[dssmodels.ID]*ridmodels.IdentificationServiceArea, len(s.isas))
+	for id, isa := range s.isas {
+		isas[id] = isa
+	}
+	subs := make(map[dssmodels.ID]*ridmodels.Subscription, len(s.subs))
+	for id, sub := range s.subs {
+		subs[id] = sub
+	}
+	aliases := make(map[dssmodels.Owner]*ridmodels.OwnerAlias, len(s.aliases))
+	for subject, alias := range s.aliases {
+		aliases[subject] = alias
+	}
+	events := len(s.events)
+
+	if err := f(&repo{store: s, inTx: true}); err != nil {
+		s.isas, s.subs, s.aliases, s.events = isas, subs, aliases, s.events[:events]
+		return err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if err := ctx.Err(); err != nil {
+		s.isas, s.subs, s.aliases, s.events = isas, subs, aliases, s.events[:events]
+		return stacktrace.Propagate(err, "Context done before committing transaction")
+	}
+	return nil
+}
+
+// GetVersion implements store.Store, reporting the schema version of the
+// latest migrations.
+func (s *Store) GetVersion(context.Context) (*semver.Version, error) {
+	return ridc.TargetSchemaVersion, nil
+}
+
+// Close implements io.Closer.
+func (s *Store) Close() error {
+	return nil
+}
+
+// nextVersion returns the version of an entity being written, later than
+// those of all the entities written before.
+func (s *Store) nextVersion() *dssmodels.Version {
+	t := time.Now().UTC()
+	if !t.After(s.lastVersion) {
+		t = s.lastVersion.Add(time.Microsecond)
+	}
+	s.lastVersion = t
+	return dssmodels.VersionFromTime(t)
+}
+
+func (s *Store) recordActivity(entity ridmodels.ActivityEntity, id dssmodels.ID, kind ridmodels.ActivityKind, startTime, endTime *time.Time) {
+	s.events = append(s.events, &ridmodels.ActivityEvent{
+		Entity:     entity,
+		EntityID:   id,
+		Kind:       kind,
+		OccurredAt: s.Clock.Now(),
+		StartTime:  copyTime(startTime),
+		EndTime:    copyTime(endTime),
+	})
+}
+
+// repo implements repos.Repository over the content of store.
+type repo struct {
+	store *Store
+	// inTx is set when the lock of store is already held by a transaction.
+	inTx bool
+}
+
+// lock locks the store unless r is part of a transaction, returning the
+// function unlocking it.
+func (r *repo) lock() func() {
+	if r.inTx {
+		return func() {}
+	}
+	r.store.mu.Lock()
+	return r.store.mu.Unlock
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func copyAltitude(a *float32) *float32 {
+	if a == nil {
+		return nil
+	}
+	c := *a
+	return &c
+}
+
+func copyLabels(labels ridmodels.Labels) ridmodels.Labels {
+	if labels == nil {
+		return nil
+	}
+	c := make(ridmodels.Labels, len(labels))
+	for k, v := range labels {
+		c[k] = v
+	}
+	return c
+}
+
+func copyISA(isa *ridmodels.IdentificationServiceArea) *ridmodels.IdentificationServiceArea {
+	c := *isa
+	c.Cells = append(s2.CellUnion(nil), isa.Cells...)
+	c.StartTime = copyTime(isa.StartTime)
+	c.EndTime = copyTime(isa.EndTime)
+	c.AltitudeLo, c.AltitudeHi = copyAltitude(isa.AltitudeLo), copyAltitude(isa.AltitudeHi)
+	c.Labels = copyLabels(isa.Labels)
+	return &c
+}
+
+func copySubscription(sub *ridmodels.Subscription) *ridmodels.Subscription {
+	c := *sub
+	c.Cells = append(s2.CellUnion(nil), sub.Cells...)
+	c.StartTime = copyTime(sub.StartTime)
+	c.EndTime = copyTime(sub.EndTime)
+	c.AltitudeLo, c.AltitudeHi = nil, nil
+	c.Labels = copyLabels(sub.Labels)
+	c.CallbackURLs = append([]string(nil), sub.CallbackURLs...)
+	c.LastNotifiedAt = copyTime(sub.LastNotifiedAt)
+	return &c
+}
+
+func validateCells(cells s2.CellUnion) error {
+	for _, cell := range cells {
+		if err := geo.ValidateCell(cell); err != nil {
+			return stacktrace.Propagate(err, "Error validating cell")
+		}
+	}
+	return nil
+}
+
+// endsAtOrAfter returns whether end, which never matches if nil as NULL in
+// SQL, is at or after t.
+func endsAtOrAfter(end *time.Time, t time.Time) bool {
+	return end != nil && !end.Before(t)
+}
+
+// hasLabels returns whether entity carries all of labels.
+func hasLabels(entity, labels ridmodels.Labels) bool {
+	for k, v := range labels {
+		if w, ok := entity[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// isaList returns copies of the ISAs matching keep, ordered by ID and
+// bounded by dssmodels.MaxResultLimit.
+func (r *repo) isaList(keep func(*ridmodels.IdentificationServiceArea) bool) []*ridmodels.IdentificationServiceArea {
+	var isas []*ridmodels.IdentificationServiceArea
+	for _, isa := range r.store.isas {
+		if keep(isa) {
+			isas = append(isas, copyISA(isa))
+		}
+	}
+	sort.Slice(isas, func(i, j int) bool { return isas[i].ID < isas[j].ID })
+	if len(isas) > dssmodels.MaxResultLimit {
+		isas = isas[:dssmodels.MaxResultLimit]
+	}
+	return isas
+}
+
+// subscriptionList returns copies of the subscriptions matching keep,
+// ordered by ID and bounded by dssmodels.MaxResultLimit.
+func (r *repo) subscriptionList(keep func(*ridmodels.Subscription) bool) []*ridmodels.Subscription {
+	var subs []*ridmodels.Subscription
+	for _, sub := range r.store.subs {
+		if keep(sub) {
+			subs = append(subs, copySubscription(sub))
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+	if len(subs) > dssmodels.MaxResultLimit {
+		subs = subs[:dssmodels.MaxResultLimit]
+	}
+	return subs
+}
+
+// GetISA implements repos.ISA.
+func (r *repo) GetISA(_ context.Context, id dssmodels.ID, _ bool) (*ridmodels.IdentificationServiceArea, error) {
+	defer r.lock()()
+	if isa, ok := r.store.isas[id]; ok {
+		return copyISA(isa), nil
+	}
+	return nil, nil
+}
+
+// DeleteISA implements repos.ISA.
+func (r *repo) DeleteISA(_ context.Context, isa *ridmodels.IdentificationServiceArea) (*ridmodels.IdentificationServiceArea, error) {
+	defer r.lock()()
+	old, ok := r.store.isas[isa.ID]
+	if !ok || !old.Version.Matches(isa.Version) {
+		return nil, nil
+	}
+	delete(r.store.isas, isa.ID)
+	r.store.recordActivity(ridmodels.ActivityISA, old.ID, ridmodels.ActivityDeleted, old.StartTime, old.EndTime)
+	return copyISA(old), nil
+}
+
+// InsertISA implements repos.ISA.
+func (r *repo) InsertISA(_ context.Context, isa *ridmodels.IdentificationServiceArea) (*ridmodels.IdentificationServiceArea, error) {
+	defer r.lock()()
+	if err := validateCells(isa.Cells); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if _, ok := r.store.isas[isa.ID]; ok {
+		return nil, stacktrace.NewError("ISA %s already exists", isa.ID)
+	}
+	stored := copyISA(isa)
+	stored.Version = r.store.nextVersion()
+	r.store.isas[isa.ID] = stored
+	r.store.recordActivity(ridmodels.ActivityISA, stored.ID, ridmodels.ActivityCreated, stored.StartTime, stored.EndTime)
+	return copyISA(stored), nil
+}
+
+// UpdateISA implements repos.ISA.
+func (r *repo) UpdateISA(_ context.Context, isa *ridmodels.IdentificationServiceArea) (*ridmodels.IdentificationServiceArea, error) {
+	defer r.lock()()
+	if err := validateCells(isa.Cells); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	old, ok := r.store.isas[isa.ID]
+	if !ok || !old.Version.Matches(isa.Version) {
+		return nil, nil
+	}
+	stored := copyISA(isa)
+	stored.Owner = old.Owner
+	stored.Labels = old.Labels
+	stored.Version = r.store.nextVersion()
+	r.store.isas[isa.ID] = stored
+	return copyISA(stored), nil
+}
+
+// SearchISAs implements repos.ISA.
+func (r *repo) SearchISAs(_ context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time, excludeOwner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error) {
+	if len(cells) == 0 {
+		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing cell IDs for query")
+	}
+	if earliest == nil {
+		return nil, stacktrace.NewError("Earliest start time is missing")
+	}
+	defer r.lock()()
+	return r.isaList(func(isa *ridmodels.IdentificationServiceArea) bool {
+		return endsAtOrAfter(isa.EndTime, *earliest) &&
+			(latest == nil || isa.StartTime == nil || !isa.StartTime.After(*latest)) &&
+			isa.Cells.Intersects(cells) &&
+			(excludeOwner == "" || isa.Owner != excludeOwner)
+	}), nil
+}
+
+// CountISAs implements repos.ISA.
+func (r *repo) CountISAs(ctx context.Context, cells s2.CellUnion, earliest *time.Time, latest *time.Time, limit int) (int, error) {
+	isas, err := r.SearchISAs(ctx, cells, earliest, latest, "")
+	if err != nil {
+		return 0, err
+	}
+	return min(len(isas), limit), nil
+}
+
+// UpdateISALabels implements repos.ISA.
+func (r *repo) UpdateISALabels(_ context.Context, id dssmodels.ID, labels ridmodels.Labels) (*ridmodels.IdentificationServiceArea, error) {
+	defer r.lock()()
+	old, ok := r.store.isas[id]
+	if !ok {
+		return nil, nil
+	}
+	stored := copyISA(old)
+	stored.Labels = copyLabels(labels)
+	r.store.isas[id] = stored
+	return copyISA(stored), nil
+}
+
+// TransferISA implements repos.ISA.
+func (r *repo) TransferISA(_ context.Context, id dssmodels.ID, owner dssmodels.Owner) (*ridmodels.IdentificationServiceArea, error) {
+	defer r.lock()()
+	old, ok := r.store.isas[id]
+	if !ok {
+		return nil, nil
+	}
+	stored := copyISA(old)
+	stored.Owner = owner
+	stored.Version = r.store.nextVersion()
+	r.store.isas[id] = stored
+	return copyISA(stored), nil
+}
+
+// SearchISAsByLabels implements repos.ISA.
+func (r *repo) SearchISAsByLabels(_ context.Context, labels ridmodels.Labels) ([]*ridmodels.IdentificationServiceArea, error) {
+	if len(labels) == 0 {
+		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing labels for query")
+	}
+	defer r.lock()()
+	now := r.store.Clock.Now()
+	return r.isaList(func(isa *ridmodels.IdentificationServiceArea) bool {
+		return hasLabels(isa.Labels, labels) && endsAtOrAfter(isa.EndTime, now)
+	}), nil
+}
+
+// ListISAsByOwner implements repos.ISA.
+func (r *repo) ListISAsByOwner(_ context.Context, owner dssmodels.Owner) ([]*ridmodels.IdentificationServiceArea, error) {
+	defer r.lock()()
+	now := r.store.Clock.Now()
+	return r.isaList(func(isa *ridmodels.IdentificationServiceArea) bool {
+		return isa.Owner == owner && endsAtOrAfter(isa.EndTime, now)
+	}), nil
+}
+
+// CountISAsByOwner implements repos.ISA.
+func (r *repo) CountISAsByOwner(_ context.Context, owner dssmodels.Owner) (int, error) {
+	defer r.lock()()
+	now := r.store.Clock.Now()
+	count := 0
+	for _, isa := range r.store.isas {
+		if isa.Owner == owner && endsAtOrAfter(isa.EndTime, now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// SearchISAsByURL implements repos.ISA.
+func (r *repo) SearchISAsByURL(_ context.Context, url string, prefix bool) ([]*ridmodels.IdentificationServiceArea, error) {
+	if url == "" {
+		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing URL for query")
+	}
+	defer r.lock()()
+	now := r.store.Clock.Now()
+	return r.isaList(func(isa *ridmodels.IdentificationServiceArea) bool {
+		matches := isa.URL == url || (prefix && strings.HasPrefix(isa.URL, url))
+		return matches && endsAtOrAfter(isa.EndTime, now)
+	}), nil
+}
+
+// ListExpiredISAs implements repos.ISA.
+func (r *repo) ListExpiredISAs(_ context.Context, writer string) ([]*ridmodels.IdentificationServiceArea, error) {
+	defer r.lock()()
+	now := r.store.Clock.Now()
+	return r.isaList(func(isa *ridmodels.IdentificationServiceArea) bool {
+		return isa.Writer == writer && isa.EndTime != nil && !isa.EndTime.Add(expiredAfter).After(now)
+	}), nil
+}
+
+// GetSubscription implements repos.Subscription.
+func (r *repo) GetSubscription(_ context.Context, id dssmodels.ID, _ bool) (*ridmodels.Subscription, error) {
+	defer r.lock()()
+	if sub, ok := r.store.subs[id]; ok {
+		return copySubscription(sub), nil
+	}
+	return nil, nil
+}
+
+// DeleteSubscription implements repos.Subscription.
+func (r *repo) DeleteSubscription(_ context.Context, sub *ridmodels.Subscription) (*ridmodels.Subscription, error) {
+	defer r.lock()()
+	old, ok := r.store.subs[sub.ID]
+	if !ok || !old.Version.Matches(sub.Version) {
+		return nil, nil
+	}
+	delete(r.store.subs, sub.ID)
+	r.store.recordActivity(ridmodels.ActivitySubscription, old.ID, ridmodels.ActivityDeleted, old.StartTime, old.EndTime)
+	return copySubscription(old), nil
+}
+
+// InsertSubscription implements repos.Subscription.
+func (r *repo) InsertSubscription(_ context.Context, sub *ridmodels.Subscription) (*ridmodels.Subscription, error) {
+	defer r.lock()()
+	if err := validateCells(sub.Cells); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	if _, ok := r.store.subs[sub.ID]; ok {
+		return nil, stacktrace.NewError("Subscription %s already exists", sub.ID)
+	}
+	stored := copySubscription(sub)
+	stored.Version = r.store.nextVersion()
+	r.store.subs[sub.ID] = stored
+	r.store.recordActivity(ridmodels.ActivitySubscription, stored.ID, ridmodels.ActivityCreated, stored.StartTime, stored.EndTime)
+	return copySubscription(stored), nil
+}
+
+// UpdateSubscription implements repos.Subscription.
+func (r *repo) UpdateSubscription(_ context.Context, sub *ridmodels.Subscription) (*ridmodels.Subscription, error) {
+	defer r.lock()()
+	if err := validateCells(sub.Cells); err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	old, ok := r.store.subs[sub.ID]
+	if !ok || !old.Version.Matches(sub.Version) {
+		return nil, nil
+	}
+	stored := copySubscription(sub)
+	stored.Owner = old.Owner
+	stored.Labels = old.Labels
+	stored.CallbackURLs = old.CallbackURLs
+	stored.MinNotificationInterval, stored.LastNotifiedAt = old.MinNotificationInterval, old.LastNotifiedAt
+	stored.NotifyOwnISAs = old.NotifyOwnISAs
+	stored.Version = r.store.nextVersion()
+	r.store.subs[sub.ID] = stored
+	r.store.recordActivity(ridmodels.ActivitySubscription, stored.ID, ridmodels.ActivityUpdated, stored.StartTime, stored.EndTime)
+	return copySubscription(stored), nil
+}
+
+// SearchSubscriptions implements repos.Subscription.
+func (r *repo) SearchSubscriptions(_ context.Context, cells s2.CellUnion) ([]*ridmodels.Subscription, error) {
+	if len(cells) == 0 {
+		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "no location provided")
+	}
+	defer r.lock()()
+	now := r.store.Clock.Now()
+	return r.subscriptionList(func(sub *ridmodels.Subscription) bool {
+		return sub.Cells.Intersects(cells) && endsAtOrAfter(sub.EndTime, now)
+	}), nil
+}
+
+// SearchSubscriptionsByOwner implements repos.Subscription.
+func (r *repo) SearchSubscriptionsByOwner(_ context.Context, cells s2.CellUnion, owner dssmodels.Owner) ([]*ridmodels.Subscription, error) {
+	if len(cells) == 0 {
+		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "no location provided")
+	}
+	defer r.lock()()
+	now := r.store.Clock.Now()
+	return r.subscriptionList(func(sub *ridmodels.Subscription) bool {
+		return sub.Owner == owner && sub.Cells.Intersects(cells) && endsAtOrAfter(sub.EndTime, now)
+	}), nil
+}
+
+// UpdateNotificationIdxsInCells implements repos.Subscription.
+func (r *repo) UpdateNotificationIdxsInCells(_ context.Context, cells s2.CellUnion, owner dssmodels.Owner, startTime, endTime *time.Time) ([]*ridmodels.Subscription, error) {
+	defer r.lock()()
+	now := r.store.Clock.Now()
+	var notified []*ridmodels.Subscription
+	for id, sub := range r.store.subs {
+		if !sub.Cells.Intersects(cells) || !endsAtOrAfter(sub.EndTime, now) || (sub.Owner == owner && !sub.NotifyOwnISAs) ||
+			(startTime != nil && !endsAtOrAfter(sub.EndTime, *startTime)) ||
+			(endTime != nil && sub.StartTime != nil && sub.StartTime.After(*endTime)) {
+			continue
+		}
+		stored := copySubscription(sub)
+		stored.NotificationIndex++
+		r.store.subs[id] = stored
+		result := copySubscription(stored)
+		result.PreviousNotificationIndex = sub.NotificationIndex
+		notified = append(notified, result)
+	}
+	sort.Slice(notified, func(i, j int) bool { return notified[i].ID < notified[j].ID })
+	return notified, nil
+}
+
+// UpdateSubscriptionLabels implements repos.Subscription.
+func (r *repo) UpdateSubscriptionLabels(_ context.Context, id dssmodels.ID, labels ridmodels.Labels) (*ridmodels.Subscription, error) {
+	defer r.lock()()
+	old, ok := r.store.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	stored := copySubscription(old)
+	stored.Labels = copyLabels(labels)
+	r.store.subs[id] = stored
+	return copySubscription(stored), nil
+}
+
+// UpdateSubscriptionCallbacks implements repos.Subscription.
+func (r *repo) UpdateSubscriptionCallbacks(_ context.Context, id dssmodels.ID, urls []string) (*ridmodels.Subscription, error) {
+	defer r.lock()()
+	old, ok := r.store.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	stored := copySubscription(old)
+	stored.CallbackURLs = append([]string(nil), urls...)
+	r.store.subs[id] = stored
+	return copySubscription(stored), nil
+}
+
+// UpdateSubscriptionNotificationInterval implements repos.Subscription.
+func (r *repo) UpdateSubscriptionNotificationInterval(_ context.Context, id dssmodels.ID, interval time.Duration) (*ridmodels.Subscription, error) {
+	defer r.lock()()
+	old, ok := r.store.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	stored := copySubscription(old)
+	stored.MinNotificationInterval = interval
+	r.store.subs[id] = stored
+	return copySubscription(stored), nil
+}
+
+// RecordSubscriptionNotifications implements repos.Subscription.
+func (r *repo) RecordSubscriptionNotifications(_ context.Context, ids []dssmodels.ID, at time.Time) error {
+	defer r.lock()()
+	for _, id := range ids {
+		if old, ok := r.store.subs[id]; ok {
+			stored := copySubscription(old)
+			stored.LastNotifiedAt = &at
+			r.store.subs[id] = stored
+		}
+	}
+	return nil
+}
+
+// UpdateSubscriptionSelfNotification implements repos.Subscription.
+func (r *repo) UpdateSubscriptionSelfNotification(_ context.Context, id dssmodels.ID, notify bool) (*ridmodels.Subscription, error) {
+	defer r.lock()()
+	old, ok := r.store.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	stored := copySubscription(old)
+	stored.NotifyOwnISAs = notify
+	r.store.subs[id] = stored
+	return copySubscription(stored), nil
+}
+
+// TransferSubscription implements repos.Subscription.
+func (r *repo) TransferSubscription(_ context.Context, id dssmodels.ID, owner dssmodels.Owner) (*ridmodels.Subscription, error) {
+	defer r.lock()()
+	old, ok := r.store.subs[id]
+	if !ok {
+		return nil, nil
+	}
+	stored := copySubscription(old)
+	stored.Owner = owner
+	stored.Version = r.store.nextVersion()
+	r.store.subs[id] = stored
+	r.store.recordActivity(ridmodels.ActivitySubscription, stored.ID, ridmodels.ActivityUpdated, stored.StartTime, stored.EndTime)
+	return copySubscription(stored), nil
+}
+
+// SearchSubscriptionsByLabels implements repos.Subscription.
+func (r *repo) SearchSubscriptionsByLabels(_ context.Context, labels ridmodels.Labels) ([]*ridmodels.Subscription, error) {
+	if len(labels) == 0 {
+		return nil, stacktrace.NewErrorWithCode(dsserr.BadRequest, "Missing labels for query")
+	}
+	defer r.lock()()
+	now := r.store.Clock.Now()
+	return r.subscriptionList(func(sub *ridmodels.Subscription) bool {
+		return hasLabels(sub.Labels, labels) && endsAtOrAfter(sub.EndTime, now)
+	}), nil
+}
+
+// SearchSubscriptionsByURL implements repos.Subscription.
+func (r *repo) SearchSubscriptionsByURL(_ context.Context, owner dssmodels.Owner, substring string) ([]*ridmodels.Subscription, error) {
+	defer r.lock()()
+	now := r.store.Clock.Now()
+	return r.subscriptionList(func(sub *ridmodels.Subscription) bool {
+		return sub.Owner == owner && strings.Contains(sub.URL, substring) && endsAtOrAfter(sub.EndTime, now)
+	}), nil
+}
+
+// CountSubscriptionsByOwner implements repos.Subscription.
+func (r *repo) CountSubscriptionsByOwner(_ context.Context, owner dssmodels.Owner) (int, error) {
+	defer r.lock()()
+	now := r.store.Clock.Now()
+	count := 0
+	for _, sub := range r.store.subs {
+		if sub.Owner == owner && endsAtOrAfter(sub.EndTime, now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// MaxSubscriptionCountInCellsByOwner implements repos.Subscription, counting
+// the subscriptions in each of cells they intersect.
+func (r *repo) MaxSubscriptionCountInCellsByOwner(_ context.Context, cells s2.CellUnion, owner dssmodels.Owner) (int, error) {
+	defer r.lock()()
+	now := r.store.Clock.Now()
+	max := 0
+	for _, cell := range cells {
+		n := 0
+		for _, sub := range r.store.subs {
+			if sub.Owner == owner && endsAtOrAfter(sub.EndTime, now) && sub.Cells.IntersectsCellID(cell) {
+				n++
+			}
+		}
+		if n > max {
+			max = n
+		}
+	}
+	return max, nil
+}
+
+// ListExpiredSubscriptions implements repos.Subscription.
+func (r *repo) ListExpiredSubscriptions(_ context.Context, writer string) ([]*ridmodels.Subscription, error) {
+	defer r.lock()()
+	now := r.store.Clock.Now()
+	return r.subscriptionList(func(sub *ridmodels.Subscription) bool {
+		return sub.Writer == writer && sub.EndTime != nil && !sub.EndTime.Add(expiredAfter).After(now)
+	}), nil
+}
+
+// ListActivity implements repos.Activity, returning all the events recorded
+// before "to", which include those of the subscriptions active from "from".
+func (r *repo) ListActivity(_ context.Context, _ time.Time, to time.Time) ([]*ridmodels.ActivityEvent, error) {
+	defer r.lock()()
+	var events []*ridmodels.ActivityEvent
+	for _, e := range r.store.events {
+		if e.OccurredAt.Before(to) {
+			c := *e
+			events = append(events, &c)
+		}
+	}
+	return events, nil
+}
+
+// ListOwnerAliases implements repos.OwnerAlias.
+func (r *repo) ListOwnerAliases(context.Context) ([]*ridmodels.OwnerAlias, error) {
+	defer r.lock()()
+	aliases := make([]*ridmodels.OwnerAlias, 0, len(r.store.aliases))
+	for _, alias := range r.store.aliases {
+		c := *alias
+		aliases = append(aliases, &c)
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Subject < aliases[j].Subject })
+	return aliases, nil
+}
+
+// UpsertOwnerAlias implements repos.OwnerAlias.
+func (r *repo) UpsertOwnerAlias(_ context.Context, alias *ridmodels.OwnerAlias) (*ridmodels.OwnerAlias, error) {
+	defer r.lock()()
+	now := r.store.Clock.Now()
+	stored := &ridmodels.OwnerAlias{Subject: alias.Subject, Owner: alias.Owner, UpdatedAt: &now}
+	r.store.aliases[alias.Subject] = stored
+	c := *stored
+	return &c, nil
+}
+
+// DeleteOwnerAlias implements repos.OwnerAlias.
+func (r *repo) DeleteOwnerAlias(_ context.Context, subject dssmodels.Owner) (*ridmodels.OwnerAlias, error) {
+	defer r.lock()()
+	alias, ok := r.store.aliases[subject]
+	if !ok {
+		return nil, nil
+	}
+	delete(r.store.aliases, subject)
+	c := *alias
+	return &c, nil
+}
@@ -0,0 +1,273 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/uuid"
+	dsserr "github.com/interuss/dss/pkg/errors"
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/dss/pkg/rid/application"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/interuss/dss/pkg/rid/repos"
+	"github.com/interuss/dss/pkg/rid/store"
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var cells = s2.CellUnion{s2.CellIDFromToken("479c7ffc"), s2.CellIDFromToken("479c8004")}
+
+func newISA(owner dssmodels.Owner) *ridmodels.IdentificationServiceArea {
+	start := time.Now()
+	end := start.Add(time.Hour)
+	return &ridmodels.IdentificationServiceArea{
+		ID:        dssmodels.ID("4348c8e5-0b1c-43cf-9114-2e67a4532765"),
+		Owner:     owner,
+		URL:       "https://uss1.example/rid",
+		Cells:     cells,
+		StartTime: &start,
+		EndTime:   &end,
+	}
+}
+
+func newSubscription(owner dssmodels.Owner) *ridmodels.Subscription {
+	start := time.Now()
+	end := start.Add(time.Hour)
+	return &ridmodels.Subscription{
+		ID:        dssmodels.ID("a3cde7e1-bc1c-4a95-bc94-0e2ba0e0dbbb"),
+		Owner:     owner,
+		URL:       "https://uss2.example/rid",
+		Cells:     cells,
+		StartTime: &start,
+		EndTime:   &end,
+	}
+}
+
+func TestStoreVersionConflicts(t *testing.T) {
+	ctx := context.Background()
+	app := application.NewFromTransactor(NewStore(), zap.NewNop())
+
+	isa, _, err := app.InsertISA(ctx, newISA("uss1"))
+	require.NoError(t, err)
+	require.False(t, isa.Version.Empty())
+
+	update := newISA("uss1")
+	update.Version = isa.Version
+	updated, _, err := app.UpdateISA(ctx, update)
+	require.NoError(t, err)
+	require.False(t, updated.Version.Matches(isa.Version))
+
+	// The version updated is no longer current.
+	_, _, err = app.UpdateISA(ctx, update)
+	require.Equal(t, dsserr.VersionMismatch, stacktrace.GetCode(err))
+	_, _, err = app.DeleteISA(ctx, isa.ID, "uss1", isa.Version)
+	require.Equal(t, dsserr.StaleVersion, stacktrace.GetCode(err))
+
+	_, _, err = app.DeleteISA(ctx, isa.ID, "uss1", updated.Version)
+	require.NoError(t, err)
+}
+
+func TestStoreNotifiesSubscriptions(t *testing.T) {
+	ctx := context.Background()
+	app := application.NewFromTransactor(NewStore(), zap.NewNop())
+
+	_, err := app.InsertSubscription(ctx, newSubscription("uss2"))
+	require.NoError(t, err)
+
+	_, subs, err := app.InsertISA(ctx, newISA("uss1"))
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	require.Equal(t, 1, subs[0].NotificationIndex)
+
+	count, err := app.CountISAs(ctx, cells, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	isas, err := app.SearchISAs(ctx, cells, nil, nil, "uss1")
+	require.NoError(t, err)
+	require.Empty(t, isas)
+	isas, err = app.SearchISAs(ctx, cells, nil, nil, "")
+	require.NoError(t, err)
+	require.Len(t, isas, 1)
+}
+
+func TestStoreKeepsSubscriptionCallbacks(t *testing.T) {
+	ctx := context.Background()
+	app := application.NewFromTransactor(NewStore(), zap.NewNop())
+
+	sub, err := app.InsertSubscription(ctx, newSubscription("uss2"))
+	require.NoError(t, err)
+
+	_, err = app.SetSubscriptionCallbacks(ctx, sub.ID, "uss1", []string{"https://uss2.example/flights"})
+	require.Equal(t, dsserr.PermissionDenied, stacktrace.GetCode(err))
+	_, err = app.SetSubscriptionCallbacks(ctx, sub.ID, "uss2", []string{"https://uss2.example/flights", "https://uss2.example/flights"})
+	require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err))
+
+	sub, err = app.SetSubscriptionCallbacks(ctx, sub.ID, "uss2", []string{"https://uss2.example/flights"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://uss2.example/rid", "https://uss2.example/flights"}, sub.URLs())
+
+	// Updates through the remote ID APIs keep the callback URLs.
+	update := newSubscription("uss2")
+	update.Version = sub.Version
+	_, err = app.UpdateSubscription(ctx, update)
+	require.NoError(t, err)
+
+	_, subs, err := app.InsertISA(ctx, newISA("uss1"))
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	require.Equal(t, []string{"https://uss2.example/flights"}, subs[0].CallbackURLs)
+}
+
+func TestStoreCollapsesNotificationsWithinInterval(t *testing.T) {
+	ctx := context.Background()
+	app := application.NewFromTransactor(NewStore(), zap.NewNop())
+
+	sub, err := app.InsertSubscription(ctx, newSubscription("uss2"))
+	require.NoError(t, err)
+	_, err = app.SetSubscriptionNotificationInterval(ctx, sub.ID, "uss1", time.Minute)
+	require.Equal(t, dsserr.PermissionDenied, stacktrace.GetCode(err))
+	_, err = app.SetSubscriptionNotificationInterval(ctx, sub.ID, "uss2", 2*time.Hour)
+	require.Equal(t, dsserr.BadRequest, stacktrace.GetCode(err))
+	_, err = app.SetSubscriptionNotificationInterval(ctx, sub.ID, "uss2", time.Hour)
+	require.NoError(t, err)
+
+	// The first change is notified, the next within the interval only
+	// increments the notification index.
+	isa, subs, err := app.InsertISA(ctx, newISA("uss1"))
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	update := newISA("uss1")
+	update.Version = isa.Version
+	_, subs, err = app.UpdateISA(ctx, update)
+	require.NoError(t, err)
+	require.Empty(t, subs)
+	sub, err = app.GetSubscription(ctx, sub.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, sub.NotificationIndex)
+
+	// Without an interval, every change is notified again.
+	_, err = app.SetSubscriptionNotificationInterval(ctx, sub.ID, "uss2", 0)
+	require.NoError(t, err)
+	isa, err = app.GetISA(ctx, isa.ID)
+	require.NoError(t, err)
+	_, subs, err = app.DeleteISA(ctx, isa.ID, "uss1", isa.Version)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	require.Equal(t, 3, subs[0].NotificationIndex)
+}
+
+func TestStoreNotifiesOwnISAsOnOptIn(t *testing.T) {
+	ctx := context.Background()
+	app := application.NewFromTransactor(NewStore(), zap.NewNop())
+
+	sub, err := app.InsertSubscription(ctx, newSubscription("uss1"))
+	require.NoError(t, err)
+	_, err = app.SetSubscriptionSelfNotification(ctx, sub.ID, "uss2", true)
+	require.Equal(t, dsserr.PermissionDenied, stacktrace.GetCode(err))
+
+	// The subscriptions of the owner of an ISA are not notified by default.
+	isa, subs, err := app.InsertISA(ctx, newISA("uss1"))
+	require.NoError(t, err)
+	require.Empty(t, subs)
+
+	sub, err = app.SetSubscriptionSelfNotification(ctx, sub.ID, "uss1", true)
+	require.NoError(t, err)
+	require.True(t, sub.NotifyOwnISAs)
+	update := newISA("uss1")
+	update.Version = isa.Version
+	_, subs, err = app.UpdateISA(ctx, update)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	require.Equal(t, sub.ID, subs[0].ID)
+
+	// The opt-in survives updates of the subscription.
+	updateSub := newSubscription("uss1")
+	updateSub.Version = subs[0].Version
+	sub, err = app.UpdateSubscription(ctx, updateSub)
+	require.NoError(t, err)
+	require.True(t, sub.NotifyOwnISAs)
+}
+
+func TestStoreCountsBeyondResultLimit(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewStore().Interact(ctx)
+	require.NoError(t, err)
+
+	n := dssmodels.MaxResultLimit + 1
+	for i := 0; i < n; i++ {
+		isa := newISA("uss1")
+		isa.ID = dssmodels.ID(uuid.New().String())
+		_, err := repo.InsertISA(ctx, isa)
+		require.NoError(t, err)
+		sub := newSubscription("uss1")
+		sub.ID = dssmodels.ID(uuid.New().String())
+		_, err = repo.InsertSubscription(ctx, sub)
+		require.NoError(t, err)
+	}
+
+	count, err := repo.CountISAsByOwner(ctx, "uss1")
+	require.NoError(t, err)
+	require.Equal(t, n, count)
+	count, err = repo.CountSubscriptionsByOwner(ctx, "uss1")
+	require.NoError(t, err)
+	require.Equal(t, n, count)
+}
+
+func TestStoreRollsBackFailedTransactions(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore()
+
+	err := s.Transact(ctx, func(repo repos.Repository) error {
+		if _, err := repo.InsertISA(ctx, newISA("uss1")); err != nil {
+			return err
+		}
+		return stacktrace.NewError("Failing")
+	})
+	require.Error(t, err)
+
+	repo, err := s.Interact(ctx)
+	require.NoError(t, err)
+	isa, err := repo.GetISA(ctx, newISA("uss1").ID, false)
+	require.NoError(t, err)
+	require.Nil(t, isa)
+	events, err := repo.ListActivity(ctx, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestStoreRollsBackCanceledTransactions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewStore()
+
+	err := s.Transact(ctx, func(repo repos.Repository) error {
+		if _, err := repo.InsertISA(ctx, newISA("uss1")); err != nil {
+			return err
+		}
+		cancel()
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	err = s.Transact(ctx, func(repos.Repository) error {
+		t.Fatal("Transaction begun after its context is done")
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	_, err = s.Interact(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	repo, err := s.Interact(context.Background())
+	require.NoError(t, err)
+	isa, err := repo.GetISA(context.Background(), newISA("uss1").ID, false)
+	require.NoError(t, err)
+	require.Nil(t, isa)
+}
+
+func TestOpenMemoryStore(t *testing.T) {
+	s, err := store.Open(context.Background(), "memory://", zap.NewNop())
+	require.NoError(t, err)
+	require.IsType(t, &Store{}, s)
+}
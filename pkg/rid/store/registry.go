@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/interuss/stacktrace"
+	"go.uber.org/zap"
+)
+
+// Driver opens the Store located by uri, whose scheme is one the driver is
+// registered for.
+type Driver func(ctx context.Context, uri *url.URL, logger *zap.Logger) (Store, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]Driver{}
+)
+
+// Register makes driver open the Stores of URIs of scheme. Like
+// database/sql drivers, backends register themselves in the init function of
+// their package, so that importing the package makes them available. It
+// panics if driver is nil or a driver is already registered for scheme.
+func Register(scheme string, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if driver == nil {
+		panic("store: Register driver is nil")
+	}
+	if _, dup := drivers[scheme]; dup {
+		panic("store: Register called twice for scheme " + scheme)
+	}
+	drivers[scheme] = driver
+}
+
+// Schemes returns the sorted URI schemes for which drivers are registered.
+func Schemes() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	schemes := make([]string, 0, len(drivers))
+	for scheme := range drivers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Open returns the Store located by uri, opened by the driver registered for
+// its scheme.
+func Open(ctx context.Context, uri string, logger *zap.Logger) (Store, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		// The URI may hold credentials, so it is not repeated.
+		return nil, stacktrace.NewError("Invalid store URI")
+	}
+	driversMu.RLock()
+	driver, ok := drivers[u.Scheme]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, stacktrace.NewError("No store driver registered for scheme `%s`; expected one of %v", u.Scheme, Schemes())
+	}
+	s, err := driver(ctx, u, logger)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Failed to open %s store", u.Scheme)
+	}
+	return s, nil
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/interuss/stacktrace"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRegistry(t *testing.T) {
+	var opened *url.URL
+	Register("test", func(ctx context.Context, uri *url.URL, logger *zap.Logger) (Store, error) {
+		opened = uri
+		return nil, nil
+	})
+	Register("failing", func(ctx context.Context, uri *url.URL, logger *zap.Logger) (Store, error) {
+		return nil, stacktrace.NewError("Unreachable")
+	})
+	require.Contains(t, Schemes(), "test")
+	require.Panics(t, func() {
+		Register("test", func(ctx context.Context, uri *url.URL, logger *zap.Logger) (Store, error) { return nil, nil })
+	})
+	require.Panics(t, func() { Register("nil", nil) })
+
+	_, err := Open(context.Background(), "test://host/db?option=1", zap.NewNop())
+	require.NoError(t, err)
+	require.Equal(t, "host", opened.Host)
+	require.Equal(t, "/db", opened.Path)
+
+	_, err = Open(context.Background(), "failing://host/db", zap.NewNop())
+	require.Error(t, err)
+	_, err = Open(context.Background(), "spanner://host/db", zap.NewNop())
+	require.Error(t, err)
+	_, err = Open(context.Background(), "://", zap.NewNop())
+	require.Error(t, err)
+}
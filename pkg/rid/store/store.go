@@ -26,6 +26,13 @@ type Interactor interface {
 	Interact(context.Context) (repos.Repository, error)
 }
 
+// PrimaryInteractor is implemented by the stores which may route the queries
+// of Interact elsewhere than the primary datastore, e.g. to read replicas.
+type PrimaryInteractor interface {
+	// InteractPrimary is like Interact but always uses the primary datastore.
+	InteractPrimary(context.Context) (repos.Repository, error)
+}
+
 // Transactor provides means to get hold of a repos.Repository instance in the context
 // of a transaction, thus guaranteeing isolation/atomicity.
 type Transactor interface {
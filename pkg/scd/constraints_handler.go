@@ -2,11 +2,13 @@ package scd
 
 import (
 	"context"
+	"errors"
 
 	"github.com/golang/geo/s2"
 	"github.com/interuss/dss/pkg/api"
 	restapi "github.com/interuss/dss/pkg/api/scdv1"
 	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/limits"
 	dssmodels "github.com/interuss/dss/pkg/models"
 	scdmodels "github.com/interuss/dss/pkg/scd/models"
 	"github.com/interuss/dss/pkg/scd/repos"
@@ -49,7 +51,7 @@ func (a *Server) DeleteConstraintReference(ctx context.Context, req *restapi.Del
 		// Make sure deletion request is valid
 		old, err := r.GetConstraint(ctx, id)
 		switch {
-		case err == pgx.ErrNoRows:
+		case errors.Is(err, pgx.ErrNoRows):
 			return stacktrace.NewErrorWithCode(dsserr.NotFound, "Constraint %s not found", id.String())
 		case err != nil:
 			return stacktrace.Propagate(err, "Unable to get Constraint from repo")
@@ -62,7 +64,8 @@ func (a *Server) DeleteConstraintReference(ctx context.Context, req *restapi.Del
 		}
 
 		// Find Subscriptions that may overlap the Constraint's Volume4D
-		allsubs, err := r.SearchSubscriptions(ctx, &dssmodels.Volume4D{
+		var subs repos.Subscriptions
+		subs, err = r.SearchSubscriptionsToNotify(ctx, &dssmodels.Volume4D{
 			StartTime: old.StartTime,
 			EndTime:   old.EndTime,
 			SpatialVolume: &dssmodels.Volume3D{
@@ -71,19 +74,11 @@ func (a *Server) DeleteConstraintReference(ctx context.Context, req *restapi.Del
 				Footprint: dssmodels.GeometryFunc(func() (s2.CellUnion, error) {
 					return old.Cells, nil
 				}),
-			}})
+			}}, scdmodels.EntityTypeConstraint)
 		if err != nil {
 			return stacktrace.Propagate(err, "Unable to search Subscriptions in repo")
 		}
 
-		// Limit Subscription notifications to only those interested in Constraints
-		subs := repos.Subscriptions{}
-		for _, sub := range allsubs {
-			if sub.NotifyForConstraints {
-				subs = append(subs, sub)
-			}
-		}
-
 		// Delete Constraint in repo
 		err = r.DeleteConstraint(ctx, id)
 		if err != nil {
@@ -151,7 +146,7 @@ func (a *Server) GetConstraintReference(ctx context.Context, req *restapi.GetCon
 	action := func(ctx context.Context, r repos.Repository) (err error) {
 		constraint, err := r.GetConstraint(ctx, id)
 		switch {
-		case err == pgx.ErrNoRows:
+		case errors.Is(err, pgx.ErrNoRows):
 			return stacktrace.NewErrorWithCode(dsserr.NotFound, "Constraint %s not found", id.String())
 		case err != nil:
 			return stacktrace.Propagate(err, "Unable to get Constraint from repo")
@@ -311,7 +306,7 @@ func (a *Server) PutConstraintReference(ctx context.Context, manager string, ent
 		// Get existing Constraint, if any, and validate request
 		old, err := r.GetConstraint(ctx, id)
 		switch {
-		case err == pgx.ErrNoRows:
+		case errors.Is(err, pgx.ErrNoRows):
 			// No existing Constraint; verify that creation was requested
 			if ovn != "" {
 				return stacktrace.NewErrorWithCode(dsserr.VersionMismatch, "Old version %s does not exist", ovn)
@@ -372,19 +367,12 @@ func (a *Server) PutConstraintReference(ctx context.Context, manager string, ent
 		}
 
 		// Find Subscriptions that may need to be notified
-		allsubs, err := r.SearchSubscriptions(ctx, notifyVol4)
+		var subs repos.Subscriptions
+		subs, err = r.SearchSubscriptionsToNotify(ctx, notifyVol4, scdmodels.EntityTypeConstraint)
 		if err != nil {
 			return err
 		}
 
-		// Limit Subscription notifications to only those interested in Constraints
-		subs := repos.Subscriptions{}
-		for _, sub := range allsubs {
-			if sub.NotifyForConstraints {
-				subs = append(subs, sub)
-			}
-		}
-
 		// Increment notification indices for relevant Subscriptions
 		err = subs.IncrementNotificationIndices(ctx, r)
 		if err != nil {
@@ -444,6 +432,10 @@ func (a *Server) QueryConstraintReferences(ctx context.Context, req *restapi.Que
 		if err != nil {
 			return err
 		}
+		constraints, err = limits.Apply(ctx, constraints)
+		if err != nil {
+			return stacktrace.Propagate(err, "Too many Constraints found")
+		}
 
 		// Create response for client
 		response = &restapi.QueryConstraintReferencesResponse{
@@ -463,6 +455,10 @@ func (a *Server) QueryConstraintReferences(ctx context.Context, req *restapi.Que
 
 	err = a.Store.Transact(ctx, action)
 	if err != nil {
+		if stacktrace.GetCode(err) == dsserr.AreaTooLarge {
+			return restapi.QueryConstraintReferencesResponseSet{Response413: &restapi.ErrorResponse{
+				Message: dsserr.Handle(ctx, err)}}
+		}
 		return restapi.QueryConstraintReferencesResponseSet{Response500: &api.InternalServerErrorBody{
 			ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Got an unexpected error"))}}
 	}
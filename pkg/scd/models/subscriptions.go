@@ -17,6 +17,25 @@ const (
 	maxSubscriptionDuration = time.Hour * 24
 )
 
+// EntityType identifies a type of entity whose changes Subscriptions may be
+// notified of.
+type EntityType string
+
+const (
+	// EntityTypeOperationalIntent designates operational intents, notified to
+	// Subscriptions with NotifyForOperationalIntents.
+	EntityTypeOperationalIntent EntityType = "OperationalIntent"
+	// EntityTypeConstraint designates constraints, notified to Subscriptions
+	// with NotifyForConstraints.
+	EntityTypeConstraint EntityType = "Constraint"
+)
+
+// MaxSubscriptionDuration returns the largest allowed interval between the
+// StartTime and EndTime of a Subscription.
+func MaxSubscriptionDuration() time.Duration {
+	return maxSubscriptionDuration
+}
+
 // Subscription represents an SCD subscription
 type Subscription struct {
 	ID dssmodels.ID
@@ -10,6 +10,7 @@ import (
 	restapi "github.com/interuss/dss/pkg/api/scdv1"
 	"github.com/interuss/dss/pkg/auth"
 	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/dss/pkg/limits"
 	dssmodels "github.com/interuss/dss/pkg/models"
 	scdmodels "github.com/interuss/dss/pkg/scd/models"
 	"github.com/interuss/dss/pkg/scd/repos"
@@ -279,6 +280,10 @@ func (a *Server) QueryOperationalIntentReferences(ctx context.Context, req *rest
 		if err != nil {
 			return stacktrace.Propagate(err, "Unable to query for OperationalIntents in repo")
 		}
+		ops, err = limits.Apply(ctx, ops)
+		if err != nil {
+			return stacktrace.Propagate(err, "Too many OperationalIntents found")
+		}
 
 		// Create response for client
 		response = &restapi.QueryOperationalIntentReferenceResponse{
@@ -299,8 +304,11 @@ func (a *Server) QueryOperationalIntentReferences(ctx context.Context, req *rest
 	err = a.Store.Transact(ctx, action)
 	if err != nil {
 		err = stacktrace.Propagate(err, "Could not query operational intent")
-		if stacktrace.GetCode(err) == dsserr.BadRequest {
+		switch stacktrace.GetCode(err) {
+		case dsserr.BadRequest:
 			return restapi.QueryOperationalIntentReferencesResponseSet{Response400: &restapi.ErrorResponse{Message: dsserr.Handle(ctx, err)}}
+		case dsserr.AreaTooLarge:
+			return restapi.QueryOperationalIntentReferencesResponseSet{Response413: &restapi.ErrorResponse{Message: dsserr.Handle(ctx, err)}}
 		}
 		return restapi.QueryOperationalIntentReferencesResponseSet{Response500: &api.InternalServerErrorBody{
 			ErrorMessage: *dsserr.Handle(ctx, stacktrace.Propagate(err, "Got an unexpected error"))}}
@@ -670,19 +678,12 @@ func getRelevantSubscriptionsAndIncrementIndices(
 ) (repos.Subscriptions, error) {
 
 	// Find Subscriptions that may need to be notified
-	allsubs, err := r.SearchSubscriptions(ctx, notifyVolume)
+	var subs repos.Subscriptions
+	subs, err := r.SearchSubscriptionsToNotify(ctx, notifyVolume, scdmodels.EntityTypeOperationalIntent)
 	if err != nil {
 		return nil, stacktrace.Propagate(err, "Failed to search for impacted subscriptions.")
 	}
 
-	// Limit Subscription notifications to only those interested in OperationalIntents
-	subs := repos.Subscriptions{}
-	for _, sub := range allsubs {
-		if sub.NotifyForOperationalIntents {
-			subs = append(subs, sub)
-		}
-	}
-
 	// Increment notification indices for relevant Subscriptions
 	if err := subs.IncrementNotificationIndices(ctx, r); err != nil {
 		return nil, stacktrace.Propagate(err, "Failed to increment notification indices of relevant subscriptions")
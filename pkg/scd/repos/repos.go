@@ -40,6 +40,10 @@ type Subscription interface {
 	// SearchSubscriptions returns all Subscriptions in "v4d".
 	SearchSubscriptions(ctx context.Context, v4d *dssmodels.Volume4D) ([]*scdmodels.Subscription, error)
 
+	// SearchSubscriptionsToNotify returns the Subscriptions in "v4d" that
+	// must be notified of changes to entities of type "entityType".
+	SearchSubscriptionsToNotify(ctx context.Context, v4d *dssmodels.Volume4D, entityType scdmodels.EntityType) ([]*scdmodels.Subscription, error)
+
 	// GetSubscription returns the Subscription referenced by id, or nil and no
 	// error if the Subscription doesn't exist
 	GetSubscription(ctx context.Context, id dssmodels.ID) (*scdmodels.Subscription, error)
@@ -77,8 +81,9 @@ type Constraint interface {
 	// SearchConstraints returns all Constraints in "v4d".
 	SearchConstraints(ctx context.Context, v4d *dssmodels.Volume4D) ([]*scdmodels.Constraint, error)
 
-	// GetConstraint returns the Constraint referenced by id, or
-	// (nil, sql.ErrNoRows) if the Constraint doesn't exist
+	// GetConstraint returns the Constraint referenced by id, or a
+	// dsserr.NotFound error wrapping pgx.ErrNoRows if the Constraint doesn't
+	// exist
 	GetConstraint(ctx context.Context, id dssmodels.ID) (*scdmodels.Constraint, error)
 
 	// UpsertConstraint upserts "constraint" into the store.
@@ -3,6 +3,7 @@ package cockroach
 import (
 	"context"
 	"fmt"
+	dsserr "github.com/interuss/dss/pkg/errors"
 	dssmodels "github.com/interuss/dss/pkg/models"
 	scdmodels "github.com/interuss/dss/pkg/scd/models"
 	dsssql "github.com/interuss/dss/pkg/sql"
@@ -46,7 +47,7 @@ func (u *repo) UpsertUssAvailability(ctx context.Context, s *scdmodels.UssAvaila
 		scd_uss_availability
 		  (%s)
 		VALUES
-			($1, $2, transaction_timestamp())
+			($1, $2, DEFAULT)
 		RETURNING
 			%s`, availabilityFieldsWithoutPrefix, availabilityFieldsWithPrefix)
 	)
@@ -99,7 +100,7 @@ func (u *repo) fetchAvailability(ctx context.Context, q dsssql.Queryable, query
 		return nil, stacktrace.NewError("Query returned %d availabilities when only 0 or 1 was expected", len(availabilities))
 	}
 	if len(availabilities) == 0 {
-		return nil, pgx.ErrNoRows
+		return nil, stacktrace.PropagateWithCode(pgx.ErrNoRows, dsserr.NotFound, "USS availability not found")
 	}
 	return availabilities[0], nil
 }
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	dsserr "github.com/interuss/dss/pkg/errors"
 	"github.com/interuss/dss/pkg/geo"
 	dssmodels "github.com/interuss/dss/pkg/models"
 	scdmodels "github.com/interuss/dss/pkg/scd/models"
@@ -100,7 +101,7 @@ func (c *repo) fetchConstraint(ctx context.Context, q dsssql.Queryable, query st
 		return nil, stacktrace.NewError("Query returned %d Constraints when only 0 or 1 was expected", len(constraints))
 	}
 	if len(constraints) == 0 {
-		return nil, pgx.ErrNoRows
+		return nil, stacktrace.PropagateWithCode(pgx.ErrNoRows, dsserr.NotFound, "Constraint not found")
 	}
 	return constraints[0], nil
 }
@@ -131,7 +132,7 @@ func (c *repo) UpsertConstraint(ctx context.Context, s *scdmodels.Constraint) (*
 		  scd_constraints
 		  (%s)
 		VALUES
-			($1, $2, $3, $4, $5, $6, $7, $8, $9, transaction_timestamp())
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, DEFAULT)
 		RETURNING
 			%s`, constraintFieldsWithoutPrefix, constraintFieldsWithPrefix)
 	)
@@ -183,7 +184,7 @@ func (c *repo) DeleteConstraint(ctx context.Context, id dssmodels.ID) error {
 	}
 
 	if res.RowsAffected() == 0 {
-		return pgx.ErrNoRows
+		return stacktrace.PropagateWithCode(pgx.ErrNoRows, dsserr.NotFound, "Constraint not found")
 	}
 
 	return nil
@@ -198,12 +199,12 @@ func (c *repo) SearchConstraints(ctx context.Context, v4d *dssmodels.Volume4D) (
 			FROM
 				scd_constraints
 			WHERE
-			  cells && $1
+			  %s
 			AND
 				COALESCE(starts_at <= $3, true)
 			AND
 				COALESCE(ends_at >= $2, true)
-			LIMIT $4`, constraintFieldsWithoutPrefix)
+			LIMIT $4`, constraintFieldsWithoutPrefix, dsssql.CellsIntersect("cells", "$1"))
 	)
 
 	// TODO: Lazily calculate & cache spatial covering so that it is only ever
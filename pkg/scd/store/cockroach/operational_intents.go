@@ -200,19 +200,13 @@ func (s *repo) UpsertOperationalIntent(ctx context.Context, operation *scdmodels
 				scd_operations
 				(%s)
 			VALUES
-				($1, $2, $3, $4, $5, $6, $7, $8, $9, transaction_timestamp(), $10, $11, $12, $13)
+				($1, $2, $3, $4, $5, $6, $7, $8, $9, DEFAULT, $10, $11, $12, $13)
 			RETURNING
 				%s`, operationFieldsWithoutPrefix, operationFieldsWithPrefix)
 	)
 
-	cids := make([]int64, len(operation.Cells))
-	// TODO clevels not used, can get rid of it?
-	clevels := make([]int, len(operation.Cells))
-
-	for i, cell := range operation.Cells {
-		cids[i] = int64(cell)
-		clevels[i] = cell.Level()
-	}
+	// All cells are written at once as a single array parameter.
+	cids := dsssql.CellUnionToCellIds(operation.Cells)
 
 	opid, err := operation.ID.PgUUID()
 	if err != nil {
@@ -266,7 +260,7 @@ func (s *repo) searchOperationalIntents(ctx context.Context, q dsssql.Queryable,
 			FROM
 				scd_operations
 			WHERE
-				cells && $1
+				%s
 			AND
 				COALESCE(scd_operations.altitude_upper >= $2, true)
 			AND
@@ -275,7 +269,7 @@ func (s *repo) searchOperationalIntents(ctx context.Context, q dsssql.Queryable,
 				COALESCE(scd_operations.ends_at >= $4, true)
 			AND
 				COALESCE(scd_operations.starts_at <= $5, true)
-			LIMIT $6`, operationFieldsWithPrefix)
+			LIMIT $6`, operationFieldsWithPrefix, dsssql.CellsIntersect("cells", "$1"))
 	)
 
 	if v4d.SpatialVolume == nil || v4d.SpatialVolume.Footprint == nil {
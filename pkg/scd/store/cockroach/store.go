@@ -4,7 +4,6 @@ import (
 	"context"
 
 	"github.com/cockroachdb/cockroach-go/v2/crdb"
-	"github.com/cockroachdb/cockroach-go/v2/crdb/crdbpgxv5"
 	"github.com/coreos/go-semver/semver"
 	"github.com/interuss/dss/pkg/datastore"
 	"github.com/interuss/dss/pkg/datastore/flags"
@@ -21,10 +20,19 @@ const (
 )
 
 var (
+	// minimumSchemaVersion is the oldest schema version supported by the
+	// store, which relies on the column defaults introduced in it.
+	minimumSchemaVersion = semver.New("3.3.0")
+
+	// TargetSchemaVersion is the schema version of the latest migration, which
+	// the store is written for and db-manager applies with --db_version latest.
+	TargetSchemaVersion = semver.New("3.3.0")
+
 	// DefaultClock is what is used as the Store's clock, returned from Dial.
 	DefaultClock = clockwork.NewRealClock()
 
-	// DatabaseName is the name of database storing strategic conflict detection data.
+	// DatabaseName is the default name of the database storing strategic
+	// conflict detection data.
 	DatabaseName = "scd"
 )
 
@@ -70,26 +78,54 @@ func (s *Store) CheckCurrentMajorSchemaVersion(ctx context.Context) error {
 		return stacktrace.NewError("Unsupported schema version for strategic conflict detection! Got %s, requires major version of %d. Please check https://github.com/interuss/dss/tree/master/build#upgrading-database-schemas", vs, currentMajorSchemaVersion)
 	}
 
+	if vs.LessThan(*minimumSchemaVersion) {
+		return stacktrace.NewError("Unsupported schema version for strategic conflict detection! Got %s, requires at least %s. Please check https://github.com/interuss/dss/tree/master/build#upgrading-database-schemas", vs, minimumSchemaVersion)
+	}
+
+	return nil
+}
+
+// CheckReady returns nil if the datastore can serve queries and its schema
+// version is supported, e.g. to answer readiness probes.
+func (s *Store) CheckReady(ctx context.Context) error {
+	if err := s.db.Pool.Ping(ctx); err != nil {
+		return stacktrace.Propagate(err, "Strategic conflict detection database is unreachable")
+	}
+	return s.CheckCurrentMajorSchemaVersion(ctx)
+}
+
+// CheckTargetSchemaVersion returns nil if the schema version of s is
+// TargetSchemaVersion, i.e. if the database has been migrated to neither an
+// older nor a newer version than the one the store is written for.
+func (s *Store) CheckTargetSchemaVersion(ctx context.Context) error {
+	vs, err := s.GetVersion(ctx)
+	if err != nil {
+		return stacktrace.Propagate(err, "Failed to get database schema version for strategic conflict detection")
+	}
+	if !vs.Equal(*TargetSchemaVersion) {
+		return stacktrace.NewError("Schema version for strategic conflict detection is %s instead of %s; migrate the database with db-manager. Please check https://github.com/interuss/dss/tree/master/build#upgrading-database-schemas", vs, TargetSchemaVersion)
+	}
 	return nil
 }
 
 // Interact implements store.Interactor interface.
 func (s *Store) Interact(_ context.Context) (repos.Repository, error) {
 	return &repo{
-		q:     s.db.Pool,
+		q:     dsssql.WithErrorTranslation(s.db.Pool),
 		clock: s.clock,
 	}, nil
 }
 
-// Transact implements store.Transactor interface.
+// Transact implements store.Transactor interface. The transaction is rolled
+// back if ctx is done before f returns.
 func (s *Store) Transact(ctx context.Context, f func(context.Context, repos.Repository) error) error {
 	ctx = crdb.WithMaxRetries(ctx, flags.ConnectParameters().MaxRetries)
-	return crdbpgx.ExecuteTx(ctx, s.db.Pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
+	return dsssql.TranslateError(dsssql.ExecuteTx(ctx, s.db.Pool, pgx.TxOptions{}, func(tx pgx.Tx) error {
 		return f(ctx, &repo{
-			q:     tx,
+			q:     dsssql.WithErrorTranslation(tx),
 			clock: s.clock,
 		})
-	})
+	}))
 }
 
 // Close closes the underlying DB connection.
@@ -101,5 +137,5 @@ func (s *Store) Close() error {
 // GetVersion returns the Version string for the Database.
 // If the DB was is not bootstrapped using the schema manager we throw and error
 func (s *Store) GetVersion(ctx context.Context) (*semver.Version, error) {
-	return s.db.GetSchemaVersion(ctx, DatabaseName)
+	return s.db.GetSchemaVersion(ctx, s.db.Pool.Config().ConnConfig.Database)
 }
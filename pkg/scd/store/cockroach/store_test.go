@@ -2,10 +2,13 @@ package cockroach
 
 import (
 	"context"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/coreos/go-semver/semver"
 	"github.com/interuss/dss/pkg/datastore"
-	"github.com/interuss/dss/pkg/datastore/flags"
+	"github.com/interuss/dss/pkg/datastore/testdb"
 	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/require"
 )
@@ -14,11 +17,28 @@ var (
 	fakeClock = clockwork.NewFakeClock()
 )
 
-func setUpStore(ctx context.Context, t *testing.T) (*Store, func()) {
-	connectParameters := flags.ConnectParameters()
-	if connectParameters.Host == "" || connectParameters.Port == 0 {
-		t.Skip()
+// TestTargetSchemaVersionIsLatest verifies that TargetSchemaVersion follows
+// the migrations applied by db-manager.
+func TestTargetSchemaVersionIsLatest(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "..", "..", "..", "build", "db_schemas", "scd", "upto-v*.sql"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	latest := semver.New("0.0.0")
+	for _, f := range files {
+		v := semver.New(strings.SplitN(strings.TrimPrefix(filepath.Base(f), "upto-v"), "-", 2)[0])
+		if latest.LessThan(*v) {
+			latest = v
+		}
 	}
+	require.Equal(t, *latest, *TargetSchemaVersion)
+}
+
+func TestMain(m *testing.M) {
+	testdb.Main(m)
+}
+
+func setUpStore(ctx context.Context, t testing.TB) (*Store, func()) {
+	connectParameters := testdb.ConnectParameters(t, DatabaseName)
 	// Reset the clock for every test.
 	fakeClock = clockwork.NewFakeClock()
 
@@ -30,7 +50,7 @@ func setUpStore(ctx context.Context, t *testing.T) (*Store, func()) {
 	}
 }
 
-func newStore(ctx context.Context, t *testing.T, connectParameters datastore.ConnectParameters) (*Store, error) {
+func newStore(ctx context.Context, t testing.TB, connectParameters datastore.ConnectParameters) (*Store, error) {
 	db, err := datastore.Dial(ctx, connectParameters)
 	require.NoError(t, err)
 
@@ -191,19 +191,13 @@ func (c *repo) pushSubscription(ctx context.Context, q dsssql.Queryable, s *scdm
 		  scd_subscriptions
 		  (%s)
 		VALUES
-			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, transaction_timestamp())
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, DEFAULT)
 		RETURNING
 			%s`, subscriptionFieldsWithoutPrefix, subscriptionFieldsWithPrefix)
 	)
 
-	cids := make([]int64, len(s.Cells))
-	// TODO get rid of clevels?
-	clevels := make([]int, len(s.Cells))
-
-	for i, cell := range s.Cells {
-		cids[i] = int64(cell)
-		clevels[i] = cell.Level()
-	}
+	// All cells are written at once as a single array parameter.
+	cids := dsssql.CellUnionToCellIds(s.Cells)
 
 	id, err := s.ID.PgUUID()
 	if err != nil {
@@ -282,6 +276,23 @@ func (c *repo) DeleteSubscription(ctx context.Context, id dssmodels.ID) error {
 
 // Implements SubscriptionStore.SearchSubscriptions
 func (c *repo) SearchSubscriptions(ctx context.Context, v4d *dssmodels.Volume4D) ([]*scdmodels.Subscription, error) {
+	return c.searchSubscriptions(ctx, v4d, "true")
+}
+
+// Implements SubscriptionStore.SearchSubscriptionsToNotify
+func (c *repo) SearchSubscriptionsToNotify(ctx context.Context, v4d *dssmodels.Volume4D, entityType scdmodels.EntityType) ([]*scdmodels.Subscription, error) {
+	switch entityType {
+	case scdmodels.EntityTypeOperationalIntent:
+		return c.searchSubscriptions(ctx, v4d, "notify_for_operations")
+	case scdmodels.EntityTypeConstraint:
+		return c.searchSubscriptions(ctx, v4d, "notify_for_constraints")
+	default:
+		return nil, stacktrace.NewError("Unknown entity type %s", entityType)
+	}
+}
+
+// searchSubscriptions returns the Subscriptions in "v4d" meeting "condition".
+func (c *repo) searchSubscriptions(ctx context.Context, v4d *dssmodels.Volume4D, condition string) ([]*scdmodels.Subscription, error) {
 	var (
 		query = fmt.Sprintf(`
 			SELECT
@@ -289,12 +300,14 @@ func (c *repo) SearchSubscriptions(ctx context.Context, v4d *dssmodels.Volume4D)
 			FROM
 				scd_subscriptions
 				WHERE
-					cells && $1
+					%s
+				AND
+					%s
 				AND
 					COALESCE(starts_at <= $3, true)
 				AND
 					COALESCE(ends_at >= $2, true)
-				LIMIT $4`, subscriptionFieldsWithPrefix)
+				LIMIT $4`, subscriptionFieldsWithPrefix, dsssql.CellsIntersect("cells", "$1"), condition)
 	)
 
 	// TODO: Lazily calculate & cache spatial covering so that it is only ever
@@ -360,15 +373,15 @@ func (c *repo) IncrementNotificationIndices(ctx context.Context, subscriptionIds
 
 func (c *repo) LockSubscriptionsOnCells(ctx context.Context, cells s2.CellUnion) error {
 
-	const query = `
+	var query = fmt.Sprintf(`
 		SELECT
 			id
 		FROM
 			scd_subscriptions
 		WHERE
-			cells && $1
+			%s
 		FOR UPDATE
-	`
+	`, dsssql.CellsIntersect("cells", "$1"))
 
 	_, err := c.q.Exec(ctx, query, dsssql.CellUnionToCellIds(cells))
 	if err != nil {
@@ -2,9 +2,11 @@ package cockroach
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/golang/geo/s2"
 	"github.com/interuss/dss/pkg/models"
 	scdmodels "github.com/interuss/dss/pkg/scd/models"
 	"github.com/stretchr/testify/require"
@@ -115,3 +117,76 @@ func TestListExpiredSubscriptions(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchSubscriptionsToNotify(t *testing.T) {
+	var (
+		ctx                  = context.Background()
+		store, tearDownStore = setUpStore(ctx, t)
+	)
+	require.NotNil(t, store)
+	defer tearDownStore()
+
+	r, err := store.Interact(ctx)
+	require.NoError(t, err)
+
+	forConstraints := *sub2
+	forConstraints.NotifyForOperationalIntents = false
+	forConstraints.NotifyForConstraints = true
+	for _, sub := range []*scdmodels.Subscription{sub1, &forConstraints} {
+		_, err = r.UpsertSubscription(ctx, sub)
+		require.NoError(t, err)
+	}
+
+	v4d := &models.Volume4D{
+		StartTime: &start1,
+		EndTime:   &end2,
+		SpatialVolume: &models.Volume3D{
+			Footprint: models.GeometryFunc(func() (s2.CellUnion, error) {
+				return cells, nil
+			}),
+		},
+	}
+	for entityType, expected := range map[scdmodels.EntityType]models.ID{
+		scdmodels.EntityTypeOperationalIntent: sub1ID,
+		scdmodels.EntityTypeConstraint:        sub2ID,
+	} {
+		subs, err := r.SearchSubscriptionsToNotify(ctx, v4d, entityType)
+		require.NoError(t, err)
+		require.Len(t, subs, 1, entityType)
+		require.Equal(t, expected, subs[0].ID, entityType)
+	}
+
+	_, err = r.SearchSubscriptionsToNotify(ctx, v4d, "Flight")
+	require.Error(t, err)
+}
+
+// benchmarkCells returns n distinct level 13 cells.
+func benchmarkCells(n int) s2.CellUnion {
+	cells := make(s2.CellUnion, n)
+	cell := s2.CellIDFromLatLng(s2.LatLngFromDegrees(37.4, -122.1)).Parent(13)
+	for i := range cells {
+		cells[i] = cell
+		cell = cell.Next()
+	}
+	return cells
+}
+
+func BenchmarkUpsertSubscription(b *testing.B) {
+	ctx := context.Background()
+	store, tearDownStore := setUpStore(ctx, b)
+	defer tearDownStore()
+
+	repo, err := store.Interact(ctx)
+	require.NoError(b, err)
+
+	for _, n := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("%d cells", n), func(b *testing.B) {
+			sub := *sub1
+			sub.Cells = benchmarkCells(n)
+			for i := 0; i < b.N; i++ {
+				_, err := repo.UpsertSubscription(ctx, &sub)
+				require.NoError(b, err)
+			}
+		})
+	}
+}
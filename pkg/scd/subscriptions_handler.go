@@ -9,6 +9,7 @@ import (
 	restapi "github.com/interuss/dss/pkg/api/scdv1"
 	dsserr "github.com/interuss/dss/pkg/errors"
 	"github.com/interuss/dss/pkg/geo"
+	"github.com/interuss/dss/pkg/limits"
 	dssmodels "github.com/interuss/dss/pkg/models"
 	scdmodels "github.com/interuss/dss/pkg/scd/models"
 	"github.com/interuss/dss/pkg/scd/repos"
@@ -438,6 +439,10 @@ func (a *Server) QuerySubscriptions(ctx context.Context, req *restapi.QuerySubsc
 				response.Subscriptions = append(response.Subscriptions, *p)
 			}
 		}
+		response.Subscriptions, err = limits.Apply(ctx, response.Subscriptions)
+		if err != nil {
+			return stacktrace.Propagate(err, "Too many Subscriptions found")
+		}
 
 		return nil
 	}
@@ -2,6 +2,7 @@ package scd
 
 import (
 	"context"
+	"errors"
 	"strings"
 
 	"github.com/interuss/dss/pkg/api"
@@ -41,7 +42,7 @@ func (a *Server) GetUssAvailability(ctx context.Context, req *restapi.GetUssAvai
 	action := func(ctx context.Context, r repos.Repository) (err error) {
 		// Get USS availability from Store
 		ussa, err := r.GetUssAvailability(ctx, id)
-		if err != nil && err != pgx.ErrNoRows {
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 			return stacktrace.Propagate(err, "Could not get USS availability from repo")
 		}
 		if ussa == nil {
@@ -0,0 +1,7 @@
+// Package signing signs the responses of the DSS and verifies their
+// signatures, so that a client can prove which response the DSS returned to
+// which request at which time. Each response carries a detached JWS of its
+// body, as sent before compression, whose protected header binds the method
+// and target of the request, the status of the response and the time at
+// which it was signed.
+package signing
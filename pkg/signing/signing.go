@@ -0,0 +1,229 @@
+package signing
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/interuss/dss/pkg/api"
+	"github.com/interuss/stacktrace"
+	"github.com/jonboulle/clockwork"
+)
+
+// Header is the response header carrying the detached JWS, in compact
+// serialization with an empty payload, of the response body.
+const Header = "DSS-Signature"
+
+// Protected header parameters binding a signed response to its request.
+const (
+	// IssuedAtParam is the time, in seconds since the epoch, at which the
+	// response was signed.
+	IssuedAtParam = "iat"
+	// MethodParam is the HTTP method of the request, as in DPoP proofs.
+	MethodParam = "htm"
+	// TargetParam is the path and query of the request, the host varying
+	// behind load balancers.
+	TargetParam = "htu"
+	// StatusParam is the HTTP status of the response.
+	StatusParam = "status"
+)
+
+// algorithms are the signature algorithms of the keys accepted, with which
+// signatures are verified.
+var algorithms = []jose.SignatureAlgorithm{jose.ES256, jose.ES384, jose.ES512, jose.RS256, jose.EdDSA}
+
+// Signer signs responses with a private key.
+type Signer struct {
+	key   jose.SigningKey
+	clock clockwork.Clock
+}
+
+// NewSigner returns a Signer signing with key, an ECDSA, RSA or Ed25519
+// private key, identifying it with keyID in signatures if not empty.
+func NewSigner(key crypto.Signer, keyID string) (*Signer, error) {
+	alg, err := algorithm(key.Public())
+	if err != nil {
+		return nil, err // No need to Propagate this error as this stack layer does not add useful information
+	}
+	return &Signer{
+		key:   jose.SigningKey{Algorithm: alg, Key: jose.JSONWebKey{Key: key, KeyID: keyID}},
+		clock: clockwork.NewRealClock(),
+	}, nil
+}
+
+// ParsePrivateKey parses a PEM-encoded PKCS #8, SEC 1 or PKCS #1 private key.
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, stacktrace.NewError("No PEM block found")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, stacktrace.NewError("Unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, stacktrace.NewError("Unsupported private key in PEM block %s", block.Type)
+}
+
+// ParsePublicKey parses a PEM-encoded PKIX public key or certificate.
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, stacktrace.NewError("No PEM block found")
+	}
+	if block.Type == "CERTIFICATE" {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, stacktrace.Propagate(err, "Error parsing certificate")
+		}
+		return cert.PublicKey, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, stacktrace.Propagate(err, "Error parsing public key")
+	}
+	return key, nil
+}
+
+// algorithm returns the signature algorithm of key.
+func algorithm(key crypto.PublicKey) (jose.SignatureAlgorithm, error) {
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jose.ES256, nil
+		case elliptic.P384():
+			return jose.ES384, nil
+		case elliptic.P521():
+			return jose.ES512, nil
+		}
+		return "", stacktrace.NewError("Unsupported elliptic curve %s", k.Curve.Params().Name)
+	case *rsa.PublicKey:
+		if k.N.BitLen() < 2048 {
+			return "", stacktrace.NewError("RSA keys must be at least 2048 bits, got %d", k.N.BitLen())
+		}
+		return jose.RS256, nil
+	case ed25519.PublicKey:
+		return jose.EdDSA, nil
+	default:
+		return "", stacktrace.NewError("Unsupported key type %T", key)
+	}
+}
+
+// Sign returns the detached JWS of the response to a request of method and
+// target with status and body.
+func (s *Signer) Sign(method, target string, status int, body []byte) (string, error) {
+	opts := (&jose.SignerOptions{}).
+		WithHeader(IssuedAtParam, s.clock.Now().Unix()).
+		WithHeader(MethodParam, method).
+		WithHeader(TargetParam, target).
+		WithHeader(StatusParam, status)
+	signer, err := jose.NewSigner(s.key, opts)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "Error creating signer")
+	}
+	jws, err := signer.Sign(body)
+	if err != nil {
+		return "", stacktrace.Propagate(err, "Error signing response")
+	}
+	signature, err := jws.DetachedCompactSerialize()
+	if err != nil {
+		return "", stacktrace.Propagate(err, "Error serializing signature")
+	}
+	return signature, nil
+}
+
+// Verify verifies that signature, the value of Header, was made with the
+// private key of key over the response to a request of method and target
+// with status and body, and returns the time at which it was signed.
+func Verify(key crypto.PublicKey, signature, method, target string, status int, body []byte) (time.Time, error) {
+	if signature == "" {
+		return time.Time{}, stacktrace.NewError("Missing %s header", Header)
+	}
+	if body == nil {
+		body = []byte{}
+	}
+	jws, err := jose.ParseDetached(signature, body, algorithms)
+	if err != nil {
+		return time.Time{}, stacktrace.Propagate(err, "Error parsing signature")
+	}
+	if err := jws.DetachedVerify(body, key); err != nil {
+		return time.Time{}, stacktrace.Propagate(err, "Invalid signature")
+	}
+	params := jws.Signatures[0].Protected.ExtraHeaders
+	if got, _ := params[MethodParam].(string); got != method {
+		return time.Time{}, stacktrace.NewError("Signature is for a %s request rather than %s", got, method)
+	}
+	if got, _ := params[TargetParam].(string); got != target {
+		return time.Time{}, stacktrace.NewError("Signature is for a request of %s rather than %s", got, target)
+	}
+	if got, _ := params[StatusParam].(float64); int(got) != status {
+		return time.Time{}, stacktrace.NewError("Signature is for a response with status %v rather than %d", params[StatusParam], status)
+	}
+	iat, ok := params[IssuedAtParam].(float64)
+	if !ok {
+		return time.Time{}, stacktrace.NewError("Signature has no %s parameter", IssuedAtParam)
+	}
+	return time.Unix(int64(iat), 0), nil
+}
+
+// Middleware returns an http.Handler signing the responses of next, which it
+// buffers to do so. If s is nil, next is returned.
+func (s *Signer) Middleware(next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{header: w.Header(), status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+		signature, err := s.Sign(r.Method, r.URL.RequestURI(), rw.status, rw.body.Bytes())
+		if err != nil {
+			// Responding unsigned would defeat the purpose of signatures.
+			api.WriteJSON(w, http.StatusInternalServerError, map[string]string{"message": "Unable to sign response"})
+			return
+		}
+		w.Header().Set(Header, signature)
+		w.WriteHeader(rw.status)
+		_, _ = w.Write(rw.body.Bytes())
+	})
+}
+
+// responseWriter buffers a response until it is signed.
+type responseWriter struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.body.Write(b)
+}
@@ -0,0 +1,138 @@
+package signing
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+)
+
+func newSigner(t *testing.T) (*Signer, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	s, err := NewSigner(key, "dss-1")
+	require.NoError(t, err)
+	s.clock = clockwork.NewFakeClockAt(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	return s, key
+}
+
+func TestSignAndVerify(t *testing.T) {
+	s, key := newSigner(t)
+	body := []byte(`{"service_areas":[]}`)
+	target := "/rid/v2/dss/identification_service_areas?area=0,0,0,1,1,1"
+
+	signature, err := s.Sign(http.MethodGet, target, http.StatusOK, body)
+	require.NoError(t, err)
+
+	signedAt, err := Verify(&key.PublicKey, signature, http.MethodGet, target, http.StatusOK, body)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), signedAt.UTC())
+
+	for name, verify := range map[string]func() error{
+		"body": func() error {
+			_, err := Verify(&key.PublicKey, signature, http.MethodGet, target, http.StatusOK, []byte(`{"service_areas":[{}]}`))
+			return err
+		},
+		"method": func() error {
+			_, err := Verify(&key.PublicKey, signature, http.MethodPut, target, http.StatusOK, body)
+			return err
+		},
+		"target": func() error {
+			_, err := Verify(&key.PublicKey, signature, http.MethodGet, "/rid/v2/dss/subscriptions", http.StatusOK, body)
+			return err
+		},
+		"status": func() error {
+			_, err := Verify(&key.PublicKey, signature, http.MethodGet, target, http.StatusNotFound, body)
+			return err
+		},
+		"key": func() error {
+			_, other := newSigner(t)
+			_, err := Verify(&other.PublicKey, signature, http.MethodGet, target, http.StatusOK, body)
+			return err
+		},
+		"missing": func() error {
+			_, err := Verify(&key.PublicKey, "", http.MethodGet, target, http.StatusOK, body)
+			return err
+		},
+	} {
+		require.Error(t, verify(), name)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	s, key := newSigner(t)
+	handler := s.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":`))
+		_, _ = w.Write([]byte(`"not found"}`))
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rid/v2/dss/identification_service_areas/abc?x=1", nil))
+
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	require.Equal(t, `{"message":"not found"}`, rec.Body.String())
+	_, err := Verify(&key.PublicKey, rec.Header().Get(Header), http.MethodGet, "/rid/v2/dss/identification_service_areas/abc?x=1", http.StatusNotFound, rec.Body.Bytes())
+	require.NoError(t, err)
+
+	// Empty responses are signed too.
+	handler = s.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/x", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	_, err = Verify(&key.PublicKey, rec.Header().Get(Header), http.MethodDelete, "/x", http.StatusOK, nil)
+	require.NoError(t, err)
+
+	var nilSigner *Signer
+	require.NotNil(t, nilSigner.Middleware(http.NotFoundHandler()))
+}
+
+func TestParseKeys(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+	for _, block := range []*pem.Block{
+		{Type: "PRIVATE KEY", Bytes: pkcs8},
+		{Type: "EC PRIVATE KEY", Bytes: sec1},
+		{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+	} {
+		key, err := ParsePrivateKey(pem.EncodeToMemory(block))
+		require.NoError(t, err, block.Type)
+		s, err := NewSigner(key, "")
+		require.NoError(t, err, block.Type)
+
+		pkix, err := x509.MarshalPKIXPublicKey(key.Public())
+		require.NoError(t, err)
+		public, err := ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}))
+		require.NoError(t, err)
+		signature, err := s.Sign(http.MethodGet, "/", http.StatusOK, []byte("{}"))
+		require.NoError(t, err)
+		_, err = Verify(public, signature, http.MethodGet, "/", http.StatusOK, []byte("{}"))
+		require.NoError(t, err, block.Type)
+	}
+
+	_, err = ParsePrivateKey([]byte("not a key"))
+	require.Error(t, err)
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	_, err = NewSigner(smallKey, "")
+	require.Error(t, err)
+}
@@ -0,0 +1,108 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SQLSTATE codes of the database errors translated by TranslateError, see
+// https://www.postgresql.org/docs/current/errcodes-appendix.html.
+const (
+	dataExceptionClass   = "22"
+	notNullViolation     = "23502"
+	foreignKeyViolation  = "23503"
+	uniqueViolation      = "23505"
+	checkViolation       = "23514"
+	serializationFailure = "40001"
+)
+
+// TranslateError maps database errors to the dsserr code matching their
+// cause so that they are reported to clients uniformly:
+//   - pgx.ErrNoRows is dsserr.NotFound;
+//   - unique violations are dsserr.AlreadyExists;
+//   - foreign key, check and not null violations as well as data exceptions
+//     are dsserr.BadRequest;
+//   - serialization failures that outlasted retries are
+//     dsserr.VersionMismatch, as the entity was modified concurrently.
+//
+// Errors that already carry a code and other errors are returned unchanged.
+// The original error remains accessible through errors.Is and errors.As.
+func TranslateError(err error) error {
+	if err == nil || stacktrace.GetCode(err) != stacktrace.NoCode {
+		return err
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return stacktrace.PropagateWithCode(err, dsserr.NotFound, "Entity not found")
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch {
+	case pgErr.Code == uniqueViolation:
+		return stacktrace.PropagateWithCode(err, dsserr.AlreadyExists, "Entity already exists")
+	case pgErr.Code == foreignKeyViolation:
+		return stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Entity references a missing entity")
+	case pgErr.Code == checkViolation, pgErr.Code == notNullViolation:
+		return stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Entity violates a constraint")
+	case strings.HasPrefix(pgErr.Code, dataExceptionClass):
+		return stacktrace.PropagateWithCode(err, dsserr.BadRequest, "Invalid data")
+	case pgErr.Code == serializationFailure:
+		return stacktrace.PropagateWithCode(err, dsserr.VersionMismatch, "Entity was modified concurrently")
+	}
+	return err
+}
+
+// WithErrorTranslation returns a Queryable forwarding to q whose errors,
+// including those of the rows it returns, are translated by TranslateError.
+func WithErrorTranslation(q Queryable) Queryable {
+	return &translatingQueryable{q: q}
+}
+
+type translatingQueryable struct {
+	q Queryable
+}
+
+func (t *translatingQueryable) Query(ctx context.Context, query string, args ...interface{}) (pgx.Rows, error) {
+	rows, err := t.q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, TranslateError(err)
+	}
+	return translatingRows{rows}, nil
+}
+
+func (t *translatingQueryable) QueryRow(ctx context.Context, query string, args ...interface{}) pgx.Row {
+	return translatingRow{t.q.QueryRow(ctx, query, args...)}
+}
+
+func (t *translatingQueryable) Exec(ctx context.Context, query string, args ...interface{}) (pgconn.CommandTag, error) {
+	tag, err := t.q.Exec(ctx, query, args...)
+	return tag, TranslateError(err)
+}
+
+type translatingRows struct {
+	pgx.Rows
+}
+
+func (r translatingRows) Scan(dest ...interface{}) error {
+	return TranslateError(r.Rows.Scan(dest...))
+}
+
+func (r translatingRows) Err() error {
+	return TranslateError(r.Rows.Err())
+}
+
+type translatingRow struct {
+	pgx.Row
+}
+
+func (r translatingRow) Scan(dest ...interface{}) error {
+	return TranslateError(r.Row.Scan(dest...))
+}
@@ -0,0 +1,76 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	dsserr "github.com/interuss/dss/pkg/errors"
+	"github.com/interuss/stacktrace"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateError(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		code stacktrace.ErrorCode
+	}{
+		{"no rows", pgx.ErrNoRows, dsserr.NotFound},
+		{"wrapped no rows", stacktrace.Propagate(pgx.ErrNoRows, "wrapped"), dsserr.NotFound},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, dsserr.AlreadyExists},
+		{"foreign key violation", &pgconn.PgError{Code: "23503"}, dsserr.BadRequest},
+		{"check violation", &pgconn.PgError{Code: "23514"}, dsserr.BadRequest},
+		{"not null violation", &pgconn.PgError{Code: "23502"}, dsserr.BadRequest},
+		{"invalid text representation", &pgconn.PgError{Code: "22P02"}, dsserr.BadRequest},
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, dsserr.VersionMismatch},
+		{"other database error", &pgconn.PgError{Code: "XX000"}, stacktrace.NoCode},
+		{"other error", errors.New("other"), stacktrace.NoCode},
+		{"coded error", stacktrace.NewErrorWithCode(dsserr.Exhausted, "coded"), dsserr.Exhausted},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := TranslateError(tc.err)
+			require.Equal(t, tc.code, stacktrace.GetCode(err))
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+	require.NoError(t, TranslateError(nil))
+}
+
+type failingRow struct{ err error }
+
+func (r failingRow) Scan(...interface{}) error { return r.err }
+
+type failingQueryable struct{ err error }
+
+func (q failingQueryable) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, q.err
+}
+
+func (q failingQueryable) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	return failingRow{q.err}
+}
+
+func (q failingQueryable) Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, q.err
+}
+
+func TestWithErrorTranslation(t *testing.T) {
+	var (
+		ctx = context.Background()
+		q   = WithErrorTranslation(failingQueryable{&pgconn.PgError{Code: "23505"}})
+	)
+
+	_, err := q.Query(ctx, "")
+	require.Equal(t, dsserr.AlreadyExists, stacktrace.GetCode(err))
+	_, err = q.Exec(ctx, "")
+	require.Equal(t, dsserr.AlreadyExists, stacktrace.GetCode(err))
+	require.Equal(t, dsserr.AlreadyExists, stacktrace.GetCode(q.QueryRow(ctx, "").Scan()))
+
+	q = WithErrorTranslation(failingQueryable{pgx.ErrNoRows})
+	err = q.QueryRow(ctx, "").Scan()
+	require.Equal(t, dsserr.NotFound, stacktrace.GetCode(err))
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
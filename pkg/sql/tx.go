@@ -0,0 +1,101 @@
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach-go/v2/crdb"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// rollbackTimeout bounds the time spent rolling back a transaction once its
+// context is done.
+const rollbackTimeout = 5 * time.Second
+
+// Beginner begins transactions, e.g. a pgxpool.Pool.
+type Beginner interface {
+	BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error)
+}
+
+// ExecuteTx runs fn in a transaction begun with db, retrying it on retryable
+// errors like crdbpgx.ExecuteTx. Unlike crdbpgx.ExecuteTx, the transaction is
+// rolled back rather than committed if ctx is done by the time fn returns,
+// e.g. as the client disconnected, and rollbacks are not interrupted by ctx,
+// so that connections are returned to the pool clean rather than closed.
+// The queries made through the pgx.Tx passed to fn carry the number of
+// previous attempts of the transaction, as returned by TxRetries.
+func ExecuteTx(ctx context.Context, db Beginner, opts pgx.TxOptions, fn func(pgx.Tx) error) error {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	retries := -1
+	return crdb.ExecuteInTx(ctx, txAdapter{tx}, func() error {
+		retries++
+		if err := fn(attemptTx{Tx: tx, retries: retries}); err != nil {
+			return err
+		}
+		return ctx.Err()
+	})
+}
+
+type txRetriesKey struct{}
+
+// TxRetries returns the number of times the transaction of the query made
+// with ctx was retried before the current attempt, or 0 outside of
+// transactions run by ExecuteTx.
+func TxRetries(ctx context.Context) int {
+	retries, _ := ctx.Value(txRetriesKey{}).(int)
+	return retries
+}
+
+// attemptTx is an attempt of a transaction, whose queries carry the number of
+// previous attempts in their context.
+type attemptTx struct {
+	pgx.Tx
+	retries int
+}
+
+func (t attemptTx) context(ctx context.Context) context.Context {
+	if t.retries == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, txRetriesKey{}, t.retries)
+}
+
+func (t attemptTx) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	return t.Tx.Exec(t.context(ctx), query, args...)
+}
+
+func (t attemptTx) Query(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+	return t.Tx.Query(t.context(ctx), query, args...)
+}
+
+func (t attemptTx) QueryRow(ctx context.Context, query string, args ...any) pgx.Row {
+	return t.Tx.QueryRow(t.context(ctx), query, args...)
+}
+
+// txAdapter adapts a pgx.Tx to crdb.Tx.
+type txAdapter struct {
+	tx pgx.Tx
+}
+
+var _ crdb.Tx = txAdapter{}
+
+func (a txAdapter) Commit(ctx context.Context) error {
+	return a.tx.Commit(ctx)
+}
+
+// Rollback rolls back the transaction even if ctx is done, as pgx closes
+// connections whose transaction it fails to roll back.
+func (a txAdapter) Rollback(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+	return a.tx.Rollback(ctx)
+}
+
+func (a txAdapter) Exec(ctx context.Context, query string, args ...interface{}) error {
+	_, err := a.tx.Exec(ctx, query, args...)
+	return err
+}
@@ -0,0 +1,107 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTx records the statements executed in a transaction.
+type fakeTx struct {
+	pgx.Tx
+	statements []string
+	// retries are the TxRetries of the statements.
+	retries    []int
+	rolledBack bool
+	// rollbackErr is the error of the context of the rollback.
+	rollbackErr error
+}
+
+func (tx *fakeTx) BeginTx(context.Context, pgx.TxOptions) (pgx.Tx, error) {
+	return tx, nil
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, query string, _ ...any) (pgconn.CommandTag, error) {
+	if err := ctx.Err(); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	tx.statements = append(tx.statements, query)
+	tx.retries = append(tx.retries, TxRetries(ctx))
+	return pgconn.CommandTag{}, nil
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	tx.statements = append(tx.statements, "COMMIT")
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	tx.rolledBack = true
+	tx.rollbackErr = ctx.Err()
+	return nil
+}
+
+func TestExecuteTxCommits(t *testing.T) {
+	tx := &fakeTx{}
+	err := ExecuteTx(context.Background(), tx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		_, err := tx.Exec(context.Background(), "INSERT")
+		return err
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"SAVEPOINT cockroach_restart", "INSERT", "RELEASE SAVEPOINT cockroach_restart", "COMMIT"}, tx.statements)
+	require.False(t, tx.rolledBack)
+}
+
+func TestExecuteTxRollsBackCanceledTransactions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	tx := &fakeTx{}
+	err := ExecuteTx(ctx, tx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "INSERT"); err != nil {
+			return err
+		}
+		// The client disconnects before the transaction is committed.
+		cancel()
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, []string{"SAVEPOINT cockroach_restart", "INSERT"}, tx.statements)
+	require.True(t, tx.rolledBack)
+	require.NoError(t, tx.rollbackErr)
+}
+
+func TestExecuteTxRollsBackFailedTransactions(t *testing.T) {
+	tx := &fakeTx{}
+	failure := errors.New("failure")
+	err := ExecuteTx(context.Background(), tx, pgx.TxOptions{}, func(pgx.Tx) error {
+		return failure
+	})
+	require.Equal(t, failure, err)
+	require.True(t, tx.rolledBack)
+	require.NotContains(t, tx.statements, "COMMIT")
+}
+
+func TestExecuteTxReportsRetries(t *testing.T) {
+	tx := &fakeTx{}
+	attempts := 0
+	err := ExecuteTx(context.Background(), tx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(context.Background(), "INSERT"); err != nil {
+			return err
+		}
+		attempts++
+		if attempts == 1 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"SAVEPOINT cockroach_restart", "INSERT", "ROLLBACK TO SAVEPOINT cockroach_restart", "INSERT",
+		"RELEASE SAVEPOINT cockroach_restart", "COMMIT",
+	}, tx.statements)
+	require.Equal(t, []int{0, 0, 0, 1, 0}, tx.retries)
+	require.Zero(t, TxRetries(context.Background()))
+}
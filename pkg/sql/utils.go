@@ -1,13 +1,18 @@
 package sql
 
 import (
+	"fmt"
+
 	"github.com/interuss/dss/pkg/geo"
 	"github.com/interuss/stacktrace"
 
 	"github.com/golang/geo/s2"
 )
 
+// CellUnionToCellIds returns the IDs of the cells of cu, normalized by
+// geo.NormalizeCells so that no cell is written or searched twice.
 func CellUnionToCellIds(cu s2.CellUnion) []int64 {
+	cu = geo.NormalizeCells(cu)
 	pgCids := make([]int64, len(cu))
 	for i, cell := range cu {
 		// TODO consider validating the cell here: it is/was done in many similar conversion loops
@@ -16,7 +21,10 @@ func CellUnionToCellIds(cu s2.CellUnion) []int64 {
 	return pgCids
 }
 
+// CellUnionToCellIdsWithValidation is CellUnionToCellIds, failing if any cell
+// is not of a level that coverings may contain.
 func CellUnionToCellIdsWithValidation(cu s2.CellUnion) ([]int64, error) {
+	cu = geo.NormalizeCells(cu)
 	pgCids := make([]int64, len(cu))
 	for i, cell := range cu {
 		if err := geo.ValidateCell(cell); err != nil {
@@ -27,6 +35,35 @@ func CellUnionToCellIdsWithValidation(cu s2.CellUnion) ([]int64, error) {
 	return pgCids, nil
 }
 
+// CellsIntersect returns the SQL condition under which any of the cells of the
+// INT8[] column intersects any of the cells of the INT8[] placeholder. When
+// all coverings are of a single cell level, cells intersect only if they are
+// equal, which the inverted index of the column serves. Otherwise, cells
+// intersect when the ID of one is within the range of cell IDs of the other,
+// which has to be checked row by row.
+func CellsIntersect(column, placeholder string) string {
+	if !geo.MultiLevelCoverings() {
+		return fmt.Sprintf("%s && %s", column, placeholder)
+	}
+	return fmt.Sprintf(`EXISTS (
+				SELECT 1 FROM unnest(%s) AS stored_cells(id), unnest(%s::INT8[]) AS searched_cells(id)
+				WHERE %s)`, column, placeholder, CellRangesIntersect("stored_cells.id", "searched_cells.id"))
+}
+
+// CellRangesIntersect returns the SQL condition under which the cells
+// identified by the INT8 expressions a and b intersect, i.e. one of them
+// contains the other: a coarse cell contains all the finer cells whose ID is
+// within its range of cell IDs (see s2.CellID.RangeMin and RangeMax). That
+// range spans the lowest set bit of the cell ID, minus one, on each side of
+// it; it never crosses a face boundary, so it is ordered consistently with
+// the signed cell IDs.
+func CellRangesIntersect(a, b string) string {
+	within := func(id, cell string) string {
+		return fmt.Sprintf("%[1]s BETWEEN %[2]s - ((%[2]s & -%[2]s) - 1) AND %[2]s + ((%[2]s & -%[2]s) - 1)", id, cell)
+	}
+	return fmt.Sprintf("(%s OR %s)", within(a, b), within(b, a))
+}
+
 func ForUpdate(forUpdate bool) string {
 	if forUpdate {
 		return "FOR UPDATE"
@@ -0,0 +1,59 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/stretchr/testify/require"
+)
+
+// cellRangesIntersect evaluates the condition of CellRangesIntersect with the
+// signed 64-bit arithmetic of the database.
+func cellRangesIntersect(a, b int64) bool {
+	within := func(id, cell int64) bool {
+		return cell-((cell&-cell)-1) <= id && id <= cell+((cell&-cell)-1)
+	}
+	return within(a, b) || within(b, a)
+}
+
+func TestCellRangesIntersect(t *testing.T) {
+	require.Equal(t,
+		"(a BETWEEN b - ((b & -b) - 1) AND b + ((b & -b) - 1) OR b BETWEEN a - ((a & -a) - 1) AND a + ((a & -a) - 1))",
+		CellRangesIntersect("a", "b"))
+
+	// Cells on every face, so that some have negative signed IDs.
+	var cells []s2.CellID
+	for face := 0; face < 6; face++ {
+		leaf := s2.CellIDFromFace(face).ChildBeginAtLevel(s2.MaxLevel).Advance(int64(face) * 12345678901)
+		for _, level := range []int{0, 5, 12, 13, 14, 20, s2.MaxLevel} {
+			cells = append(cells, leaf.Parent(level), leaf.Parent(level).Next())
+		}
+	}
+	for _, c := range cells {
+		// The ranges computed in the database are those of s2.
+		require.True(t, cellRangesIntersect(int64(c.RangeMin()), int64(c)))
+		require.True(t, cellRangesIntersect(int64(c.RangeMax()), int64(c)))
+		if c.Level() < s2.MaxLevel {
+			require.False(t, cellRangesIntersect(int64(c.RangeMax().Next()), int64(c)))
+		}
+	}
+	for _, a := range cells {
+		for _, b := range cells {
+			require.Equal(t, a.Intersects(b), cellRangesIntersect(int64(a), int64(b)), "cells %s and %s", a, b)
+		}
+	}
+}
+
+func TestCellsIntersectWithSingleLevelCoverings(t *testing.T) {
+	require.Equal(t, "cells && $1", CellsIntersect("cells", "$1"))
+}
+
+func TestCellUnionToCellIdsNormalizes(t *testing.T) {
+	cell := s2.CellIDFromLatLng(s2.LatLngFromDegrees(37.4, -122.1)).Parent(13)
+	next := cell.Next()
+
+	require.Equal(t, []int64{int64(cell), int64(next)}, CellUnionToCellIds(s2.CellUnion{next, cell, next, cell}))
+	cids, err := CellUnionToCellIdsWithValidation(s2.CellUnion{cell, next, cell})
+	require.NoError(t, err)
+	require.Equal(t, []int64{int64(cell), int64(next)}, cids)
+}
@@ -0,0 +1,7 @@
+// Package webhooks delivers the changes of remote ID entities to webhooks
+// configured by the operator of a DSS instance, e.g. to feed dashboards or
+// compliance systems without polling. Unlike the notifications of
+// subscribers required by the standard, every change is delivered, as a JSON
+// event signed with a secret shared with the receivers, and retried until
+// acknowledged or given up.
+package webhooks
@@ -0,0 +1,239 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/interuss/dss/pkg/rid/application"
+	"github.com/interuss/stacktrace"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+const (
+	// SignatureHeader is the header of deliveries carrying the signature of
+	// their body, as computed by Sign.
+	SignatureHeader = "DSS-Webhook-Signature"
+	// TimestampHeader is the header of deliveries carrying the time, in
+	// seconds since the epoch, at which they were signed.
+	TimestampHeader = "DSS-Webhook-Timestamp"
+)
+
+var (
+	deliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dss_webhook_deliveries_total",
+		Help: "Number of attempts to deliver events to webhooks, by result (delivered, retried or failed).",
+	}, []string{"result"})
+	droppedEvents = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "dss_webhook_events_dropped_total",
+		Help: "Number of events dropped because the queue of a webhook was full.",
+	})
+)
+
+// Event is the JSON body of the deliveries of an application.EntityEvent.
+type Event struct {
+	// Entity is isa or subscription.
+	Entity string `json:"entity"`
+	// Event is created, updated or deleted.
+	Event      string     `json:"event"`
+	ID         string     `json:"id"`
+	Owner      string     `json:"owner"`
+	Version    string     `json:"version,omitempty"`
+	TimeStart  *time.Time `json:"time_start,omitempty"`
+	TimeEnd    *time.Time `json:"time_end,omitempty"`
+	OccurredAt time.Time  `json:"occurred_at"`
+}
+
+// Config configures a Dispatcher.
+type Config struct {
+	// URLs are the http or https URLs to which events are POSTed.
+	URLs []string
+	// Secret signs deliveries.
+	Secret []byte
+	// MaxAttempts is the number of attempts to deliver an event before
+	// giving up; 5 if 0.
+	MaxAttempts int
+	// Backoff is the delay before the second attempt, doubling after every
+	// further attempt; 1s if 0.
+	Backoff time.Duration
+	// Timeout bounds each attempt; 10s if 0.
+	Timeout time.Duration
+	// QueueSize is the number of events waiting to be delivered to each
+	// webhook, further events being dropped; 1000 if 0.
+	QueueSize int
+	// Client makes the deliveries; http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// Dispatcher is an application.EventSink delivering events to webhooks. Each
+// webhook receives the events in order, one at a time.
+type Dispatcher struct {
+	hooks []*hook
+}
+
+type hook struct {
+	url    string
+	config Config
+	queue  chan []byte
+	logger *zap.Logger
+}
+
+// NewDispatcher returns a Dispatcher delivering events according to config
+// until ctx is done.
+func NewDispatcher(ctx context.Context, config Config, logger *zap.Logger) (*Dispatcher, error) {
+	if len(config.Secret) == 0 {
+		return nil, stacktrace.NewError("Webhooks require a secret to sign deliveries")
+	}
+	if config.MaxAttempts == 0 {
+		config.MaxAttempts = 5
+	}
+	if config.Backoff == 0 {
+		config.Backoff = time.Second
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.QueueSize == 0 {
+		config.QueueSize = 1000
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.MaxAttempts < 0 || config.Backoff < 0 || config.Timeout < 0 || config.QueueSize < 0 {
+		return nil, stacktrace.NewError("Webhook attempts, backoff, timeout and queue size must not be negative")
+	}
+
+	d := &Dispatcher{}
+	for _, u := range config.URLs {
+		parsed, err := url.Parse(u)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, stacktrace.NewError("Invalid webhook URL `%s`: expected an http or https URL", u)
+		}
+		d.hooks = append(d.hooks, &hook{
+			url:    u,
+			config: config,
+			queue:  make(chan []byte, config.QueueSize),
+			logger: logger.With(zap.String("webhook", parsed.Host)),
+		})
+	}
+	for _, h := range d.hooks {
+		go h.run(ctx)
+	}
+	return d, nil
+}
+
+// Publish implements application.EventSink.
+func (d *Dispatcher) Publish(_ context.Context, event *application.EntityEvent) {
+	e := Event{
+		Entity:     string(event.Entity),
+		Event:      string(event.Kind),
+		ID:         event.EntityID.String(),
+		Owner:      event.Owner.String(),
+		TimeStart:  event.StartTime,
+		TimeEnd:    event.EndTime,
+		OccurredAt: event.OccurredAt,
+	}
+	if event.Version != nil {
+		e.Version = event.Version.String()
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		// Events are made of strings and times, which always marshal.
+		panic(err)
+	}
+	for _, h := range d.hooks {
+		select {
+		case h.queue <- body:
+		default:
+			droppedEvents.Inc()
+			h.logger.Warn("Webhook queue full; dropping event", zap.String("entity", e.Entity), zap.String("id", e.ID))
+		}
+	}
+}
+
+// Sign returns the signature of a delivery of body signed at timestamp, the
+// value of TimestampHeader: the hex-encoded HMAC-SHA256 of the timestamp, a
+// dot and the body, keyed with secret, prefixed with "sha256=".
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (h *hook) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case body := <-h.queue:
+			h.deliver(ctx, body)
+		}
+	}
+}
+
+// deliver attempts to deliver body until it is acknowledged, the failure is
+// permanent or the attempts are exhausted.
+func (h *hook) deliver(ctx context.Context, body []byte) {
+	backoff := h.config.Backoff
+	for attempt := 1; ; attempt++ {
+		retryable, err := h.post(ctx, body)
+		if err == nil {
+			deliveries.WithLabelValues("delivered").Inc()
+			return
+		}
+		if !retryable || attempt >= h.config.MaxAttempts {
+			deliveries.WithLabelValues("failed").Inc()
+			h.logger.Warn("Failed to deliver event to webhook", zap.Int("attempts", attempt), zap.Error(err))
+			return
+		}
+		deliveries.WithLabelValues("retried").Inc()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post makes one attempt to deliver body, returning whether a failure may be
+// retried.
+func (h *hook) post(ctx context.Context, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return false, stacktrace.Propagate(err, "Error creating webhook request")
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(h.config.Secret, timestamp, body))
+
+	resp, err := h.config.Client.Do(req)
+	if err != nil {
+		return true, stacktrace.Propagate(err, "Error posting event to webhook")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return true, stacktrace.NewError("Webhook responded %s", resp.Status)
+	default:
+		return false, stacktrace.NewError("Webhook rejected event: %s", resp.Status)
+	}
+}
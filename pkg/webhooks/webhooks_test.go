@@ -0,0 +1,173 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dssmodels "github.com/interuss/dss/pkg/models"
+	"github.com/interuss/dss/pkg/rid/application"
+	ridmodels "github.com/interuss/dss/pkg/rid/models"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type delivery struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver returns a server answering deliveries with the statuses, then 200,
+// and the channel of the deliveries it received.
+func receiver(t *testing.T, statuses ...int) (*httptest.Server, chan delivery) {
+	received := make(chan delivery, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received <- delivery{header: r.Header, body: body}
+		if len(statuses) > 0 {
+			w.WriteHeader(statuses[0])
+			statuses = statuses[1:]
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func testEvent() *application.EntityEvent {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return &application.EntityEvent{
+		ActivityEvent: ridmodels.ActivityEvent{
+			Entity:     ridmodels.ActivityISA,
+			EntityID:   dssmodels.ID("4348c8e5-0b1c-43cf-9114-2e67a4532765"),
+			Kind:       ridmodels.ActivityCreated,
+			OccurredAt: start,
+			StartTime:  &start,
+		},
+		Owner:   "uss1",
+		Version: dssmodels.VersionFromTime(start),
+	}
+}
+
+func next(t *testing.T, received chan delivery) delivery {
+	select {
+	case d := <-received:
+		return d
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no delivery received")
+		return delivery{}
+	}
+}
+
+func TestDeliversSignedEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, received := receiver(t)
+	secret := []byte("secret")
+	d, err := NewDispatcher(ctx, Config{URLs: []string{server.URL}, Secret: secret}, zap.NewNop())
+	require.NoError(t, err)
+
+	d.Publish(ctx, testEvent())
+	got := next(t, received)
+	require.Equal(t, "application/json", got.header.Get("Content-Type"))
+	require.Equal(t, Sign(secret, got.header.Get(TimestampHeader), got.body), got.header.Get(SignatureHeader))
+	require.NotEqual(t, Sign([]byte("other"), got.header.Get(TimestampHeader), got.body), got.header.Get(SignatureHeader))
+
+	var e Event
+	require.NoError(t, json.Unmarshal(got.body, &e))
+	require.Equal(t, "isa", e.Entity)
+	require.Equal(t, "created", e.Event)
+	require.Equal(t, "4348c8e5-0b1c-43cf-9114-2e67a4532765", e.ID)
+	require.Equal(t, "uss1", e.Owner)
+	require.NotEmpty(t, e.Version)
+	require.NotNil(t, e.TimeStart)
+	require.Nil(t, e.TimeEnd)
+}
+
+func TestRetriesTransientFailures(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, received := receiver(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	d, err := NewDispatcher(ctx, Config{URLs: []string{server.URL}, Secret: []byte("secret"), Backoff: time.Millisecond}, zap.NewNop())
+	require.NoError(t, err)
+
+	d.Publish(ctx, testEvent())
+	first := next(t, received)
+	next(t, received)
+	third := next(t, received)
+	require.Equal(t, first.body, third.body)
+	select {
+	case <-received:
+		require.FailNow(t, "acknowledged event delivered again")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestGivesUp(t *testing.T) {
+	for name, config := range map[string]struct {
+		statuses []int
+		attempts int
+	}{
+		"rejected":  {statuses: []int{http.StatusBadRequest}, attempts: 1},
+		"exhausted": {statuses: []int{500, 500, 500}, attempts: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			server, received := receiver(t, config.statuses...)
+			d, err := NewDispatcher(ctx, Config{
+				URLs: []string{server.URL}, Secret: []byte("secret"), Backoff: time.Millisecond, MaxAttempts: 2,
+			}, zap.NewNop())
+			require.NoError(t, err)
+
+			d.Publish(ctx, testEvent())
+			for i := 0; i < config.attempts; i++ {
+				next(t, received)
+			}
+			select {
+			case <-received:
+				require.FailNow(t, "event delivered after giving up")
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestDropsEventsWhenQueueFull(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	release := make(chan struct{})
+	received := make(chan struct{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	d, err := NewDispatcher(ctx, Config{URLs: []string{server.URL}, Secret: []byte("secret"), QueueSize: 1}, zap.NewNop())
+	require.NoError(t, err)
+
+	// The first event is being delivered, the second is queued and the third
+	// is dropped without blocking.
+	d.Publish(ctx, testEvent())
+	<-received
+	d.Publish(ctx, testEvent())
+	d.Publish(ctx, testEvent())
+	require.Len(t, d.hooks[0].queue, 1)
+}
+
+func TestNewDispatcherValidatesConfig(t *testing.T) {
+	ctx := context.Background()
+	_, err := NewDispatcher(ctx, Config{URLs: []string{"https://example.com/hook"}}, zap.NewNop())
+	require.Error(t, err)
+	for _, u := range []string{"example.com/hook", "ftp://example.com", "https://"} {
+		_, err = NewDispatcher(ctx, Config{URLs: []string{u}, Secret: []byte("secret")}, zap.NewNop())
+		require.Error(t, err, u)
+	}
+	_, err = NewDispatcher(ctx, Config{URLs: []string{"https://example.com/hook"}, Secret: []byte("secret"), MaxAttempts: -1}, zap.NewNop())
+	require.Error(t, err)
+}